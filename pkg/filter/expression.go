@@ -23,6 +23,7 @@ import (
 	"fmt"
 
 	"configcenter/src/common/criteria/enumor"
+	"configcenter/src/common/mapstr"
 
 	"github.com/tidwall/gjson"
 	"go.mongodb.org/mongo-driver/bson"
@@ -98,6 +99,16 @@ func (exp Expression) Validate(opt *ExprOption) error {
 	return exp.RuleFactory.Validate(opt)
 }
 
+// Match test if the document matches the expression, returns the same result as querying the document in db
+// with the expression's mongo condition.
+func (exp Expression) Match(doc mapstr.MapStr, opts ...*RuleOption) (bool, error) {
+	if exp.RuleFactory == nil {
+		return false, errors.New("expression should not be nil")
+	}
+
+	return exp.RuleFactory.Match(doc, opts...)
+}
+
 // MarshalJSON marshal Expression into json value
 func (exp Expression) MarshalJSON() ([]byte, error) {
 	if exp.RuleFactory != nil {
@@ -0,0 +1,344 @@
+/*
+ * Tencent is pleased to support the open source community by making
+ * 蓝鲸智云 - 配置平台 (BlueKing - Configuration System) available.
+ * Copyright (C) 2017 THL A29 Limited,
+ * a Tencent company. All rights reserved.
+ * Licensed under the MIT License (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at http://opensource.org/licenses/MIT
+ * Unless required by applicable law or agreed to in writing,
+ * software distributed under the License is distributed on
+ * an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND,
+ * either express or implied. See the License for the
+ * specific language governing permissions and limitations under the License.
+ * We undertake not to change the open source license (MIT license) applicable
+ * to the current version of the project delivered to anyone in the future.
+ */
+
+package filter
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"math/big"
+	"net"
+	"strconv"
+	"strings"
+
+	"configcenter/src/common"
+)
+
+// ipNetwork is an ip network, ip and mask are 4 bytes for ipv4 and 16 bytes for ipv6.
+type ipNetwork struct {
+	ip   net.IP
+	ones int
+}
+
+// parseIP parse the ip address, returns 4 bytes ip for ipv4 address and 16 bytes ip for ipv6 address.
+// an ipv4 address embedded in ipv6 address is still an ipv6 address, like the way host ipv6 fields are stored.
+func parseIP(address string) (net.IP, error) {
+	address = strings.TrimSpace(address)
+	ip := net.ParseIP(address)
+	if ip == nil {
+		return nil, fmt.Errorf("ip address %s is invalid", address)
+	}
+
+	if strings.Contains(address, ":") {
+		return ip.To16(), nil
+	}
+	return ip.To4(), nil
+}
+
+// parseCIDRs parse the ip_in_cidr and ip_not_in_cidr operator's value, it can be a cidr or an array of cidrs.
+func parseCIDRs(value interface{}, maxLimit uint) ([]ipNetwork, error) {
+	var cidrs []interface{}
+	if str, ok := value.(string); ok {
+		cidrs = []interface{}{str}
+	} else {
+		var isSlice bool
+		cidrs, isSlice = toSlice(value)
+		if !isSlice {
+			return nil, fmt.Errorf("value(%+v) should be a cidr or an array of cidrs", value)
+		}
+	}
+
+	if len(cidrs) == 0 {
+		return nil, errors.New("cidrs can not be empty")
+	}
+
+	if maxLimit > 0 && len(cidrs) > int(maxLimit) {
+		return nil, fmt.Errorf("cidrs elements number exceeds limit: %d", maxLimit)
+	}
+
+	networks := make([]ipNetwork, len(cidrs))
+	for idx, cidr := range cidrs {
+		str, ok := cidr.(string)
+		if !ok {
+			return nil, fmt.Errorf("cidr(%+v) should be a string", cidr)
+		}
+
+		ip, ipNet, err := net.ParseCIDR(strings.TrimSpace(str))
+		if err != nil {
+			return nil, fmt.Errorf("cidr %s is invalid, err: %v", str, err)
+		}
+
+		ones, bits := ipNet.Mask.Size()
+		if strings.Contains(str, ":") {
+			ip = ip.To16().Mask(net.CIDRMask(ones, bits))
+		} else {
+			ip = ip.To4().Mask(net.CIDRMask(ones, bits))
+		}
+
+		networks[idx] = ipNetwork{ip: ip, ones: ones}
+	}
+
+	return networks, nil
+}
+
+// parseIPRange parse the ip_range operator's value, it must be an array of the start and end ip of the same type.
+func parseIPRange(value interface{}) (net.IP, net.IP, error) {
+	ips, ok := toSlice(value)
+	if !ok || len(ips) != 2 {
+		return nil, nil, fmt.Errorf("value(%+v) should be an array of start ip and end ip", value)
+	}
+
+	rangeIPs := make([]net.IP, 2)
+	for idx, ip := range ips {
+		str, ok := ip.(string)
+		if !ok {
+			return nil, nil, fmt.Errorf("ip(%+v) should be a string", ip)
+		}
+
+		parsed, err := parseIP(str)
+		if err != nil {
+			return nil, nil, err
+		}
+		rangeIPs[idx] = parsed
+	}
+
+	start, end := rangeIPs[0], rangeIPs[1]
+	if len(start) != len(end) {
+		return nil, nil, fmt.Errorf("start ip %s and end ip %s are not the same type", start, end)
+	}
+
+	if bytes.Compare(start, end) > 0 {
+		return nil, nil, fmt.Errorf("start ip %s is greater than end ip %s", start, end)
+	}
+
+	return start, end, nil
+}
+
+// contains test if the ip is in the network, ipv4 network only contains ipv4 address, and so is ipv6 network.
+func (n ipNetwork) contains(ip net.IP) bool {
+	if len(ip) != len(n.ip) {
+		return false
+	}
+
+	return ip.Mask(net.CIDRMask(n.ones, len(ip)*8)).Equal(n.ip)
+}
+
+// ipRangeToNetworks split the ip range into the minimum number of networks.
+func ipRangeToNetworks(start, end net.IP) []ipNetwork {
+	bits := len(start) * 8
+	cur := new(big.Int).SetBytes(start)
+	last := new(big.Int).SetBytes(end)
+	one := big.NewInt(1)
+
+	networks := make([]ipNetwork, 0)
+	for cur.Cmp(last) <= 0 {
+		size := bits
+		if cur.Sign() != 0 {
+			size = int(cur.TrailingZeroBits())
+		}
+
+		// find the largest network starting at the current ip that does not exceed the end ip
+		for size > 0 {
+			blockEnd := new(big.Int).Add(cur, new(big.Int).Sub(new(big.Int).Lsh(one, uint(size)), one))
+			if blockEnd.Cmp(last) <= 0 {
+				break
+			}
+			size--
+		}
+
+		ip := make(net.IP, len(start))
+		cur.FillBytes(ip)
+		networks = append(networks, ipNetwork{ip: ip, ones: bits - size})
+
+		cur.Add(cur, new(big.Int).Lsh(one, uint(size)))
+	}
+
+	return networks
+}
+
+// matchIPs test if any of the field values' ips matches the match function, the value can be an ip string, a
+// comma separated multiple ip string, or an array of them, which is the format of the host ip fields.
+func matchIPs(values []interface{}, match func(ip net.IP) bool) bool {
+	return matchAny(values, func(v interface{}) bool {
+		str, ok := v.(string)
+		if !ok {
+			return false
+		}
+
+		for _, address := range strings.Split(str, ",") {
+			ip, err := parseIP(address)
+			if err != nil {
+				continue
+			}
+
+			if match(ip) {
+				return true
+			}
+		}
+		return false
+	})
+}
+
+// networksToRegex generate the regular expression that matches the ips in the networks, it is used to filter ip in
+// mongodb. ipv4 address is stored as it is, ipv6 address is stored in the full format, like what
+// common.ConvertIPv6ToStandardFormat does, e.g. 0000:0000:0000:0000:0000:0000:0000:0001 or with an embedded ipv4
+// address like 0000:0000:0000:0000:0000:ffff:127.0.0.1. like matchIPs, the value matches if any of its comma
+// separated ips is in the networks.
+func networksToRegex(networks []ipNetwork) (string, error) {
+	patterns := make([]string, len(networks))
+	for idx, network := range networks {
+		if len(network.ip) == net.IPv4len {
+			patterns[idx] = ipv4Regex(network.ip, network.ones)
+			continue
+		}
+
+		fullAddr, err := common.ConvertIPv6ToStandardFormat(ipv6HexString(network.ip))
+		if err != nil {
+			return "", err
+		}
+
+		// ipv6 address in full hex format, or with the last 32 bits in ipv4 format
+		hexNibbles := strings.ReplaceAll(fullAddr, ":", "")
+		patterns[idx] = ipv6HexRegex(hexNibbles, network.ones, 32) + "|" + ipv6HexRegex(hexNibbles, network.ones, 24) +
+			":" + ipv4Regex(network.ip[12:], network.ones-96)
+	}
+
+	pattern := `(?:^|,)\s*(?:` + strings.Join(patterns, "|") + `)\s*(?:,|$)`
+	if len(pattern) > maxIPRegexLength {
+		return "", errors.New("ip range is too complex to be converted to mongodb condition, please use cidrs instead")
+	}
+
+	return pattern, nil
+}
+
+// maxIPRegexLength is the maximum length of the ip regular expression, mongodb's limit is 32764 bytes.
+const maxIPRegexLength = 32000
+
+// ipv6HexString returns the ipv6 address in hex format without compression, it's always an ipv6 address even
+// if the address is an ipv4 embedded address, so that ipv6 normalization can be used.
+func ipv6HexString(ip net.IP) string {
+	groups := make([]string, 8)
+	for i := 0; i < 8; i++ {
+		groups[i] = strconv.FormatUint(uint64(ip[2*i])<<8|uint64(ip[2*i+1]), 16)
+	}
+	return strings.Join(groups, ":")
+}
+
+// ipv6HexRegex generate the regular expression of the first nibbleCnt hex nibbles of the ipv6 network.
+func ipv6HexRegex(nibbles string, ones int, nibbleCnt int) string {
+	var sb strings.Builder
+	for i := 0; i < nibbleCnt; i++ {
+		if i > 0 && i%4 == 0 {
+			sb.WriteString(":")
+		}
+
+		fixedBits := ones - i*4
+		switch {
+		case fixedBits >= 4:
+			sb.WriteByte(nibbles[i])
+		case fixedBits <= 0:
+			// the remaining nibbles are all free, write them in the compact form
+			if groupRest := 4 - i%4; groupRest > 0 {
+				sb.WriteString(fmt.Sprintf("[0-9a-f]{%d}", groupRest))
+			}
+			if groups := (nibbleCnt - i - 4 + i%4) / 4; groups > 0 {
+				sb.WriteString(fmt.Sprintf("(?::[0-9a-f]{4}){%d}", groups))
+			}
+			return sb.String()
+		default:
+			lo, _ := strconv.ParseUint(nibbles[i:i+1], 16, 8)
+			hi := lo | (1<<uint(4-fixedBits) - 1)
+			sb.WriteString("[")
+			for v := lo; v <= hi; v++ {
+				sb.WriteString(strconv.FormatUint(v, 16))
+			}
+			sb.WriteString("]")
+		}
+	}
+	return sb.String()
+}
+
+// ipv4Regex generate the regular expression of the ipv4 network.
+func ipv4Regex(ip net.IP, ones int) string {
+	octets := make([]string, net.IPv4len)
+	for i := 0; i < net.IPv4len; i++ {
+		fixedBits := ones - i*8
+		switch {
+		case fixedBits >= 8:
+			octets[i] = strconv.Itoa(int(ip[i]))
+		case fixedBits <= 0:
+			octets[i] = "[0-9]{1,3}"
+		default:
+			lo := int(ip[i])
+			octets[i] = decimalRangeRegex(lo, lo|(1<<uint(8-fixedBits)-1))
+		}
+	}
+	return strings.Join(octets, `\.`)
+}
+
+// decimalRangeRegex generate the regular expression that matches the decimal numbers in range [lo, hi].
+func decimalRangeRegex(lo, hi int) string {
+	if lo == hi {
+		return strconv.Itoa(lo)
+	}
+
+	parts := make([]string, 0)
+	// split the range into sub ranges whose numbers have the same length
+	for digitMax := 9; lo <= hi; digitMax = digitMax*10 + 9 {
+		if lo > digitMax {
+			continue
+		}
+
+		subHi := hi
+		if subHi > digitMax {
+			subHi = digitMax
+		}
+		parts = append(parts, sameLenDecimalRangeRegex(strconv.Itoa(lo), strconv.Itoa(subHi)))
+		lo = subHi + 1
+	}
+
+	return "(?:" + strings.Join(parts, "|") + ")"
+}
+
+// sameLenDecimalRangeRegex generate the regular expression that matches decimal numbers in range [lo, hi], the
+// lo and hi numbers must have the same length.
+func sameLenDecimalRangeRegex(lo, hi string) string {
+	if lo == hi {
+		return lo
+	}
+
+	if len(lo) == 1 {
+		return "[" + lo + "-" + hi + "]"
+	}
+
+	if lo[0] == hi[0] {
+		return lo[:1] + sameLenDecimalRangeRegex(lo[1:], hi[1:])
+	}
+
+	restLen := len(lo) - 1
+	parts := []string{
+		lo[:1] + sameLenDecimalRangeRegex(lo[1:], strings.Repeat("9", restLen)),
+	}
+
+	if hi[0]-lo[0] > 1 {
+		parts = append(parts, fmt.Sprintf("[%c-%c][0-9]{%d}", lo[0]+1, hi[0]-1, restLen))
+	}
+
+	parts = append(parts, hi[:1]+sameLenDecimalRangeRegex(strings.Repeat("0", restLen), hi[1:]))
+	return "(?:" + strings.Join(parts, "|") + ")"
+}
@@ -0,0 +1,244 @@
+/*
+ * Tencent is pleased to support the open source community by making
+ * 蓝鲸智云 - 配置平台 (BlueKing - Configuration System) available.
+ * Copyright (C) 2017 THL A29 Limited,
+ * a Tencent company. All rights reserved.
+ * Licensed under the MIT License (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at http://opensource.org/licenses/MIT
+ * Unless required by applicable law or agreed to in writing,
+ * software distributed under the License is distributed on
+ * an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND,
+ * either express or implied. See the License for the
+ * specific language governing permissions and limitations under the License.
+ * We undertake not to change the open source license (MIT license) applicable
+ * to the current version of the project delivered to anyone in the future.
+ */
+
+package filter
+
+import (
+	"math/rand"
+	"net"
+	"regexp"
+	"testing"
+
+	"configcenter/src/common"
+	"configcenter/src/common/mapstr"
+)
+
+// TestIPRegex test that the generated regular expression matches the stored ip if and only if the ip is in the
+// networks, using random networks and ips around the networks' boundaries.
+func TestIPRegex(t *testing.T) {
+	r := rand.New(rand.NewSource(1))
+
+	for i := 0; i < 200; i++ {
+		ipLen := net.IPv4len
+		if i%2 == 1 {
+			ipLen = net.IPv6len
+		}
+
+		start, end := randomIP(r, ipLen), randomIP(r, ipLen)
+		if string(start) > string(end) {
+			start, end = end, start
+		}
+		// make the range small sometimes to test the boundaries more precisely
+		if i%3 == 0 {
+			copy(end, start)
+			end[ipLen-1] |= byte(r.Intn(256))
+			end[ipLen-2] |= byte(r.Intn(4))
+		}
+
+		networks := ipRangeToNetworks(start, end)
+		pattern, err := networksToRegex(networks)
+		if err != nil {
+			t.Errorf("generate regex for range %s-%s failed, err: %v", start, end, err)
+			return
+		}
+		reg := regexp.MustCompile(pattern)
+
+		candidates := []net.IP{start, end, offsetIP(start, -1), offsetIP(end, 1), randomIP(r, ipLen)}
+		for j := 0; j < 10; j++ {
+			candidates = append(candidates, offsetIP(start, r.Intn(512)-256), offsetIP(end, r.Intn(512)-256))
+		}
+
+		for _, ip := range candidates {
+			inRange := string(ip) >= string(start) && string(ip) <= string(end)
+
+			inNetworks := false
+			for _, network := range networks {
+				if network.contains(ip) {
+					inNetworks = true
+				}
+			}
+
+			if inRange != inNetworks {
+				t.Errorf("ip %s in range %s-%s is %v, but in networks is %v", ip, start, end, inRange, inNetworks)
+				return
+			}
+
+			for _, stored := range storedIPFormats(t, ip) {
+				if reg.MatchString(stored) != inRange {
+					t.Errorf("ip %s in range %s-%s is %v, but regex %s does not agree", stored, start, end,
+						inRange, pattern)
+					return
+				}
+			}
+		}
+	}
+}
+
+func randomIP(r *rand.Rand, ipLen int) net.IP {
+	ip := make(net.IP, ipLen)
+	r.Read(ip)
+	return ip
+}
+
+func offsetIP(ip net.IP, offset int) net.IP {
+	result := make(net.IP, len(ip))
+	copy(result, ip)
+
+	carry := offset
+	for i := len(result) - 1; i >= 0 && carry != 0; i-- {
+		sum := int(result[i]) + carry
+		result[i] = byte(sum & 0xff)
+		carry = sum >> 8
+	}
+	return result
+}
+
+// storedIPFormats returns the formats of the ip stored in db, ipv6 address can have an embedded ipv4 format.
+func storedIPFormats(t *testing.T, ip net.IP) []string {
+	if len(ip) == net.IPv4len {
+		return []string{ip.String()}
+	}
+
+	full, err := common.ConvertIPv6ToStandardFormat(ipv6HexString(ip))
+	if err != nil {
+		t.Fatalf("convert ipv6 %s failed, err: %v", ip, err)
+	}
+
+	return []string{full, full[:30] + net.IP(ip[12:]).String()}
+}
+
+func TestIPMatchCommaSeparated(t *testing.T) {
+	doc := mapstr.MapStr{"bk_host_innerip": "192.168.1.1,10.2.0.1", "bk_host_innerip_v6": "::1,fe80::1"}
+
+	cases := map[*AtomRule]bool{
+		atom("bk_host_innerip", IPInCIDR, "10.2.0.0/16"):                         true,
+		atom("bk_host_innerip", IPNotInCIDR, []interface{}{"10.2.0.0/16"}):       false,
+		atom("bk_host_innerip", IPRange, []string{"192.168.0.0", "192.168.1.0"}): false,
+		atom("bk_host_innerip_v6", IPInCIDR, "fe80::/64"):                        true,
+		atom("bk_host_innerip_v6", IPRange, []string{"::", "::2"}):               true,
+	}
+
+	for rule, expected := range cases {
+		matched, err := rule.Match(doc)
+		if err != nil {
+			t.Errorf("match rule %s %s failed, err: %v", rule.Operator, rule.Value, err)
+			return
+		}
+
+		if matched != expected {
+			t.Errorf("match rule %s %v result is not %v", rule.Operator, rule.Value, expected)
+			return
+		}
+	}
+
+	invalids := []*AtomRule{
+		atom("bk_host_innerip", IPInCIDR, "10.2.0.1"),
+		atom("bk_host_innerip", IPInCIDR, []string{}),
+		atom("bk_host_innerip", IPRange, []string{"10.0.0.2", "10.0.0.1"}),
+		atom("bk_host_innerip", IPRange, []string{"10.0.0.1", "::1"}),
+		atom("bk_host_innerip", IPRange, "10.0.0.1"),
+	}
+
+	for _, rule := range invalids {
+		if err := rule.Validate(&ExprOption{IgnoreRuleFields: true, MaxInLimit: 10, MaxNotInLimit: 10}); err == nil {
+			t.Errorf("validate invalid rule %s %v should fail", rule.Operator, rule.Value)
+			return
+		}
+	}
+}
+
+// TestIPMgoAndMatchConsistent test that the mongo condition and the in-memory match of the ip operators give the
+// same result for the ip fields with a single ip, comma separated ips, or an array of ips.
+func TestIPMgoAndMatchConsistent(t *testing.T) {
+	docs := []mapstr.MapStr{
+		{"ip": "192.168.1.1"},
+		{"ip": "192.168.1.1,10.2.0.1"},
+		{"ip": "10.2.0.1, 192.168.1.1"},
+		{"ip": "10.3.0.1,10.2.0.1,192.168.1.1"},
+		{"ip": "110.2.0.1,10.2.0.10"},
+		{"ip": []interface{}{"192.168.1.1", "10.2.0.1"}},
+		{"ip": []interface{}{"172.16.0.1"}},
+		{"ip": "0000:0000:0000:0000:0000:0000:0000:0001,fe80:0000:0000:0000:0000:0000:0000:0001"},
+		{"ip": "fe80:0000:0000:0000:0000:0000:0000:0001,0000:0000:0000:0000:0000:ffff:10.2.0.1"},
+		{"ip": ""},
+		{"other": "10.2.0.1"},
+	}
+
+	ops := []struct {
+		op    OpFactory
+		value interface{}
+	}{
+		{op: OpFactory(IPInCIDR), value: "10.2.0.0/16"},
+		{op: OpFactory(IPInCIDR), value: []interface{}{"192.168.1.0/24", "fe80::/64"}},
+		{op: OpFactory(IPInCIDR), value: "::ffff:10.2.0.0/112"},
+		{op: OpFactory(IPNotInCIDR), value: "10.2.0.0/16"},
+		{op: OpFactory(IPNotInCIDR), value: []interface{}{"192.168.0.0/16", "::/64"}},
+		{op: OpFactory(IPRange), value: []interface{}{"10.2.0.0", "10.2.0.5"}},
+		{op: OpFactory(IPRange), value: []interface{}{"172.16.0.0", "192.168.1.1"}},
+		{op: OpFactory(IPRange), value: []interface{}{"::", "::2"}},
+	}
+
+	for _, op := range ops {
+		operator := op.op.Operator()
+		cond, err := operator.ToMgo("ip", op.value)
+		if err != nil {
+			t.Errorf("convert %s %v to mongo condition failed, err: %v", op.op, op.value, err)
+			continue
+		}
+
+		for _, doc := range docs {
+			matched, err := operator.Match("ip", op.value, doc)
+			if err != nil {
+				t.Errorf("match %s %v with %v failed, err: %v", op.op, op.value, doc, err)
+				continue
+			}
+
+			mgoMatched := mgoRegexMatch(t, cond["ip"], doc["ip"])
+			if matched != mgoMatched {
+				t.Errorf("%s %v with %v, match result is %v, but mongo condition %v result is %v", op.op, op.value,
+					doc, matched, cond, mgoMatched)
+			}
+		}
+	}
+}
+
+// mgoRegexMatch simulates how mongodb evaluates the $regex or $not $regex condition of a field, an array field
+// matches $regex if any of its element matches, and matches $not $regex if none of its element matches.
+func mgoRegexMatch(t *testing.T, cond interface{}, value interface{}) bool {
+	condMap := cond.(map[string]interface{})
+	if notCond, exists := condMap[common.BKDBNot]; exists {
+		return !mgoRegexMatch(t, notCond, value)
+	}
+
+	reg := regexp.MustCompile(condMap[common.BKDBLIKE].(string))
+	switch val := value.(type) {
+	case string:
+		return reg.MatchString(val)
+	case []interface{}:
+		for _, elem := range val {
+			if str, ok := elem.(string); ok && reg.MatchString(str) {
+				return true
+			}
+		}
+		return false
+	case nil:
+		return false
+	default:
+		t.Fatalf("unsupported field value type %T", value)
+		return false
+	}
+}
@@ -0,0 +1,301 @@
+/*
+ * Tencent is pleased to support the open source community by making
+ * 蓝鲸智云 - 配置平台 (BlueKing - Configuration System) available.
+ * Copyright (C) 2017 THL A29 Limited,
+ * a Tencent company. All rights reserved.
+ * Licensed under the MIT License (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at http://opensource.org/licenses/MIT
+ * Unless required by applicable law or agreed to in writing,
+ * software distributed under the License is distributed on
+ * an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND,
+ * either express or implied. See the License for the
+ * specific language governing permissions and limitations under the License.
+ * We undertake not to change the open source license (MIT license) applicable
+ * to the current version of the project delivered to anyone in the future.
+ */
+
+package filter
+
+import (
+	"fmt"
+	"reflect"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"configcenter/src/common/mapstr"
+	"configcenter/src/common/util"
+)
+
+// getFieldValues get the values of the field in the document, the field can be a nested field joined by '.'.
+// it follows the mongodb's path semantics, if an embedded field's value is an array, all the elements of the
+// array will be traversed, so a field may have multiple values. returns no values if the field does not exist.
+func getFieldValues(doc mapstr.MapStr, field string) []interface{} {
+	return lookupFieldValues(doc, strings.Split(field, "."))
+}
+
+func lookupFieldValues(val interface{}, paths []string) []interface{} {
+	if len(paths) == 0 {
+		return []interface{}{val}
+	}
+
+	if m, ok := toMap(val); ok {
+		sub, exists := m[paths[0]]
+		if !exists {
+			return nil
+		}
+		return lookupFieldValues(sub, paths[1:])
+	}
+
+	elements, ok := toSlice(val)
+	if !ok {
+		return nil
+	}
+
+	values := make([]interface{}, 0)
+
+	// the path can be an index of the array
+	if idx, err := strconv.Atoi(paths[0]); err == nil && idx >= 0 && idx < len(elements) {
+		values = append(values, lookupFieldValues(elements[idx], paths[1:])...)
+	}
+
+	// traverse the array's embedded documents
+	for _, element := range elements {
+		if _, isMap := toMap(element); !isMap {
+			continue
+		}
+		values = append(values, lookupFieldValues(element, paths)...)
+	}
+
+	return values
+}
+
+func toMap(val interface{}) (map[string]interface{}, bool) {
+	switch m := val.(type) {
+	case mapstr.MapStr:
+		return m, true
+	case map[string]interface{}:
+		return m, true
+	case nil:
+		return nil, false
+	}
+
+	value := reflect.ValueOf(val)
+	if value.Kind() != reflect.Map || value.Type().Key().Kind() != reflect.String {
+		return nil, false
+	}
+
+	m := make(map[string]interface{}, value.Len())
+	iter := value.MapRange()
+	for iter.Next() {
+		m[iter.Key().String()] = iter.Value().Interface()
+	}
+	return m, true
+}
+
+func toSlice(val interface{}) ([]interface{}, bool) {
+	switch s := val.(type) {
+	case []interface{}:
+		return s, true
+	case nil, string, []byte:
+		return nil, false
+	}
+
+	value := reflect.ValueOf(val)
+	if value.Kind() != reflect.Slice && value.Kind() != reflect.Array {
+		return nil, false
+	}
+
+	s := make([]interface{}, value.Len())
+	for i := 0; i < value.Len(); i++ {
+		s[i] = value.Index(i).Interface()
+	}
+	return s, true
+}
+
+// matchAny test if any of the field values or its array elements matches the match function, this is the same
+// as the way mongodb matches the array field using a scalar condition.
+func matchAny(values []interface{}, match func(v interface{}) bool) bool {
+	for _, value := range values {
+		if match(value) {
+			return true
+		}
+
+		elements, ok := toSlice(value)
+		if !ok {
+			continue
+		}
+
+		for _, element := range elements {
+			if match(element) {
+				return true
+			}
+		}
+	}
+
+	return false
+}
+
+// matchEqual test if the field values matches the mongodb's $eq condition.
+func matchEqual(values []interface{}, target interface{}) bool {
+	if target == nil {
+		// null value matches the field that does not exist or the field that is null
+		if len(values) == 0 {
+			return true
+		}
+		return matchAny(values, func(v interface{}) bool {
+			return v == nil
+		})
+	}
+
+	return matchAny(values, func(v interface{}) bool {
+		return isEqual(v, target)
+	})
+}
+
+// matchIn test if the field values matches the mongodb's $in condition.
+func matchIn(values []interface{}, target interface{}) (bool, error) {
+	targets, ok := toSlice(target)
+	if !ok {
+		return false, fmt.Errorf("value(%+v) is not an array", target)
+	}
+
+	for _, t := range targets {
+		if matchEqual(values, t) {
+			return true, nil
+		}
+	}
+
+	return false, nil
+}
+
+func isEqual(a, b interface{}) bool {
+	if a == nil || b == nil {
+		return a == nil && b == nil
+	}
+
+	if util.IsNumeric(a) && util.IsNumeric(b) {
+		result, ok := compareNumeric(a, b)
+		return ok && result == 0
+	}
+
+	aVal, bVal := reflect.ValueOf(a), reflect.ValueOf(b)
+	switch {
+	case aVal.Kind() == reflect.String && bVal.Kind() == reflect.String:
+		return aVal.String() == bVal.String()
+	case aVal.Kind() == reflect.Bool && bVal.Kind() == reflect.Bool:
+		return aVal.Bool() == bVal.Bool()
+	}
+
+	aTime, aOk := a.(time.Time)
+	bTime, bOk := b.(time.Time)
+	if aOk && bOk {
+		return aTime.Equal(bTime)
+	}
+
+	return reflect.DeepEqual(a, b)
+}
+
+// compareNumeric compares two numeric values, returns -1 if a < b, 0 if a == b, 1 if a > b.
+// returns false if any of the values is not numeric.
+func compareNumeric(a, b interface{}) (int, bool) {
+	if !util.IsNumeric(a) || !util.IsNumeric(b) {
+		return 0, false
+	}
+
+	aFloat, err := util.GetFloat64ByInterface(a)
+	if err != nil {
+		return 0, false
+	}
+
+	bFloat, err := util.GetFloat64ByInterface(b)
+	if err != nil {
+		return 0, false
+	}
+
+	switch {
+	case aFloat < bFloat:
+		return -1, true
+	case aFloat > bFloat:
+		return 1, true
+	default:
+		return 0, true
+	}
+}
+
+// matchNumeric test if any of the field values matches the numeric compare condition with the target value.
+func matchNumeric(values []interface{}, target interface{}, cmp func(result int) bool) (bool, error) {
+	if !util.IsNumeric(target) {
+		return false, fmt.Errorf("value(%+v) is not numeric", target)
+	}
+
+	return matchAny(values, func(v interface{}) bool {
+		result, ok := compareNumeric(v, target)
+		return ok && cmp(result)
+	}), nil
+}
+
+// matchDatetime test if any of the field values matches the datetime compare condition with the target value.
+// only the time type and the string of time type field values can be compared.
+func matchDatetime(values []interface{}, target interface{}, cmp func(result int) bool) (bool, error) {
+	targetTime, err := util.ConvToTime(target)
+	if err != nil {
+		return false, fmt.Errorf("convert value to time failed, err: %v", err)
+	}
+
+	return matchAny(values, func(v interface{}) bool {
+		switch v.(type) {
+		case time.Time, string:
+		default:
+			return false
+		}
+
+		t, err := util.ConvToTime(v)
+		if err != nil {
+			return false
+		}
+
+		switch {
+		case t.Before(targetTime):
+			return cmp(-1)
+		case t.After(targetTime):
+			return cmp(1)
+		default:
+			return cmp(0)
+		}
+	}), nil
+}
+
+// matchRegex test if any of the string field values matches the regular expression, the same as mongodb's $regex.
+func matchRegex(values []interface{}, pattern string, caseInsensitive bool) (bool, error) {
+	if caseInsensitive {
+		pattern = "(?i)" + pattern
+	}
+
+	reg, err := regexp.Compile(pattern)
+	if err != nil {
+		return false, fmt.Errorf("invalid regular expression %s, err: %v", pattern, err)
+	}
+
+	return matchAny(values, func(v interface{}) bool {
+		value := reflect.ValueOf(v)
+		if !value.IsValid() || value.Kind() != reflect.String {
+			return false
+		}
+		return reg.MatchString(value.String())
+	}), nil
+}
+
+// matchSize test if any of the field values is an array whose length matches the compare function.
+func matchSize(values []interface{}, cmp func(length int) bool) bool {
+	for _, value := range values {
+		elements, ok := toSlice(value)
+		if ok && cmp(len(elements)) {
+			return true
+		}
+	}
+
+	return false
+}
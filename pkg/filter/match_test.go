@@ -0,0 +1,485 @@
+/*
+ * Tencent is pleased to support the open source community by making
+ * 蓝鲸智云 - 配置平台 (BlueKing - Configuration System) available.
+ * Copyright (C) 2017 THL A29 Limited,
+ * a Tencent company. All rights reserved.
+ * Licensed under the MIT License (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at http://opensource.org/licenses/MIT
+ * Unless required by applicable law or agreed to in writing,
+ * software distributed under the License is distributed on
+ * an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND,
+ * either express or implied. See the License for the
+ * specific language governing permissions and limitations under the License.
+ * We undertake not to change the open source license (MIT license) applicable
+ * to the current version of the project delivered to anyone in the future.
+ */
+
+package filter
+
+import (
+	"fmt"
+	"reflect"
+	"regexp"
+	"strings"
+	"testing"
+	"time"
+
+	"configcenter/src/common"
+	"configcenter/src/common/mapstr"
+	"configcenter/src/common/util"
+)
+
+var (
+	matchTestTime = time.Date(2023, 6, 1, 12, 0, 0, 0, time.Local)
+
+	matchTestDocs = []mapstr.MapStr{
+		{
+			"name":        "Host-01",
+			"count":       10,
+			"score":       3.5,
+			"enabled":     true,
+			"null_field":  nil,
+			"tags":        []interface{}{"web", "db"},
+			"nums":        []interface{}{1, 5, 9},
+			"empty":       []interface{}{},
+			"create_time": matchTestTime,
+			"obj": mapstr.MapStr{
+				"sub":    "Value",
+				"num":    3,
+				"nested": map[string]interface{}{"x": int64(1)},
+			},
+			"list": []interface{}{
+				mapstr.MapStr{"id": 1, "name": "a"},
+				mapstr.MapStr{"id": 2, "name": "b"},
+			},
+			"bk_host_innerip":    []string{"10.2.3.4", "192.168.1.10"},
+			"bk_host_innerip_v6": []string{"fe80:0000:0000:0000:0000:0000:0000:0001"},
+		},
+		{
+			"name":               "db-server",
+			"count":              int64(20),
+			"enabled":            false,
+			"tags":               []string{"cache"},
+			"nums":               []int64{},
+			"create_time":        matchTestTime.Add(time.Hour),
+			"obj":                map[string]interface{}{"sub": "other"},
+			"list":               []interface{}{map[string]interface{}{"id": 3}},
+			"bk_host_innerip":    []interface{}{"10.3.0.1"},
+			"bk_host_innerip_v6": []interface{}{"0000:0000:0000:0000:0000:ffff:10.2.0.1"},
+		},
+	}
+)
+
+type matchTestCase struct {
+	rule RuleFactory
+	// expects is the expected match result of each of the matchTestDocs
+	expects []bool
+}
+
+func atom(field string, op OpType, value interface{}) *AtomRule {
+	return &AtomRule{Field: field, Operator: op.Factory(), Value: value}
+}
+
+func getMatchTestCases() []matchTestCase {
+	return []matchTestCase{
+		// generic operator
+		{rule: atom("name", Equal, "Host-01"), expects: []bool{true, false}},
+		{rule: atom("count", Equal, 20), expects: []bool{false, true}},
+		{rule: atom("score", Equal, 3.5), expects: []bool{true, false}},
+		{rule: atom("enabled", Equal, false), expects: []bool{false, true}},
+		{rule: atom("tags", Equal, "db"), expects: []bool{true, false}},
+		{rule: atom("not_exist", Equal, "a"), expects: []bool{false, false}},
+		{rule: atom("name", NotEqual, "Host-01"), expects: []bool{false, true}},
+		{rule: atom("tags", NotEqual, "cache"), expects: []bool{true, false}},
+		{rule: atom("score", NotEqual, 1), expects: []bool{true, true}},
+
+		// set operator
+		{rule: atom("name", In, []interface{}{"Host-01", "x"}), expects: []bool{true, false}},
+		{rule: atom("count", In, []int64{10, 20}), expects: []bool{true, true}},
+		{rule: atom("tags", In, []string{"cache", "web"}), expects: []bool{true, true}},
+		{rule: atom("name", NotIn, []string{"Host-01"}), expects: []bool{false, true}},
+		{rule: atom("score", NotIn, []interface{}{1, 2}), expects: []bool{true, true}},
+
+		// numeric compare operator
+		{rule: atom("count", Less, 20), expects: []bool{true, false}},
+		{rule: atom("count", LessOrEqual, 20), expects: []bool{true, true}},
+		{rule: atom("count", Greater, 10), expects: []bool{false, true}},
+		{rule: atom("count", GreaterOrEqual, 10.0), expects: []bool{true, true}},
+		{rule: atom("nums", Greater, 8), expects: []bool{true, false}},
+		{rule: atom("name", Less, 100), expects: []bool{false, false}},
+		{rule: atom("score", Less, 4), expects: []bool{true, false}},
+
+		// datetime operator
+		{rule: atom("create_time", DatetimeLess, matchTestTime.Add(time.Minute)), expects: []bool{true, false}},
+		{rule: atom("create_time", DatetimeLessOrEqual, matchTestTime), expects: []bool{true, false}},
+		{rule: atom("create_time", DatetimeGreater, matchTestTime.Unix()), expects: []bool{false, true}},
+		{rule: atom("create_time", DatetimeGreaterOrEqual, matchTestTime), expects: []bool{true, true}},
+		{rule: atom("count", DatetimeGreater, 1), expects: []bool{false, false}},
+
+		// string operator
+		{rule: atom("name", BeginsWith, "Host"), expects: []bool{true, false}},
+		{rule: atom("name", BeginsWith, "host"), expects: []bool{false, false}},
+		{rule: atom("name", BeginsWithInsensitive, "host"), expects: []bool{true, false}},
+		{rule: atom("name", NotBeginsWith, "Host"), expects: []bool{false, true}},
+		{rule: atom("name", NotBeginsWithInsensitive, "DB"), expects: []bool{true, false}},
+		{rule: atom("name", Contains, "SERVER"), expects: []bool{false, true}},
+		{rule: atom("name", ContainsSensitive, "SERVER"), expects: []bool{false, false}},
+		{rule: atom("name", ContainsSensitive, "st-0"), expects: []bool{true, false}},
+		{rule: atom("name", NotContains, "-"), expects: []bool{false, false}},
+		{rule: atom("name", NotContainsInsensitive, "HOST"), expects: []bool{false, true}},
+		{rule: atom("name", EndsWith, "01"), expects: []bool{true, false}},
+		{rule: atom("name", EndsWithInsensitive, "SERVER"), expects: []bool{false, true}},
+		{rule: atom("name", NotEndsWith, "server"), expects: []bool{true, false}},
+		{rule: atom("name", NotEndsWithInsensitive, "-SERVER"), expects: []bool{true, false}},
+		{rule: atom("tags", BeginsWith, "ca"), expects: []bool{false, true}},
+		{rule: atom("not_exist", NotContains, "a"), expects: []bool{true, true}},
+		{rule: atom("count", Contains, "1"), expects: []bool{false, false}},
+
+		// array operator
+		{rule: atom("empty", IsEmpty, ""), expects: []bool{true, false}},
+		{rule: atom("nums", IsEmpty, ""), expects: []bool{false, true}},
+		{rule: atom("nums", IsNotEmpty, ""), expects: []bool{true, false}},
+		{rule: atom("name", IsNotEmpty, ""), expects: []bool{false, false}},
+		{rule: atom("tags", Size, 2), expects: []bool{true, false}},
+		{rule: atom("tags", Size, 1), expects: []bool{false, true}},
+
+		// null check operator
+		{rule: atom("null_field", IsNull, ""), expects: []bool{true, true}},
+		{rule: atom("score", IsNull, ""), expects: []bool{false, true}},
+		{rule: atom("null_field", IsNotNull, ""), expects: []bool{false, false}},
+		{rule: atom("score", IsNotNull, ""), expects: []bool{true, false}},
+
+		// existence check operator
+		{rule: atom("null_field", Exist, ""), expects: []bool{true, false}},
+		{rule: atom("obj.nested.x", Exist, ""), expects: []bool{true, false}},
+		{rule: atom("list.name", Exist, ""), expects: []bool{true, false}},
+		{rule: atom("score", NotExist, ""), expects: []bool{false, true}},
+
+		// embedded field
+		{rule: atom("obj.sub", Equal, "other"), expects: []bool{false, true}},
+		{rule: atom("list.id", Equal, 3), expects: []bool{false, true}},
+		{rule: atom("list.1.id", Equal, 2), expects: []bool{true, false}},
+
+		// filter embedded elements operator
+		{rule: atom("obj", Object, atom("sub", Equal, "Value")), expects: []bool{true, false}},
+		{rule: atom("obj", Object, atom("nested", Object, atom("x", Equal, 1))), expects: []bool{true, false}},
+		{rule: atom("obj", Object, &CombinedRule{Condition: Or, Rules: []RuleFactory{
+			atom("num", Greater, 2), atom("sub", Equal, "other")}}), expects: []bool{true, true}},
+		{rule: atom("tags", Array, atom(ArrayElement, Equal, "cache")), expects: []bool{false, true}},
+		{rule: atom("nums", Array, atom(ArrayElement, GreaterOrEqual, 9)), expects: []bool{true, false}},
+		{rule: atom("list", Array, atom(ArrayElement, Object, atom("id", In, []int{2, 3}))), expects: []bool{true, true}},
+		{rule: atom("list", Array, atom(ArrayElement, Object, atom("id", Greater, 2))), expects: []bool{false, true}},
+
+		// ip operator
+		{rule: atom("bk_host_innerip", IPInCIDR, "10.2.0.0/16"), expects: []bool{true, false}},
+		{rule: atom("bk_host_innerip", IPInCIDR, []string{"10.3.0.0/24", "1.1.1.1/32"}), expects: []bool{false, true}},
+		{rule: atom("bk_host_innerip", IPInCIDR, "10.0.0.0/8"), expects: []bool{true, true}},
+		{rule: atom("bk_host_innerip", IPInCIDR, "192.168.1.8/29"), expects: []bool{true, false}},
+		{rule: atom("bk_host_innerip", IPNotInCIDR, "192.168.0.0/16"), expects: []bool{false, true}},
+		{rule: atom("bk_host_innerip", IPRange, []string{"10.2.3.0", "10.2.3.4"}), expects: []bool{true, false}},
+		{rule: atom("bk_host_innerip", IPRange, []string{"10.2.3.5", "10.3.0.1"}), expects: []bool{false, true}},
+		{rule: atom("bk_host_innerip_v6", IPInCIDR, "fe80::/10"), expects: []bool{true, false}},
+		{rule: atom("bk_host_innerip_v6", IPInCIDR, "::ffff:10.2.0.0/112"), expects: []bool{false, true}},
+		{rule: atom("bk_host_innerip_v6", IPInCIDR, "10.2.0.0/16"), expects: []bool{false, false}},
+		{rule: atom("bk_host_innerip_v6", IPNotInCIDR, "::/0"), expects: []bool{false, false}},
+		{rule: atom("bk_host_innerip_v6", IPRange, []string{"::ffff:10.1.255.255", "::ffff:10.2.0.1"}),
+			expects: []bool{false, true}},
+		{rule: atom("bk_host_innerip_v6", IPRange, []string{"fe80::", "fe80::1"}), expects: []bool{true, false}},
+
+		// combined rule
+		{rule: &CombinedRule{Condition: And, Rules: []RuleFactory{
+			atom("count", Greater, 5), atom("name", BeginsWithInsensitive, "db")}}, expects: []bool{false, true}},
+		{rule: &CombinedRule{Condition: Or, Rules: []RuleFactory{
+			atom("enabled", Equal, true), atom("tags", Size, 1)}}, expects: []bool{true, true}},
+		{rule: &CombinedRule{Condition: And, Rules: []RuleFactory{
+			atom("count", Less, 100), &CombinedRule{Condition: Or, Rules: []RuleFactory{
+				atom("score", Exist, ""), atom("obj.sub", Equal, "nothing")}}}}, expects: []bool{true, false}},
+	}
+}
+
+// TestMatchConformance test that the Match result is the same as the result of the mongo condition generated by ToMgo.
+func TestMatchConformance(t *testing.T) {
+	for idx, c := range getMatchTestCases() {
+		cond, err := c.rule.ToMgo()
+		if err != nil {
+			t.Errorf("case %d rule to mongo failed, err: %v", idx, err)
+			return
+		}
+
+		for docIdx, doc := range matchTestDocs {
+			matched, err := c.rule.Match(doc)
+			if err != nil {
+				t.Errorf("case %d match doc %d failed, err: %v", idx, docIdx, err)
+				return
+			}
+
+			mgoMatched, err := evalMgoCond(cond, doc)
+			if err != nil {
+				t.Errorf("case %d eval mongo condition %+v on doc %d failed, err: %v", idx, cond, docIdx, err)
+				return
+			}
+
+			if matched != mgoMatched {
+				t.Errorf("case %d doc %d, match result %v is not the same as mongo condition %+v result %v", idx,
+					docIdx, matched, cond, mgoMatched)
+				return
+			}
+
+			if matched != c.expects[docIdx] {
+				t.Errorf("case %d doc %d, match result %v is not as expected", idx, docIdx, matched)
+				return
+			}
+		}
+	}
+}
+
+func TestExpressionMatch(t *testing.T) {
+	exp := &Expression{RuleFactory: &CombinedRule{
+		Condition: And,
+		Rules: []RuleFactory{
+			atom("bk_host_innerip", ContainsSensitive, "127.0"),
+			atom("create_time", DatetimeGreater, "2023-01-01 00:00:00"),
+		},
+	}}
+
+	matched, err := exp.Match(mapstr.MapStr{"bk_host_innerip": "127.0.0.1", "create_time": "2023-06-01 12:00:00"})
+	if err != nil {
+		t.Errorf("match failed, err: %v", err)
+		return
+	}
+
+	if !matched {
+		t.Errorf("expression should match the document")
+		return
+	}
+
+	if _, err = new(Expression).Match(mapstr.MapStr{}); err == nil {
+		t.Errorf("match nil expression should return error")
+		return
+	}
+
+	invalid := atom("field", "invalid", 1)
+	if _, err = invalid.Match(mapstr.MapStr{}); err == nil {
+		t.Errorf("match invalid operator should return error")
+		return
+	}
+
+	if _, err = atom("list", Array, atom("id", Equal, 1)).Match(mapstr.MapStr{}); err == nil {
+		t.Errorf("match invalid filter array field should return error")
+		return
+	}
+}
+
+// evalMgoCond is a simple mongodb query evaluator used to test the mongo condition generated by ToMgo.
+func evalMgoCond(cond map[string]interface{}, doc mapstr.MapStr) (bool, error) {
+	for key, value := range cond {
+		switch key {
+		case common.BKDBAND, common.BKDBOR:
+			subConds, ok := value.([]map[string]interface{})
+			if !ok {
+				return false, fmt.Errorf("invalid %s value %+v", key, value)
+			}
+
+			matchCnt := 0
+			for _, subCond := range subConds {
+				matched, err := evalMgoCond(subCond, doc)
+				if err != nil {
+					return false, err
+				}
+				if matched {
+					matchCnt++
+				}
+			}
+
+			if (key == common.BKDBAND && matchCnt != len(subConds)) || (key == common.BKDBOR && matchCnt == 0) {
+				return false, nil
+			}
+		default:
+			ops, ok := value.(map[string]interface{})
+			if !ok {
+				return false, fmt.Errorf("invalid field %s condition %+v", key, value)
+			}
+
+			matched, err := evalMgoFieldOps(mgoLookup(doc, strings.Split(key, ".")), ops)
+			if err != nil {
+				return false, err
+			}
+			if !matched {
+				return false, nil
+			}
+		}
+	}
+
+	return true, nil
+}
+
+func evalMgoFieldOps(values []interface{}, ops map[string]interface{}) (bool, error) {
+	for op, target := range ops {
+		var matched bool
+
+		switch op {
+		case common.BKDBEQ:
+			matched = mgoEqual(values, target)
+		case common.BKDBNE:
+			matched = !mgoEqual(values, target)
+		case common.BKDBIN, common.BKDBNIN:
+			targets := reflect.ValueOf(target)
+			for i := 0; i < targets.Len(); i++ {
+				if mgoEqual(values, targets.Index(i).Interface()) {
+					matched = true
+					break
+				}
+			}
+			matched = matched == (op == common.BKDBIN)
+		case common.BKDBLT, common.BKDBLTE, common.BKDBGT, common.BKDBGTE:
+			matched = mgoAny(values, func(v interface{}) bool {
+				return mgoCompare(op, v, target)
+			})
+		case common.BKDBLIKE:
+			pattern := target.(string)
+			if ops[common.BKDBOPTIONS] == "i" {
+				pattern = "(?i)" + pattern
+			}
+			reg := regexp.MustCompile(pattern)
+			matched = mgoAny(values, func(v interface{}) bool {
+				s, ok := v.(string)
+				return ok && reg.MatchString(s)
+			})
+		case common.BKDBOPTIONS:
+			continue
+		case common.BKDBNot:
+			subMatched, err := evalMgoFieldOps(values, target.(map[string]interface{}))
+			if err != nil {
+				return false, err
+			}
+			matched = !subMatched
+		case common.BKDBSize:
+			for _, v := range values {
+				arr := reflect.ValueOf(v)
+				if !arr.IsValid() || arr.Kind() != reflect.Slice {
+					continue
+				}
+
+				if sizeOps, ok := target.(map[string]interface{}); ok {
+					matched = mgoCompare(common.BKDBGT, arr.Len(), sizeOps[common.BKDBGT])
+				} else {
+					matched = mgoCompare(common.BKDBEQ, arr.Len(), target)
+				}
+
+				if matched {
+					break
+				}
+			}
+		case common.BKDBExists:
+			matched = (len(values) > 0) == target.(bool)
+		default:
+			return false, fmt.Errorf("unsupported mongo operator %s", op)
+		}
+
+		if !matched {
+			return false, nil
+		}
+	}
+
+	return true, nil
+}
+
+func mgoLookup(val interface{}, paths []string) []interface{} {
+	if len(paths) == 0 {
+		return []interface{}{val}
+	}
+
+	switch v := val.(type) {
+	case mapstr.MapStr:
+		return mgoLookup(map[string]interface{}(v), paths)
+	case map[string]interface{}:
+		sub, exists := v[paths[0]]
+		if !exists {
+			return nil
+		}
+		return mgoLookup(sub, paths[1:])
+	case []interface{}:
+		values := make([]interface{}, 0)
+		for idx, elem := range v {
+			if fmt.Sprint(idx) == paths[0] {
+				values = append(values, mgoLookup(elem, paths[1:])...)
+			}
+			values = append(values, mgoLookup(elem, paths)...)
+		}
+		return values
+	}
+
+	return nil
+}
+
+func mgoAny(values []interface{}, match func(v interface{}) bool) bool {
+	for _, value := range values {
+		if match(value) {
+			return true
+		}
+
+		arr := reflect.ValueOf(value)
+		if !arr.IsValid() || arr.Kind() != reflect.Slice {
+			continue
+		}
+
+		for i := 0; i < arr.Len(); i++ {
+			if match(arr.Index(i).Interface()) {
+				return true
+			}
+		}
+	}
+
+	return false
+}
+
+func mgoEqual(values []interface{}, target interface{}) bool {
+	if target == nil && len(values) == 0 {
+		return true
+	}
+
+	return mgoAny(values, func(v interface{}) bool {
+		if target == nil || v == nil {
+			return target == v
+		}
+
+		if util.IsNumeric(v) && util.IsNumeric(target) {
+			return mgoCompare(common.BKDBEQ, v, target)
+		}
+
+		return v == target
+	})
+}
+
+func mgoCompare(op string, a, b interface{}) bool {
+	var result float64
+	aTime, aOk := a.(time.Time)
+	bTime, bOk := b.(time.Time)
+
+	switch {
+	case aOk && bOk:
+		result = float64(aTime.Sub(bTime))
+	case util.IsNumeric(a) && util.IsNumeric(b):
+		aFloat, _ := util.GetFloat64ByInterface(a)
+		bFloat, _ := util.GetFloat64ByInterface(b)
+		result = aFloat - bFloat
+	default:
+		return false
+	}
+
+	switch op {
+	case common.BKDBEQ:
+		return result == 0
+	case common.BKDBLT:
+		return result < 0
+	case common.BKDBLTE:
+		return result <= 0
+	case common.BKDBGT:
+		return result > 0
+	case common.BKDBGTE:
+		return result >= 0
+	}
+
+	return false
+}
@@ -18,8 +18,10 @@
 package filter
 
 import (
+	"bytes"
 	"errors"
 	"fmt"
+	"net"
 
 	"configcenter/src/common"
 	"configcenter/src/common/criteria/enumor"
@@ -99,6 +101,12 @@ func init() {
 	opFactory[OpFactory(obj.Name())] = &obj
 	filterArr := ArrayOp(Array)
 	opFactory[OpFactory(filterArr.Name())] = &filterArr
+	ipInCIDR := IPInCIDROp(IPInCIDR)
+	opFactory[OpFactory(ipInCIDR.Name())] = &ipInCIDR
+	ipNotInCIDR := IPNotInCIDROp(IPNotInCIDR)
+	opFactory[OpFactory(ipNotInCIDR.Name())] = &ipNotInCIDR
+	ipRange := IPRangeOp(IPRange)
+	opFactory[OpFactory(ipRange.Name())] = &ipRange
 }
 
 const (
@@ -239,6 +247,15 @@ const (
 	Object OpType = "filter_object"
 	// Array filter array elements operator
 	Array OpType = "filter_array"
+
+	// ip operator, the field value can be an ip, a comma separated multiple ip string or an array of them
+
+	// IPInCIDR operator that matches the ip in any of the cidrs
+	IPInCIDR OpType = "ip_in_cidr"
+	// IPNotInCIDR operator that matches if none of the ips are in the cidrs
+	IPNotInCIDR OpType = "ip_not_in_cidr"
+	// IPRange operator that matches the ip in the range of start ip and end ip
+	IPRange OpType = "ip_range"
 )
 
 // OpType defines the operators supported by cc.
@@ -251,7 +268,7 @@ func (op OpType) Validate() error {
 		DatetimeGreater, DatetimeGreaterOrEqual, BeginsWith, BeginsWithInsensitive, NotBeginsWith,
 		NotBeginsWithInsensitive, Contains, ContainsSensitive, NotContains, NotContainsInsensitive, EndsWith,
 		EndsWithInsensitive, NotEndsWith, NotEndsWithInsensitive, IsEmpty, IsNotEmpty, Size, IsNull,
-		IsNotNull, Exist, NotExist, Object, Array:
+		IsNotNull, Exist, NotExist, Object, Array, IPInCIDR, IPNotInCIDR, IPRange:
 	default:
 		return fmt.Errorf("unsupported operator: %s", op)
 	}
@@ -272,6 +289,9 @@ type Operator interface {
 	ValidateValue(v interface{}, opt *ExprOption) error
 	// ToMgo generate an operator's mongo condition with its field and value.
 	ToMgo(field string, value interface{}) (map[string]interface{}, error)
+	// Match test if the document matches this operator's field and value, the result must be the same as
+	// the result of querying mongodb with the condition generated by ToMgo.
+	Match(field string, value interface{}, doc mapstr.MapStr) (bool, error)
 }
 
 // UnknownOp is unknown operator
@@ -292,6 +312,11 @@ func (o UnknownOp) ToMgo(_ string, _ interface{}) (map[string]interface{}, error
 	return nil, errors.New("unknown operator, can not gen mongo expression")
 }
 
+// Match test if the document matches this operator's field and value.
+func (o UnknownOp) Match(_ string, _ interface{}, _ mapstr.MapStr) (bool, error) {
+	return false, errors.New("unknown operator, can not match the document")
+}
+
 // EqualOp is equal operator type
 type EqualOp OpType
 
@@ -319,6 +344,15 @@ func (o EqualOp) ToMgo(field string, value interface{}) (map[string]interface{},
 	}, nil
 }
 
+// Match test if the document matches the equal operator's field and value.
+func (o EqualOp) Match(field string, value interface{}, doc mapstr.MapStr) (bool, error) {
+	if len(field) == 0 {
+		return false, errors.New("field is empty")
+	}
+
+	return matchEqual(getFieldValues(doc, field), value), nil
+}
+
 // NotEqualOp is not equal operator type
 type NotEqualOp OpType
 
@@ -346,6 +380,15 @@ func (ne NotEqualOp) ToMgo(field string, value interface{}) (map[string]interfac
 	}, nil
 }
 
+// Match test if the document matches the not equal operator's field and value.
+func (ne NotEqualOp) Match(field string, value interface{}, doc mapstr.MapStr) (bool, error) {
+	if len(field) == 0 {
+		return false, errors.New("field is empty")
+	}
+
+	return !matchEqual(getFieldValues(doc, field), value), nil
+}
+
 // InOp is in operator
 type InOp OpType
 
@@ -379,6 +422,15 @@ func (o InOp) ToMgo(field string, value interface{}) (map[string]interface{}, er
 	}, nil
 }
 
+// Match test if the document matches the in operator's field and value.
+func (o InOp) Match(field string, value interface{}, doc mapstr.MapStr) (bool, error) {
+	if len(field) == 0 {
+		return false, errors.New("field is empty")
+	}
+
+	return matchIn(getFieldValues(doc, field), value)
+}
+
 // NotInOp is not in operator
 type NotInOp OpType
 
@@ -412,6 +464,19 @@ func (o NotInOp) ToMgo(field string, value interface{}) (map[string]interface{},
 	}, nil
 }
 
+// Match test if the document matches the not in operator's field and value.
+func (o NotInOp) Match(field string, value interface{}, doc mapstr.MapStr) (bool, error) {
+	if len(field) == 0 {
+		return false, errors.New("field is empty")
+	}
+
+	matched, err := matchIn(getFieldValues(doc, field), value)
+	if err != nil {
+		return false, err
+	}
+	return !matched, nil
+}
+
 // LessOp is less than operator
 type LessOp OpType
 
@@ -439,6 +504,17 @@ func (o LessOp) ToMgo(field string, value interface{}) (map[string]interface{},
 	}, nil
 }
 
+// Match test if the document matches the less than operator's field and value.
+func (o LessOp) Match(field string, value interface{}, doc mapstr.MapStr) (bool, error) {
+	if len(field) == 0 {
+		return false, errors.New("field is empty")
+	}
+
+	return matchNumeric(getFieldValues(doc, field), value, func(result int) bool {
+		return result < 0
+	})
+}
+
 // LessOrEqualOp is less than or equal operator
 type LessOrEqualOp OpType
 
@@ -466,6 +542,17 @@ func (o LessOrEqualOp) ToMgo(field string, value interface{}) (map[string]interf
 	}, nil
 }
 
+// Match test if the document matches the less than or equal operator's field and value.
+func (o LessOrEqualOp) Match(field string, value interface{}, doc mapstr.MapStr) (bool, error) {
+	if len(field) == 0 {
+		return false, errors.New("field is empty")
+	}
+
+	return matchNumeric(getFieldValues(doc, field), value, func(result int) bool {
+		return result <= 0
+	})
+}
+
 // GreaterOp is greater than operator
 type GreaterOp OpType
 
@@ -493,6 +580,17 @@ func (o GreaterOp) ToMgo(field string, value interface{}) (map[string]interface{
 	}, nil
 }
 
+// Match test if the document matches the greater than operator's field and value.
+func (o GreaterOp) Match(field string, value interface{}, doc mapstr.MapStr) (bool, error) {
+	if len(field) == 0 {
+		return false, errors.New("field is empty")
+	}
+
+	return matchNumeric(getFieldValues(doc, field), value, func(result int) bool {
+		return result > 0
+	})
+}
+
 // GreaterOrEqualOp is greater than or equal operator
 type GreaterOrEqualOp OpType
 
@@ -520,6 +618,17 @@ func (o GreaterOrEqualOp) ToMgo(field string, value interface{}) (map[string]int
 	}, nil
 }
 
+// Match test if the document matches the greater than or equal operator's field and value.
+func (o GreaterOrEqualOp) Match(field string, value interface{}, doc mapstr.MapStr) (bool, error) {
+	if len(field) == 0 {
+		return false, errors.New("field is empty")
+	}
+
+	return matchNumeric(getFieldValues(doc, field), value, func(result int) bool {
+		return result >= 0
+	})
+}
+
 // DatetimeLessOp is datetime less than operator
 type DatetimeLessOp OpType
 
@@ -553,6 +662,17 @@ func (o DatetimeLessOp) ToMgo(field string, value interface{}) (map[string]inter
 	}, nil
 }
 
+// Match test if the document matches the datetime less than operator's field and value.
+func (o DatetimeLessOp) Match(field string, value interface{}, doc mapstr.MapStr) (bool, error) {
+	if len(field) == 0 {
+		return false, errors.New("field is empty")
+	}
+
+	return matchDatetime(getFieldValues(doc, field), value, func(result int) bool {
+		return result < 0
+	})
+}
+
 // DatetimeLessOrEqualOp is datetime less than or equal operator
 type DatetimeLessOrEqualOp OpType
 
@@ -586,6 +706,17 @@ func (o DatetimeLessOrEqualOp) ToMgo(field string, value interface{}) (map[strin
 	}, nil
 }
 
+// Match test if the document matches the datetime less than or equal operator's field and value.
+func (o DatetimeLessOrEqualOp) Match(field string, value interface{}, doc mapstr.MapStr) (bool, error) {
+	if len(field) == 0 {
+		return false, errors.New("field is empty")
+	}
+
+	return matchDatetime(getFieldValues(doc, field), value, func(result int) bool {
+		return result <= 0
+	})
+}
+
 // DatetimeGreaterOp is datetime greater than operator
 type DatetimeGreaterOp OpType
 
@@ -620,6 +751,17 @@ func (o DatetimeGreaterOp) ToMgo(field string, value interface{}) (map[string]in
 	}, nil
 }
 
+// Match test if the document matches the datetime greater than operator's field and value.
+func (o DatetimeGreaterOp) Match(field string, value interface{}, doc mapstr.MapStr) (bool, error) {
+	if len(field) == 0 {
+		return false, errors.New("field is empty")
+	}
+
+	return matchDatetime(getFieldValues(doc, field), value, func(result int) bool {
+		return result > 0
+	})
+}
+
 // DatetimeGreaterOrEqualOp is datetime greater than or equal operator
 type DatetimeGreaterOrEqualOp OpType
 
@@ -653,6 +795,17 @@ func (o DatetimeGreaterOrEqualOp) ToMgo(field string, value interface{}) (map[st
 	}, nil
 }
 
+// Match test if the document matches the datetime greater than or equal operator's field and value.
+func (o DatetimeGreaterOrEqualOp) Match(field string, value interface{}, doc mapstr.MapStr) (bool, error) {
+	if len(field) == 0 {
+		return false, errors.New("field is empty")
+	}
+
+	return matchDatetime(getFieldValues(doc, field), value, func(result int) bool {
+		return result >= 0
+	})
+}
+
 // BeginsWithOp is begins with operator
 type BeginsWithOp OpType
 
@@ -684,6 +837,15 @@ func (o BeginsWithOp) ToMgo(field string, value interface{}) (map[string]interfa
 	}, nil
 }
 
+// Match test if the document matches the begins with operator's field and value.
+func (o BeginsWithOp) Match(field string, value interface{}, doc mapstr.MapStr) (bool, error) {
+	if len(field) == 0 {
+		return false, errors.New("field is empty")
+	}
+
+	return matchRegex(getFieldValues(doc, field), fmt.Sprintf("^%s", value), false)
+}
+
 // BeginsWithInsensitiveOp is begins with insensitive operator
 type BeginsWithInsensitiveOp OpType
 
@@ -716,6 +878,15 @@ func (o BeginsWithInsensitiveOp) ToMgo(field string, value interface{}) (map[str
 	}, nil
 }
 
+// Match test if the document matches the begins with insensitive operator's field and value.
+func (o BeginsWithInsensitiveOp) Match(field string, value interface{}, doc mapstr.MapStr) (bool, error) {
+	if len(field) == 0 {
+		return false, errors.New("field is empty")
+	}
+
+	return matchRegex(getFieldValues(doc, field), fmt.Sprintf("^%s", value), true)
+}
+
 // NotBeginsWithOp is not begins with operator
 type NotBeginsWithOp OpType
 
@@ -747,6 +918,19 @@ func (o NotBeginsWithOp) ToMgo(field string, value interface{}) (map[string]inte
 	}, nil
 }
 
+// Match test if the document matches the not begins with operator's field and value.
+func (o NotBeginsWithOp) Match(field string, value interface{}, doc mapstr.MapStr) (bool, error) {
+	if len(field) == 0 {
+		return false, errors.New("field is empty")
+	}
+
+	matched, err := matchRegex(getFieldValues(doc, field), fmt.Sprintf("^%s", value), false)
+	if err != nil {
+		return false, err
+	}
+	return !matched, nil
+}
+
 // NotBeginsWithInsensitiveOp is not begins with insensitive operator
 type NotBeginsWithInsensitiveOp OpType
 
@@ -781,6 +965,19 @@ func (o NotBeginsWithInsensitiveOp) ToMgo(field string, value interface{}) (map[
 	}, nil
 }
 
+// Match test if the document matches the not begins with insensitive operator's field and value.
+func (o NotBeginsWithInsensitiveOp) Match(field string, value interface{}, doc mapstr.MapStr) (bool, error) {
+	if len(field) == 0 {
+		return false, errors.New("field is empty")
+	}
+
+	matched, err := matchRegex(getFieldValues(doc, field), fmt.Sprintf("^%s", value), true)
+	if err != nil {
+		return false, err
+	}
+	return !matched, nil
+}
+
 // ContainsOp is contains operator
 type ContainsOp OpType
 
@@ -813,6 +1010,15 @@ func (o ContainsOp) ToMgo(field string, value interface{}) (map[string]interface
 	}, nil
 }
 
+// Match test if the document matches the contains operator's field and value.
+func (o ContainsOp) Match(field string, value interface{}, doc mapstr.MapStr) (bool, error) {
+	if len(field) == 0 {
+		return false, errors.New("field is empty")
+	}
+
+	return matchRegex(getFieldValues(doc, field), util.GetStrByInterface(value), true)
+}
+
 // ContainsSensitiveOp is contains sensitive operator
 type ContainsSensitiveOp OpType
 
@@ -844,6 +1050,15 @@ func (o ContainsSensitiveOp) ToMgo(field string, value interface{}) (map[string]
 	}, nil
 }
 
+// Match test if the document matches the contains sensitive operator's field and value.
+func (o ContainsSensitiveOp) Match(field string, value interface{}, doc mapstr.MapStr) (bool, error) {
+	if len(field) == 0 {
+		return false, errors.New("field is empty")
+	}
+
+	return matchRegex(getFieldValues(doc, field), util.GetStrByInterface(value), false)
+}
+
 // NotContainsOp is not contains operator
 type NotContainsOp OpType
 
@@ -875,6 +1090,19 @@ func (o NotContainsOp) ToMgo(field string, value interface{}) (map[string]interf
 	}, nil
 }
 
+// Match test if the document matches the not contains operator's field and value.
+func (o NotContainsOp) Match(field string, value interface{}, doc mapstr.MapStr) (bool, error) {
+	if len(field) == 0 {
+		return false, errors.New("field is empty")
+	}
+
+	matched, err := matchRegex(getFieldValues(doc, field), util.GetStrByInterface(value), false)
+	if err != nil {
+		return false, err
+	}
+	return !matched, nil
+}
+
 // NotContainsInsensitiveOp is not contains insensitive operator
 type NotContainsInsensitiveOp OpType
 
@@ -909,6 +1137,19 @@ func (o NotContainsInsensitiveOp) ToMgo(field string, value interface{}) (map[st
 	}, nil
 }
 
+// Match test if the document matches the not contains insensitive operator's field and value.
+func (o NotContainsInsensitiveOp) Match(field string, value interface{}, doc mapstr.MapStr) (bool, error) {
+	if len(field) == 0 {
+		return false, errors.New("field is empty")
+	}
+
+	matched, err := matchRegex(getFieldValues(doc, field), util.GetStrByInterface(value), true)
+	if err != nil {
+		return false, err
+	}
+	return !matched, nil
+}
+
 // EndsWithOp is ends with operator
 type EndsWithOp OpType
 
@@ -940,6 +1181,15 @@ func (o EndsWithOp) ToMgo(field string, value interface{}) (map[string]interface
 	}, nil
 }
 
+// Match test if the document matches the ends with operator's field and value.
+func (o EndsWithOp) Match(field string, value interface{}, doc mapstr.MapStr) (bool, error) {
+	if len(field) == 0 {
+		return false, errors.New("field is empty")
+	}
+
+	return matchRegex(getFieldValues(doc, field), fmt.Sprintf("%s$", value), false)
+}
+
 // EndsWithInsensitiveOp is ends with insensitive operator
 type EndsWithInsensitiveOp OpType
 
@@ -972,6 +1222,15 @@ func (o EndsWithInsensitiveOp) ToMgo(field string, value interface{}) (map[strin
 	}, nil
 }
 
+// Match test if the document matches the ends with insensitive operator's field and value.
+func (o EndsWithInsensitiveOp) Match(field string, value interface{}, doc mapstr.MapStr) (bool, error) {
+	if len(field) == 0 {
+		return false, errors.New("field is empty")
+	}
+
+	return matchRegex(getFieldValues(doc, field), fmt.Sprintf("%s$", value), true)
+}
+
 // NotEndsWithOp is not ends with operator
 type NotEndsWithOp OpType
 
@@ -1003,6 +1262,19 @@ func (o NotEndsWithOp) ToMgo(field string, value interface{}) (map[string]interf
 	}, nil
 }
 
+// Match test if the document matches the not ends with operator's field and value.
+func (o NotEndsWithOp) Match(field string, value interface{}, doc mapstr.MapStr) (bool, error) {
+	if len(field) == 0 {
+		return false, errors.New("field is empty")
+	}
+
+	matched, err := matchRegex(getFieldValues(doc, field), fmt.Sprintf("%s$", value), false)
+	if err != nil {
+		return false, err
+	}
+	return !matched, nil
+}
+
 // NotEndsWithInsensitiveOp is not ends with insensitive operator
 type NotEndsWithInsensitiveOp OpType
 
@@ -1037,6 +1309,19 @@ func (o NotEndsWithInsensitiveOp) ToMgo(field string, value interface{}) (map[st
 	}, nil
 }
 
+// Match test if the document matches the not ends with insensitive operator's field and value.
+func (o NotEndsWithInsensitiveOp) Match(field string, value interface{}, doc mapstr.MapStr) (bool, error) {
+	if len(field) == 0 {
+		return false, errors.New("field is empty")
+	}
+
+	matched, err := matchRegex(getFieldValues(doc, field), fmt.Sprintf("%s$", value), true)
+	if err != nil {
+		return false, err
+	}
+	return !matched, nil
+}
+
 // IsEmptyOp is empty operator
 type IsEmptyOp OpType
 
@@ -1063,6 +1348,17 @@ func (o IsEmptyOp) ToMgo(field string, value interface{}) (map[string]interface{
 	}, nil
 }
 
+// Match test if the document matches the empty operator's field and value.
+func (o IsEmptyOp) Match(field string, value interface{}, doc mapstr.MapStr) (bool, error) {
+	if len(field) == 0 {
+		return false, errors.New("field is empty")
+	}
+
+	return matchSize(getFieldValues(doc, field), func(length int) bool {
+		return length == 0
+	}), nil
+}
+
 // IsNotEmptyOp is not empty operator
 type IsNotEmptyOp OpType
 
@@ -1089,6 +1385,17 @@ func (o IsNotEmptyOp) ToMgo(field string, value interface{}) (map[string]interfa
 	}, nil
 }
 
+// Match test if the document matches the is not empty operator's field and value.
+func (o IsNotEmptyOp) Match(field string, value interface{}, doc mapstr.MapStr) (bool, error) {
+	if len(field) == 0 {
+		return false, errors.New("field is empty")
+	}
+
+	return matchSize(getFieldValues(doc, field), func(length int) bool {
+		return length > 0
+	}), nil
+}
+
 // SizeOp size operator
 type SizeOp OpType
 
@@ -1123,6 +1430,22 @@ func (o SizeOp) ToMgo(field string, value interface{}) (map[string]interface{},
 	}, nil
 }
 
+// Match test if the document matches the size operator's field and value.
+func (o SizeOp) Match(field string, value interface{}, doc mapstr.MapStr) (bool, error) {
+	if len(field) == 0 {
+		return false, errors.New("field is empty")
+	}
+
+	size, err := util.GetInt64ByInterface(value)
+	if err != nil {
+		return false, fmt.Errorf("invalid size operator's value, err: %v", err)
+	}
+
+	return matchSize(getFieldValues(doc, field), func(length int) bool {
+		return int64(length) == size
+	}), nil
+}
+
 // IsNullOp is null operator
 type IsNullOp OpType
 
@@ -1149,6 +1472,15 @@ func (o IsNullOp) ToMgo(field string, value interface{}) (map[string]interface{}
 	}, nil
 }
 
+// Match test if the document matches the null operator's field and value.
+func (o IsNullOp) Match(field string, value interface{}, doc mapstr.MapStr) (bool, error) {
+	if len(field) == 0 {
+		return false, errors.New("field is empty")
+	}
+
+	return matchEqual(getFieldValues(doc, field), nil), nil
+}
+
 // IsNotNullOp is not null operator
 type IsNotNullOp OpType
 
@@ -1175,6 +1507,15 @@ func (o IsNotNullOp) ToMgo(field string, value interface{}) (map[string]interfac
 	}, nil
 }
 
+// Match test if the document matches the is not null operator's field and value.
+func (o IsNotNullOp) Match(field string, value interface{}, doc mapstr.MapStr) (bool, error) {
+	if len(field) == 0 {
+		return false, errors.New("field is empty")
+	}
+
+	return !matchEqual(getFieldValues(doc, field), nil), nil
+}
+
 // ExistOp is 'exist' operator
 type ExistOp OpType
 
@@ -1201,6 +1542,15 @@ func (o ExistOp) ToMgo(field string, value interface{}) (map[string]interface{},
 	}, nil
 }
 
+// Match test if the document matches the 'exist' operator's field and value.
+func (o ExistOp) Match(field string, value interface{}, doc mapstr.MapStr) (bool, error) {
+	if len(field) == 0 {
+		return false, errors.New("field is empty")
+	}
+
+	return len(getFieldValues(doc, field)) > 0, nil
+}
+
 // NotExistOp is not exist operator
 type NotExistOp OpType
 
@@ -1227,6 +1577,15 @@ func (o NotExistOp) ToMgo(field string, value interface{}) (map[string]interface
 	}, nil
 }
 
+// Match test if the document matches the is not exist operator's field and value.
+func (o NotExistOp) Match(field string, value interface{}, doc mapstr.MapStr) (bool, error) {
+	if len(field) == 0 {
+		return false, errors.New("field is empty")
+	}
+
+	return len(getFieldValues(doc, field)) == 0, nil
+}
+
 // ObjectOp is filter object operator
 type ObjectOp OpType
 
@@ -1280,6 +1639,25 @@ func (o ObjectOp) ToMgo(field string, value interface{}) (map[string]interface{}
 	return subRule.ToMgo(parentOpt)
 }
 
+// Match test if the document matches the filter object operator's field and value.
+func (o ObjectOp) Match(field string, value interface{}, doc mapstr.MapStr) (bool, error) {
+	if len(field) == 0 {
+		return false, errors.New("field is empty")
+	}
+
+	subRule, ok := value.(RuleFactory)
+	if !ok {
+		return false, fmt.Errorf("filter object operator's value(%+v) is not a rule type", value)
+	}
+
+	parentOpt := &RuleOption{
+		Parent:     field,
+		ParentType: enumor.Object,
+	}
+
+	return subRule.Match(doc, parentOpt)
+}
+
 const (
 	ArrayElement = "element"
 )
@@ -1338,3 +1716,198 @@ func (o ArrayOp) ToMgo(field string, value interface{}) (map[string]interface{},
 
 	return subRule.ToMgo(parentOpt)
 }
+
+// Match test if the document matches the filter array operator's field and value.
+func (o ArrayOp) Match(field string, value interface{}, doc mapstr.MapStr) (bool, error) {
+	if len(field) == 0 {
+		return false, errors.New("field is empty")
+	}
+
+	subRule, ok := value.(RuleFactory)
+	if !ok {
+		return false, fmt.Errorf("filter array operator's value(%+v) is not a rule type", value)
+	}
+
+	parentOpt := &RuleOption{
+		Parent:     field,
+		ParentType: enumor.Array,
+	}
+
+	return subRule.Match(doc, parentOpt)
+}
+
+// IPInCIDROp is ip in cidr operator
+type IPInCIDROp OpType
+
+// Name is ip in cidr operator name
+func (o IPInCIDROp) Name() OpType {
+	return IPInCIDR
+}
+
+// ValidateValue validate ip in cidr operator's value, it can be a cidr or an array of cidrs
+func (o IPInCIDROp) ValidateValue(v interface{}, opt *ExprOption) error {
+	if opt == nil {
+		return errors.New("validate option must be set")
+	}
+
+	if _, err := parseCIDRs(v, opt.MaxInLimit); err != nil {
+		return fmt.Errorf("ip in cidr operator's value is invalid, err: %v", err)
+	}
+
+	return nil
+}
+
+// ToMgo convert the ip in cidr operator's field and value to a mongo query condition.
+func (o IPInCIDROp) ToMgo(field string, value interface{}) (map[string]interface{}, error) {
+	if len(field) == 0 {
+		return nil, errors.New("field is empty")
+	}
+
+	networks, err := parseCIDRs(value, 0)
+	if err != nil {
+		return nil, err
+	}
+
+	pattern, err := networksToRegex(networks)
+	if err != nil {
+		return nil, err
+	}
+
+	return mapstr.MapStr{
+		field: map[string]interface{}{
+			common.BKDBLIKE: pattern,
+		},
+	}, nil
+}
+
+// Match test if the document matches the ip in cidr operator's field and value.
+func (o IPInCIDROp) Match(field string, value interface{}, doc mapstr.MapStr) (bool, error) {
+	if len(field) == 0 {
+		return false, errors.New("field is empty")
+	}
+
+	networks, err := parseCIDRs(value, 0)
+	if err != nil {
+		return false, err
+	}
+
+	return matchIPs(getFieldValues(doc, field), func(ip net.IP) bool {
+		for _, network := range networks {
+			if network.contains(ip) {
+				return true
+			}
+		}
+		return false
+	}), nil
+}
+
+// IPNotInCIDROp is ip not in cidr operator
+type IPNotInCIDROp OpType
+
+// Name is ip not in cidr operator name
+func (o IPNotInCIDROp) Name() OpType {
+	return IPNotInCIDR
+}
+
+// ValidateValue validate ip not in cidr operator's value, it can be a cidr or an array of cidrs
+func (o IPNotInCIDROp) ValidateValue(v interface{}, opt *ExprOption) error {
+	if opt == nil {
+		return errors.New("validate option must be set")
+	}
+
+	if _, err := parseCIDRs(v, opt.MaxNotInLimit); err != nil {
+		return fmt.Errorf("ip not in cidr operator's value is invalid, err: %v", err)
+	}
+
+	return nil
+}
+
+// ToMgo convert the ip not in cidr operator's field and value to a mongo query condition.
+func (o IPNotInCIDROp) ToMgo(field string, value interface{}) (map[string]interface{}, error) {
+	if len(field) == 0 {
+		return nil, errors.New("field is empty")
+	}
+
+	networks, err := parseCIDRs(value, 0)
+	if err != nil {
+		return nil, err
+	}
+
+	pattern, err := networksToRegex(networks)
+	if err != nil {
+		return nil, err
+	}
+
+	return mapstr.MapStr{
+		field: map[string]interface{}{
+			common.BKDBNot: map[string]interface{}{common.BKDBLIKE: pattern},
+		},
+	}, nil
+}
+
+// Match test if the document matches the ip not in cidr operator's field and value.
+func (o IPNotInCIDROp) Match(field string, value interface{}, doc mapstr.MapStr) (bool, error) {
+	in := IPInCIDROp(IPInCIDR)
+	matched, err := in.Match(field, value, doc)
+	if err != nil {
+		return false, err
+	}
+
+	return !matched, nil
+}
+
+// IPRangeOp is ip range operator
+type IPRangeOp OpType
+
+// Name is ip range operator name
+func (o IPRangeOp) Name() OpType {
+	return IPRange
+}
+
+// ValidateValue validate ip range operator's value, it should be an array of start ip and end ip
+func (o IPRangeOp) ValidateValue(v interface{}, opt *ExprOption) error {
+	if _, _, err := parseIPRange(v); err != nil {
+		return fmt.Errorf("ip range operator's value is invalid, err: %v", err)
+	}
+
+	return nil
+}
+
+// ToMgo convert the ip range operator's field and value to a mongo query condition.
+func (o IPRangeOp) ToMgo(field string, value interface{}) (map[string]interface{}, error) {
+	if len(field) == 0 {
+		return nil, errors.New("field is empty")
+	}
+
+	start, end, err := parseIPRange(value)
+	if err != nil {
+		return nil, err
+	}
+
+	pattern, err := networksToRegex(ipRangeToNetworks(start, end))
+	if err != nil {
+		return nil, err
+	}
+
+	return mapstr.MapStr{
+		field: map[string]interface{}{
+			common.BKDBLIKE: pattern,
+		},
+	}, nil
+}
+
+// Match test if the document matches the ip range operator's field and value.
+func (o IPRangeOp) Match(field string, value interface{}, doc mapstr.MapStr) (bool, error) {
+	if len(field) == 0 {
+		return false, errors.New("field is empty")
+	}
+
+	start, end, err := parseIPRange(value)
+	if err != nil {
+		return false, err
+	}
+
+	return matchIPs(getFieldValues(doc, field), func(ip net.IP) bool {
+		return len(ip) == len(start) && bytes.Compare(ip, start) >= 0 && bytes.Compare(ip, end) <= 0
+	}), nil
+}
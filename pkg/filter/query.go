@@ -0,0 +1,644 @@
+/*
+ * Tencent is pleased to support the open source community by making
+ * 蓝鲸智云 - 配置平台 (BlueKing - Configuration System) available.
+ * Copyright (C) 2017 THL A29 Limited,
+ * a Tencent company. All rights reserved.
+ * Licensed under the MIT License (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at http://opensource.org/licenses/MIT
+ * Unless required by applicable law or agreed to in writing,
+ * software distributed under the License is distributed on
+ * an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND,
+ * either express or implied. See the License for the
+ * specific language governing permissions and limitations under the License.
+ * We undertake not to change the open source license (MIT license) applicable
+ * to the current version of the project delivered to anyone in the future.
+ */
+
+package filter
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+	"unicode"
+
+	"configcenter/src/common"
+	"configcenter/src/common/util"
+)
+
+// ParseQuery parse the text form query into an expression, if the option is set, the expression is validated
+// using the option. the query syntax is like: bk_os_type = "1" AND (bk_host_innerip begins_with "10." OR
+// NOT bk_cloud_id in (0, 2)), see README.md for details.
+func ParseQuery(query string, opt *ExprOption) (*Expression, error) {
+	tokens, err := lexQuery(query)
+	if err != nil {
+		return nil, err
+	}
+
+	p := &queryParser{tokens: tokens}
+	rule, err := p.parseOr()
+	if err != nil {
+		return nil, err
+	}
+
+	if tok := p.peek(); tok.typ != eofToken {
+		return nil, tok.errorf("unexpected %s, expect AND, OR or end of query", tok)
+	}
+
+	exp := &Expression{RuleFactory: rule}
+	if opt != nil {
+		if err := exp.Validate(opt); err != nil {
+			return nil, err
+		}
+	}
+
+	return exp, nil
+}
+
+// QueryError is the error of parsing the text form query, it reports the position of the error.
+type QueryError struct {
+	Line   int
+	Column int
+	Msg    string
+}
+
+// Error returns the query error message with its position.
+func (e *QueryError) Error() string {
+	return fmt.Sprintf("line %d, column %d: %s", e.Line, e.Column, e.Msg)
+}
+
+type queryTokenType int
+
+const (
+	eofToken queryTokenType = iota
+	identToken
+	stringToken
+	numberToken
+	symbolToken
+)
+
+type queryToken struct {
+	typ    queryTokenType
+	text   string
+	line   int
+	column int
+}
+
+// String returns the token's description used in error messages.
+func (t queryToken) String() string {
+	if t.typ == eofToken {
+		return "end of query"
+	}
+	return strconv.Quote(t.text)
+}
+
+func (t queryToken) errorf(format string, args ...interface{}) error {
+	return &QueryError{Line: t.line, Column: t.column, Msg: fmt.Sprintf(format, args...)}
+}
+
+// isKeyword test if the token is the specified keyword, keywords are case-insensitive.
+func (t queryToken) isKeyword(keyword string) bool {
+	return t.typ == identToken && strings.EqualFold(t.text, keyword)
+}
+
+func (t queryToken) isSymbol(symbol string) bool {
+	return t.typ == symbolToken && t.text == symbol
+}
+
+const (
+	andKeyword   = "AND"
+	orKeyword    = "OR"
+	notKeyword   = "NOT"
+	trueKeyword  = "true"
+	falseKeyword = "false"
+	nullKeyword  = "null"
+)
+
+// querySymbolOps is the operators that can be written as symbols in the text form query.
+var querySymbolOps = map[string]OpType{
+	"=":  Equal,
+	"!=": NotEqual,
+	"<":  Less,
+	"<=": LessOrEqual,
+	">":  Greater,
+	">=": GreaterOrEqual,
+}
+
+func lexQuery(query string) ([]queryToken, error) {
+	runes := []rune(query)
+	tokens := make([]queryToken, 0)
+	line, column := 1, 1
+
+	for i := 0; i < len(runes); {
+		r := runes[i]
+		tok := queryToken{line: line, column: column}
+		start := i
+
+		switch {
+		case r == '\n':
+			i++
+			line++
+			column = 1
+			continue
+		case unicode.IsSpace(r):
+			i++
+			column++
+			continue
+		case r == '"':
+			i++
+			for ; i < len(runes) && runes[i] != '"'; i++ {
+				if runes[i] == '\\' {
+					i++
+				}
+				if i < len(runes) && runes[i] == '\n' {
+					return nil, tok.errorf("unterminated string")
+				}
+			}
+			if i >= len(runes) {
+				return nil, tok.errorf("unterminated string")
+			}
+			i++
+
+			str, err := strconv.Unquote(string(runes[start:i]))
+			if err != nil {
+				return nil, tok.errorf("invalid string %s, err: %v", string(runes[start:i]), err)
+			}
+			tok.typ, tok.text = stringToken, str
+		case unicode.IsDigit(r) || (r == '-' && i+1 < len(runes) && unicode.IsDigit(runes[i+1])):
+			i++
+			for ; i < len(runes) && isQueryNumberRune(runes[i], runes[i-1]); i++ {
+			}
+			tok.typ, tok.text = numberToken, string(runes[start:i])
+		case unicode.IsLetter(r) || r == '_':
+			for ; i < len(runes) && (unicode.IsLetter(runes[i]) || unicode.IsDigit(runes[i]) ||
+				runes[i] == '_' || runes[i] == '.'); i++ {
+			}
+			tok.typ, tok.text = identToken, string(runes[start:i])
+		case strings.ContainsRune("()[],", r):
+			i++
+			tok.typ, tok.text = symbolToken, string(r)
+		case strings.ContainsRune("=!<>", r):
+			i++
+			if i < len(runes) && runes[i] == '=' {
+				i++
+			}
+			tok.typ, tok.text = symbolToken, string(runes[start:i])
+			if _, exists := querySymbolOps[tok.text]; !exists {
+				return nil, tok.errorf("invalid operator %s", tok.text)
+			}
+		default:
+			return nil, tok.errorf("unexpected character %q", r)
+		}
+
+		column += i - start
+		tokens = append(tokens, tok)
+	}
+
+	tokens = append(tokens, queryToken{typ: eofToken, line: line, column: column})
+	return tokens, nil
+}
+
+func isQueryNumberRune(r, prev rune) bool {
+	switch {
+	case unicode.IsDigit(r), r == '.', r == 'e', r == 'E':
+		return true
+	case r == '+' || r == '-':
+		return prev == 'e' || prev == 'E'
+	}
+	return false
+}
+
+type queryParser struct {
+	tokens []queryToken
+	pos    int
+}
+
+func (p *queryParser) peek() queryToken {
+	return p.tokens[p.pos]
+}
+
+func (p *queryParser) next() queryToken {
+	tok := p.tokens[p.pos]
+	if tok.typ != eofToken {
+		p.pos++
+	}
+	return tok
+}
+
+func (p *queryParser) expectSymbol(symbol string) error {
+	tok := p.next()
+	if !tok.isSymbol(symbol) {
+		return tok.errorf("unexpected %s, expect %q", tok, symbol)
+	}
+	return nil
+}
+
+// parseOr parses: and_expr { OR and_expr }
+func (p *queryParser) parseOr() (RuleFactory, error) {
+	return p.parseCombined(Or, orKeyword, p.parseAnd)
+}
+
+// parseAnd parses: unary_expr { AND unary_expr }
+func (p *queryParser) parseAnd() (RuleFactory, error) {
+	return p.parseCombined(And, andKeyword, p.parseUnary)
+}
+
+func (p *queryParser) parseCombined(cond LogicOperator, keyword string,
+	parseSub func() (RuleFactory, error)) (RuleFactory, error) {
+
+	rules := make([]RuleFactory, 0)
+	for {
+		rule, err := parseSub()
+		if err != nil {
+			return nil, err
+		}
+
+		// flatten the rules with the same logic operator
+		if combined, ok := rule.(*CombinedRule); ok && combined.Condition == cond {
+			rules = append(rules, combined.Rules...)
+		} else {
+			rules = append(rules, rule)
+		}
+
+		if !p.peek().isKeyword(keyword) {
+			break
+		}
+		p.next()
+	}
+
+	if len(rules) == 1 {
+		return rules[0], nil
+	}
+
+	return &CombinedRule{Condition: cond, Rules: rules}, nil
+}
+
+// parseUnary parses: NOT unary_expr | primary_expr
+func (p *queryParser) parseUnary() (RuleFactory, error) {
+	tok := p.peek()
+	if !tok.isKeyword(notKeyword) {
+		return p.parsePrimary()
+	}
+	p.next()
+
+	rule, err := p.parseUnary()
+	if err != nil {
+		return nil, err
+	}
+
+	negated, err := negateRule(rule)
+	if err != nil {
+		return nil, tok.errorf("%v", err)
+	}
+	return negated, nil
+}
+
+// parsePrimary parses: ( or_expr ) | atom_expr
+func (p *queryParser) parsePrimary() (RuleFactory, error) {
+	if !p.peek().isSymbol("(") {
+		return p.parseAtom()
+	}
+	p.next()
+
+	rule, err := p.parseOr()
+	if err != nil {
+		return nil, err
+	}
+
+	if err := p.expectSymbol(")"); err != nil {
+		return nil, err
+	}
+	return rule, nil
+}
+
+// parseAtom parses: field operator [value]
+func (p *queryParser) parseAtom() (RuleFactory, error) {
+	fieldTok := p.next()
+	if fieldTok.typ != identToken || isQueryKeyword(fieldTok) {
+		return nil, fieldTok.errorf("unexpected %s, expect a field name", fieldTok)
+	}
+
+	opTok := p.next()
+	var op OpType
+	switch opTok.typ {
+	case symbolToken:
+		symbolOp, exists := querySymbolOps[opTok.text]
+		if !exists {
+			return nil, opTok.errorf("unexpected %s, expect an operator", opTok)
+		}
+		op = symbolOp
+	case identToken:
+		op = OpType(strings.ToLower(opTok.text))
+		if err := op.Validate(); err != nil {
+			return nil, opTok.errorf("%v", err)
+		}
+	default:
+		return nil, opTok.errorf("unexpected %s, expect an operator", opTok)
+	}
+
+	rule := &AtomRule{Field: fieldTok.text, Operator: op.Factory()}
+
+	var err error
+	switch op {
+	case In, NotIn, IPRange:
+		rule.Value, err = p.parseList()
+	case IPInCIDR, IPNotInCIDR:
+		// ip cidr operator's value can be a cidr or a list of cidrs
+		if p.peek().isSymbol("(") || p.peek().isSymbol("[") {
+			rule.Value, err = p.parseList()
+		} else {
+			rule.Value, err = p.parseScalar()
+		}
+	case IsEmpty, IsNotEmpty, IsNull, IsNotNull, Exist, NotExist:
+		// these operators do not need a value, but the atom rule's value can not be nil
+		rule.Value = ""
+	case Object, Array:
+		if err = p.expectSymbol("("); err != nil {
+			return nil, err
+		}
+		if rule.Value, err = p.parseOr(); err != nil {
+			return nil, err
+		}
+		err = p.expectSymbol(")")
+	default:
+		rule.Value, err = p.parseScalar()
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return rule, nil
+}
+
+// parseList parses: ( value {, value} ) | [ value {, value} ]
+func (p *queryParser) parseList() ([]interface{}, error) {
+	tok := p.next()
+	var end string
+	switch {
+	case tok.isSymbol("("):
+		end = ")"
+	case tok.isSymbol("["):
+		end = "]"
+	default:
+		return nil, tok.errorf("unexpected %s, expect a value list", tok)
+	}
+
+	values := make([]interface{}, 0)
+	if p.peek().isSymbol(end) {
+		p.next()
+		return values, nil
+	}
+
+	for {
+		value, err := p.parseScalar()
+		if err != nil {
+			return nil, err
+		}
+		values = append(values, value)
+
+		tok = p.next()
+		if tok.isSymbol(end) {
+			return values, nil
+		}
+		if !tok.isSymbol(",") {
+			return nil, tok.errorf("unexpected %s, expect \",\" or %q", tok, end)
+		}
+	}
+}
+
+// parseScalar parses a string, number, boolean or null value.
+func (p *queryParser) parseScalar() (interface{}, error) {
+	tok := p.next()
+	switch tok.typ {
+	case stringToken:
+		return tok.text, nil
+	case numberToken:
+		if !strings.ContainsAny(tok.text, ".eE") {
+			if intVal, err := strconv.ParseInt(tok.text, 10, 64); err == nil {
+				return intVal, nil
+			}
+		}
+
+		floatVal, err := strconv.ParseFloat(tok.text, 64)
+		if err != nil {
+			return nil, tok.errorf("invalid number %s", tok.text)
+		}
+		return floatVal, nil
+	case identToken:
+		switch {
+		case tok.isKeyword(trueKeyword):
+			return true, nil
+		case tok.isKeyword(falseKeyword):
+			return false, nil
+		case tok.isKeyword(nullKeyword):
+			return nil, nil
+		}
+	}
+
+	return nil, tok.errorf("unexpected %s, expect a value", tok)
+}
+
+func isQueryKeyword(tok queryToken) bool {
+	return tok.isKeyword(andKeyword) || tok.isKeyword(orKeyword) || tok.isKeyword(notKeyword)
+}
+
+// negatedOps is the operators whose negation has exactly the opposite result, both in mongodb and Match.
+var negatedOps = map[OpType]OpType{
+	Equal:                    NotEqual,
+	In:                       NotIn,
+	BeginsWith:               NotBeginsWith,
+	BeginsWithInsensitive:    NotBeginsWithInsensitive,
+	Contains:                 NotContainsInsensitive,
+	ContainsSensitive:        NotContains,
+	EndsWith:                 NotEndsWith,
+	EndsWithInsensitive:      NotEndsWithInsensitive,
+	IsNull:                   IsNotNull,
+	Exist:                    NotExist,
+	IPInCIDR:                 IPNotInCIDR,
+	NotEqual:                 Equal,
+	NotIn:                    In,
+	NotBeginsWith:            BeginsWith,
+	NotBeginsWithInsensitive: BeginsWithInsensitive,
+	NotContainsInsensitive:   Contains,
+	NotContains:              ContainsSensitive,
+	NotEndsWith:              EndsWith,
+	NotEndsWithInsensitive:   EndsWithInsensitive,
+	IsNotNull:                IsNull,
+	NotExist:                 Exist,
+	IPNotInCIDR:              IPInCIDR,
+}
+
+// negateRule returns the negation of the rule, since expression has no 'not' logic, the negation is pushed down
+// to the atom rules using De Morgan's laws and the opposite operators.
+func negateRule(rule RuleFactory) (RuleFactory, error) {
+	switch r := rule.(type) {
+	case *CombinedRule:
+		cond := And
+		if r.Condition == And {
+			cond = Or
+		}
+
+		rules := make([]RuleFactory, len(r.Rules))
+		for idx, sub := range r.Rules {
+			negated, err := negateRule(sub)
+			if err != nil {
+				return nil, err
+			}
+			rules[idx] = negated
+		}
+		return &CombinedRule{Condition: cond, Rules: rules}, nil
+
+	case *AtomRule:
+		op := OpType(r.Operator)
+		switch op {
+		case Object, Array:
+			// filter object and array operator's sub rules are applied on the same field, negate the sub rules
+			subRule, ok := r.Value.(RuleFactory)
+			if !ok {
+				return nil, fmt.Errorf("%s operator's value(%+v) is not a rule type", op, r.Value)
+			}
+
+			negated, err := negateRule(subRule)
+			if err != nil {
+				return nil, err
+			}
+			return &AtomRule{Field: r.Field, Operator: r.Operator, Value: negated}, nil
+		}
+
+		negatedOp, exists := negatedOps[op]
+		if !exists {
+			return nil, fmt.Errorf("operator %s can not be negated", op)
+		}
+		return &AtomRule{Field: r.Field, Operator: negatedOp.Factory(), Value: r.Value}, nil
+	}
+
+	return nil, fmt.Errorf("rule type %s can not be negated", rule.WithType())
+}
+
+// querySymbolTexts is the symbol form of the operators used when formatting the expression to text form query.
+var querySymbolTexts = map[OpType]string{
+	Equal:          "=",
+	NotEqual:       "!=",
+	Less:           "<",
+	LessOrEqual:    "<=",
+	Greater:        ">",
+	GreaterOrEqual: ">=",
+}
+
+// FormatQuery format the expression to the text form query, which can be parsed back by ParseQuery.
+func FormatQuery(exp *Expression) (string, error) {
+	if exp == nil || exp.RuleFactory == nil {
+		return "", errors.New("expression should not be nil")
+	}
+
+	return formatQueryRule(exp.RuleFactory)
+}
+
+func formatQueryRule(rule RuleFactory) (string, error) {
+	switch r := rule.(type) {
+	case *Expression:
+		return FormatQuery(r)
+	case *CombinedRule:
+		if err := r.Condition.Validate(); err != nil {
+			return "", err
+		}
+
+		if len(r.Rules) == 0 {
+			return "", errors.New("combined rules shouldn't be empty")
+		}
+
+		subQueries := make([]string, len(r.Rules))
+		for idx, sub := range r.Rules {
+			subQuery, err := formatQueryRule(sub)
+			if err != nil {
+				return "", err
+			}
+
+			// wrap the sub combined rule with parentheses to keep its priority
+			if sub.WithType() == CombinedType {
+				subQuery = "(" + subQuery + ")"
+			}
+			subQueries[idx] = subQuery
+		}
+
+		return strings.Join(subQueries, " "+string(r.Condition)+" "), nil
+	case *AtomRule:
+		return formatQueryAtom(r)
+	}
+
+	return "", fmt.Errorf("rule type %s is invalid", rule.WithType())
+}
+
+func formatQueryAtom(rule *AtomRule) (string, error) {
+	op := OpType(rule.Operator)
+	if err := op.Validate(); err != nil {
+		return "", err
+	}
+
+	opText, exists := querySymbolTexts[op]
+	if !exists {
+		opText = string(op)
+	}
+
+	prefix := rule.Field + " " + opText
+
+	switch op {
+	case IsEmpty, IsNotEmpty, IsNull, IsNotNull, Exist, NotExist:
+		return prefix, nil
+	case Object, Array:
+		subRule, ok := rule.Value.(RuleFactory)
+		if !ok {
+			return "", fmt.Errorf("%s operator's value(%+v) is not a rule type", op, rule.Value)
+		}
+
+		subQuery, err := formatQueryRule(subRule)
+		if err != nil {
+			return "", err
+		}
+		return prefix + " (" + subQuery + ")", nil
+	case In, NotIn, IPRange:
+		if _, ok := toSlice(rule.Value); !ok {
+			return "", fmt.Errorf("%s operator's value(%+v) is not an array", op, rule.Value)
+		}
+	}
+
+	text, err := formatQueryValue(rule.Value)
+	if err != nil {
+		return "", err
+	}
+	return prefix + " " + text, nil
+}
+
+func formatQueryValue(value interface{}) (string, error) {
+	if values, ok := toSlice(value); ok {
+		valueTexts := make([]string, len(values))
+		for idx, value := range values {
+			text, err := formatQueryValue(value)
+			if err != nil {
+				return "", err
+			}
+			valueTexts[idx] = text
+		}
+		return "(" + strings.Join(valueTexts, ", ") + ")", nil
+	}
+
+	switch v := value.(type) {
+	case nil:
+		return nullKeyword, nil
+	case string:
+		return strconv.Quote(v), nil
+	case bool:
+		return strconv.FormatBool(v), nil
+	case time.Time:
+		return strconv.Quote(v.Format(common.TimeTransferModel)), nil
+	}
+
+	if util.IsNumeric(value) {
+		return fmt.Sprintf("%v", value), nil
+	}
+
+	return "", fmt.Errorf("value(%+v) type %T is not supported in query", value, value)
+}
@@ -0,0 +1,193 @@
+/*
+ * Tencent is pleased to support the open source community by making
+ * 蓝鲸智云 - 配置平台 (BlueKing - Configuration System) available.
+ * Copyright (C) 2017 THL A29 Limited,
+ * a Tencent company. All rights reserved.
+ * Licensed under the MIT License (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at http://opensource.org/licenses/MIT
+ * Unless required by applicable law or agreed to in writing,
+ * software distributed under the License is distributed on
+ * an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND,
+ * either express or implied. See the License for the
+ * specific language governing permissions and limitations under the License.
+ * We undertake not to change the open source license (MIT license) applicable
+ * to the current version of the project delivered to anyone in the future.
+ */
+
+package filter
+
+import (
+	"reflect"
+	"testing"
+
+	"configcenter/src/common/criteria/enumor"
+	"configcenter/src/common/json"
+)
+
+func TestParseQuery(t *testing.T) {
+	exp, err := ParseQuery(`bk_os_type = "1" AND bk_host_innerip begins_with "10." AND NOT bk_cloud_id in (0,2)`,
+		nil)
+	if err != nil {
+		t.Errorf("parse query failed, err: %v", err)
+		return
+	}
+
+	expected := &CombinedRule{
+		Condition: And,
+		Rules: []RuleFactory{
+			atom("bk_os_type", Equal, "1"),
+			atom("bk_host_innerip", BeginsWith, "10."),
+			atom("bk_cloud_id", NotIn, []interface{}{int64(0), int64(2)}),
+		},
+	}
+
+	if !reflect.DeepEqual(exp.RuleFactory, expected) {
+		t.Errorf("parsed expression %s is not as expected", exp.String())
+		return
+	}
+
+	exp, err = ParseQuery(`a >= 1.5 or not (b != true and c is_null)
+		OR d filter_array (element filter_object (id < -3 AND name contains_s "x\"y"))`, nil)
+	if err != nil {
+		t.Errorf("parse query failed, err: %v", err)
+		return
+	}
+
+	expected = &CombinedRule{
+		Condition: Or,
+		Rules: []RuleFactory{
+			atom("a", GreaterOrEqual, 1.5),
+			atom("b", Equal, true),
+			atom("c", IsNotNull, ""),
+			atom("d", Array, atom(ArrayElement, Object, &CombinedRule{
+				Condition: And,
+				Rules:     []RuleFactory{atom("id", Less, int64(-3)), atom("name", ContainsSensitive, `x"y`)},
+			})),
+		},
+	}
+
+	if !reflect.DeepEqual(exp.RuleFactory, expected) {
+		t.Errorf("parsed expression %s is not as expected", exp.String())
+		return
+	}
+}
+
+func TestParseQueryError(t *testing.T) {
+	invalids := map[string]QueryError{
+		`a = `:                  {Line: 1, Column: 5},
+		`a = 1 AND`:             {Line: 1, Column: 10},
+		"a = 1 AND\n  b ~ 2":    {Line: 2, Column: 5},
+		"a = 1\n  b = 2":        {Line: 2, Column: 3},
+		`a unknown 1`:           {Line: 1, Column: 3},
+		`a = "abc`:              {Line: 1, Column: 5},
+		`(a = 1`:                {Line: 1, Column: 7},
+		`a in (1, 2`:            {Line: 1, Column: 11},
+		`AND = 1`:               {Line: 1, Column: 1},
+		`NOT a < 1`:             {Line: 1, Column: 1},
+		`a filter_object b = 1`: {Line: 1, Column: 17},
+	}
+
+	for query, expected := range invalids {
+		_, err := ParseQuery(query, nil)
+		if err == nil {
+			t.Errorf("parse invalid query %s should fail", query)
+			return
+		}
+
+		queryErr, ok := err.(*QueryError)
+		if !ok {
+			t.Errorf("parse query %s error %v is not a query error", query, err)
+			return
+		}
+
+		if queryErr.Line != expected.Line || queryErr.Column != expected.Column {
+			t.Errorf("parse query %s error %v position is not line %d, column %d", query, err, expected.Line,
+				expected.Column)
+			return
+		}
+	}
+}
+
+func TestParseQueryValidate(t *testing.T) {
+	opt := NewDefaultExprOpt(map[string]enumor.FieldType{
+		"bk_os_type":  enumor.Enum,
+		"bk_cloud_id": enumor.Numeric,
+	})
+
+	if _, err := ParseQuery(`bk_os_type = "1" AND bk_cloud_id not_in (0, 2)`, opt); err != nil {
+		t.Errorf("parse valid query failed, err: %v", err)
+		return
+	}
+
+	if _, err := ParseQuery(`bk_cloud_id = "1"`, opt); err == nil {
+		t.Errorf("parse query with invalid value type should fail")
+		return
+	}
+
+	if _, err := ParseQuery(`bk_host_name = "1"`, opt); err == nil {
+		t.Errorf("parse query with invalid field should fail")
+		return
+	}
+}
+
+func TestFormatQuery(t *testing.T) {
+	queries := map[string]string{
+		`bk_os_type = "1" AND bk_host_innerip begins_with "10." AND NOT bk_cloud_id in (0,2)`: `bk_os_type = "1" ` +
+			`AND bk_host_innerip begins_with "10." AND bk_cloud_id not_in (0, 2)`,
+		`(a < 1 or b exist) and c filter_array (element = "x")`: `(a < 1 OR b exist) AND c filter_array (element = "x")`,
+		`a in [] OR b = null OR c datetime_less "2023-01-01"`:   `a in () OR b = null OR c datetime_less "2023-01-01"`,
+		`NOT a ip_in_cidr ["10.0.0.0/8"] AND b ip_range ("1.1.1.1", "1.1.1.2") AND c ip_in_cidr "::/0"`: `a ` +
+			`ip_not_in_cidr ("10.0.0.0/8") AND b ip_range ("1.1.1.1", "1.1.1.2") AND c ip_in_cidr "::/0"`,
+	}
+
+	for query, expected := range queries {
+		exp, err := ParseQuery(query, nil)
+		if err != nil {
+			t.Errorf("parse query %s failed, err: %v", query, err)
+			return
+		}
+
+		formatted, err := FormatQuery(exp)
+		if err != nil {
+			t.Errorf("format query %s failed, err: %v", query, err)
+			return
+		}
+
+		if formatted != expected {
+			t.Errorf("formatted query %s is not as expected", formatted)
+			return
+		}
+
+		// formatted query can be parsed back to the same expression
+		reparsed, err := ParseQuery(formatted, nil)
+		if err != nil {
+			t.Errorf("parse formatted query %s failed, err: %v", formatted, err)
+			return
+		}
+
+		if !reflect.DeepEqual(exp, reparsed) {
+			t.Errorf("reparsed expression %s is not the same as %s", reparsed.String(), exp.String())
+			return
+		}
+	}
+
+	// format expression unmarshalled from json
+	exp := new(Expression)
+	if err := json.Unmarshal([]byte(`{"condition":"AND","rules":[{"field":"a","operator":"in","value":[1,"b"]},`+
+		`{"field":"c","operator":"equal","value":1.5}]}`), exp); err != nil {
+		t.Errorf("unmarshal expression failed, err: %v", err)
+		return
+	}
+
+	formatted, err := FormatQuery(exp)
+	if err != nil {
+		t.Errorf("format query failed, err: %v", err)
+		return
+	}
+
+	if formatted != `a in (1, "b") AND c = 1.5` {
+		t.Errorf("formatted query %s is not as expected", formatted)
+		return
+	}
+}
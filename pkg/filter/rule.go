@@ -27,6 +27,7 @@ import (
 	"configcenter/src/common"
 	"configcenter/src/common/blog"
 	"configcenter/src/common/criteria/enumor"
+	"configcenter/src/common/mapstr"
 	"configcenter/src/common/util"
 	"configcenter/src/common/valid"
 
@@ -43,6 +44,8 @@ type RuleFactory interface {
 	RuleFields() []string
 	// ToMgo convert this rule to a mongo condition
 	ToMgo(opt ...*RuleOption) (map[string]interface{}, error)
+	// Match test if the document matches this rule
+	Match(doc mapstr.MapStr, opt ...*RuleOption) (bool, error)
 }
 
 // RuleType is the expression rule's rule type.
@@ -270,6 +273,33 @@ func (ar *AtomRule) ToMgo(opts ...*RuleOption) (map[string]interface{}, error) {
 	return ar.Operator.Operator().ToMgo(ar.Field, ar.Value)
 }
 
+// Match test if the document matches this atom rule, the field is generated in the same way as ToMgo.
+func (ar *AtomRule) Match(doc mapstr.MapStr, opts ...*RuleOption) (bool, error) {
+	if len(opts) > 0 && opts[0] != nil {
+		opt := opts[0]
+		if len(opt.Parent) == 0 {
+			return false, errors.New("parent is empty")
+		}
+
+		switch opt.ParentType {
+		case enumor.Object:
+			return ar.Operator.Operator().Match(opt.Parent+"."+ar.Field, ar.Value, doc)
+		case enumor.Array:
+			switch ar.Field {
+			case ArrayElement:
+				// filter array element, matches if any of the elements matches the filter
+				return ar.Operator.Operator().Match(opt.Parent, ar.Value, doc)
+			default:
+				return false, fmt.Errorf("filter array field %s is invalid", ar.Field)
+			}
+		default:
+			return false, fmt.Errorf("parent type %s is invalid", opt.ParentType)
+		}
+	}
+
+	return ar.Operator.Operator().Match(ar.Field, ar.Value, doc)
+}
+
 type jsonAtomRuleBroker struct {
 	Field    string          `json:"field"`
 	Operator OpFactory       `json:"operator"`
@@ -468,6 +498,38 @@ func (cr *CombinedRule) ToMgo(opt ...*RuleOption) (map[string]interface{}, error
 	}
 }
 
+// Match test if the document matches the combined rule.
+func (cr *CombinedRule) Match(doc mapstr.MapStr, opt ...*RuleOption) (bool, error) {
+	if err := cr.Condition.Validate(); err != nil {
+		return false, err
+	}
+
+	if len(cr.Rules) == 0 {
+		return false, errors.New("combined rules shouldn't be empty")
+	}
+
+	for idx, rule := range cr.Rules {
+		matched, err := rule.Match(doc, opt...)
+		if err != nil {
+			return false, fmt.Errorf("rules[%d] is invalid, err: %v", idx, err)
+		}
+
+		switch cr.Condition {
+		case Or:
+			if matched {
+				return true, nil
+			}
+		case And:
+			if !matched {
+				return false, nil
+			}
+		}
+	}
+
+	// all rules matches for AND condition, none of the rules matches for OR condition
+	return cr.Condition == And, nil
+}
+
 type jsonCombinedRuleBroker struct {
 	Condition LogicOperator     `json:"condition"`
 	Rules     []json.RawMessage `json:"rules"`
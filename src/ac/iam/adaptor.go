@@ -415,7 +415,18 @@ var resourceActionMap = map[meta.ResourceType]map[meta.Action]ActionID{
 		meta.WatchKubeNamespace:    WatchKubeNamespaceEvent,
 		meta.WatchKubeWorkload:     WatchKubeWorkloadEvent,
 		meta.WatchKubePod:          WatchKubePodEvent,
+		meta.WatchKubeService:      WatchKubeNamespaceEvent,
+		meta.WatchKubeIngress:      WatchKubeNamespaceEvent,
+		meta.WatchKubeEndpoints:    WatchKubeNamespaceEvent,
+		meta.WatchKubeConfigMap:    WatchKubeConfigMapEvent,
 		meta.WatchProject:          WatchProjectEvent,
+		meta.WatchServiceTemplate:  WatchServiceTemplateEvent,
+		meta.WatchProcessTemplate:  WatchProcessTemplateEvent,
+		meta.WatchSetTemplate:      WatchSetTemplateEvent,
+		meta.WatchFieldTemplate:    WatchFieldTemplateEvent,
+		meta.WatchModel:            WatchModelEvent,
+		meta.WatchModelAttribute:   WatchModelAttributeEvent,
+		meta.WatchHostApplyRule:    WatchHostApplyRuleEvent,
 	},
 	meta.UserCustom: {
 		meta.Find:   Skip,
@@ -398,9 +398,33 @@ func genResourceManageActionGroups() []ActionGroup {
 						{
 							ID: WatchKubePodEvent,
 						},
+						{
+							ID: WatchKubeConfigMapEvent,
+						},
 						{
 							ID: WatchProjectEvent,
 						},
+						{
+							ID: WatchServiceTemplateEvent,
+						},
+						{
+							ID: WatchProcessTemplateEvent,
+						},
+						{
+							ID: WatchSetTemplateEvent,
+						},
+						{
+							ID: WatchFieldTemplateEvent,
+						},
+						{
+							ID: WatchModelEvent,
+						},
+						{
+							ID: WatchModelAttributeEvent,
+						},
+						{
+							ID: WatchHostApplyRuleEvent,
+						},
 					},
 				},
 			},
@@ -127,7 +127,15 @@ var ActionIDNameMap = map[ActionID]string{
 	WatchKubeNamespaceEvent:             "容器命名空间事件监听",
 	WatchKubeWorkloadEvent:              "容器工作负载事件监听",
 	WatchKubePodEvent:                   "容器Pod事件监听",
+	WatchKubeConfigMapEvent:             "容器ConfigMap事件监听",
 	WatchProjectEvent:                   "项目事件监听",
+	WatchServiceTemplateEvent:           "服务模板事件监听",
+	WatchProcessTemplateEvent:           "进程模板事件监听",
+	WatchSetTemplateEvent:               "集群模板事件监听",
+	WatchFieldTemplateEvent:             "字段组合模板事件监听",
+	WatchModelEvent:                     "模型事件监听",
+	WatchModelAttributeEvent:            "模型字段事件监听",
+	WatchHostApplyRuleEvent:             "主机属性自动应用事件监听",
 	GlobalSettings:                      "全局设置",
 	ManageHostAgentID:                   "主机AgentID管理",
 	CreateContainerCluster:              "容器集群新建",
@@ -1342,6 +1350,62 @@ func genEventWatchActions() []ResourceAction {
 		Version: 1,
 	})
 
+	actions = append(actions, ResourceAction{
+		ID:      WatchServiceTemplateEvent,
+		Name:    ActionIDNameMap[WatchServiceTemplateEvent],
+		NameEn:  "Service Template Event Listen",
+		Type:    View,
+		Version: 1,
+	})
+
+	actions = append(actions, ResourceAction{
+		ID:      WatchProcessTemplateEvent,
+		Name:    ActionIDNameMap[WatchProcessTemplateEvent],
+		NameEn:  "Process Template Event Listen",
+		Type:    View,
+		Version: 1,
+	})
+
+	actions = append(actions, ResourceAction{
+		ID:      WatchSetTemplateEvent,
+		Name:    ActionIDNameMap[WatchSetTemplateEvent],
+		NameEn:  "Set Template Event Listen",
+		Type:    View,
+		Version: 1,
+	})
+
+	actions = append(actions, ResourceAction{
+		ID:      WatchFieldTemplateEvent,
+		Name:    ActionIDNameMap[WatchFieldTemplateEvent],
+		NameEn:  "Field Template Event Listen",
+		Type:    View,
+		Version: 1,
+	})
+
+	actions = append(actions, ResourceAction{
+		ID:      WatchModelEvent,
+		Name:    ActionIDNameMap[WatchModelEvent],
+		NameEn:  "Model Event Listen",
+		Type:    View,
+		Version: 1,
+	})
+
+	actions = append(actions, ResourceAction{
+		ID:      WatchModelAttributeEvent,
+		Name:    ActionIDNameMap[WatchModelAttributeEvent],
+		NameEn:  "Model Attribute Event Listen",
+		Type:    View,
+		Version: 1,
+	})
+
+	actions = append(actions, ResourceAction{
+		ID:      WatchHostApplyRuleEvent,
+		Name:    ActionIDNameMap[WatchHostApplyRuleEvent],
+		NameEn:  "Host Apply Rule Event Listen",
+		Type:    View,
+		Version: 1,
+	})
+
 	modelSelection := []RelatedInstanceSelection{{
 		SystemID: SystemIDCMDB,
 		ID:       SysModelEventSelection,
@@ -1465,6 +1529,13 @@ func genKubeEventWatchActions() []ResourceAction {
 			Type:    View,
 			Version: 1,
 		},
+		{
+			ID:      WatchKubeConfigMapEvent,
+			Name:    ActionIDNameMap[WatchKubeConfigMapEvent],
+			NameEn:  "Kube ConfigMap Event Listen",
+			Type:    View,
+			Version: 1,
+		},
 	}
 }
 
@@ -502,6 +502,20 @@ const (
 	WatchPlatEvent ActionID = "watch_plat_event"
 	// WatchProjectEvent watch project event action id
 	WatchProjectEvent ActionID = "watch_project_event"
+	// WatchServiceTemplateEvent watch service template event action id
+	WatchServiceTemplateEvent ActionID = "watch_service_template_event"
+	// WatchProcessTemplateEvent watch process template event action id
+	WatchProcessTemplateEvent ActionID = "watch_process_template_event"
+	// WatchSetTemplateEvent watch set template event action id
+	WatchSetTemplateEvent ActionID = "watch_set_template_event"
+	// WatchFieldTemplateEvent watch field template event action id
+	WatchFieldTemplateEvent ActionID = "watch_field_template_event"
+	// WatchModelEvent watch model event action id
+	WatchModelEvent ActionID = "watch_model_event"
+	// WatchModelAttributeEvent watch model attribute event action id
+	WatchModelAttributeEvent ActionID = "watch_model_attribute_event"
+	// WatchHostApplyRuleEvent watch host apply rule event action id
+	WatchHostApplyRuleEvent ActionID = "watch_host_apply_rule_event"
 
 	// watch kube related event actions
 
@@ -515,6 +529,8 @@ const (
 	WatchKubeWorkloadEvent ActionID = "watch_kube_workload"
 	// WatchKubePodEvent watch kube pod event action id, its event detail includes containers in it
 	WatchKubePodEvent ActionID = "watch_kube_pod"
+	// WatchKubeConfigMapEvent watch kube config map event action id, its event detail includes the config data
+	WatchKubeConfigMapEvent ActionID = "watch_kube_config_map"
 
 	// CreateFieldGroupingTemplate create field grouping template action id
 	CreateFieldGroupingTemplate = "create_field_grouping_template"
@@ -208,6 +208,32 @@ const (
 	WatchKubeWorkload Action = "kube_workload"
 	// WatchKubePod watch kube pod event cc action
 	WatchKubePod Action = "kube_pod"
+	// WatchKubeService watch kube service event cc action
+	WatchKubeService Action = "kube_service"
+	// WatchKubeIngress watch kube ingress event cc action
+	WatchKubeIngress Action = "kube_ingress"
+	// WatchKubeEndpoints watch kube endpoints event cc action
+	WatchKubeEndpoints Action = "kube_endpoints"
+	// WatchKubeConfigMap watch kube config map event cc action
+	WatchKubeConfigMap Action = "kube_config_map"
+
+	// template, model and host apply rule related event watch cc actions, each of them is authorized by its own
+	// iam watch action.
+
+	// WatchServiceTemplate watch service template event cc action
+	WatchServiceTemplate Action = "service_template"
+	// WatchProcessTemplate watch process template event cc action
+	WatchProcessTemplate Action = "process_template"
+	// WatchSetTemplate watch set template event cc action
+	WatchSetTemplate Action = "set_template"
+	// WatchFieldTemplate watch field template event cc action
+	WatchFieldTemplate Action = "field_template"
+	// WatchModel watch model event cc action
+	WatchModel Action = "model"
+	// WatchModelAttribute watch model attribute event cc action
+	WatchModelAttribute Action = "model_attribute"
+	// WatchHostApplyRule watch host apply rule event cc action
+	WatchHostApplyRule Action = "host_apply_rule"
 
 	// ViewBusinessResource view business related resources action, including business and business collection resources
 	ViewBusinessResource Action = "viewBusinessResource"
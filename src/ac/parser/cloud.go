@@ -190,6 +190,66 @@ var cloudResourceTaskConfigs = []AuthConfig{
 			}
 			return []int64{taskID}, nil
 		},
+	}, {
+		Name:           "listCloudResourceTaskPreviewPattern",
+		Description:    "查询云资源同步预览",
+		Pattern:        "/api/v3/findmany/cloud/sync/preview",
+		HTTPMethod:     http.MethodPost,
+		ResourceType:   meta.CloudResourceTask,
+		ResourceAction: meta.Find,
+		InstanceIDGetter: func(request *RequestContext, re *regexp.Regexp) (int64s []int64, e error) {
+			val, err := request.getValueFromBody(common.BKCloudTaskID)
+			if err != nil {
+				return nil, err
+			}
+			taskID := val.Int()
+			if taskID <= 0 {
+				return nil, errors.New("invalid cloud sync task id")
+			}
+			return []int64{taskID}, nil
+		},
+	}, {
+		Name:           "createCloudResourceTaskPreviewRegex",
+		Description:    "生成云资源同步预览",
+		Regex:          regexp.MustCompile(`^/api/v3/create/cloud/sync/preview/([0-9]+)$`),
+		HTTPMethod:     http.MethodPost,
+		ResourceType:   meta.CloudResourceTask,
+		ResourceAction: meta.Find,
+		InstanceIDGetter: func(request *RequestContext, re *regexp.Regexp) (int64s []int64, e error) {
+			subMatch := re.FindStringSubmatch(request.URI)
+			for _, subStr := range subMatch {
+				if strings.Contains(subStr, "api") {
+					continue
+				}
+				id, err := strconv.ParseInt(subStr, 10, 64)
+				if err != nil {
+					return nil, fmt.Errorf("parse task id to int64 failed, err: %s", err)
+				}
+				return []int64{id}, nil
+			}
+			return nil, errors.New("unexpected error: this code shouldn't be reached")
+		},
+	}, {
+		Name:           "approveCloudResourceTaskPreviewRegex",
+		Description:    "审批云资源同步预览",
+		Regex:          regexp.MustCompile(`^/api/v3/approve/cloud/sync/preview/([0-9]+)$`),
+		HTTPMethod:     http.MethodPost,
+		ResourceType:   meta.CloudResourceTask,
+		ResourceAction: meta.Update,
+		InstanceIDGetter: func(request *RequestContext, re *regexp.Regexp) (int64s []int64, e error) {
+			subMatch := re.FindStringSubmatch(request.URI)
+			for _, subStr := range subMatch {
+				if strings.Contains(subStr, "api") {
+					continue
+				}
+				id, err := strconv.ParseInt(subStr, 10, 64)
+				if err != nil {
+					return nil, fmt.Errorf("parse task id to int64 failed, err: %s", err)
+				}
+				return []int64{id}, nil
+			}
+			return nil, errors.New("unexpected error: this code shouldn't be reached")
+		},
 	},
 	{
 		Name:           "listCloudResourceRegionPattern",
@@ -13,6 +13,7 @@
 package parser
 
 import (
+	"errors"
 	"fmt"
 	"net/http"
 	"regexp"
@@ -33,7 +34,8 @@ func (ps *parseStream) eventRelated() *parseStream {
 	ps.watch().
 		syncHostIdentifier().
 		pushHostIdentifier().
-		findHostIdentifierPushResult()
+		findHostIdentifierPushResult().
+		subscription()
 	return ps
 }
 
@@ -54,62 +56,76 @@ func (ps *parseStream) watch() *parseStream {
 			return ps
 		}
 
-		if resource == string(watch.HostIdentifier) {
-			// redirect host identity resource to host resource in iam.
-			resource = string(watch.Host)
+		authResource, err := ps.watchAuthResource(resource)
+		if err != nil {
+			ps.err = err
+			return ps
 		}
 
-		if resource == string(watch.BizSetRelation) {
-			// redirect biz set relation resource to biz set resource in iam.
-			resource = string(watch.BizSet)
-		}
+		ps.Attribute.Resources = append(ps.Attribute.Resources, authResource)
+		return ps
+	}
 
-		authResource := meta.ResourceAttribute{
-			Basic: meta.Basic{
-				Type:   meta.EventWatch,
-				Action: meta.Action(resource),
-			},
-		}
+	return ps
+}
 
-		switch watch.CursorType(resource) {
-		case watch.ObjectBase, watch.MainlineInstance, watch.InstAsst:
-			body, err := ps.RequestCtx.getRequestBody()
-			if err != nil {
-				ps.err = err
-				return ps
-			}
+// watchAuthResource returns the auth resource to watch the resource, the sub resource in the request body's
+// watch filter is used for authorization if it is set.
+func (ps *parseStream) watchAuthResource(resource string) (meta.ResourceAttribute, error) {
+	if resource == string(watch.HostIdentifier) {
+		// redirect host identity resource to host resource in iam.
+		resource = string(watch.Host)
+	}
 
-			// use sub resource(corresponding to the bk_obj_id of the object) for authorization if it is set
-			// if sub resource is not set, verify authorization of the resource(which means all sub resources)
-			subResource := gjson.GetBytes(body, "bk_filter."+common.BKSubResourceField)
-			if subResource.Exists() {
-				model, err := ps.getOneModel(mapstr.MapStr{common.BKObjIDField: subResource.String()})
-				if err != nil {
-					ps.err = err
-					return ps
-				}
-				authResource.InstanceID = model.ID
-			}
-		case watch.KubeWorkload:
-			body, err := ps.RequestCtx.getRequestBody()
-			if err != nil {
-				ps.err = err
-				return ps
-			}
+	if resource == string(watch.BizSetRelation) {
+		// redirect biz set relation resource to biz set resource in iam.
+		resource = string(watch.BizSet)
+	}
+
+	if resource == string(watch.DynamicGroupMember) {
+		// redirect dynamic group member resource to host resource in iam.
+		resource = string(watch.Host)
+	}
+
+	authResource := meta.ResourceAttribute{
+		Basic: meta.Basic{
+			Type:   meta.EventWatch,
+			Action: meta.Action(resource),
+		},
+	}
 
-			// use sub resource(corresponding to the kind of the workload) for authorization if it is set
-			// if sub resource is not set, verify authorization of the resource(which means all sub resources)
-			subResource := gjson.GetBytes(body, "bk_filter."+common.BKSubResourceField)
-			if subResource.Exists() {
-				authResource.InstanceIDEx = subResource.String()
+	switch watch.CursorType(resource) {
+	case watch.ObjectBase, watch.MainlineInstance, watch.InstAsst:
+		body, err := ps.RequestCtx.getRequestBody()
+		if err != nil {
+			return authResource, err
+		}
+
+		// use sub resource(corresponding to the bk_obj_id of the object) for authorization if it is set
+		// if sub resource is not set, verify authorization of the resource(which means all sub resources)
+		subResource := gjson.GetBytes(body, "bk_filter."+common.BKSubResourceField)
+		if subResource.Exists() {
+			model, err := ps.getOneModel(mapstr.MapStr{common.BKObjIDField: subResource.String()})
+			if err != nil {
+				return authResource, err
 			}
+			authResource.InstanceID = model.ID
+		}
+	case watch.KubeWorkload:
+		body, err := ps.RequestCtx.getRequestBody()
+		if err != nil {
+			return authResource, err
 		}
 
-		ps.Attribute.Resources = append(ps.Attribute.Resources, authResource)
-		return ps
+		// use sub resource(corresponding to the kind of the workload) for authorization if it is set
+		// if sub resource is not set, verify authorization of the resource(which means all sub resources)
+		subResource := gjson.GetBytes(body, "bk_filter."+common.BKSubResourceField)
+		if subResource.Exists() {
+			authResource.InstanceIDEx = subResource.String()
+		}
 	}
 
-	return ps
+	return authResource, nil
 }
 
 const (
@@ -174,3 +190,74 @@ func (ps *parseStream) findHostIdentifierPushResult() *parseStream {
 
 	return ps
 }
+
+const (
+	createSubscriptionPattern        = "/api/v3/event/create/subscription"
+	findManySubscriptionPattern      = "/api/v3/event/findmany/subscription"
+	findManyDeadLetterPattern        = "/api/v3/event/findmany/subscription/dead_letter"
+	redeliverDeadLetterPattern       = "/api/v3/event/redeliver/subscription/dead_letter"
+	subscriptionIDPatternPlaceholder = `^/api/v3/event/%s/subscription/[0-9]+/?$`
+)
+
+var (
+	updateSubscriptionRegexp = regexp.MustCompile(fmt.Sprintf(subscriptionIDPatternPlaceholder, "update"))
+	deleteSubscriptionRegexp = regexp.MustCompile(fmt.Sprintf(subscriptionIDPatternPlaceholder, "delete"))
+)
+
+// subscription parses the event push subscription apis, creating or updating a subscription needs the watch
+// authorization of all its resources, since the subscription pushes these resources' events. the other apis of
+// a subscription are only allowed to its creator, which is checked by event server, so they need no authorization.
+func (ps *parseStream) subscription() *parseStream {
+	if ps.shouldReturn() {
+		return ps
+	}
+
+	isCreate := ps.hitPattern(createSubscriptionPattern, http.MethodPost)
+	if isCreate || ps.hitRegexp(updateSubscriptionRegexp, http.MethodPut) {
+		body, err := ps.RequestCtx.getRequestBody()
+		if err != nil {
+			ps.err = err
+			return ps
+		}
+
+		resources := gjson.GetBytes(body, "bk_resources").Array()
+		if len(resources) == 0 {
+			if isCreate {
+				ps.err = errors.New("create subscription, but bk_resources is not set")
+				return ps
+			}
+
+			// updating a subscription without its resources keeps the resources authorized when they are saved,
+			// and the filter can not be updated without resources, event server checks it with the creator.
+			ps.Attribute.Resources = []meta.ResourceAttribute{{Basic: meta.Basic{Action: meta.SkipAction}}}
+			return ps
+		}
+
+		for _, resource := range resources {
+			authResource, err := ps.watchAuthResource(resource.String())
+			if err != nil {
+				ps.err = err
+				return ps
+			}
+			ps.Attribute.Resources = append(ps.Attribute.Resources, authResource)
+		}
+		return ps
+	}
+
+	if ps.hitRegexp(deleteSubscriptionRegexp, http.MethodDelete) ||
+		ps.hitPattern(findManySubscriptionPattern, http.MethodPost) ||
+		ps.hitPattern(findManyDeadLetterPattern, http.MethodPost) ||
+		ps.hitPattern(redeliverDeadLetterPattern, http.MethodPost) {
+
+		ps.Attribute.Resources = []meta.ResourceAttribute{
+			{
+				Basic: meta.Basic{
+					Action: meta.SkipAction,
+				},
+			},
+		}
+		return ps
+	}
+
+	return ps
+}
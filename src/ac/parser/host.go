@@ -349,6 +349,7 @@ const (
 	lockHostPattern                       = "/api/v3/host/lock"
 	unLockHostPattern                     = "/api/v3/host/lock"
 	queryHostLockPattern                  = "/api/v3/host/lock/search"
+	queryHostLockDetailPattern            = "/api/v3/host/lock/detail/search"
 
 	// used in sync framework.
 	// moveHostToBusinessOrModulePattern = "/api/v3/hosts/sync/new/host"
@@ -582,6 +583,18 @@ func (ps *parseStream) host() *parseStream {
 		return ps
 	}
 
+	if ps.hitPattern(queryHostLockDetailPattern, http.MethodPost) {
+		ps.Attribute.Resources = []meta.ResourceAttribute{
+			{
+				Basic: meta.Basic{
+					Type:   meta.HostInstance,
+					Action: meta.SkipAction,
+				},
+			},
+		}
+		return ps
+	}
+
 	// delete hosts batch operation.
 	if ps.hitPattern(deleteHostBatchPattern, http.MethodDelete) {
 		ps.Attribute.Resources = []meta.ResourceAttribute{
@@ -164,6 +164,33 @@ var HostApplyAuthConfigs = []AuthConfig{
 		BizIDGetter:    DefaultBizIDGetter,
 		ResourceType:   meta.HostApply,
 		ResourceAction: meta.DefaultHostApply,
+	}, {
+		Name:           "GetHostApplyReconcileConfigRegex",
+		Description:    "获取业务的主机属性自动应用偏离处理配置",
+		Regex:          regexp.MustCompile(`^/api/v3/find/host_apply_reconcile_config/bk_biz_id/([0-9]+)/?$`),
+		HTTPMethod:     http.MethodGet,
+		BizIDGetter:    BizIDFromURLGetter,
+		BizIndex:       5,
+		ResourceType:   meta.HostApply,
+		ResourceAction: meta.DefaultHostApply,
+	}, {
+		Name:           "UpdateHostApplyReconcileConfigRegex",
+		Description:    "更新业务的主机属性自动应用偏离处理配置",
+		Regex:          regexp.MustCompile(`^/api/v3/update/host_apply_reconcile_config/bk_biz_id/([0-9]+)/?$`),
+		HTTPMethod:     http.MethodPut,
+		BizIDGetter:    BizIDFromURLGetter,
+		BizIndex:       5,
+		ResourceType:   meta.HostApply,
+		ResourceAction: meta.Update,
+	}, {
+		Name:           "ListHostApplyDriftRegex",
+		Description:    "列表查询业务下偏离主机属性自动应用规则的主机属性",
+		Regex:          regexp.MustCompile(`^/api/v3/findmany/host_apply_drift/bk_biz_id/([0-9]+)/?$`),
+		HTTPMethod:     http.MethodPost,
+		BizIDGetter:    BizIDFromURLGetter,
+		BizIndex:       5,
+		ResourceType:   meta.HostApply,
+		ResourceAction: meta.DefaultHostApply,
 	},
 }
 
@@ -468,6 +468,7 @@ func (ps *parseStream) getModelAsstUpdateResAttr(objID, asstObjID interface{}) (
 const (
 	findObjectInstanceAssociationLatestPattern        = "/api/v3/find/instassociation"
 	findObjectInstanceAssociationRelatedLatestPattern = "/api/v3/find/instassociation/related"
+	findObjectInstanceGraphLatestPattern              = "/api/v3/find/instassociation/graph"
 	createObjectInstanceAssociationLatestPattern      = "/api/v3/create/instassociation"
 	createObjectManyInstanceAssociationLatestPattern  = "/api/v3/createmany/instassociation"
 )
@@ -615,6 +616,40 @@ func (ps *parseStream) objectInstanceAssociationLatest() *parseStream {
 		return ps
 	}
 
+	// find instance graph operation, which is authorized as finding the start instances.
+	if ps.hitPattern(findObjectInstanceGraphLatestPattern, http.MethodPost) {
+		val, err := ps.RequestCtx.getValueFromBody("start.bk_obj_id")
+		if err != nil {
+			ps.err = err
+			return ps
+		}
+		objID := val.Value()
+		if objID == nil {
+			ps.err = fmt.Errorf("find instance graph failed, no start.bk_obj_id was found in request body")
+			return ps
+		}
+		model, err := ps.getOneModel(mapstr.MapStr{common.BKObjIDField: objID})
+		if err != nil {
+			ps.err = err
+			return ps
+		}
+		instanceType, err := ps.getInstanceTypeByObject(model.ObjectID, model.ID)
+		if err != nil {
+			ps.err = err
+			return ps
+		}
+
+		ps.Attribute.Resources = []meta.ResourceAttribute{
+			{
+				Basic: meta.Basic{
+					Type:   instanceType,
+					Action: meta.FindMany,
+				},
+			},
+		}
+		return ps
+	}
+
 	// find instance's association related info operation.
 	if ps.hitPattern(findObjectInstanceAssociationRelatedLatestPattern, http.MethodPost) {
 		bizID, err := ps.RequestCtx.getBizIDFromBody()
@@ -1078,6 +1113,10 @@ var (
 	deleteObjectInstanceBatchLatestRegexp = regexp.MustCompile(`^/api/v3/deletemany/instance/object/[^\s/]+/?$`)
 	deleteObjectInstanceLatestRegexp      = regexp.MustCompile(
 		`^/api/v3/delete/instance/object/[^\s/]+/inst/[0-9]+/?$`)
+	restoreObjectInstanceLatestRegexp        = regexp.MustCompile(`^/api/v3/restore/instance/object/[^\s/]+/?$`)
+	previewCascadeDeleteInstanceLatestRegexp = regexp.MustCompile(
+		`^/api/v3/find/instance/object/[^\s/]+/cascade_delete/preview/?$`)
+	analyzeInstanceImpactLatestRegexp = regexp.MustCompile(`^/api/v3/find/instance/object/[^\s/]+/impact/?$`)
 	// TODO remove it
 	findObjectInstanceSubTopologyLatestRegexp = regexp.MustCompile(
 		`^/api/v3/find/insttopo/object/[^\s/]+/inst/[0-9]+/?$`)
@@ -1175,6 +1214,44 @@ func (ps *parseStream) objectInstanceLatest() *parseStream {
 		return ps
 	}
 
+	// restore deleted instances is authorized as creating them
+	if ps.hitRegexp(restoreObjectInstanceLatestRegexp, http.MethodPost) {
+		if len(ps.RequestCtx.Elements) != 6 {
+			ps.err = errors.New("restore instance, but got invalid url")
+			return ps
+		}
+
+		objID := ps.RequestCtx.Elements[5]
+		model, err := ps.getOneModel(mapstr.MapStr{common.BKObjIDField: objID})
+		if err != nil {
+			ps.err = err
+			return ps
+		}
+		instanceType, err := ps.getInstanceTypeByObject(model.ObjectID, model.ID)
+		if err != nil {
+			ps.err = err
+			return ps
+		}
+
+		bizID, err := ps.RequestCtx.getBizIDFromBody()
+		if err != nil {
+			ps.err = err
+			return ps
+		}
+
+		ps.Attribute.Resources = []meta.ResourceAttribute{
+			{
+				BusinessID: bizID,
+				Basic: meta.Basic{
+					Type:   instanceType,
+					Action: meta.Create,
+				},
+			},
+		}
+
+		return ps
+	}
+
 	if ps.hitRegexp(createObjectManyInstanceLatestRegexp, http.MethodPost) {
 		if len(ps.RequestCtx.Elements) != 6 {
 			ps.err = errors.New("create instance, but got invalid url")
@@ -1495,6 +1572,66 @@ func (ps *parseStream) objectInstanceLatest() *parseStream {
 		return ps
 	}
 
+	// preview the instances deleted in cascade operation, which is authorized as finding instances
+	if ps.hitRegexp(previewCascadeDeleteInstanceLatestRegexp, http.MethodPost) {
+		if len(ps.RequestCtx.Elements) != 8 {
+			ps.err = errors.New("preview cascade delete instance, but got invalid url")
+			return ps
+		}
+
+		objID := ps.RequestCtx.Elements[5]
+		model, err := ps.getOneModel(mapstr.MapStr{common.BKObjIDField: objID})
+		if err != nil {
+			ps.err = err
+			return ps
+		}
+		instanceType, err := ps.getInstanceTypeByObject(model.ObjectID, model.ID)
+		if err != nil {
+			ps.err = err
+			return ps
+		}
+
+		ps.Attribute.Resources = []meta.ResourceAttribute{
+			{
+				Basic: meta.Basic{
+					Type:   instanceType,
+					Action: meta.SkipAction,
+				},
+			},
+		}
+		return ps
+	}
+
+	// analyze the impact of instances, which is authorized as finding instances
+	if ps.hitRegexp(analyzeInstanceImpactLatestRegexp, http.MethodPost) {
+		if len(ps.RequestCtx.Elements) != 7 {
+			ps.err = errors.New("analyze instance impact, but got invalid url")
+			return ps
+		}
+
+		objID := ps.RequestCtx.Elements[5]
+		model, err := ps.getOneModel(mapstr.MapStr{common.BKObjIDField: objID})
+		if err != nil {
+			ps.err = err
+			return ps
+		}
+		instanceType, err := ps.getInstanceTypeByObject(model.ObjectID, model.ID)
+		if err != nil {
+			ps.err = err
+			return ps
+		}
+
+		ps.Attribute.Resources = []meta.ResourceAttribute{
+			{
+				Basic: meta.Basic{
+					Type:   instanceType,
+					Action: meta.FindMany,
+				},
+			},
+		}
+		return ps
+	}
+
 	// find object's instance list operation
 	if ps.hitRegexp(findObjectInstancesLatestRegexp, http.MethodPost) {
 		if len(ps.RequestCtx.Elements) != 6 {
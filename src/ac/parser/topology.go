@@ -1342,6 +1342,11 @@ var (
 	searchAuditList   = `/api/v3/findmany/audit_list`
 	searchAuditDetail = `/api/v3/find/audit`
 	searchInstAudit   = `/api/v3/find/inst_audit`
+
+	searchAuditSnapshot        = `/api/v3/find/audit_snapshot`
+	searchBizTopoAuditSnapshot = `/api/v3/find/audit_biz_topo_snapshot`
+	searchAuditTimeline        = `/api/v3/find/audit_timeline`
+	verifyAuditChain           = `/api/v3/find/audit_chain/verify`
 )
 
 // NOCC:golint/fnsize(设计如此)
@@ -1374,6 +1379,33 @@ func (ps *parseStream) audit() *parseStream {
 		return ps
 	}
 
+	// reconstructing the data at a past time and the change timeline are both based on audit log details
+	if ps.hitPattern(searchAuditSnapshot, http.MethodPost) || ps.hitPattern(searchBizTopoAuditSnapshot,
+		http.MethodPost) || ps.hitPattern(searchAuditTimeline, http.MethodPost) {
+		ps.Attribute.Resources = []meta.ResourceAttribute{
+			{
+				Basic: meta.Basic{
+					Type:   meta.AuditLog,
+					Action: meta.Find,
+				},
+			},
+		}
+		return ps
+	}
+
+	// verifying the audit log hash chain reads all the audit logs of the supplier account
+	if ps.hitPattern(verifyAuditChain, http.MethodPost) {
+		ps.Attribute.Resources = []meta.ResourceAttribute{
+			{
+				Basic: meta.Basic{
+					Type:   meta.AuditLog,
+					Action: meta.FindMany,
+				},
+			},
+		}
+		return ps
+	}
+
 	if ps.hitPattern(searchAuditDetail, http.MethodPost) {
 		ps.Attribute.Resources = []meta.ResourceAttribute{
 			{
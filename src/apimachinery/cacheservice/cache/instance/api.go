@@ -0,0 +1,40 @@
+/*
+ * Tencent is pleased to support the open source community by making 蓝鲸 available.
+ * Copyright (C) 2017-2018 THL A29 Limited, a Tencent company. All rights reserved.
+ * Licensed under the MIT License (the "License"); you may not use this file except
+ * in compliance with the License. You may obtain a copy of the License at
+ * http://opensource.org/licenses/MIT
+ * Unless required by applicable law or agreed to in writing, software distributed under
+ * the License is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND,
+ * either express or implied. See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+// Package instance is the cache client of the custom object instances, service instances and processes
+package instance
+
+import (
+	"context"
+	"net/http"
+
+	"configcenter/src/apimachinery/rest"
+	"configcenter/src/common/metadata"
+)
+
+// Interface is the instance cache client interface
+type Interface interface {
+	ListInstances(ctx context.Context, h http.Header, objID string, opt *metadata.ListWithIDOption) (
+		jsonArray string, err error)
+	ListServiceInstances(ctx context.Context, h http.Header, opt *metadata.ListWithIDOption) (jsonArray string,
+		err error)
+	ListProcesses(ctx context.Context, h http.Header, opt *metadata.ListWithIDOption) (jsonArray string, err error)
+}
+
+// NewCacheClient new instance cache client
+func NewCacheClient(client rest.ClientInterface) Interface {
+	return &instCache{client: client}
+}
+
+type instCache struct {
+	client rest.ClientInterface
+}
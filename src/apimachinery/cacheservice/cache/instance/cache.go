@@ -0,0 +1,92 @@
+/*
+ * Tencent is pleased to support the open source community by making 蓝鲸 available.
+ * Copyright (C) 2017-2018 THL A29 Limited, a Tencent company. All rights reserved.
+ * Licensed under the MIT License (the "License"); you may not use this file except
+ * in compliance with the License. You may obtain a copy of the License at
+ * http://opensource.org/licenses/MIT
+ * Unless required by applicable law or agreed to in writing, software distributed under
+ * the License is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND,
+ * either express or implied. See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package instance
+
+import (
+	"context"
+	"net/http"
+
+	"configcenter/src/common"
+	"configcenter/src/common/errors"
+	"configcenter/src/common/metadata"
+)
+
+// ListInstances list the object's instances with id list and return with a json array string which is []string json.
+func (c *instCache) ListInstances(ctx context.Context, h http.Header, objID string, opt *metadata.ListWithIDOption) (
+	jsonArray string, err error) {
+
+	resp, err := c.client.Post().
+		WithContext(ctx).
+		Body(opt).
+		SubResourcef("/findmany/cache/instance/object/%s", objID).
+		WithHeaders(h).
+		Do().
+		IntoJsonString()
+
+	if err != nil {
+		return "", errors.New(common.CCErrCommHTTPDoRequestFailed, err.Error())
+	}
+
+	if !resp.Result {
+		return "", errors.New(resp.Code, resp.ErrMsg)
+	}
+
+	return resp.Data, nil
+}
+
+// ListServiceInstances list service instances with id list and return with a json array string which is []string
+// json.
+func (c *instCache) ListServiceInstances(ctx context.Context, h http.Header, opt *metadata.ListWithIDOption) (
+	jsonArray string, err error) {
+
+	resp, err := c.client.Post().
+		WithContext(ctx).
+		Body(opt).
+		SubResourcef("/findmany/cache/service_instance").
+		WithHeaders(h).
+		Do().
+		IntoJsonString()
+
+	if err != nil {
+		return "", errors.New(common.CCErrCommHTTPDoRequestFailed, err.Error())
+	}
+
+	if !resp.Result {
+		return "", errors.New(resp.Code, resp.ErrMsg)
+	}
+
+	return resp.Data, nil
+}
+
+// ListProcesses list processes with id list and return with a json array string which is []string json.
+func (c *instCache) ListProcesses(ctx context.Context, h http.Header, opt *metadata.ListWithIDOption) (
+	jsonArray string, err error) {
+
+	resp, err := c.client.Post().
+		WithContext(ctx).
+		Body(opt).
+		SubResourcef("/findmany/cache/process").
+		WithHeaders(h).
+		Do().
+		IntoJsonString()
+
+	if err != nil {
+		return "", errors.New(common.CCErrCommHTTPDoRequestFailed, err.Error())
+	}
+
+	if !resp.Result {
+		return "", errors.New(resp.Code, resp.ErrMsg)
+	}
+
+	return resp.Data, nil
+}
@@ -18,6 +18,7 @@ import (
 
 	"configcenter/src/apimachinery/cacheservice/cache/event"
 	"configcenter/src/apimachinery/cacheservice/cache/host"
+	"configcenter/src/apimachinery/cacheservice/cache/instance"
 	"configcenter/src/apimachinery/cacheservice/cache/topology"
 	"configcenter/src/apimachinery/rest"
 	"configcenter/src/apimachinery/util"
@@ -27,6 +28,7 @@ import (
 type Cache interface {
 	Host() host.Interface
 	Topology() topology.Interface
+	Instance() instance.Interface
 	Event() event.Interface
 }
 
@@ -68,6 +70,11 @@ func (c *cache) Topology() topology.Interface {
 	return topology.NewCacheClient(c.restCli)
 }
 
+// Instance returns the instance cache client
+func (c *cache) Instance() instance.Interface {
+	return instance.NewCacheClient(c.restCli)
+}
+
 // Event TODO
 func (c *cache) Event() event.Interface {
 	return event.NewCacheClient(c.restCli)
@@ -70,3 +70,29 @@ func (inst *auditlog) SearchAuditLog(ctx context.Context, h http.Header, param m
 
 	return resp.Data, nil
 }
+
+// VerifyAuditChain verify a range of the audit log hash chain
+func (inst *auditlog) VerifyAuditChain(ctx context.Context, h http.Header, opt *metadata.VerifyAuditChainOption) (
+	*metadata.VerifyAuditChainResult, errors.CCErrorCoder) {
+
+	resp := new(metadata.VerifyAuditChainResp)
+	subPath := "/verify/auditlog/chain"
+
+	err := inst.client.Post().
+		WithContext(ctx).
+		Body(opt).
+		SubResourcef(subPath).
+		WithHeaders(h).
+		Do().
+		Into(resp)
+
+	if err != nil {
+		return nil, errors.CCHttpError
+	}
+
+	if err := resp.CCError(); err != nil {
+		return nil, err
+	}
+
+	return resp.Data, nil
+}
@@ -27,6 +27,8 @@ type AuditClientInterface interface {
 	SaveAuditLog(ctx context.Context, h http.Header, logs ...metadata.AuditLog) errors.CCErrorCoder
 	SearchAuditLog(ctx context.Context, h http.Header, param metadata.QueryCondition) (*metadata.AuditQueryResult,
 		errors.CCErrorCoder)
+	VerifyAuditChain(ctx context.Context, h http.Header, opt *metadata.VerifyAuditChainOption) (
+		*metadata.VerifyAuditChainResult, errors.CCErrorCoder)
 }
 
 // NewAuditClientInterface TODO
@@ -303,3 +303,95 @@ func (c *cloud) DeleteDestroyedHostRelated(ctx context.Context, h http.Header,
 
 	return nil
 }
+
+// CreateSyncPreview create cloud sync preview, the pending preview of the task will be replaced
+func (c *cloud) CreateSyncPreview(ctx context.Context, h http.Header, preview *metadata.CloudSyncPreview) (
+	*metadata.CloudSyncPreview, errors.CCErrorCoder) {
+	ret := new(metadata.CreateSyncPreviewResult)
+	subPath := "/create/cloud/sync/preview"
+
+	err := c.client.Post().
+		WithContext(ctx).
+		Body(preview).
+		SubResourcef(subPath).
+		WithHeaders(h).
+		Do().
+		Into(ret)
+
+	if err != nil {
+		return nil, errors.CCHttpError
+	}
+	if ret.CCError() != nil {
+		return nil, ret.CCError()
+	}
+
+	return &ret.Data, nil
+}
+
+// SearchSyncPreview search cloud sync previews of a task
+func (c *cloud) SearchSyncPreview(ctx context.Context, h http.Header,
+	option *metadata.SearchSyncPreviewOption) (*metadata.MultipleSyncPreview, errors.CCErrorCoder) {
+	ret := new(metadata.MultipleSyncPreviewResult)
+	subPath := "/findmany/cloud/sync/preview"
+
+	err := c.client.Post().
+		WithContext(ctx).
+		Body(option).
+		SubResourcef(subPath).
+		WithHeaders(h).
+		Do().
+		Into(ret)
+
+	if err != nil {
+		return nil, errors.CCHttpError
+	}
+	if ret.CCError() != nil {
+		return nil, ret.CCError()
+	}
+
+	return &ret.Data, nil
+}
+
+// ApplySyncPreview set the pending cloud sync preview to applied status
+func (c *cloud) ApplySyncPreview(ctx context.Context, h http.Header, previewID int64) errors.CCErrorCoder {
+	ret := new(metadata.UpdatedOptionResult)
+	subPath := "/update/cloud/sync/preview/%d/apply"
+
+	err := c.client.Put().
+		WithContext(ctx).
+		SubResourcef(subPath, previewID).
+		WithHeaders(h).
+		Do().
+		Into(ret)
+
+	if err != nil {
+		return errors.CCHttpError
+	}
+	if ret.CCError() != nil {
+		return ret.CCError()
+	}
+
+	return nil
+}
+
+// DeletePendingSyncPreview delete the pending cloud sync preview of a task
+func (c *cloud) DeletePendingSyncPreview(ctx context.Context, h http.Header, taskID int64) errors.CCErrorCoder {
+	ret := new(metadata.DeletedOptionResult)
+	subPath := "/delete/cloud/sync/preview/pending/%d"
+
+	err := c.client.Delete().
+		WithContext(ctx).
+		SubResourcef(subPath, taskID).
+		WithHeaders(h).
+		Do().
+		Into(ret)
+
+	if err != nil {
+		return errors.CCHttpError
+	}
+	if ret.CCError() != nil {
+		return ret.CCError()
+	}
+
+	return nil
+}
@@ -48,6 +48,14 @@ type CloudInterface interface {
 		option *metadata.SearchSyncHistoryOption) (*metadata.MultipleSyncHistory, errors.CCErrorCoder)
 	DeleteDestroyedHostRelated(ctx context.Context, h http.Header,
 		option *metadata.DeleteDestroyedHostRelatedOption) errors.CCErrorCoder
+
+	// cloud sync preview
+	CreateSyncPreview(ctx context.Context, h http.Header, preview *metadata.CloudSyncPreview) (
+		*metadata.CloudSyncPreview, errors.CCErrorCoder)
+	SearchSyncPreview(ctx context.Context, h http.Header,
+		option *metadata.SearchSyncPreviewOption) (*metadata.MultipleSyncPreview, errors.CCErrorCoder)
+	ApplySyncPreview(ctx context.Context, h http.Header, previewID int64) errors.CCErrorCoder
+	DeletePendingSyncPreview(ctx context.Context, h http.Header, taskID int64) errors.CCErrorCoder
 }
 
 // NewCloudInterfaceClient TODO
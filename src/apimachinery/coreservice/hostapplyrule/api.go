@@ -289,3 +289,126 @@ func (p *hostApplyRule) SearchRuleRelatedServiceTemplates(ctx context.Context, h
 
 	return resp.Data, nil
 }
+
+// ReconcileHostApply reconcile the hosts with the host apply rules of their modules
+func (p *hostApplyRule) ReconcileHostApply(ctx context.Context, header http.Header, bizID int64,
+	option *metadata.ReconcileHostApplyOption) (*metadata.ReconcileHostApplyResult, errors.CCErrorCoder) {
+
+	ret := struct {
+		metadata.BaseResp
+		Data *metadata.ReconcileHostApplyResult `json:"data"`
+	}{}
+
+	err := p.client.Put().
+		WithContext(ctx).
+		Body(option).
+		SubResourcef("/updatemany/host/bk_biz_id/%d/reconcile_host_apply", bizID).
+		WithHeaders(header).
+		Do().
+		Into(&ret)
+
+	if err != nil {
+		blog.Errorf("reconcile host apply failed, http request failed, err: %v", err)
+		return nil, errors.CCHttpError
+	}
+	if ret.CCError() != nil {
+		return nil, ret.CCError()
+	}
+	return ret.Data, nil
+}
+
+// DeleteHostApplyDrift delete the host apply drifts of the hosts
+func (p *hostApplyRule) DeleteHostApplyDrift(ctx context.Context, header http.Header,
+	option *metadata.DeleteHostApplyDriftOption) errors.CCErrorCoder {
+
+	ret := new(metadata.BaseResp)
+
+	err := p.client.Delete().
+		WithContext(ctx).
+		Body(option).
+		SubResourcef("/deletemany/host_apply_drift").
+		WithHeaders(header).
+		Do().
+		Into(ret)
+
+	if err != nil {
+		blog.Errorf("delete host apply drift failed, http request failed, err: %v", err)
+		return errors.CCHttpError
+	}
+	return ret.CCError()
+}
+
+// ListHostApplyDrift list the host apply drifts in the business
+func (p *hostApplyRule) ListHostApplyDrift(ctx context.Context, header http.Header, bizID int64,
+	option *metadata.ListHostApplyDriftOption) (*metadata.HostApplyDriftResult, errors.CCErrorCoder) {
+
+	ret := struct {
+		metadata.BaseResp
+		Data *metadata.HostApplyDriftResult `json:"data"`
+	}{}
+
+	err := p.client.Post().
+		WithContext(ctx).
+		Body(option).
+		SubResourcef("/findmany/host_apply_drift/bk_biz_id/%d", bizID).
+		WithHeaders(header).
+		Do().
+		Into(&ret)
+
+	if err != nil {
+		blog.Errorf("list host apply drift failed, http request failed, err: %v", err)
+		return nil, errors.CCHttpError
+	}
+	if ret.CCError() != nil {
+		return nil, ret.CCError()
+	}
+	return ret.Data, nil
+}
+
+// ListHostApplyReconcileConfig list the host apply reconcile configs of the businesses
+func (p *hostApplyRule) ListHostApplyReconcileConfig(ctx context.Context, header http.Header,
+	option *metadata.ListHostApplyReconcileConfigOption) ([]metadata.HostApplyReconcileConfig, errors.CCErrorCoder) {
+
+	ret := struct {
+		metadata.BaseResp
+		Data []metadata.HostApplyReconcileConfig `json:"data"`
+	}{}
+
+	err := p.client.Post().
+		WithContext(ctx).
+		Body(option).
+		SubResourcef("/findmany/host_apply_reconcile_config").
+		WithHeaders(header).
+		Do().
+		Into(&ret)
+
+	if err != nil {
+		blog.Errorf("list host apply reconcile config failed, http request failed, err: %v", err)
+		return nil, errors.CCHttpError
+	}
+	if ret.CCError() != nil {
+		return nil, ret.CCError()
+	}
+	return ret.Data, nil
+}
+
+// UpdateHostApplyReconcileConfig update the host apply reconcile config of the business
+func (p *hostApplyRule) UpdateHostApplyReconcileConfig(ctx context.Context, header http.Header, bizID int64,
+	option *metadata.UpdateHostApplyReconcileConfigOption) errors.CCErrorCoder {
+
+	ret := new(metadata.BaseResp)
+
+	err := p.client.Put().
+		WithContext(ctx).
+		Body(option).
+		SubResourcef("/update/host_apply_reconcile_config/bk_biz_id/%d", bizID).
+		WithHeaders(header).
+		Do().
+		Into(ret)
+
+	if err != nil {
+		blog.Errorf("update host apply reconcile config failed, http request failed, err: %v", err)
+		return errors.CCHttpError
+	}
+	return ret.CCError()
+}
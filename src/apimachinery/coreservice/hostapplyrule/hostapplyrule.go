@@ -46,6 +46,17 @@ type HostApplyRuleInterface interface {
 		option metadata.UpdateHostByHostApplyRuleOption) (metadata.MultipleHostApplyResult, errors.CCErrorCoder)
 	SearchRuleRelatedServiceTemplates(ctx context.Context, header http.Header,
 		option *metadata.RuleRelatedServiceTemplateOption) ([]metadata.SrvTemplate, errors.CCErrorCoder)
+
+	ReconcileHostApply(ctx context.Context, header http.Header, bizID int64,
+		option *metadata.ReconcileHostApplyOption) (*metadata.ReconcileHostApplyResult, errors.CCErrorCoder)
+	DeleteHostApplyDrift(ctx context.Context, header http.Header,
+		option *metadata.DeleteHostApplyDriftOption) errors.CCErrorCoder
+	ListHostApplyDrift(ctx context.Context, header http.Header, bizID int64,
+		option *metadata.ListHostApplyDriftOption) (*metadata.HostApplyDriftResult, errors.CCErrorCoder)
+	ListHostApplyReconcileConfig(ctx context.Context, header http.Header,
+		option *metadata.ListHostApplyReconcileConfigOption) ([]metadata.HostApplyReconcileConfig, errors.CCErrorCoder)
+	UpdateHostApplyReconcileConfig(ctx context.Context, header http.Header, bizID int64,
+		option *metadata.UpdateHostApplyReconcileConfigOption) errors.CCErrorCoder
 }
 
 // NewHostApplyRuleClient TODO
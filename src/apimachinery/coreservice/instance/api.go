@@ -286,3 +286,29 @@ func (inst *instance) GetInstanceObjectMapping(ctx context.Context, header http.
 
 	return resp.Data, nil
 }
+
+// RestoreInstance restore deleted instances with their original ids from the delete archive
+func (inst *instance) RestoreInstance(ctx context.Context, h http.Header, objID string,
+	input *metadata.RestoreInstOption) (*metadata.RestoreInstResult, errors.CCErrorCoder) {
+
+	resp := new(metadata.RestoreInstResp)
+	subPath := "/restore/model/%s/instance"
+
+	err := inst.client.Post().
+		WithContext(ctx).
+		Body(input).
+		SubResourcef(subPath, objID).
+		WithHeaders(h).
+		Do().
+		Into(resp)
+
+	if err != nil {
+		return nil, errors.CCHttpError
+	}
+
+	if err := resp.CCError(); err != nil {
+		return nil, err
+	}
+
+	return &resp.Data, nil
+}
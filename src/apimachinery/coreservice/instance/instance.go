@@ -50,6 +50,9 @@ type InstanceClientInterface interface {
 		*metadata.CountResponseContent, error)
 	GetInstanceObjectMapping(ctx context.Context, h http.Header, ids []int64) ([]metadata.ObjectMapping,
 		errors.CCErrorCoder)
+	// RestoreInstance restore deleted instances with their original ids from the delete archive
+	RestoreInstance(ctx context.Context, h http.Header, objID string, input *metadata.RestoreInstOption) (
+		*metadata.RestoreInstResult, errors.CCErrorCoder)
 }
 
 // NewInstanceClientInterface TODO
@@ -41,6 +41,15 @@ type TaskClientInterface interface {
 
 	DeleteTask(ctx context.Context, header http.Header, taskCond *metadata.DeleteOption) error
 
+	CancelTask(ctx context.Context, header http.Header, opt *metadata.OperateAPITaskOption) errors.CCErrorCoder
+	PauseTask(ctx context.Context, header http.Header, opt *metadata.OperateAPITaskOption) errors.CCErrorCoder
+	ResumeTask(ctx context.Context, header http.Header, opt *metadata.OperateAPITaskOption) errors.CCErrorCoder
+	UpdateTaskPriority(ctx context.Context, h http.Header, opt *metadata.UpdateAPITaskPriorityOption) errors.CCErrorCoder
+
+	// WatchTaskProgress long poll the task progress, returns when the task is updated after the last time in option
+	WatchTaskProgress(ctx context.Context, header http.Header, opt *metadata.WatchAPITaskProgressOption) (
+		*metadata.APITaskProgressResult, errors.CCErrorCoder)
+
 	ListLatestSyncStatus(ctx context.Context, header http.Header, option *metadata.ListLatestSyncStatusRequest) (
 		[]metadata.APITaskSyncStatus, errors.CCErrorCoder)
 
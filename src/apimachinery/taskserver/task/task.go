@@ -175,6 +175,128 @@ func (t *task) DeleteTask(ctx context.Context, header http.Header, taskCond *met
 	return nil
 }
 
+// CancelTask cancel the unfinished tasks and their subtasks
+func (t *task) CancelTask(ctx context.Context, header http.Header, opt *metadata.OperateAPITaskOption) errors.CCErrorCoder {
+	resp := new(metadata.Response)
+	subPath := "/task/cancel"
+
+	err := t.client.Post().
+		WithContext(ctx).
+		Body(opt).
+		SubResourcef(subPath).
+		WithHeaders(header).
+		Do().
+		Into(resp)
+
+	if err != nil {
+		blog.Errorf("cancel tasks failed, http request failed, err: %v", err)
+		return errors.CCHttpError
+	}
+	if err := resp.CCError(); err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// PauseTask pause the unfinished tasks
+func (t *task) PauseTask(ctx context.Context, header http.Header, opt *metadata.OperateAPITaskOption) errors.CCErrorCoder {
+	resp := new(metadata.Response)
+	subPath := "/task/pause"
+
+	err := t.client.Post().
+		WithContext(ctx).
+		Body(opt).
+		SubResourcef(subPath).
+		WithHeaders(header).
+		Do().
+		Into(resp)
+
+	if err != nil {
+		blog.Errorf("pause tasks failed, http request failed, err: %v", err)
+		return errors.CCHttpError
+	}
+	if err := resp.CCError(); err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// ResumeTask resume the paused tasks
+func (t *task) ResumeTask(ctx context.Context, header http.Header, opt *metadata.OperateAPITaskOption) errors.CCErrorCoder {
+	resp := new(metadata.Response)
+	subPath := "/task/resume"
+
+	err := t.client.Post().
+		WithContext(ctx).
+		Body(opt).
+		SubResourcef(subPath).
+		WithHeaders(header).
+		Do().
+		Into(resp)
+
+	if err != nil {
+		blog.Errorf("resume tasks failed, http request failed, err: %v", err)
+		return errors.CCHttpError
+	}
+	if err := resp.CCError(); err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// UpdateTaskPriority update the priority of the unfinished tasks
+func (t *task) UpdateTaskPriority(ctx context.Context, header http.Header, opt *metadata.UpdateAPITaskPriorityOption) errors.CCErrorCoder {
+	resp := new(metadata.Response)
+	subPath := "/task/update/priority"
+
+	err := t.client.Put().
+		WithContext(ctx).
+		Body(opt).
+		SubResourcef(subPath).
+		WithHeaders(header).
+		Do().
+		Into(resp)
+
+	if err != nil {
+		blog.Errorf("update task priority failed, http request failed, err: %v", err)
+		return errors.CCHttpError
+	}
+	if err := resp.CCError(); err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// WatchTaskProgress long poll the task progress, returns when the task is updated after the last time in option
+func (t *task) WatchTaskProgress(ctx context.Context, header http.Header, opt *metadata.WatchAPITaskProgressOption) (
+	*metadata.APITaskProgressResult, errors.CCErrorCoder) {
+
+	resp := new(metadata.WatchAPITaskProgressResp)
+	subPath := "/task/watch/progress"
+
+	err := t.client.Post().
+		WithContext(ctx).
+		Body(opt).
+		SubResourcef(subPath).
+		WithHeaders(header).
+		Do().
+		Into(resp)
+
+	if err != nil {
+		blog.Errorf("watch task progress failed, http request failed, err: %v", err)
+		return nil, errors.CCHttpError
+	}
+	if err := resp.CCError(); err != nil {
+		return nil, err
+	}
+
+	return resp.Data, nil
+}
+
 // ListLatestSyncStatus list latest sync status by condition
 func (t *task) ListLatestSyncStatus(ctx context.Context, header http.Header,
 	option *metadata.ListLatestSyncStatusRequest) ([]metadata.APITaskSyncStatus, errors.CCErrorCoder) {
@@ -43,6 +43,12 @@ type InstanceInterface interface {
 	SearchAuditList(ctx context.Context, h http.Header, input *metadata.AuditQueryInput) (*metadata.Response, error)
 	SearchAuditDetail(ctx context.Context, h http.Header, input *metadata.AuditDetailQueryInput) (*metadata.Response,
 		error)
+	SearchAuditSnapshot(ctx context.Context, h http.Header, input *metadata.AuditSnapshotOption) (*metadata.Response,
+		error)
+	SearchBizTopoAuditSnapshot(ctx context.Context, h http.Header, input *metadata.BizTopoAuditSnapshotOption) (
+		*metadata.Response, error)
+	SearchAuditTimeline(ctx context.Context, h http.Header, input *metadata.AuditTimelineOption) (*metadata.Response,
+		error)
 	GetInternalModule(ctx context.Context, ownerID, appID string,
 		h http.Header) (resp *metadata.SearchInnterAppTopoResult, err error)
 	SearchBriefBizTopo(ctx context.Context, h http.Header, bizID int64,
@@ -95,6 +95,81 @@ func (t *instanceClient) SearchAuditDetail(ctx context.Context, h http.Header,
 	return resp, nil
 }
 
+// SearchAuditSnapshot reconstruct the instance at a past time by replaying its audit logs
+func (t *instanceClient) SearchAuditSnapshot(ctx context.Context, h http.Header,
+	input *metadata.AuditSnapshotOption) (*metadata.Response, error) {
+	resp := new(metadata.Response)
+	subPath := "/find/audit_snapshot"
+
+	err := t.client.Post().
+		WithContext(ctx).
+		Body(input).
+		SubResourcef(subPath).
+		WithHeaders(h).
+		Do().
+		Into(resp)
+
+	if err != nil {
+		return nil, errors.New(common.CCErrCommHTTPDoRequestFailed, err.Error())
+	}
+
+	if !resp.Result {
+		return nil, errors.New(resp.Code, resp.ErrMsg)
+	}
+
+	return resp, nil
+}
+
+// SearchBizTopoAuditSnapshot reconstruct the business topology at a past time by replaying the audit logs
+func (t *instanceClient) SearchBizTopoAuditSnapshot(ctx context.Context, h http.Header,
+	input *metadata.BizTopoAuditSnapshotOption) (*metadata.Response, error) {
+	resp := new(metadata.Response)
+	subPath := "/find/audit_biz_topo_snapshot"
+
+	err := t.client.Post().
+		WithContext(ctx).
+		Body(input).
+		SubResourcef(subPath).
+		WithHeaders(h).
+		Do().
+		Into(resp)
+
+	if err != nil {
+		return nil, errors.New(common.CCErrCommHTTPDoRequestFailed, err.Error())
+	}
+
+	if !resp.Result {
+		return nil, errors.New(resp.Code, resp.ErrMsg)
+	}
+
+	return resp, nil
+}
+
+// SearchAuditTimeline get the field level change timeline of an instance from its audit logs
+func (t *instanceClient) SearchAuditTimeline(ctx context.Context, h http.Header,
+	input *metadata.AuditTimelineOption) (*metadata.Response, error) {
+	resp := new(metadata.Response)
+	subPath := "/find/audit_timeline"
+
+	err := t.client.Post().
+		WithContext(ctx).
+		Body(input).
+		SubResourcef(subPath).
+		WithHeaders(h).
+		Do().
+		Into(resp)
+
+	if err != nil {
+		return nil, errors.New(common.CCErrCommHTTPDoRequestFailed, err.Error())
+	}
+
+	if !resp.Result {
+		return nil, errors.New(resp.Code, resp.ErrMsg)
+	}
+
+	return resp, nil
+}
+
 // GetInternalModule TODO
 func (t *instanceClient) GetInternalModule(ctx context.Context, ownerID, appID string,
 	h http.Header) (resp *metadata.SearchInnterAppTopoResult, err error) {
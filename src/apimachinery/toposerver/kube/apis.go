@@ -71,12 +71,35 @@ type KubeOperationInterface interface {
 	ListWorkload(ctx context.Context, header http.Header, kind types.WorkloadType,
 		option *types.WlQueryOption) (*metadata.InstDataInfo, errors.CCErrorCoder)
 
+	// CreateNsResource create namespace scoped resource
+	CreateNsResource(ctx context.Context, header http.Header, kind types.NsResourceType,
+		option *types.NsResCreateOption) (*metadata.RspIDs, errors.CCErrorCoder)
+
+	// UpdateNsResource update namespace scoped resource
+	UpdateNsResource(ctx context.Context, header http.Header, kind types.NsResourceType,
+		option *types.NsResUpdateOption) errors.CCErrorCoder
+
+	// DeleteNsResource delete namespace scoped resource
+	DeleteNsResource(ctx context.Context, header http.Header, kind types.NsResourceType,
+		option *types.NsResDeleteOption) errors.CCErrorCoder
+
+	// ListNsResource list namespace scoped resource
+	ListNsResource(ctx context.Context, header http.Header, kind types.NsResourceType,
+		option *types.NsResQueryOption) (*metadata.InstDataInfo, errors.CCErrorCoder)
+
+	// FindKubeRelation find the related kube resources of the resources
+	FindKubeRelation(ctx context.Context, header http.Header, kind string, option *types.KubeRelationOption) (
+		[]types.KubeRelation, errors.CCErrorCoder)
+
 	// ListPod list pod
 	ListPod(ctx context.Context, header http.Header, option *types.PodQueryOption) (
 		*metadata.InstDataInfo, errors.CCErrorCoder)
 
 	BatchCreatePod(ctx context.Context, header http.Header, data *types.CreatePodsOption) ([]int64, errors.CCErrorCoder)
 
+	// DeletePods delete pods
+	DeletePods(ctx context.Context, header http.Header, option *types.DeletePodsOption) errors.CCErrorCoder
+
 	// ListContainer list container
 	ListContainer(ctx context.Context, header http.Header, option *types.ContainerQueryOption) (
 		*metadata.InstDataInfo, errors.CCErrorCoder)
@@ -446,6 +446,131 @@ func (st *Kube) ListWorkload(ctx context.Context, header http.Header, kind types
 	return &result.Data, nil
 }
 
+// CreateNsResource create namespace scoped resource
+func (st *Kube) CreateNsResource(ctx context.Context, header http.Header, kind types.NsResourceType,
+	option *types.NsResCreateOption) (*metadata.RspIDs, errors.CCErrorCoder) {
+
+	result := new(types.NsResCreateResp)
+
+	err := st.client.Post().
+		WithContext(ctx).
+		Body(option).
+		SubResourcef("/createmany/kube/ns_resource/%s", kind).
+		WithHeaders(header).
+		Do().
+		Into(result)
+
+	if err != nil {
+		return nil, errors.CCHttpError
+	}
+
+	if ccErr := result.CCError(); ccErr != nil {
+		return nil, ccErr
+	}
+
+	return &result.Data, nil
+}
+
+// UpdateNsResource update namespace scoped resource
+func (st *Kube) UpdateNsResource(ctx context.Context, header http.Header, kind types.NsResourceType,
+	option *types.NsResUpdateOption) errors.CCErrorCoder {
+
+	result := new(metadata.BaseResp)
+
+	err := st.client.Put().
+		WithContext(ctx).
+		Body(option).
+		SubResourcef("/updatemany/kube/ns_resource/%s", kind).
+		WithHeaders(header).
+		Do().
+		Into(result)
+
+	if err != nil {
+		return errors.CCHttpError
+	}
+
+	if ccErr := result.CCError(); ccErr != nil {
+		return ccErr
+	}
+
+	return nil
+}
+
+// DeleteNsResource delete namespace scoped resource
+func (st *Kube) DeleteNsResource(ctx context.Context, header http.Header, kind types.NsResourceType,
+	option *types.NsResDeleteOption) errors.CCErrorCoder {
+
+	result := new(metadata.BaseResp)
+
+	err := st.client.Delete().
+		WithContext(ctx).
+		Body(option).
+		SubResourcef("/deletemany/kube/ns_resource/%s", kind).
+		WithHeaders(header).
+		Do().
+		Into(result)
+
+	if err != nil {
+		return errors.CCHttpError
+	}
+
+	if ccErr := result.CCError(); ccErr != nil {
+		return ccErr
+	}
+
+	return nil
+}
+
+// ListNsResource list namespace scoped resource
+func (st *Kube) ListNsResource(ctx context.Context, header http.Header, kind types.NsResourceType,
+	option *types.NsResQueryOption) (*metadata.InstDataInfo, errors.CCErrorCoder) {
+
+	result := new(metadata.ResponseInstData)
+
+	err := st.client.Post().
+		WithContext(ctx).
+		Body(option).
+		SubResourcef("/findmany/kube/ns_resource/%s", kind).
+		WithHeaders(header).
+		Do().
+		Into(result)
+
+	if err != nil {
+		return nil, errors.CCHttpError
+	}
+
+	if ccErr := result.CCError(); ccErr != nil {
+		return nil, ccErr
+	}
+
+	return &result.Data, nil
+}
+
+// FindKubeRelation find the related kube resources of the resources
+func (st *Kube) FindKubeRelation(ctx context.Context, header http.Header, kind string,
+	option *types.KubeRelationOption) ([]types.KubeRelation, errors.CCErrorCoder) {
+
+	result := new(types.KubeRelationResp)
+
+	err := st.client.Post().
+		WithContext(ctx).
+		Body(option).
+		SubResourcef("/find/kube/relation/%s", kind).
+		WithHeaders(header).
+		Do().
+		Into(result)
+
+	if err != nil {
+		return nil, errors.CCHttpError
+	}
+
+	if ccErr := result.CCError(); ccErr != nil {
+		return nil, ccErr
+	}
+
+	return result.Data, nil
+}
+
 // ListPod list pod
 func (st *Kube) ListPod(ctx context.Context, header http.Header, option *types.PodQueryOption) (
 	*metadata.InstDataInfo, errors.CCErrorCoder) {
@@ -471,6 +596,29 @@ func (st *Kube) ListPod(ctx context.Context, header http.Header, option *types.P
 	return &result.Data, nil
 }
 
+// DeletePods delete pods
+func (st *Kube) DeletePods(ctx context.Context, header http.Header, option *types.DeletePodsOption) errors.CCErrorCoder {
+	result := new(metadata.BaseResp)
+
+	err := st.client.Delete().
+		WithContext(ctx).
+		Body(option).
+		SubResourcef("/deletemany/kube/pod").
+		WithHeaders(header).
+		Do().
+		Into(result)
+
+	if err != nil {
+		return errors.CCHttpError
+	}
+
+	if ccErr := result.CCError(); ccErr != nil {
+		return ccErr
+	}
+
+	return nil
+}
+
 // ListContainer list container
 func (st *Kube) ListContainer(ctx context.Context, header http.Header,
 	option *types.ContainerQueryOption) (*metadata.InstDataInfo, errors.CCErrorCoder) {
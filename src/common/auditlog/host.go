@@ -96,6 +96,76 @@ func (h *hostAuditLog) generateAuditLog(parameter *generateAuditCommonParameter,
 	return auditLogs, nil
 }
 
+// GenerateLockAuditLog generate audit log of locking or unlocking hosts, the details are the host locks
+func (h *hostAuditLog) GenerateLockAuditLog(parameter *generateAuditCommonParameter,
+	locks []metadata.HostLockData) ([]metadata.AuditLog, error) {
+
+	kit := parameter.kit
+	if len(locks) == 0 {
+		return make([]metadata.AuditLog, 0), nil
+	}
+
+	hostIDs := make([]int64, len(locks))
+	for index, lock := range locks {
+		hostIDs[index] = lock.ID
+	}
+
+	cond := map[string]interface{}{common.BKHostIDField: map[string]interface{}{common.BKDBIN: hostIDs}}
+	fields := []string{common.BKHostIDField, common.BKHostInnerIPField, common.BKHostInnerIPv6Field}
+	hosts, err := h.getInstByCond(kit, common.BKInnerObjIDHost, cond, fields)
+	if err != nil {
+		blog.Errorf("get hosts failed, err: %v, host ids: %+v, rid: %s", err, hostIDs, kit.Rid)
+		return nil, err
+	}
+
+	hostMap := make(map[int64]mapstr.MapStr)
+	for _, host := range hosts {
+		hostID, err := util.GetInt64ByInterface(host[common.BKHostIDField])
+		if err != nil {
+			blog.Errorf("parse host id failed, err: %v, host: %#v, rid: %s", err, host, kit.Rid)
+			return nil, kit.CCError.CCErrorf(common.CCErrCommParamsInvalid, common.BKHostIDField)
+		}
+		hostMap[hostID] = host
+	}
+
+	hostBizMap, err := h.getBizIDByHostID(kit, hostIDs)
+	if err != nil {
+		blog.Errorf("get biz id for hosts failed, err: %v, host ids: %+v, rid: %s", err, hostIDs, kit.Rid)
+		return nil, kit.CCError.CCErrorf(common.CCErrCommParamsInvalid, common.BKHostIDField)
+	}
+
+	auditLogs := make([]metadata.AuditLog, len(locks))
+	for index, lock := range locks {
+		details := mapstr.MapStr{
+			common.BKHostIDField:             lock.ID,
+			"bk_user":                        lock.User,
+			"reason":                         lock.Reason,
+			metadata.HostLockScopeField:      lock.Scope,
+			metadata.HostLockExpireTimeField: lock.ExpireTime,
+			common.CreateTimeField:           lock.CreateTime,
+		}
+
+		auditLogs[index] = metadata.AuditLog{
+			AuditType:          metadata.HostType,
+			ResourceType:       metadata.HostRes,
+			Action:             parameter.action,
+			BusinessID:         hostBizMap[lock.ID],
+			ResourceID:         lock.ID,
+			ResourceName:       util.GetStrByInterface(hostMap[lock.ID][common.BKHostInnerIPField]),
+			ExtendResourceName: util.GetStrByInterface(hostMap[lock.ID][common.BKHostInnerIPv6Field]),
+			OperateFrom:        parameter.operateFrom,
+			OperationDetail: &metadata.InstanceOpDetail{
+				BasicOpDetail: metadata.BasicOpDetail{
+					Details: parameter.NewBasicContent(details),
+				},
+				ModelID: common.BKInnerObjIDHost,
+			},
+		}
+	}
+
+	return auditLogs, nil
+}
+
 // getBizIDByHostID get mapping of host id to biz id
 func (h *hostAuditLog) getBizIDByHostID(kit *rest.Kit, hostIDs []int64) (map[int64]int64, error) {
 	input := &metadata.HostModuleRelationRequest{HostIDArr: hostIDs, Fields: []string{common.BKHostIDField,
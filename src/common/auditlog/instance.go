@@ -80,7 +80,7 @@ func (i *instanceAuditLog) generateAuditLog(parameter *generateAuditCommonParame
 
 		var details *metadata.BasicContent
 		switch action {
-		case metadata.AuditCreate:
+		case metadata.AuditCreate, metadata.AuditRestore:
 			details = &metadata.BasicContent{
 				CurData: inst,
 			}
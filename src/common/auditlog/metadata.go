@@ -49,11 +49,11 @@ func (a *generateAuditCommonParameter) WithUpdateFields(updateFields map[string]
 func (a *generateAuditCommonParameter) NewBasicContent(data map[string]interface{}) *metadata.BasicContent {
 	var basicDetail *metadata.BasicContent
 	switch a.action {
-	case metadata.AuditCreate:
+	case metadata.AuditCreate, metadata.AuditLock, metadata.AuditRestore:
 		basicDetail = &metadata.BasicContent{
 			CurData: data,
 		}
-	case metadata.AuditDelete:
+	case metadata.AuditDelete, metadata.AuditUnlock:
 		basicDetail = &metadata.BasicContent{
 			PreData: data,
 		}
@@ -0,0 +1,250 @@
+/*
+ * Tencent is pleased to support the open source community by making 蓝鲸 available.
+ * Copyright (C) 2017-2018 THL A29 Limited, a Tencent company. All rights reserved.
+ * Licensed under the MIT License (the "License"); you may not use this file except
+ * in compliance with the License. You may obtain a copy of the License at
+ * http://opensource.org/licenses/MIT
+ * Unless required by applicable law or agreed to in writing, software distributed under
+ * the License is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND,
+ * either express or implied. See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package auditlog
+
+import (
+	"encoding/json"
+	"sort"
+
+	"configcenter/src/common"
+	"configcenter/src/common/mapstr"
+	"configcenter/src/common/metadata"
+)
+
+// HostTopoField is the virtual field name of the host's business topology in the audit timeline
+const HostTopoField = "host_topo"
+
+// replayIgnoredFields are the fields that changes with every update, they are ignored when comparing the data
+var replayIgnoredFields = map[string]struct{}{
+	common.LastTimeField: {},
+	common.BKUpdatedAt:   {},
+	common.BKUpdatedBy:   {},
+	"_id":                {},
+}
+
+// GetAuditBasicContent returns the basic content in the audit log's operation detail, returns nil if it has none
+func GetAuditBasicContent(auditLog *metadata.AuditLog) *metadata.BasicContent {
+	switch detail := auditLog.OperationDetail.(type) {
+	case *metadata.InstanceOpDetail:
+		return detail.Details
+	case *metadata.BasicOpDetail:
+		return detail.Details
+	case *metadata.ModelAttrOpDetail:
+		return detail.Details
+	case *metadata.QuotedInstOpDetail:
+		return detail.Details
+	case *metadata.ServiceInstanceOpDetail:
+		return detail.Details
+	}
+	return nil
+}
+
+// IsAuditDataAction checks if the audit action changes the data of an instance
+func IsAuditDataAction(action metadata.ActionType) bool {
+	for _, dataAction := range metadata.AuditDataActions {
+		if action == dataAction {
+			return true
+		}
+	}
+	return false
+}
+
+// DataBeforeAuditLog returns the instance data before the operation of the data audit log, exists is false if the
+// instance did not exist before the operation, which means the audit log is a create or restore audit log.
+func DataBeforeAuditLog(auditLog *metadata.AuditLog) (data mapstr.MapStr, exists bool) {
+	content := GetAuditBasicContent(auditLog)
+	if content == nil || auditLog.Action == metadata.AuditCreate || auditLog.Action == metadata.AuditRestore {
+		return nil, false
+	}
+
+	return copyAuditData(content.PreData), content.PreData != nil
+}
+
+// ApplyAuditLog returns the instance data after the operation of the data audit log is applied to the previous data,
+// exists is false if the instance is deleted by the operation, the returned data is the last known data in this case.
+// NOTE: the update fields might not be the actual changed data, so the pre data of the next audit log is more
+// accurate than the returned data if it exists.
+func ApplyAuditLog(pre mapstr.MapStr, auditLog *metadata.AuditLog) (data mapstr.MapStr, exists bool) {
+	content := GetAuditBasicContent(auditLog)
+	if content == nil || !IsAuditDataAction(auditLog.Action) {
+		return pre, pre != nil
+	}
+
+	switch auditLog.Action {
+	case metadata.AuditCreate, metadata.AuditRestore:
+		return copyAuditData(content.CurData), true
+	case metadata.AuditDelete:
+		if content.PreData != nil {
+			return copyAuditData(content.PreData), false
+		}
+		return pre, false
+	}
+
+	if content.CurData != nil {
+		return copyAuditData(content.CurData), true
+	}
+
+	data = copyAuditData(pre)
+	if content.PreData != nil {
+		data = copyAuditData(content.PreData)
+	}
+	if data == nil {
+		data = make(mapstr.MapStr)
+	}
+	for field, value := range content.UpdateFields {
+		data[field] = value
+	}
+
+	return data, true
+}
+
+// BuildAuditTimeline builds the field level change timeline from the audit logs of one instance that is sorted by
+// operation order, next is the first data audit log after them that is used to get the accurate data after the last
+// one, it can be nil. if fields is set, only changes of the fields are returned.
+func BuildAuditTimeline(auditLogs []metadata.AuditLog, next *metadata.AuditLog,
+	fields []string) []metadata.AuditTimelineItem {
+
+	fieldMap := make(map[string]struct{})
+	for _, field := range fields {
+		fieldMap[field] = struct{}{}
+	}
+
+	// nextData[i] is the accurate data after the i-th audit log, which is the pre data of the next data audit log
+	nextData := make([]mapstr.MapStr, len(auditLogs))
+	nextExists := make([]bool, len(auditLogs))
+	if next != nil {
+		nextData[len(auditLogs)-1], nextExists[len(auditLogs)-1] = DataBeforeAuditLog(next)
+	}
+	for i := len(auditLogs) - 1; i > 0; i-- {
+		if IsAuditDataAction(auditLogs[i].Action) {
+			nextData[i-1], nextExists[i-1] = DataBeforeAuditLog(&auditLogs[i])
+			continue
+		}
+		nextData[i-1], nextExists[i-1] = nextData[i], nextExists[i]
+	}
+
+	timeline := make([]metadata.AuditTimelineItem, 0)
+	var state mapstr.MapStr
+	for i := range auditLogs {
+		auditLog := &auditLogs[i]
+		item := metadata.AuditTimelineItem{
+			AuditID:       auditLog.ID,
+			User:          auditLog.User,
+			Action:        auditLog.Action,
+			OperationTime: auditLog.OperationTime,
+		}
+
+		if topo, ok := auditLog.OperationDetail.(*metadata.HostTransferOpDetail); ok {
+			if _, exists := fieldMap[HostTopoField]; len(fieldMap) > 0 && !exists {
+				continue
+			}
+
+			item.Changes = []metadata.AuditFieldChange{{Field: HostTopoField, PreValue: topo.PreData,
+				CurValue: topo.CurData}}
+			timeline = append(timeline, item)
+			continue
+		}
+
+		if !IsAuditDataAction(auditLog.Action) || GetAuditBasicContent(auditLog) == nil {
+			continue
+		}
+
+		pre := state
+		if data, exists := DataBeforeAuditLog(auditLog); exists {
+			pre = data
+		}
+		if auditLog.Action == metadata.AuditCreate || auditLog.Action == metadata.AuditRestore {
+			pre = nil
+		}
+
+		cur, exists := ApplyAuditLog(pre, auditLog)
+		if exists && nextExists[i] {
+			cur = nextData[i]
+		}
+		if !exists {
+			cur = nil
+		}
+
+		item.Changes = DiffAuditData(pre, cur, fieldMap)
+		state = cur
+		if len(item.Changes) == 0 {
+			continue
+		}
+		timeline = append(timeline, item)
+	}
+
+	return timeline
+}
+
+// DiffAuditData returns the changed fields between the previous and current data sorted by field name, if fieldMap
+// is not empty, only the fields in it are compared.
+func DiffAuditData(pre, cur mapstr.MapStr, fieldMap map[string]struct{}) []metadata.AuditFieldChange {
+	allFields := make(map[string]struct{})
+	for field := range pre {
+		allFields[field] = struct{}{}
+	}
+	for field := range cur {
+		allFields[field] = struct{}{}
+	}
+
+	changes := make([]metadata.AuditFieldChange, 0)
+	for field := range allFields {
+		if _, exists := replayIgnoredFields[field]; exists {
+			continue
+		}
+
+		if _, exists := fieldMap[field]; len(fieldMap) > 0 && !exists {
+			continue
+		}
+
+		preVal, curVal := pre[field], cur[field]
+		if isAuditValueEqual(preVal, curVal) {
+			continue
+		}
+
+		changes = append(changes, metadata.AuditFieldChange{Field: field, PreValue: preVal, CurValue: curVal})
+	}
+
+	sort.Slice(changes, func(i, j int) bool {
+		return changes[i].Field < changes[j].Field
+	})
+	return changes
+}
+
+// isAuditValueEqual compares the values by their json value, because the same value can be decoded into different
+// types from db and from request, like int64 and float64.
+func isAuditValueEqual(a, b interface{}) bool {
+	aJs, err := json.Marshal(a)
+	if err != nil {
+		return false
+	}
+
+	bJs, err := json.Marshal(b)
+	if err != nil {
+		return false
+	}
+
+	return string(aJs) == string(bJs)
+}
+
+func copyAuditData(data map[string]interface{}) mapstr.MapStr {
+	if data == nil {
+		return nil
+	}
+
+	copied := make(mapstr.MapStr, len(data))
+	for key, value := range data {
+		copied[key] = value
+	}
+	return copied
+}
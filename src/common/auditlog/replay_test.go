@@ -0,0 +1,106 @@
+/*
+ * Tencent is pleased to support the open source community by making 蓝鲸 available.
+ * Copyright (C) 2017-2018 THL A29 Limited, a Tencent company. All rights reserved.
+ * Licensed under the MIT License (the "License"); you may not use this file except
+ * in compliance with the License. You may obtain a copy of the License at
+ * http://opensource.org/licenses/MIT
+ * Unless required by applicable law or agreed to in writing, software distributed under
+ * the License is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND,
+ * either express or implied. See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package auditlog
+
+import (
+	"testing"
+
+	"configcenter/src/common/metadata"
+)
+
+func newInstAudit(id int64, action metadata.ActionType, content *metadata.BasicContent) metadata.AuditLog {
+	return metadata.AuditLog{
+		ID:              id,
+		Action:          action,
+		ResourceType:    metadata.HostRes,
+		OperationDetail: &metadata.InstanceOpDetail{BasicOpDetail: metadata.BasicOpDetail{Details: content}},
+	}
+}
+
+func TestBuildAuditTimeline(t *testing.T) {
+	auditLogs := []metadata.AuditLog{
+		newInstAudit(1, metadata.AuditCreate, &metadata.BasicContent{
+			CurData: map[string]interface{}{"bk_host_id": 1, "bk_host_innerip": "127.0.0.1", "operator": "a"},
+		}),
+		// update fields might not be the actual changed data, the pre data of next audit log is used instead
+		newInstAudit(2, metadata.AuditUpdate, &metadata.BasicContent{
+			PreData:      map[string]interface{}{"bk_host_id": 1, "bk_host_innerip": "127.0.0.1", "operator": "a"},
+			UpdateFields: map[string]interface{}{"operator": "b", "last_time": "2023-01-01"},
+		}),
+		newInstAudit(3, metadata.AuditLock, &metadata.BasicContent{
+			CurData: map[string]interface{}{"bk_host_id": 1, "reason": "test"},
+		}),
+		{
+			ID:     4,
+			Action: metadata.AuditTransferHostModule,
+			OperationDetail: &metadata.HostTransferOpDetail{
+				PreData: metadata.HostBizTopo{BizID: 1},
+				CurData: metadata.HostBizTopo{BizID: 2},
+			},
+		},
+		newInstAudit(5, metadata.AuditDelete, &metadata.BasicContent{
+			PreData: map[string]interface{}{"bk_host_id": 1, "bk_host_innerip": "127.0.0.1", "operator": "c"},
+		}),
+	}
+
+	timeline := BuildAuditTimeline(auditLogs, nil, nil)
+	if len(timeline) != 4 {
+		t.Fatalf("timeline length %d is not 4, timeline: %+v", len(timeline), timeline)
+	}
+
+	if timeline[0].AuditID != 1 || len(timeline[0].Changes) != 3 || timeline[0].Changes[0].PreValue != nil {
+		t.Errorf("create changes %+v are invalid", timeline[0])
+	}
+
+	changes := timeline[1].Changes
+	if len(changes) != 1 || changes[0].Field != "operator" || changes[0].PreValue != "a" ||
+		changes[0].CurValue != "c" {
+		t.Errorf("update changes %+v are invalid", changes)
+	}
+
+	if timeline[2].Changes[0].Field != HostTopoField {
+		t.Errorf("transfer changes %+v are invalid", timeline[2].Changes)
+	}
+
+	if timeline[3].AuditID != 5 || len(timeline[3].Changes) != 3 || timeline[3].Changes[0].CurValue != nil {
+		t.Errorf("delete changes %+v are invalid", timeline[3])
+	}
+
+	timeline = BuildAuditTimeline(auditLogs, nil, []string{"operator"})
+	if len(timeline) != 3 {
+		t.Errorf("timeline of operator length %d is not 3, timeline: %+v", len(timeline), timeline)
+	}
+}
+
+func TestApplyAuditLog(t *testing.T) {
+	update := newInstAudit(1, metadata.AuditUpdate, &metadata.BasicContent{
+		PreData:      map[string]interface{}{"name": "a", "operator": "a"},
+		UpdateFields: map[string]interface{}{"operator": "b"},
+	})
+	data, exists := ApplyAuditLog(nil, &update)
+	if !exists || data["name"] != "a" || data["operator"] != "b" {
+		t.Errorf("apply update audit log result %+v, %v is invalid", data, exists)
+	}
+
+	del := newInstAudit(2, metadata.AuditDelete, &metadata.BasicContent{
+		PreData: map[string]interface{}{"name": "a", "operator": "b"},
+	})
+	data, exists = ApplyAuditLog(data, &del)
+	if exists || data["operator"] != "b" {
+		t.Errorf("apply delete audit log result %+v, %v is invalid", data, exists)
+	}
+
+	if _, exists = DataBeforeAuditLog(&del); !exists {
+		t.Errorf("data before delete audit log should exist")
+	}
+}
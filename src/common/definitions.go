@@ -233,6 +233,10 @@ const (
 	// BKDBAll matches arrays that contain all elements specified in the query.
 	BKDBAll = "$all"
 
+	// BKDBElemMatch matches documents that contain an array field with at least one element that matches all the
+	// specified query criteria.
+	BKDBElemMatch = "$elemMatch"
+
 	// BKDBProject passes along the documents with the requested fields to the next stage in the pipeline
 	BKDBProject = "$project"
 
@@ -490,6 +494,15 @@ const (
 	// BKExtendResourceNameField the audit extend resource name field
 	BKExtendResourceNameField = "extend_resource_name"
 
+	// BKAuditSeqNoField the audit hash chain sequence number field
+	BKAuditSeqNoField = "seq_no"
+
+	// BKAuditHashField the audit hash chain hash field
+	BKAuditHashField = "hash"
+
+	// BKAuditPrevHashField the audit hash chain previous hash field
+	BKAuditPrevHashField = "prev_hash"
+
 	// BKLabelField the audit resource name field
 	BKLabelField = "label"
 
@@ -1614,6 +1627,9 @@ const (
 	BKVpcName                    = "bk_vpc_name"
 	BKRegion                     = "bk_region"
 	BKCloudSyncVpcs              = "bk_sync_vpcs"
+	BKCloudSyncMode              = "bk_sync_mode"
+	BKCloudSyncRemoveThreshold   = "bk_remove_threshold"
+	BKCloudSyncPreviewID         = "bk_preview_id"
 
 	// 是否为被销毁的云主机
 	IsDestroyedCloudHost = "is_destroyed_cloud_host"
@@ -745,6 +745,8 @@ const (
 	CCErrHostFindManyCloudAreaAddHostCountFieldFail           = 1110065
 	CCErrDeleteDefaultCloudAreaFail                           = 1110066
 	CCErrHostFindManyCloudAreaAddSyncTaskIDsFieldFail         = 1110067
+	// CCErrHostLocked hosts %v are locked, %s operation is not allowed
+	CCErrHostLocked = 1110068
 
 	// web 1111XXX
 	CCErrWebFileNoFound                 = 1111001
@@ -941,6 +943,12 @@ const (
 	CCErrGetCloudAccountConfBatchFailed       = 1118021
 	CCErrDeleteDestroyedHostRelatedFailed     = 1118022
 	CCErrCloudAccountDeletedFailedForSyncTask = 1118023
+	// CCErrCloudSyncRemoveExceedThreshold the ratio of the hosts to be removed by cloud sync exceeds the threshold
+	CCErrCloudSyncRemoveExceedThreshold = 1118024
+	// CCErrCloudSyncPreviewNotPending cloud sync preview not exists or is not pending for approval
+	CCErrCloudSyncPreviewNotPending = 1118025
+	// CCErrCloudSyncPreviewStale cloud sync preview is expired or the task has synced again since it was generated
+	CCErrCloudSyncPreviewStale = 1118026
 
 	/** TODO: 以下错误码需要改造 **/
 
@@ -29,7 +29,21 @@ func init() {
 
 //  新加和修改后的索引,索引名字一定要用对应的前缀，CCLogicUniqueIdxNamePrefix|common.CCLogicIndexNamePrefix
 
-var commAuditLogIndexes = []types.Index{}
+var commAuditLogIndexes = []types.Index{
+	{
+		Name: common.CCLogicUniqueIdxNamePrefix + "bkSupplierAccount_seqNo",
+		Keys: bson.D{
+			{common.BKOwnerIDField, 1},
+			{common.BKAuditSeqNoField, 1},
+		},
+		Unique:     true,
+		Background: true,
+		// only audit logs in the hash chain have the sequence number
+		PartialFilterExpression: map[string]interface{}{
+			common.BKAuditSeqNoField: map[string]string{common.BKDBType: "number"},
+		},
+	},
+}
 
 // deprecated 未规范化前的索引，只允许删除不允许新加和修改，
 var deprecatedAuditLogIndexes = []types.Index{
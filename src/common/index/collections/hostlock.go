@@ -29,7 +29,17 @@ func init() {
 
 //  新加和修改后的索引,索引名字一定要用对应的前缀，CCLogicUniqueIdxNamePrefix|common.CCLogicIndexNamePrefix
 
-var commHostLockIndexes = []types.Index{}
+var commHostLockIndexes = []types.Index{
+	{
+		// expired host locks are removed by mongodb, the locks without expire time are never removed
+		Name: common.CCLogicIndexNamePrefix + "expire_time",
+		Keys: bson.D{
+			{"expire_time", 1},
+		},
+		Background:         true,
+		ExpireAfterSeconds: 1,
+	},
+}
 
 // deprecated 未规范化前的索引，只允许删除不允许新加和修改，
 var deprecatedHostLockIndexes = []types.Index{
@@ -42,6 +42,11 @@ func init() {
 	for _, table := range workLoadTables {
 		registerIndexes(table, commWorkLoadIndexes)
 	}
+
+	// namespace scoped resources are unique by name in namespace just like workloads
+	for _, table := range kubetypes.GetNsResourceTables() {
+		registerIndexes(table, commWorkLoadIndexes)
+	}
 }
 
 var commWorkLoadIndexes = []types.Index{
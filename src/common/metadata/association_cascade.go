@@ -0,0 +1,84 @@
+/*
+ * Tencent is pleased to support the open source community by making 蓝鲸 available.
+ * Copyright (C) 2017-2018 THL A29 Limited, a Tencent company. All rights reserved.
+ * Licensed under the MIT License (the "License"); you may not use this file except
+ * in compliance with the License. You may obtain a copy of the License at
+ * http://opensource.org/licenses/MIT
+ * Unless required by applicable law or agreed to in writing, software distributed under
+ * the License is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND,
+ * either express or implied. See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package metadata
+
+import (
+	"configcenter/src/common"
+	"configcenter/src/common/errors"
+)
+
+const (
+	// InstCascadeDeleteMaxDepth is the max depth of the cascade deletion tree caused by the association on delete
+	// actions, deletion is rejected if the instances are still cascaded to be deleted beyond this depth.
+	InstCascadeDeleteMaxDepth = 10
+	// InstCascadeDeletePreviewLimit is the max number of instances that can be previewed in one request
+	InstCascadeDeletePreviewLimit = 100
+)
+
+// IsValid checks if the association on delete action is valid
+func (a AssociationOnDeleteAction) IsValid() bool {
+	switch a {
+	case NoAction, DeleteSource, DeleteDestinatioin:
+		return true
+	default:
+		return false
+	}
+}
+
+// InstCascadeDeletePreviewOption is the option to preview the instances that will be deleted in cascade
+type InstCascadeDeletePreviewOption struct {
+	InstIDs []int64 `json:"inst_ids"`
+}
+
+// Validate InstCascadeDeletePreviewOption
+func (o *InstCascadeDeletePreviewOption) Validate() errors.RawErrorInfo {
+	if len(o.InstIDs) == 0 {
+		return errors.RawErrorInfo{ErrCode: common.CCErrCommParamsNeedSet, Args: []interface{}{"inst_ids"}}
+	}
+
+	if len(o.InstIDs) > InstCascadeDeletePreviewLimit {
+		return errors.RawErrorInfo{ErrCode: common.CCErrCommXXExceedLimit,
+			Args: []interface{}{"inst_ids", InstCascadeDeletePreviewLimit}}
+	}
+
+	for _, id := range o.InstIDs {
+		if id <= 0 {
+			return errors.RawErrorInfo{ErrCode: common.CCErrCommParamsInvalid, Args: []interface{}{"inst_ids"}}
+		}
+	}
+
+	return errors.RawErrorInfo{}
+}
+
+// InstCascadeNode is a node of the cascade deletion tree, the children are the instances that will be deleted
+// because of this instance's deletion.
+type InstCascadeNode struct {
+	ObjectID string `json:"bk_obj_id"`
+	InstID   int64  `json:"bk_inst_id"`
+	InstName string `json:"bk_inst_name"`
+	// AsstID is the id of the instance association that causes this instance to be deleted, it is 0 for the
+	// instances that are deleted directly.
+	AsstID int64 `json:"asst_id,omitempty"`
+	// ObjectAsstID is the object association id of the instance association
+	ObjectAsstID string `json:"bk_obj_asst_id,omitempty"`
+	// OnDelete is the on delete action of the object association
+	OnDelete AssociationOnDeleteAction `json:"on_delete,omitempty"`
+	Children []*InstCascadeNode        `json:"children"`
+}
+
+// InstCascadeDeletePreviewResult is the cascade deletion trees of the instances to be deleted
+type InstCascadeDeletePreviewResult struct {
+	// Count is the total number of the instances that will be deleted, including the directly deleted ones
+	Count int                `json:"count"`
+	Tree  []*InstCascadeNode `json:"tree"`
+}
@@ -205,6 +205,13 @@ type AuditLog struct {
 	RequestID string `json:"rid,omitempty" bson:"rid,omitempty"`
 	// todo ExtendResourceName for the temporary solution of ipv6
 	ExtendResourceName string `json:"extend_resource_name" bson:"extend_resource_name"`
+	// SeqNo is the sequence number of the audit log in its supplier account's hash chain, only set when the
+	// audit log hash chain is enabled.
+	SeqNo int64 `json:"seq_no,omitempty" bson:"seq_no,omitempty"`
+	// PrevHash is the hash of the previous audit log in the hash chain.
+	PrevHash string `json:"prev_hash,omitempty" bson:"prev_hash,omitempty"`
+	// Hash is the hash of this audit log, which is calculated with the PrevHash so that it links to the chain.
+	Hash string `json:"hash,omitempty" bson:"hash,omitempty"`
 }
 
 type bsonAuditLog struct {
@@ -223,6 +230,9 @@ type bsonAuditLog struct {
 	AppCode            string          `json:"code" bson:"code"`
 	RequestID          string          `json:"rid" bson:"rid"`
 	ExtendResourceName string          `json:"extend_resource_name" bson:"extend_resource_name"`
+	SeqNo              int64           `json:"seq_no,omitempty" bson:"seq_no,omitempty"`
+	PrevHash           string          `json:"prev_hash,omitempty" bson:"prev_hash,omitempty"`
+	Hash               string          `json:"hash,omitempty" bson:"hash,omitempty"`
 }
 
 type jsonAuditLog struct {
@@ -241,6 +251,9 @@ type jsonAuditLog struct {
 	AppCode            string          `json:"code" bson:"code"`
 	RequestID          string          `json:"rid" bson:"rid"`
 	ExtendResourceName string          `json:"extend_resource_name" bson:"extend_resource_name"`
+	SeqNo              int64           `json:"seq_no,omitempty" bson:"seq_no,omitempty"`
+	PrevHash           string          `json:"prev_hash,omitempty" bson:"prev_hash,omitempty"`
+	Hash               string          `json:"hash,omitempty" bson:"hash,omitempty"`
 }
 
 // DetailFactory TODO
@@ -289,6 +302,9 @@ func (auditLog *AuditLog) UnmarshalJSON(data []byte) error {
 	auditLog.AppCode = audit.AppCode
 	auditLog.RequestID = audit.RequestID
 	auditLog.ExtendResourceName = audit.ExtendResourceName
+	auditLog.SeqNo = audit.SeqNo
+	auditLog.PrevHash = audit.PrevHash
+	auditLog.Hash = audit.Hash
 
 	if audit.OperationDetail == nil {
 		return nil
@@ -353,6 +369,9 @@ func (auditLog *AuditLog) UnmarshalBSON(data []byte) error {
 	auditLog.AppCode = audit.AppCode
 	auditLog.RequestID = audit.RequestID
 	auditLog.ExtendResourceName = audit.ExtendResourceName
+	auditLog.SeqNo = audit.SeqNo
+	auditLog.PrevHash = audit.PrevHash
+	auditLog.Hash = audit.Hash
 
 	if audit.OperationDetail == nil {
 		return nil
@@ -412,6 +431,9 @@ func (auditLog AuditLog) MarshalBSON() ([]byte, error) {
 	audit.AppCode = auditLog.AppCode
 	audit.RequestID = auditLog.RequestID
 	audit.ExtendResourceName = auditLog.ExtendResourceName
+	audit.SeqNo = auditLog.SeqNo
+	audit.PrevHash = auditLog.PrevHash
+	audit.Hash = auditLog.Hash
 	var err error
 	switch val := auditLog.OperationDetail.(type) {
 	default:
@@ -749,6 +771,9 @@ const (
 	KubeWorkload ResourceType = "kube_workload"
 	// KubePod kube pod audit resource type
 	KubePod ResourceType = "kube_pod"
+	// KubeNsResource kube namespace scoped resource audit resource type, including service, ingress, endpoints and
+	// configMap
+	KubeNsResource ResourceType = "kube_ns_resource"
 
 	// QuotedInst is quoted instance related audit resource type
 	QuotedInst ResourceType = "quoted_inst"
@@ -777,6 +802,8 @@ const (
 	FromSynchronizer OperateFromType = "synchronizer"
 	// FromCloudSync means this audit is created by cloud sync.
 	FromCloudSync OperateFromType = "cloud_sync"
+	// FromCascadeDelete means this audit is created by the cascade deletion of the association on delete action.
+	FromCascadeDelete OperateFromType = "cascade_delete"
 )
 
 // ActionType defines all the user's operation type
@@ -813,6 +840,14 @@ const (
 	// AuditResume TODO
 	// resume using an object
 	AuditResume ActionType = "resume"
+	// AuditLock lock a host
+	AuditLock ActionType = "lock"
+	// AuditUnlock unlock a host
+	AuditUnlock ActionType = "unlock"
+	// AuditForceUnlock unlock a host that is locked by another owner
+	AuditForceUnlock ActionType = "force_unlock"
+	// AuditRestore restore a deleted resource from the delete archive
+	AuditRestore ActionType = "restore"
 )
 
 // GetAuditTypeByObjID TODO
@@ -909,6 +944,7 @@ var auditDict = []resourceTypeInfo{
 			actionInfoMap[AuditCreate],
 			actionInfoMap[AuditUpdate],
 			actionInfoMap[AuditDelete],
+			actionInfoMap[AuditRestore],
 		},
 	},
 	{
@@ -918,6 +954,7 @@ var auditDict = []resourceTypeInfo{
 			actionInfoMap[AuditCreate],
 			actionInfoMap[AuditUpdate],
 			actionInfoMap[AuditDelete],
+			actionInfoMap[AuditRestore],
 		},
 	},
 	{
@@ -927,6 +964,7 @@ var auditDict = []resourceTypeInfo{
 			actionInfoMap[AuditCreate],
 			actionInfoMap[AuditUpdate],
 			actionInfoMap[AuditDelete],
+			actionInfoMap[AuditRestore],
 		},
 	},
 	{
@@ -948,6 +986,10 @@ var auditDict = []resourceTypeInfo{
 			actionInfoMap[AuditAssignHost],
 			actionInfoMap[AuditUnassignHost],
 			actionInfoMap[AuditTransferHostModule],
+			actionInfoMap[AuditLock],
+			actionInfoMap[AuditUnlock],
+			actionInfoMap[AuditForceUnlock],
+			actionInfoMap[AuditRestore],
 		},
 	},
 	{
@@ -994,6 +1036,7 @@ var auditDict = []resourceTypeInfo{
 			actionInfoMap[AuditCreate],
 			actionInfoMap[AuditUpdate],
 			actionInfoMap[AuditDelete],
+			actionInfoMap[AuditRestore],
 		},
 	},
 	{
@@ -1128,6 +1171,10 @@ var actionInfoMap = map[ActionType]actionTypeInfo{
 	AuditRecover:            {ID: AuditRecover, Name: "恢复"},
 	AuditPause:              {ID: AuditPause, Name: "停用"},
 	AuditResume:             {ID: AuditResume, Name: "启用"},
+	AuditLock:               {ID: AuditLock, Name: "锁定"},
+	AuditUnlock:             {ID: AuditUnlock, Name: "解锁"},
+	AuditForceUnlock:        {ID: AuditForceUnlock, Name: "强制解锁"},
+	AuditRestore:            {ID: AuditRestore, Name: "还原"},
 }
 
 type resourceTypeInfo struct {
@@ -1172,6 +1219,7 @@ var auditEnDict = []resourceTypeInfo{
 			actionInfoEnMap[AuditCreate],
 			actionInfoEnMap[AuditUpdate],
 			actionInfoEnMap[AuditDelete],
+			actionInfoEnMap[AuditRestore],
 		},
 	},
 	{
@@ -1181,6 +1229,7 @@ var auditEnDict = []resourceTypeInfo{
 			actionInfoEnMap[AuditCreate],
 			actionInfoEnMap[AuditUpdate],
 			actionInfoEnMap[AuditDelete],
+			actionInfoEnMap[AuditRestore],
 		},
 	},
 	{
@@ -1190,6 +1239,7 @@ var auditEnDict = []resourceTypeInfo{
 			actionInfoEnMap[AuditCreate],
 			actionInfoEnMap[AuditUpdate],
 			actionInfoEnMap[AuditDelete],
+			actionInfoEnMap[AuditRestore],
 		},
 	},
 	{
@@ -1211,6 +1261,9 @@ var auditEnDict = []resourceTypeInfo{
 			actionInfoEnMap[AuditAssignHost],
 			actionInfoEnMap[AuditUnassignHost],
 			actionInfoEnMap[AuditTransferHostModule],
+			actionInfoEnMap[AuditLock],
+			actionInfoEnMap[AuditUnlock],
+			actionInfoEnMap[AuditRestore],
 		},
 	},
 	{
@@ -1257,6 +1310,7 @@ var auditEnDict = []resourceTypeInfo{
 			actionInfoEnMap[AuditCreate],
 			actionInfoEnMap[AuditUpdate],
 			actionInfoEnMap[AuditDelete],
+			actionInfoEnMap[AuditRestore],
 		},
 	},
 	{
@@ -1390,4 +1444,7 @@ var actionInfoEnMap = map[ActionType]actionTypeInfo{
 	AuditRecover:            {ID: AuditRecover, Name: "Recover"},
 	AuditPause:              {ID: AuditPause, Name: "Pause"},
 	AuditResume:             {ID: AuditResume, Name: "Resume"},
+	AuditLock:               {ID: AuditLock, Name: "Lock"},
+	AuditUnlock:             {ID: AuditUnlock, Name: "Unlock"},
+	AuditRestore:            {ID: AuditRestore, Name: "Restore"},
 }
@@ -0,0 +1,109 @@
+/*
+ * Tencent is pleased to support the open source community by making 蓝鲸 available.
+ * Copyright (C) 2017-2018 THL A29 Limited, a Tencent company. All rights reserved.
+ * Licensed under the MIT License (the "License"); you may not use this file except
+ * in compliance with the License. You may obtain a copy of the License at
+ * http://opensource.org/licenses/MIT
+ * Unless required by applicable law or agreed to in writing, software distributed under
+ * the License is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND,
+ * either express or implied. See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package metadata
+
+import (
+	"configcenter/src/common"
+	"configcenter/src/common/errors"
+)
+
+// AuditChainVerifyLimit is the max number of audit logs in the hash chain that can be verified in one request
+const AuditChainVerifyLimit = 1000
+
+// AuditChainHead is the head of a supplier account's audit log hash chain, it records the latest audit log that is
+// linked to the chain, so that the removal of the latest audit logs can be detected.
+type AuditChainHead struct {
+	SupplierAccount string `json:"bk_supplier_account" bson:"bk_supplier_account"`
+	// SeqNo is the sequence number of the latest audit log in the chain
+	SeqNo int64 `json:"seq_no" bson:"seq_no"`
+	// Hash is the hash of the latest audit log in the chain
+	Hash string `json:"hash" bson:"hash"`
+	// PrunedSeqNo is the max sequence number of the audit logs that are removed by the audit log cleaning,
+	// the chain is verified from the audit log after it.
+	PrunedSeqNo int64 `json:"pruned_seq_no" bson:"pruned_seq_no"`
+	// PrunedHash is the hash of the audit log with PrunedSeqNo, it is the previous hash of the first remaining one.
+	PrunedHash string `json:"pruned_hash" bson:"pruned_hash"`
+	LastTime   Time   `json:"last_time" bson:"last_time"`
+}
+
+// VerifyAuditChainOption is the option to verify a range of the audit log hash chain
+type VerifyAuditChainOption struct {
+	// StartSeqNo is the sequence number to verify from, default is the first audit log that is not pruned.
+	StartSeqNo int64 `json:"start_seq_no"`
+	// Limit is the max number of sequence numbers to verify from StartSeqNo
+	Limit int64 `json:"limit"`
+}
+
+// Validate VerifyAuditChainOption
+func (o *VerifyAuditChainOption) Validate() errors.RawErrorInfo {
+	if o.StartSeqNo < 0 {
+		return errors.RawErrorInfo{ErrCode: common.CCErrCommParamsInvalid, Args: []interface{}{"start_seq_no"}}
+	}
+
+	if o.Limit <= 0 {
+		return errors.RawErrorInfo{ErrCode: common.CCErrCommParamsInvalid, Args: []interface{}{"limit"}}
+	}
+
+	if o.Limit > AuditChainVerifyLimit {
+		return errors.RawErrorInfo{ErrCode: common.CCErrCommXXExceedLimit,
+			Args: []interface{}{"limit", AuditChainVerifyLimit}}
+	}
+
+	return errors.RawErrorInfo{}
+}
+
+// AuditChainIssueType is the type of the problem found when verifying the audit log hash chain
+type AuditChainIssueType string
+
+const (
+	// AuditChainGap means the audit logs between StartSeqNo and EndSeqNo are missing
+	AuditChainGap AuditChainIssueType = "gap"
+	// AuditChainHashMismatch means the audit log's content does not match its hash, it has been edited
+	AuditChainHashMismatch AuditChainIssueType = "hash_mismatch"
+	// AuditChainLinkMismatch means the audit log's previous hash does not match the hash of the previous one
+	AuditChainLinkMismatch AuditChainIssueType = "link_mismatch"
+	// AuditChainHeadMismatch means the chain head does not match the latest audit log in the chain
+	AuditChainHeadMismatch AuditChainIssueType = "head_mismatch"
+)
+
+// AuditChainIssue is a problem found when verifying the audit log hash chain
+type AuditChainIssue struct {
+	Type       AuditChainIssueType `json:"type"`
+	StartSeqNo int64               `json:"start_seq_no"`
+	EndSeqNo   int64               `json:"end_seq_no"`
+	// AuditID is the id of the audit log that has the problem, it is empty for gap
+	AuditID  int64  `json:"audit_id,omitempty"`
+	Expected string `json:"expected,omitempty"`
+	Actual   string `json:"actual,omitempty"`
+}
+
+// VerifyAuditChainResult is the result of verifying a range of the audit log hash chain
+type VerifyAuditChainResult struct {
+	// HeadSeqNo is the sequence number of the latest audit log in the chain
+	HeadSeqNo int64 `json:"head_seq_no"`
+	// PrunedSeqNo is the max sequence number of the audit logs that are removed by the audit log cleaning
+	PrunedSeqNo int64 `json:"pruned_seq_no"`
+	// Checked is the number of audit logs that are verified in this request
+	Checked int64 `json:"checked"`
+	// NextSeqNo is the sequence number to verify from in the next request
+	NextSeqNo int64 `json:"next_seq_no"`
+	// Finished means the whole chain after StartSeqNo has been verified
+	Finished bool              `json:"finished"`
+	Issues   []AuditChainIssue `json:"issues"`
+}
+
+// VerifyAuditChainResp is the response of verifying the audit log hash chain
+type VerifyAuditChainResp struct {
+	BaseResp `json:",inline"`
+	Data     *VerifyAuditChainResult `json:"data"`
+}
@@ -0,0 +1,147 @@
+/*
+ * Tencent is pleased to support the open source community by making 蓝鲸 available.
+ * Copyright (C) 2017-2018 THL A29 Limited, a Tencent company. All rights reserved.
+ * Licensed under the MIT License (the "License"); you may not use this file except
+ * in compliance with the License. You may obtain a copy of the License at
+ * http://opensource.org/licenses/MIT
+ * Unless required by applicable law or agreed to in writing, software distributed under
+ * the License is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND,
+ * either express or implied. See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package metadata
+
+import (
+	"time"
+
+	"configcenter/src/common"
+	"configcenter/src/common/errors"
+	"configcenter/src/common/mapstr"
+
+	"github.com/coccyx/timeparser"
+)
+
+// AuditReplayLimit is the max number of audit logs that can be replayed in one request
+const AuditReplayLimit = 2000
+
+// AuditDataActions are the audit actions that change the data of an instance, their operation details contain the
+// data before or after the operation, which can be replayed to reconstruct the instance at a past time.
+var AuditDataActions = []ActionType{AuditCreate, AuditUpdate, AuditDelete, AuditArchive, AuditRecover,
+	AuditRestore}
+
+// AuditHostTopoActions are the audit actions that change the business topology of a host
+var AuditHostTopoActions = []ActionType{AuditTransferHostModule, AuditAssignHost, AuditUnassignHost}
+
+// AuditSnapshotOption reconstruct the instance's state at a past time by replaying its audit logs
+type AuditSnapshotOption struct {
+	ObjID  string `json:"bk_obj_id"`
+	InstID int64  `json:"bk_inst_id"`
+	// AsOf is the past time to reconstruct the instance at, its format is the same as the audit operation time
+	AsOf string `json:"as_of"`
+}
+
+// Validate validates the audit snapshot option
+func (o *AuditSnapshotOption) Validate() errors.RawErrorInfo {
+	if len(o.ObjID) == 0 {
+		return errors.RawErrorInfo{ErrCode: common.CCErrCommParamsNeedSet, Args: []interface{}{common.BKObjIDField}}
+	}
+
+	if o.InstID <= 0 {
+		return errors.RawErrorInfo{ErrCode: common.CCErrCommParamsInvalid, Args: []interface{}{common.BKInstIDField}}
+	}
+
+	return validateAuditAsOf(o.AsOf)
+}
+
+func validateAuditAsOf(asOf string) errors.RawErrorInfo {
+	if len(asOf) == 0 {
+		return errors.RawErrorInfo{ErrCode: common.CCErrCommParamsNeedSet, Args: []interface{}{"as_of"}}
+	}
+
+	if _, err := timeparser.TimeParserInLocation(asOf, time.Local); err != nil {
+		return errors.RawErrorInfo{ErrCode: common.CCErrCommParamsInvalid, Args: []interface{}{"as_of"}}
+	}
+
+	return errors.RawErrorInfo{}
+}
+
+// AuditSnapshot is the reconstructed state of an instance at a past time
+type AuditSnapshot struct {
+	ObjID  string `json:"bk_obj_id"`
+	InstID int64  `json:"bk_inst_id"`
+	AsOf   string `json:"as_of"`
+	// Exists represents whether the instance existed at the time
+	Exists bool `json:"exists"`
+	// Data is the instance data at the time, if the instance has been deleted at the time, it is the last known data
+	Data mapstr.MapStr `json:"data"`
+	// AuditID is the id of the audit log that the data is reconstructed from, 0 if the data is the current data
+	AuditID int64 `json:"audit_id"`
+	// HostTopo is the business topology of the host at the time, it is only set for host whose topology has been
+	// changed after the time, otherwise the host's topology is the same as now
+	HostTopo *HostBizTopo `json:"host_topo,omitempty"`
+}
+
+// BizTopoAuditSnapshotOption reconstruct the business topology at a past time by replaying the audit logs
+type BizTopoAuditSnapshotOption struct {
+	BizID int64  `json:"bk_biz_id"`
+	AsOf  string `json:"as_of"`
+}
+
+// Validate validates the business topology audit snapshot option
+func (o *BizTopoAuditSnapshotOption) Validate() errors.RawErrorInfo {
+	if o.BizID <= 0 {
+		return errors.RawErrorInfo{ErrCode: common.CCErrCommParamsInvalid, Args: []interface{}{common.BKAppIDField}}
+	}
+
+	return validateAuditAsOf(o.AsOf)
+}
+
+// AuditTimelineOption get the field level change timeline of an instance from its audit logs
+type AuditTimelineOption struct {
+	ObjID         string                 `json:"bk_obj_id"`
+	InstID        int64                  `json:"bk_inst_id"`
+	OperationTime OperationTimeCondition `json:"operation_time"`
+	// Fields are the fields whose changes are returned, returns all changed fields if not set
+	Fields []string `json:"fields"`
+}
+
+// Validate validates the audit timeline option
+func (o *AuditTimelineOption) Validate() errors.RawErrorInfo {
+	if len(o.ObjID) == 0 {
+		return errors.RawErrorInfo{ErrCode: common.CCErrCommParamsNeedSet, Args: []interface{}{common.BKObjIDField}}
+	}
+
+	if o.InstID <= 0 {
+		return errors.RawErrorInfo{ErrCode: common.CCErrCommParamsInvalid, Args: []interface{}{common.BKInstIDField}}
+	}
+
+	for _, t := range []string{o.OperationTime.Start, o.OperationTime.End} {
+		if len(t) == 0 {
+			continue
+		}
+
+		if _, err := timeparser.TimeParserInLocation(t, time.Local); err != nil {
+			return errors.RawErrorInfo{ErrCode: common.CCErrCommParamsInvalid,
+				Args: []interface{}{common.BKOperationTimeField}}
+		}
+	}
+
+	return errors.RawErrorInfo{}
+}
+
+// AuditTimelineItem is the field level changes of an instance in one audit log
+type AuditTimelineItem struct {
+	AuditID       int64              `json:"audit_id"`
+	User          string             `json:"user"`
+	Action        ActionType         `json:"action"`
+	OperationTime Time               `json:"operation_time"`
+	Changes       []AuditFieldChange `json:"changes"`
+}
+
+// AuditFieldChange is the change of one field, the value is nil if the field does not exist
+type AuditFieldChange struct {
+	Field    string      `json:"field"`
+	PreValue interface{} `json:"pre_value"`
+	CurValue interface{} `json:"cur_value"`
+}
@@ -65,7 +65,8 @@ func (c *CloudAccount) Validate() (rawError errors.RawErrorInfo) {
 		}
 	}
 
-	if c.SecretKey == "" {
+	// 通用云厂商的bk_secret_id为资源清单地址，bk_secret_key为可选的访问令牌
+	if c.SecretKey == "" && c.CloudVendor != Generic {
 		return errors.RawErrorInfo{
 			ErrCode: common.CCErrCommParamsNeedSet,
 			Args:    []interface{}{"bk_secret_key"},
@@ -87,11 +88,13 @@ type CloudAccountWithExtraInfo struct {
 const (
 	AWS          string = "1"
 	TencentCloud string = "2"
+	// Generic 通用云厂商，对应属性表中的"企业私有云"，从HTTP接口或本地清单文件中读取云资源
+	Generic string = "5"
 )
 
 // SupportedCloudVendors 支持的云厂商
 // 实现了相应的云厂商插件
-var SupportedCloudVendors = []string{AWS, TencentCloud}
+var SupportedCloudVendors = []string{AWS, TencentCloud, Generic}
 
 // 云同步任务同步状态
 const (
@@ -243,10 +246,14 @@ type CloudSyncTask struct {
 	SyncAll           bool           `json:"bk_sync_all" bson:"bk_sync_all"`
 	SyncAllDir        int64          `json:"bk_sync_all_dir" bson:"bk_sync_all_dir"`
 	SyncVpcs          []VpcSyncInfo  `json:"bk_sync_vpcs" bson:"bk_sync_vpcs"`
-	Creator           string         `json:"bk_creator" bson:"bk_creator"`
-	LastEditor        string         `json:"bk_last_editor" bson:"bk_last_editor"`
-	CreateTime        time.Time      `json:"create_time" bson:"create_time"`
-	LastTime          time.Time      `json:"last_time" bson:"last_time"`
+	// SyncMode 同步模式，为空时表示直接同步
+	SyncMode CloudSyncMode `json:"bk_sync_mode" bson:"bk_sync_mode"`
+	// RemoveThreshold 一次同步允许移除的主机百分比阈值(0~100)，0表示不限制，超过阈值时需要审批同步预览后才能同步
+	RemoveThreshold int64     `json:"bk_remove_threshold" bson:"bk_remove_threshold"`
+	Creator         string    `json:"bk_creator" bson:"bk_creator"`
+	LastEditor      string    `json:"bk_last_editor" bson:"bk_last_editor"`
+	CreateTime      time.Time `json:"create_time" bson:"create_time"`
+	LastTime        time.Time `json:"last_time" bson:"last_time"`
 }
 
 // VpcSyncInfo TODO
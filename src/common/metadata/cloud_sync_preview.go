@@ -0,0 +1,226 @@
+/*
+ * Tencent is pleased to support the open source community by making 蓝鲸 available.
+ * Copyright (C) 2017-2018 THL A29 Limited, a Tencent company. All rights reserved.
+ * Licensed under the MIT License (the "License"); you may not use this file except
+ * in compliance with the License. You may obtain a copy of the License at
+ * http://opensource.org/licenses/MIT
+ * Unless required by applicable law or agreed to in writing, software distributed under
+ * the License is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND,
+ * either express or implied. See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package metadata
+
+import (
+	"math"
+	"time"
+
+	"configcenter/src/common"
+	"configcenter/src/common/errors"
+)
+
+// CloudSyncMode 云同步任务的同步模式
+type CloudSyncMode string
+
+const (
+	// CloudSyncModeDirect 直接同步，每次同步时直接将差异应用到主机上，为默认的同步模式
+	CloudSyncModeDirect CloudSyncMode = "direct"
+	// CloudSyncModeApproval 审批同步，每次同步时只生成同步预览，审批同步预览后才将差异应用到主机上
+	CloudSyncModeApproval CloudSyncMode = "approval"
+)
+
+// Validate 校验同步模式，为空时表示直接同步
+func (m CloudSyncMode) Validate() error {
+	switch m {
+	case "", CloudSyncModeDirect, CloudSyncModeApproval:
+		return nil
+	default:
+		return errors.New(common.CCErrCommParamsIsInvalid, common.BKCloudSyncMode)
+	}
+}
+
+// ValidateCloudSyncRemoveThreshold 校验同步时允许移除的主机比例阈值，取值范围为0~100，0表示不限制
+func ValidateCloudSyncRemoveThreshold(threshold int64) error {
+	if threshold < 0 || threshold > 100 {
+		return errors.New(common.CCErrCommParamsIsInvalid, common.BKCloudSyncRemoveThreshold)
+	}
+	return nil
+}
+
+// CloudSyncPreviewStatus 云同步预览的状态
+type CloudSyncPreviewStatus string
+
+const (
+	// CloudSyncPreviewPending 待审批，每个同步任务只保留最新的一个待审批的同步预览
+	CloudSyncPreviewPending CloudSyncPreviewStatus = "pending"
+	// CloudSyncPreviewApplied 已审批并应用到主机上
+	CloudSyncPreviewApplied CloudSyncPreviewStatus = "applied"
+)
+
+// CloudSyncPreview 云同步预览，记录一次同步将要新增、更新和移除的主机，以及被销毁的vpc
+type CloudSyncPreview struct {
+	PreviewID int64                  `json:"bk_preview_id" bson:"bk_preview_id"`
+	TaskID    int64                  `json:"bk_task_id" bson:"bk_task_id"`
+	Status    CloudSyncPreviewStatus `json:"status" bson:"status"`
+	Add       []CloudSyncHostDiff    `json:"add" bson:"add"`
+	Update    []CloudSyncHostDiff    `json:"update" bson:"update"`
+	// Remove 云端已不存在的主机，同步后会被置为已销毁状态
+	Remove []CloudSyncHostDiff `json:"remove" bson:"remove"`
+	// DestroyedVpcs 云端被销毁的vpc，其下的主机同步后都会被置为已销毁状态
+	DestroyedVpcs []CloudSyncDestroyedVpc `json:"destroyed_vpcs" bson:"destroyed_vpcs"`
+	// LocalHostCount 同步范围内未销毁的云主机个数，用于计算移除主机的比例
+	LocalHostCount int64 `json:"local_host_count" bson:"local_host_count"`
+	// RemovePercent 将要移除的主机个数占同步范围内未销毁的云主机个数的百分比
+	RemovePercent float64 `json:"remove_percent" bson:"remove_percent"`
+	// ExceedThreshold 移除主机的比例是否超过了同步任务设置的阈值
+	ExceedThreshold bool       `json:"exceed_threshold" bson:"exceed_threshold"`
+	Approver        string     `json:"approver,omitempty" bson:"approver,omitempty"`
+	ApplyTime       *time.Time `json:"apply_time,omitempty" bson:"apply_time,omitempty"`
+	OwnerID         string     `json:"bk_supplier_account" bson:"bk_supplier_account"`
+	CreateTime      time.Time  `json:"create_time" bson:"create_time"`
+	// ExpireTime 同步预览的过期时间，过期后云端和本地的主机可能已经变化，不能再审批
+	ExpireTime time.Time `json:"expire_time" bson:"expire_time"`
+}
+
+// IsStale 判断同步预览是否已过期，或者同步任务在同步预览生成后是否已再次同步，过时的同步预览不能再审批
+func (p *CloudSyncPreview) IsStale(task *CloudSyncTask, now time.Time) bool {
+	if !p.ExpireTime.IsZero() && now.After(p.ExpireTime) {
+		return true
+	}
+	return task.LastSyncTime != nil && task.LastSyncTime.After(p.CreateTime)
+}
+
+// IsEmpty 判断同步预览是否没有任何差异
+func (p *CloudSyncPreview) IsEmpty() bool {
+	return len(p.Add) == 0 && len(p.Update) == 0 && len(p.Remove) == 0 && len(p.DestroyedVpcs) == 0
+}
+
+// RemoveCount 将要移除的主机个数，包括云端已不存在的主机和被销毁的vpc下的主机
+func (p *CloudSyncPreview) RemoveCount() int64 {
+	count := int64(len(p.Remove))
+	for _, vpc := range p.DestroyedVpcs {
+		count += int64(len(vpc.Hosts))
+	}
+	return count
+}
+
+// CheckRemoveThreshold 计算将要移除的主机比例(保留一位小数)，并判断是否超过了同步任务设置的阈值，阈值为0表示不限制
+func (p *CloudSyncPreview) CheckRemoveThreshold(threshold int64) {
+	p.RemovePercent = 0
+	if p.LocalHostCount > 0 {
+		percent := float64(p.RemoveCount()) * 100 / float64(p.LocalHostCount)
+		p.RemovePercent = math.Round(percent*10) / 10
+	}
+	p.ExceedThreshold = threshold > 0 && p.RemovePercent > float64(threshold)
+}
+
+// CloudSyncDestroyedVpc 同步预览中被销毁的vpc及其下将要移除的主机
+type CloudSyncDestroyedVpc struct {
+	VpcSyncInfo `json:",inline" bson:",inline"`
+	Hosts       []CloudSyncHostDiff `json:"hosts" bson:"hosts"`
+}
+
+// CloudSyncHostDiff 同步预览中的一个主机差异，新增和更新时为云端的主机信息，移除时为本地的主机信息
+type CloudSyncHostDiff struct {
+	HostID        int64  `json:"bk_host_id" bson:"bk_host_id"`
+	InstanceID    string `json:"bk_cloud_inst_id" bson:"bk_cloud_inst_id"`
+	CloudID       int64  `json:"bk_cloud_id" bson:"bk_cloud_id"`
+	PrivateIP     string `json:"bk_host_innerip" bson:"bk_host_innerip"`
+	PublicIP      string `json:"bk_host_outerip" bson:"bk_host_outerip"`
+	InstanceState string `json:"bk_cloud_host_status" bson:"bk_cloud_host_status"`
+	SyncDir       int64  `json:"bk_sync_dir,omitempty" bson:"bk_sync_dir,omitempty"`
+	VendorName    string `json:"bk_cloud_vendor,omitempty" bson:"bk_cloud_vendor,omitempty"`
+	// Changes 更新时有变化的字段
+	Changes []CloudSyncFieldChange `json:"changes,omitempty" bson:"changes,omitempty"`
+}
+
+// NewCloudSyncHostDiff 根据云主机生成同步预览中的主机差异
+func NewCloudSyncHostDiff(host *CloudHost) CloudSyncHostDiff {
+	return CloudSyncHostDiff{
+		HostID:        host.HostID,
+		InstanceID:    host.InstanceId,
+		CloudID:       host.CloudID,
+		PrivateIP:     host.PrivateIp,
+		PublicIP:      host.PublicIp,
+		InstanceState: host.InstanceState,
+		SyncDir:       host.SyncDir,
+		VendorName:    host.VendorName,
+	}
+}
+
+// ToCloudHost 将同步预览中的主机差异转换为云主机，用于应用同步预览
+func (d *CloudSyncHostDiff) ToCloudHost() *CloudHost {
+	return &CloudHost{
+		Instance: Instance{
+			InstanceId:    d.InstanceID,
+			PrivateIp:     d.PrivateIP,
+			PublicIp:      d.PublicIP,
+			InstanceState: d.InstanceState,
+		},
+		CloudID:    d.CloudID,
+		SyncDir:    d.SyncDir,
+		HostID:     d.HostID,
+		VendorName: d.VendorName,
+	}
+}
+
+// CloudSyncFieldChange 主机字段在同步前后的值
+type CloudSyncFieldChange struct {
+	Field  string      `json:"field" bson:"field"`
+	Before interface{} `json:"before" bson:"before"`
+	After  interface{} `json:"after" bson:"after"`
+}
+
+// SearchSyncPreviewOption 查询云同步预览的条件
+type SearchSyncPreviewOption struct {
+	TaskID    int64                  `json:"bk_task_id"`
+	PreviewID int64                  `json:"bk_preview_id"`
+	Status    CloudSyncPreviewStatus `json:"status"`
+	Page      BasePage               `json:"page"`
+}
+
+// Validate 校验查询云同步预览的条件
+func (o *SearchSyncPreviewOption) Validate() errors.RawErrorInfo {
+	if o.TaskID <= 0 {
+		return errors.RawErrorInfo{
+			ErrCode: common.CCErrCommParamsNeedSet,
+			Args:    []interface{}{common.BKCloudSyncTaskID},
+		}
+	}
+	return o.Page.ValidateWithEnableCount(false)
+}
+
+// MultipleSyncPreview 云同步预览的查询结果
+type MultipleSyncPreview struct {
+	Count int64              `json:"count"`
+	Info  []CloudSyncPreview `json:"info"`
+}
+
+// ApplySyncPreviewOption 审批并应用云同步预览的参数
+type ApplySyncPreviewOption struct {
+	PreviewID int64 `json:"bk_preview_id"`
+}
+
+// Validate 校验审批云同步预览的参数
+func (o *ApplySyncPreviewOption) Validate() errors.RawErrorInfo {
+	if o.PreviewID <= 0 {
+		return errors.RawErrorInfo{
+			ErrCode: common.CCErrCommParamsNeedSet,
+			Args:    []interface{}{common.BKCloudSyncPreviewID},
+		}
+	}
+	return errors.RawErrorInfo{}
+}
+
+// CreateSyncPreviewResult 创建云同步预览的返回结果
+type CreateSyncPreviewResult struct {
+	BaseResp `json:",inline"`
+	Data     CloudSyncPreview `json:"data"`
+}
+
+// MultipleSyncPreviewResult 查询云同步预览的返回结果
+type MultipleSyncPreviewResult struct {
+	BaseResp `json:",inline"`
+	Data     MultipleSyncPreview `json:"data"`
+}
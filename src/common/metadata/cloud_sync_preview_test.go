@@ -0,0 +1,90 @@
+/*
+ * Tencent is pleased to support the open source community by making 蓝鲸 available.
+ * Copyright (C) 2017-2018 THL A29 Limited, a Tencent company. All rights reserved.
+ * Licensed under the MIT License (the "License"); you may not use this file except
+ * in compliance with the License. You may obtain a copy of the License at
+ * http://opensource.org/licenses/MIT
+ * Unless required by applicable law or agreed to in writing, software distributed under
+ * the License is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND,
+ * either express or implied. See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package metadata
+
+import (
+	"testing"
+	"time"
+)
+
+func TestCloudSyncPreviewIsStale(t *testing.T) {
+	now := time.Now()
+	createTime := now.Add(-time.Hour)
+	before, after := createTime.Add(-time.Minute), createTime.Add(time.Minute)
+
+	tests := []struct {
+		name         string
+		expireTime   time.Time
+		lastSyncTime *time.Time
+		want         bool
+	}{
+		{"not expired and never synced", now.Add(time.Hour), nil, false},
+		{"not expired and synced before preview", now.Add(time.Hour), &before, false},
+		{"not expired but synced after preview", now.Add(time.Hour), &after, true},
+		{"expired", now.Add(-time.Second), nil, true},
+		{"expired and synced before preview", now.Add(-time.Second), &before, true},
+		{"no expire time", time.Time{}, &before, false},
+		{"no expire time but synced after preview", time.Time{}, &after, true},
+		{"synced at preview create time", now.Add(time.Hour), &createTime, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			preview := &CloudSyncPreview{CreateTime: createTime, ExpireTime: tt.expireTime}
+			task := &CloudSyncTask{LastSyncTime: tt.lastSyncTime}
+			if got := preview.IsStale(task, now); got != tt.want {
+				t.Errorf("IsStale() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestCloudSyncPreviewCheckRemoveThreshold(t *testing.T) {
+	tests := []struct {
+		name           string
+		localHostCount int64
+		removeCount    int
+		vpcHostCount   int
+		threshold      int64
+		wantPercent    float64
+		wantExceed     bool
+	}{
+		{"no local hosts", 0, 0, 0, 10, 0, false},
+		{"nothing removed", 10, 0, 0, 10, 0, false},
+		{"below threshold", 10, 1, 0, 20, 10, false},
+		{"equal to threshold", 10, 2, 0, 20, 20, false},
+		{"exceed threshold", 10, 3, 0, 20, 30, true},
+		{"exceed threshold by destroyed vpc hosts", 10, 1, 2, 20, 30, true},
+		{"no threshold", 10, 10, 0, 0, 100, false},
+		{"rounded to one decimal", 3, 1, 0, 33, 33.3, true},
+		{"max threshold", 3, 3, 0, 100, 100, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			preview := &CloudSyncPreview{
+				LocalHostCount: tt.localHostCount,
+				Remove:         make([]CloudSyncHostDiff, tt.removeCount),
+			}
+			if tt.vpcHostCount > 0 {
+				preview.DestroyedVpcs = []CloudSyncDestroyedVpc{{Hosts: make([]CloudSyncHostDiff, tt.vpcHostCount)}}
+			}
+
+			preview.CheckRemoveThreshold(tt.threshold)
+			if preview.RemovePercent != tt.wantPercent {
+				t.Errorf("RemovePercent = %v, want %v", preview.RemovePercent, tt.wantPercent)
+			}
+			if preview.ExceedThreshold != tt.wantExceed {
+				t.Errorf("ExceedThreshold = %v, want %v", preview.ExceedThreshold, tt.wantExceed)
+			}
+		})
+	}
+}
@@ -16,8 +16,10 @@ import (
 	"errors"
 	"fmt"
 	"regexp"
+	"strings"
 	"time"
 
+	"configcenter/pkg/filter"
 	"configcenter/src/common"
 	"configcenter/src/common/blog"
 	"configcenter/src/common/util"
@@ -80,6 +82,81 @@ var (
 // Validatefunc is func callback for validating.
 type Validatefunc func(objectID string) ([]Attribute, error)
 
+// DynamicGroupFilterObjects returns the condition object types of the dynamic group with filter conditions,
+// mainlineObjs is the custom mainline objects between business and set.
+// host dynamic group supports host/module/set/custom mainline object conditions, set dynamic group supports
+// set/custom mainline object conditions, custom mainline object and common object dynamic group only supports
+// the conditions of its own object.
+func DynamicGroupFilterObjects(objectID string, mainlineObjs []string) (map[string]struct{}, error) {
+	types := make(map[string]struct{})
+	switch objectID {
+	case common.BKInnerObjIDHost:
+		types[common.BKInnerObjIDModule] = struct{}{}
+		types[common.BKInnerObjIDSet] = struct{}{}
+	case common.BKInnerObjIDSet:
+	case common.BKInnerObjIDApp, common.BKInnerObjIDModule, common.BKInnerObjIDPlat, common.BKInnerObjIDProc:
+		return nil, fmt.Errorf("not support dynamic group type, %s", objectID)
+	default:
+		types[objectID] = struct{}{}
+		return types, nil
+	}
+
+	types[objectID] = struct{}{}
+	for _, obj := range mainlineObjs {
+		types[obj] = struct{}{}
+	}
+	return types, nil
+}
+
+// DynamicGroupFilterCondition is target resource search condition of an object based on filter expression,
+// it supports nested AND/OR logic and all the filter operators.
+type DynamicGroupFilterCondition struct {
+	// ObjID is the object id of the condition.
+	ObjID string `json:"bk_obj_id" bson:"bk_obj_id"`
+
+	// Filter is the filter expression of the object's instances.
+	Filter *filter.Expression `json:"filter" bson:"filter"`
+}
+
+// Validate validates dynamic group filter condition format.
+func (c *DynamicGroupFilterCondition) Validate(validatefunc Validatefunc) error {
+	if c.Filter == nil {
+		return fmt.Errorf("filter of condition type %s is not set", c.ObjID)
+	}
+
+	attributes, err := validatefunc(c.ObjID)
+	if err != nil {
+		return fmt.Errorf("validate dynamic group failed, %+v", err)
+	}
+
+	if len(attributes) == 0 {
+		return fmt.Errorf("not support condition type %s, object has no attributes", c.ObjID)
+	}
+
+	attributeMap := make(map[string]struct{})
+	for _, attribute := range attributes {
+		attributeMap[attribute.PropertyID] = struct{}{}
+	}
+	attributeMap[common.GetInstIDField(c.ObjID)] = struct{}{}
+	attributeMap[common.CreateTimeField] = struct{}{}
+	attributeMap[common.LastTimeField] = struct{}{}
+	attributeMap[common.BKDefaultField] = struct{}{}
+	if c.ObjID == common.BKInnerObjIDHost {
+		attributeMap[common.BKCloudIDField] = struct{}{}
+	}
+
+	// embedded fields of object and array fields are checked by its root field.
+	for _, field := range c.Filter.RuleFields() {
+		if _, exists := attributeMap[strings.Split(field, ".")[0]]; !exists {
+			return fmt.Errorf("not support condition field, %s", field)
+		}
+	}
+
+	opt := filter.NewDefaultExprOpt(nil)
+	opt.IgnoreRuleFields = true
+	return c.Filter.Validate(opt)
+}
+
 // DynamicGroupCondition is target resource search condition on fields level.
 type DynamicGroupCondition struct {
 	// Field is target field name for index resource.
@@ -259,11 +336,27 @@ func (c *DynamicGroupInfoCondition) Validate(validatefunc Validatefunc) error {
 // DynamicGroupInfo is info field in DynamicGroup struct.
 type DynamicGroupInfo struct {
 	// Condition is dynamic group index conditions set.
-	Condition []DynamicGroupInfoCondition `json:"condition" bson:"condition"`
+	Condition []DynamicGroupInfoCondition `json:"condition,omitempty" bson:"condition,omitempty"`
+
+	// FilterCondition is dynamic group filter expression conditions set, it can not be used with Condition.
+	FilterCondition []DynamicGroupFilterCondition `json:"filter_condition,omitempty" bson:"filter_condition,omitempty"`
+}
+
+// IsEmpty returns if there is no conditions in dynamic group info.
+func (c *DynamicGroupInfo) IsEmpty() bool {
+	return len(c.Condition) == 0 && len(c.FilterCondition) == 0
 }
 
 // Validate validates dynamic group info format, it's OK if conditions empty in this level.
-func (c *DynamicGroupInfo) Validate(objectID string, validatefunc Validatefunc) error {
+// mainlineObjs is the custom mainline objects, which is used to validate filter conditions.
+func (c *DynamicGroupInfo) Validate(objectID string, validatefunc Validatefunc, mainlineObjs []string) error {
+	if len(c.FilterCondition) != 0 {
+		if len(c.Condition) != 0 {
+			return errors.New("info.condition and info.filter_condition can not be set at the same time")
+		}
+		return c.validateFilterCondition(objectID, validatefunc, mainlineObjs)
+	}
+
 	types, isSupport := DynamicGroupConditionTypes[objectID]
 	if !isSupport {
 		return fmt.Errorf("not support dynamic group type, %s", objectID)
@@ -288,6 +381,32 @@ func (c *DynamicGroupInfo) Validate(objectID string, validatefunc Validatefunc)
 	return nil
 }
 
+func (c *DynamicGroupInfo) validateFilterCondition(objectID string, validatefunc Validatefunc,
+	mainlineObjs []string) error {
+
+	types, err := DynamicGroupFilterObjects(objectID, mainlineObjs)
+	if err != nil {
+		return err
+	}
+
+	condObjs := make(map[string]struct{})
+	for _, cond := range c.FilterCondition {
+		if _, isSupport := types[cond.ObjID]; !isSupport {
+			return fmt.Errorf("not support condition type[%s] for %s dynamic group", cond.ObjID, objectID)
+		}
+
+		if _, exists := condObjs[cond.ObjID]; exists {
+			return fmt.Errorf("condition type[%s] is duplicated", cond.ObjID)
+		}
+		condObjs[cond.ObjID] = struct{}{}
+
+		if err := cond.Validate(validatefunc); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
 // DynamicGroup is dynamic grouping of conditions for host/set data searching.
 type DynamicGroup struct {
 	// AppID is application id which dynamic group belongs to.
@@ -299,12 +418,16 @@ type DynamicGroup struct {
 	// Name is dynamic group name.
 	Name string `json:"name" bson:"name"`
 
-	// ObjID is cmdb object id, could be host/set now.
+	// ObjID is cmdb object id, could be host/set now, custom mainline object and common object are also
+	// supported by filter conditions.
 	ObjID string `json:"bk_obj_id" bson:"bk_obj_id"`
 
 	// Info is dynamic group core conditions information.
 	Info DynamicGroupInfo `json:"info" bson:"info"`
 
+	// TrackMember is whether to track the dynamic group's host members and generate member change events.
+	TrackMember bool `json:"track_member" bson:"track_member"`
+
 	// CreateUser create user name.
 	CreateUser string `json:"create_user" bson:"create_user"`
 
@@ -319,7 +442,7 @@ type DynamicGroup struct {
 }
 
 // Validate validates dynamic group format.
-func (g *DynamicGroup) Validate(validatefunc Validatefunc) error {
+func (g *DynamicGroup) Validate(validatefunc Validatefunc, mainlineObjs []string) error {
 	if g.AppID <= 0 {
 		return errors.New("empty bk_biz_id")
 	}
@@ -334,11 +457,47 @@ func (g *DynamicGroup) Validate(validatefunc Validatefunc) error {
 	}
 
 	// check conditions format.
-	if len(g.Info.Condition) == 0 {
+	if g.Info.IsEmpty() {
 		// it's not OK if conditions empty in this level.
 		return errors.New("empty info.condition")
 	}
-	return g.Info.Validate(g.ObjID, validatefunc)
+
+	if err := g.Info.Validate(g.ObjID, validatefunc, mainlineObjs); err != nil {
+		return err
+	}
+
+	if g.TrackMember {
+		return ValidateDynamicGroupTrackMember(g.ObjID, &g.Info)
+	}
+	return nil
+}
+
+// ValidateDynamicGroupTrackMember validates if the dynamic group's members can be tracked, only host dynamic group
+// with filter expression conditions is supported.
+func ValidateDynamicGroupTrackMember(objectID string, info *DynamicGroupInfo) error {
+	if objectID != common.BKInnerObjIDHost {
+		return fmt.Errorf("track_member is not supported for %s dynamic group", objectID)
+	}
+
+	if info == nil || len(info.FilterCondition) == 0 {
+		return errors.New("track_member is only supported for dynamic group with info.filter_condition")
+	}
+	return nil
+}
+
+// DynamicGroupMember is the materialized host member of the dynamic group whose members are tracked.
+type DynamicGroupMember struct {
+	// AppID is application id which dynamic group belongs to.
+	AppID int64 `json:"bk_biz_id" bson:"bk_biz_id"`
+
+	// GroupID is dynamic group instance unique id.
+	GroupID string `json:"id" bson:"id"`
+
+	// HostID is the id of the host that is a member of the dynamic group.
+	HostID int64 `json:"bk_host_id" bson:"bk_host_id"`
+
+	// CreateTime is the time when the host joined the dynamic group.
+	CreateTime time.Time `json:"create_time" bson:"create_time"`
 }
 
 // DynamicGroupBatch is batch result struct of dynamic group.
@@ -0,0 +1,192 @@
+/*
+ * Tencent is pleased to support the open source community by making 蓝鲸 available.
+ * Copyright (C) 2017-2018 THL A29 Limited, a Tencent company. All rights reserved.
+ * Licensed under the MIT License (the "License"); you may not use this file except
+ * in compliance with the License. You may obtain a copy of the License at
+ * http://opensource.org/licenses/MIT
+ * Unless required by applicable law or agreed to in writing, software distributed under
+ * the License is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND,
+ * either express or implied. See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package metadata
+
+import (
+	"time"
+
+	"configcenter/src/common"
+	"configcenter/src/common/errors"
+)
+
+// HostApplyReconcileMode defines how the host apply reconciler deals with the hosts that drift from the host apply
+// rules of their modules after the hosts are updated.
+type HostApplyReconcileMode string
+
+const (
+	// HostApplyReconcileDisabled the host apply reconciler does nothing, this is the default mode
+	HostApplyReconcileDisabled HostApplyReconcileMode = "disabled"
+	// HostApplyReconcileReport the host apply reconciler only records the drifted fields of the hosts
+	HostApplyReconcileReport HostApplyReconcileMode = "report"
+	// HostApplyReconcileReapply the host apply reconciler re-applies the rules to the drifted hosts, the fields that
+	// failed to be re-applied are recorded as drifts
+	HostApplyReconcileReapply HostApplyReconcileMode = "reapply"
+)
+
+// Validate validate if the host apply reconcile mode is valid
+func (m HostApplyReconcileMode) Validate() error {
+	switch m {
+	case HostApplyReconcileDisabled, HostApplyReconcileReport, HostApplyReconcileReapply:
+		return nil
+	default:
+		return errors.New(common.CCErrCommParamsIsInvalid, "mode")
+	}
+}
+
+const (
+	// HostApplyReconcileModeField the host apply reconcile mode field
+	HostApplyReconcileModeField = "mode"
+	// HostApplyDriftModuleIDsField the host apply drift module ids field
+	HostApplyDriftModuleIDsField = "bk_module_ids"
+)
+
+// HostApplyReconcileConfig is the host apply reconcile config of a business, the business whose config is not set
+// uses the HostApplyReconcileDisabled mode.
+type HostApplyReconcileConfig struct {
+	BizID           int64                  `json:"bk_biz_id" bson:"bk_biz_id"`
+	Mode            HostApplyReconcileMode `json:"mode" bson:"mode"`
+	Modifier        string                 `json:"modifier" bson:"modifier"`
+	LastTime        time.Time              `json:"last_time" bson:"last_time"`
+	SupplierAccount string                 `json:"bk_supplier_account" bson:"bk_supplier_account"`
+}
+
+// UpdateHostApplyReconcileConfigOption update host apply reconcile config of a business option
+type UpdateHostApplyReconcileConfigOption struct {
+	Mode HostApplyReconcileMode `json:"mode"`
+}
+
+// Validate validate UpdateHostApplyReconcileConfigOption
+func (o *UpdateHostApplyReconcileConfigOption) Validate() errors.RawErrorInfo {
+	if len(o.Mode) == 0 {
+		return errors.RawErrorInfo{
+			ErrCode: common.CCErrCommParamsNeedSet,
+			Args:    []interface{}{HostApplyReconcileModeField},
+		}
+	}
+
+	if err := o.Mode.Validate(); err != nil {
+		return errors.RawErrorInfo{
+			ErrCode: common.CCErrCommParamsIsInvalid,
+			Args:    []interface{}{HostApplyReconcileModeField},
+		}
+	}
+	return errors.RawErrorInfo{}
+}
+
+// ListHostApplyReconcileConfigOption list host apply reconcile configs of businesses option
+type ListHostApplyReconcileConfigOption struct {
+	BizIDs []int64 `json:"bk_biz_ids"`
+}
+
+// Validate validate ListHostApplyReconcileConfigOption
+func (o *ListHostApplyReconcileConfigOption) Validate() errors.RawErrorInfo {
+	if len(o.BizIDs) == 0 {
+		return errors.RawErrorInfo{
+			ErrCode: common.CCErrCommParamsNeedSet,
+			Args:    []interface{}{"bk_biz_ids"},
+		}
+	}
+
+	if len(o.BizIDs) > common.BKMaxLimitSize {
+		return errors.RawErrorInfo{
+			ErrCode: common.CCErrCommXXExceedLimit,
+			Args:    []interface{}{"bk_biz_ids", common.BKMaxLimitSize},
+		}
+	}
+	return errors.RawErrorInfo{}
+}
+
+// HostApplyDrift is a host field that drifts from the host apply rule of the host's module, which means that
+// the actual value of the host field is not the expected value of the rule.
+type HostApplyDrift struct {
+	BizID           int64       `json:"bk_biz_id" bson:"bk_biz_id"`
+	HostID          int64       `json:"bk_host_id" bson:"bk_host_id"`
+	ModuleIDs       []int64     `json:"bk_module_ids" bson:"bk_module_ids"`
+	AttributeID     int64       `json:"bk_attribute_id" bson:"bk_attribute_id"`
+	PropertyID      string      `json:"bk_property_id" bson:"bk_property_id"`
+	ExpectValue     interface{} `json:"expect_value" bson:"expect_value"`
+	ActualValue     interface{} `json:"actual_value" bson:"actual_value"`
+	DetectTime      time.Time   `json:"detect_time" bson:"detect_time"`
+	SupplierAccount string      `json:"bk_supplier_account" bson:"bk_supplier_account"`
+}
+
+// ReconcileHostApplyOption reconcile the hosts in a business with their host apply rules option
+type ReconcileHostApplyOption struct {
+	HostIDs []int64 `json:"bk_host_ids"`
+}
+
+// Validate validate ReconcileHostApplyOption
+func (o *ReconcileHostApplyOption) Validate() errors.RawErrorInfo {
+	if len(o.HostIDs) == 0 {
+		return errors.RawErrorInfo{
+			ErrCode: common.CCErrCommParamsNeedSet,
+			Args:    []interface{}{"bk_host_ids"},
+		}
+	}
+
+	if len(o.HostIDs) > common.BKMaxLimitSize {
+		return errors.RawErrorInfo{
+			ErrCode: common.CCErrCommXXExceedLimit,
+			Args:    []interface{}{"bk_host_ids", common.BKMaxLimitSize},
+		}
+	}
+	return errors.RawErrorInfo{}
+}
+
+// ReconcileHostApplyResult reconcile the hosts with their host apply rules result
+type ReconcileHostApplyResult struct {
+	// Drifts are the drifted host fields that are recorded after the reconciliation
+	Drifts []HostApplyDrift `json:"drifts"`
+}
+
+// DeleteHostApplyDriftOption delete the host apply drifts of the hosts option
+type DeleteHostApplyDriftOption struct {
+	HostIDs []int64 `json:"bk_host_ids"`
+}
+
+// Validate validate DeleteHostApplyDriftOption
+func (o *DeleteHostApplyDriftOption) Validate() errors.RawErrorInfo {
+	if len(o.HostIDs) == 0 {
+		return errors.RawErrorInfo{
+			ErrCode: common.CCErrCommParamsNeedSet,
+			Args:    []interface{}{"bk_host_ids"},
+		}
+	}
+
+	if len(o.HostIDs) > common.BKMaxLimitSize {
+		return errors.RawErrorInfo{
+			ErrCode: common.CCErrCommXXExceedLimit,
+			Args:    []interface{}{"bk_host_ids", common.BKMaxLimitSize},
+		}
+	}
+	return errors.RawErrorInfo{}
+}
+
+// ListHostApplyDriftOption list the host apply drifts in a business option
+type ListHostApplyDriftOption struct {
+	HostIDs     []int64  `json:"bk_host_ids"`
+	ModuleIDs   []int64  `json:"bk_module_ids"`
+	PropertyIDs []string `json:"bk_property_ids"`
+	Page        BasePage `json:"page"`
+}
+
+// Validate validate ListHostApplyDriftOption
+func (o *ListHostApplyDriftOption) Validate() errors.RawErrorInfo {
+	return o.Page.ValidateWithEnableCount(false)
+}
+
+// HostApplyDriftResult list host apply drifts result
+type HostApplyDriftResult struct {
+	Count int64            `json:"count"`
+	Info  []HostApplyDrift `json:"info"`
+}
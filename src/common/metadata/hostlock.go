@@ -15,12 +15,91 @@ package metadata
 import (
 	"time"
 
+	"configcenter/src/common"
+	"configcenter/src/common/errors"
 	"configcenter/src/common/mapstr"
 )
 
-// HostLockRequest TODO
+// HostLockScope is the operation scope that a host lock refuses
+type HostLockScope string
+
+const (
+	// HostLockScopeTransfer refuses transferring the host between modules, businesses and resource directories
+	HostLockScopeTransfer HostLockScope = "transfer"
+	// HostLockScopeUpdate refuses updating the host's attributes
+	HostLockScopeUpdate HostLockScope = "update"
+	// HostLockScopeDelete refuses deleting the host
+	HostLockScopeDelete HostLockScope = "delete"
+)
+
+// Validate validate if the host lock scope is valid
+func (s HostLockScope) Validate() error {
+	switch s {
+	case HostLockScopeTransfer, HostLockScopeUpdate, HostLockScopeDelete:
+		return nil
+	default:
+		return errors.New(common.CCErrCommParamsIsInvalid, HostLockScopeField)
+	}
+}
+
+const (
+	// HostLockScopeField the host lock scope field
+	HostLockScopeField = "scope"
+	// HostLockExpireTimeField the host lock expire time field
+	HostLockExpireTimeField = "expire_time"
+	// HostLockUserField the host lock owner field
+	HostLockUserField = "bk_user"
+	// HostLockReasonMaxLength the max length of the host lock reason
+	HostLockReasonMaxLength = 256
+)
+
+// HostLockRequest lock or unlock hosts request, only the id list is used when unlocking hosts
 type HostLockRequest struct {
 	IDS []int64 `json:"id_list"`
+	// Owner is the owner of the lock, default to the operator, only the system user can lock for another owner
+	Owner  string `json:"owner"`
+	Reason string `json:"reason"`
+	// TTL is the seconds after which the lock expires, 0 means the lock never expires
+	TTL int64 `json:"ttl"`
+	// Scope is the operations that the lock refuses, empty means all the operations
+	Scope []HostLockScope `json:"scope"`
+	// Force is used to release the locks of the other owners when unlocking, it needs the global settings
+	// permission, and is audited as force unlock
+	Force bool `json:"force"`
+}
+
+// ValidateLockOption validate the options of locking hosts
+func (h *HostLockRequest) ValidateLockOption() errors.RawErrorInfo {
+	if len(h.IDS) == 0 {
+		return errors.RawErrorInfo{
+			ErrCode: common.CCErrCommParamsNeedSet,
+			Args:    []interface{}{"id_list"},
+		}
+	}
+
+	if h.TTL < 0 {
+		return errors.RawErrorInfo{
+			ErrCode: common.CCErrCommParamsIsInvalid,
+			Args:    []interface{}{"ttl"},
+		}
+	}
+
+	if len([]rune(h.Reason)) > HostLockReasonMaxLength {
+		return errors.RawErrorInfo{
+			ErrCode: common.CCErrCommXXExceedLimit,
+			Args:    []interface{}{"reason", HostLockReasonMaxLength},
+		}
+	}
+
+	for _, scope := range h.Scope {
+		if err := scope.Validate(); err != nil {
+			return errors.RawErrorInfo{
+				ErrCode: common.CCErrCommParamsIsInvalid,
+				Args:    []interface{}{HostLockScopeField},
+			}
+		}
+	}
+	return errors.RawErrorInfo{}
 }
 
 // QueryHostLockRequest TODO
@@ -34,12 +113,42 @@ type HostLockResultResponse struct {
 	Data     map[int64]bool `json:"data"`
 }
 
-// HostLockData TODO
+// HostLockData is the lock of a host
 type HostLockData struct {
-	User       string    `json:"bk_user" bson:"bk_user"`
-	ID         int64     `json:"bk_host_id" bson:"bk_host_id"`
-	CreateTime time.Time `json:"create_time" bson:"create_time"`
-	OwnerID    string    `json:"-" bson:"bk_supplier_account"`
+	// User is the owner of the lock
+	User   string          `json:"bk_user" bson:"bk_user"`
+	ID     int64           `json:"bk_host_id" bson:"bk_host_id"`
+	Reason string          `json:"reason" bson:"reason"`
+	Scope  []HostLockScope `json:"scope" bson:"scope"`
+	// ExpireTime is the time when the lock expires, nil means the lock never expires
+	ExpireTime *time.Time `json:"expire_time,omitempty" bson:"expire_time"`
+	CreateTime time.Time  `json:"create_time" bson:"create_time"`
+	OwnerID    string     `json:"-" bson:"bk_supplier_account"`
+}
+
+// IsExpired check if the host lock is expired at the time
+func (h *HostLockData) IsExpired(now time.Time) bool {
+	return h.ExpireTime != nil && !h.ExpireTime.After(now)
+}
+
+// InScope check if the host lock refuses the operation of the scope, the lock without scope refuses all operations
+func (h *HostLockData) InScope(scope HostLockScope) bool {
+	if len(h.Scope) == 0 {
+		return true
+	}
+
+	for _, s := range h.Scope {
+		if s == scope {
+			return true
+		}
+	}
+	return false
+}
+
+// HostLockDetailResult query host lock details result
+type HostLockDetailResult struct {
+	Info  []HostLockData `json:"info"`
+	Count int64          `json:"count"`
 }
 
 // HostLockQueryResponse TODO
@@ -0,0 +1,188 @@
+/*
+ * Tencent is pleased to support the open source community by making 蓝鲸 available.
+ * Copyright (C) 2017-2018 THL A29 Limited, a Tencent company. All rights reserved.
+ * Licensed under the MIT License (the "License"); you may not use this file except
+ * in compliance with the License. You may obtain a copy of the License at
+ * http://opensource.org/licenses/MIT
+ * Unless required by applicable law or agreed to in writing, software distributed under
+ * the License is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND,
+ * either express or implied. See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package metadata
+
+import (
+	"configcenter/src/common"
+	"configcenter/src/common/errors"
+	"configcenter/src/common/querybuilder"
+)
+
+const (
+	// InstGraphMaxDepth is the max number of hops that an instance graph query can traverse
+	InstGraphMaxDepth = 5
+	// InstGraphStartLimit is the max number of start instances of an instance graph query
+	InstGraphStartLimit = 100
+	// InstGraphMaxNodes is the max number of nodes that an instance graph query can reach, the traversal stops
+	// and the result is marked as truncated when it is reached
+	InstGraphMaxNodes = 1000
+	// InstGraphMaxEdges is the max number of edges that an instance graph query can reach, it is also the max number
+	// of associations or mainline relations fetched in one batch
+	InstGraphMaxEdges = 5000
+)
+
+// InstGraphDirection is the direction of the instance associations to traverse in a hop
+type InstGraphDirection string
+
+const (
+	// InstGraphOutgoing traverses from the source instance to the destination instance of the associations,
+	// for mainline associations, it's from the child instance to its parent.
+	InstGraphOutgoing InstGraphDirection = "out"
+	// InstGraphIncoming traverses from the destination instance to the source instance of the associations
+	InstGraphIncoming InstGraphDirection = "in"
+	// InstGraphBoth traverses the associations in both directions
+	InstGraphBoth InstGraphDirection = "both"
+)
+
+// InstGraphReturnMode is what the instance graph query returns
+type InstGraphReturnMode string
+
+const (
+	// InstGraphReturnPaths returns a shortest path from the start instances to each reached target instance
+	InstGraphReturnPaths InstGraphReturnMode = "paths"
+	// InstGraphReturnNodes returns the distinct reached target instances
+	InstGraphReturnNodes InstGraphReturnMode = "nodes"
+	// InstGraphReturnSubgraph returns the nodes and edges of the traversed graph, if target objects are specified,
+	// it's the union of the paths to the reached target instances.
+	InstGraphReturnSubgraph InstGraphReturnMode = "subgraph"
+)
+
+// InstGraphQueryOption is the option to traverse the graph made up of the instance associations and the mainline
+// topology from the start instances
+type InstGraphQueryOption struct {
+	Start InstGraphStart `json:"start"`
+	// Hops are the associations allowed in each hop, the nth hop uses the nth item and the hops after the last item
+	// use the last item, all associations in both directions are allowed if it is empty.
+	Hops []InstGraphHop `json:"hops"`
+	// MaxDepth is the max number of hops to traverse
+	MaxDepth int `json:"max_depth"`
+	// TargetObjects are the objects whose instances are returned, all reached instances are returned if it's empty
+	TargetObjects []string            `json:"target_objects"`
+	ReturnMode    InstGraphReturnMode `json:"return_mode"`
+}
+
+// InstGraphStart is the start instances of an instance graph query
+type InstGraphStart struct {
+	ObjectID   string                    `json:"bk_obj_id"`
+	Conditions *querybuilder.QueryFilter `json:"conditions"`
+}
+
+// InstGraphHop is the associations allowed in a hop
+type InstGraphHop struct {
+	// AsstKindIDs are the association kinds allowed, bk_mainline means the mainline topology, all kinds are allowed
+	// if it's empty.
+	AsstKindIDs []string           `json:"bk_asst_ids"`
+	Direction   InstGraphDirection `json:"direction"`
+}
+
+// Validate InstGraphQueryOption
+func (o *InstGraphQueryOption) Validate() errors.RawErrorInfo {
+	if len(o.Start.ObjectID) == 0 {
+		return errors.RawErrorInfo{ErrCode: common.CCErrCommParamsNeedSet, Args: []interface{}{"start.bk_obj_id"}}
+	}
+
+	if o.Start.Conditions != nil {
+		option := &querybuilder.RuleOption{
+			NeedSameSliceElementType: true,
+			MaxSliceElementsCount:    querybuilder.DefaultMaxSliceElementsCount,
+			MaxConditionOrRulesCount: querybuilder.DefaultMaxConditionOrRulesCount,
+		}
+		if invalidKey, err := o.Start.Conditions.Validate(option); err != nil {
+			return errors.RawErrorInfo{ErrCode: common.CCErrCommParamsInvalid,
+				Args: []interface{}{"start.conditions." + invalidKey}}
+		}
+
+		if o.Start.Conditions.GetDeep() > querybuilder.MaxDeep {
+			return errors.RawErrorInfo{ErrCode: common.CCErrCommXXExceedLimit,
+				Args: []interface{}{"start.conditions", querybuilder.MaxDeep}}
+		}
+	}
+
+	if o.MaxDepth <= 0 {
+		return errors.RawErrorInfo{ErrCode: common.CCErrCommParamsInvalid, Args: []interface{}{"max_depth"}}
+	}
+
+	if o.MaxDepth > InstGraphMaxDepth {
+		return errors.RawErrorInfo{ErrCode: common.CCErrCommXXExceedLimit,
+			Args: []interface{}{"max_depth", InstGraphMaxDepth}}
+	}
+
+	if len(o.Hops) > o.MaxDepth {
+		return errors.RawErrorInfo{ErrCode: common.CCErrCommParamsInvalid, Args: []interface{}{"hops"}}
+	}
+
+	for _, hop := range o.Hops {
+		switch hop.Direction {
+		case InstGraphOutgoing, InstGraphIncoming, InstGraphBoth:
+		default:
+			return errors.RawErrorInfo{ErrCode: common.CCErrCommParamsInvalid, Args: []interface{}{"hops.direction"}}
+		}
+	}
+
+	switch o.ReturnMode {
+	case InstGraphReturnPaths, InstGraphReturnNodes, InstGraphReturnSubgraph:
+	default:
+		return errors.RawErrorInfo{ErrCode: common.CCErrCommParamsInvalid, Args: []interface{}{"return_mode"}}
+	}
+
+	return errors.RawErrorInfo{}
+}
+
+// GetHop returns the allowed associations of the hop at the depth which starts from 0
+func (o *InstGraphQueryOption) GetHop(depth int) InstGraphHop {
+	if len(o.Hops) == 0 {
+		return InstGraphHop{Direction: InstGraphBoth}
+	}
+
+	if depth >= len(o.Hops) {
+		return o.Hops[len(o.Hops)-1]
+	}
+	return o.Hops[depth]
+}
+
+// InstGraphNode is an instance in the instance graph
+type InstGraphNode struct {
+	ObjectID string `json:"bk_obj_id"`
+	InstID   int64  `json:"bk_inst_id"`
+	InstName string `json:"bk_inst_name"`
+	// Depth is the number of hops from the start instances
+	Depth int `json:"depth"`
+}
+
+// InstGraphEdge is an instance association or a mainline relation in the instance graph, the source instance of a
+// mainline relation is the child instance.
+type InstGraphEdge struct {
+	// ID is the instance association id, it is 0 for mainline relations
+	ID           int64  `json:"id"`
+	ObjectAsstID string `json:"bk_obj_asst_id"`
+	AsstKindID   string `json:"bk_asst_id"`
+	ObjectID     string `json:"bk_obj_id"`
+	InstID       int64  `json:"bk_inst_id"`
+	AsstObjectID string `json:"bk_asst_obj_id"`
+	AsstInstID   int64  `json:"bk_asst_inst_id"`
+}
+
+// InstGraphPath is a path from a start instance to a reached instance, the edges connect the nodes in order
+type InstGraphPath struct {
+	Nodes []InstGraphNode `json:"nodes"`
+	Edges []InstGraphEdge `json:"edges"`
+}
+
+// InstGraphQueryResult is the result of an instance graph query, the fields are set by the return mode
+type InstGraphQueryResult struct {
+	Nodes []InstGraphNode `json:"nodes,omitempty"`
+	Edges []InstGraphEdge `json:"edges,omitempty"`
+	Paths []InstGraphPath `json:"paths,omitempty"`
+	// Truncated means the traversal is stopped because the number of nodes or edges exceeds the limit
+	Truncated bool `json:"truncated"`
+}
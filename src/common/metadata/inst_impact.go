@@ -0,0 +1,134 @@
+/*
+ * Tencent is pleased to support the open source community by making 蓝鲸 available.
+ * Copyright (C) 2017-2018 THL A29 Limited, a Tencent company. All rights reserved.
+ * Licensed under the MIT License (the "License"); you may not use this file except
+ * in compliance with the License. You may obtain a copy of the License at
+ * http://opensource.org/licenses/MIT
+ * Unless required by applicable law or agreed to in writing, software distributed under
+ * the License is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND,
+ * either express or implied. See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package metadata
+
+import (
+	"configcenter/src/common"
+	"configcenter/src/common/errors"
+)
+
+// InstImpactMaxInstances is the max number of instances that can be analyzed in one impact analysis
+const InstImpactMaxInstances = InstGraphStartLimit
+
+// InstImpactDefaultAsstKinds are the dependency association kinds used in the impact analysis if none is specified
+var InstImpactDefaultAsstKinds = []string{common.AssociationTypeBelong, common.AssociationTypeGroup,
+	common.AssociationTypeRun, common.AssociationTypeConnect}
+
+// InstImpactOption is the option to analyze what depends on the instances
+type InstImpactOption struct {
+	InstIDs []int64 `json:"bk_inst_ids"`
+	// AsstKindIDs are the dependency association kinds that are followed, InstImpactDefaultAsstKinds is used if it
+	// is empty, the mainline topology is always analyzed for the hosts so bk_mainline is not allowed.
+	AsstKindIDs []string `json:"bk_asst_ids"`
+	// MaxDepth is the max number of association hops to follow, defaults to 1
+	MaxDepth int `json:"max_depth"`
+}
+
+// Validate InstImpactOption
+func (o *InstImpactOption) Validate() errors.RawErrorInfo {
+	if len(o.InstIDs) == 0 {
+		return errors.RawErrorInfo{ErrCode: common.CCErrCommParamsNeedSet, Args: []interface{}{"bk_inst_ids"}}
+	}
+
+	if len(o.InstIDs) > InstImpactMaxInstances {
+		return errors.RawErrorInfo{ErrCode: common.CCErrCommXXExceedLimit,
+			Args: []interface{}{"bk_inst_ids", InstImpactMaxInstances}}
+	}
+
+	for _, kind := range o.AsstKindIDs {
+		if kind == common.AssociationKindMainline {
+			return errors.RawErrorInfo{ErrCode: common.CCErrCommParamsInvalid, Args: []interface{}{"bk_asst_ids"}}
+		}
+	}
+
+	if o.MaxDepth < 0 {
+		return errors.RawErrorInfo{ErrCode: common.CCErrCommParamsInvalid, Args: []interface{}{"max_depth"}}
+	}
+
+	if o.MaxDepth > InstGraphMaxDepth {
+		return errors.RawErrorInfo{ErrCode: common.CCErrCommXXExceedLimit,
+			Args: []interface{}{"max_depth", InstGraphMaxDepth}}
+	}
+
+	return errors.RawErrorInfo{}
+}
+
+// InstImpactResult is the blast radius report of the instances, everything in it is deduplicated
+type InstImpactResult struct {
+	// Instances are the analyzed instances that exist
+	Instances []InstGraphNode `json:"instances"`
+	// AssociatedInstances are the instances linked to the analyzed ones by the dependency associations
+	AssociatedInstances []InstImpactObjectInsts `json:"associated_instances"`
+	// the following topology and process resources are related to the analyzed or associated hosts
+	Businesses       []InstImpactBusiness        `json:"businesses"`
+	Sets             []InstImpactSet             `json:"sets"`
+	Modules          []InstImpactModule          `json:"modules"`
+	ServiceInstances []InstImpactServiceInstance `json:"service_instances"`
+	Processes        []InstImpactProcess         `json:"processes"`
+	// Contacts are all the owners of the related businesses and modules
+	Contacts []string `json:"contacts"`
+	// Truncated means the association traversal is stopped because the number of instances exceeds the limit
+	Truncated bool `json:"truncated"`
+}
+
+// InstImpactObjectInsts is the associated instances of an object
+type InstImpactObjectInsts struct {
+	ObjectID  string          `json:"bk_obj_id"`
+	Instances []InstGraphNode `json:"instances"`
+}
+
+// InstImpactBusiness is a business in the impact analysis with its owners
+type InstImpactBusiness struct {
+	BizID       int64  `json:"bk_biz_id"`
+	BizName     string `json:"bk_biz_name"`
+	Maintainers string `json:"bk_biz_maintainer"`
+	Productor   string `json:"bk_biz_productor"`
+	Developer   string `json:"bk_biz_developer"`
+	Tester      string `json:"bk_biz_tester"`
+	Operator    string `json:"operator"`
+}
+
+// InstImpactSet is a set in the impact analysis
+type InstImpactSet struct {
+	BizID   int64  `json:"bk_biz_id"`
+	SetID   int64  `json:"bk_set_id"`
+	SetName string `json:"bk_set_name"`
+}
+
+// InstImpactModule is a module in the impact analysis with its owners
+type InstImpactModule struct {
+	BizID       int64  `json:"bk_biz_id"`
+	SetID       int64  `json:"bk_set_id"`
+	ModuleID    int64  `json:"bk_module_id"`
+	ModuleName  string `json:"bk_module_name"`
+	Operator    string `json:"operator"`
+	BakOperator string `json:"bk_bak_operator"`
+}
+
+// InstImpactServiceInstance is a service instance in the impact analysis
+type InstImpactServiceInstance struct {
+	BizID    int64  `json:"bk_biz_id"`
+	ID       int64  `json:"id"`
+	Name     string `json:"name"`
+	ModuleID int64  `json:"bk_module_id"`
+	HostID   int64  `json:"bk_host_id"`
+}
+
+// InstImpactProcess is a process in the impact analysis
+type InstImpactProcess struct {
+	BizID             int64  `json:"bk_biz_id"`
+	ProcessID         int64  `json:"bk_process_id"`
+	ProcessName       string `json:"bk_process_name"`
+	ServiceInstanceID int64  `json:"service_instance_id"`
+	HostID            int64  `json:"bk_host_id"`
+}
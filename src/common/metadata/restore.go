@@ -0,0 +1,74 @@
+/*
+ * Tencent is pleased to support the open source community by making 蓝鲸 available.
+ * Copyright (C) 2017-2018 THL A29 Limited, a Tencent company. All rights reserved.
+ * Licensed under the MIT License (the "License"); you may not use this file except
+ * in compliance with the License. You may obtain a copy of the License at
+ * http://opensource.org/licenses/MIT
+ * Unless required by applicable law or agreed to in writing, software distributed under
+ * the License is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND,
+ * either express or implied. See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package metadata
+
+import (
+	"configcenter/src/common"
+	"configcenter/src/common/errors"
+	"configcenter/src/common/mapstr"
+)
+
+// RestoreInstLimit is the max number of deleted instances that can be restored in one request
+const RestoreInstLimit = 100
+
+// RestoreInstOption is the option to restore deleted instances from the delete archive
+type RestoreInstOption struct {
+	// BizID is the business that the restored instances must belong to, it is required by topo server for business
+	// resources like set, module and custom mainline instances, restore is rejected if the archived one is not in it.
+	BizID int64 `json:"bk_biz_id"`
+	// InstIDs are the ids of the deleted instances, they are restored in order, so that a parent like set can be
+	// restored before its children in the same request.
+	InstIDs []int64 `json:"inst_ids"`
+}
+
+// Validate RestoreInstOption
+func (o *RestoreInstOption) Validate() errors.RawErrorInfo {
+	if len(o.InstIDs) == 0 {
+		return errors.RawErrorInfo{ErrCode: common.CCErrCommParamsNeedSet, Args: []interface{}{"inst_ids"}}
+	}
+
+	if len(o.InstIDs) > RestoreInstLimit {
+		return errors.RawErrorInfo{ErrCode: common.CCErrCommXXExceedLimit,
+			Args: []interface{}{"inst_ids", RestoreInstLimit}}
+	}
+
+	idMap := make(map[int64]struct{})
+	for _, id := range o.InstIDs {
+		if id <= 0 {
+			return errors.RawErrorInfo{ErrCode: common.CCErrCommParamsInvalid, Args: []interface{}{"inst_ids"}}
+		}
+
+		if _, exists := idMap[id]; exists {
+			return errors.RawErrorInfo{ErrCode: common.CCErrCommDuplicateItem, Args: []interface{}{id}}
+		}
+		idMap[id] = struct{}{}
+	}
+
+	return errors.RawErrorInfo{}
+}
+
+// RestoreInstResult is the result of restoring deleted instances
+type RestoreInstResult struct {
+	// Instances are the restored instances' data
+	Instances []mapstr.MapStr `json:"instances"`
+	// Associations are the restored instance associations that are still valid
+	Associations []InstAsst `json:"associations"`
+	// HostRelations are the relations between the restored hosts and the resource pool idle module, only for host
+	HostRelations []ModuleHost `json:"host_relations"`
+}
+
+// RestoreInstResp is the response of restoring deleted instances
+type RestoreInstResp struct {
+	BaseResp `json:",inline"`
+	Data     RestoreInstResult `json:"data"`
+}
@@ -33,6 +33,9 @@ type CreateTaskRequest struct {
 	// a task. currently used in scenarios where taskType is SyncFieldTemplateTaskFlag
 	Extra interface{} `json:"extra,omitempty" bson:"extra"`
 
+	// Priority 任务优先级，同一任务队列中优先级高的任务先执行，默认为0
+	Priority int64 `json:"priority,omitempty"`
+
 	Data []interface{} `json:"data"`
 }
 
@@ -55,6 +58,10 @@ type APITaskDetail struct {
 	Header http.Header `json:"header,omitempty" bson:"header"`
 	// Status 任务执行状态
 	Status APITaskStatus `json:"status,omitempty" bson:"status"`
+	// Priority 任务优先级，同一任务队列中优先级高的任务先执行，相同优先级的任务按创建时间先后执行
+	Priority int64 `json:"priority,omitempty" bson:"priority"`
+	// Progress 任务执行进度
+	Progress APITaskProgress `json:"progress" bson:"progress"`
 	// Detail 子任务详情列表
 	Detail []APISubTaskDetail `json:"detail,omitempty" bson:"detail"`
 	// SupplierAccount 开发商ID
@@ -70,7 +77,37 @@ type APISubTaskDetail struct {
 	SubTaskID string        `json:"sub_task_id,omitempty" bson:"sub_task_id"`
 	Data      interface{}   `json:"data,omitempty" bson:"data"`
 	Status    APITaskStatus `json:"status,omitempty" bson:"status"`
-	Response  *Response     `json:"response,omitempty" bson:"response"`
+	// Response 子任务执行结果，执行失败时包含错误码和错误详情
+	Response *Response `json:"response,omitempty" bson:"response"`
+	// RetryTimes 子任务执行请求的重试次数
+	RetryTimes int64 `json:"retry_times,omitempty" bson:"retry_times,omitempty"`
+	// StartTime 子任务最近一次开始执行的时间
+	StartTime *time.Time `json:"start_time,omitempty" bson:"start_time,omitempty"`
+	// EndTime 子任务最近一次执行结束的时间
+	EndTime *time.Time `json:"end_time,omitempty" bson:"end_time,omitempty"`
+}
+
+// APITaskProgress api task execution progress
+type APITaskProgress struct {
+	// Total 子任务总数
+	Total int64 `json:"total" bson:"total"`
+	// Done 已执行完成的子任务数，包括执行成功和执行失败的子任务
+	Done int64 `json:"done" bson:"done"`
+	// CurrentSubTaskID 正在执行的子任务ID，任务不在执行中时为空
+	CurrentSubTaskID string `json:"current_sub_task_id,omitempty" bson:"current_sub_task_id"`
+	// EstimatedEndTime 根据本次执行已完成子任务的平均耗时预估的任务完成时间，任务不在执行中时为空
+	EstimatedEndTime *time.Time `json:"estimated_end_time,omitempty" bson:"estimated_end_time"`
+}
+
+// NewAPITaskProgress generate api task progress by its subtasks
+func NewAPITaskProgress(subTasks []APISubTaskDetail) APITaskProgress {
+	progress := APITaskProgress{Total: int64(len(subTasks))}
+	for _, subTask := range subTasks {
+		if subTask.Status.IsFinished() && subTask.Status != APITaskStatusCanceled {
+			progress.Done++
+		}
+	}
+	return progress
 }
 
 // APITaskSyncStatus api task sync status
@@ -88,6 +125,8 @@ type APITaskSyncStatus struct {
 	Extra interface{} `json:"extra,omitempty" bson:"extra"`
 	// Status 任务执行状态
 	Status APITaskStatus `json:"status,omitempty" bson:"status"`
+	// Progress 任务执行进度
+	Progress APITaskProgress `json:"progress" bson:"progress"`
 	// Creator 任务创建者
 	Creator string `json:"creator,omitempty" bson:"creator"`
 	// CreateTime 任务创建时间
@@ -103,7 +142,7 @@ type APITaskStatus string
 
 // IsFinished TODO
 func (s APITaskStatus) IsFinished() bool {
-	if s == APITaskStatusSuccess || s == APITAskStatusFail {
+	if s == APITaskStatusSuccess || s == APITAskStatusFail || s == APITaskStatusCanceled {
 		return true
 	}
 	return false
@@ -125,6 +164,22 @@ func (s APITaskStatus) IsFailure() bool {
 	return false
 }
 
+// IsInterrupted returns if the task is paused or canceled, the subsequent subtasks of an interrupted task will not be
+// executed.
+func (s APITaskStatus) IsInterrupted() bool {
+	return s == APITaskStatusPaused || s == APITaskStatusCanceled
+}
+
+// CanChangeTo returns if the task status can be changed to the target status by canceling, pausing or resuming it
+func (s APITaskStatus) CanChangeTo(target APITaskStatus) bool {
+	for _, status := range APITaskStatusTransitions[target] {
+		if s == status {
+			return true
+		}
+	}
+	return false
+}
+
 const (
 	// APITaskStatusNew new task ,waiting execute
 	APITaskStatusNew APITaskStatus = "new"
@@ -140,6 +195,13 @@ const (
 	// APITAskStatusFail task execute failure
 	APITAskStatusFail APITaskStatus = "failure"
 
+	// APITaskStatusPaused task is paused, it will not be executed until it is resumed,
+	// the executing batch of subtasks will not be interrupted, but the subsequent subtasks will not be executed
+	APITaskStatusPaused APITaskStatus = "paused"
+
+	// APITaskStatusCanceled task is canceled, the subtasks that are not executed will be canceled as well
+	APITaskStatusCanceled APITaskStatus = "canceled"
+
 	// APITAskStatusNeedSync only used for instance with all tasks finished but actual status is not finished
 	APITAskStatusNeedSync APITaskStatus = "need_sync"
 
@@ -150,11 +212,119 @@ const (
 	// APITaskExtraField the extra field is used
 	// in conjunction with instID to identify the uniqueness of the task.
 	APITaskExtraField = "extra"
+	// APITaskPriorityField the priority field of the task, tasks with higher priority are executed first.
+	APITaskPriorityField = "priority"
+	// APITaskProgressField the execution progress field of the task
+	APITaskProgressField = "progress"
 	// APITaskFieldTemplateMaxNum the possible task status scenarios are: one is executing,
 	// one is waiting or new, but there will be no more than two tasks.
 	APITaskFieldTemplateMaxNum = 2
 )
 
+// APITaskUnfinishedStatus the status of the tasks that are not finished, tasks with these status are in progress
+var APITaskUnfinishedStatus = []APITaskStatus{APITaskStatusNew, APITaskStatusWaitExecute, APITaskStatusExecute,
+	APITaskStatusPaused}
+
+// APITaskStatusTransitions the target status of canceling, pausing and resuming tasks to the status that the tasks
+// can be changed from, the paused tasks are resumed to waiting status.
+var APITaskStatusTransitions = map[APITaskStatus][]APITaskStatus{
+	APITaskStatusCanceled:    APITaskUnfinishedStatus,
+	APITaskStatusPaused:      {APITaskStatusNew, APITaskStatusWaitExecute, APITaskStatusExecute},
+	APITaskStatusWaitExecute: {APITaskStatusPaused},
+}
+
+// OperateAPITaskOption cancel, pause or resume api task option
+type OperateAPITaskOption struct {
+	TaskIDs []string `json:"task_ids"`
+}
+
+// Validate operate api task option
+func (o *OperateAPITaskOption) Validate() ccErr.RawErrorInfo {
+	if len(o.TaskIDs) == 0 {
+		return ccErr.RawErrorInfo{
+			ErrCode: common.CCErrCommParamsNeedSet,
+			Args:    []interface{}{"task_ids"},
+		}
+	}
+
+	if len(o.TaskIDs) > common.BKMaxLimitSize {
+		return ccErr.RawErrorInfo{
+			ErrCode: common.CCErrCommXXExceedLimit,
+			Args:    []interface{}{"task_ids", common.BKMaxLimitSize},
+		}
+	}
+	return ccErr.RawErrorInfo{}
+}
+
+// UpdateAPITaskPriorityOption update api task priority option
+type UpdateAPITaskPriorityOption struct {
+	TaskIDs  []string `json:"task_ids"`
+	Priority int64    `json:"priority"`
+}
+
+// Validate update api task priority option
+func (o *UpdateAPITaskPriorityOption) Validate() ccErr.RawErrorInfo {
+	opt := OperateAPITaskOption{TaskIDs: o.TaskIDs}
+	return opt.Validate()
+}
+
+// WatchAPITaskProgressOption watch api task progress option
+type WatchAPITaskProgressOption struct {
+	TaskID string `json:"task_id"`
+	// LastTime the last update time of the task that the caller has got, the request returns when the task is
+	// updated after this time or when the task is finished or the request times out. returns directly if not set.
+	LastTime *time.Time `json:"last_time"`
+	// Timeout the long poll timeout seconds, default is 30s, max is 60s
+	Timeout int64 `json:"timeout"`
+}
+
+const (
+	// APITaskWatchDefaultTimeout default long poll timeout seconds for watching api task progress
+	APITaskWatchDefaultTimeout int64 = 30
+	// APITaskWatchMaxTimeout max long poll timeout seconds for watching api task progress
+	APITaskWatchMaxTimeout int64 = 60
+)
+
+// Validate watch api task progress option
+func (o *WatchAPITaskProgressOption) Validate() ccErr.RawErrorInfo {
+	if len(o.TaskID) == 0 {
+		return ccErr.RawErrorInfo{
+			ErrCode: common.CCErrCommParamsNeedSet,
+			Args:    []interface{}{common.BKTaskIDField},
+		}
+	}
+
+	if o.Timeout < 0 || o.Timeout > APITaskWatchMaxTimeout {
+		return ccErr.RawErrorInfo{
+			ErrCode: common.CCErrCommParamsInvalid,
+			Args:    []interface{}{"timeout"},
+		}
+	}
+
+	if o.Timeout == 0 {
+		o.Timeout = APITaskWatchDefaultTimeout
+	}
+	return ccErr.RawErrorInfo{}
+}
+
+// APITaskProgressResult api task progress result
+type APITaskProgressResult struct {
+	TaskID   string          `json:"task_id"`
+	Status   APITaskStatus   `json:"status"`
+	Progress APITaskProgress `json:"progress"`
+	// FailedSubTasks the subtasks that are failed with their error details
+	FailedSubTasks []APISubTaskDetail `json:"failed_sub_tasks"`
+	LastTime       time.Time          `json:"last_time"`
+	// Changed if the task is updated after the last time in the request
+	Changed bool `json:"changed"`
+}
+
+// WatchAPITaskProgressResp watch api task progress response
+type WatchAPITaskProgressResp struct {
+	BaseResp
+	Data *APITaskProgressResult `json:"data"`
+}
+
 // ListAPITaskRequest TODO
 type ListAPITaskRequest struct {
 	Condition mapstr.MapStr `json:"condition"`
@@ -0,0 +1,74 @@
+/*
+ * Tencent is pleased to support the open source community by making 蓝鲸 available.
+ * Copyright (C) 2017-2018 THL A29 Limited, a Tencent company. All rights reserved.
+ * Licensed under the MIT License (the "License"); you may not use this file except
+ * in compliance with the License. You may obtain a copy of the License at
+ * http://opensource.org/licenses/MIT
+ * Unless required by applicable law or agreed to in writing, software distributed under
+ * the License is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND,
+ * either express or implied. See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package metadata
+
+import (
+	"testing"
+)
+
+func TestAPITaskStatusCanChangeTo(t *testing.T) {
+	tests := []struct {
+		name   string
+		from   APITaskStatus
+		target APITaskStatus
+		want   bool
+	}{
+		{"pause new task", APITaskStatusNew, APITaskStatusPaused, true},
+		{"pause waiting task", APITaskStatusWaitExecute, APITaskStatusPaused, true},
+		{"pause executing task", APITaskStatusExecute, APITaskStatusPaused, true},
+		{"pause paused task", APITaskStatusPaused, APITaskStatusPaused, false},
+		{"pause finished task", APITaskStatusSuccess, APITaskStatusPaused, false},
+		{"pause failed task", APITAskStatusFail, APITaskStatusPaused, false},
+		{"pause canceled task", APITaskStatusCanceled, APITaskStatusPaused, false},
+		{"cancel new task", APITaskStatusNew, APITaskStatusCanceled, true},
+		{"cancel waiting task", APITaskStatusWaitExecute, APITaskStatusCanceled, true},
+		{"cancel executing task", APITaskStatusExecute, APITaskStatusCanceled, true},
+		{"cancel paused task", APITaskStatusPaused, APITaskStatusCanceled, true},
+		{"cancel canceled task", APITaskStatusCanceled, APITaskStatusCanceled, false},
+		{"cancel finished task", APITaskStatusSuccess, APITaskStatusCanceled, false},
+		{"cancel failed task", APITAskStatusFail, APITaskStatusCanceled, false},
+		{"resume paused task", APITaskStatusPaused, APITaskStatusWaitExecute, true},
+		{"resume executing task", APITaskStatusExecute, APITaskStatusWaitExecute, false},
+		{"resume canceled task", APITaskStatusCanceled, APITaskStatusWaitExecute, false},
+		{"change to finished", APITaskStatusExecute, APITaskStatusSuccess, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.from.CanChangeTo(tt.target); got != tt.want {
+				t.Errorf("%s.CanChangeTo(%s) = %v, want %v", tt.from, tt.target, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestAPITaskStatusIsInterrupted(t *testing.T) {
+	tests := []struct {
+		status APITaskStatus
+		want   bool
+	}{
+		{APITaskStatusNew, false},
+		{APITaskStatusWaitExecute, false},
+		{APITaskStatusExecute, false},
+		{APITaskStatusSuccess, false},
+		{APITAskStatusFail, false},
+		{APITaskStatusPaused, true},
+		{APITaskStatusCanceled, true},
+	}
+	for _, tt := range tests {
+		t.Run(string(tt.status), func(t *testing.T) {
+			if got := tt.status.IsInterrupted(); got != tt.want {
+				t.Errorf("%s.IsInterrupted() = %v, want %v", tt.status, got, tt.want)
+			}
+		})
+	}
+}
@@ -17,6 +17,7 @@ import (
 	"regexp"
 	"time"
 
+	ccfilter "configcenter/pkg/filter"
 	"configcenter/src/common"
 )
 
@@ -131,6 +132,13 @@ var (
 	OperatorExist = Operator("exist")
 	// OperatorNotExist TODO
 	OperatorNotExist = Operator("not_exist")
+
+	// OperatorIPInCIDR ip in cidr operator, value is a cidr or an array of cidrs
+	OperatorIPInCIDR = Operator(ccfilter.IPInCIDR)
+	// OperatorIPNotInCIDR ip not in cidr operator, value is a cidr or an array of cidrs
+	OperatorIPNotInCIDR = Operator(ccfilter.IPNotInCIDR)
+	// OperatorIPRange ip range operator, value is an array of start ip and end ip
+	OperatorIPRange = Operator(ccfilter.IPRange)
 )
 
 // SupportOperators TODO
@@ -166,6 +174,10 @@ var SupportOperators = map[Operator]bool{
 
 	OperatorExist:    true,
 	OperatorNotExist: true,
+
+	OperatorIPInCIDR:    true,
+	OperatorIPNotInCIDR: true,
+	OperatorIPRange:     true,
 }
 
 // Validate TODO
@@ -249,6 +261,11 @@ func (r AtomRule) validateValue(option *RuleOption) error {
 		return nil
 	case OperatorExist, OperatorNotExist:
 		return nil
+	case OperatorIPInCIDR, OperatorIPNotInCIDR, OperatorIPRange:
+		// ip operators are the same as the filter's ip operators
+		maxLimit := uint(option.MaxSliceElementsCount)
+		opt := &ccfilter.ExprOption{MaxInLimit: maxLimit, MaxNotInLimit: maxLimit}
+		return ccfilter.OpFactory(r.Operator).Operator().ValidateValue(r.Value, opt)
 	default:
 		return fmt.Errorf("unsupported operator: %s", r.Operator)
 	}
@@ -393,6 +410,12 @@ func (r AtomRule) ToMgo() (mgoFiler map[string]interface{}, key string, err erro
 		filter[r.Field] = map[string]interface{}{
 			common.BKDBExists: false,
 		}
+	case OperatorIPInCIDR, OperatorIPNotInCIDR, OperatorIPRange:
+		ipFilter, err := ccfilter.OpFactory(r.Operator).Operator().ToMgo(r.Field, r.Value)
+		if err != nil {
+			return nil, "value", err
+		}
+		filter = ipFilter
 	default:
 		return nil, "operator", fmt.Errorf("unsupported operator: %s", r.Operator)
 	}
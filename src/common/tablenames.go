@@ -103,6 +103,7 @@ const (
 	BKTableNameCloudSyncTask    = "cc_CloudSyncTask"
 	BKTableNameCloudAccount     = "cc_CloudAccount"
 	BKTableNameCloudSyncHistory = "cc_CloudSyncHistory"
+	BKTableNameCloudSyncPreview = "cc_CloudSyncPreview"
 
 	// BKTableNameWatchToken the table to store the latest watch token for collections
 	BKTableNameWatchToken = "cc_WatchToken"
@@ -121,6 +122,27 @@ const (
 
 	// BKTableNameObjFieldTemplateRelation  object and field template relationship table
 	BKTableNameObjFieldTemplateRelation = "cc_ObjFieldTemplateRelation"
+
+	// BKTableNameEventSubscription the table to store the event push subscriptions
+	BKTableNameEventSubscription = "cc_EventSubscription"
+
+	// BKTableNameEventSubscriptionCursor the table to store the watch cursor of each subscription's resource
+	BKTableNameEventSubscriptionCursor = "cc_EventSubscriptionCursor"
+
+	// BKTableNameEventSubscriptionDeadLetter the table to store the event pushes that keep failing
+	BKTableNameEventSubscriptionDeadLetter = "cc_EventSubscriptionDeadLetter"
+
+	// BKTableNameDynamicGroupMember the table to store the materialized host members of the tracked dynamic groups
+	BKTableNameDynamicGroupMember = "cc_DynamicGroupMember"
+
+	// BKTableNameHostApplyReconcileConfig the table to store the host apply reconcile config of the businesses
+	BKTableNameHostApplyReconcileConfig = "cc_HostApplyReconcileConfig"
+
+	// BKTableNameHostApplyDrift the table to store the host fields that drift from their host apply rules
+	BKTableNameHostApplyDrift = "cc_HostApplyDrift"
+
+	// BKTableNameAuditLogChain the table to store the head of each supplier account's audit log hash chain
+	BKTableNameAuditLogChain = "cc_AuditLogChain"
 )
 
 // AllTables is all table names, not include the sharding tables which is created dynamically,
@@ -174,6 +196,8 @@ var AllTables = []string{
 	BKTableNameCloudSyncTask,
 	BKTableNameCloudAccount,
 	BKTableNameCloudSyncHistory,
+	BKTableNameCloudSyncPreview,
+	BKTableNameAuditLogChain,
 }
 
 // TableSpecifier is table specifier type which describes the metadata
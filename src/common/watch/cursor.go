@@ -79,6 +79,18 @@ var (
 		KubeWorkload:            20,
 		KubePod:                 21,
 		Project:                 22,
+		DynamicGroupMember:      23,
+		ServiceTemplate:         24,
+		ProcessTemplate:         25,
+		SetTemplate:             26,
+		FieldTemplate:           27,
+		Model:                   28,
+		ModelAttribute:          29,
+		HostApplyRule:           30,
+		KubeService:             31,
+		KubeIngress:             32,
+		KubeEndpoints:           33,
+		KubeConfigMap:           34,
 	}
 
 	intCursorTypeMap = make(map[int]CursorType)
@@ -124,6 +136,24 @@ const (
 	Plat CursorType = "plat"
 	// Project project event cursor type
 	Project CursorType = "project"
+	// DynamicGroupMember a mixed event type containing host, host relation, set & module events, which are converted
+	// to the events of hosts joining or leaving the tracked dynamic groups
+	DynamicGroupMember CursorType = "dynamic_group_member"
+	// template and model metadata related cursor types
+	// ServiceTemplate service template event cursor type
+	ServiceTemplate CursorType = "service_template"
+	// ProcessTemplate process template event cursor type, its sub resource is the service template id
+	ProcessTemplate CursorType = "process_template"
+	// SetTemplate set template event cursor type
+	SetTemplate CursorType = "set_template"
+	// FieldTemplate field template event cursor type
+	FieldTemplate CursorType = "field_template"
+	// Model model definition event cursor type
+	Model CursorType = "model"
+	// ModelAttribute model attribute event cursor type, its sub resource is the bk_obj_id of the attribute's model
+	ModelAttribute CursorType = "model_attribute"
+	// HostApplyRule host apply rule event cursor type
+	HostApplyRule CursorType = "host_apply_rule"
 	// kube related cursor types
 	// KubeCluster cursor type
 	KubeCluster CursorType = "kube_cluster"
@@ -135,6 +165,14 @@ const (
 	KubeWorkload CursorType = "kube_workload"
 	// KubePod cursor type, its event detail is pod info with containers in it
 	KubePod CursorType = "kube_pod"
+	// KubeService cursor type
+	KubeService CursorType = "kube_service"
+	// KubeIngress cursor type
+	KubeIngress CursorType = "kube_ingress"
+	// KubeEndpoints cursor type
+	KubeEndpoints CursorType = "kube_endpoints"
+	// KubeConfigMap cursor type
+	KubeConfigMap CursorType = "kube_config_map"
 )
 
 // ToInt TODO
@@ -162,7 +200,8 @@ func (ct *CursorType) ParseInt(typ int) {
 func ListCursorTypes() []CursorType {
 	return []CursorType{Host, ModuleHostRelation, Biz, Set, Module, ObjectBase, Process, ProcessInstanceRelation,
 		HostIdentifier, MainlineInstance, InstAsst, BizSet, BizSetRelation, Plat, KubeCluster, KubeNode, KubeNamespace,
-		KubeWorkload, KubePod, Project}
+		KubeWorkload, KubePod, Project, DynamicGroupMember, ServiceTemplate, ProcessTemplate, SetTemplate, FieldTemplate,
+		Model, ModelAttribute, HostApplyRule, KubeService, KubeIngress, KubeEndpoints, KubeConfigMap}
 }
 
 // Cursor is a self-defined token which is corresponding to the mongodb's resume token.
@@ -344,7 +383,18 @@ var collEventCursorTypeMap = map[string]CursorType{
 	kubetypes.BKTableNameBaseNamespace:        KubeNamespace,
 	kubetypes.BKTableNameBaseWorkload:         KubeWorkload,
 	kubetypes.BKTableNameBasePod:              KubePod,
+	kubetypes.BKTableNameBaseService:          KubeService,
+	kubetypes.BKTableNameBaseIngress:          KubeIngress,
+	kubetypes.BKTableNameBaseEndpoints:        KubeEndpoints,
+	kubetypes.BKTableNameBaseConfigMap:        KubeConfigMap,
 	common.BKTableNameBaseProject:             Project,
+	common.BKTableNameServiceTemplate:         ServiceTemplate,
+	common.BKTableNameProcessTemplate:         ProcessTemplate,
+	common.BKTableNameSetTemplate:             SetTemplate,
+	common.BKTableNameFieldTemplate:           FieldTemplate,
+	common.BKTableNameObjDes:                  Model,
+	common.BKTableNameObjAttDes:               ModelAttribute,
+	common.BKTableNameHostApplyRule:           HostApplyRule,
 }
 
 // GetEventCursor get event cursor.
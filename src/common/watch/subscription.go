@@ -0,0 +1,359 @@
+/*
+ * Tencent is pleased to support the open source community by making 蓝鲸 available.
+ * Copyright (C) 2017-2018 THL A29 Limited, a Tencent company. All rights reserved.
+ * Licensed under the MIT License (the "License"); you may not use this file except
+ * in compliance with the License. You may obtain a copy of the License at
+ * http://opensource.org/licenses/MIT
+ * Unless required by applicable law or agreed to in writing, software distributed under
+ * the License is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND,
+ * either express or implied. See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package watch
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"net/url"
+	"strconv"
+	"unicode/utf8"
+
+	"configcenter/src/common"
+	"configcenter/src/common/metadata"
+)
+
+const (
+	// SubscriptionSignatureHeader is the http header which carries the hmac signature of a push request body.
+	SubscriptionSignatureHeader = "X-Bkcmdb-Signature"
+	// SubscriptionTimestampHeader is the http header which carries the unix timestamp of a push request.
+	SubscriptionTimestampHeader = "X-Bkcmdb-Timestamp"
+	// SubscriptionIDHeader is the http header which carries the subscription id of a push request.
+	SubscriptionIDHeader = "X-Bkcmdb-Subscription-Id"
+	// subscriptionSignaturePrefix is the algorithm prefix of the signature header value.
+	subscriptionSignaturePrefix = "sha256="
+
+	// DefaultSubscriptionBatchSize is the default number of events pushed in one request.
+	DefaultSubscriptionBatchSize = 50
+	// MaxSubscriptionBatchSize is the maximum number of events pushed in one request.
+	MaxSubscriptionBatchSize = 200
+	// DefaultSubscriptionTimeout is the default timeout seconds of one push request.
+	DefaultSubscriptionTimeout = 10
+	// MaxSubscriptionTimeout is the maximum timeout seconds of one push request.
+	MaxSubscriptionTimeout = 60
+	// DefaultSubscriptionMaxRetry is the default retry times of a failed push request before it's dead lettered.
+	DefaultSubscriptionMaxRetry = 5
+	// MaxSubscriptionMaxRetry is the maximum retry times of a failed push request.
+	MaxSubscriptionMaxRetry = 20
+
+	// MaxRedeliverDeadLetterCount is the maximum number of dead letters that can be redelivered in one request,
+	// because the dead letters are pushed synchronously in the request.
+	MaxRedeliverDeadLetterCount = 10
+
+	subscriptionNameMaxLen   = 128
+	subscriptionSecretMaxLen = 256
+)
+
+// Subscription defines a server side subscription, the watched events of the subscribed resources are pushed
+// to the callback url in batches instead of being long polled by the subscriber.
+type Subscription struct {
+	ID   int64  `json:"id" bson:"id"`
+	Name string `json:"name" bson:"name"`
+	// Resources the resources to be watched and pushed, each resource holds its own cursor.
+	Resources []CursorType `json:"bk_resources" bson:"bk_resources"`
+	// EventTypes event types you want to care, empty means all.
+	EventTypes []EventType `json:"bk_event_types" bson:"bk_event_types"`
+	// Fields the fields you only care, if nil, means all. it's required for host, biz, set and module resources.
+	Fields []string `json:"bk_fields" bson:"bk_fields"`
+	// Filter the watch filter, eg. sub resource(object id) of object instance resource.
+	Filter WatchEventFilter `json:"bk_filter" bson:"bk_filter"`
+	// CallbackURL the http(s) url that receives the pushed events.
+	CallbackURL string `json:"callback_url" bson:"callback_url"`
+	// Secret is used to sign the push request body, the signature is set in SubscriptionSignatureHeader.
+	Secret string `json:"secret,omitempty" bson:"secret"`
+	// BatchSize the maximum number of events pushed in one request.
+	BatchSize int `json:"batch_size" bson:"batch_size"`
+	// Timeout the timeout seconds of one push request.
+	Timeout int `json:"timeout" bson:"timeout"`
+	// MaxRetry the retry times of a failed push request before it's dead lettered.
+	MaxRetry int    `json:"max_retry" bson:"max_retry"`
+	Enabled  bool   `json:"enabled" bson:"enabled"`
+	OwnerID  string `json:"bk_supplier_account" bson:"bk_supplier_account"`
+	Creator  string `json:"creator" bson:"creator"`
+	Modifier string `json:"modifier" bson:"modifier"`
+	// CreateTime and LastTime are used to detect if a subscription is changed and its workers need to be reloaded.
+	CreateTime metadata.Time `json:"create_time" bson:"create_time"`
+	LastTime   metadata.Time `json:"last_time" bson:"last_time"`
+}
+
+// Validate validates the subscription and sets the default values of the optional fields.
+func (s *Subscription) Validate() error {
+	if len(s.Name) == 0 {
+		return errors.New("name is not set")
+	}
+
+	if utf8.RuneCountInString(s.Name) > subscriptionNameMaxLen {
+		return fmt.Errorf("name exceeds max length %d", subscriptionNameMaxLen)
+	}
+
+	if len(s.Resources) == 0 {
+		return errors.New("bk_resources is not set")
+	}
+
+	supported := make(map[CursorType]struct{})
+	for _, resource := range ListCursorTypes() {
+		supported[resource] = struct{}{}
+	}
+
+	duplicated := make(map[CursorType]struct{})
+	for _, resource := range s.Resources {
+		if _, exists := supported[resource]; !exists {
+			return fmt.Errorf("unsupported resource %s", resource)
+		}
+
+		if _, exists := duplicated[resource]; exists {
+			return fmt.Errorf("resource %s is duplicated", resource)
+		}
+		duplicated[resource] = struct{}{}
+
+		// use the watch options validation so that the subscription is always watchable
+		opts := s.WatchOptions(resource, "")
+		if err := opts.Validate(); err != nil {
+			return err
+		}
+	}
+
+	u, err := url.Parse(s.CallbackURL)
+	if err != nil {
+		return fmt.Errorf("invalid callback_url, err: %v", err)
+	}
+
+	if (u.Scheme != "http" && u.Scheme != "https") || len(u.Host) == 0 {
+		return errors.New("callback_url must be an absolute http or https url")
+	}
+
+	if utf8.RuneCountInString(s.Secret) > subscriptionSecretMaxLen {
+		return fmt.Errorf("secret exceeds max length %d", subscriptionSecretMaxLen)
+	}
+
+	switch {
+	case s.BatchSize == 0:
+		s.BatchSize = DefaultSubscriptionBatchSize
+	case s.BatchSize < 0 || s.BatchSize > MaxSubscriptionBatchSize:
+		return fmt.Errorf("batch_size must be in range [1, %d]", MaxSubscriptionBatchSize)
+	}
+
+	switch {
+	case s.Timeout == 0:
+		s.Timeout = DefaultSubscriptionTimeout
+	case s.Timeout < 0 || s.Timeout > MaxSubscriptionTimeout:
+		return fmt.Errorf("timeout must be in range [1, %d]", MaxSubscriptionTimeout)
+	}
+
+	switch {
+	case s.MaxRetry == 0:
+		s.MaxRetry = DefaultSubscriptionMaxRetry
+	case s.MaxRetry < 0 || s.MaxRetry > MaxSubscriptionMaxRetry:
+		return fmt.Errorf("max_retry must be in range [1, %d]", MaxSubscriptionMaxRetry)
+	}
+
+	return nil
+}
+
+// WatchOptions generate the watch options of the subscription's resource with the cursor it holds.
+func (s *Subscription) WatchOptions(resource CursorType, cursor string) *WatchEventOptions {
+	opts := &WatchEventOptions{
+		EventTypes: s.EventTypes,
+		Fields:     s.Fields,
+		Cursor:     cursor,
+		Resource:   resource,
+	}
+
+	switch resource {
+	case ObjectBase, MainlineInstance, InstAsst, KubeWorkload, DynamicGroupMember, ProcessTemplate, ModelAttribute:
+		opts.Filter = s.Filter
+	}
+
+	return opts
+}
+
+// UpdateSubscriptionOption is the option to update a subscription, only the set fields are updated.
+type UpdateSubscriptionOption struct {
+	Name        *string           `json:"name"`
+	Resources   []CursorType      `json:"bk_resources"`
+	EventTypes  []EventType       `json:"bk_event_types"`
+	Fields      []string          `json:"bk_fields"`
+	Filter      *WatchEventFilter `json:"bk_filter"`
+	CallbackURL *string           `json:"callback_url"`
+	Secret      *string           `json:"secret"`
+	BatchSize   *int              `json:"batch_size"`
+	Timeout     *int              `json:"timeout"`
+	MaxRetry    *int              `json:"max_retry"`
+	Enabled     *bool             `json:"enabled"`
+}
+
+// Validate validates the update subscription option. the filter decides the sub resources to be authorized,
+// so the resources must be set with the filter to be authorized again.
+func (u *UpdateSubscriptionOption) Validate() error {
+	if u.Filter != nil && len(u.Resources) == 0 {
+		return errors.New("bk_resources must be set when bk_filter is updated")
+	}
+
+	return nil
+}
+
+// MergeInto merges the update option into the subscription.
+func (u *UpdateSubscriptionOption) MergeInto(s *Subscription) {
+	if u.Name != nil {
+		s.Name = *u.Name
+	}
+	if u.Resources != nil {
+		s.Resources = u.Resources
+	}
+	if u.EventTypes != nil {
+		s.EventTypes = u.EventTypes
+	}
+	if u.Fields != nil {
+		s.Fields = u.Fields
+	}
+	if u.Filter != nil {
+		s.Filter = *u.Filter
+	}
+	if u.CallbackURL != nil {
+		s.CallbackURL = *u.CallbackURL
+	}
+	if u.Secret != nil {
+		s.Secret = *u.Secret
+	}
+	if u.BatchSize != nil {
+		s.BatchSize = *u.BatchSize
+	}
+	if u.Timeout != nil {
+		s.Timeout = *u.Timeout
+	}
+	if u.MaxRetry != nil {
+		s.MaxRetry = *u.MaxRetry
+	}
+	if u.Enabled != nil {
+		s.Enabled = *u.Enabled
+	}
+}
+
+// ListSubscriptionOption is the option to list subscriptions.
+type ListSubscriptionOption struct {
+	IDs  []int64           `json:"ids"`
+	Page metadata.BasePage `json:"page"`
+}
+
+// Validate validates the list subscription option.
+func (l *ListSubscriptionOption) Validate() error {
+	if len(l.IDs) > common.BKMaxPageSize {
+		return fmt.Errorf("ids exceeds max length %d", common.BKMaxPageSize)
+	}
+
+	if l.Page.IsIllegal() {
+		return errors.New("page is illegal")
+	}
+
+	return nil
+}
+
+// SubscriptionCursor is the watch cursor that a subscription holds for one of its resources.
+type SubscriptionCursor struct {
+	SubscriptionID int64         `json:"subscription_id" bson:"subscription_id"`
+	Resource       CursorType    `json:"bk_resource" bson:"bk_resource"`
+	Cursor         string        `json:"bk_cursor" bson:"bk_cursor"`
+	OwnerID        string        `json:"bk_supplier_account" bson:"bk_supplier_account"`
+	LastTime       metadata.Time `json:"last_time" bson:"last_time"`
+}
+
+// SubscriptionPayload is the request body pushed to the subscription's callback url.
+type SubscriptionPayload struct {
+	SubscriptionID int64               `json:"subscription_id"`
+	Resource       CursorType          `json:"bk_resource"`
+	Events         []*WatchEventDetail `json:"bk_events"`
+}
+
+// SubscriptionDeadLetter is a push request that is still failed after all the retries.
+type SubscriptionDeadLetter struct {
+	ID             int64      `json:"id" bson:"id"`
+	SubscriptionID int64      `json:"subscription_id" bson:"subscription_id"`
+	Resource       CursorType `json:"bk_resource" bson:"bk_resource"`
+	// Payload is the raw json request body, so that it can be redelivered as it is.
+	Payload string `json:"payload" bson:"payload"`
+	// FirstCursor and LastCursor are the cursors of the first and last event in the payload.
+	FirstCursor string        `json:"first_cursor" bson:"first_cursor"`
+	LastCursor  string        `json:"last_cursor" bson:"last_cursor"`
+	Attempts    int           `json:"attempts" bson:"attempts"`
+	Error       string        `json:"error" bson:"error"`
+	OwnerID     string        `json:"bk_supplier_account" bson:"bk_supplier_account"`
+	CreateTime  metadata.Time `json:"create_time" bson:"create_time"`
+	LastTime    metadata.Time `json:"last_time" bson:"last_time"`
+}
+
+// ListSubscriptionDeadLetterOption is the option to list dead letters of a subscription.
+type ListSubscriptionDeadLetterOption struct {
+	SubscriptionID int64             `json:"subscription_id"`
+	Page           metadata.BasePage `json:"page"`
+}
+
+// Validate validates the list subscription dead letter option.
+func (l *ListSubscriptionDeadLetterOption) Validate() error {
+	if l.SubscriptionID <= 0 {
+		return errors.New("subscription_id is invalid")
+	}
+
+	if l.Page.IsIllegal() {
+		return errors.New("page is illegal")
+	}
+
+	return nil
+}
+
+// RedeliverDeadLetterOption is the option to redeliver dead letters of a subscription.
+type RedeliverDeadLetterOption struct {
+	SubscriptionID int64   `json:"subscription_id"`
+	IDs            []int64 `json:"ids"`
+}
+
+// Validate validates the redeliver dead letter option.
+func (r *RedeliverDeadLetterOption) Validate() error {
+	if r.SubscriptionID <= 0 {
+		return errors.New("subscription_id is invalid")
+	}
+
+	if len(r.IDs) == 0 {
+		return errors.New("ids is not set")
+	}
+
+	if len(r.IDs) > MaxRedeliverDeadLetterCount {
+		return fmt.Errorf("ids exceeds max length %d", MaxRedeliverDeadLetterCount)
+	}
+
+	return nil
+}
+
+// RedeliverDeadLetterResult is the result of redelivering dead letters.
+type RedeliverDeadLetterResult struct {
+	SuccessIDs []int64 `json:"success_ids"`
+	FailedIDs  []int64 `json:"failed_ids"`
+}
+
+// SignSubscriptionPayload generates the signature of a push request, it's the hex encoded
+// hmac-sha256 of "{timestamp}.{body}" with the subscription's secret as the key.
+func SignSubscriptionPayload(secret string, timestamp int64, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(strconv.FormatInt(timestamp, 10)))
+	mac.Write([]byte{'.'})
+	mac.Write(body)
+	return subscriptionSignaturePrefix + hex.EncodeToString(mac.Sum(nil))
+}
+
+// VerifySubscriptionSignature checks if the signature of a push request is valid.
+func VerifySubscriptionSignature(secret string, timestamp int64, body []byte, signature string) bool {
+	expected := SignSubscriptionPayload(secret, timestamp, body)
+	return hmac.Equal([]byte(expected), []byte(signature))
+}
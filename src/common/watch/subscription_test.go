@@ -0,0 +1,83 @@
+/*
+ * Tencent is pleased to support the open source community by making 蓝鲸 available.
+ * Copyright (C) 2017-2018 THL A29 Limited, a Tencent company. All rights reserved.
+ * Licensed under the MIT License (the "License"); you may not use this file except
+ * in compliance with the License. You may obtain a copy of the License at
+ * http://opensource.org/licenses/MIT
+ * Unless required by applicable law or agreed to in writing, software distributed under
+ * the License is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND,
+ * either express or implied. See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package watch
+
+import (
+	"testing"
+)
+
+func TestSignSubscriptionPayload(t *testing.T) {
+	body := []byte(`{"a":1}`)
+	sign := SignSubscriptionPayload("secret", 1700000000, body)
+	if sign != "sha256=49f24e537407743fa4a0242bb63b94b9a47ee99cbbe071ccd8a22550ae411686" {
+		t.Errorf("sign subscription payload, got invalid signature: %s", sign)
+		return
+	}
+
+	if !VerifySubscriptionSignature("secret", 1700000000, body, sign) {
+		t.Errorf("verify subscription signature failed")
+		return
+	}
+
+	if VerifySubscriptionSignature("secret", 1700000001, body, sign) {
+		t.Errorf("verify subscription signature with another timestamp should fail")
+		return
+	}
+
+	if VerifySubscriptionSignature("another", 1700000000, body, sign) {
+		t.Errorf("verify subscription signature with another secret should fail")
+		return
+	}
+}
+
+func TestSubscriptionValidate(t *testing.T) {
+	sub := &Subscription{
+		Name:        "test",
+		Resources:   []CursorType{Process, ObjectBase},
+		Filter:      WatchEventFilter{SubResource: "switch"},
+		CallbackURL: "http://127.0.0.1:8080/echo",
+	}
+
+	if err := sub.Validate(); err != nil {
+		t.Errorf("validate subscription failed, err: %v", err)
+		return
+	}
+
+	if sub.BatchSize != DefaultSubscriptionBatchSize || sub.Timeout != DefaultSubscriptionTimeout ||
+		sub.MaxRetry != DefaultSubscriptionMaxRetry {
+		t.Errorf("validate subscription, default values are not set: %+v", sub)
+		return
+	}
+
+	if opts := sub.WatchOptions(Process, ""); opts.Filter.SubResource != "" {
+		t.Errorf("process watch options should not have sub resource")
+		return
+	}
+
+	invalids := []*Subscription{
+		{Name: "test", Resources: []CursorType{Host}, CallbackURL: "http://127.0.0.1/echo"},
+		{Name: "test", Resources: []CursorType{Process, Process}, CallbackURL: "http://127.0.0.1/echo"},
+		{Name: "test", Resources: []CursorType{"unknown"}, CallbackURL: "http://127.0.0.1/echo"},
+		{Name: "test", Resources: []CursorType{Process}, CallbackURL: "ftp://127.0.0.1/echo"},
+		{Name: "test", Resources: []CursorType{Process}, CallbackURL: "/echo"},
+		{Name: "test", Resources: []CursorType{Process}, CallbackURL: "http://127.0.0.1/echo", BatchSize: 1000},
+		{Resources: []CursorType{Process}, CallbackURL: "http://127.0.0.1/echo"},
+	}
+
+	for idx, invalid := range invalids {
+		if err := invalid.Validate(); err == nil {
+			t.Errorf("validate invalid subscription %d should fail", idx)
+			return
+		}
+	}
+}
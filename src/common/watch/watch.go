@@ -39,7 +39,9 @@ type WatchEventOptions struct {
 
 // WatchEventFilter TODO
 type WatchEventFilter struct {
-	// SubResource the sub resource you want to watch, eg. object ID of the instance resource, watch all if not set
+	// SubResource the sub resource you want to watch, eg. object ID of the instance resource, dynamic group ID of the
+	// dynamic group member resource, service template ID of the process template resource, object ID of the model
+	// attribute resource, watch all if not set
 	SubResource string `json:"bk_sub_resource,omitempty"`
 }
 
@@ -67,7 +69,7 @@ func (w *WatchEventOptions) Validate() error {
 
 	if len(w.Filter.SubResource) > 0 {
 		switch w.Resource {
-		case ObjectBase, MainlineInstance, InstAsst, KubeWorkload:
+		case ObjectBase, MainlineInstance, InstAsst, KubeWorkload, DynamicGroupMember, ProcessTemplate, ModelAttribute:
 		default:
 			return fmt.Errorf("%s event cannot have sub resource", w.Resource)
 		}
@@ -0,0 +1,111 @@
+/*
+ * Tencent is pleased to support the open source community by making
+ * 蓝鲸智云 - 配置平台 (BlueKing - Configuration System) available.
+ * Copyright (C) 2017 THL A29 Limited,
+ * a Tencent company. All rights reserved.
+ * Licensed under the MIT License (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at http://opensource.org/licenses/MIT
+ * Unless required by applicable law or agreed to in writing,
+ * software distributed under the License is distributed on
+ * an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND,
+ * either express or implied. See the License for the
+ * specific language governing permissions and limitations under the License.
+ * We undertake not to change the open source license (MIT license) applicable
+ * to the current version of the project delivered to anyone in the future.
+ */
+
+package types
+
+import (
+	"errors"
+
+	"configcenter/src/common"
+	"configcenter/src/common/criteria/enumor"
+	ccErr "configcenter/src/common/errors"
+	"configcenter/src/storage/dal/table"
+)
+
+// ConfigMapFields merge the fields of the ConfigMap and the details corresponding to the fields together.
+var ConfigMapFields = table.MergeFields(CommonSpecFieldsDescriptor, NamespaceBaseRefDescriptor,
+	ClusterBaseRefDescriptor, ConfigMapSpecFieldsDescriptor)
+
+// ConfigMapSpecFieldsDescriptor ConfigMap spec's fields descriptors.
+var ConfigMapSpecFieldsDescriptor = table.FieldsDescriptors{
+	{Field: KubeNameField, Type: enumor.String, IsRequired: true, IsEditable: false},
+	{Field: LabelsField, Type: enumor.MapString, IsRequired: false, IsEditable: true},
+	{Field: DataField, Type: enumor.MapString, IsRequired: false, IsEditable: true},
+	{Field: ImmutableField, Type: enumor.Boolean, IsRequired: false, IsEditable: true},
+}
+
+// ConfigMap define the config map struct.
+type ConfigMap struct {
+	NsResourceBase `json:",inline" bson:",inline"`
+	Labels         *map[string]string `json:"labels,omitempty" bson:"labels"`
+	Data           *map[string]string `json:"data,omitempty" bson:"data"`
+	Immutable      *bool              `json:"immutable,omitempty" bson:"immutable"`
+}
+
+// GetNsResourceBase get namespace resource base
+func (c *ConfigMap) GetNsResourceBase() NsResourceBase {
+	return c.NsResourceBase
+}
+
+// SetNsResourceBase set namespace resource base
+func (c *ConfigMap) SetNsResourceBase(res NsResourceBase) {
+	c.NsResourceBase = res
+}
+
+// ValidateCreate validate create config map
+func (c *ConfigMap) ValidateCreate() ccErr.RawErrorInfo {
+	if c == nil {
+		return ccErr.RawErrorInfo{
+			ErrCode: common.CCErrCommParamsNeedSet,
+			Args:    []interface{}{"data"},
+		}
+	}
+
+	return validateNsResourceCreate(c, *c, ConfigMapFields)
+}
+
+// ValidateUpdate validate update config map
+func (c *ConfigMap) ValidateUpdate() ccErr.RawErrorInfo {
+	if c == nil {
+		return ccErr.RawErrorInfo{
+			ErrCode: common.CCErrCommParamsNeedSet,
+			Args:    []interface{}{"data"},
+		}
+	}
+
+	return ValidateUpdate(*c, ConfigMapFields)
+}
+
+// BuildUpdateData build config map update data
+func (c *ConfigMap) BuildUpdateData(user string) (map[string]interface{}, error) {
+	if c == nil {
+		return nil, errors.New("update param is invalid")
+	}
+
+	return buildNsResourceUpdateData(c, user)
+}
+
+// IsMountedBy check if the config map is mounted by the pod volumes, including the projected volumes
+func (c *ConfigMap) IsMountedBy(volumes []Volume) bool {
+	for _, volume := range volumes {
+		if volume.ConfigMap != nil && volume.ConfigMap.Name == c.Name {
+			return true
+		}
+
+		if volume.Projected == nil {
+			continue
+		}
+
+		for _, source := range volume.Projected.Sources {
+			if source.ConfigMap != nil && source.ConfigMap.Name == c.Name {
+				return true
+			}
+		}
+	}
+
+	return false
+}
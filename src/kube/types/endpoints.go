@@ -0,0 +1,141 @@
+/*
+ * Tencent is pleased to support the open source community by making
+ * 蓝鲸智云 - 配置平台 (BlueKing - Configuration System) available.
+ * Copyright (C) 2017 THL A29 Limited,
+ * a Tencent company. All rights reserved.
+ * Licensed under the MIT License (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at http://opensource.org/licenses/MIT
+ * Unless required by applicable law or agreed to in writing,
+ * software distributed under the License is distributed on
+ * an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND,
+ * either express or implied. See the License for the
+ * specific language governing permissions and limitations under the License.
+ * We undertake not to change the open source license (MIT license) applicable
+ * to the current version of the project delivered to anyone in the future.
+ */
+
+package types
+
+import (
+	"errors"
+
+	"configcenter/src/common"
+	"configcenter/src/common/criteria/enumor"
+	ccErr "configcenter/src/common/errors"
+	"configcenter/src/storage/dal/table"
+)
+
+// EndpointsFields merge the fields of the Endpoints and the details corresponding to the fields together.
+var EndpointsFields = table.MergeFields(CommonSpecFieldsDescriptor, NamespaceBaseRefDescriptor,
+	ClusterBaseRefDescriptor, EndpointsSpecFieldsDescriptor)
+
+// EndpointsSpecFieldsDescriptor Endpoints spec's fields descriptors.
+var EndpointsSpecFieldsDescriptor = table.FieldsDescriptors{
+	{Field: KubeNameField, Type: enumor.String, IsRequired: true, IsEditable: false},
+	{Field: LabelsField, Type: enumor.MapString, IsRequired: false, IsEditable: true},
+	{Field: SubsetsField, Type: enumor.Array, IsRequired: false, IsEditable: true},
+}
+
+// EndpointTargetRef the reference to the object providing the endpoint, it is usually a pod.
+type EndpointTargetRef struct {
+	Kind string `json:"kind" bson:"kind"`
+	Name string `json:"name" bson:"name"`
+}
+
+// EndpointAddress describes a single IP address.
+type EndpointAddress struct {
+	IP        string             `json:"ip" bson:"ip"`
+	Hostname  string             `json:"hostname" bson:"hostname"`
+	NodeName  string             `json:"node_name" bson:"node_name"`
+	TargetRef *EndpointTargetRef `json:"target_ref" bson:"target_ref"`
+}
+
+// EndpointPort is a tuple that describes a single port.
+type EndpointPort struct {
+	Name     string   `json:"name" bson:"name"`
+	Port     int32    `json:"port" bson:"port"`
+	Protocol Protocol `json:"protocol" bson:"protocol"`
+}
+
+// EndpointSubset is a group of addresses with a common set of ports.
+type EndpointSubset struct {
+	Addresses         []EndpointAddress `json:"addresses" bson:"addresses"`
+	NotReadyAddresses []EndpointAddress `json:"not_ready_addresses" bson:"not_ready_addresses"`
+	Ports             []EndpointPort    `json:"ports" bson:"ports"`
+}
+
+// Endpoints define the endpoints struct, the endpoints belongs to the service with the same name.
+type Endpoints struct {
+	NsResourceBase `json:",inline" bson:",inline"`
+	Labels         *map[string]string `json:"labels,omitempty" bson:"labels"`
+	Subsets        *[]EndpointSubset  `json:"subsets,omitempty" bson:"subsets"`
+}
+
+// GetNsResourceBase get namespace resource base
+func (e *Endpoints) GetNsResourceBase() NsResourceBase {
+	return e.NsResourceBase
+}
+
+// SetNsResourceBase set namespace resource base
+func (e *Endpoints) SetNsResourceBase(res NsResourceBase) {
+	e.NsResourceBase = res
+}
+
+// ValidateCreate validate create endpoints
+func (e *Endpoints) ValidateCreate() ccErr.RawErrorInfo {
+	if e == nil {
+		return ccErr.RawErrorInfo{
+			ErrCode: common.CCErrCommParamsNeedSet,
+			Args:    []interface{}{"data"},
+		}
+	}
+
+	return validateNsResourceCreate(e, *e, EndpointsFields)
+}
+
+// ValidateUpdate validate update endpoints
+func (e *Endpoints) ValidateUpdate() ccErr.RawErrorInfo {
+	if e == nil {
+		return ccErr.RawErrorInfo{
+			ErrCode: common.CCErrCommParamsNeedSet,
+			Args:    []interface{}{"data"},
+		}
+	}
+
+	return ValidateUpdate(*e, EndpointsFields)
+}
+
+// BuildUpdateData build endpoints update data
+func (e *Endpoints) BuildUpdateData(user string) (map[string]interface{}, error) {
+	if e == nil {
+		return nil, errors.New("update param is invalid")
+	}
+
+	return buildNsResourceUpdateData(e, user)
+}
+
+// GetPodNames get the names of the pods that provide the ready and not ready endpoints
+func (e *Endpoints) GetPodNames() []string {
+	names := make([]string, 0)
+	if e.Subsets == nil {
+		return names
+	}
+
+	exists := make(map[string]struct{})
+	for _, subset := range *e.Subsets {
+		addresses := append(append([]EndpointAddress{}, subset.Addresses...), subset.NotReadyAddresses...)
+		for _, address := range addresses {
+			if address.TargetRef == nil || address.TargetRef.Kind != "Pod" || address.TargetRef.Name == "" {
+				continue
+			}
+			if _, ok := exists[address.TargetRef.Name]; ok {
+				continue
+			}
+			exists[address.TargetRef.Name] = struct{}{}
+			names = append(names, address.TargetRef.Name)
+		}
+	}
+
+	return names
+}
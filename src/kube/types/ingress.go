@@ -0,0 +1,185 @@
+/*
+ * Tencent is pleased to support the open source community by making
+ * 蓝鲸智云 - 配置平台 (BlueKing - Configuration System) available.
+ * Copyright (C) 2017 THL A29 Limited,
+ * a Tencent company. All rights reserved.
+ * Licensed under the MIT License (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at http://opensource.org/licenses/MIT
+ * Unless required by applicable law or agreed to in writing,
+ * software distributed under the License is distributed on
+ * an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND,
+ * either express or implied. See the License for the
+ * specific language governing permissions and limitations under the License.
+ * We undertake not to change the open source license (MIT license) applicable
+ * to the current version of the project delivered to anyone in the future.
+ */
+
+package types
+
+import (
+	"errors"
+
+	"configcenter/src/common"
+	"configcenter/src/common/criteria/enumor"
+	ccErr "configcenter/src/common/errors"
+	"configcenter/src/storage/dal/table"
+)
+
+// IngressFields merge the fields of the Ingress and the details corresponding to the fields together.
+var IngressFields = table.MergeFields(CommonSpecFieldsDescriptor, NamespaceBaseRefDescriptor,
+	ClusterBaseRefDescriptor, IngressSpecFieldsDescriptor)
+
+// IngressSpecFieldsDescriptor Ingress spec's fields descriptors.
+var IngressSpecFieldsDescriptor = table.FieldsDescriptors{
+	{Field: KubeNameField, Type: enumor.String, IsRequired: true, IsEditable: false},
+	{Field: LabelsField, Type: enumor.MapString, IsRequired: false, IsEditable: true},
+	{Field: IngressClassNameField, Type: enumor.String, IsRequired: false, IsEditable: true},
+	{Field: DefaultBackendField, Type: enumor.Object, IsRequired: false, IsEditable: true},
+	{Field: RulesField, Type: enumor.Array, IsRequired: false, IsEditable: true},
+	{Field: TLSField, Type: enumor.Array, IsRequired: false, IsEditable: true},
+}
+
+// IngressBackend describes the service and port that the ingress traffic is routed to.
+type IngressBackend struct {
+	// ServiceName the referenced service name, the service must exist in the same namespace as the ingress.
+	ServiceName string `json:"service_name" bson:"service_name"`
+	// ServicePort the referenced port of the service, can be the port number or the port name.
+	ServicePort *IntOrString `json:"service_port" bson:"service_port"`
+}
+
+// IngressPath associates a path with a backend.
+type IngressPath struct {
+	// Path is matched against the path of an incoming request.
+	Path string `json:"path" bson:"path"`
+	// PathType determines the interpretation of the path matching, can be Exact, Prefix or ImplementationSpecific.
+	PathType string `json:"path_type" bson:"path_type"`
+	// Backend defines the referenced service endpoint to which the traffic will be forwarded to.
+	Backend IngressBackend `json:"backend" bson:"backend"`
+}
+
+// IngressRule represents the rules mapping the paths under a specified host to the related backend services.
+type IngressRule struct {
+	// Host the fully qualified domain name of a network host, the rule applies to all hosts if it is not set.
+	Host  string        `json:"host" bson:"host"`
+	Paths []IngressPath `json:"paths" bson:"paths"`
+}
+
+// IngressTLS describes the transport layer security associated with an ingress.
+type IngressTLS struct {
+	Hosts      []string `json:"hosts" bson:"hosts"`
+	SecretName string   `json:"secret_name" bson:"secret_name"`
+}
+
+// Ingress define the ingress struct.
+type Ingress struct {
+	NsResourceBase   `json:",inline" bson:",inline"`
+	Labels           *map[string]string `json:"labels,omitempty" bson:"labels"`
+	IngressClassName *string            `json:"ingress_class_name,omitempty" bson:"ingress_class_name"`
+	DefaultBackend   *IngressBackend    `json:"default_backend,omitempty" bson:"default_backend"`
+	Rules            *[]IngressRule     `json:"rules,omitempty" bson:"rules"`
+	TLS              *[]IngressTLS      `json:"tls,omitempty" bson:"tls"`
+}
+
+// GetNsResourceBase get namespace resource base
+func (i *Ingress) GetNsResourceBase() NsResourceBase {
+	return i.NsResourceBase
+}
+
+// SetNsResourceBase set namespace resource base
+func (i *Ingress) SetNsResourceBase(res NsResourceBase) {
+	i.NsResourceBase = res
+}
+
+// ValidateCreate validate create ingress
+func (i *Ingress) ValidateCreate() ccErr.RawErrorInfo {
+	if i == nil {
+		return ccErr.RawErrorInfo{
+			ErrCode: common.CCErrCommParamsNeedSet,
+			Args:    []interface{}{"data"},
+		}
+	}
+
+	return validateNsResourceCreate(i, *i, IngressFields)
+}
+
+// ValidateUpdate validate update ingress
+func (i *Ingress) ValidateUpdate() ccErr.RawErrorInfo {
+	if i == nil {
+		return ccErr.RawErrorInfo{
+			ErrCode: common.CCErrCommParamsNeedSet,
+			Args:    []interface{}{"data"},
+		}
+	}
+
+	return ValidateUpdate(*i, IngressFields)
+}
+
+// BuildUpdateData build ingress update data
+func (i *Ingress) BuildUpdateData(user string) (map[string]interface{}, error) {
+	if i == nil {
+		return nil, errors.New("update param is invalid")
+	}
+
+	return buildNsResourceUpdateData(i, user)
+}
+
+// GetServiceNames get the names of the services that the ingress routes traffic to
+func (i *Ingress) GetServiceNames() []string {
+	names := make([]string, 0)
+	exists := make(map[string]struct{})
+	addName := func(backend IngressBackend) {
+		if backend.ServiceName == "" {
+			return
+		}
+		if _, ok := exists[backend.ServiceName]; ok {
+			return
+		}
+		exists[backend.ServiceName] = struct{}{}
+		names = append(names, backend.ServiceName)
+	}
+
+	if i.DefaultBackend != nil {
+		addName(*i.DefaultBackend)
+	}
+
+	if i.Rules != nil {
+		for _, rule := range *i.Rules {
+			for _, path := range rule.Paths {
+				addName(path.Backend)
+			}
+		}
+	}
+
+	return names
+}
+
+// GetServiceHosts get the hosts that route traffic to the service, the rule without host is ignored, and the hosts
+// of all the rules are returned if the service is the default backend.
+func (i *Ingress) GetServiceHosts(serviceName string) []string {
+	hosts := make([]string, 0)
+	if i.Rules == nil {
+		return hosts
+	}
+
+	isDefault := i.DefaultBackend != nil && i.DefaultBackend.ServiceName == serviceName
+	for _, rule := range *i.Rules {
+		if rule.Host == "" {
+			continue
+		}
+
+		if isDefault {
+			hosts = append(hosts, rule.Host)
+			continue
+		}
+
+		for _, path := range rule.Paths {
+			if path.Backend.ServiceName == serviceName {
+				hosts = append(hosts, rule.Host)
+				break
+			}
+		}
+	}
+
+	return hosts
+}
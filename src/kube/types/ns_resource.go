@@ -0,0 +1,520 @@
+/*
+ * Tencent is pleased to support the open source community by making
+ * 蓝鲸智云 - 配置平台 (BlueKing - Configuration System) available.
+ * Copyright (C) 2017 THL A29 Limited,
+ * a Tencent company. All rights reserved.
+ * Licensed under the MIT License (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at http://opensource.org/licenses/MIT
+ * Unless required by applicable law or agreed to in writing,
+ * software distributed under the License is distributed on
+ * an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND,
+ * either express or implied. See the License for the
+ * specific language governing permissions and limitations under the License.
+ * We undertake not to change the open source license (MIT license) applicable
+ * to the current version of the project delivered to anyone in the future.
+ */
+
+package types
+
+import (
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"time"
+
+	"configcenter/pkg/filter"
+	"configcenter/src/common"
+	"configcenter/src/common/errors"
+	"configcenter/src/common/metadata"
+	"configcenter/src/kube/orm"
+	"configcenter/src/storage/dal/table"
+
+	"github.com/tidwall/gjson"
+)
+
+// NsResourceType namespace scoped resource type enum, these resources are not workloads, they describe how the
+// workloads and pods in the namespace are accessed and configured.
+type NsResourceType string
+
+const (
+	// KubeService k8s service type
+	KubeService NsResourceType = "service"
+
+	// KubeIngress k8s ingress type
+	KubeIngress NsResourceType = "ingress"
+
+	// KubeEndpoints k8s endpoints type
+	KubeEndpoints NsResourceType = "endpoints"
+
+	// KubeConfigMap k8s configMap type
+	KubeConfigMap NsResourceType = "configMap"
+)
+
+// Validate validate NsResourceType
+func (t NsResourceType) Validate() error {
+	switch t {
+	case KubeService, KubeIngress, KubeEndpoints, KubeConfigMap:
+		return nil
+	default:
+		return fmt.Errorf("can not support this type of namespace resource, kind: %s", t)
+	}
+}
+
+// Table get the table name based on the namespace resource type
+func (t NsResourceType) Table() (string, error) {
+	switch t {
+	case KubeService:
+		return BKTableNameBaseService, nil
+
+	case KubeIngress:
+		return BKTableNameBaseIngress, nil
+
+	case KubeEndpoints:
+		return BKTableNameBaseEndpoints, nil
+
+	case KubeConfigMap:
+		return BKTableNameBaseConfigMap, nil
+
+	default:
+		return "", fmt.Errorf("can not find table name, kind: %s", t)
+	}
+}
+
+// Fields get the namespace resource type related table fields
+func (t NsResourceType) Fields() (*table.Fields, error) {
+	switch t {
+	case KubeService:
+		return ServiceFields, nil
+
+	case KubeIngress:
+		return IngressFields, nil
+
+	case KubeEndpoints:
+		return EndpointsFields, nil
+
+	case KubeConfigMap:
+		return ConfigMapFields, nil
+
+	default:
+		return nil, fmt.Errorf("namespace resource type %s is not supported", t)
+	}
+}
+
+// NewInst new a namespace resource instance according to namespace resource type
+func (t NsResourceType) NewInst() (NsResourceInterface, error) {
+	switch t {
+	case KubeService:
+		return new(Service), nil
+
+	case KubeIngress:
+		return new(Ingress), nil
+
+	case KubeEndpoints:
+		return new(Endpoints), nil
+
+	case KubeConfigMap:
+		return new(ConfigMap), nil
+
+	default:
+		return nil, fmt.Errorf("namespace resource type %s is not supported", t)
+	}
+}
+
+// GetNsResourceTables get the table names of all the namespace resources.
+func GetNsResourceTables() []string {
+	return []string{
+		BKTableNameBaseService,
+		BKTableNameBaseIngress,
+		BKTableNameBaseEndpoints,
+		BKTableNameBaseConfigMap,
+	}
+}
+
+const (
+	// NsResUpdateLimit limit on the number of namespace resource updates
+	NsResUpdateLimit = 200
+	// NsResDeleteLimit limit on the number of namespace resource delete
+	NsResDeleteLimit = 200
+	// NsResCreateLimit limit on the number of namespace resource create
+	NsResCreateLimit = 200
+	// NsResQueryLimit limit on the number of namespace resource query
+	NsResQueryLimit = 500
+)
+
+// NsResourceInterface defines the namespace resource data common operation.
+type NsResourceInterface interface {
+	ValidateCreate() errors.RawErrorInfo
+	ValidateUpdate() errors.RawErrorInfo
+	GetNsResourceBase() NsResourceBase
+	SetNsResourceBase(res NsResourceBase)
+	BuildUpdateData(user string) (map[string]interface{}, error)
+}
+
+// NsResourceBase define the namespace resource common struct, k8s resource attributes are placed in their
+// respective structures.
+type NsResourceBase struct {
+	NamespaceSpec   `json:",inline" bson:",inline"`
+	ID              int64  `json:"id,omitempty" bson:"id"`
+	Name            string `json:"name,omitempty" bson:"name"`
+	SupplierAccount string `json:"bk_supplier_account,omitempty" bson:"bk_supplier_account"`
+	// Revision record this app's revision information
+	table.Revision `json:",inline" bson:",inline"`
+}
+
+// validateNsResourceCreate validate the common part of the namespace resource to be created
+func validateNsResourceCreate(res NsResourceInterface, data interface{}, fields *table.Fields) errors.RawErrorInfo {
+	base := res.GetNsResourceBase()
+	if base.BizID == 0 {
+		return errors.RawErrorInfo{
+			ErrCode: common.CCErrCommParamsNeedSet,
+			Args:    []interface{}{common.BKAppIDField},
+		}
+	}
+
+	if base.NamespaceID == 0 {
+		return errors.RawErrorInfo{
+			ErrCode: common.CCErrCommParamsNeedSet,
+			Args:    []interface{}{BKNamespaceIDField},
+		}
+	}
+
+	if base.Name == "" {
+		return errors.RawErrorInfo{
+			ErrCode: common.CCErrCommParamsNeedSet,
+			Args:    []interface{}{common.BKFieldName},
+		}
+	}
+
+	return ValidateCreate(data, fields)
+}
+
+var nsResIgnoreField = []string{
+	common.BKAppIDField, BKClusterIDFiled, ClusterUIDField, BKNamespaceIDField, NamespaceField, common.BKFieldName,
+	common.BKFieldID, common.CreateTimeField,
+}
+
+// buildNsResourceUpdateData build namespace resource update data
+func buildNsResourceUpdateData(data interface{}, user string) (map[string]interface{}, error) {
+	opts := orm.NewFieldOptions().AddIgnoredFields(nsResIgnoreField...)
+	updateData, err := orm.GetUpdateFieldsWithOption(data, opts)
+	if err != nil {
+		return nil, err
+	}
+	updateData[common.LastTimeField] = time.Now().Unix()
+	updateData[common.ModifierField] = user
+	return updateData, nil
+}
+
+type jsonNsResData struct {
+	BizID int64           `json:"bk_biz_id"`
+	IDs   []int64         `json:"ids"`
+	Data  json.RawMessage `json:"data"`
+}
+
+// NsResArrayUnmarshalJSON unmarshal namespace resource array json
+func NsResArrayUnmarshalJSON(kind NsResourceType, js []byte) ([]NsResourceInterface, error) {
+	newInst, err := kind.NewInst()
+	if err != nil {
+		return nil, err
+	}
+
+	info := reflect.New(reflect.SliceOf(reflect.ValueOf(newInst).Type())).Elem().Addr().Interface()
+	if err = json.Unmarshal(js, info); err != nil {
+		return nil, err
+	}
+
+	infoArr := reflect.ValueOf(info).Elem()
+	infoArrLen := infoArr.Len()
+
+	resources := make([]NsResourceInterface, infoArrLen)
+	for i := 0; i < infoArrLen; i++ {
+		resources[i] = infoArr.Index(i).Interface().(NsResourceInterface)
+	}
+
+	return resources, nil
+}
+
+// NsResCreateOption create namespace resource request
+type NsResCreateOption struct {
+	BizID int64                 `json:"bk_biz_id"`
+	Kind  NsResourceType        `json:"kind"`
+	Data  []NsResourceInterface `json:"data"`
+}
+
+// UnmarshalJSON unmarshal NsResCreateOption
+func (n *NsResCreateOption) UnmarshalJSON(data []byte) error {
+	req := new(jsonNsResData)
+	if err := json.Unmarshal(data, req); err != nil {
+		return err
+	}
+
+	n.BizID = req.BizID
+
+	if len(req.Data) == 0 {
+		return nil
+	}
+
+	if err := n.Kind.Validate(); err != nil {
+		return err
+	}
+
+	createData, err := NsResArrayUnmarshalJSON(n.Kind, req.Data)
+	if err != nil {
+		return err
+	}
+
+	n.Data = createData
+	return nil
+}
+
+// Validate validate NsResCreateOption
+func (n *NsResCreateOption) Validate() errors.RawErrorInfo {
+	if n.BizID == 0 {
+		return errors.RawErrorInfo{
+			ErrCode: common.CCErrCommParamsNeedSet,
+			Args:    []interface{}{common.BKAppIDField},
+		}
+	}
+
+	if len(n.Data) == 0 {
+		return errors.RawErrorInfo{
+			ErrCode: common.CCErrCommParamsNeedSet,
+			Args:    []interface{}{"data"},
+		}
+	}
+
+	if len(n.Data) > NsResCreateLimit {
+		return errors.RawErrorInfo{
+			ErrCode: common.CCErrCommXXExceedLimit,
+			Args:    []interface{}{"data", NsResCreateLimit},
+		}
+	}
+
+	for i := range n.Data {
+		base := n.Data[i].GetNsResourceBase()
+		base.BizID = n.BizID
+		n.Data[i].SetNsResourceBase(base)
+		if err := n.Data[i].ValidateCreate(); err.ErrCode != 0 {
+			return err
+		}
+	}
+
+	return errors.RawErrorInfo{}
+}
+
+// NsResCreateResp create namespace resource response
+type NsResCreateResp struct {
+	metadata.BaseResp `json:",inline"`
+	Data              metadata.RspIDs `json:"data"`
+}
+
+// NsResUpdateOption update namespace resource request
+type NsResUpdateOption struct {
+	BizID int64 `json:"bk_biz_id"`
+	NsResUpdateByIDsOption
+}
+
+// Validate validate NsResUpdateOption
+func (n *NsResUpdateOption) Validate() errors.RawErrorInfo {
+	if n.BizID == 0 {
+		return errors.RawErrorInfo{
+			ErrCode: common.CCErrCommParamsNeedSet,
+			Args:    []interface{}{common.BKAppIDField},
+		}
+	}
+
+	return n.NsResUpdateByIDsOption.Validate()
+}
+
+// UnmarshalJSON unmarshal NsResUpdateOption
+func (n *NsResUpdateOption) UnmarshalJSON(data []byte) error {
+	n.BizID = gjson.GetBytes(data, common.BKAppIDField).Int()
+	return json.Unmarshal(data, &n.NsResUpdateByIDsOption)
+}
+
+// NsResUpdateByIDsOption update namespace resource by ids request
+type NsResUpdateByIDsOption struct {
+	Kind NsResourceType      `json:"kind"`
+	IDs  []int64             `json:"ids"`
+	Data NsResourceInterface `json:"data"`
+}
+
+// Validate validate NsResUpdateByIDsOption
+func (n *NsResUpdateByIDsOption) Validate() errors.RawErrorInfo {
+	if len(n.IDs) == 0 {
+		return errors.RawErrorInfo{
+			ErrCode: common.CCErrCommParamsNeedSet,
+			Args:    []interface{}{"ids"},
+		}
+	}
+
+	if len(n.IDs) > NsResUpdateLimit {
+		return errors.RawErrorInfo{
+			ErrCode: common.CCErrCommXXExceedLimit,
+			Args:    []interface{}{"ids", NsResUpdateLimit},
+		}
+	}
+
+	if n.Data == nil {
+		return errors.RawErrorInfo{
+			ErrCode: common.CCErrCommParamsNeedSet,
+			Args:    []interface{}{"data"},
+		}
+	}
+
+	return n.Data.ValidateUpdate()
+}
+
+// UnmarshalJSON unmarshal NsResUpdateByIDsOption
+func (n *NsResUpdateByIDsOption) UnmarshalJSON(data []byte) error {
+	if err := n.Kind.Validate(); err != nil {
+		return err
+	}
+
+	req := new(jsonNsResData)
+	if err := json.Unmarshal(data, req); err != nil {
+		return err
+	}
+	n.IDs = req.IDs
+
+	if len(req.Data) == 0 {
+		return nil
+	}
+
+	inst, err := n.Kind.NewInst()
+	if err != nil {
+		return err
+	}
+	if err = json.Unmarshal(req.Data, inst); err != nil {
+		return err
+	}
+	n.Data = inst
+
+	return nil
+}
+
+// NsResDeleteOption delete namespace resource request
+type NsResDeleteOption struct {
+	BizID int64 `json:"bk_biz_id"`
+	NsResDeleteByIDsOption
+}
+
+// Validate validate NsResDeleteOption
+func (n *NsResDeleteOption) Validate() errors.RawErrorInfo {
+	if n.BizID == 0 {
+		return errors.RawErrorInfo{
+			ErrCode: common.CCErrCommParamsNeedSet,
+			Args:    []interface{}{common.BKAppIDField},
+		}
+	}
+
+	return n.NsResDeleteByIDsOption.Validate()
+}
+
+// NsResDeleteByIDsOption delete namespace resource by ids request
+type NsResDeleteByIDsOption struct {
+	IDs []int64 `json:"ids"`
+}
+
+// Validate validate NsResDeleteByIDsOption
+func (n *NsResDeleteByIDsOption) Validate() errors.RawErrorInfo {
+	if len(n.IDs) == 0 {
+		return errors.RawErrorInfo{
+			ErrCode: common.CCErrCommParamsNeedSet,
+			Args:    []interface{}{"ids"},
+		}
+	}
+
+	if len(n.IDs) > NsResDeleteLimit {
+		return errors.RawErrorInfo{
+			ErrCode: common.CCErrCommXXExceedLimit,
+			Args:    []interface{}{"ids", NsResDeleteLimit},
+		}
+	}
+
+	return errors.RawErrorInfo{}
+}
+
+// NsResQueryOption namespace resource query request
+type NsResQueryOption struct {
+	BizID  int64              `json:"bk_biz_id"`
+	Filter *filter.Expression `json:"filter"`
+	Fields []string           `json:"fields,omitempty"`
+	Page   metadata.BasePage  `json:"page,omitempty"`
+}
+
+// Validate validate NsResQueryOption
+func (n *NsResQueryOption) Validate(kind NsResourceType) errors.RawErrorInfo {
+	if n.BizID == 0 {
+		return errors.RawErrorInfo{
+			ErrCode: common.CCErrCommParamsNeedSet,
+			Args:    []interface{}{common.BKAppIDField},
+		}
+	}
+
+	if err := n.Page.ValidateWithEnableCount(false, NsResQueryLimit); err.ErrCode != 0 {
+		return err
+	}
+
+	fields, err := kind.Fields()
+	if err != nil {
+		return errors.RawErrorInfo{
+			ErrCode: common.CCErrCommParamsIsInvalid,
+			Args:    []interface{}{KindField},
+		}
+	}
+
+	if n.Filter == nil {
+		return errors.RawErrorInfo{}
+	}
+
+	op := filter.NewDefaultExprOpt(fields.FieldsType())
+	if err := n.Filter.Validate(op); err != nil {
+		return errors.RawErrorInfo{
+			ErrCode: common.CCErrCommParamsInvalid,
+			Args:    []interface{}{err.Error()},
+		}
+	}
+	return errors.RawErrorInfo{}
+}
+
+// NsResDataResp namespace resource data
+type NsResDataResp struct {
+	Kind NsResourceType        `json:"kind"`
+	Info []NsResourceInterface `json:"info"`
+}
+
+type jsonNsResDataResp struct {
+	Info json.RawMessage `json:"info"`
+}
+
+// UnmarshalJSON unmarshal NsResDataResp
+func (n *NsResDataResp) UnmarshalJSON(data []byte) error {
+	if err := n.Kind.Validate(); err != nil {
+		return err
+	}
+
+	resp := new(jsonNsResDataResp)
+	if err := json.Unmarshal(data, resp); err != nil {
+		return err
+	}
+
+	if len(resp.Info) == 0 {
+		return nil
+	}
+
+	info, err := NsResArrayUnmarshalJSON(n.Kind, resp.Info)
+	if err != nil {
+		return err
+	}
+	n.Info = info
+
+	return nil
+}
+
+// NsResInstResp namespace resource instance response
+type NsResInstResp struct {
+	metadata.BaseResp `json:",inline"`
+	Data              NsResDataResp `json:"data"`
+}
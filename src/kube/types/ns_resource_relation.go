@@ -0,0 +1,97 @@
+/*
+ * Tencent is pleased to support the open source community by making
+ * 蓝鲸智云 - 配置平台 (BlueKing - Configuration System) available.
+ * Copyright (C) 2017 THL A29 Limited,
+ * a Tencent company. All rights reserved.
+ * Licensed under the MIT License (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at http://opensource.org/licenses/MIT
+ * Unless required by applicable law or agreed to in writing,
+ * software distributed under the License is distributed on
+ * an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND,
+ * either express or implied. See the License for the
+ * specific language governing permissions and limitations under the License.
+ * We undertake not to change the open source license (MIT license) applicable
+ * to the current version of the project delivered to anyone in the future.
+ */
+
+package types
+
+import (
+	"configcenter/src/common"
+	"configcenter/src/common/errors"
+	"configcenter/src/common/metadata"
+)
+
+// KubeRelationLimit limit on the number of resources to find relations for
+const KubeRelationLimit = 100
+
+// IsKubeRelationKind check if the kind supports finding relations, including the namespace scoped resources,
+// the workloads and the pod.
+func IsKubeRelationKind(kind string) bool {
+	if kind == KubePod {
+		return true
+	}
+
+	if err := NsResourceType(kind).Validate(); err == nil {
+		return true
+	}
+
+	return WorkloadType(kind).Validate() == nil
+}
+
+// KubeRelationOption find the related kube resources of the specified resources
+type KubeRelationOption struct {
+	BizID int64   `json:"bk_biz_id"`
+	IDs   []int64 `json:"ids"`
+}
+
+// Validate validate KubeRelationOption
+func (k *KubeRelationOption) Validate() errors.RawErrorInfo {
+	if k.BizID == 0 {
+		return errors.RawErrorInfo{
+			ErrCode: common.CCErrCommParamsNeedSet,
+			Args:    []interface{}{common.BKAppIDField},
+		}
+	}
+
+	if len(k.IDs) == 0 {
+		return errors.RawErrorInfo{
+			ErrCode: common.CCErrCommParamsNeedSet,
+			Args:    []interface{}{"ids"},
+		}
+	}
+
+	if len(k.IDs) > KubeRelationLimit {
+		return errors.RawErrorInfo{
+			ErrCode: common.CCErrCommXXExceedLimit,
+			Args:    []interface{}{"ids", KubeRelationLimit},
+		}
+	}
+
+	return errors.RawErrorInfo{}
+}
+
+// KubeRelation the related kube resources of a resource in the same namespace. services are associated with pods by
+// their label selectors, workloads are associated with services by their pods, ingresses are associated with
+// services by their backends, endpoints are associated with the service with the same name and with pods by their
+// target references, config maps are associated with the pods that mount them.
+type KubeRelation struct {
+	Kind       string           `json:"kind"`
+	ID         int64            `json:"id"`
+	Name       string           `json:"name"`
+	Services   []KubeObjectInfo `json:"services"`
+	Ingresses  []KubeObjectInfo `json:"ingresses"`
+	Endpoints  []KubeObjectInfo `json:"endpoints"`
+	ConfigMaps []KubeObjectInfo `json:"config_maps"`
+	Workloads  []KubeObjectInfo `json:"workloads"`
+	Pods       []KubeObjectInfo `json:"pods"`
+	// Hosts the ingress hosts that route traffic to the resource
+	Hosts []string `json:"hosts"`
+}
+
+// KubeRelationResp find kube resource relations response
+type KubeRelationResp struct {
+	metadata.BaseResp `json:",inline"`
+	Data              []KubeRelation `json:"data"`
+}
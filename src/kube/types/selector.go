@@ -0,0 +1,77 @@
+/*
+ * Tencent is pleased to support the open source community by making
+ * 蓝鲸智云 - 配置平台 (BlueKing - Configuration System) available.
+ * Copyright (C) 2017 THL A29 Limited,
+ * a Tencent company. All rights reserved.
+ * Licensed under the MIT License (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at http://opensource.org/licenses/MIT
+ * Unless required by applicable law or agreed to in writing,
+ * software distributed under the License is distributed on
+ * an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND,
+ * either express or implied. See the License for the
+ * specific language governing permissions and limitations under the License.
+ * We undertake not to change the open source license (MIT license) applicable
+ * to the current version of the project delivered to anyone in the future.
+ */
+
+package types
+
+// IsEmpty check if the label selector has no requirement, an empty label selector matches all objects.
+func (l *LabelSelector) IsEmpty() bool {
+	if l == nil {
+		return true
+	}
+
+	return len(l.MatchLabels) == 0 && len(l.MatchExpressions) == 0
+}
+
+// Matches check if the labels match the label selector, the results of match_labels and match_expressions are
+// ANDed. an empty label selector matches all objects, and a null label selector matches no objects.
+func (l *LabelSelector) Matches(labels map[string]string) bool {
+	if l == nil {
+		return false
+	}
+
+	for key, value := range l.MatchLabels {
+		labelValue, exists := labels[key]
+		if !exists || labelValue != value {
+			return false
+		}
+	}
+
+	for _, requirement := range l.MatchExpressions {
+		if !requirement.Matches(labels) {
+			return false
+		}
+	}
+
+	return true
+}
+
+// Matches check if the labels match the label selector requirement, unknown operator matches no objects.
+func (r LabelSelectorRequirement) Matches(labels map[string]string) bool {
+	value, exists := labels[r.Key]
+
+	switch r.Operator {
+	case LabelSelectorOpIn:
+		return exists && r.hasValue(value)
+	case LabelSelectorOpNotIn:
+		return !exists || !r.hasValue(value)
+	case LabelSelectorOpExists:
+		return exists
+	case LabelSelectorOpDoesNotExist:
+		return !exists
+	default:
+		return false
+	}
+}
+
+func (r LabelSelectorRequirement) hasValue(value string) bool {
+	for _, v := range r.Values {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,74 @@
+/*
+ * Tencent is pleased to support the open source community by making
+ * 蓝鲸智云 - 配置平台 (BlueKing - Configuration System) available.
+ * Copyright (C) 2017 THL A29 Limited,
+ * a Tencent company. All rights reserved.
+ * Licensed under the MIT License (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at http://opensource.org/licenses/MIT
+ * Unless required by applicable law or agreed to in writing,
+ * software distributed under the License is distributed on
+ * an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND,
+ * either express or implied. See the License for the
+ * specific language governing permissions and limitations under the License.
+ * We undertake not to change the open source license (MIT license) applicable
+ * to the current version of the project delivered to anyone in the future.
+ */
+
+package types
+
+import "testing"
+
+// TestLabelSelectorMatches unit test for label selector evaluation
+func TestLabelSelectorMatches(t *testing.T) {
+	labels := map[string]string{"app": "web", "tier": "frontend"}
+
+	cases := []struct {
+		name     string
+		selector *LabelSelector
+		expected bool
+	}{
+		{name: "null selector", selector: nil, expected: false},
+		{name: "empty selector", selector: &LabelSelector{}, expected: true},
+		{name: "match labels", selector: &LabelSelector{MatchLabels: map[string]string{"app": "web"}},
+			expected: true},
+		{name: "match labels mismatch", selector: &LabelSelector{MatchLabels: map[string]string{"app": "db"}},
+			expected: false},
+		{name: "match labels absent", selector: &LabelSelector{MatchLabels: map[string]string{"env": "prod"}},
+			expected: false},
+		{name: "in", selector: &LabelSelector{MatchExpressions: []LabelSelectorRequirement{
+			{Key: "tier", Operator: LabelSelectorOpIn, Values: []string{"frontend", "backend"}}}}, expected: true},
+		{name: "in absent key", selector: &LabelSelector{MatchExpressions: []LabelSelectorRequirement{
+			{Key: "env", Operator: LabelSelectorOpIn, Values: []string{"prod"}}}}, expected: false},
+		{name: "not in", selector: &LabelSelector{MatchExpressions: []LabelSelectorRequirement{
+			{Key: "tier", Operator: LabelSelectorOpNotIn, Values: []string{"frontend"}}}}, expected: false},
+		{name: "not in absent key", selector: &LabelSelector{MatchExpressions: []LabelSelectorRequirement{
+			{Key: "env", Operator: LabelSelectorOpNotIn, Values: []string{"prod"}}}}, expected: true},
+		{name: "exists", selector: &LabelSelector{MatchExpressions: []LabelSelectorRequirement{
+			{Key: "app", Operator: LabelSelectorOpExists}}}, expected: true},
+		{name: "does not exist", selector: &LabelSelector{MatchExpressions: []LabelSelectorRequirement{
+			{Key: "app", Operator: LabelSelectorOpDoesNotExist}}}, expected: false},
+		{name: "unknown operator", selector: &LabelSelector{MatchExpressions: []LabelSelectorRequirement{
+			{Key: "app", Operator: "Gt", Values: []string{"1"}}}}, expected: false},
+		{name: "labels and expressions are ANDed", selector: &LabelSelector{
+			MatchLabels: map[string]string{"app": "web"},
+			MatchExpressions: []LabelSelectorRequirement{
+				{Key: "tier", Operator: LabelSelectorOpIn, Values: []string{"backend"}}}}, expected: false},
+	}
+
+	for _, c := range cases {
+		if actual := c.selector.Matches(labels); actual != c.expected {
+			t.Errorf("case %s failed, expected: %v, actual: %v", c.name, c.expected, actual)
+		}
+	}
+
+	svc := &Service{}
+	if svc.MatchPod(labels) {
+		t.Errorf("service without selector should not match any pod")
+	}
+
+	svc.Selector = &LabelSelector{MatchLabels: map[string]string{"app": "web"}}
+	if !svc.MatchPod(labels) {
+		t.Errorf("service selector should match the pod labels")
+	}
+}
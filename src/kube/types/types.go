@@ -48,6 +48,9 @@ const (
 
 	// KubeContainer k8s container type
 	KubeContainer = "container"
+
+	// KubeNsResource k8s namespace scoped resource type, including service, ingress, endpoints and configMap
+	KubeNsResource = "ns_resource"
 )
 
 // WorkloadType workload type enum
@@ -234,6 +237,18 @@ const (
 	// BKTableNameBaseContainer the table name of the Container
 	BKTableNameBaseContainer = "cc_ContainerBase"
 
+	// BKTableNameBaseService the table name of the Service
+	BKTableNameBaseService = "cc_ServiceBase"
+
+	// BKTableNameBaseIngress the table name of the Ingress
+	BKTableNameBaseIngress = "cc_IngressBase"
+
+	// BKTableNameBaseEndpoints the table name of the Endpoints
+	BKTableNameBaseEndpoints = "cc_EndpointsBase"
+
+	// BKTableNameBaseConfigMap the table name of the ConfigMap
+	BKTableNameBaseConfigMap = "cc_ConfigMapBase"
+
 	// BKTableNameNsSharedClusterRel the table name of shared cluster and biz relation by namespace dimension
 	BKTableNameNsSharedClusterRel = "cc_NsSharedClusterRelation"
 )
@@ -455,6 +470,36 @@ const (
 	KubeFolderNameEn = "Empty Pod Node(s)"
 )
 
+// namespace scoped resource field names
+const (
+	// ClusterIPsField service cluster ips field
+	ClusterIPsField = "cluster_ips"
+
+	// ExternalIPsField service external ips field
+	ExternalIPsField = "external_ips"
+
+	// IngressClassNameField ingress class name field
+	IngressClassNameField = "ingress_class_name"
+
+	// DefaultBackendField ingress default backend field
+	DefaultBackendField = "default_backend"
+
+	// RulesField ingress rules field
+	RulesField = "rules"
+
+	// TLSField ingress tls field
+	TLSField = "tls"
+
+	// SubsetsField endpoints subsets field
+	SubsetsField = "subsets"
+
+	// DataField config map data field
+	DataField = "data"
+
+	// ImmutableField config map immutable field
+	ImmutableField = "immutable"
+)
+
 // container field names
 const (
 	// ContainerUIDField container unique id field in third party platform
@@ -99,4 +99,11 @@ import (
 	_ "configcenter/src/scene_server/admin_server/upgrader/y3.12.202310301633"
 	_ "configcenter/src/scene_server/admin_server/upgrader/y3.12.202310302130"
 	_ "configcenter/src/scene_server/admin_server/upgrader/y3.12.202311061800"
+	_ "configcenter/src/scene_server/admin_server/upgrader/y3.12.202311201500"
+	_ "configcenter/src/scene_server/admin_server/upgrader/y3.12.202312011000"
+	_ "configcenter/src/scene_server/admin_server/upgrader/y3.12.202312051000"
+	_ "configcenter/src/scene_server/admin_server/upgrader/y3.12.202312111000"
+	_ "configcenter/src/scene_server/admin_server/upgrader/y3.12.202312151000"
+	_ "configcenter/src/scene_server/admin_server/upgrader/y3.12.202312201000"
+	_ "configcenter/src/scene_server/admin_server/upgrader/y3.12.202312221000"
 )
@@ -28,6 +28,10 @@ import (
 
 type metaId struct {
 	MongoID primitive.ObjectID `bson:"_id"`
+	// the audit log hash chain fields, used to record the pruned audit logs in the chain head
+	SupplierAccount string `bson:"bk_supplier_account"`
+	SeqNo           int64  `bson:"seq_no"`
+	Hash            string `bson:"hash"`
 }
 
 const (
@@ -117,7 +121,8 @@ func (s *Service) DeleteAuditLog(req *restful.Request, resp *restful.Response) {
 		metaIdList := make([]metaId, 0)
 
 		// find docs for the specified date.
-		err := s.db.Table(common.BKTableNameAuditLog).Find(cond).Fields("_id").
+		err := s.db.Table(common.BKTableNameAuditLog).Find(cond).Fields("_id", common.BKOwnerIDField,
+			common.BKAuditSeqNoField, common.BKAuditHashField).
 			Limit(uint64(maxDelBatchLimit)).All(s.ctx, &metaIdList)
 		if err != nil {
 			blog.Errorf("search auditLog failed, err: %+v, rid: %s", err, rid)
@@ -147,6 +152,11 @@ func (s *Service) DeleteAuditLog(req *restful.Request, resp *restful.Response) {
 			return
 		}
 
+		if err := s.updateAuditChainPruned(metaIdList, rid); err != nil {
+			_ = resp.WriteError(http.StatusInternalServerError, err)
+			return
+		}
+
 		cnt += len(metaIdList)
 		total += len(metaIdList)
 		if cnt >= maxDelDocPageLimit {
@@ -165,3 +175,37 @@ func (s *Service) DeleteAuditLog(req *restful.Request, resp *restful.Response) {
 	_ = resp.WriteEntity(metadata.NewSuccessResp(response))
 	return
 }
+
+// updateAuditChainPruned record the max sequence number of the deleted audit logs in the hash chain head, so that
+// the verification starts after them instead of reporting them as removed.
+func (s *Service) updateAuditChainPruned(deleted []metaId, rid string) error {
+	prunedMap := make(map[string]metaId)
+	for _, data := range deleted {
+		if data.SeqNo <= 0 {
+			continue
+		}
+
+		if pruned, exists := prunedMap[data.SupplierAccount]; !exists || data.SeqNo > pruned.SeqNo {
+			prunedMap[data.SupplierAccount] = data
+		}
+	}
+
+	for supplierAccount, pruned := range prunedMap {
+		cond := map[string]interface{}{
+			common.BKOwnerIDField: supplierAccount,
+			"pruned_seq_no":       map[string]interface{}{common.BKDBLT: pruned.SeqNo},
+		}
+		data := map[string]interface{}{
+			"pruned_seq_no": pruned.SeqNo,
+			"pruned_hash":   pruned.Hash,
+		}
+
+		if err := s.db.Table(common.BKTableNameAuditLogChain).Update(s.ctx, cond, data); err != nil {
+			blog.Errorf("update audit log chain pruned seq no to %d failed, supplier account: %s, err: %v, rid: %s",
+				pruned.SeqNo, supplierAccount, err, rid)
+			return err
+		}
+	}
+
+	return nil
+}
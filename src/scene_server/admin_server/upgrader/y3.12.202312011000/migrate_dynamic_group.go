@@ -0,0 +1,183 @@
+/*
+ * Tencent is pleased to support the open source community by making
+ * 蓝鲸智云 - 配置平台 (BlueKing - Configuration System) available.
+ * Copyright (C) 2017 THL A29 Limited,
+ * a Tencent company. All rights reserved.
+ * Licensed under the MIT License (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at http://opensource.org/licenses/MIT
+ * Unless required by applicable law or agreed to in writing,
+ * software distributed under the License is distributed on
+ * an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND,
+ * either express or implied. See the License for the
+ * specific language governing permissions and limitations under the License.
+ * We undertake not to change the open source license (MIT license) applicable
+ * to the current version of the project delivered to anyone in the future.
+ */
+
+package y3_12_202312011000
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"configcenter/pkg/filter"
+	"configcenter/src/common"
+	"configcenter/src/common/blog"
+	"configcenter/src/common/mapstr"
+	"configcenter/src/common/metadata"
+	"configcenter/src/common/util"
+	"configcenter/src/storage/dal"
+)
+
+// dynamicGroupOperatorMap is the map of old dynamic group condition operator to filter operator.
+var dynamicGroupOperatorMap = map[string]filter.OpFactory{
+	metadata.DynamicGroupOperatorEQ:   filter.OpFactory(filter.Equal),
+	metadata.DynamicGroupOperatorNE:   filter.OpFactory(filter.NotEqual),
+	metadata.DynamicGroupOperatorIN:   filter.OpFactory(filter.In),
+	metadata.DynamicGroupOperatorNIN:  filter.OpFactory(filter.NotIn),
+	metadata.DynamicGroupOperatorLTE:  filter.OpFactory(filter.LessOrEqual),
+	metadata.DynamicGroupOperatorGTE:  filter.OpFactory(filter.GreaterOrEqual),
+	metadata.DynamicGroupOperatorLIKE: filter.OpFactory(filter.ContainsSensitive),
+}
+
+// migrateDynamicGroupCondition convert dynamic group conditions to filter conditions, the dynamic groups whose
+// conditions can not be converted keeps the old conditions, they can still be executed in the old way. only the
+// filter conditions are set, the old conditions are kept so that the groups are not lost if the upgrade is rolled
+// back, the filter conditions take precedence when a group is executed.
+func migrateDynamicGroupCondition(ctx context.Context, db dal.RDB) error {
+	cond := mapstr.MapStr{
+		"info.condition":        mapstr.MapStr{common.BKDBExists: true},
+		"info.filter_condition": mapstr.MapStr{common.BKDBExists: false},
+	}
+
+	groups := make([]metadata.DynamicGroup, 0)
+	if err := db.Table(common.BKTableNameDynamicGroup).Find(cond).All(ctx, &groups); err != nil {
+		blog.Errorf("find dynamic groups failed, err: %v, cond: %+v", err, cond)
+		return err
+	}
+
+	for _, group := range groups {
+		filterConds, err := convertDynamicGroupCondition(group.Info.Condition)
+		if err != nil {
+			blog.Warnf("dynamic group(%s) condition can not be converted, keep the old one, err: %v, info: %+v",
+				group.ID, err, group.Info)
+			continue
+		}
+
+		updateCond := mapstr.MapStr{
+			common.BKAppIDField: group.AppID,
+			common.BKFieldID:    group.ID,
+		}
+		doc := mapstr.MapStr{"info.filter_condition": filterConds}
+
+		if err := db.Table(common.BKTableNameDynamicGroup).Update(ctx, updateCond, doc); err != nil {
+			blog.Errorf("update dynamic group(%s) condition failed, err: %v", group.ID, err)
+			return err
+		}
+	}
+
+	return nil
+}
+
+func convertDynamicGroupCondition(conditions []metadata.DynamicGroupInfoCondition) (
+	[]metadata.DynamicGroupFilterCondition, error) {
+
+	filterConds := make([]metadata.DynamicGroupFilterCondition, 0)
+	for _, condition := range conditions {
+		rules := make([]filter.RuleFactory, 0)
+		for _, item := range condition.Condition {
+			rule, err := convertDynamicGroupConditionItem(item)
+			if err != nil {
+				return nil, err
+			}
+			rules = append(rules, rule)
+		}
+
+		timeRules, err := convertDynamicGroupTimeCondition(condition.TimeCondition)
+		if err != nil {
+			return nil, err
+		}
+		rules = append(rules, timeRules...)
+
+		// empty condition has no effect on the dynamic group
+		if len(rules) == 0 {
+			continue
+		}
+
+		filterConds = append(filterConds, metadata.DynamicGroupFilterCondition{
+			ObjID:  condition.ObjID,
+			Filter: &filter.Expression{RuleFactory: &filter.CombinedRule{Condition: filter.And, Rules: rules}},
+		})
+	}
+
+	if len(filterConds) == 0 {
+		return nil, errors.New("dynamic group has no valid condition")
+	}
+
+	return filterConds, nil
+}
+
+func convertDynamicGroupConditionItem(item metadata.DynamicGroupCondition) (filter.RuleFactory, error) {
+	operator, exists := dynamicGroupOperatorMap[item.Operator]
+	if !exists {
+		return nil, fmt.Errorf("operator %s is not supported", item.Operator)
+	}
+
+	value := item.Value
+	switch item.Operator {
+	case metadata.DynamicGroupOperatorIN, metadata.DynamicGroupOperatorNIN:
+		if value == nil {
+			value = make([]interface{}, 0)
+		}
+	case metadata.DynamicGroupOperatorLTE, metadata.DynamicGroupOperatorGTE:
+		// time string value is compared as time, the same as the old way of executing dynamic group
+		if _, isTime := util.IsTime(value); isTime {
+			operator = filter.OpFactory(filter.DatetimeLessOrEqual)
+			if item.Operator == metadata.DynamicGroupOperatorGTE {
+				operator = filter.OpFactory(filter.DatetimeGreaterOrEqual)
+			}
+		}
+	}
+
+	if value == nil {
+		return nil, fmt.Errorf("value of field %s is not set", item.Field)
+	}
+
+	return &filter.AtomRule{Field: item.Field, Operator: operator, Value: value}, nil
+}
+
+func convertDynamicGroupTimeCondition(timeCond *metadata.TimeCondition) ([]filter.RuleFactory, error) {
+	if timeCond == nil {
+		return make([]filter.RuleFactory, 0), nil
+	}
+
+	rules := make([]filter.RuleFactory, 0)
+	for _, item := range timeCond.Rules {
+		field := item.Field
+		switch field {
+		case common.BKCreatedAt:
+			field = common.CreateTimeField
+		case common.BKUpdatedAt:
+			field = common.LastTimeField
+		}
+
+		if item.Start == nil && item.End == nil {
+			return nil, fmt.Errorf("time condition of field %s has no start and end", item.Field)
+		}
+
+		// use timestamp value so that the time is not affected by the time zone
+		if item.Start != nil {
+			rules = append(rules, &filter.AtomRule{Field: field,
+				Operator: filter.OpFactory(filter.DatetimeGreaterOrEqual), Value: item.Start.Unix()})
+		}
+
+		if item.End != nil {
+			rules = append(rules, &filter.AtomRule{Field: field,
+				Operator: filter.OpFactory(filter.DatetimeLessOrEqual), Value: item.End.Unix()})
+		}
+	}
+
+	return rules, nil
+}
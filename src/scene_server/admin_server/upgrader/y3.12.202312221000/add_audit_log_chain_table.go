@@ -0,0 +1,47 @@
+/*
+ * Tencent is pleased to support the open source community by making
+ * 蓝鲸智云 - 配置平台 (BlueKing - Configuration System) available.
+ * Copyright (C) 2017 THL A29 Limited,
+ * a Tencent company. All rights reserved.
+ * Licensed under the MIT License (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at http://opensource.org/licenses/MIT
+ * Unless required by applicable law or agreed to in writing,
+ * software distributed under the License is distributed on
+ * an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND,
+ * either express or implied. See the License for the
+ * specific language governing permissions and limitations under the License.
+ * We undertake not to change the open source license (MIT license) applicable
+ * to the current version of the project delivered to anyone in the future.
+ */
+
+package y3_12_202312221000
+
+import (
+	"context"
+
+	"configcenter/src/common"
+	"configcenter/src/common/blog"
+	"configcenter/src/storage/dal"
+)
+
+// addAuditLogChainTable create the audit log hash chain head table, its indexes are synced by the index logics.
+func addAuditLogChainTable(ctx context.Context, db dal.RDB) error {
+	exists, err := db.HasTable(ctx, common.BKTableNameAuditLogChain)
+	if err != nil {
+		blog.Errorf("check if table %s exists failed, err: %v", common.BKTableNameAuditLogChain, err)
+		return err
+	}
+
+	if exists {
+		return nil
+	}
+
+	err = db.CreateTable(ctx, common.BKTableNameAuditLogChain)
+	if err != nil && !db.IsDuplicatedError(err) {
+		blog.Errorf("create table %s failed, err: %v", common.BKTableNameAuditLogChain, err)
+		return err
+	}
+
+	return nil
+}
@@ -16,6 +16,7 @@ package options
 import (
 	"configcenter/src/common/auth"
 	"configcenter/src/common/core/cc/config"
+	"configcenter/src/scene_server/cloud_server/kubesync"
 
 	"github.com/spf13/pflag"
 )
@@ -54,4 +55,6 @@ type Config struct {
 	SecretsEnv     string
 	// sync period of cloud sync task, unit is second
 	SyncPeriodMinutes int
+	// KubeSync config of synchronizing kubernetes clusters into cmdb kube resources
+	KubeSync kubesync.Conf
 }
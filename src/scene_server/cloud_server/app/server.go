@@ -29,6 +29,8 @@ import (
 	"configcenter/src/common/util"
 	"configcenter/src/scene_server/cloud_server/app/options"
 	"configcenter/src/scene_server/cloud_server/cloudsync"
+	"configcenter/src/scene_server/cloud_server/cloudvendor"
+	"configcenter/src/scene_server/cloud_server/kubesync"
 	"configcenter/src/scene_server/cloud_server/logics"
 	svc "configcenter/src/scene_server/cloud_server/service"
 	"configcenter/src/thirdparty/secrets"
@@ -113,6 +115,10 @@ func Run(ctx context.Context, cancel context.CancelFunc, op *options.ServerOptio
 		return fmt.Errorf("ProcessTask failed: %v", err)
 	}
 
+	// the kubernetes sync config is not reloaded, changes of it take effect after restart
+	kubeSyncConf := process.Config.KubeSync
+	go kubesync.KubeSync(ctx, engine, &kubeSyncConf)
+
 	err = backbone.StartServer(ctx, cancel, engine, service.WebService(), true)
 	if err != nil {
 		return err
@@ -144,6 +150,18 @@ func (c *CloudServer) onCloudConfigUpdate(previous, current cc.ProcessConfig) {
 	c.Config.SecretsProject, _ = cc.String("cloudServer.cryptor.secretsProject")
 	c.Config.SecretsEnv, _ = cc.String("cloudServer.cryptor.secretsEnv")
 	c.Config.SyncPeriodMinutes, _ = cc.Int("cloudServer.syncTask.syncPeriodMinutes")
+
+	kubeSyncConf := kubesync.Conf{}
+	if err := cc.UnmarshalKey("cloudServer.kubeSync", &kubeSyncConf); err != nil {
+		blog.Warnf("parse cloudServer.kubeSync config failed, kubernetes sync is disabled, err: %v", err)
+	}
+	c.Config.KubeSync = kubeSyncConf
+
+	genericConf := cloudvendor.GenericConf{}
+	if err := cc.UnmarshalKey("cloudServer.generic", &genericConf); err != nil {
+		blog.Warnf("parse cloudServer.generic config failed, generic vendor inventory can not be read, err: %v", err)
+	}
+	cloudvendor.SetGenericConf(genericConf)
 }
 
 // getSecretKey get the secret key from bk-secrets service
@@ -33,33 +33,24 @@ func NewHostSyncor(logics *logics.Logics) *HostSyncor {
 	}
 }
 
-func (h *HostSyncor) syncCloudHost(syncResult *metadata.SyncResult, hostResource *metadata.CloudHostResource,
-	taskID int64, accountConf *metadata.CloudAccountConf, startTime time.Time) error {
+// syncCloudHost 将同步预览中的差异同步到主机上
+func (h *HostSyncor) syncCloudHost(syncResult *metadata.SyncResult, preview *metadata.CloudSyncPreview,
+	startTime time.Time) error {
 
+	taskID := preview.TaskID
 	// 让writeKit的header含有同样的事务信息，以保证同一个事务里写操作后的数据能够被读到
 	ccom.CopyHeaderTxnInfo(h.readKit.Header, h.writeKit.Header)
-	if len(hostResource.DestroyedVpcs) > 0 {
+	if len(preview.DestroyedVpcs) > 0 {
 		// 同步被销毁的VPC相关资源
-		err := h.syncDestroyedVpcs(hostResource, syncResult)
+		err := h.syncDestroyedVpcs(taskID, preview.DestroyedVpcs, syncResult)
 		if err != nil {
 			blog.Errorf("syncDestroyedVpcs fail, taskID: %d, err: %v, rid: %s", taskID, err, h.readKit.Rid)
 			return err
 		}
 	}
 
-	// 查询vpc对应的云区域并更新云主机资源信息里的云区域id
-	err := h.addCLoudId(accountConf, hostResource)
-	if err != nil {
-		blog.Errorf("addCLoudId fail, taskID: %d, err: %v, rid: %s", taskID, err, h.readKit.Rid)
-		return err
-	}
-
-	// 根据主机实例id获取mongo中的主机信息,并获取有差异的主机
-	diffHosts, err := h.getDiffHosts(hostResource)
-	if err != nil {
-		blog.Errorf("getDiffHosts fail, taskID: %d, err: %v, rid: %s", taskID, err, h.readKit.Rid)
-		return err
-	}
+	// 同步预览中有差异的主机
+	diffHosts := getPreviewDiffHosts(preview)
 
 	// 没差异则结束
 	if len(diffHosts) == 0 {
@@ -71,7 +62,7 @@ func (h *HostSyncor) syncCloudHost(syncResult *metadata.SyncResult, hostResource
 	}
 
 	// 有差异的更新任务同步状态为同步中
-	err = h.updateTaskState(h.writeKit, taskID, metadata.CloudSyncInProgress, nil)
+	err := h.updateTaskState(h.writeKit, taskID, metadata.CloudSyncInProgress, nil)
 	if err != nil {
 		blog.Errorf("updateTaskState fail, taskID: %d, err: %v, rid:%s", taskID, err, h.readKit.Rid)
 		return err
@@ -149,11 +140,33 @@ func (h *HostSyncor) Sync(task *metadata.CloudSyncTask) error {
 	blog.Infof("taskID: %d, destroyed vpc count: %d, other vpc count: %d, rid: %s", task.TaskID,
 		len(hostResource.DestroyedVpcs), len(hostResource.HostResource), h.readKit.Rid)
 
+	// 生成同步预览，获取本次同步有差异的主机以及将要移除的主机比例
+	preview, err := h.generatePreview(task, accountConf, hostResource)
+	if err != nil {
+		blog.Errorf("generatePreview fail, taskID: %d, err: %v, rid: %s", task.TaskID, err, h.readKit.Rid)
+		return err
+	}
+
+	// 审批同步模式只保存同步预览，审批后才会将差异同步到主机上
+	if task.SyncMode == metadata.CloudSyncModeApproval {
+		if _, err := h.savePreview(preview); err != nil {
+			blog.Errorf("savePreview fail, taskID: %d, err: %v, rid: %s", task.TaskID, err, h.readKit.Rid)
+			return err
+		}
+		blog.Infof("sync preview is generated for taskID: %d, rid: %s", task.TaskID, h.readKit.Rid)
+		return nil
+	}
+
+	// 将要移除的主机比例超过阈值时保存同步预览并中止同步，需要审批同步预览后才会将差异同步到主机上
+	if preview.ExceedThreshold {
+		return h.abortSync(preview, task.RemoveThreshold)
+	}
+
 	syncResult := new(metadata.SyncResult)
 	syncResult.FailInfo.IPError = make(map[string]string)
 
 	txnErr := h.logics.CoreAPI.CoreService().Txn().AutoRunTxn(h.readKit.Ctx, h.readKit.Header, func() error {
-		return h.syncCloudHost(syncResult, hostResource, task.TaskID, accountConf, startTime)
+		return h.syncCloudHost(syncResult, preview, startTime)
 	})
 
 	// 事务结束，去掉readKit、writeKit中header的事务信息
@@ -217,37 +230,24 @@ func (h *HostSyncor) getCloudHostResource(task *metadata.CloudSyncTask,
 }
 
 // syncDestroyedVpcs 同步被销毁的VPC相关资源
-func (h *HostSyncor) syncDestroyedVpcs(hostResource *metadata.CloudHostResource,
+func (h *HostSyncor) syncDestroyedVpcs(taskID int64, destroyedVpcs []metadata.CloudSyncDestroyedVpc,
 	syncResult *metadata.SyncResult) error {
-	if len(hostResource.DestroyedVpcs) == 0 {
+	if len(destroyedVpcs) == 0 {
 		return nil
 	}
 	cloudIDs := make([]int64, 0)
-	for _, vpcInfo := range hostResource.DestroyedVpcs {
+	hostIDs := make([]int64, 0)
+	vpcs := make(map[string]bool)
+	for _, vpcInfo := range destroyedVpcs {
 		cloudIDs = append(cloudIDs, vpcInfo.CloudID)
+		vpcs[vpcInfo.VpcID] = true
+		for _, host := range vpcInfo.Hosts {
+			hostIDs = append(hostIDs, host.HostID)
+		}
 	}
 	blog.Infof("Destroyed cloudIDs: %#v, rid:%s", cloudIDs, h.readKit.Rid)
 
 	// 更新属于被销毁vpc下的主机信息，将内外网ip置空，状态置为已销毁
-	condition := mapstr.MapStr{common.BKCloudIDField: map[string]interface{}{
-		common.BKDBIN: cloudIDs,
-	}}
-
-	query := &metadata.QueryCondition{
-		Condition: condition,
-	}
-	res, err := h.logics.CoreAPI.CoreService().Instance().ReadInstance(h.readKit.Ctx, h.readKit.Header,
-		common.BKInnerObjIDHost, query)
-	if nil != err {
-		blog.Errorf("syncDestroyedVpcs ReadInstance failed, error: %v query:%#v, rid:%s", err, query, h.readKit.Rid)
-		return err
-	}
-
-	hostIDs := make([]int64, 0)
-	for _, host := range res.Info {
-		hostID, _ := host.Int64(common.BKHostIDField)
-		hostIDs = append(hostIDs, hostID)
-	}
 	sResult, err := h.deleteDestroyedHosts(hostIDs)
 	if err != nil {
 		blog.Errorf("syncDestroyedVpcs deleteDestroyedHosts fail, cloudIDs:%#v, err:%s, rid:%s", cloudIDs,
@@ -266,12 +266,8 @@ func (h *HostSyncor) syncDestroyedVpcs(hostResource *metadata.CloudHostResource,
 		return err
 	}
 
-	vpcs := make(map[string]bool)
-	for _, vpcInfo := range hostResource.DestroyedVpcs {
-		vpcs[vpcInfo.VpcID] = true
-	}
 	// 更新同步任务里的vpc状态为被销毁
-	if err := h.updateDestroyedTaskVpc(hostResource.TaskID, vpcs); err != nil {
+	if err := h.updateDestroyedTaskVpc(taskID, vpcs); err != nil {
 		blog.Errorf("syncDestroyedVpcs updateDestroyedTaskVpc fail, cloudIDs:%#v, err:%s, rid:%s", cloudIDs,
 			err.Error(), h.readKit.Rid)
 		return err
@@ -300,8 +296,8 @@ func (h *HostSyncor) addCLoudId(accountConf *metadata.CloudAccountConf,
 	return nil
 }
 
-// getDiffHosts 根据主机实例id获取mongo中的主机信息,并获取有差异的主机
-func (h *HostSyncor) getDiffHosts(hostResource *metadata.CloudHostResource) (map[string][]*metadata.CloudHost, error) {
+// getDiffHosts 根据主机实例id获取mongo中的主机信息,并将有差异的主机记录到同步预览中
+func (h *HostSyncor) getDiffHosts(hostResource *metadata.CloudHostResource, preview *metadata.CloudSyncPreview) error {
 	// 云端的主机
 	remoteHostsMap := make(map[string]*metadata.CloudHost)
 	for _, hostRes := range hostResource.HostResource {
@@ -324,7 +320,7 @@ func (h *HostSyncor) getDiffHosts(hostResource *metadata.CloudHostResource) (map
 	// 本地已有的云主机
 	localHosts, err := h.getLocalHosts(cloudIDs)
 	if err != nil {
-		return nil, err
+		return err
 	}
 	blog.V(4).Infof("taskid:%d, len(localHosts):%d, rid:%s", hostResource.TaskID, len(localHosts), h.readKit.Rid)
 	localIdHostsMap := make(map[string]*metadata.CloudHost)
@@ -332,8 +328,6 @@ func (h *HostSyncor) getDiffHosts(hostResource *metadata.CloudHostResource) (map
 		localIdHostsMap[h.InstanceId] = h
 	}
 
-	// 有差异的主机
-	diffHosts := make(map[string][]*metadata.CloudHost)
 	// 本地需要同步新增和更新的主机
 	for _, h := range remoteHostsMap {
 		if _, ok := localIdHostsMap[h.InstanceId]; ok {
@@ -343,12 +337,15 @@ func (h *HostSyncor) getDiffHosts(hostResource *metadata.CloudHostResource) (map
 			if lh.InstanceState == common.BKCloudHostStatusDestroyed {
 				continue
 			}
-			if h.InstanceState != lh.InstanceState || h.PublicIp != lh.PublicIp ||
-				h.PrivateIp != lh.PrivateIp || h.CloudID != lh.CloudID {
-				diffHosts["update"] = append(diffHosts["update"], h)
+			changes := getHostFieldChanges(h, lh)
+			if len(changes) > 0 {
+				diff := metadata.NewCloudSyncHostDiff(h)
+				diff.HostID = lh.HostID
+				diff.Changes = changes
+				preview.Update = append(preview.Update, diff)
 			}
 		} else {
-			diffHosts["add"] = append(diffHosts["add"], h)
+			preview.Add = append(preview.Add, metadata.NewCloudSyncHostDiff(h))
 		}
 	}
 
@@ -358,12 +355,13 @@ func (h *HostSyncor) getDiffHosts(hostResource *metadata.CloudHostResource) (map
 		if h.InstanceState == common.BKCloudHostStatusDestroyed {
 			continue
 		}
+		preview.LocalHostCount++
 		if _, ok := remoteHostsMap[id]; !ok {
-			diffHosts["delete"] = append(diffHosts["delete"], h)
+			preview.Remove = append(preview.Remove, metadata.NewCloudSyncHostDiff(h))
 		}
 	}
 
-	return diffHosts, nil
+	return nil
 }
 
 // syncDiffHosts 同步有差异的主机数据
@@ -0,0 +1,291 @@
+/*
+ * Tencent is pleased to support the open source community by making 蓝鲸 available.
+ * Copyright (C) 2017-2018 THL A29 Limited, a Tencent company. All rights reserved.
+ * Licensed under the MIT License (the "License"); you may not use this file except
+ * in compliance with the License. You may obtain a copy of the License at
+ * http://opensource.org/licenses/MIT
+ * Unless required by applicable law or agreed to in writing, software distributed under
+ * the License is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND,
+ * either express or implied. See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package cloudsync
+
+import (
+	"time"
+
+	"configcenter/src/common"
+	"configcenter/src/common/blog"
+	"configcenter/src/common/http/rest"
+	"configcenter/src/common/mapstr"
+	"configcenter/src/common/metadata"
+	"configcenter/src/common/util"
+	ccom "configcenter/src/scene_server/cloud_server/common"
+	"configcenter/src/scene_server/cloud_server/logics"
+)
+
+// syncPreviewExpireDuration 同步预览的有效期，过期后需要重新生成同步预览
+const syncPreviewExpireDuration = 24 * time.Hour
+
+// PreviewSync 生成同步任务的同步预览并保存为待审批状态，不会修改主机数据，没有差异时返回的同步预览id为0
+func PreviewSync(lgc *logics.Logics, kit *rest.Kit, task *metadata.CloudSyncTask) (*metadata.CloudSyncPreview,
+	error) {
+
+	h := newRequestSyncor(lgc, kit, task.OwnerID)
+
+	accountConf, err := h.logics.GetCloudAccountConf(h.readKit, task.AccountID)
+	if err != nil {
+		blog.Errorf("get cloud account conf failed, taskID: %d, err: %v, rid: %s", task.TaskID, err, kit.Rid)
+		return nil, err
+	}
+
+	hostResource, err := h.getCloudHostResource(task, accountConf)
+	if err != nil {
+		blog.Errorf("get cloud host resource failed, taskID: %d, err: %v, rid: %s", task.TaskID, err, kit.Rid)
+		return nil, err
+	}
+
+	preview, err := h.generatePreview(task, accountConf, hostResource)
+	if err != nil {
+		blog.Errorf("generate sync preview failed, taskID: %d, err: %v, rid: %s", task.TaskID, err, kit.Rid)
+		return nil, err
+	}
+
+	return h.savePreview(preview)
+}
+
+// ApplySyncPreview 审批同步任务待审批的同步预览，并将同步预览中的差异同步到主机上
+func ApplySyncPreview(lgc *logics.Logics, kit *rest.Kit, task *metadata.CloudSyncTask, previewID int64) (
+	*metadata.SyncResult, error) {
+
+	h := newRequestSyncor(lgc, kit, task.OwnerID)
+
+	opt := &metadata.SearchSyncPreviewOption{
+		TaskID:    task.TaskID,
+		PreviewID: previewID,
+		Status:    metadata.CloudSyncPreviewPending,
+		Page:      metadata.BasePage{Limit: 1},
+	}
+	ret, err := h.logics.CoreAPI.CoreService().Cloud().SearchSyncPreview(h.readKit.Ctx, h.readKit.Header, opt)
+	if err != nil {
+		blog.Errorf("search sync preview failed, opt: %#v, err: %v, rid: %s", opt, err, kit.Rid)
+		return nil, err
+	}
+	if len(ret.Info) == 0 {
+		blog.Errorf("sync preview %d of task %d is not pending, rid: %s", previewID, task.TaskID, kit.Rid)
+		return nil, kit.CCError.CCErrorf(common.CCErrCloudSyncPreviewNotPending, previewID)
+	}
+	preview := &ret.Info[0]
+
+	// 同步预览过期或同步任务在其生成后已再次同步时，云端和本地的主机都可能已经变化，不能再审批
+	if preview.IsStale(task, time.Now()) {
+		blog.Errorf("sync preview %d of task %d is stale, expire time: %s, create time: %s, last sync time: %v, "+
+			"rid: %s", previewID, task.TaskID, preview.ExpireTime, preview.CreateTime, task.LastSyncTime, kit.Rid)
+		return nil, kit.CCError.CCErrorf(common.CCErrCloudSyncPreviewStale, previewID)
+	}
+
+	startTime := time.Now()
+	syncResult := new(metadata.SyncResult)
+	syncResult.FailInfo.IPError = make(map[string]string)
+
+	txnErr := h.logics.CoreAPI.CoreService().Txn().AutoRunTxn(h.readKit.Ctx, h.readKit.Header, func() error {
+		if err := h.syncCloudHost(syncResult, preview, startTime); err != nil {
+			return err
+		}
+
+		// 同步预览在审批过程中被新的同步预览替换时会返回错误，回滚本次同步
+		if err := h.logics.CoreAPI.CoreService().Cloud().ApplySyncPreview(h.writeKit.Ctx, h.writeKit.Header,
+			previewID); err != nil {
+			blog.Errorf("apply sync preview %d failed, err: %v, rid: %s", previewID, err, kit.Rid)
+			return err
+		}
+		return nil
+	})
+
+	// 事务结束，去掉readKit、writeKit中header的事务信息
+	ccom.DelHeaderTxnInfo(h.readKit.Header)
+	ccom.DelHeaderTxnInfo(h.writeKit.Header)
+
+	if txnErr != nil {
+		blog.Errorf("apply sync preview failed, taskID: %d, previewID: %d, err: %v, rid: %s", task.TaskID,
+			previewID, txnErr, kit.Rid)
+		return nil, txnErr
+	}
+
+	return syncResult, nil
+}
+
+// newRequestSyncor 创建处理接口请求的云主机同步器，读写kit使用请求的requestID和操作人
+func newRequestSyncor(lgc *logics.Logics, kit *rest.Kit, ownerID string) *HostSyncor {
+	h := NewHostSyncor(lgc)
+	h.readKit = ccom.NewKit()
+	h.writeKit = ccom.NewWriteKit(ownerID)
+
+	for _, k := range []*rest.Kit{h.readKit, h.writeKit} {
+		k.Header.Set(common.BKHTTPCCRequestID, kit.Rid)
+		k.Header.Set(common.BKHTTPHeaderUser, kit.User)
+		k.Rid = kit.Rid
+		k.User = kit.User
+		k.Ctx = util.NewContextFromHTTPHeader(k.Header)
+	}
+	return h
+}
+
+// generatePreview 生成同步预览，只读取主机数据，不会修改主机数据
+func (h *HostSyncor) generatePreview(task *metadata.CloudSyncTask, accountConf *metadata.CloudAccountConf,
+	hostResource *metadata.CloudHostResource) (*metadata.CloudSyncPreview, error) {
+
+	preview := &metadata.CloudSyncPreview{
+		TaskID:        task.TaskID,
+		Add:           make([]metadata.CloudSyncHostDiff, 0),
+		Update:        make([]metadata.CloudSyncHostDiff, 0),
+		Remove:        make([]metadata.CloudSyncHostDiff, 0),
+		DestroyedVpcs: make([]metadata.CloudSyncDestroyedVpc, 0),
+	}
+
+	// 被销毁的vpc下的主机都会被移除
+	for _, vpc := range hostResource.DestroyedVpcs {
+		hosts, err := h.getCloudAreaHosts(vpc.CloudID)
+		if err != nil {
+			return nil, err
+		}
+		preview.DestroyedVpcs = append(preview.DestroyedVpcs, metadata.CloudSyncDestroyedVpc{
+			VpcSyncInfo: *vpc,
+			Hosts:       hosts,
+		})
+		preview.LocalHostCount += int64(len(hosts))
+	}
+
+	// 查询vpc对应的云区域并更新云主机资源信息里的云区域id
+	if err := h.addCLoudId(accountConf, hostResource); err != nil {
+		blog.Errorf("addCLoudId fail, taskID: %d, err: %v, rid: %s", task.TaskID, err, h.readKit.Rid)
+		return nil, err
+	}
+
+	// 根据主机实例id获取mongo中的主机信息,并获取有差异的主机
+	if err := h.getDiffHosts(hostResource, preview); err != nil {
+		blog.Errorf("getDiffHosts fail, taskID: %d, err: %v, rid: %s", task.TaskID, err, h.readKit.Rid)
+		return nil, err
+	}
+
+	preview.CheckRemoveThreshold(task.RemoveThreshold)
+
+	return preview, nil
+}
+
+// getCloudAreaHosts 获取云区域下未销毁的主机
+func (h *HostSyncor) getCloudAreaHosts(cloudID int64) ([]metadata.CloudSyncHostDiff, error) {
+	query := &metadata.QueryCondition{
+		Condition: mapstr.MapStr{
+			common.BKCloudIDField:         cloudID,
+			common.BKCloudHostStatusField: mapstr.MapStr{common.BKDBNE: common.BKCloudHostStatusDestroyed},
+		},
+	}
+	res, err := h.logics.CoreAPI.CoreService().Instance().ReadInstance(h.readKit.Ctx, h.readKit.Header,
+		common.BKInnerObjIDHost, query)
+	if err != nil {
+		blog.Errorf("get cloud area hosts failed, err: %v, query: %#v, rid: %s", err, query, h.readKit.Rid)
+		return nil, err
+	}
+
+	hosts := make([]metadata.CloudSyncHostDiff, 0)
+	for _, host := range res.Info {
+		hostID, _ := host.Int64(common.BKHostIDField)
+		instID, _ := host.String(common.BKCloudInstIDField)
+		privateIp, _ := host.String(common.BKHostInnerIPField)
+		publicIp, _ := host.String(common.BKHostOuterIPField)
+		hostStatus, _ := host.String(common.BKCloudHostStatusField)
+		hosts = append(hosts, metadata.CloudSyncHostDiff{
+			HostID:        hostID,
+			InstanceID:    instID,
+			CloudID:       cloudID,
+			PrivateIP:     privateIp,
+			PublicIP:      publicIp,
+			InstanceState: hostStatus,
+		})
+	}
+	return hosts, nil
+}
+
+// savePreview 保存同步预览为待审批状态，没有差异时只删除之前待审批的同步预览
+func (h *HostSyncor) savePreview(preview *metadata.CloudSyncPreview) (*metadata.CloudSyncPreview, error) {
+	if preview.IsEmpty() {
+		err := h.logics.CoreAPI.CoreService().Cloud().DeletePendingSyncPreview(h.writeKit.Ctx, h.writeKit.Header,
+			preview.TaskID)
+		if err != nil {
+			blog.Errorf("delete pending sync preview failed, taskID: %d, err: %v, rid: %s", preview.TaskID, err,
+				h.readKit.Rid)
+			return nil, err
+		}
+		return preview, nil
+	}
+
+	preview.ExpireTime = time.Now().Add(syncPreviewExpireDuration)
+	result, err := h.logics.CoreAPI.CoreService().Cloud().CreateSyncPreview(h.writeKit.Ctx, h.writeKit.Header,
+		preview)
+	if err != nil {
+		blog.Errorf("create sync preview failed, taskID: %d, err: %v, rid: %s", preview.TaskID, err, h.readKit.Rid)
+		return nil, err
+	}
+	return result, nil
+}
+
+// abortSync 将要移除的主机比例超过阈值时将任务同步状态置为失败，并保存同步预览
+// 先更新任务同步状态，使本次中止的同步时间早于同步预览的创建时间，同步预览不会被视为过时
+func (h *HostSyncor) abortSync(preview *metadata.CloudSyncPreview, threshold int64) error {
+	blog.Errorf("sync is aborted, taskID: %d, remove percent %v exceeds threshold %d, rid: %s", preview.TaskID,
+		preview.RemovePercent, threshold, h.readKit.Rid)
+
+	errInfo := h.writeKit.CCError.CCErrorf(common.CCErrCloudSyncRemoveExceedThreshold, preview.RemovePercent,
+		threshold).Error()
+	err := h.updateTaskState(h.writeKit, preview.TaskID, metadata.CloudSyncFail,
+		&metadata.SyncStatusDesc{ErrorInfo: errInfo})
+	if err != nil {
+		blog.Errorf("updateTaskState fail, taskID: %d, err: %v, rid: %s", preview.TaskID, err, h.readKit.Rid)
+		return err
+	}
+
+	if _, err := h.savePreview(preview); err != nil {
+		blog.Errorf("savePreview fail, taskID: %d, err: %v, rid: %s", preview.TaskID, err, h.readKit.Rid)
+		return err
+	}
+	return nil
+}
+
+// getPreviewDiffHosts 将同步预览中的主机差异转换为需要同步的主机
+func getPreviewDiffHosts(preview *metadata.CloudSyncPreview) map[string][]*metadata.CloudHost {
+	diffHosts := make(map[string][]*metadata.CloudHost)
+	for i := range preview.Add {
+		diffHosts["add"] = append(diffHosts["add"], preview.Add[i].ToCloudHost())
+	}
+	for i := range preview.Update {
+		diffHosts["update"] = append(diffHosts["update"], preview.Update[i].ToCloudHost())
+	}
+	for i := range preview.Remove {
+		diffHosts["delete"] = append(diffHosts["delete"], preview.Remove[i].ToCloudHost())
+	}
+	return diffHosts
+}
+
+// getHostFieldChanges 获取云端主机相对于本地主机有变化的字段
+func getHostFieldChanges(remote, local *metadata.CloudHost) []metadata.CloudSyncFieldChange {
+	changes := make([]metadata.CloudSyncFieldChange, 0)
+	if remote.InstanceState != local.InstanceState {
+		changes = append(changes, metadata.CloudSyncFieldChange{Field: common.BKCloudHostStatusField,
+			Before: local.InstanceState, After: remote.InstanceState})
+	}
+	if remote.PublicIp != local.PublicIp {
+		changes = append(changes, metadata.CloudSyncFieldChange{Field: common.BKHostOuterIPField,
+			Before: local.PublicIp, After: remote.PublicIp})
+	}
+	if remote.PrivateIp != local.PrivateIp {
+		changes = append(changes, metadata.CloudSyncFieldChange{Field: common.BKHostInnerIPField,
+			Before: local.PrivateIp, After: remote.PrivateIp})
+	}
+	if remote.CloudID != local.CloudID {
+		changes = append(changes, metadata.CloudSyncFieldChange{Field: common.BKCloudIDField,
+			Before: local.CloudID, After: remote.CloudID})
+	}
+	return changes
+}
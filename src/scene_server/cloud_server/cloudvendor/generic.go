@@ -0,0 +1,472 @@
+/*
+ * Tencent is pleased to support the open source community by making 蓝鲸 available.
+ * Copyright (C) 2017-2018 THL A29 Limited, a Tencent company. All rights reserved.
+ * Licensed under the MIT License (the "License"); you may not use this file except
+ * in compliance with the License. You may obtain a copy of the License at
+ * http://opensource.org/licenses/MIT
+ * Unless required by applicable law or agreed to in writing, software distributed under
+ * the License is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND,
+ * either express or implied. See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package cloudvendor
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	"configcenter/src/common/metadata"
+	ccom "configcenter/src/scene_server/cloud_server/common"
+
+	"gopkg.in/yaml.v2"
+)
+
+func init() {
+	Register(metadata.Generic, &genericClient{vendorName: metadata.Generic})
+}
+
+// genericClient 通用云厂商客户端，从HTTP接口或本地资源清单文件中读取地域、vpc和实例
+// secretID为资源清单地址，支持http(s)://开头的HTTP接口地址、file://开头或者直接指定的本地文件路径，
+// 本地文件只能位于配置的资源清单目录下，HTTP接口地址的主机名需要在配置的允许列表中
+// secretKey为可选的HTTP接口访问令牌，设置时以Bearer Token的方式放在Authorization请求头中
+type genericClient struct {
+	vendorName string
+	source     string
+	token      string
+}
+
+const (
+	// genericRequestTimeout 请求资源清单HTTP接口的超时时间
+	genericRequestTimeout = 30 * time.Second
+	// genericVpcIDFilter 按vpc id过滤的过滤条件名称
+	genericVpcIDFilter = "vpc-id"
+	// genericInstanceIDFilter 按实例id过滤的过滤条件名称
+	genericInstanceIDFilter = "instance-id"
+)
+
+var genericHttpClient = &http.Client{
+	Timeout: genericRequestTimeout,
+	// 重定向的地址同样需要校验主机名，避免通过重定向访问不允许的地址
+	CheckRedirect: func(req *http.Request, via []*http.Request) error {
+		return validateGenericHost(req.URL)
+	},
+}
+
+// GenericConf 通用云厂商的配置，在common.yaml的cloudServer.generic中配置
+type GenericConf struct {
+	// InventoryDir 本地资源清单文件所在的目录，只允许读取该目录下的文件，未配置时不允许使用本地文件
+	InventoryDir string
+	// AllowedHosts 允许访问的资源清单HTTP接口的主机名列表，未配置时不限制
+	AllowedHosts []string
+}
+
+var (
+	genericConf     GenericConf
+	genericConfLock sync.RWMutex
+)
+
+// SetGenericConf 设置通用云厂商的配置，配置更新时调用
+func SetGenericConf(conf GenericConf) {
+	genericConfLock.Lock()
+	defer genericConfLock.Unlock()
+	genericConf = conf
+}
+
+func getGenericConf() GenericConf {
+	genericConfLock.RLock()
+	defer genericConfLock.RUnlock()
+	return genericConf
+}
+
+// GenericInventory 通用云厂商的资源清单，格式为json或yaml，文档见docs/features/cloud_generic_vendor.md
+type GenericInventory struct {
+	Regions []GenericRegion `json:"regions" yaml:"regions"`
+}
+
+// GenericRegion 资源清单中的地域
+type GenericRegion struct {
+	ID    string       `json:"id" yaml:"id"`
+	Name  string       `json:"name" yaml:"name"`
+	State string       `json:"state" yaml:"state"`
+	Vpcs  []GenericVpc `json:"vpcs" yaml:"vpcs"`
+}
+
+// GenericVpc 资源清单中的vpc
+type GenericVpc struct {
+	ID        string            `json:"id" yaml:"id"`
+	Name      string            `json:"name" yaml:"name"`
+	Instances []GenericInstance `json:"instances" yaml:"instances"`
+}
+
+// GenericInstance 资源清单中的实例
+type GenericInstance struct {
+	ID        string `json:"id" yaml:"id"`
+	PrivateIP string `json:"private_ip" yaml:"private_ip"`
+	PublicIP  string `json:"public_ip" yaml:"public_ip"`
+	// State 实例状态，如running、stopped等，会被转换为统一的实例状态
+	State string `json:"state" yaml:"state"`
+}
+
+// Validate 校验资源清单，地域、vpc和实例的id以及实例的内网ip不能为空，且地域id、vpc id和实例id分别不能重复
+func (i *GenericInventory) Validate() error {
+	regionIDs := make(map[string]struct{})
+	vpcIDs := make(map[string]struct{})
+	instIDs := make(map[string]struct{})
+	for _, region := range i.Regions {
+		if region.ID == "" {
+			return fmt.Errorf("inventory region id is empty")
+		}
+		if _, exists := regionIDs[region.ID]; exists {
+			return fmt.Errorf("inventory region id %s is duplicated", region.ID)
+		}
+		regionIDs[region.ID] = struct{}{}
+
+		for _, vpc := range region.Vpcs {
+			if vpc.ID == "" {
+				return fmt.Errorf("inventory vpc id in region %s is empty", region.ID)
+			}
+			if _, exists := vpcIDs[vpc.ID]; exists {
+				return fmt.Errorf("inventory vpc id %s is duplicated", vpc.ID)
+			}
+			vpcIDs[vpc.ID] = struct{}{}
+
+			for _, inst := range vpc.Instances {
+				if inst.ID == "" {
+					return fmt.Errorf("inventory instance id in vpc %s is empty", vpc.ID)
+				}
+				if inst.PrivateIP == "" {
+					return fmt.Errorf("inventory instance %s private ip is empty", inst.ID)
+				}
+				if _, exists := instIDs[inst.ID]; exists {
+					return fmt.Errorf("inventory instance id %s is duplicated", inst.ID)
+				}
+				instIDs[inst.ID] = struct{}{}
+			}
+		}
+	}
+	return nil
+}
+
+// NewVendorClient 创建云厂商客户端
+func (c *genericClient) NewVendorClient(secretID, secretKey string) VendorClient {
+	return &genericClient{
+		vendorName: metadata.Generic,
+		source:     secretID,
+		token:      secretKey,
+	}
+}
+
+// GetRegions 获取地域列表
+func (c *genericClient) GetRegions() ([]*metadata.Region, error) {
+	inventory, err := c.getInventory()
+	if err != nil {
+		return nil, err
+	}
+
+	regionSet := make([]*metadata.Region, 0)
+	for _, region := range inventory.Regions {
+		regionSet = append(regionSet, &metadata.Region{
+			RegionId:    region.ID,
+			RegionName:  region.Name,
+			RegionState: region.State,
+		})
+	}
+	return regionSet, nil
+}
+
+// GetVpcs 获取vpc列表，支持按vpc-id过滤
+func (c *genericClient) GetVpcs(region string, opt *ccom.VpcOpt) (*metadata.VpcsInfo, error) {
+	if opt == nil {
+		opt = ccom.GetDefaultVpcOpt()
+	}
+
+	vpcs, err := c.getRegionVpcs(region, opt.Filters)
+	if err != nil {
+		return nil, err
+	}
+
+	vpcsInfo := &metadata.VpcsInfo{VpcSet: make([]*metadata.Vpc, 0)}
+	for _, vpc := range vpcs {
+		if opt.Limit > 0 && int64(len(vpcsInfo.VpcSet)) >= opt.Limit {
+			break
+		}
+		vpcsInfo.VpcSet = append(vpcsInfo.VpcSet, &metadata.Vpc{
+			VpcId:   vpc.ID,
+			VpcName: vpc.Name,
+		})
+	}
+	vpcsInfo.Count = int64(len(vpcsInfo.VpcSet))
+
+	return vpcsInfo, nil
+}
+
+// GetInstances 获取实例列表，支持按vpc-id和instance-id过滤，返回的实例总数为不受limit限制的全部实例个数
+func (c *genericClient) GetInstances(region string, opt *ccom.InstanceOpt) (*metadata.InstancesInfo, error) {
+	if opt == nil {
+		opt = ccom.GetDefaultInstanceOpt()
+	}
+
+	instances, err := c.getInstances(region, opt.Filters)
+	if err != nil {
+		return nil, err
+	}
+
+	instancesInfo := &metadata.InstancesInfo{
+		Count:       int64(len(instances)),
+		InstanceSet: make([]*metadata.Instance, 0),
+	}
+	for _, inst := range instances {
+		if opt.Limit > 0 && int64(len(instancesInfo.InstanceSet)) >= opt.Limit {
+			break
+		}
+		instancesInfo.InstanceSet = append(instancesInfo.InstanceSet, inst)
+	}
+
+	return instancesInfo, nil
+}
+
+// GetInstancesTotalCnt 获取实例总个数
+func (c *genericClient) GetInstancesTotalCnt(region string, opt *ccom.InstanceOpt) (int64, error) {
+	var filters []*ccom.Filter
+	if opt != nil {
+		filters = opt.Filters
+	}
+
+	instances, err := c.getInstances(region, filters)
+	if err != nil {
+		return 0, err
+	}
+	return int64(len(instances)), nil
+}
+
+// getRegionVpcs 获取地域下符合过滤条件的vpc
+func (c *genericClient) getRegionVpcs(region string, filters []*ccom.Filter) ([]GenericVpc, error) {
+	conds, err := parseGenericFilters(filters)
+	if err != nil {
+		return nil, err
+	}
+
+	inventory, err := c.getInventory()
+	if err != nil {
+		return nil, err
+	}
+
+	for _, r := range inventory.Regions {
+		if r.ID != region {
+			continue
+		}
+
+		vpcs := make([]GenericVpc, 0)
+		for _, vpc := range r.Vpcs {
+			if conds.match(genericVpcIDFilter, vpc.ID) {
+				vpcs = append(vpcs, vpc)
+			}
+		}
+		return vpcs, nil
+	}
+
+	return nil, fmt.Errorf("region %s is not found in the inventory", region)
+}
+
+// getInstances 获取地域下符合过滤条件的全部实例
+func (c *genericClient) getInstances(region string, filters []*ccom.Filter) ([]*metadata.Instance, error) {
+	vpcs, err := c.getRegionVpcs(region, filters)
+	if err != nil {
+		return nil, err
+	}
+
+	// 过滤条件在获取vpc时已经校验过，这里不会出错
+	conds, _ := parseGenericFilters(filters)
+	instances := make([]*metadata.Instance, 0)
+	for _, vpc := range vpcs {
+		for _, inst := range vpc.Instances {
+			if !conds.match(genericInstanceIDFilter, inst.ID) {
+				continue
+			}
+
+			instances = append(instances, &metadata.Instance{
+				InstanceId:    inst.ID,
+				PrivateIp:     inst.PrivateIP,
+				PublicIp:      inst.PublicIP,
+				InstanceState: ccom.CovertInstState(inst.State),
+				VpcId:         vpc.ID,
+			})
+		}
+	}
+	return instances, nil
+}
+
+// getInventory 从HTTP接口或本地文件中读取并校验资源清单，每次调用都会重新读取，以获取最新的云资源
+func (c *genericClient) getInventory() (*GenericInventory, error) {
+	if c.source == "" {
+		return nil, fmt.Errorf("generic vendor inventory source is not set")
+	}
+
+	var content []byte
+	var err error
+	isYaml := false
+	if strings.HasPrefix(c.source, "http://") || strings.HasPrefix(c.source, "https://") {
+		content, isYaml, err = c.fetchInventory()
+	} else {
+		var path string
+		path, err = resolveGenericInventoryFile(c.source)
+		if err == nil {
+			content, err = ioutil.ReadFile(path)
+			if err != nil {
+				err = fmt.Errorf("read inventory file %s failed, err: %v", path, err)
+			}
+		}
+		ext := strings.ToLower(filepath.Ext(path))
+		isYaml = ext == ".yaml" || ext == ".yml"
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	inventory := new(GenericInventory)
+	if isYaml {
+		err = yaml.Unmarshal(content, inventory)
+	} else {
+		err = json.Unmarshal(content, inventory)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("unmarshal inventory from %s failed, err: %v", c.source, err)
+	}
+
+	if err = inventory.Validate(); err != nil {
+		return nil, err
+	}
+	return inventory, nil
+}
+
+// resolveGenericInventoryFile 获取本地资源清单文件的真实路径，相对路径基于资源清单目录，文件必须位于资源清单目录下
+func resolveGenericInventoryFile(source string) (string, error) {
+	dir := getGenericConf().InventoryDir
+	if dir == "" {
+		return "", errors.New("generic vendor inventory dir is not configured, local inventory file is not allowed")
+	}
+
+	realDir, err := filepath.EvalSymlinks(dir)
+	if err != nil {
+		return "", fmt.Errorf("get real path of inventory dir %s failed, err: %v", dir, err)
+	}
+
+	path := strings.TrimPrefix(source, "file://")
+	if !filepath.IsAbs(path) {
+		path = filepath.Join(dir, path)
+	}
+
+	// 解析符号链接后再校验，避免通过符号链接读取目录外的文件
+	realPath, err := filepath.EvalSymlinks(path)
+	if err != nil {
+		return "", fmt.Errorf("get real path of inventory file %s failed, err: %v", path, err)
+	}
+
+	rel, err := filepath.Rel(realDir, realPath)
+	if err != nil || rel == ".." || strings.HasPrefix(rel, ".."+string(filepath.Separator)) {
+		return "", fmt.Errorf("inventory file %s is not in the inventory dir %s", path, dir)
+	}
+
+	return realPath, nil
+}
+
+// validateGenericHost 校验资源清单HTTP接口的主机名是否在允许列表中，未配置允许列表时不限制
+func validateGenericHost(u *url.URL) error {
+	allowedHosts := getGenericConf().AllowedHosts
+	if len(allowedHosts) == 0 {
+		return nil
+	}
+
+	host := strings.ToLower(u.Hostname())
+	for _, allowed := range allowedHosts {
+		if strings.ToLower(allowed) == host {
+			return nil
+		}
+	}
+	return fmt.Errorf("inventory host %s is not allowed", host)
+}
+
+// fetchInventory 从HTTP接口获取资源清单，返回清单内容以及是否为yaml格式
+func (c *genericClient) fetchInventory() ([]byte, bool, error) {
+	req, err := http.NewRequest(http.MethodGet, c.source, nil)
+	if err != nil {
+		return nil, false, fmt.Errorf("new inventory request to %s failed, err: %v", c.source, err)
+	}
+
+	if err = validateGenericHost(req.URL); err != nil {
+		return nil, false, err
+	}
+	req.Header.Set("Accept", "application/json, application/yaml")
+	if c.token != "" {
+		req.Header.Set("Authorization", "Bearer "+c.token)
+	}
+
+	resp, err := genericHttpClient.Do(req)
+	if err != nil {
+		return nil, false, fmt.Errorf("request inventory from %s failed, err: %v", c.source, err)
+	}
+	defer resp.Body.Close()
+
+	// 认证失败时返回的错误包含AuthFailure，以便账户连通性测试时返回密钥错误
+	if resp.StatusCode == http.StatusUnauthorized || resp.StatusCode == http.StatusForbidden {
+		return nil, false, fmt.Errorf("AuthFailure, request inventory from %s failed, status: %s", c.source,
+			resp.Status)
+	}
+	if resp.StatusCode != http.StatusOK {
+		return nil, false, fmt.Errorf("request inventory from %s failed, status: %s", c.source, resp.Status)
+	}
+
+	content, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		return nil, false, fmt.Errorf("read inventory response from %s failed, err: %v", c.source, err)
+	}
+
+	return content, strings.Contains(strings.ToLower(resp.Header.Get("Content-Type")), "yaml"), nil
+}
+
+// genericFilters 通用云厂商的过滤条件，key为过滤条件名称，value为过滤值集合
+type genericFilters map[string]map[string]struct{}
+
+// parseGenericFilters 解析过滤条件，只支持vpc-id和instance-id
+func parseGenericFilters(filters []*ccom.Filter) (genericFilters, error) {
+	conds := make(genericFilters)
+	for _, filter := range filters {
+		if filter == nil || filter.Name == nil {
+			continue
+		}
+
+		name := *filter.Name
+		if name != genericVpcIDFilter && name != genericInstanceIDFilter {
+			return nil, fmt.Errorf("filter %s is not supported by generic vendor", name)
+		}
+
+		if _, exists := conds[name]; !exists {
+			conds[name] = make(map[string]struct{})
+		}
+		for _, value := range filter.Values {
+			if value != nil {
+				conds[name][*value] = struct{}{}
+			}
+		}
+	}
+	return conds, nil
+}
+
+// match 判断值是否符合对应名称的过滤条件，未设置该过滤条件时视为符合
+func (f genericFilters) match(name, value string) bool {
+	values, exists := f[name]
+	if !exists {
+		return true
+	}
+	_, exists = values[value]
+	return exists
+}
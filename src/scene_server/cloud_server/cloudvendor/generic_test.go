@@ -0,0 +1,152 @@
+/*
+ * Tencent is pleased to support the open source community by making 蓝鲸 available.
+ * Copyright (C) 2017-2018 THL A29 Limited, a Tencent company. All rights reserved.
+ * Licensed under the MIT License (the "License"); you may not use this file except
+ * in compliance with the License. You may obtain a copy of the License at
+ * http://opensource.org/licenses/MIT
+ * Unless required by applicable law or agreed to in writing, software distributed under
+ * the License is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND,
+ * either express or implied. See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package cloudvendor_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"configcenter/src/common/metadata"
+	"configcenter/src/scene_server/cloud_server/cloudvendor"
+	"configcenter/src/scene_server/cloud_server/cloudvendor/vendortest"
+)
+
+const genericTestInventory = `{
+    "regions": [
+        {
+            "id": "dc-1",
+            "name": "datacenter 1",
+            "vpcs": [
+                {
+                    "id": "vpc-1",
+                    "name": "office",
+                    "instances": [
+                        {"id": "i-1", "private_ip": "10.0.0.1", "public_ip": "1.1.1.1", "state": "running"},
+                        {"id": "i-2", "private_ip": "10.0.0.2", "state": "stopped"}
+                    ]
+                },
+                {
+                    "id": "vpc-2",
+                    "name": "prod",
+                    "instances": [
+                        {"id": "i-3", "private_ip": "10.0.1.3", "state": "ACTIVE"}
+                    ]
+                }
+            ]
+        },
+        {
+            "id": "dc-2",
+            "name": "datacenter 2"
+        }
+    ]
+}`
+
+const genericTestYamlInventory = `regions:
+- id: dc-1
+  name: datacenter 1
+  vpcs:
+  - id: vpc-1
+    name: office
+    instances:
+    - id: i-1
+      private_ip: 10.0.0.1
+      state: running
+`
+
+func newGenericTestClient(t *testing.T, source, token string) cloudvendor.VendorClient {
+	client, err := cloudvendor.GetVendorClient(metadata.CloudAccountConf{
+		VendorName: metadata.Generic,
+		SecretID:   source,
+		SecretKey:  token,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return client
+}
+
+func TestGenericHTTPInventory(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Authorization") != "Bearer test-token" {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(genericTestInventory))
+	}))
+	defer server.Close()
+
+	vendortest.RunConformance(t, newGenericTestClient(t, server.URL, "test-token"))
+
+	_, err := newGenericTestClient(t, server.URL, "wrong-token").GetRegions()
+	if err == nil || !strings.Contains(err.Error(), "AuthFailure") {
+		t.Fatalf("get regions with wrong token should return auth failure, err: %v", err)
+	}
+
+	cloudvendor.SetGenericConf(cloudvendor.GenericConf{AllowedHosts: []string{"inventory.example.com"}})
+	defer cloudvendor.SetGenericConf(cloudvendor.GenericConf{})
+
+	_, err = newGenericTestClient(t, server.URL, "test-token").GetRegions()
+	if err == nil || !strings.Contains(err.Error(), "not allowed") {
+		t.Fatalf("get regions from the host that is not allowed should return error, err: %v", err)
+	}
+}
+
+func TestGenericFileInventory(t *testing.T) {
+	dir := t.TempDir()
+	jsonFile := filepath.Join(dir, "inventory.json")
+	if err := os.WriteFile(jsonFile, []byte(genericTestInventory), 0644); err != nil {
+		t.Fatal(err)
+	}
+	yamlFile := filepath.Join(dir, "inventory.yaml")
+	if err := os.WriteFile(yamlFile, []byte(genericTestYamlInventory), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	_, err := newGenericTestClient(t, jsonFile, "").GetRegions()
+	if err == nil {
+		t.Fatal("get regions from local file without inventory dir configured should return error")
+	}
+
+	cloudvendor.SetGenericConf(cloudvendor.GenericConf{InventoryDir: dir})
+	defer cloudvendor.SetGenericConf(cloudvendor.GenericConf{})
+
+	vendortest.RunConformance(t, newGenericTestClient(t, jsonFile, ""))
+	vendortest.RunConformance(t, newGenericTestClient(t, "file://"+yamlFile, ""))
+	vendortest.RunConformance(t, newGenericTestClient(t, "inventory.json", ""))
+
+	_, err = newGenericTestClient(t, jsonFile, "").GetVpcs("dc-3", nil)
+	if err == nil {
+		t.Fatal("get vpcs of the region that not exists should return error")
+	}
+
+	outsideDir := t.TempDir()
+	outsideFile := filepath.Join(outsideDir, "inventory.json")
+	if err := os.WriteFile(outsideFile, []byte(genericTestInventory), 0644); err != nil {
+		t.Fatal(err)
+	}
+	linkFile := filepath.Join(dir, "link.json")
+	if err := os.Symlink(outsideFile, linkFile); err != nil {
+		t.Fatal(err)
+	}
+
+	for _, source := range []string{outsideFile, "../" + filepath.Base(outsideDir) + "/inventory.json", linkFile} {
+		if _, err := newGenericTestClient(t, source, "").GetRegions(); err == nil {
+			t.Fatalf("get regions from file %s that is not in inventory dir should return error", source)
+		}
+	}
+}
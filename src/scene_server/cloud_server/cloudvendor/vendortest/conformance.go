@@ -0,0 +1,195 @@
+/*
+ * Tencent is pleased to support the open source community by making 蓝鲸 available.
+ * Copyright (C) 2017-2018 THL A29 Limited, a Tencent company. All rights reserved.
+ * Licensed under the MIT License (the "License"); you may not use this file except
+ * in compliance with the License. You may obtain a copy of the License at
+ * http://opensource.org/licenses/MIT
+ * Unless required by applicable law or agreed to in writing, software distributed under
+ * the License is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND,
+ * either express or implied. See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+// Package vendortest provides the conformance test harness for the cloudvendor.VendorClient implementations,
+// which checks the behaviors that cloud sync relies on, regardless of the cloud resources of the account.
+package vendortest
+
+import (
+	"testing"
+
+	"configcenter/src/common"
+	"configcenter/src/common/metadata"
+	"configcenter/src/scene_server/cloud_server/cloudvendor"
+	ccom "configcenter/src/scene_server/cloud_server/common"
+)
+
+var validInstStates = map[string]struct{}{
+	common.BKCloudHostStatusUnknown:   {},
+	common.BKCloudHostStatusStarting:  {},
+	common.BKCloudHostStatusRunning:   {},
+	common.BKCloudHostStatusStopping:  {},
+	common.BKCloudHostStatusStopped:   {},
+	common.BKCloudHostStatusDestroyed: {},
+}
+
+// RunConformance run the conformance tests against the vendor client, the regions, vpcs and instances of the
+// client's account are all checked, so the account should not have too many cloud resources.
+func RunConformance(t *testing.T, client cloudvendor.VendorClient) {
+	regions, err := client.GetRegions()
+	if err != nil {
+		t.Fatalf("get regions failed, err: %v", err)
+	}
+
+	regionIDs := make(map[string]struct{})
+	for _, region := range regions {
+		if region == nil || region.RegionId == "" {
+			t.Fatalf("region %+v has no region id", region)
+		}
+		if _, exists := regionIDs[region.RegionId]; exists {
+			t.Fatalf("region id %s is duplicated", region.RegionId)
+		}
+		regionIDs[region.RegionId] = struct{}{}
+	}
+
+	for _, region := range regions {
+		regionID := region.RegionId
+		t.Run(regionID, func(t *testing.T) {
+			t.Run("vpcs", func(t *testing.T) {
+				checkVpcs(t, client, regionID)
+			})
+			t.Run("instances", func(t *testing.T) {
+				checkInstances(t, client, regionID)
+			})
+		})
+	}
+}
+
+// checkVpcs check the vpcs of the region, including the limit and the vpc-id filter
+func checkVpcs(t *testing.T, client cloudvendor.VendorClient, region string) {
+	vpcsInfo, err := client.GetVpcs(region, ccom.GetDefaultVpcOpt())
+	if err != nil {
+		t.Fatalf("get vpcs failed, err: %v", err)
+	}
+
+	if vpcsInfo.Count != int64(len(vpcsInfo.VpcSet)) {
+		t.Fatalf("vpc count %d is not equal to the vpc set length %d", vpcsInfo.Count, len(vpcsInfo.VpcSet))
+	}
+
+	vpcIDs := make(map[string]struct{})
+	for _, vpc := range vpcsInfo.VpcSet {
+		if vpc == nil || vpc.VpcId == "" {
+			t.Fatalf("vpc %+v has no vpc id", vpc)
+		}
+		if _, exists := vpcIDs[vpc.VpcId]; exists {
+			t.Fatalf("vpc id %s is duplicated", vpc.VpcId)
+		}
+		vpcIDs[vpc.VpcId] = struct{}{}
+	}
+
+	if len(vpcsInfo.VpcSet) == 0 {
+		return
+	}
+
+	limited, err := client.GetVpcs(region, &ccom.VpcOpt{BaseOpt: ccom.BaseOpt{Limit: 1}})
+	if err != nil {
+		t.Fatalf("get vpcs with limit failed, err: %v", err)
+	}
+	if len(limited.VpcSet) != 1 {
+		t.Fatalf("get vpcs with limit 1 returns %d vpcs", len(limited.VpcSet))
+	}
+
+	vpcID := vpcsInfo.VpcSet[0].VpcId
+	filtered, err := client.GetVpcs(region, &ccom.VpcOpt{BaseOpt: ccom.BaseOpt{
+		Filters: newVpcIDFilters(vpcID),
+		Limit:   ccom.MaxLimit,
+	}})
+	if err != nil {
+		t.Fatalf("get vpcs with vpc id filter failed, err: %v", err)
+	}
+	if len(filtered.VpcSet) != 1 || filtered.VpcSet[0].VpcId != vpcID {
+		t.Fatalf("get vpcs with vpc id %s filter returns %d vpcs", vpcID, len(filtered.VpcSet))
+	}
+}
+
+// checkInstances check the instances of the region, including the total count, the limit and the vpc-id filter
+func checkInstances(t *testing.T, client cloudvendor.VendorClient, region string) {
+	instancesInfo, err := client.GetInstances(region, ccom.GetDefaultInstanceOpt())
+	if err != nil {
+		t.Fatalf("get instances failed, err: %v", err)
+	}
+
+	if instancesInfo.Count < int64(len(instancesInfo.InstanceSet)) {
+		t.Fatalf("instance count %d is less than the instance set length %d", instancesInfo.Count,
+			len(instancesInfo.InstanceSet))
+	}
+
+	totalCnt, err := client.GetInstancesTotalCnt(region, ccom.GetDefaultInstanceOpt())
+	if err != nil {
+		t.Fatalf("get instances total count failed, err: %v", err)
+	}
+	if totalCnt != instancesInfo.Count {
+		t.Fatalf("instances total count %d is not equal to the instance count %d", totalCnt, instancesInfo.Count)
+	}
+
+	instIDs := make(map[string]struct{})
+	for _, inst := range instancesInfo.InstanceSet {
+		checkInstance(t, inst)
+		if _, exists := instIDs[inst.InstanceId]; exists {
+			t.Fatalf("instance id %s is duplicated", inst.InstanceId)
+		}
+		instIDs[inst.InstanceId] = struct{}{}
+	}
+
+	if len(instancesInfo.InstanceSet) == 0 {
+		return
+	}
+
+	limited, err := client.GetInstances(region, &ccom.InstanceOpt{BaseOpt: ccom.BaseOpt{Limit: 1}})
+	if err != nil {
+		t.Fatalf("get instances with limit failed, err: %v", err)
+	}
+	if len(limited.InstanceSet) != 1 {
+		t.Fatalf("get instances with limit 1 returns %d instances", len(limited.InstanceSet))
+	}
+	if limited.Count != instancesInfo.Count {
+		t.Fatalf("instance count %d with limit is not equal to the total count %d", limited.Count,
+			instancesInfo.Count)
+	}
+
+	vpcID := instancesInfo.InstanceSet[0].VpcId
+	filtered, err := client.GetInstances(region, &ccom.InstanceOpt{BaseOpt: ccom.BaseOpt{
+		Filters: newVpcIDFilters(vpcID),
+		Limit:   ccom.MaxLimit,
+	}})
+	if err != nil {
+		t.Fatalf("get instances with vpc id filter failed, err: %v", err)
+	}
+	if len(filtered.InstanceSet) == 0 {
+		t.Fatalf("get instances with vpc id %s filter returns no instance", vpcID)
+	}
+	for _, inst := range filtered.InstanceSet {
+		if inst.VpcId != vpcID {
+			t.Fatalf("instance %s in vpc %s is returned by vpc id %s filter", inst.InstanceId, inst.VpcId, vpcID)
+		}
+	}
+}
+
+// checkInstance check the fields of the instance that are required by cloud sync
+func checkInstance(t *testing.T, inst *metadata.Instance) {
+	if inst == nil || inst.InstanceId == "" {
+		t.Fatalf("instance %+v has no instance id", inst)
+	}
+	if inst.VpcId == "" {
+		t.Fatalf("instance %s has no vpc id", inst.InstanceId)
+	}
+	if inst.PrivateIp == "" {
+		t.Fatalf("instance %s has no private ip", inst.InstanceId)
+	}
+	if _, exists := validInstStates[inst.InstanceState]; !exists {
+		t.Fatalf("instance %s state %s is invalid", inst.InstanceId, inst.InstanceState)
+	}
+}
+
+func newVpcIDFilters(vpcID string) []*ccom.Filter {
+	return []*ccom.Filter{{Name: ccom.StringPtr("vpc-id"), Values: ccom.StringPtrs([]string{vpcID})}}
+}
@@ -0,0 +1,475 @@
+/*
+ * Tencent is pleased to support the open source community by making 蓝鲸 available.
+ * Copyright (C) 2017-2018 THL A29 Limited, a Tencent company. All rights reserved.
+ * Licensed under the MIT License (the "License"); you may not use this file except
+ * in compliance with the License. You may obtain a copy of the License at
+ * http://opensource.org/licenses/MIT
+ * Unless required by applicable law or agreed to in writing, software distributed under
+ * the License is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND,
+ * either express or implied. See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package kubesync
+
+import (
+	"sort"
+	"strings"
+
+	"configcenter/src/common/criteria/enumor"
+	"configcenter/src/common/json"
+	"configcenter/src/kube/types"
+
+	appsv1 "k8s.io/api/apps/v1"
+	batchv1 "k8s.io/api/batch/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/util/intstr"
+)
+
+const (
+	// nodeRoleLabelPrefix the label prefix of the node roles, e.g. node-role.kubernetes.io/master
+	nodeRoleLabelPrefix = "node-role.kubernetes.io/"
+)
+
+// convertNode convert the kubernetes node to cmdb node, the cluster and host info is set by the syncer
+func convertNode(node *corev1.Node) *types.Node {
+	roles := make([]string, 0)
+	for key := range node.Labels {
+		if strings.HasPrefix(key, nodeRoleLabelPrefix) {
+			roles = append(roles, strings.TrimPrefix(key, nodeRoleLabelPrefix))
+		}
+	}
+	sort.Strings(roles)
+
+	taints := make(enumor.MapStringType)
+	for _, taint := range node.Spec.Taints {
+		taints[taint.Key] = taint.Value
+	}
+
+	internalIPs, externalIPs := make([]string, 0), make([]string, 0)
+	hostname := ""
+	for _, addr := range node.Status.Addresses {
+		switch addr.Type {
+		case corev1.NodeInternalIP:
+			internalIPs = append(internalIPs, addr.Address)
+		case corev1.NodeExternalIP:
+			externalIPs = append(externalIPs, addr.Address)
+		case corev1.NodeHostName:
+			hostname = addr.Address
+		}
+	}
+
+	labels := enumor.MapStringType(copyStringMap(node.Labels))
+	return &types.Node{
+		Name:             stringPtr(node.Name),
+		Labels:           &labels,
+		Taints:           &taints,
+		Unschedulable:    &node.Spec.Unschedulable,
+		InternalIP:       &internalIPs,
+		ExternalIP:       &externalIPs,
+		HostName:         stringPtr(hostname),
+		RuntimeComponent: stringPtr(node.Status.NodeInfo.ContainerRuntimeVersion),
+		PodCidr:          stringPtr(node.Spec.PodCIDR),
+		Roles:            stringPtr(strings.Join(roles, ",")),
+	}
+}
+
+// convertNamespace convert the kubernetes namespace and its resource quotas to cmdb namespace
+func convertNamespace(ns *corev1.Namespace, quotas []*corev1.ResourceQuota) *types.Namespace {
+	labels := copyStringMap(ns.Labels)
+	resourceQuotas := make([]types.ResourceQuota, 0, len(quotas))
+	for _, quota := range quotas {
+		hard := make(map[string]string, len(quota.Spec.Hard))
+		for name, quantity := range quota.Spec.Hard {
+			hard[string(name)] = quantity.String()
+		}
+
+		scopes := make([]types.ResourceQuotaScope, 0, len(quota.Spec.Scopes))
+		for _, scope := range quota.Spec.Scopes {
+			scopes = append(scopes, types.ResourceQuotaScope(scope))
+		}
+
+		var scopeSelector *types.ScopeSelector
+		if quota.Spec.ScopeSelector != nil {
+			scopeSelector = &types.ScopeSelector{
+				MatchExpressions: make([]types.ScopedResourceSelectorRequirement, 0),
+			}
+			for _, expr := range quota.Spec.ScopeSelector.MatchExpressions {
+				scopeSelector.MatchExpressions = append(scopeSelector.MatchExpressions,
+					types.ScopedResourceSelectorRequirement{
+						ScopeName: types.ResourceQuotaScope(expr.ScopeName),
+						Operator:  types.ScopeSelectorOperator(expr.Operator),
+						Values:    expr.Values,
+					})
+			}
+		}
+
+		resourceQuotas = append(resourceQuotas, types.ResourceQuota{
+			Hard:          hard,
+			Scopes:        scopes,
+			ScopeSelector: scopeSelector,
+		})
+	}
+
+	return &types.Namespace{
+		Name:           ns.Name,
+		Labels:         &labels,
+		ResourceQuotas: &resourceQuotas,
+	}
+}
+
+// convertDeployment convert the kubernetes deployment to cmdb deployment
+func convertDeployment(deploy *appsv1.Deployment) *types.Deployment {
+	result := &types.Deployment{
+		WorkloadBase:    types.WorkloadBase{Name: deploy.Name},
+		Labels:          mapPtr(deploy.Labels),
+		Selector:        convertLabelSelector(deploy.Spec.Selector),
+		Replicas:        int32PtrToInt64Ptr(deploy.Spec.Replicas),
+		MinReadySeconds: int64Ptr(int64(deploy.Spec.MinReadySeconds)),
+	}
+
+	if deploy.Spec.Strategy.Type != "" {
+		strategyType := types.DeploymentStrategyType(deploy.Spec.Strategy.Type)
+		result.StrategyType = &strategyType
+	}
+	if deploy.Spec.Strategy.RollingUpdate != nil {
+		result.RollingUpdateStrategy = &types.RollingUpdateDeployment{
+			MaxUnavailable: convertIntOrString(deploy.Spec.Strategy.RollingUpdate.MaxUnavailable),
+			MaxSurge:       convertIntOrString(deploy.Spec.Strategy.RollingUpdate.MaxSurge),
+		}
+	}
+	return result
+}
+
+// convertStatefulSet convert the kubernetes statefulSet to cmdb statefulSet
+func convertStatefulSet(sts *appsv1.StatefulSet) *types.StatefulSet {
+	result := &types.StatefulSet{
+		WorkloadBase:    types.WorkloadBase{Name: sts.Name},
+		Labels:          mapPtr(sts.Labels),
+		Selector:        convertLabelSelector(sts.Spec.Selector),
+		Replicas:        int32PtrToInt64Ptr(sts.Spec.Replicas),
+		MinReadySeconds: int64Ptr(int64(sts.Spec.MinReadySeconds)),
+	}
+
+	if sts.Spec.UpdateStrategy.Type != "" {
+		strategyType := types.StatefulSetUpdateStrategyType(sts.Spec.UpdateStrategy.Type)
+		result.StrategyType = &strategyType
+	}
+	if sts.Spec.UpdateStrategy.RollingUpdate != nil {
+		result.RollingUpdateStrategy = &types.RollingUpdateStatefulSetStrategy{
+			Partition:      sts.Spec.UpdateStrategy.RollingUpdate.Partition,
+			MaxUnavailable: convertIntOrString(sts.Spec.UpdateStrategy.RollingUpdate.MaxUnavailable),
+		}
+	}
+	return result
+}
+
+// convertDaemonSet convert the kubernetes daemonSet to cmdb daemonSet, the replicas is the desired number of the
+// scheduled pods
+func convertDaemonSet(ds *appsv1.DaemonSet) *types.DaemonSet {
+	result := &types.DaemonSet{
+		WorkloadBase:    types.WorkloadBase{Name: ds.Name},
+		Labels:          mapPtr(ds.Labels),
+		Selector:        convertLabelSelector(ds.Spec.Selector),
+		Replicas:        int64Ptr(int64(ds.Status.DesiredNumberScheduled)),
+		MinReadySeconds: int64Ptr(int64(ds.Spec.MinReadySeconds)),
+	}
+
+	if ds.Spec.UpdateStrategy.Type != "" {
+		strategyType := types.DaemonSetUpdateStrategyType(ds.Spec.UpdateStrategy.Type)
+		result.StrategyType = &strategyType
+	}
+	if ds.Spec.UpdateStrategy.RollingUpdate != nil {
+		result.RollingUpdateStrategy = &types.RollingUpdateDaemonSet{
+			MaxUnavailable: convertIntOrString(ds.Spec.UpdateStrategy.RollingUpdate.MaxUnavailable),
+			MaxSurge:       convertIntOrString(ds.Spec.UpdateStrategy.RollingUpdate.MaxSurge),
+		}
+	}
+	return result
+}
+
+// convertJob convert the kubernetes job to cmdb job, the replicas is the parallelism of the job
+func convertJob(job *batchv1.Job) *types.Job {
+	return &types.Job{
+		WorkloadBase: types.WorkloadBase{Name: job.Name},
+		Labels:       mapPtr(job.Labels),
+		Selector:     convertLabelSelector(job.Spec.Selector),
+		Replicas:     int32PtrToInt64Ptr(job.Spec.Parallelism),
+	}
+}
+
+// convertCronJob convert the kubernetes cronJob to cmdb cronJob
+func convertCronJob(cronJob *batchv1.CronJob) *types.CronJob {
+	return &types.CronJob{
+		WorkloadBase: types.WorkloadBase{Name: cronJob.Name},
+		Labels:       mapPtr(cronJob.Labels),
+	}
+}
+
+// convertGameDeployment convert the gameDeployment custom resource to cmdb gameDeployment
+func convertGameDeployment(obj *unstructured.Unstructured) *types.GameDeployment {
+	result := &types.GameDeployment{
+		WorkloadBase:    types.WorkloadBase{Name: obj.GetName()},
+		Labels:          mapPtr(obj.GetLabels()),
+		Selector:        unstructuredLabelSelector(obj),
+		Replicas:        unstructuredInt64(obj, "spec", "replicas"),
+		MinReadySeconds: unstructuredInt64(obj, "spec", "minReadySeconds"),
+	}
+
+	if strategyType, found, _ := unstructured.NestedString(obj.Object, "spec", "updateStrategy",
+		"type"); found && strategyType != "" {
+		typ := types.GameDeploymentUpdateStrategyType(strategyType)
+		result.StrategyType = &typ
+	}
+	// the rolling update params of gameDeployment are in the update strategy directly
+	path := []string{"spec", "updateStrategy"}
+	rollingUpdate := &types.RollingUpdateGameDeployment{
+		Partition:      int64PtrToInt32Ptr(unstructuredInt64(obj, append(path, "partition")...)),
+		MaxUnavailable: unstructuredIntOrString(obj, append(path, "maxUnavailable")...),
+		MaxSurge:       unstructuredIntOrString(obj, append(path, "maxSurge")...),
+	}
+	if rollingUpdate.Partition != nil || rollingUpdate.MaxUnavailable != nil || rollingUpdate.MaxSurge != nil {
+		result.RollingUpdateStrategy = rollingUpdate
+	}
+	return result
+}
+
+// convertGameStatefulSet convert the gameStatefulSet custom resource to cmdb gameStatefulSet
+func convertGameStatefulSet(obj *unstructured.Unstructured) *types.GameStatefulSet {
+	result := &types.GameStatefulSet{
+		WorkloadBase:    types.WorkloadBase{Name: obj.GetName()},
+		Labels:          mapPtr(obj.GetLabels()),
+		Selector:        unstructuredLabelSelector(obj),
+		Replicas:        unstructuredInt64(obj, "spec", "replicas"),
+		MinReadySeconds: unstructuredInt64(obj, "spec", "minReadySeconds"),
+	}
+
+	if strategyType, found, _ := unstructured.NestedString(obj.Object, "spec", "updateStrategy",
+		"type"); found && strategyType != "" {
+		typ := types.GameStatefulSetUpdateStrategyType(strategyType)
+		result.StrategyType = &typ
+	}
+	if _, found, _ := unstructured.NestedMap(obj.Object, "spec", "updateStrategy", "rollingUpdate"); found {
+		path := []string{"spec", "updateStrategy", "rollingUpdate"}
+		result.RollingUpdateStrategy = &types.RollingUpdateGameStatefulSetStrategy{
+			Partition:      int64PtrToInt32Ptr(unstructuredInt64(obj, append(path, "partition")...)),
+			MaxUnavailable: unstructuredIntOrString(obj, append(path, "maxUnavailable")...),
+			MaxSurge:       unstructuredIntOrString(obj, append(path, "maxSurge")...),
+		}
+	}
+	return result
+}
+
+// convertPod convert the kubernetes pod and its containers to cmdb pod, returns false if the containers are not all
+// created, because the container uid is required in cmdb. the cluster, namespace, workload and node info is set by
+// the syncer
+func convertPod(pod *corev1.Pod) (*types.Pod, []types.Container, bool) {
+	statuses := make(map[string]corev1.ContainerStatus, len(pod.Status.ContainerStatuses))
+	for _, status := range pod.Status.ContainerStatuses {
+		statuses[status.Name] = status
+	}
+
+	containers := make([]types.Container, 0, len(pod.Spec.Containers))
+	for _, container := range pod.Spec.Containers {
+		status, exists := statuses[container.Name]
+		if !exists || status.ContainerID == "" {
+			return nil, nil, false
+		}
+		containers = append(containers, convertContainer(&container, &status))
+	}
+
+	ips := make([]types.PodIP, 0, len(pod.Status.PodIPs))
+	for _, ip := range pod.Status.PodIPs {
+		ips = append(ips, types.PodIP{IP: ip.IP})
+	}
+
+	volumes := make([]types.Volume, 0, len(pod.Spec.Volumes))
+	for _, volume := range pod.Spec.Volumes {
+		// volume sources with resource quantities can not be converted, only the name of them is recorded
+		converted := types.Volume{}
+		if err := convertByJSON(volume, &converted); err != nil {
+			converted = types.Volume{Name: volume.Name}
+		}
+		volumes = append(volumes, converted)
+	}
+
+	tolerations := make([]types.Toleration, 0, len(pod.Spec.Tolerations))
+	if err := convertByJSON(pod.Spec.Tolerations, &tolerations); err != nil {
+		tolerations = make([]types.Toleration, 0)
+	}
+
+	qosClass := types.PodQOSClass(pod.Status.QOSClass)
+	result := &types.Pod{
+		Name:          stringPtr(pod.Name),
+		Priority:      pod.Spec.Priority,
+		Labels:        mapPtr(pod.Labels),
+		IP:            stringPtr(pod.Status.PodIP),
+		IPs:           &ips,
+		Volumes:       &volumes,
+		QOSClass:      &qosClass,
+		NodeSelectors: mapPtr(pod.Spec.NodeSelector),
+		Tolerations:   &tolerations,
+	}
+	return result, containers, true
+}
+
+// convertContainer convert the kubernetes container with its status to cmdb container
+func convertContainer(container *corev1.Container, status *corev1.ContainerStatus) types.Container {
+	ports := make([]types.ContainerPort, 0, len(container.Ports))
+	for _, port := range container.Ports {
+		ports = append(ports, types.ContainerPort{
+			Name:          port.Name,
+			HostPort:      port.HostPort,
+			ContainerPort: port.ContainerPort,
+			Protocol:      types.Protocol(port.Protocol),
+			HostIP:        port.HostIP,
+		})
+	}
+
+	env := make([]types.EnvVar, 0, len(container.Env))
+	for _, e := range container.Env {
+		env = append(env, types.EnvVar{Name: e.Name, Value: e.Value})
+	}
+
+	mounts := make([]types.VolumeMount, 0, len(container.VolumeMounts))
+	if err := convertByJSON(container.VolumeMounts, &mounts); err != nil {
+		mounts = make([]types.VolumeMount, 0)
+	}
+
+	args := append(append(make([]string, 0), container.Command...), container.Args...)
+	result := types.Container{
+		Name:        stringPtr(container.Name),
+		ContainerID: stringPtr(status.ContainerID),
+		Image:       stringPtr(container.Image),
+		Ports:       &ports,
+		Args:        &args,
+		Environment: &env,
+		Mounts:      &mounts,
+	}
+	if status.State.Running != nil {
+		result.Started = int64Ptr(status.State.Running.StartedAt.Unix())
+	}
+	return result
+}
+
+// convertLabelSelector convert the kubernetes label selector to cmdb label selector
+func convertLabelSelector(selector *metav1.LabelSelector) *types.LabelSelector {
+	if selector == nil {
+		return nil
+	}
+
+	result := &types.LabelSelector{
+		MatchLabels:      copyStringMap(selector.MatchLabels),
+		MatchExpressions: make([]types.LabelSelectorRequirement, 0, len(selector.MatchExpressions)),
+	}
+	for _, expr := range selector.MatchExpressions {
+		result.MatchExpressions = append(result.MatchExpressions, types.LabelSelectorRequirement{
+			Key:      expr.Key,
+			Operator: types.LabelSelectorOperator(expr.Operator),
+			Values:   expr.Values,
+		})
+	}
+	return result
+}
+
+// convertIntOrString convert the kubernetes int or string value to cmdb int or string value
+func convertIntOrString(val *intstr.IntOrString) *types.IntOrString {
+	if val == nil {
+		return nil
+	}
+
+	if val.Type == intstr.String {
+		return &types.IntOrString{Type: types.StringType, StrVal: val.StrVal}
+	}
+	return &types.IntOrString{Type: types.IntType, IntVal: val.IntVal}
+}
+
+// unstructuredLabelSelector get the label selector in the spec of the custom resource
+func unstructuredLabelSelector(obj *unstructured.Unstructured) *types.LabelSelector {
+	raw, found, err := unstructured.NestedMap(obj.Object, "spec", "selector")
+	if err != nil || !found {
+		return nil
+	}
+
+	selector := new(metav1.LabelSelector)
+	if err := runtime.DefaultUnstructuredConverter.FromUnstructured(raw, selector); err != nil {
+		return nil
+	}
+	return convertLabelSelector(selector)
+}
+
+// unstructuredInt64 get the integer value in the custom resource
+func unstructuredInt64(obj *unstructured.Unstructured, fields ...string) *int64 {
+	val, found, err := unstructured.NestedInt64(obj.Object, fields...)
+	if err != nil || !found {
+		return nil
+	}
+	return &val
+}
+
+// unstructuredIntOrString get the int or string value in the custom resource
+func unstructuredIntOrString(obj *unstructured.Unstructured, fields ...string) *types.IntOrString {
+	val, found, err := unstructured.NestedFieldNoCopy(obj.Object, fields...)
+	if err != nil || !found {
+		return nil
+	}
+
+	switch v := val.(type) {
+	case string:
+		return &types.IntOrString{Type: types.StringType, StrVal: v}
+	case int64:
+		return &types.IntOrString{Type: types.IntType, IntVal: int32(v)}
+	case float64:
+		return &types.IntOrString{Type: types.IntType, IntVal: int32(v)}
+	default:
+		return nil
+	}
+}
+
+// convertByJSON convert the kubernetes value to cmdb value with the same json format
+func convertByJSON(src, dst interface{}) error {
+	js, err := json.Marshal(src)
+	if err != nil {
+		return err
+	}
+	return json.Unmarshal(js, dst)
+}
+
+func copyStringMap(m map[string]string) map[string]string {
+	copied := make(map[string]string, len(m))
+	for k, v := range m {
+		copied[k] = v
+	}
+	return copied
+}
+
+func mapPtr(m map[string]string) *map[string]string {
+	copied := copyStringMap(m)
+	return &copied
+}
+
+func stringPtr(s string) *string {
+	return &s
+}
+
+func int64Ptr(i int64) *int64 {
+	return &i
+}
+
+func int32PtrToInt64Ptr(i *int32) *int64 {
+	if i == nil {
+		return nil
+	}
+	return int64Ptr(int64(*i))
+}
+
+func int64PtrToInt32Ptr(i *int64) *int32 {
+	if i == nil {
+		return nil
+	}
+	val := int32(*i)
+	return &val
+}
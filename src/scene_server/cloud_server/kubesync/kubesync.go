@@ -0,0 +1,162 @@
+/*
+ * Tencent is pleased to support the open source community by making 蓝鲸 available.
+ * Copyright (C) 2017-2018 THL A29 Limited, a Tencent company. All rights reserved.
+ * Licensed under the MIT License (the "License"); you may not use this file except
+ * in compliance with the License. You may obtain a copy of the License at
+ * http://opensource.org/licenses/MIT
+ * Unless required by applicable law or agreed to in writing, software distributed under
+ * the License is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND,
+ * either express or implied. See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+// Package kubesync synchronize the nodes, namespaces, workloads and pods of kubernetes clusters into the cmdb kube
+// resources by the kubernetes informers
+package kubesync
+
+import (
+	"context"
+	"time"
+
+	"configcenter/src/common"
+	"configcenter/src/common/backbone"
+	"configcenter/src/common/blog"
+	"configcenter/src/common/http/rest"
+	ccom "configcenter/src/scene_server/cloud_server/common"
+
+	"k8s.io/client-go/dynamic"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/tools/clientcmd"
+)
+
+const (
+	// defaultResyncPeriodSeconds the default period of the full reconcile
+	defaultResyncPeriodSeconds = 300
+	// minResyncPeriodSeconds the min period of the full reconcile
+	minResyncPeriodSeconds = 60
+	// checkMasterInterval the interval of checking if the cloud server is master
+	checkMasterInterval = 10 * time.Second
+	// retryInterval the interval of restarting the syncer of a cluster after it is failed
+	retryInterval = 30 * time.Second
+)
+
+// ClusterConf the config of a kubernetes cluster to be synchronized
+type ClusterConf struct {
+	// BizID the business id of the cmdb cluster
+	BizID int64
+	// ClusterID the id of the cmdb cluster that the kubernetes resources are synchronized into
+	ClusterID int64
+	// CloudID the cloud area id of the node hosts, the node is linked to the host by its internal ips in the area
+	CloudID int64
+	// Kubeconfig the kubeconfig file path of the kubernetes cluster
+	Kubeconfig string
+}
+
+// Conf the config of the kubernetes sync
+type Conf struct {
+	Enabled bool
+	// ResyncPeriodSeconds the period of the full reconcile, unit is second
+	ResyncPeriodSeconds int
+	Clusters            []ClusterConf
+}
+
+// KubeSync synchronize the configured kubernetes clusters when the cloud server is master, the syncers are stopped
+// when it is no longer master, and this function returns when the context is done.
+func KubeSync(ctx context.Context, engine *backbone.Engine, conf *Conf) {
+	if conf == nil || !conf.Enabled || len(conf.Clusters) == 0 {
+		blog.Infof("kubernetes sync is not enabled")
+		return
+	}
+
+	if conf.ResyncPeriodSeconds == 0 {
+		conf.ResyncPeriodSeconds = defaultResyncPeriodSeconds
+	}
+	if conf.ResyncPeriodSeconds < minResyncPeriodSeconds {
+		conf.ResyncPeriodSeconds = minResyncPeriodSeconds
+	}
+	resyncPeriod := time.Duration(conf.ResyncPeriodSeconds) * time.Second
+	blog.Infof("kubernetes sync is enabled, clusters: %d, resync period: %s", len(conf.Clusters), resyncPeriod)
+
+	var cancel context.CancelFunc
+	for {
+		isMaster := engine.Discovery().IsMaster()
+		if isMaster && cancel == nil {
+			var syncCtx context.Context
+			syncCtx, cancel = context.WithCancel(ctx)
+			for _, cluster := range conf.Clusters {
+				go runClusterSyncer(syncCtx, engine, cluster, resyncPeriod)
+			}
+		}
+
+		if !isMaster && cancel != nil {
+			blog.Infof("cloud server is no longer master, stop kubernetes sync")
+			cancel()
+			cancel = nil
+		}
+
+		select {
+		case <-ctx.Done():
+			if cancel != nil {
+				cancel()
+			}
+			return
+		case <-time.After(checkMasterInterval):
+		}
+	}
+}
+
+// runClusterSyncer run the syncer of the cluster until the context is done, the syncer is restarted after it is failed
+func runClusterSyncer(ctx context.Context, engine *backbone.Engine, conf ClusterConf, resyncPeriod time.Duration) {
+	for {
+		kit := ccom.NewWriteKit(common.BKDefaultOwnerID)
+		syncer, err := newClusterSyncer(kit, engine, conf, resyncPeriod)
+		if err == nil {
+			blog.Infof("start to synchronize cluster %d, rid: %s", conf.ClusterID, kit.Rid)
+			err = syncer.Run(ctx)
+		}
+
+		if err != nil {
+			blog.Errorf("synchronize cluster %d failed, retry after %s, err: %v, rid: %s", conf.ClusterID,
+				retryInterval, err, kit.Rid)
+		}
+
+		select {
+		case <-ctx.Done():
+			blog.Infof("stop synchronizing cluster %d", conf.ClusterID)
+			return
+		case <-time.After(retryInterval):
+		}
+	}
+}
+
+// newClusterSyncer new the syncer of the cluster by the kubeconfig
+func newClusterSyncer(kit *rest.Kit, engine *backbone.Engine, conf ClusterConf, resyncPeriod time.Duration) (
+	*ClusterSyncer, error) {
+
+	restConf, err := clientcmd.BuildConfigFromFlags("", conf.Kubeconfig)
+	if err != nil {
+		blog.Errorf("build config of cluster %d failed, kubeconfig: %s, err: %v, rid: %s", conf.ClusterID,
+			conf.Kubeconfig, err, kit.Rid)
+		return nil, err
+	}
+
+	client, err := kubernetes.NewForConfig(restConf)
+	if err != nil {
+		blog.Errorf("new client of cluster %d failed, err: %v, rid: %s", conf.ClusterID, err, kit.Rid)
+		return nil, err
+	}
+
+	dynamicClient, err := dynamic.NewForConfig(restConf)
+	if err != nil {
+		blog.Errorf("new dynamic client of cluster %d failed, err: %v, rid: %s", conf.ClusterID, err, kit.Rid)
+		return nil, err
+	}
+
+	cluster, err := GetCluster(kit, engine.CoreAPI, conf.BizID, conf.ClusterID)
+	if err != nil {
+		return nil, err
+	}
+
+	store := NewStore(engine.CoreAPI, cluster, conf.CloudID)
+	return NewClusterSyncer(cluster, store, client, dynamicClient, resyncPeriod), nil
+}
@@ -0,0 +1,821 @@
+/*
+ * Tencent is pleased to support the open source community by making 蓝鲸 available.
+ * Copyright (C) 2017-2018 THL A29 Limited, a Tencent company. All rights reserved.
+ * Licensed under the MIT License (the "License"); you may not use this file except
+ * in compliance with the License. You may obtain a copy of the License at
+ * http://opensource.org/licenses/MIT
+ * Unless required by applicable law or agreed to in writing, software distributed under
+ * the License is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND,
+ * either express or implied. See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package kubesync
+
+import (
+	"reflect"
+
+	"configcenter/src/common"
+	"configcenter/src/common/blog"
+	"configcenter/src/common/http/rest"
+	"configcenter/src/kube/types"
+	"configcenter/src/storage/dal/table"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/labels"
+)
+
+const (
+	// podsWorkloadName the name of the pods workload that the pods without workload in a namespace belong to
+	podsWorkloadName = "pods"
+	// maxWriteCount the max count of the resources that are written to cmdb in one request
+	maxWriteCount = 100
+)
+
+// workloadKinds the workload kinds that are synchronized, the pods workload is at last because it is created on
+// demand for the pods without workload
+var workloadKinds = []types.WorkloadType{types.KubeDeployment, types.KubeStatefulSet, types.KubeDaemonSet,
+	types.KubeGameDeployment, types.KubeGameStatefulSet, types.KubeCronJob, types.KubeJob, types.KubePodWorkload}
+
+// podInfo the pod with its containers
+type podInfo struct {
+	pod        *types.Pod
+	containers []types.Container
+}
+
+// clusterState the kube resources of a cluster, the namespaces, workloads and pods are indexed by "namespace/name"
+type clusterState struct {
+	nodes      map[string]*types.Node
+	namespaces map[string]*types.Namespace
+	workloads  map[types.WorkloadType]map[string]types.WorkloadInterface
+	pods       map[string]*podInfo
+}
+
+func newClusterState() *clusterState {
+	state := &clusterState{
+		nodes:      make(map[string]*types.Node),
+		namespaces: make(map[string]*types.Namespace),
+		workloads:  make(map[types.WorkloadType]map[string]types.WorkloadInterface),
+		pods:       make(map[string]*podInfo),
+	}
+	for _, kind := range workloadKinds {
+		state.workloads[kind] = make(map[string]types.WorkloadInterface)
+	}
+	return state
+}
+
+func resourceKey(namespace, name string) string {
+	return namespace + "/" + name
+}
+
+// syncKinds the workload kinds that are synchronized in the cluster, the game workloads are synchronized only if
+// their custom resources are installed
+func (s *ClusterSyncer) syncKinds() []types.WorkloadType {
+	kinds := make([]types.WorkloadType, 0, len(workloadKinds))
+	for _, kind := range workloadKinds {
+		if kind == types.KubeGameDeployment || kind == types.KubeGameStatefulSet {
+			if _, exists := s.gameListers[kind]; !exists {
+				continue
+			}
+		}
+		kinds = append(kinds, kind)
+	}
+	return kinds
+}
+
+// Reconcile reconcile the kubernetes resources in the informer caches into cmdb. the stale resources are deleted
+// from the bottom up, then the new and changed resources are created and updated from the top down. a failed phase
+// does not stop the following phases, and the first error is returned.
+func (s *ClusterSyncer) Reconcile(kit *rest.Kit) error {
+	desired, err := s.desiredState(kit)
+	if err != nil {
+		blog.Errorf("get kubernetes resources of cluster %d failed, err: %v, rid: %s", s.cluster.ID, err, kit.Rid)
+		return err
+	}
+
+	existing, err := s.existingState(kit)
+	if err != nil {
+		blog.Errorf("get cmdb resources of cluster %d failed, err: %v, rid: %s", s.cluster.ID, err, kit.Rid)
+		return err
+	}
+
+	phases := []func(kit *rest.Kit, desired, existing *clusterState) error{
+		s.deletePods,
+		s.deleteWorkloads,
+		s.deleteNamespaces,
+		s.deleteNodes,
+		s.syncNodes,
+		s.syncNamespaces,
+		s.syncWorkloads,
+		s.createPods,
+	}
+
+	var firstErr error
+	for _, phase := range phases {
+		if err := phase(kit, desired, existing); err != nil && firstErr == nil {
+			firstErr = err
+		}
+	}
+	return firstErr
+}
+
+// desiredState get the kubernetes resources from the informer caches and convert them to cmdb resources
+func (s *ClusterSyncer) desiredState(kit *rest.Kit) (*clusterState, error) {
+	state := newClusterState()
+
+	if err := s.desiredNodes(kit, state); err != nil {
+		return nil, err
+	}
+
+	if err := s.desiredNamespaces(state); err != nil {
+		return nil, err
+	}
+
+	for _, kind := range s.syncKinds() {
+		workloads, err := s.listWorkloads(kind)
+		if err != nil {
+			return nil, err
+		}
+		for key, workload := range workloads {
+			state.workloads[kind][key] = workload
+		}
+	}
+
+	if err := s.desiredPods(kit, state); err != nil {
+		return nil, err
+	}
+	return state, nil
+}
+
+// desiredNodes get the nodes, the node is linked to the host by its internal ips, and the nodes without host in
+// cmdb are not synchronized
+func (s *ClusterSyncer) desiredNodes(kit *rest.Kit, state *clusterState) error {
+	nodes, err := s.nodeLister.List(labels.Everything())
+	if err != nil {
+		return err
+	}
+
+	converted := make([]*types.Node, 0, len(nodes))
+	ips := make([]string, 0)
+	for _, node := range nodes {
+		cmdbNode := convertNode(node)
+		converted = append(converted, cmdbNode)
+		ips = append(ips, *cmdbNode.InternalIP...)
+	}
+
+	hostIDs, err := s.store.GetHostIDs(kit, ips)
+	if err != nil {
+		return err
+	}
+
+	for _, node := range converted {
+		for _, ip := range *node.InternalIP {
+			if hostID, exists := hostIDs[ip]; exists {
+				node.HostID = hostID
+				break
+			}
+		}
+
+		if node.HostID == 0 {
+			blog.Warnf("host of node %s in cluster %d is not found by internal ips %v, skip it, rid: %s", *node.Name,
+				s.cluster.ID, *node.InternalIP, kit.Rid)
+			continue
+		}
+		state.nodes[*node.Name] = node
+	}
+	return nil
+}
+
+// desiredNamespaces get the namespaces with their resource quotas
+func (s *ClusterSyncer) desiredNamespaces(state *clusterState) error {
+	namespaces, err := s.namespaceLister.List(labels.Everything())
+	if err != nil {
+		return err
+	}
+
+	quotas, err := s.quotaLister.List(labels.Everything())
+	if err != nil {
+		return err
+	}
+
+	nsQuotas := make(map[string][]*corev1.ResourceQuota)
+	for _, quota := range quotas {
+		nsQuotas[quota.Namespace] = append(nsQuotas[quota.Namespace], quota)
+	}
+
+	for _, ns := range namespaces {
+		state.namespaces[ns.Name] = convertNamespace(ns, nsQuotas[ns.Name])
+	}
+	return nil
+}
+
+// listWorkloads list the workloads of the kind from the informer caches, the pods workloads are created on demand
+// by the pods, so they are not listed
+func (s *ClusterSyncer) listWorkloads(kind types.WorkloadType) (map[string]types.WorkloadInterface, error) {
+	workloads := make(map[string]types.WorkloadInterface)
+	add := func(namespace string, workload types.WorkloadInterface) {
+		base := workload.GetWorkloadBase()
+		base.Namespace = namespace
+		workload.SetWorkloadBase(base)
+		workloads[resourceKey(namespace, base.Name)] = workload
+	}
+
+	switch kind {
+	case types.KubeDeployment:
+		list, err := s.deploymentLister.List(labels.Everything())
+		if err != nil {
+			return nil, err
+		}
+		for _, item := range list {
+			add(item.Namespace, convertDeployment(item))
+		}
+	case types.KubeStatefulSet:
+		list, err := s.statefulSetLister.List(labels.Everything())
+		if err != nil {
+			return nil, err
+		}
+		for _, item := range list {
+			add(item.Namespace, convertStatefulSet(item))
+		}
+	case types.KubeDaemonSet:
+		list, err := s.daemonSetLister.List(labels.Everything())
+		if err != nil {
+			return nil, err
+		}
+		for _, item := range list {
+			add(item.Namespace, convertDaemonSet(item))
+		}
+	case types.KubeJob:
+		list, err := s.jobLister.List(labels.Everything())
+		if err != nil {
+			return nil, err
+		}
+		for _, item := range list {
+			add(item.Namespace, convertJob(item))
+		}
+	case types.KubeCronJob:
+		list, err := s.cronJobLister.List(labels.Everything())
+		if err != nil {
+			return nil, err
+		}
+		for _, item := range list {
+			add(item.Namespace, convertCronJob(item))
+		}
+	case types.KubeGameDeployment, types.KubeGameStatefulSet:
+		list, err := s.gameListers[kind].List(labels.Everything())
+		if err != nil {
+			return nil, err
+		}
+		for _, item := range list {
+			obj, ok := item.(*unstructured.Unstructured)
+			if !ok {
+				continue
+			}
+			if kind == types.KubeGameDeployment {
+				add(obj.GetNamespace(), convertGameDeployment(obj))
+			} else {
+				add(obj.GetNamespace(), convertGameStatefulSet(obj))
+			}
+		}
+	}
+	return workloads, nil
+}
+
+// desiredPods get the pods that are scheduled to the synchronized nodes and whose containers are all created. the
+// pod is linked to its workload by the controller owner reference, and the pods without workload are linked to the
+// pods workload of the namespace
+func (s *ClusterSyncer) desiredPods(kit *rest.Kit, state *clusterState) error {
+	pods, err := s.podLister.List(labels.Everything())
+	if err != nil {
+		return err
+	}
+
+	for _, pod := range pods {
+		if pod.Spec.NodeName == "" {
+			continue
+		}
+
+		node, exists := state.nodes[pod.Spec.NodeName]
+		if !exists {
+			blog.V(4).Infof("node %s of pod %s/%s is not synchronized, skip it, rid: %s", pod.Spec.NodeName,
+				pod.Namespace, pod.Name, kit.Rid)
+			continue
+		}
+
+		cmdbPod, containers, ok := convertPod(pod)
+		if !ok {
+			continue
+		}
+
+		kind, name := s.podWorkload(pod)
+		workloadKey := resourceKey(pod.Namespace, name)
+		if kind == types.KubePodWorkload {
+			if _, exists := state.workloads[kind][workloadKey]; !exists {
+				state.workloads[kind][workloadKey] = &types.PodsWorkload{
+					WorkloadBase: types.WorkloadBase{
+						NamespaceSpec: types.NamespaceSpec{Namespace: pod.Namespace},
+						Name:          name,
+					},
+				}
+			}
+		}
+
+		if _, exists := state.workloads[kind][workloadKey]; !exists {
+			blog.V(4).Infof("%s workload %s of pod %s/%s is not synchronized, skip it, rid: %s", kind, workloadKey,
+				pod.Namespace, pod.Name, kit.Rid)
+			continue
+		}
+
+		cmdbPod.Namespace = pod.Namespace
+		cmdbPod.Ref = &types.Reference{Kind: kind, Name: name}
+		cmdbPod.Node = pod.Spec.NodeName
+		cmdbPod.HostID = node.HostID
+		cmdbPod.Operator = &[]string{common.BKCloudSyncUser}
+		state.pods[resourceKey(pod.Namespace, pod.Name)] = &podInfo{pod: cmdbPod, containers: containers}
+	}
+	return nil
+}
+
+// podWorkload get the kind and name of the workload that the pod belongs to by the controller owner reference, the
+// pods of deployments are owned by replicaSets, so the deployment is got from the owner reference of the replicaSet
+func (s *ClusterSyncer) podWorkload(pod *corev1.Pod) (types.WorkloadType, string) {
+	owner := metav1.GetControllerOf(pod)
+	if owner == nil {
+		return types.KubePodWorkload, podsWorkloadName
+	}
+
+	switch owner.Kind {
+	case "ReplicaSet":
+		rs, err := s.replicaSetLister.ReplicaSets(pod.Namespace).Get(owner.Name)
+		if err != nil {
+			return types.KubePodWorkload, podsWorkloadName
+		}
+		if rsOwner := metav1.GetControllerOf(rs); rsOwner != nil && rsOwner.Kind == "Deployment" {
+			return types.KubeDeployment, rsOwner.Name
+		}
+	case "StatefulSet":
+		return types.KubeStatefulSet, owner.Name
+	case "DaemonSet":
+		return types.KubeDaemonSet, owner.Name
+	case "Job":
+		return types.KubeJob, owner.Name
+	case "GameDeployment":
+		if _, exists := s.gameListers[types.KubeGameDeployment]; exists {
+			return types.KubeGameDeployment, owner.Name
+		}
+	case "GameStatefulSet":
+		if _, exists := s.gameListers[types.KubeGameStatefulSet]; exists {
+			return types.KubeGameStatefulSet, owner.Name
+		}
+	}
+	// the static pods owned by nodes and the pods owned by unknown controllers belong to the pods workload
+	return types.KubePodWorkload, podsWorkloadName
+}
+
+// existingState get the kube resources of the cluster in cmdb
+func (s *ClusterSyncer) existingState(kit *rest.Kit) (*clusterState, error) {
+	state := newClusterState()
+
+	nodes, err := s.store.ListNodes(kit)
+	if err != nil {
+		return nil, err
+	}
+	for idx := range nodes {
+		if nodes[idx].Name != nil {
+			state.nodes[*nodes[idx].Name] = &nodes[idx]
+		}
+	}
+
+	namespaces, err := s.store.ListNamespaces(kit)
+	if err != nil {
+		return nil, err
+	}
+	for idx := range namespaces {
+		state.namespaces[namespaces[idx].Name] = &namespaces[idx]
+	}
+
+	for _, kind := range s.syncKinds() {
+		workloads, err := s.store.ListWorkloads(kit, kind)
+		if err != nil {
+			return nil, err
+		}
+		for _, workload := range workloads {
+			base := workload.GetWorkloadBase()
+			state.workloads[kind][resourceKey(base.Namespace, base.Name)] = workload
+		}
+	}
+
+	pods, err := s.store.ListPods(kit)
+	if err != nil {
+		return nil, err
+	}
+	for idx := range pods {
+		if pods[idx].Name != nil {
+			state.pods[resourceKey(pods[idx].Namespace, *pods[idx].Name)] = &podInfo{pod: &pods[idx]}
+		}
+	}
+	return state, nil
+}
+
+// deletePods delete the pods that are not exist or are changed, because the pods can not be updated
+func (s *ClusterSyncer) deletePods(kit *rest.Kit, desired, existing *clusterState) error {
+	ids := make([]int64, 0)
+	keys := make([]string, 0)
+	for key, info := range existing.pods {
+		desiredInfo, exists := desired.pods[key]
+		if exists {
+			changed, err := podChanged(desiredInfo.pod, info.pod)
+			if err != nil {
+				blog.Errorf("compare pod %s failed, err: %v, rid: %s", key, err, kit.Rid)
+				return err
+			}
+			if !changed {
+				continue
+			}
+		}
+		ids = append(ids, info.pod.ID)
+		keys = append(keys, key)
+	}
+
+	if len(ids) == 0 {
+		return nil
+	}
+
+	if err := s.store.DeletePods(kit, ids); err != nil {
+		return err
+	}
+	for _, key := range keys {
+		delete(existing.pods, key)
+	}
+	blog.Infof("deleted %d pods of cluster %d, rid: %s", len(ids), s.cluster.ID, kit.Rid)
+	return nil
+}
+
+// podChanged check if the pod is moved to another host or workload, or its editable fields are changed
+func podChanged(desired, existing *types.Pod) (bool, error) {
+	if desired.HostID != existing.HostID || desired.Node != existing.Node {
+		return true, nil
+	}
+
+	if existing.Ref == nil || existing.Ref.Kind != desired.Ref.Kind || existing.Ref.Name != desired.Ref.Name {
+		return true, nil
+	}
+
+	return buildUpdateData(types.PodFields, desired, existing, new(types.Pod))
+}
+
+// deleteWorkloads delete the workloads that are not exist
+func (s *ClusterSyncer) deleteWorkloads(kit *rest.Kit, desired, existing *clusterState) error {
+	for _, kind := range s.syncKinds() {
+		ids := make([]int64, 0)
+		keys := make([]string, 0)
+		for key, workload := range existing.workloads[kind] {
+			if _, exists := desired.workloads[kind][key]; exists {
+				continue
+			}
+			ids = append(ids, workload.GetWorkloadBase().ID)
+			keys = append(keys, key)
+		}
+
+		if len(ids) == 0 {
+			continue
+		}
+
+		if err := s.store.DeleteWorkloads(kit, kind, ids); err != nil {
+			return err
+		}
+		for _, key := range keys {
+			delete(existing.workloads[kind], key)
+		}
+		blog.Infof("deleted %d %s workloads of cluster %d, rid: %s", len(ids), kind, s.cluster.ID, kit.Rid)
+	}
+	return nil
+}
+
+// deleteNamespaces delete the namespaces that are not exist
+func (s *ClusterSyncer) deleteNamespaces(kit *rest.Kit, desired, existing *clusterState) error {
+	ids := make([]int64, 0)
+	keys := make([]string, 0)
+	for key, ns := range existing.namespaces {
+		if _, exists := desired.namespaces[key]; exists {
+			continue
+		}
+		ids = append(ids, ns.ID)
+		keys = append(keys, key)
+	}
+
+	if len(ids) == 0 {
+		return nil
+	}
+
+	if err := s.store.DeleteNamespaces(kit, ids); err != nil {
+		return err
+	}
+	for _, key := range keys {
+		delete(existing.namespaces, key)
+	}
+	blog.Infof("deleted %d namespaces of cluster %d, rid: %s", len(ids), s.cluster.ID, kit.Rid)
+	return nil
+}
+
+// deleteNodes delete the nodes that are not exist or are moved to another host, because the host of node can not
+// be updated
+func (s *ClusterSyncer) deleteNodes(kit *rest.Kit, desired, existing *clusterState) error {
+	ids := make([]int64, 0)
+	keys := make([]string, 0)
+	for key, node := range existing.nodes {
+		if desiredNode, exists := desired.nodes[key]; exists && desiredNode.HostID == node.HostID {
+			continue
+		}
+		ids = append(ids, node.ID)
+		keys = append(keys, key)
+	}
+
+	if len(ids) == 0 {
+		return nil
+	}
+
+	if err := s.store.DeleteNodes(kit, ids); err != nil {
+		return err
+	}
+	for _, key := range keys {
+		delete(existing.nodes, key)
+	}
+	blog.Infof("deleted %d nodes of cluster %d, rid: %s", len(ids), s.cluster.ID, kit.Rid)
+	return nil
+}
+
+// syncNodes create the new nodes and update the changed nodes
+func (s *ClusterSyncer) syncNodes(kit *rest.Kit, desired, existing *clusterState) error {
+	keys := make([]string, 0)
+	creates := make([]types.OneNodeCreateOption, 0)
+	for key, node := range desired.nodes {
+		existingNode, exists := existing.nodes[key]
+		if !exists {
+			keys = append(keys, key)
+			creates = append(creates, types.OneNodeCreateOption{
+				BizID:     s.cluster.BizID,
+				HostID:    node.HostID,
+				ClusterID: s.cluster.ID,
+				Node:      *node,
+			})
+			continue
+		}
+
+		update := new(types.Node)
+		changed, err := buildUpdateData(types.NodeFields, node, existingNode, update)
+		if err != nil {
+			blog.Errorf("compare node %s failed, err: %v, rid: %s", key, err, kit.Rid)
+			return err
+		}
+		if !changed {
+			continue
+		}
+		if err := s.store.UpdateNode(kit, existingNode.ID, update); err != nil {
+			return err
+		}
+	}
+
+	if len(creates) == 0 {
+		return nil
+	}
+
+	ids, err := s.store.CreateNodes(kit, creates)
+	if err != nil {
+		return err
+	}
+	for idx, id := range ids {
+		node := desired.nodes[keys[idx]]
+		node.ID = id
+		existing.nodes[keys[idx]] = node
+	}
+	blog.Infof("created %d nodes of cluster %d, rid: %s", len(ids), s.cluster.ID, kit.Rid)
+	return nil
+}
+
+// syncNamespaces create the new namespaces and update the changed namespaces
+func (s *ClusterSyncer) syncNamespaces(kit *rest.Kit, desired, existing *clusterState) error {
+	keys := make([]string, 0)
+	creates := make([]types.Namespace, 0)
+	for key, ns := range desired.namespaces {
+		existingNs, exists := existing.namespaces[key]
+		if !exists {
+			ns.ClusterSpec = s.clusterSpec()
+			keys = append(keys, key)
+			creates = append(creates, *ns)
+			continue
+		}
+
+		update := new(types.Namespace)
+		changed, err := buildUpdateData(types.NamespaceFields, ns, existingNs, update)
+		if err != nil {
+			blog.Errorf("compare namespace %s failed, err: %v, rid: %s", key, err, kit.Rid)
+			return err
+		}
+		if !changed {
+			continue
+		}
+		if err := s.store.UpdateNamespace(kit, existingNs.ID, update); err != nil {
+			return err
+		}
+	}
+
+	if len(creates) == 0 {
+		return nil
+	}
+
+	ids, err := s.store.CreateNamespaces(kit, creates)
+	if err != nil {
+		return err
+	}
+	for idx, id := range ids {
+		ns := desired.namespaces[keys[idx]]
+		ns.ID = id
+		existing.namespaces[keys[idx]] = ns
+	}
+	blog.Infof("created %d namespaces of cluster %d, rid: %s", len(ids), s.cluster.ID, kit.Rid)
+	return nil
+}
+
+// syncWorkloads create the new workloads and update the changed workloads, the workloads whose namespace is not
+// synchronized are skipped
+func (s *ClusterSyncer) syncWorkloads(kit *rest.Kit, desired, existing *clusterState) error {
+	for _, kind := range s.syncKinds() {
+		fields, err := kind.Fields()
+		if err != nil {
+			return err
+		}
+
+		keys := make([]string, 0)
+		creates := make([]types.WorkloadInterface, 0)
+		for key, workload := range desired.workloads[kind] {
+			existingWl, exists := existing.workloads[kind][key]
+			if !exists {
+				base := workload.GetWorkloadBase()
+				ns, exists := existing.namespaces[base.Namespace]
+				if !exists {
+					continue
+				}
+				base.NamespaceSpec = types.NamespaceSpec{
+					ClusterSpec: s.clusterSpec(),
+					NamespaceID: ns.ID,
+					Namespace:   ns.Name,
+				}
+				workload.SetWorkloadBase(base)
+				keys = append(keys, key)
+				creates = append(creates, workload)
+				continue
+			}
+
+			update, err := kind.NewInst()
+			if err != nil {
+				return err
+			}
+			changed, err := buildUpdateData(fields, workload, existingWl, update)
+			if err != nil {
+				blog.Errorf("compare %s workload %s failed, err: %v, rid: %s", kind, key, err, kit.Rid)
+				return err
+			}
+			if !changed {
+				continue
+			}
+			if err := s.store.UpdateWorkload(kit, kind, existingWl.GetWorkloadBase().ID, update); err != nil {
+				return err
+			}
+		}
+
+		if len(creates) == 0 {
+			continue
+		}
+
+		ids, err := s.store.CreateWorkloads(kit, kind, creates)
+		if err != nil {
+			return err
+		}
+		for idx, id := range ids {
+			workload := desired.workloads[kind][keys[idx]]
+			base := workload.GetWorkloadBase()
+			base.ID = id
+			workload.SetWorkloadBase(base)
+			existing.workloads[kind][keys[idx]] = workload
+		}
+		blog.Infof("created %d %s workloads of cluster %d, rid: %s", len(ids), kind, s.cluster.ID, kit.Rid)
+	}
+	return nil
+}
+
+// createPods create the new pods, the pods whose namespace, workload or node is not synchronized are skipped
+func (s *ClusterSyncer) createPods(kit *rest.Kit, desired, existing *clusterState) error {
+	creates := make([]types.PodsInfo, 0)
+	for key, info := range desired.pods {
+		if _, exists := existing.pods[key]; exists {
+			continue
+		}
+
+		pod := info.pod
+		ns, nsExists := existing.namespaces[pod.Namespace]
+		workload, wlExists := existing.workloads[pod.Ref.Kind][resourceKey(pod.Namespace, pod.Ref.Name)]
+		node, nodeExists := existing.nodes[pod.Node]
+		if !nsExists || !wlExists || !nodeExists {
+			continue
+		}
+
+		data := *pod
+		data.SysSpec = types.SysSpec{}
+		creates = append(creates, types.PodsInfo{
+			Spec: types.SpecSimpleInfo{
+				ClusterID:   s.cluster.ID,
+				NamespaceID: ns.ID,
+				Ref: types.Reference{
+					Kind: pod.Ref.Kind,
+					Name: pod.Ref.Name,
+					ID:   workload.GetWorkloadBase().ID,
+				},
+				NodeID: node.ID,
+			},
+			HostID:     node.HostID,
+			Pod:        data,
+			Containers: info.containers,
+		})
+	}
+
+	if len(creates) == 0 {
+		return nil
+	}
+
+	ids, err := s.store.CreatePods(kit, creates)
+	if err != nil {
+		return err
+	}
+	blog.Infof("created %d pods of cluster %d, rid: %s", len(ids), s.cluster.ID, kit.Rid)
+	return nil
+}
+
+func (s *ClusterSyncer) clusterSpec() types.ClusterSpec {
+	return types.ClusterSpec{BizID: s.cluster.BizID, ClusterID: s.cluster.ID, ClusterUID: s.cluster.UID}
+}
+
+// buildUpdateData compare the editable fields of the desired and existing resources, returns whether they are
+// changed, and set the editable fields of the desired resource to the update data if changed. the fields that are
+// not set in the desired resource are not compared, and the fields maintained by cmdb or users are ignored.
+func buildUpdateData(fields *table.Fields, desired, existing, update interface{}) (bool, error) {
+	desiredData := make(map[string]interface{})
+	if err := convertByJSON(desired, &desiredData); err != nil {
+		return false, err
+	}
+
+	existingData := make(map[string]interface{})
+	if err := convertByJSON(existing, &existingData); err != nil {
+		return false, err
+	}
+
+	updateData := make(map[string]interface{})
+	changed := false
+	for field := range fields.EditableFields() {
+		switch field {
+		case types.ModifierField, types.LastTimeField, types.OperatorField:
+			continue
+		case types.RefField:
+			// the workload id in the reference is set by cmdb, the reference of pod is compared by podChanged
+			continue
+		}
+
+		value, exists := desiredData[field]
+		if !exists {
+			continue
+		}
+		updateData[field] = value
+		if !valueEqual(value, existingData[field]) {
+			changed = true
+		}
+	}
+
+	if !changed {
+		return false, nil
+	}
+	return true, convertByJSON(updateData, update)
+}
+
+// valueEqual check if the json decoded values are equal, the empty values are treated as equal
+func valueEqual(a, b interface{}) bool {
+	if isEmptyValue(a) && isEmptyValue(b) {
+		return true
+	}
+	return reflect.DeepEqual(a, b)
+}
+
+func isEmptyValue(val interface{}) bool {
+	switch v := val.(type) {
+	case nil:
+		return true
+	case string:
+		return v == ""
+	case map[string]interface{}:
+		return len(v) == 0
+	case []interface{}:
+		return len(v) == 0
+	}
+	return false
+}
@@ -0,0 +1,498 @@
+/*
+ * Tencent is pleased to support the open source community by making 蓝鲸 available.
+ * Copyright (C) 2017-2018 THL A29 Limited, a Tencent company. All rights reserved.
+ * Licensed under the MIT License (the "License"); you may not use this file except
+ * in compliance with the License. You may obtain a copy of the License at
+ * http://opensource.org/licenses/MIT
+ * Unless required by applicable law or agreed to in writing, software distributed under
+ * the License is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND,
+ * either express or implied. See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package kubesync
+
+import (
+	"fmt"
+	"strings"
+
+	"configcenter/pkg/filter"
+	"configcenter/src/apimachinery"
+	"configcenter/src/common"
+	"configcenter/src/common/blog"
+	"configcenter/src/common/http/rest"
+	"configcenter/src/common/json"
+	"configcenter/src/common/mapstr"
+	"configcenter/src/common/metadata"
+	"configcenter/src/kube/types"
+
+	"github.com/tidwall/gjson"
+)
+
+// Cluster the cmdb cluster that the kubernetes resources are synchronized into
+type Cluster struct {
+	BizID int64
+	ID    int64
+	UID   string
+}
+
+// Store the cmdb kube resources of a cluster, the syncer reconciles the kubernetes resources into it
+type Store interface {
+	ListNodes(kit *rest.Kit) ([]types.Node, error)
+	CreateNodes(kit *rest.Kit, nodes []types.OneNodeCreateOption) ([]int64, error)
+	UpdateNode(kit *rest.Kit, id int64, node *types.Node) error
+	DeleteNodes(kit *rest.Kit, ids []int64) error
+
+	ListNamespaces(kit *rest.Kit) ([]types.Namespace, error)
+	CreateNamespaces(kit *rest.Kit, namespaces []types.Namespace) ([]int64, error)
+	UpdateNamespace(kit *rest.Kit, id int64, namespace *types.Namespace) error
+	DeleteNamespaces(kit *rest.Kit, ids []int64) error
+
+	ListWorkloads(kit *rest.Kit, kind types.WorkloadType) ([]types.WorkloadInterface, error)
+	CreateWorkloads(kit *rest.Kit, kind types.WorkloadType, workloads []types.WorkloadInterface) ([]int64, error)
+	UpdateWorkload(kit *rest.Kit, kind types.WorkloadType, id int64, workload types.WorkloadInterface) error
+	DeleteWorkloads(kit *rest.Kit, kind types.WorkloadType, ids []int64) error
+
+	// ListPods list the pods of the cluster, pods can not be updated, a changed pod is deleted and created again
+	ListPods(kit *rest.Kit) ([]types.Pod, error)
+	CreatePods(kit *rest.Kit, pods []types.PodsInfo) ([]int64, error)
+	DeletePods(kit *rest.Kit, ids []int64) error
+
+	// GetHostIDs get the ids of the hosts whose inner ips are in the ips, returns the map of inner ip to host id
+	GetHostIDs(kit *rest.Kit, ips []string) (map[string]int64, error)
+}
+
+// GetCluster get the cmdb cluster by business id and cluster id
+func GetCluster(kit *rest.Kit, clientSet apimachinery.ClientSetInterface, bizID, clusterID int64) (*Cluster,
+	error) {
+
+	opt := &types.QueryClusterOption{
+		BizID:  bizID,
+		Filter: equalFilter(types.BKIDField, clusterID),
+		Page:   metadata.BasePage{Limit: 1},
+	}
+	res, err := clientSet.TopoServer().Kube().SearchCluster(kit.Ctx, kit.Header, opt)
+	if err != nil {
+		blog.Errorf("search cluster %d failed, biz: %d, err: %v, rid: %s", clusterID, bizID, err, kit.Rid)
+		return nil, err
+	}
+
+	clusters := make([]types.Cluster, 0)
+	if err := decodeResponseInfo(res.Data, &clusters); err != nil {
+		blog.Errorf("decode cluster %d failed, err: %v, rid: %s", clusterID, err, kit.Rid)
+		return nil, err
+	}
+
+	if len(clusters) == 0 {
+		return nil, fmt.Errorf("cluster %d is not exist in biz %d", clusterID, bizID)
+	}
+
+	cluster := &Cluster{BizID: bizID, ID: clusterID}
+	if clusters[0].Uid != nil {
+		cluster.UID = *clusters[0].Uid
+	}
+	return cluster, nil
+}
+
+// topoStore the Store implementation by the kube apis of topo server
+type topoStore struct {
+	clientSet apimachinery.ClientSetInterface
+	cluster   *Cluster
+	cloudID   int64
+}
+
+// NewStore new the Store of the cluster by the kube apis of topo server, the node hosts are searched in the cloud area
+func NewStore(clientSet apimachinery.ClientSetInterface, cluster *Cluster, cloudID int64) Store {
+	return &topoStore{
+		clientSet: clientSet,
+		cluster:   cluster,
+		cloudID:   cloudID,
+	}
+}
+
+// ListNodes list the nodes of the cluster
+func (s *topoStore) ListNodes(kit *rest.Kit) ([]types.Node, error) {
+	nodes := make([]types.Node, 0)
+	for start := 0; ; start += common.BKMaxLimitSize {
+		opt := &types.QueryNodeOption{
+			BizID:  s.cluster.BizID,
+			Filter: equalFilter(types.BKClusterIDFiled, s.cluster.ID),
+			Page:   metadata.BasePage{Start: start, Limit: common.BKMaxLimitSize, Sort: types.BKIDField},
+		}
+		res, err := s.clientSet.TopoServer().Kube().SearchNode(kit.Ctx, kit.Header, opt)
+		if err != nil {
+			blog.Errorf("search nodes of cluster %d failed, err: %v, rid: %s", s.cluster.ID, err, kit.Rid)
+			return nil, err
+		}
+
+		page := make([]types.Node, 0)
+		if err := decodeResponseInfo(res.Data, &page); err != nil {
+			blog.Errorf("decode nodes of cluster %d failed, err: %v, rid: %s", s.cluster.ID, err, kit.Rid)
+			return nil, err
+		}
+
+		nodes = append(nodes, page...)
+		if len(page) < common.BKMaxLimitSize {
+			return nodes, nil
+		}
+	}
+}
+
+// CreateNodes create nodes of the cluster
+func (s *topoStore) CreateNodes(kit *rest.Kit, nodes []types.OneNodeCreateOption) ([]int64, error) {
+	ids := make([]int64, 0, len(nodes))
+	for start := 0; start < len(nodes); start += maxWriteCount {
+		end := minInt(start+maxWriteCount, len(nodes))
+		opt := &types.CreateNodesOption{BizID: s.cluster.BizID, Nodes: nodes[start:end]}
+		created, err := s.clientSet.TopoServer().Kube().BatchCreateNode(kit.Ctx, kit.Header, opt)
+		if err != nil {
+			blog.Errorf("create nodes of cluster %d failed, err: %v, rid: %s", s.cluster.ID, err, kit.Rid)
+			return nil, err
+		}
+		ids = append(ids, created...)
+	}
+	return ids, nil
+}
+
+// UpdateNode update the editable fields of the node
+func (s *topoStore) UpdateNode(kit *rest.Kit, id int64, node *types.Node) error {
+	opt := &types.UpdateNodeOption{
+		BizID:                 s.cluster.BizID,
+		UpdateNodeByIDsOption: types.UpdateNodeByIDsOption{IDs: []int64{id}, Data: *node},
+	}
+	if err := s.clientSet.TopoServer().Kube().UpdateNodeFields(kit.Ctx, kit.Header, opt); err != nil {
+		blog.Errorf("update node %d failed, err: %v, rid: %s", id, err, kit.Rid)
+		return err
+	}
+	return nil
+}
+
+// DeleteNodes delete nodes of the cluster
+func (s *topoStore) DeleteNodes(kit *rest.Kit, ids []int64) error {
+	for start := 0; start < len(ids); start += maxWriteCount {
+		end := minInt(start+maxWriteCount, len(ids))
+		opt := &types.BatchDeleteNodeOption{
+			BizID:                      s.cluster.BizID,
+			BatchDeleteNodeByIDsOption: types.BatchDeleteNodeByIDsOption{IDs: ids[start:end]},
+		}
+		if err := s.clientSet.TopoServer().Kube().BatchDeleteNode(kit.Ctx, kit.Header, opt); err != nil {
+			blog.Errorf("delete nodes %v failed, err: %v, rid: %s", ids[start:end], err, kit.Rid)
+			return err
+		}
+	}
+	return nil
+}
+
+// ListNamespaces list the namespaces of the cluster
+func (s *topoStore) ListNamespaces(kit *rest.Kit) ([]types.Namespace, error) {
+	namespaces := make([]types.Namespace, 0)
+	for start := 0; ; start += common.BKMaxLimitSize {
+		opt := &types.NsQueryOption{
+			BizID:  s.cluster.BizID,
+			Filter: equalFilter(types.BKClusterIDFiled, s.cluster.ID),
+			Page:   metadata.BasePage{Start: start, Limit: common.BKMaxLimitSize, Sort: types.BKIDField},
+		}
+		res, err := s.clientSet.TopoServer().Kube().ListNamespace(kit.Ctx, kit.Header, opt)
+		if err != nil {
+			blog.Errorf("list namespaces of cluster %d failed, err: %v, rid: %s", s.cluster.ID, err, kit.Rid)
+			return nil, err
+		}
+
+		page := make([]types.Namespace, 0)
+		if err := decodeInfo(res.Info, &page); err != nil {
+			blog.Errorf("decode namespaces of cluster %d failed, err: %v, rid: %s", s.cluster.ID, err, kit.Rid)
+			return nil, err
+		}
+
+		namespaces = append(namespaces, page...)
+		if len(page) < common.BKMaxLimitSize {
+			return namespaces, nil
+		}
+	}
+}
+
+// CreateNamespaces create namespaces of the cluster
+func (s *topoStore) CreateNamespaces(kit *rest.Kit, namespaces []types.Namespace) ([]int64, error) {
+	ids := make([]int64, 0, len(namespaces))
+	for start := 0; start < len(namespaces); start += maxWriteCount {
+		end := minInt(start+maxWriteCount, len(namespaces))
+		opt := &types.NsCreateOption{BizID: s.cluster.BizID, Data: namespaces[start:end]}
+		res, err := s.clientSet.TopoServer().Kube().CreateNamespace(kit.Ctx, kit.Header, opt)
+		if err != nil {
+			blog.Errorf("create namespaces of cluster %d failed, err: %v, rid: %s", s.cluster.ID, err, kit.Rid)
+			return nil, err
+		}
+		ids = append(ids, res.IDs...)
+	}
+	return ids, nil
+}
+
+// UpdateNamespace update the editable fields of the namespace
+func (s *topoStore) UpdateNamespace(kit *rest.Kit, id int64, namespace *types.Namespace) error {
+	opt := &types.NsUpdateOption{
+		BizID:               s.cluster.BizID,
+		NsUpdateByIDsOption: types.NsUpdateByIDsOption{IDs: []int64{id}, Data: namespace},
+	}
+	if err := s.clientSet.TopoServer().Kube().UpdateNamespace(kit.Ctx, kit.Header, opt); err != nil {
+		blog.Errorf("update namespace %d failed, err: %v, rid: %s", id, err, kit.Rid)
+		return err
+	}
+	return nil
+}
+
+// DeleteNamespaces delete namespaces of the cluster
+func (s *topoStore) DeleteNamespaces(kit *rest.Kit, ids []int64) error {
+	for start := 0; start < len(ids); start += maxWriteCount {
+		end := minInt(start+maxWriteCount, len(ids))
+		opt := &types.NsDeleteOption{
+			BizID:               s.cluster.BizID,
+			NsDeleteByIDsOption: types.NsDeleteByIDsOption{IDs: ids[start:end]},
+		}
+		if err := s.clientSet.TopoServer().Kube().DeleteNamespace(kit.Ctx, kit.Header, opt); err != nil {
+			blog.Errorf("delete namespaces %v failed, err: %v, rid: %s", ids[start:end], err, kit.Rid)
+			return err
+		}
+	}
+	return nil
+}
+
+// ListWorkloads list the workloads of the cluster
+func (s *topoStore) ListWorkloads(kit *rest.Kit, kind types.WorkloadType) ([]types.WorkloadInterface, error) {
+	workloads := make([]types.WorkloadInterface, 0)
+	for start := 0; ; start += common.BKMaxLimitSize {
+		opt := &types.WlQueryOption{
+			BizID:  s.cluster.BizID,
+			Filter: equalFilter(types.BKClusterIDFiled, s.cluster.ID),
+			Page:   metadata.BasePage{Start: start, Limit: common.BKMaxLimitSize, Sort: types.BKIDField},
+		}
+		res, err := s.clientSet.TopoServer().Kube().ListWorkload(kit.Ctx, kit.Header, kind, opt)
+		if err != nil {
+			blog.Errorf("list %s workloads of cluster %d failed, err: %v, rid: %s", kind, s.cluster.ID, err, kit.Rid)
+			return nil, err
+		}
+
+		js, jsErr := json.Marshal(res.Info)
+		if jsErr != nil {
+			return nil, jsErr
+		}
+		page, jsErr := types.WlArrayUnmarshalJSON(kind, js)
+		if jsErr != nil {
+			blog.Errorf("decode %s workloads of cluster %d failed, err: %v, rid: %s", kind, s.cluster.ID, jsErr,
+				kit.Rid)
+			return nil, jsErr
+		}
+
+		workloads = append(workloads, page...)
+		if len(page) < common.BKMaxLimitSize {
+			return workloads, nil
+		}
+	}
+}
+
+// CreateWorkloads create workloads of the cluster
+func (s *topoStore) CreateWorkloads(kit *rest.Kit, kind types.WorkloadType, workloads []types.WorkloadInterface) (
+	[]int64, error) {
+
+	ids := make([]int64, 0, len(workloads))
+	for start := 0; start < len(workloads); start += maxWriteCount {
+		end := minInt(start+maxWriteCount, len(workloads))
+		opt := &types.WlCreateOption{BizID: s.cluster.BizID, Kind: kind, Data: workloads[start:end]}
+		res, err := s.clientSet.TopoServer().Kube().CreateWorkload(kit.Ctx, kit.Header, kind, opt)
+		if err != nil {
+			blog.Errorf("create %s workloads of cluster %d failed, err: %v, rid: %s", kind, s.cluster.ID, err,
+				kit.Rid)
+			return nil, err
+		}
+		ids = append(ids, res.IDs...)
+	}
+	return ids, nil
+}
+
+// UpdateWorkload update the editable fields of the workload
+func (s *topoStore) UpdateWorkload(kit *rest.Kit, kind types.WorkloadType, id int64,
+	workload types.WorkloadInterface) error {
+
+	opt := &types.WlUpdateOption{
+		BizID:               s.cluster.BizID,
+		WlUpdateByIDsOption: types.WlUpdateByIDsOption{Kind: kind, IDs: []int64{id}, Data: workload},
+	}
+	if err := s.clientSet.TopoServer().Kube().UpdateWorkload(kit.Ctx, kit.Header, kind, opt); err != nil {
+		blog.Errorf("update %s workload %d failed, err: %v, rid: %s", kind, id, err, kit.Rid)
+		return err
+	}
+	return nil
+}
+
+// DeleteWorkloads delete workloads of the cluster
+func (s *topoStore) DeleteWorkloads(kit *rest.Kit, kind types.WorkloadType, ids []int64) error {
+	for start := 0; start < len(ids); start += maxWriteCount {
+		end := minInt(start+maxWriteCount, len(ids))
+		opt := &types.WlDeleteOption{
+			BizID:               s.cluster.BizID,
+			WlDeleteByIDsOption: types.WlDeleteByIDsOption{IDs: ids[start:end]},
+		}
+		if err := s.clientSet.TopoServer().Kube().DeleteWorkload(kit.Ctx, kit.Header, kind, opt); err != nil {
+			blog.Errorf("delete %s workloads %v failed, err: %v, rid: %s", kind, ids[start:end], err, kit.Rid)
+			return err
+		}
+	}
+	return nil
+}
+
+// ListPods list the pods of the cluster
+func (s *topoStore) ListPods(kit *rest.Kit) ([]types.Pod, error) {
+	pods := make([]types.Pod, 0)
+	for start := 0; ; start += common.BKMaxLimitSize {
+		opt := &types.PodQueryOption{
+			BizID:  s.cluster.BizID,
+			Filter: equalFilter(types.BKClusterIDFiled, s.cluster.ID),
+			Page:   metadata.BasePage{Start: start, Limit: common.BKMaxLimitSize, Sort: types.BKIDField},
+		}
+		res, err := s.clientSet.TopoServer().Kube().ListPod(kit.Ctx, kit.Header, opt)
+		if err != nil {
+			blog.Errorf("list pods of cluster %d failed, err: %v, rid: %s", s.cluster.ID, err, kit.Rid)
+			return nil, err
+		}
+
+		page := make([]types.Pod, 0)
+		if err := decodeInfo(res.Info, &page); err != nil {
+			blog.Errorf("decode pods of cluster %d failed, err: %v, rid: %s", s.cluster.ID, err, kit.Rid)
+			return nil, err
+		}
+
+		pods = append(pods, page...)
+		if len(page) < common.BKMaxLimitSize {
+			return pods, nil
+		}
+	}
+}
+
+// CreatePods create pods of the cluster with their containers
+func (s *topoStore) CreatePods(kit *rest.Kit, pods []types.PodsInfo) ([]int64, error) {
+	ids := make([]int64, 0, len(pods))
+	for start := 0; start < len(pods); start += maxWriteCount {
+		end := minInt(start+maxWriteCount, len(pods))
+		opt := &types.CreatePodsOption{
+			Data: []types.PodsInfoArray{{BizID: s.cluster.BizID, Pods: pods[start:end]}},
+		}
+		created, err := s.clientSet.TopoServer().Kube().BatchCreatePod(kit.Ctx, kit.Header, opt)
+		if err != nil {
+			blog.Errorf("create pods of cluster %d failed, err: %v, rid: %s", s.cluster.ID, err, kit.Rid)
+			return nil, err
+		}
+		ids = append(ids, created...)
+	}
+	return ids, nil
+}
+
+// DeletePods delete pods of the cluster with their containers
+func (s *topoStore) DeletePods(kit *rest.Kit, ids []int64) error {
+	for start := 0; start < len(ids); start += maxWriteCount {
+		end := minInt(start+maxWriteCount, len(ids))
+		opt := &types.DeletePodsOption{
+			Data: []types.DeletePodData{{BizID: s.cluster.BizID, PodIDs: ids[start:end]}},
+		}
+		if err := s.clientSet.TopoServer().Kube().DeletePods(kit.Ctx, kit.Header, opt); err != nil {
+			blog.Errorf("delete pods %v failed, err: %v, rid: %s", ids[start:end], err, kit.Rid)
+			return err
+		}
+	}
+	return nil
+}
+
+// GetHostIDs get the ids of the hosts in the cloud area whose inner ips are in the ips
+func (s *topoStore) GetHostIDs(kit *rest.Kit, ips []string) (map[string]int64, error) {
+	hostIDs := make(map[string]int64)
+	if len(ips) == 0 {
+		return hostIDs, nil
+	}
+
+	query := &metadata.QueryCondition{
+		Condition: mapstr.MapStr{
+			common.BKHostInnerIPField: mapstr.MapStr{common.BKDBIN: ips},
+			common.BKCloudIDField:     s.cloudID,
+		},
+		Fields: []string{common.BKHostIDField, common.BKHostInnerIPField},
+		Page:   metadata.BasePage{Limit: common.BKNoLimit},
+	}
+	res, err := s.clientSet.CoreService().Instance().ReadInstance(kit.Ctx, kit.Header, common.BKInnerObjIDHost,
+		query)
+	if err != nil {
+		blog.Errorf("get hosts by inner ips failed, ips: %v, err: %v, rid: %s", ips, err, kit.Rid)
+		return nil, err
+	}
+
+	for _, host := range res.Info {
+		hostID, err := host.Int64(common.BKHostIDField)
+		if err != nil {
+			blog.Errorf("parse host id failed, host: %v, err: %v, rid: %s", host, err, kit.Rid)
+			return nil, err
+		}
+
+		for _, ip := range getHostInnerIPs(host[common.BKHostInnerIPField]) {
+			hostIDs[ip] = hostID
+		}
+	}
+	return hostIDs, nil
+}
+
+// getHostInnerIPs the inner ips of the host are stored as an array, and are returned as a comma separated string
+// by the older versions
+func getHostInnerIPs(val interface{}) []string {
+	ips := make([]string, 0)
+	switch v := val.(type) {
+	case string:
+		ips = append(ips, strings.Split(v, ",")...)
+	case []interface{}:
+		for _, ip := range v {
+			if s, ok := ip.(string); ok {
+				ips = append(ips, s)
+			}
+		}
+	case []string:
+		ips = append(ips, v...)
+	}
+	return ips
+}
+
+// equalFilter the filter that the field is equal to the value
+func equalFilter(field string, value interface{}) *filter.Expression {
+	return &filter.Expression{
+		RuleFactory: &filter.CombinedRule{
+			Condition: filter.And,
+			Rules: []filter.RuleFactory{
+				&filter.AtomRule{Field: field, Operator: filter.OpFactory(filter.Equal), Value: value},
+			},
+		},
+	}
+}
+
+// decodeResponseInfo decode the info of the response data with count and info into the result
+func decodeResponseInfo(data interface{}, result interface{}) error {
+	js, err := json.Marshal(data)
+	if err != nil {
+		return err
+	}
+
+	info := gjson.GetBytes(js, "info")
+	if !info.Exists() {
+		return nil
+	}
+	return json.Unmarshal([]byte(info.Raw), result)
+}
+
+// decodeInfo decode the queried instances into the result
+func decodeInfo(info []mapstr.MapStr, result interface{}) error {
+	js, err := json.Marshal(info)
+	if err != nil {
+		return err
+	}
+	return json.Unmarshal(js, result)
+}
+
+func minInt(a, b int) int {
+	if a < b {
+		return a
+	}
+	return b
+}
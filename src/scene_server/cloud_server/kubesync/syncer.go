@@ -0,0 +1,221 @@
+/*
+ * Tencent is pleased to support the open source community by making 蓝鲸 available.
+ * Copyright (C) 2017-2018 THL A29 Limited, a Tencent company. All rights reserved.
+ * Licensed under the MIT License (the "License"); you may not use this file except
+ * in compliance with the License. You may obtain a copy of the License at
+ * http://opensource.org/licenses/MIT
+ * Unless required by applicable law or agreed to in writing, software distributed under
+ * the License is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND,
+ * either express or implied. See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package kubesync
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"configcenter/src/common"
+	"configcenter/src/common/blog"
+	"configcenter/src/kube/types"
+	ccom "configcenter/src/scene_server/cloud_server/common"
+
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/client-go/dynamic"
+	"k8s.io/client-go/dynamic/dynamicinformer"
+	"k8s.io/client-go/informers"
+	"k8s.io/client-go/kubernetes"
+	appslisters "k8s.io/client-go/listers/apps/v1"
+	batchlisters "k8s.io/client-go/listers/batch/v1"
+	corelisters "k8s.io/client-go/listers/core/v1"
+	"k8s.io/client-go/tools/cache"
+)
+
+const (
+	// gameWorkloadGroupVersion the group version of the gameDeployment and gameStatefulSet custom resources
+	gameWorkloadGroupVersion = "tkex.tencent.com/v1alpha1"
+	// reconcileDelay the delay of reconciling after the kubernetes resources are changed, the changes in the delay
+	// are reconciled together
+	reconcileDelay = 5 * time.Second
+)
+
+var (
+	gameDeploymentGVR = schema.GroupVersionResource{Group: "tkex.tencent.com", Version: "v1alpha1",
+		Resource: "gamedeployments"}
+	gameStatefulSetGVR = schema.GroupVersionResource{Group: "tkex.tencent.com", Version: "v1alpha1",
+		Resource: "gamestatefulsets"}
+)
+
+// ClusterSyncer synchronize the nodes, namespaces, workloads and pods of a kubernetes cluster into the cmdb cluster.
+// the kubernetes resources are watched by the informers, and every change triggers a full reconcile of the informer
+// caches with the cmdb resources after a short delay, the full reconcile is also done periodically.
+type ClusterSyncer struct {
+	cluster       *Cluster
+	store         Store
+	client        kubernetes.Interface
+	dynamicClient dynamic.Interface
+	resyncPeriod  time.Duration
+	// notify is notified when the kubernetes resources are changed
+	notify chan struct{}
+
+	nodeLister        corelisters.NodeLister
+	namespaceLister   corelisters.NamespaceLister
+	quotaLister       corelisters.ResourceQuotaLister
+	podLister         corelisters.PodLister
+	deploymentLister  appslisters.DeploymentLister
+	replicaSetLister  appslisters.ReplicaSetLister
+	statefulSetLister appslisters.StatefulSetLister
+	daemonSetLister   appslisters.DaemonSetLister
+	jobLister         batchlisters.JobLister
+	cronJobLister     batchlisters.CronJobLister
+	// gameListers the listers of the game workload custom resources that are installed in the cluster
+	gameListers map[types.WorkloadType]cache.GenericLister
+}
+
+// NewClusterSyncer new the syncer of the cluster, the dynamic client is used for the game workload custom resources,
+// and the game workloads are not synchronized if it is nil.
+func NewClusterSyncer(cluster *Cluster, store Store, client kubernetes.Interface, dynamicClient dynamic.Interface,
+	resyncPeriod time.Duration) *ClusterSyncer {
+
+	return &ClusterSyncer{
+		cluster:       cluster,
+		store:         store,
+		client:        client,
+		dynamicClient: dynamicClient,
+		resyncPeriod:  resyncPeriod,
+		notify:        make(chan struct{}, 1),
+		gameListers:   make(map[types.WorkloadType]cache.GenericLister),
+	}
+}
+
+// Start start the informers of the cluster and wait for their caches to be synced
+func (s *ClusterSyncer) Start(ctx context.Context) error {
+	handler := cache.ResourceEventHandlerFuncs{
+		AddFunc:    func(interface{}) { s.enqueue() },
+		UpdateFunc: func(interface{}, interface{}) { s.enqueue() },
+		DeleteFunc: func(interface{}) { s.enqueue() },
+	}
+
+	factory := informers.NewSharedInformerFactory(s.client, s.resyncPeriod)
+	s.nodeLister = factory.Core().V1().Nodes().Lister()
+	s.namespaceLister = factory.Core().V1().Namespaces().Lister()
+	s.quotaLister = factory.Core().V1().ResourceQuotas().Lister()
+	s.podLister = factory.Core().V1().Pods().Lister()
+	s.deploymentLister = factory.Apps().V1().Deployments().Lister()
+	s.replicaSetLister = factory.Apps().V1().ReplicaSets().Lister()
+	s.statefulSetLister = factory.Apps().V1().StatefulSets().Lister()
+	s.daemonSetLister = factory.Apps().V1().DaemonSets().Lister()
+	s.jobLister = factory.Batch().V1().Jobs().Lister()
+	s.cronJobLister = factory.Batch().V1().CronJobs().Lister()
+
+	informerList := []cache.SharedIndexInformer{
+		factory.Core().V1().Nodes().Informer(),
+		factory.Core().V1().Namespaces().Informer(),
+		factory.Core().V1().ResourceQuotas().Informer(),
+		factory.Core().V1().Pods().Informer(),
+		factory.Apps().V1().Deployments().Informer(),
+		factory.Apps().V1().ReplicaSets().Informer(),
+		factory.Apps().V1().StatefulSets().Informer(),
+		factory.Apps().V1().DaemonSets().Informer(),
+		factory.Batch().V1().Jobs().Informer(),
+		factory.Batch().V1().CronJobs().Informer(),
+	}
+
+	var dynamicFactory dynamicinformer.DynamicSharedInformerFactory
+	gameGVRs := s.getGameWorkloadGVRs()
+	if len(gameGVRs) > 0 {
+		dynamicFactory = dynamicinformer.NewDynamicSharedInformerFactory(s.dynamicClient, s.resyncPeriod)
+		for kind, gvr := range gameGVRs {
+			informer := dynamicFactory.ForResource(gvr)
+			s.gameListers[kind] = informer.Lister()
+			informerList = append(informerList, informer.Informer())
+		}
+	}
+
+	for _, informer := range informerList {
+		informer.AddEventHandler(handler)
+	}
+
+	factory.Start(ctx.Done())
+	if dynamicFactory != nil {
+		dynamicFactory.Start(ctx.Done())
+	}
+
+	synced := make([]cache.InformerSynced, 0, len(informerList))
+	for _, informer := range informerList {
+		synced = append(synced, informer.HasSynced)
+	}
+	if !cache.WaitForCacheSync(ctx.Done(), synced...) {
+		return errors.New("wait for the informer caches to be synced failed")
+	}
+	return nil
+}
+
+// getGameWorkloadGVRs get the game workload custom resources that are installed in the cluster
+func (s *ClusterSyncer) getGameWorkloadGVRs() map[types.WorkloadType]schema.GroupVersionResource {
+	gvrs := make(map[types.WorkloadType]schema.GroupVersionResource)
+	if s.dynamicClient == nil {
+		return gvrs
+	}
+
+	resources, err := s.client.Discovery().ServerResourcesForGroupVersion(gameWorkloadGroupVersion)
+	if err != nil {
+		blog.Infof("game workloads of cluster %d are not synchronized, get %s resources failed, err: %v",
+			s.cluster.ID, gameWorkloadGroupVersion, err)
+		return gvrs
+	}
+
+	for _, resource := range resources.APIResources {
+		switch resource.Name {
+		case gameDeploymentGVR.Resource:
+			gvrs[types.KubeGameDeployment] = gameDeploymentGVR
+		case gameStatefulSetGVR.Resource:
+			gvrs[types.KubeGameStatefulSet] = gameStatefulSetGVR
+		}
+	}
+	return gvrs
+}
+
+// enqueue notify the syncer to reconcile, the notification is dropped if there is already one
+func (s *ClusterSyncer) enqueue() {
+	select {
+	case s.notify <- struct{}{}:
+	default:
+	}
+}
+
+// Run start the informers and reconcile the cluster until the context is done
+func (s *ClusterSyncer) Run(ctx context.Context) error {
+	if err := s.Start(ctx); err != nil {
+		return err
+	}
+
+	ticker := time.NewTicker(s.resyncPeriod)
+	defer ticker.Stop()
+
+	for {
+		kit := ccom.NewWriteKit(common.BKDefaultOwnerID)
+		if err := s.Reconcile(kit); err != nil {
+			blog.Errorf("reconcile cluster %d failed, err: %v, rid: %s", s.cluster.ID, err, kit.Rid)
+		}
+
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-ticker.C:
+		case <-s.notify:
+			// wait for a while so that the changes in the delay are reconciled together
+			select {
+			case <-ctx.Done():
+				return nil
+			case <-time.After(reconcileDelay):
+			}
+			select {
+			case <-s.notify:
+			default:
+			}
+		}
+	}
+}
@@ -0,0 +1,437 @@
+/*
+ * Tencent is pleased to support the open source community by making 蓝鲸 available.
+ * Copyright (C) 2017-2018 THL A29 Limited, a Tencent company. All rights reserved.
+ * Licensed under the MIT License (the "License"); you may not use this file except
+ * in compliance with the License. You may obtain a copy of the License at
+ * http://opensource.org/licenses/MIT
+ * Unless required by applicable law or agreed to in writing, software distributed under
+ * the License is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND,
+ * either express or implied. See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package kubesync
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"configcenter/src/common"
+	"configcenter/src/common/http/rest"
+	"configcenter/src/common/json"
+	"configcenter/src/kube/types"
+	ccom "configcenter/src/scene_server/cloud_server/common"
+
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/client-go/kubernetes/fake"
+)
+
+// fakeStore the in memory Store, the ids are assigned in order
+type fakeStore struct {
+	nextID     int64
+	writes     int
+	hosts      map[string]int64
+	nodes      map[int64]*types.Node
+	namespaces map[int64]*types.Namespace
+	workloads  map[types.WorkloadType]map[int64]types.WorkloadInterface
+	pods       map[int64]*types.Pod
+}
+
+func newFakeStore(hosts map[string]int64) *fakeStore {
+	return &fakeStore{
+		hosts:      hosts,
+		nodes:      make(map[int64]*types.Node),
+		namespaces: make(map[int64]*types.Namespace),
+		workloads:  make(map[types.WorkloadType]map[int64]types.WorkloadInterface),
+		pods:       make(map[int64]*types.Pod),
+	}
+}
+
+func (f *fakeStore) newID() int64 {
+	f.nextID++
+	f.writes++
+	return f.nextID
+}
+
+func (f *fakeStore) ListNodes(_ *rest.Kit) ([]types.Node, error) {
+	nodes := make([]types.Node, 0)
+	for _, node := range f.nodes {
+		nodes = append(nodes, *node)
+	}
+	return nodes, copyByJSON(&nodes)
+}
+
+func (f *fakeStore) CreateNodes(_ *rest.Kit, nodes []types.OneNodeCreateOption) ([]int64, error) {
+	ids := make([]int64, 0)
+	for _, opt := range nodes {
+		node := opt.Node
+		node.ID = f.newID()
+		node.BizID, node.HostID, node.ClusterID = opt.BizID, opt.HostID, opt.ClusterID
+		f.nodes[node.ID] = &node
+		ids = append(ids, node.ID)
+	}
+	return ids, nil
+}
+
+func (f *fakeStore) UpdateNode(_ *rest.Kit, id int64, node *types.Node) error {
+	f.writes++
+	return mergeByJSON(node, f.nodes[id])
+}
+
+func (f *fakeStore) DeleteNodes(_ *rest.Kit, ids []int64) error {
+	for _, id := range ids {
+		f.writes++
+		delete(f.nodes, id)
+	}
+	return nil
+}
+
+func (f *fakeStore) ListNamespaces(_ *rest.Kit) ([]types.Namespace, error) {
+	namespaces := make([]types.Namespace, 0)
+	for _, ns := range f.namespaces {
+		namespaces = append(namespaces, *ns)
+	}
+	return namespaces, copyByJSON(&namespaces)
+}
+
+func (f *fakeStore) CreateNamespaces(_ *rest.Kit, namespaces []types.Namespace) ([]int64, error) {
+	ids := make([]int64, 0)
+	for idx := range namespaces {
+		ns := namespaces[idx]
+		ns.ID = f.newID()
+		f.namespaces[ns.ID] = &ns
+		ids = append(ids, ns.ID)
+	}
+	return ids, nil
+}
+
+func (f *fakeStore) UpdateNamespace(_ *rest.Kit, id int64, namespace *types.Namespace) error {
+	f.writes++
+	return mergeByJSON(namespace, f.namespaces[id])
+}
+
+func (f *fakeStore) DeleteNamespaces(_ *rest.Kit, ids []int64) error {
+	for _, id := range ids {
+		f.writes++
+		delete(f.namespaces, id)
+	}
+	return nil
+}
+
+func (f *fakeStore) ListWorkloads(_ *rest.Kit, kind types.WorkloadType) ([]types.WorkloadInterface, error) {
+	workloads := make([]types.WorkloadInterface, 0)
+	for _, workload := range f.workloads[kind] {
+		workloads = append(workloads, workload)
+	}
+	js, err := json.Marshal(workloads)
+	if err != nil {
+		return nil, err
+	}
+	return types.WlArrayUnmarshalJSON(kind, js)
+}
+
+func (f *fakeStore) CreateWorkloads(_ *rest.Kit, kind types.WorkloadType, workloads []types.WorkloadInterface) (
+	[]int64, error) {
+
+	if f.workloads[kind] == nil {
+		f.workloads[kind] = make(map[int64]types.WorkloadInterface)
+	}
+
+	ids := make([]int64, 0)
+	for _, workload := range workloads {
+		created, err := kind.NewInst()
+		if err != nil {
+			return nil, err
+		}
+		if err := mergeByJSON(workload, created); err != nil {
+			return nil, err
+		}
+		base := created.GetWorkloadBase()
+		base.ID = f.newID()
+		created.SetWorkloadBase(base)
+		f.workloads[kind][base.ID] = created
+		ids = append(ids, base.ID)
+	}
+	return ids, nil
+}
+
+func (f *fakeStore) UpdateWorkload(_ *rest.Kit, kind types.WorkloadType, id int64,
+	workload types.WorkloadInterface) error {
+
+	f.writes++
+	return mergeByJSON(workload, f.workloads[kind][id])
+}
+
+func (f *fakeStore) DeleteWorkloads(_ *rest.Kit, kind types.WorkloadType, ids []int64) error {
+	for _, id := range ids {
+		f.writes++
+		delete(f.workloads[kind], id)
+	}
+	return nil
+}
+
+func (f *fakeStore) ListPods(_ *rest.Kit) ([]types.Pod, error) {
+	pods := make([]types.Pod, 0)
+	for _, pod := range f.pods {
+		pods = append(pods, *pod)
+	}
+	return pods, copyByJSON(&pods)
+}
+
+// CreatePods create the pods, the system spec of the pods is filled by the spec as cmdb does
+func (f *fakeStore) CreatePods(_ *rest.Kit, pods []types.PodsInfo) ([]int64, error) {
+	ids := make([]int64, 0)
+	for idx := range pods {
+		info := pods[idx]
+		pod := info.Pod
+		pod.ID = f.newID()
+		ref := info.Spec.Ref
+		pod.SysSpec = types.SysSpec{
+			WorkloadSpec: types.WorkloadSpec{
+				NamespaceSpec: types.NamespaceSpec{
+					NamespaceID: info.Spec.NamespaceID,
+					Namespace:   f.namespaces[info.Spec.NamespaceID].Name,
+				},
+				Ref: &ref,
+			},
+			HostID: info.HostID,
+			NodeID: info.Spec.NodeID,
+			Node:   *f.nodes[info.Spec.NodeID].Name,
+		}
+		f.pods[pod.ID] = &pod
+		ids = append(ids, pod.ID)
+	}
+	return ids, nil
+}
+
+func (f *fakeStore) DeletePods(_ *rest.Kit, ids []int64) error {
+	for _, id := range ids {
+		f.writes++
+		delete(f.pods, id)
+	}
+	return nil
+}
+
+func (f *fakeStore) GetHostIDs(_ *rest.Kit, ips []string) (map[string]int64, error) {
+	hostIDs := make(map[string]int64)
+	for _, ip := range ips {
+		if hostID, exists := f.hosts[ip]; exists {
+			hostIDs[ip] = hostID
+		}
+	}
+	return hostIDs, nil
+}
+
+func (f *fakeStore) getWorkload(kind types.WorkloadType, name string) types.WorkloadInterface {
+	for _, workload := range f.workloads[kind] {
+		if workload.GetWorkloadBase().Name == name {
+			return workload
+		}
+	}
+	return nil
+}
+
+func (f *fakeStore) getPod(name string) *types.Pod {
+	for _, pod := range f.pods {
+		if *pod.Name == name {
+			return pod
+		}
+	}
+	return nil
+}
+
+func copyByJSON(val interface{}) error {
+	return mergeByJSON(val, val)
+}
+
+func mergeByJSON(src, dst interface{}) error {
+	js, err := json.Marshal(src)
+	if err != nil {
+		return err
+	}
+	return json.Unmarshal(js, dst)
+}
+
+func controllerRef(kind, name string) []metav1.OwnerReference {
+	controller := true
+	return []metav1.OwnerReference{{Kind: kind, Name: name, Controller: &controller}}
+}
+
+func newTestPod(name, nodeName string, owners []metav1.OwnerReference) *corev1.Pod {
+	return &corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: "default", OwnerReferences: owners},
+		Spec: corev1.PodSpec{
+			NodeName:   nodeName,
+			Containers: []corev1.Container{{Name: "app", Image: "nginx:1.21"}},
+		},
+		Status: corev1.PodStatus{
+			PodIP: "172.16.0.1",
+			ContainerStatuses: []corev1.ContainerStatus{{Name: "app", ContainerID: "docker://" + name,
+				State: corev1.ContainerState{Running: &corev1.ContainerStateRunning{}}}},
+		},
+	}
+}
+
+func newTestSyncer(t *testing.T, ctx context.Context, store Store) (*ClusterSyncer, *fake.Clientset) {
+	replicas := int32(2)
+	client := fake.NewSimpleClientset(
+		&corev1.Node{
+			ObjectMeta: metav1.ObjectMeta{Name: "node-1",
+				Labels: map[string]string{"node-role.kubernetes.io/master": ""}},
+			Status: corev1.NodeStatus{Addresses: []corev1.NodeAddress{
+				{Type: corev1.NodeInternalIP, Address: "10.0.0.1"},
+			}},
+		},
+		// the host of node-2 is not in cmdb
+		&corev1.Node{
+			ObjectMeta: metav1.ObjectMeta{Name: "node-2"},
+			Status: corev1.NodeStatus{Addresses: []corev1.NodeAddress{
+				{Type: corev1.NodeInternalIP, Address: "10.0.0.2"},
+			}},
+		},
+		&corev1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: "default"}},
+		&corev1.ResourceQuota{
+			ObjectMeta: metav1.ObjectMeta{Name: "quota", Namespace: "default"},
+			Spec: corev1.ResourceQuotaSpec{Hard: corev1.ResourceList{
+				corev1.ResourcePods: resource.MustParse("10"),
+			}},
+		},
+		&appsv1.Deployment{
+			ObjectMeta: metav1.ObjectMeta{Name: "web", Namespace: "default"},
+			Spec:       appsv1.DeploymentSpec{Replicas: &replicas},
+		},
+		&appsv1.ReplicaSet{
+			ObjectMeta: metav1.ObjectMeta{Name: "web-6d4cf56db6", Namespace: "default",
+				OwnerReferences: controllerRef("Deployment", "web")},
+		},
+		newTestPod("web-6d4cf56db6-x2x9z", "node-1", controllerRef("ReplicaSet", "web-6d4cf56db6")),
+		newTestPod("bare", "node-1", nil),
+		newTestPod("on-node-2", "node-2", nil),
+	)
+
+	cluster := &Cluster{BizID: 2, ID: 1, UID: "BCS-K8S-00001"}
+	syncer := NewClusterSyncer(cluster, store, client, nil, time.Minute)
+	if err := syncer.Start(ctx); err != nil {
+		t.Fatal(err)
+	}
+	return syncer, client
+}
+
+func TestReconcile(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	store := newFakeStore(map[string]int64{"10.0.0.1": 1001})
+	syncer, client := newTestSyncer(t, ctx, store)
+	kit := ccom.NewWriteKit(common.BKDefaultOwnerID)
+
+	if err := syncer.Reconcile(kit); err != nil {
+		t.Fatal(err)
+	}
+
+	if len(store.nodes) != 1 {
+		t.Fatalf("expect only the node with host is synchronized, but got %d nodes", len(store.nodes))
+	}
+	for _, node := range store.nodes {
+		if *node.Name != "node-1" || node.HostID != 1001 || *node.Roles != "master" {
+			t.Errorf("node is not synchronized correctly, node: %+v", node)
+		}
+	}
+
+	if len(store.namespaces) != 1 {
+		t.Fatalf("expect 1 namespace, but got %d", len(store.namespaces))
+	}
+	for _, ns := range store.namespaces {
+		if ns.ClusterUID != "BCS-K8S-00001" || ns.ResourceQuotas == nil || len(*ns.ResourceQuotas) != 1 {
+			t.Errorf("namespace is not synchronized correctly, namespace: %+v", ns)
+		}
+	}
+
+	deploy := store.getWorkload(types.KubeDeployment, "web")
+	if deploy == nil {
+		t.Fatal("deployment web is not synchronized")
+	}
+	podsWorkload := store.getWorkload(types.KubePodWorkload, podsWorkloadName)
+	if podsWorkload == nil {
+		t.Fatal("pods workload is not created for the pod without workload")
+	}
+
+	if len(store.pods) != 2 {
+		t.Fatalf("expect 2 pods on the synchronized node, but got %d", len(store.pods))
+	}
+	webPod := store.getPod("web-6d4cf56db6-x2x9z")
+	if webPod == nil || webPod.Ref.Kind != types.KubeDeployment || webPod.Ref.ID != deploy.GetWorkloadBase().ID ||
+		webPod.HostID != 1001 {
+		t.Errorf("pod of deployment is not linked correctly, pod: %+v", webPod)
+	}
+	barePod := store.getPod("bare")
+	if barePod == nil || barePod.Ref.Kind != types.KubePodWorkload ||
+		barePod.Ref.ID != podsWorkload.GetWorkloadBase().ID {
+		t.Errorf("pod without workload is not linked to pods workload, pod: %+v", barePod)
+	}
+
+	// reconcile again without changes should not write anything
+	writes := store.writes
+	if err := syncer.Reconcile(kit); err != nil {
+		t.Fatal(err)
+	}
+	if store.writes != writes {
+		t.Errorf("expect no writes when nothing is changed, but got %d writes", store.writes-writes)
+	}
+
+	// scale the deployment and delete the pod without workload
+	replicas := int32(3)
+	deployment, err := client.AppsV1().Deployments("default").Get(ctx, "web", metav1.GetOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	deployment.Spec.Replicas = &replicas
+	if _, err := client.AppsV1().Deployments("default").Update(ctx, deployment, metav1.UpdateOptions{}); err != nil {
+		t.Fatal(err)
+	}
+	if err := client.CoreV1().Pods("default").Delete(ctx, "bare", metav1.DeleteOptions{}); err != nil {
+		t.Fatal(err)
+	}
+
+	waitFor(t, func() bool {
+		deploy, err := syncer.deploymentLister.Deployments("default").Get("web")
+		if err != nil || *deploy.Spec.Replicas != 3 {
+			return false
+		}
+		pods, err := syncer.podLister.List(labels.Everything())
+		return err == nil && len(pods) == 2
+	})
+
+	if err := syncer.Reconcile(kit); err != nil {
+		t.Fatal(err)
+	}
+
+	deploy = store.getWorkload(types.KubeDeployment, "web")
+	if replicas := deploy.(*types.Deployment).Replicas; replicas == nil || *replicas != 3 {
+		t.Errorf("deployment replicas is not updated, replicas: %v", replicas)
+	}
+	if store.getPod("bare") != nil {
+		t.Error("deleted pod is not removed")
+	}
+	if store.getWorkload(types.KubePodWorkload, podsWorkloadName) != nil {
+		t.Error("pods workload without pods is not removed")
+	}
+	if store.getPod("web-6d4cf56db6-x2x9z") == nil {
+		t.Error("unchanged pod should not be removed")
+	}
+}
+
+func waitFor(t *testing.T, condition func() bool) {
+	for i := 0; i < 50; i++ {
+		if condition() {
+			return
+		}
+		time.Sleep(100 * time.Millisecond)
+	}
+	t.Fatal("wait for the informer caches to be updated timeout")
+}
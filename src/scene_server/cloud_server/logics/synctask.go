@@ -388,3 +388,42 @@ func (lgc *Logics) SearchSyncRegion(kit *rest.Kit, option *metadata.SearchSyncRe
 
 	return result, nil
 }
+
+// GetSyncTask 根据任务id获取同步任务
+func (lgc *Logics) GetSyncTask(kit *rest.Kit, taskID int64) (*metadata.CloudSyncTask, error) {
+	option := &metadata.SearchCloudOption{Condition: mapstr.MapStr{common.BKCloudSyncTaskID: taskID}}
+	result, err := lgc.CoreAPI.CoreService().Cloud().SearchSyncTask(kit.Ctx, kit.Header, option)
+	if err != nil {
+		blog.Errorf("search sync task failed, taskID: %d, err: %v, rid: %s", taskID, err, kit.Rid)
+		return nil, err
+	}
+
+	if len(result.Info) == 0 {
+		blog.Errorf("sync task %d is not found, rid: %s", taskID, kit.Rid)
+		return nil, kit.CCError.CCError(common.CCErrCommNotFound)
+	}
+
+	return &result.Info[0], nil
+}
+
+// SearchSyncPreview 查询同步任务的同步预览
+func (lgc *Logics) SearchSyncPreview(kit *rest.Kit,
+	option *metadata.SearchSyncPreviewOption) (*metadata.MultipleSyncPreview, error) {
+	// set default limit
+	if option.Page.Limit == 0 {
+		option.Page.Limit = common.BKDefaultLimit
+	}
+
+	if rawErr := option.Validate(); rawErr.ErrCode != 0 {
+		blog.Errorf("search sync preview option is invalid, option: %#v, err: %v, rid: %s", option, rawErr, kit.Rid)
+		return nil, rawErr.ToCCError(kit.CCError)
+	}
+
+	result, err := lgc.CoreAPI.CoreService().Cloud().SearchSyncPreview(kit.Ctx, kit.Header, option)
+	if err != nil {
+		blog.Errorf("search sync preview failed, option: %#v, err: %v, rid: %s", option, err, kit.Rid)
+		return nil, err
+	}
+
+	return result, nil
+}
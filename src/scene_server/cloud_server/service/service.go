@@ -116,5 +116,13 @@ func (s *Service) initRoute(api *restful.WebService) {
 	utility.AddHandler(rest.Action{Verb: http.MethodPost, Path: "/findmany/cloud/sync/region",
 		Handler: s.SearchSyncRegion})
 
+	// cloud sync preview
+	utility.AddHandler(rest.Action{Verb: http.MethodPost, Path: "/findmany/cloud/sync/preview",
+		Handler: s.SearchSyncPreview})
+	utility.AddHandler(rest.Action{Verb: http.MethodPost, Path: "/create/cloud/sync/preview/{bk_task_id}",
+		Handler: s.CreateSyncPreview})
+	utility.AddHandler(rest.Action{Verb: http.MethodPost, Path: "/approve/cloud/sync/preview/{bk_task_id}",
+		Handler: s.ApproveSyncPreview})
+
 	utility.AddToRestfulWebService(api)
 }
@@ -21,6 +21,7 @@ import (
 	"configcenter/src/common/blog"
 	"configcenter/src/common/http/rest"
 	"configcenter/src/common/metadata"
+	"configcenter/src/scene_server/cloud_server/cloudsync"
 )
 
 // SearchVpc TODO
@@ -198,3 +199,78 @@ func (s *Service) SearchSyncRegion(ctx *rest.Contexts) {
 
 	ctx.RespEntity(result)
 }
+
+// SearchSyncPreview search the sync previews of a cloud sync task
+func (s *Service) SearchSyncPreview(ctx *rest.Contexts) {
+	option := metadata.SearchSyncPreviewOption{}
+	if err := ctx.DecodeInto(&option); err != nil {
+		ctx.RespAutoError(err)
+		return
+	}
+
+	result, err := s.Logics.SearchSyncPreview(ctx.Kit, &option)
+	if err != nil {
+		ctx.RespAutoError(err)
+		return
+	}
+
+	ctx.RespEntity(result)
+}
+
+// CreateSyncPreview generate the sync preview of a cloud sync task without changing any host, the preview is saved
+// as pending and can be approved later
+func (s *Service) CreateSyncPreview(ctx *rest.Contexts) {
+	taskID, err := strconv.ParseInt(ctx.Request.PathParameter(common.BKCloudSyncTaskID), 10, 64)
+	if err != nil || taskID <= 0 {
+		ctx.RespAutoError(ctx.Kit.CCError.CCErrorf(common.CCErrCommParamsInvalid, common.BKCloudSyncTaskID))
+		return
+	}
+
+	task, err := s.Logics.GetSyncTask(ctx.Kit, taskID)
+	if err != nil {
+		ctx.RespAutoError(err)
+		return
+	}
+
+	result, err := cloudsync.PreviewSync(s.Logics, ctx.Kit, task)
+	if err != nil {
+		ctx.RespAutoError(err)
+		return
+	}
+
+	ctx.RespEntity(result)
+}
+
+// ApproveSyncPreview approve the pending sync preview of a cloud sync task and sync its diff to the hosts
+func (s *Service) ApproveSyncPreview(ctx *rest.Contexts) {
+	taskID, err := strconv.ParseInt(ctx.Request.PathParameter(common.BKCloudSyncTaskID), 10, 64)
+	if err != nil || taskID <= 0 {
+		ctx.RespAutoError(ctx.Kit.CCError.CCErrorf(common.CCErrCommParamsInvalid, common.BKCloudSyncTaskID))
+		return
+	}
+
+	option := metadata.ApplySyncPreviewOption{}
+	if err := ctx.DecodeInto(&option); err != nil {
+		ctx.RespAutoError(err)
+		return
+	}
+
+	if rawErr := option.Validate(); rawErr.ErrCode != 0 {
+		ctx.RespAutoError(rawErr.ToCCError(ctx.Kit.CCError))
+		return
+	}
+
+	task, err := s.Logics.GetSyncTask(ctx.Kit, taskID)
+	if err != nil {
+		ctx.RespAutoError(err)
+		return
+	}
+
+	result, err := cloudsync.ApplySyncPreview(s.Logics, ctx.Kit, task, option.PreviewID)
+	if err != nil {
+		ctx.RespAutoError(err)
+		return
+	}
+
+	ctx.RespEntity(result)
+}
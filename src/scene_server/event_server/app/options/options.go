@@ -17,6 +17,7 @@ import (
 	"configcenter/src/ac/iam"
 	"configcenter/src/common/auth"
 	"configcenter/src/common/core/cc/config"
+	"configcenter/src/scene_server/event_server/subscription"
 	"configcenter/src/scene_server/event_server/sync/hostidentifier"
 	"configcenter/src/storage/dal/mongo"
 	"configcenter/src/storage/dal/redis"
@@ -62,6 +63,9 @@ type Config struct {
 	// IdentifierConf host identifier config
 	IdentifierConf *hostidentifier.HostIdentifierConf
 
+	// SubscriptionConf event push subscription config
+	SubscriptionConf *subscription.Config
+
 	// TaskConf gse taskServer connection config
 	TaskConf *client.GseConnConfig
 
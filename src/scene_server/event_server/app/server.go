@@ -29,6 +29,7 @@ import (
 	"configcenter/src/common/types"
 	"configcenter/src/scene_server/event_server/app/options"
 	svc "configcenter/src/scene_server/event_server/service"
+	"configcenter/src/scene_server/event_server/subscription"
 	"configcenter/src/scene_server/event_server/sync/hostidentifier"
 	eventtype "configcenter/src/scene_server/event_server/types"
 	"configcenter/src/storage/dal"
@@ -190,6 +191,12 @@ func (es *EventServer) initConfigs() error {
 	}
 	es.config.IdentifierConf = identifierConf
 
+	es.config.SubscriptionConf, err = subscription.ParseConfig()
+	if err != nil {
+		blog.Errorf("parse eventServer subscription config error, err: %v", err)
+		return err
+	}
+
 	if !es.config.IdentifierConf.StartUp {
 		return nil
 	}
@@ -281,9 +288,25 @@ func (es *EventServer) Run() error {
 	if err := es.runSyncData(); err != nil {
 		return err
 	}
+
+	es.runSubscription()
 	return nil
 }
 
+// runSubscription runs the event push subscription manager.
+func (es *EventServer) runSubscription() {
+	if !es.config.SubscriptionConf.StartUp {
+		blog.Warnf("eventServer.subscription.startUp is false, will not push subscription events")
+		return
+	}
+
+	manager := subscription.NewManager(es.ctx, es.engine, es.db, es.config.SubscriptionConf)
+	es.service.SubscriptionManager = manager
+	go manager.Run()
+
+	blog.Info("run subscription manager success!")
+}
+
 func (es *EventServer) runSyncData() error {
 	if !es.config.IdentifierConf.StartUp {
 		return nil
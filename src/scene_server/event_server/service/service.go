@@ -28,6 +28,7 @@ import (
 	"configcenter/src/common/rdapi"
 	"configcenter/src/common/types"
 	"configcenter/src/common/webservice/restfulservice"
+	"configcenter/src/scene_server/event_server/subscription"
 	"configcenter/src/scene_server/event_server/sync/hostidentifier"
 	"configcenter/src/storage/dal"
 	"configcenter/src/storage/dal/redis"
@@ -48,6 +49,9 @@ type Service struct {
 
 	// SyncData is sync host identifier operator
 	SyncData *hostidentifier.HostIdentifier
+
+	// SubscriptionManager is the event push subscription manager, it's nil if subscription push is disabled
+	SubscriptionManager *subscription.Manager
 }
 
 // NewService creates a new Service object.
@@ -112,6 +116,18 @@ func (s *Service) initService(web *restful.WebService) {
 	utility.AddHandler(rest.Action{Verb: http.MethodPost, Path: "/find/host_identifier_push_result",
 		Handler: s.GetHostIdentifierPushResult})
 
+	// event push subscription
+	utility.AddHandler(rest.Action{Verb: http.MethodPost, Path: "/create/subscription", Handler: s.CreateSubscription})
+	utility.AddHandler(rest.Action{Verb: http.MethodPut, Path: "/update/subscription/{id}",
+		Handler: s.UpdateSubscription})
+	utility.AddHandler(rest.Action{Verb: http.MethodDelete, Path: "/delete/subscription/{id}",
+		Handler: s.DeleteSubscription})
+	utility.AddHandler(rest.Action{Verb: http.MethodPost, Path: "/findmany/subscription", Handler: s.ListSubscription})
+	utility.AddHandler(rest.Action{Verb: http.MethodPost, Path: "/findmany/subscription/dead_letter",
+		Handler: s.ListSubscriptionDeadLetter})
+	utility.AddHandler(rest.Action{Verb: http.MethodPost, Path: "/redeliver/subscription/dead_letter",
+		Handler: s.RedeliverSubscriptionDeadLetter})
+
 	utility.AddToRestfulWebService(web)
 
 }
@@ -0,0 +1,351 @@
+/*
+ * Tencent is pleased to support the open source community by making 蓝鲸 available.
+ * Copyright (C) 2017-2018 THL A29 Limited, a Tencent company. All rights reserved.
+ * Licensed under the MIT License (the "License"); you may not use this file except
+ * in compliance with the License. You may obtain a copy of the License at
+ * http://opensource.org/licenses/MIT
+ * Unless required by applicable law or agreed to in writing, software distributed under
+ * the License is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND,
+ * either express or implied. See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package service
+
+import (
+	"strconv"
+
+	"configcenter/src/common"
+	"configcenter/src/common/blog"
+	"configcenter/src/common/http/rest"
+	"configcenter/src/common/mapstr"
+	"configcenter/src/common/metadata"
+	"configcenter/src/common/watch"
+	"configcenter/src/scene_server/event_server/subscription"
+)
+
+// CreateSubscription create an event push subscription
+func (s *Service) CreateSubscription(ctx *rest.Contexts) {
+	sub := new(watch.Subscription)
+	if err := ctx.DecodeInto(sub); err != nil {
+		ctx.RespAutoError(err)
+		return
+	}
+
+	if err := sub.Validate(); err != nil {
+		blog.Errorf("create subscription, but got invalid option, err: %v, rid: %s", err, ctx.Kit.Rid)
+		ctx.RespAutoError(ctx.Kit.CCError.CCErrorf(common.CCErrCommParamsInvalid, err.Error()))
+		return
+	}
+
+	id, err := s.db.NextSequence(ctx.Kit.Ctx, common.BKTableNameEventSubscription)
+	if err != nil {
+		blog.Errorf("generate subscription id failed, err: %v, rid: %s", err, ctx.Kit.Rid)
+		ctx.RespAutoError(ctx.Kit.CCError.CCError(common.CCErrEventSubscribeInsertFailed))
+		return
+	}
+
+	now := metadata.Now()
+	sub.ID = int64(id)
+	sub.OwnerID = ctx.Kit.SupplierAccount
+	sub.Creator = ctx.Kit.User
+	sub.Modifier = ctx.Kit.User
+	sub.CreateTime = now
+	sub.LastTime = now
+
+	if err := s.db.Table(common.BKTableNameEventSubscription).Insert(ctx.Kit.Ctx, sub); err != nil {
+		blog.Errorf("create subscription %+v failed, err: %v, rid: %s", sub, err, ctx.Kit.Rid)
+		ctx.RespAutoError(ctx.Kit.CCError.CCError(common.CCErrEventSubscribeInsertFailed))
+		return
+	}
+
+	s.reloadSubscription()
+	ctx.RespEntity(metadata.RspID{ID: sub.ID})
+}
+
+// UpdateSubscription update an event push subscription, the subscription's cursors are kept so that
+// the pushes resume from where they were.
+func (s *Service) UpdateSubscription(ctx *rest.Contexts) {
+	id, err := strconv.ParseInt(ctx.Request.PathParameter(common.BKFieldID), 10, 64)
+	if err != nil || id <= 0 {
+		ctx.RespAutoError(ctx.Kit.CCError.CCErrorf(common.CCErrCommParamsInvalid, common.BKFieldID))
+		return
+	}
+
+	opt := new(watch.UpdateSubscriptionOption)
+	if err := ctx.DecodeInto(opt); err != nil {
+		ctx.RespAutoError(err)
+		return
+	}
+
+	if err := opt.Validate(); err != nil {
+		ctx.RespAutoError(ctx.Kit.CCError.CCErrorf(common.CCErrCommParamsInvalid, err.Error()))
+		return
+	}
+
+	sub, err := s.getCreatedSubscription(ctx.Kit, id)
+	if err != nil {
+		ctx.RespAutoError(err)
+		return
+	}
+
+	opt.MergeInto(sub)
+	if err := sub.Validate(); err != nil {
+		blog.Errorf("update subscription, but got invalid option, err: %v, rid: %s", err, ctx.Kit.Rid)
+		ctx.RespAutoError(ctx.Kit.CCError.CCErrorf(common.CCErrCommParamsInvalid, err.Error()))
+		return
+	}
+
+	sub.Modifier = ctx.Kit.User
+	sub.LastTime = metadata.Now()
+
+	filter := s.subscriptionFilter(ctx.Kit, id)
+	if err := s.db.Table(common.BKTableNameEventSubscription).Update(ctx.Kit.Ctx, filter, sub); err != nil {
+		blog.Errorf("update subscription %d failed, err: %v, rid: %s", id, err, ctx.Kit.Rid)
+		ctx.RespAutoError(ctx.Kit.CCError.CCError(common.CCErrEventSubscribeUpdateFailed))
+		return
+	}
+
+	s.reloadSubscription()
+	ctx.RespEntity(nil)
+}
+
+// DeleteSubscription delete an event push subscription with its cursors and dead letters
+func (s *Service) DeleteSubscription(ctx *rest.Contexts) {
+	id, err := strconv.ParseInt(ctx.Request.PathParameter(common.BKFieldID), 10, 64)
+	if err != nil || id <= 0 {
+		ctx.RespAutoError(ctx.Kit.CCError.CCErrorf(common.CCErrCommParamsInvalid, common.BKFieldID))
+		return
+	}
+
+	if _, err := s.getCreatedSubscription(ctx.Kit, id); err != nil {
+		ctx.RespAutoError(err)
+		return
+	}
+
+	relationFilter := mapstr.MapStr{
+		"subscription_id":        id,
+		common.BkSupplierAccount: ctx.Kit.SupplierAccount,
+	}
+	for _, table := range []string{common.BKTableNameEventSubscriptionCursor,
+		common.BKTableNameEventSubscriptionDeadLetter} {
+		if err := s.db.Table(table).Delete(ctx.Kit.Ctx, relationFilter); err != nil {
+			blog.Errorf("delete subscription %d data in %s failed, err: %v, rid: %s", id, table, err, ctx.Kit.Rid)
+			ctx.RespAutoError(ctx.Kit.CCError.CCError(common.CCErrEventSubscribeDeleteFailed))
+			return
+		}
+	}
+
+	filter := s.subscriptionFilter(ctx.Kit, id)
+	if err := s.db.Table(common.BKTableNameEventSubscription).Delete(ctx.Kit.Ctx, filter); err != nil {
+		blog.Errorf("delete subscription %d failed, err: %v, rid: %s", id, err, ctx.Kit.Rid)
+		ctx.RespAutoError(ctx.Kit.CCError.CCError(common.CCErrEventSubscribeDeleteFailed))
+		return
+	}
+
+	s.reloadSubscription()
+	ctx.RespEntity(nil)
+}
+
+// ListSubscription list event push subscriptions created by the user, the secrets are not returned.
+func (s *Service) ListSubscription(ctx *rest.Contexts) {
+	opt := new(watch.ListSubscriptionOption)
+	if err := ctx.DecodeInto(opt); err != nil {
+		ctx.RespAutoError(err)
+		return
+	}
+
+	if err := opt.Validate(); err != nil {
+		ctx.RespAutoError(ctx.Kit.CCError.CCErrorf(common.CCErrCommParamsInvalid, err.Error()))
+		return
+	}
+
+	filter := mapstr.MapStr{
+		common.BkSupplierAccount: ctx.Kit.SupplierAccount,
+		common.CreatorField:      ctx.Kit.User,
+	}
+	if len(opt.IDs) > 0 {
+		filter[common.BKFieldID] = mapstr.MapStr{common.BKDBIN: opt.IDs}
+	}
+
+	count, err := s.db.Table(common.BKTableNameEventSubscription).Find(filter).Count(ctx.Kit.Ctx)
+	if err != nil {
+		blog.Errorf("count subscriptions failed, filter: %+v, err: %v, rid: %s", filter, err, ctx.Kit.Rid)
+		ctx.RespAutoError(ctx.Kit.CCError.CCError(common.CCErrEventSubscribeSelectFailed))
+		return
+	}
+
+	subs := make([]*watch.Subscription, 0)
+	err = s.db.Table(common.BKTableNameEventSubscription).Find(filter).Start(uint64(opt.Page.Start)).
+		Limit(uint64(opt.Page.Limit)).Sort(common.BKFieldID).All(ctx.Kit.Ctx, &subs)
+	if err != nil {
+		blog.Errorf("list subscriptions failed, filter: %+v, err: %v, rid: %s", filter, err, ctx.Kit.Rid)
+		ctx.RespAutoError(ctx.Kit.CCError.CCError(common.CCErrEventSubscribeSelectFailed))
+		return
+	}
+
+	for _, sub := range subs {
+		sub.Secret = ""
+	}
+
+	ctx.RespEntityWithCount(int64(count), subs)
+}
+
+// ListSubscriptionDeadLetter list the dead lettered pushes of a subscription
+func (s *Service) ListSubscriptionDeadLetter(ctx *rest.Contexts) {
+	opt := new(watch.ListSubscriptionDeadLetterOption)
+	if err := ctx.DecodeInto(opt); err != nil {
+		ctx.RespAutoError(err)
+		return
+	}
+
+	if err := opt.Validate(); err != nil {
+		ctx.RespAutoError(ctx.Kit.CCError.CCErrorf(common.CCErrCommParamsInvalid, err.Error()))
+		return
+	}
+
+	if _, err := s.getCreatedSubscription(ctx.Kit, opt.SubscriptionID); err != nil {
+		ctx.RespAutoError(err)
+		return
+	}
+
+	filter := mapstr.MapStr{
+		"subscription_id":        opt.SubscriptionID,
+		common.BkSupplierAccount: ctx.Kit.SupplierAccount,
+	}
+
+	count, err := s.db.Table(common.BKTableNameEventSubscriptionDeadLetter).Find(filter).Count(ctx.Kit.Ctx)
+	if err != nil {
+		blog.Errorf("count dead letters failed, filter: %+v, err: %v, rid: %s", filter, err, ctx.Kit.Rid)
+		ctx.RespAutoError(ctx.Kit.CCError.CCError(common.CCErrCommDBSelectFailed))
+		return
+	}
+
+	letters := make([]*watch.SubscriptionDeadLetter, 0)
+	err = s.db.Table(common.BKTableNameEventSubscriptionDeadLetter).Find(filter).Start(uint64(opt.Page.Start)).
+		Limit(uint64(opt.Page.Limit)).Sort(common.BKFieldID).All(ctx.Kit.Ctx, &letters)
+	if err != nil {
+		blog.Errorf("list dead letters failed, filter: %+v, err: %v, rid: %s", filter, err, ctx.Kit.Rid)
+		ctx.RespAutoError(ctx.Kit.CCError.CCError(common.CCErrCommDBSelectFailed))
+		return
+	}
+
+	ctx.RespEntityWithCount(int64(count), letters)
+}
+
+// RedeliverSubscriptionDeadLetter push the dead letters again, the successfully pushed ones are removed,
+// and the failed ones are kept with their attempts and error updated. the letters are pushed synchronously,
+// so at most watch.MaxRedeliverDeadLetterCount letters can be redelivered in one call.
+func (s *Service) RedeliverSubscriptionDeadLetter(ctx *rest.Contexts) {
+	opt := new(watch.RedeliverDeadLetterOption)
+	if err := ctx.DecodeInto(opt); err != nil {
+		ctx.RespAutoError(err)
+		return
+	}
+
+	if err := opt.Validate(); err != nil {
+		ctx.RespAutoError(ctx.Kit.CCError.CCErrorf(common.CCErrCommParamsInvalid, err.Error()))
+		return
+	}
+
+	sub, err := s.getCreatedSubscription(ctx.Kit, opt.SubscriptionID)
+	if err != nil {
+		ctx.RespAutoError(err)
+		return
+	}
+
+	filter := mapstr.MapStr{
+		common.BKFieldID:         mapstr.MapStr{common.BKDBIN: opt.IDs},
+		"subscription_id":        opt.SubscriptionID,
+		common.BkSupplierAccount: ctx.Kit.SupplierAccount,
+	}
+	letters := make([]*watch.SubscriptionDeadLetter, 0)
+	err = s.db.Table(common.BKTableNameEventSubscriptionDeadLetter).Find(filter).Sort(common.BKFieldID).
+		All(ctx.Kit.Ctx, &letters)
+	if err != nil {
+		blog.Errorf("get dead letters failed, filter: %+v, err: %v, rid: %s", filter, err, ctx.Kit.Rid)
+		ctx.RespAutoError(ctx.Kit.CCError.CCError(common.CCErrCommDBSelectFailed))
+		return
+	}
+
+	result := &watch.RedeliverDeadLetterResult{
+		SuccessIDs: make([]int64, 0),
+		FailedIDs:  make([]int64, 0),
+	}
+	for _, letter := range letters {
+		letterFilter := mapstr.MapStr{
+			common.BKFieldID:         letter.ID,
+			common.BkSupplierAccount: ctx.Kit.SupplierAccount,
+		}
+
+		pushErr := subscription.Push(ctx.Kit.Ctx, sub, []byte(letter.Payload))
+		if pushErr == nil {
+			err = s.db.Table(common.BKTableNameEventSubscriptionDeadLetter).Delete(ctx.Kit.Ctx, letterFilter)
+			if err != nil {
+				blog.Errorf("delete redelivered dead letter %d failed, err: %v, rid: %s", letter.ID, err, ctx.Kit.Rid)
+			}
+			result.SuccessIDs = append(result.SuccessIDs, letter.ID)
+			continue
+		}
+
+		blog.Errorf("redeliver dead letter %d failed, err: %v, rid: %s", letter.ID, pushErr, ctx.Kit.Rid)
+		result.FailedIDs = append(result.FailedIDs, letter.ID)
+
+		update := mapstr.MapStr{
+			"attempts":  letter.Attempts + 1,
+			"error":     pushErr.Error(),
+			"last_time": metadata.Now(),
+		}
+		err = s.db.Table(common.BKTableNameEventSubscriptionDeadLetter).Update(ctx.Kit.Ctx, letterFilter, update)
+		if err != nil {
+			blog.Errorf("update dead letter %d failed, err: %v, rid: %s", letter.ID, err, ctx.Kit.Rid)
+		}
+	}
+
+	ctx.RespEntity(result)
+}
+
+func (s *Service) subscriptionFilter(kit *rest.Kit, id int64) mapstr.MapStr {
+	return mapstr.MapStr{
+		common.BKFieldID:         id,
+		common.BkSupplierAccount: kit.SupplierAccount,
+	}
+}
+
+func (s *Service) getSubscription(kit *rest.Kit, id int64) (*watch.Subscription, error) {
+	sub := new(watch.Subscription)
+	err := s.db.Table(common.BKTableNameEventSubscription).Find(s.subscriptionFilter(kit, id)).One(kit.Ctx, sub)
+	if err != nil {
+		if s.db.IsNotFoundError(err) {
+			blog.Errorf("subscription %d is not exist, rid: %s", id, kit.Rid)
+			return nil, kit.CCError.CCErrorf(common.CCErrCommNotFound)
+		}
+
+		blog.Errorf("get subscription %d failed, err: %v, rid: %s", id, err, kit.Rid)
+		return nil, kit.CCError.CCError(common.CCErrEventSubscribeSelectFailed)
+	}
+
+	return sub, nil
+}
+
+// getCreatedSubscription get the subscription that is created by the user, only the creator can operate the
+// subscription, because its resources are authorized with the creator's watch permission when it's saved.
+func (s *Service) getCreatedSubscription(kit *rest.Kit, id int64) (*watch.Subscription, error) {
+	sub, err := s.getSubscription(kit, id)
+	if err != nil {
+		return nil, err
+	}
+
+	if sub.Creator != kit.User {
+		blog.Errorf("user %s is not the creator %s of subscription %d, rid: %s", kit.User, sub.Creator, id, kit.Rid)
+		return nil, kit.CCError.CCError(common.CCErrCommAuthNotHavePermission)
+	}
+
+	return sub, nil
+}
+
+func (s *Service) reloadSubscription() {
+	if s.SubscriptionManager != nil {
+		s.SubscriptionManager.Reload()
+	}
+}
@@ -0,0 +1,81 @@
+/*
+ * Tencent is pleased to support the open source community by making 蓝鲸 available.
+ * Copyright (C) 2017-2018 THL A29 Limited, a Tencent company. All rights reserved.
+ * Licensed under the MIT License (the "License"); you may not use this file except
+ * in compliance with the License. You may obtain a copy of the License at
+ * http://opensource.org/licenses/MIT
+ * Unless required by applicable law or agreed to in writing, software distributed under
+ * the License is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND,
+ * either express or implied. See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package subscription
+
+import (
+	"time"
+
+	cc "configcenter/src/common/backbone/configcenter"
+	"configcenter/src/common/blog"
+)
+
+const (
+	// defaultReloadIntervalSeconds is the default interval to reload subscriptions from db.
+	defaultReloadIntervalSeconds = 30
+	// defaultMaxBackoffSeconds is the default maximum backoff between two retries of a push request.
+	defaultMaxBackoffSeconds = 60
+)
+
+// Config subscription push config
+type Config struct {
+	// StartUp defines if the subscription push is enabled, default is true.
+	StartUp bool
+	// ReloadInterval is the interval to reload the changed subscriptions.
+	ReloadInterval time.Duration
+	// MaxBackoff is the maximum backoff between two retries of a push request.
+	MaxBackoff time.Duration
+}
+
+// ParseConfig parse subscription config, all the configs are optional.
+func ParseConfig() (*Config, error) {
+	conf := &Config{
+		StartUp:        true,
+		ReloadInterval: defaultReloadIntervalSeconds * time.Second,
+		MaxBackoff:     defaultMaxBackoffSeconds * time.Second,
+	}
+
+	var err error
+	if cc.IsExist("eventServer.subscription.startUp") {
+		conf.StartUp, err = cc.Bool("eventServer.subscription.startUp")
+		if err != nil {
+			blog.Errorf("get eventServer.subscription.startUp error, err: %v", err)
+			return nil, err
+		}
+	}
+
+	if cc.IsExist("eventServer.subscription.reloadIntervalSeconds") {
+		interval, err := cc.Int("eventServer.subscription.reloadIntervalSeconds")
+		if err != nil {
+			blog.Errorf("get eventServer.subscription.reloadIntervalSeconds error, err: %v", err)
+			return nil, err
+		}
+
+		if interval > 0 {
+			conf.ReloadInterval = time.Duration(interval) * time.Second
+		}
+	}
+
+	if cc.IsExist("eventServer.subscription.maxBackoffSeconds") {
+		backoff, err := cc.Int("eventServer.subscription.maxBackoffSeconds")
+		if err != nil {
+			blog.Errorf("get eventServer.subscription.maxBackoffSeconds error, err: %v", err)
+			return nil, err
+		}
+
+		if backoff > 0 {
+			conf.MaxBackoff = time.Duration(backoff) * time.Second
+		}
+	}
+
+	return conf, nil
+}
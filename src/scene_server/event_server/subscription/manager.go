@@ -0,0 +1,142 @@
+/*
+ * Tencent is pleased to support the open source community by making 蓝鲸 available.
+ * Copyright (C) 2017-2018 THL A29 Limited, a Tencent company. All rights reserved.
+ * Licensed under the MIT License (the "License"); you may not use this file except
+ * in compliance with the License. You may obtain a copy of the License at
+ * http://opensource.org/licenses/MIT
+ * Unless required by applicable law or agreed to in writing, software distributed under
+ * the License is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND,
+ * either express or implied. See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+// Package subscription pushes the watched events to the subscribers' callback urls.
+package subscription
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"configcenter/src/common"
+	"configcenter/src/common/backbone"
+	"configcenter/src/common/blog"
+	"configcenter/src/common/util"
+	"configcenter/src/common/watch"
+	"configcenter/src/storage/dal"
+)
+
+// Manager manages the push workers of all the enabled subscriptions, only the master event server runs the workers.
+type Manager struct {
+	ctx    context.Context
+	engine *backbone.Engine
+	db     dal.RDB
+	conf   *Config
+
+	lock sync.Mutex
+	// workers is the running subscription workers, key is the subscription id.
+	workers map[int64]*worker
+	// reloadCh notifies the manager to reload subscriptions immediately.
+	reloadCh chan struct{}
+}
+
+// NewManager new subscription push manager
+func NewManager(ctx context.Context, engine *backbone.Engine, db dal.RDB, conf *Config) *Manager {
+	return &Manager{
+		ctx:      ctx,
+		engine:   engine,
+		db:       db,
+		conf:     conf,
+		workers:  make(map[int64]*worker),
+		reloadCh: make(chan struct{}, 1),
+	}
+}
+
+// Reload notifies the manager to reload the subscriptions, it does not block. the changes made on a
+// slave event server are picked up by the master in the next reload interval.
+func (m *Manager) Reload() {
+	select {
+	case m.reloadCh <- struct{}{}:
+	default:
+	}
+}
+
+// Run loop reloads subscriptions and starts or stops their workers until the context is done.
+func (m *Manager) Run() {
+	ticker := time.NewTicker(m.conf.ReloadInterval)
+	defer ticker.Stop()
+
+	for {
+		if m.engine.Discovery().IsMaster() {
+			m.reload()
+		} else {
+			blog.V(4).Infof("loop push subscription events, but not master, skip.")
+			m.stopAll()
+		}
+
+		select {
+		case <-m.ctx.Done():
+			m.stopAll()
+			blog.Infof("subscription manager stopped")
+			return
+		case <-ticker.C:
+		case <-m.reloadCh:
+		}
+	}
+}
+
+// reload compares the enabled subscriptions with the running workers, starts the new or changed ones,
+// and stops the removed or disabled ones.
+func (m *Manager) reload() {
+	rid := util.GenerateRID()
+
+	subs := make([]*watch.Subscription, 0)
+	filter := map[string]interface{}{"enabled": true}
+	if err := m.db.Table(common.BKTableNameEventSubscription).Find(filter).All(m.ctx, &subs); err != nil {
+		blog.Errorf("reload subscriptions failed, err: %v, rid: %s", err, rid)
+		return
+	}
+
+	m.lock.Lock()
+	defer m.lock.Unlock()
+
+	enabled := make(map[int64]struct{})
+	for _, sub := range subs {
+		enabled[sub.ID] = struct{}{}
+
+		w, exists := m.workers[sub.ID]
+		if exists && w.sub.LastTime.Time.Equal(sub.LastTime.Time) {
+			continue
+		}
+
+		if exists {
+			blog.Infof("subscription %d is changed, restart its worker, rid: %s", sub.ID, rid)
+			w.stop()
+		}
+
+		w = newWorker(m.ctx, m.engine, m.db, m.conf, sub)
+		w.start()
+		m.workers[sub.ID] = w
+		blog.Infof("start subscription %d worker, resources: %v, rid: %s", sub.ID, sub.Resources, rid)
+	}
+
+	for id, w := range m.workers {
+		if _, exists := enabled[id]; exists {
+			continue
+		}
+
+		w.stop()
+		delete(m.workers, id)
+		blog.Infof("subscription %d is removed or disabled, stop its worker, rid: %s", id, rid)
+	}
+}
+
+func (m *Manager) stopAll() {
+	m.lock.Lock()
+	defer m.lock.Unlock()
+
+	for id, w := range m.workers {
+		w.stop()
+		delete(m.workers, id)
+	}
+}
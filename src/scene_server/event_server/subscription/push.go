@@ -0,0 +1,78 @@
+/*
+ * Tencent is pleased to support the open source community by making 蓝鲸 available.
+ * Copyright (C) 2017-2018 THL A29 Limited, a Tencent company. All rights reserved.
+ * Licensed under the MIT License (the "License"); you may not use this file except
+ * in compliance with the License. You may obtain a copy of the License at
+ * http://opensource.org/licenses/MIT
+ * Unless required by applicable law or agreed to in writing, software distributed under
+ * the License is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND,
+ * either express or implied. See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package subscription
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"strconv"
+	"time"
+
+	"configcenter/src/common/watch"
+)
+
+// maxRespBodyLen is the max length of the callback response body that is recorded in the push error.
+const maxRespBodyLen = 512
+
+// pushClient is shared by all the push requests, the timeout is controlled by the request context.
+var pushClient = &http.Client{Transport: http.DefaultTransport}
+
+// Push posts the payload to the subscription's callback url, the request is signed with the subscription's
+// secret if it is set. a push is successful only if the callback url responds with a 2xx status code.
+func Push(ctx context.Context, sub *watch.Subscription, body []byte) error {
+	ctx, cancel := context.WithTimeout(ctx, time.Duration(sub.Timeout)*time.Second)
+	defer cancel()
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, sub.CallbackURL, bytes.NewReader(body))
+	if err != nil {
+		return fmt.Errorf("new push request failed, err: %v", err)
+	}
+
+	timestamp := time.Now().Unix()
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set(watch.SubscriptionIDHeader, strconv.FormatInt(sub.ID, 10))
+	req.Header.Set(watch.SubscriptionTimestampHeader, strconv.FormatInt(timestamp, 10))
+	if len(sub.Secret) != 0 {
+		req.Header.Set(watch.SubscriptionSignatureHeader, watch.SignSubscriptionPayload(sub.Secret, timestamp, body))
+	}
+
+	resp, err := pushClient.Do(req)
+	if err != nil {
+		return fmt.Errorf("do push request failed, err: %v", err)
+	}
+	defer resp.Body.Close()
+
+	respBody, _ := ioutil.ReadAll(io.LimitReader(resp.Body, maxRespBodyLen))
+	if resp.StatusCode < http.StatusOK || resp.StatusCode >= http.StatusMultipleChoices {
+		return fmt.Errorf("push request got http status %d, body: %s", resp.StatusCode, respBody)
+	}
+
+	return nil
+}
+
+// backoff returns the wait duration before the nth retry, it is doubled each time and limited by max.
+func backoff(retry int, max time.Duration) time.Duration {
+	wait := time.Second
+	for i := 1; i < retry && wait < max; i++ {
+		wait *= 2
+	}
+
+	if wait > max {
+		return max
+	}
+	return wait
+}
@@ -0,0 +1,306 @@
+/*
+ * Tencent is pleased to support the open source community by making 蓝鲸 available.
+ * Copyright (C) 2017-2018 THL A29 Limited, a Tencent company. All rights reserved.
+ * Licensed under the MIT License (the "License"); you may not use this file except
+ * in compliance with the License. You may obtain a copy of the License at
+ * http://opensource.org/licenses/MIT
+ * Unless required by applicable law or agreed to in writing, software distributed under
+ * the License is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND,
+ * either express or implied. See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package subscription
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"sync"
+	"time"
+
+	"configcenter/src/common"
+	"configcenter/src/common/backbone"
+	"configcenter/src/common/blog"
+	"configcenter/src/common/metadata"
+	"configcenter/src/common/util"
+	"configcenter/src/common/watch"
+	"configcenter/src/storage/dal"
+)
+
+const (
+	// errWaitDuration is the wait duration before the next watch when an error occurs.
+	errWaitDuration = 3 * time.Second
+)
+
+// worker watches all the resources of a subscription, each resource is watched in its own goroutine
+// with its own cursor. events are pushed in batches and the cursor is only saved after the batch is
+// pushed successfully or dead lettered, so the events are delivered at least once.
+type worker struct {
+	ctx    context.Context
+	cancel context.CancelFunc
+	wg     sync.WaitGroup
+	engine *backbone.Engine
+	db     dal.RDB
+	conf   *Config
+	sub    *watch.Subscription
+}
+
+func newWorker(ctx context.Context, engine *backbone.Engine, db dal.RDB, conf *Config,
+	sub *watch.Subscription) *worker {
+
+	ctx, cancel := context.WithCancel(ctx)
+	return &worker{
+		ctx:    ctx,
+		cancel: cancel,
+		engine: engine,
+		db:     db,
+		conf:   conf,
+		sub:    sub,
+	}
+}
+
+func (w *worker) start() {
+	for _, resource := range w.sub.Resources {
+		w.wg.Add(1)
+		go func(resource watch.CursorType) {
+			defer w.wg.Done()
+			w.watch(resource)
+		}(resource)
+	}
+}
+
+// stop stops the worker and waits until all its goroutines exit.
+func (w *worker) stop() {
+	w.cancel()
+	w.wg.Wait()
+}
+
+func (w *worker) isStopped() bool {
+	select {
+	case <-w.ctx.Done():
+		return true
+	default:
+		return false
+	}
+}
+
+// sleep waits for the duration, returns false if the worker is stopped during the wait.
+func (w *worker) sleep(duration time.Duration) bool {
+	select {
+	case <-w.ctx.Done():
+		return false
+	case <-time.After(duration):
+		return true
+	}
+}
+
+func (w *worker) watch(resource watch.CursorType) {
+	cursor, ok := w.getCursor(resource)
+	if !ok {
+		return
+	}
+
+	errFreq := util.NewErrFrequency(nil)
+	for !w.isStopped() {
+		header, rid := newHeaderWithRid(w.sub.OwnerID)
+		opts := w.sub.WatchOptions(resource, cursor)
+
+		resp, err := w.engine.CoreAPI.CacheService().Cache().Event().WatchEvent(w.ctx, header, opts)
+		if err != nil {
+			if w.isStopped() {
+				return
+			}
+
+			// the cursor is expired or the same error keeps occurring, reset to watch from now.
+			if err.GetCode() == common.CCErrEventChainNodeNotExist || errFreq.IsErrAlwaysAppear(err) {
+				blog.Errorf("subscription %d watch %s failed, reset to watch from now, cursor: %s, err: %v, rid: %s",
+					w.sub.ID, resource, cursor, err, rid)
+				cursor = ""
+				errFreq.Release()
+			} else {
+				blog.Errorf("subscription %d watch %s failed, cursor: %s, err: %v, rid: %s", w.sub.ID, resource,
+					cursor, err, rid)
+			}
+
+			w.sleep(errWaitDuration)
+			continue
+		}
+		errFreq.Release()
+
+		watchResp := new(watch.WatchResp)
+		if err := json.Unmarshal([]byte(*resp), watchResp); err != nil {
+			blog.Errorf("subscription %d unmarshal %s watch response failed, err: %v, rid: %s", w.sub.ID, resource,
+				err, rid)
+			w.sleep(errWaitDuration)
+			continue
+		}
+
+		if len(watchResp.Events) == 0 {
+			continue
+		}
+
+		lastCursor := watchResp.Events[len(watchResp.Events)-1].Cursor
+
+		// watch from now or no event is matched, the returned cursor is only used to locate the start point.
+		if !watchResp.Watched || len(cursor) == 0 {
+			if lastCursor != cursor {
+				cursor = lastCursor
+				w.saveCursor(resource, cursor, rid)
+			}
+			continue
+		}
+
+		for start := 0; start < len(watchResp.Events); start += w.sub.BatchSize {
+			end := start + w.sub.BatchSize
+			if end > len(watchResp.Events) {
+				end = len(watchResp.Events)
+			}
+
+			batch := watchResp.Events[start:end]
+			if !w.deliver(resource, batch, rid) {
+				// worker is stopped, the undelivered events will be watched again by the next worker.
+				return
+			}
+
+			cursor = batch[len(batch)-1].Cursor
+			w.saveCursor(resource, cursor, rid)
+		}
+	}
+}
+
+// deliver pushes a batch of events with retries, the batch is dead lettered if it keeps failing.
+// returns false only if the worker is stopped before the batch is delivered or dead lettered.
+func (w *worker) deliver(resource watch.CursorType, events []*watch.WatchEventDetail, rid string) bool {
+	payload := &watch.SubscriptionPayload{
+		SubscriptionID: w.sub.ID,
+		Resource:       resource,
+		Events:         events,
+	}
+
+	body, err := json.Marshal(payload)
+	if err != nil {
+		// this should not happen, the events are just unmarshalled from the watch response.
+		blog.Errorf("subscription %d marshal %s push payload failed, err: %v, rid: %s", w.sub.ID, resource, err, rid)
+		return true
+	}
+
+	attempts := 0
+	for {
+		attempts++
+		err = Push(w.ctx, w.sub, body)
+		if err == nil {
+			return true
+		}
+
+		if w.isStopped() {
+			return false
+		}
+
+		blog.Errorf("subscription %d push %d %s events failed, attempts: %d, err: %v, rid: %s", w.sub.ID,
+			len(events), resource, attempts, err, rid)
+
+		if attempts > w.sub.MaxRetry {
+			break
+		}
+
+		if !w.sleep(backoff(attempts, w.conf.MaxBackoff)) {
+			return false
+		}
+	}
+
+	now := metadata.Now()
+	letter := &watch.SubscriptionDeadLetter{
+		SubscriptionID: w.sub.ID,
+		Resource:       resource,
+		Payload:        string(body),
+		FirstCursor:    events[0].Cursor,
+		LastCursor:     events[len(events)-1].Cursor,
+		Attempts:       attempts,
+		Error:          err.Error(),
+		OwnerID:        w.sub.OwnerID,
+		CreateTime:     now,
+		LastTime:       now,
+	}
+
+	for !w.isStopped() {
+		if err := SaveDeadLetter(w.ctx, w.db, letter); err != nil {
+			blog.Errorf("subscription %d save dead letter failed, err: %v, rid: %s", w.sub.ID, err, rid)
+			w.sleep(errWaitDuration)
+			continue
+		}
+
+		blog.Warnf("subscription %d push %s events still failed after %d attempts, dead letter %d is saved, rid: %s",
+			w.sub.ID, resource, attempts, letter.ID, rid)
+		return true
+	}
+
+	return false
+}
+
+// getCursor get the saved cursor of the resource, empty cursor means watch from now.
+// returns false if the worker is stopped before the cursor is got.
+func (w *worker) getCursor(resource watch.CursorType) (string, bool) {
+	filter := map[string]interface{}{
+		"subscription_id": w.sub.ID,
+		"bk_resource":     resource,
+	}
+
+	for !w.isStopped() {
+		cursor := new(watch.SubscriptionCursor)
+		err := w.db.Table(common.BKTableNameEventSubscriptionCursor).Find(filter).One(w.ctx, cursor)
+		if err == nil {
+			return cursor.Cursor, true
+		}
+
+		if w.db.IsNotFoundError(err) {
+			return "", true
+		}
+
+		blog.Errorf("get subscription %d %s cursor failed, err: %v", w.sub.ID, resource, err)
+		w.sleep(errWaitDuration)
+	}
+
+	return "", false
+}
+
+func (w *worker) saveCursor(resource watch.CursorType, cursor string, rid string) {
+	filter := map[string]interface{}{
+		"subscription_id": w.sub.ID,
+		"bk_resource":     resource,
+	}
+
+	doc := &watch.SubscriptionCursor{
+		SubscriptionID: w.sub.ID,
+		Resource:       resource,
+		Cursor:         cursor,
+		OwnerID:        w.sub.OwnerID,
+		LastTime:       metadata.Now(),
+	}
+
+	// a failed save only causes the events to be pushed again, so do not block the push.
+	if err := w.db.Table(common.BKTableNameEventSubscriptionCursor).Upsert(w.ctx, filter, doc); err != nil {
+		blog.Errorf("save subscription %d %s cursor %s failed, err: %v, rid: %s", w.sub.ID, resource, cursor, err,
+			rid)
+	}
+}
+
+// SaveDeadLetter generates an id for the dead letter and saves it to db.
+func SaveDeadLetter(ctx context.Context, db dal.RDB, letter *watch.SubscriptionDeadLetter) error {
+	id, err := db.NextSequence(ctx, common.BKTableNameEventSubscriptionDeadLetter)
+	if err != nil {
+		return err
+	}
+	letter.ID = int64(id)
+
+	return db.Table(common.BKTableNameEventSubscriptionDeadLetter).Insert(ctx, letter)
+}
+
+func newHeaderWithRid(ownerID string) (http.Header, string) {
+	header := http.Header{}
+	header.Add(common.BKHTTPOwnerID, ownerID)
+	header.Add(common.BKHTTPHeaderUser, common.CCSystemOperatorUserName)
+	rid := util.GenerateRID()
+	header.Add(common.BKHTTPCCRequestID, rid)
+	return header, rid
+}
@@ -113,6 +113,8 @@ func Run(ctx context.Context, cancel context.CancelFunc, op *options.ServerOptio
 		return err
 	}
 
+	go service.Logic.RunHostApplyReconciler(ctx)
+
 	select {
 	case <-ctx.Done():
 	}
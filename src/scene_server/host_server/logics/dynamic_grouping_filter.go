@@ -0,0 +1,371 @@
+/*
+ * Tencent is pleased to support the open source community by making 蓝鲸 available.
+ * Copyright (C) 2017-2018 THL A29 Limited, a Tencent company. All rights reserved.
+ * Licensed under the MIT License (the "License"); you may not use this file except
+ * in compliance with the License. You may obtain a copy of the License at
+ * http://opensource.org/licenses/MIT
+ * Unless required by applicable law or agreed to in writing, software distributed under
+ * the License is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND,
+ * either express or implied. See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package logics
+
+import (
+	"strings"
+
+	"configcenter/pkg/filter"
+	"configcenter/src/ac"
+	"configcenter/src/ac/meta"
+	"configcenter/src/common"
+	"configcenter/src/common/blog"
+	"configcenter/src/common/http/rest"
+	"configcenter/src/common/mapstr"
+	"configcenter/src/common/metadata"
+	"configcenter/src/common/util"
+)
+
+// ExecuteFilterDynamicGroup executes dynamic group with filter expression conditions, returns the instances of
+// the dynamic group's target object.
+func (lgc *Logics) ExecuteFilterDynamicGroup(kit *rest.Kit, group *metadata.DynamicGroup,
+	input *metadata.QueryCondition) (*metadata.InstDataInfo, error) {
+
+	mainlineObjs, err := lgc.GetCustomMainlineObjects(kit)
+	if err != nil {
+		return nil, err
+	}
+
+	executor := &filterDynamicGroupExecutor{
+		kit:          kit,
+		lgc:          lgc,
+		bizID:        group.AppID,
+		input:        input,
+		conds:        make(map[string]mapstr.MapStr),
+		mainlineObjs: mainlineObjs,
+	}
+
+	if err := executor.parseCondition(group.Info.FilterCondition); err != nil {
+		return nil, err
+	}
+
+	switch group.ObjID {
+	case common.BKInnerObjIDHost:
+		return executor.searchHosts()
+	case common.BKInnerObjIDSet:
+		return executor.searchSets()
+	default:
+		return executor.searchInstances(group.ObjID)
+	}
+}
+
+// filterDynamicGroupExecutor handle dynamic group with filter expression conditions.
+type filterDynamicGroupExecutor struct {
+	kit   *rest.Kit
+	lgc   *Logics
+	bizID int64
+	input *metadata.QueryCondition
+
+	// conds is the map of condition object id to its mongodb condition.
+	conds map[string]mapstr.MapStr
+	// mainlineObjs is the custom mainline objects from business to set.
+	mainlineObjs []string
+}
+
+func (e *filterDynamicGroupExecutor) parseCondition(conditions []metadata.DynamicGroupFilterCondition) error {
+	for _, cond := range conditions {
+		if cond.Filter == nil {
+			continue
+		}
+
+		if cond.ObjID == common.BKInnerObjIDHost {
+			if err := convertHostIPv6Rule(cond.Filter.RuleFactory); err != nil {
+				blog.Errorf("convert host ipv6 filter failed, err: %v, filter: %s, rid: %s", err,
+					cond.Filter.String(), e.kit.Rid)
+				return e.kit.CCError.CCErrorf(common.CCErrCommParamsInvalid, "filter_condition")
+			}
+		}
+
+		mgoCond, err := cond.Filter.ToMgo()
+		if err != nil {
+			blog.Errorf("parse dynamic group filter failed, err: %v, filter: %s, rid: %s", err,
+				cond.Filter.String(), e.kit.Rid)
+			return e.kit.CCError.CCErrorf(common.CCErrCommParamsInvalid, "filter_condition")
+		}
+		e.conds[cond.ObjID] = mgoCond
+	}
+	return nil
+}
+
+// convertHostIPv6Rule converts host ipv6 rule values to the full format that is stored in db.
+func convertHostIPv6Rule(rule filter.RuleFactory) error {
+	switch r := rule.(type) {
+	case *filter.CombinedRule:
+		for _, subRule := range r.Rules {
+			if err := convertHostIPv6Rule(subRule); err != nil {
+				return err
+			}
+		}
+	case *filter.AtomRule:
+		switch r.Operator {
+		case filter.OpFactory(filter.Equal), filter.OpFactory(filter.NotEqual), filter.OpFactory(filter.In),
+			filter.OpFactory(filter.NotIn):
+			value, err := common.ConvertIpv6ToFullWord(r.Field, r.Value)
+			if err != nil {
+				return err
+			}
+			r.Value = value
+		}
+	}
+	return nil
+}
+
+// searchHosts searches hosts in business that matches the host and its topology conditions.
+func (e *filterDynamicGroupExecutor) searchHosts() (*metadata.InstDataInfo, error) {
+	topoRelation := &metadata.DistinctHostIDByTopoRelationRequest{ApplicationIDArr: []int64{e.bizID}}
+
+	setIDs, isFiltered, err := e.searchSetIDs()
+	if err != nil {
+		return nil, err
+	}
+
+	if isFiltered {
+		if len(setIDs) == 0 {
+			return &metadata.InstDataInfo{Info: make([]mapstr.MapStr, 0)}, nil
+		}
+		topoRelation.SetIDArr = setIDs
+	}
+
+	if moduleCond, exists := e.conds[common.BKInnerObjIDModule]; exists {
+		moduleIDs, err := e.searchInstIDs(common.BKInnerObjIDModule, e.bizCond(moduleCond, common.BKSetIDField,
+			topoRelation.SetIDArr))
+		if err != nil {
+			return nil, err
+		}
+
+		if len(moduleIDs) == 0 {
+			return &metadata.InstDataInfo{Info: make([]mapstr.MapStr, 0)}, nil
+		}
+		topoRelation.ModuleIDArr = moduleIDs
+	}
+
+	hostIDs, err := e.lgc.CoreAPI.CoreService().Host().GetDistinctHostIDByTopology(e.kit.Ctx, e.kit.Header,
+		topoRelation)
+	if err != nil {
+		blog.Errorf("get host ids by topology failed, err: %v, input: %+v, rid: %s", err, topoRelation, e.kit.Rid)
+		return nil, err
+	}
+
+	if len(hostIDs) == 0 {
+		return &metadata.InstDataInfo{Info: make([]mapstr.MapStr, 0)}, nil
+	}
+
+	conds := []map[string]interface{}{{common.BKHostIDField: mapstr.MapStr{common.BKDBIN: hostIDs}}}
+	if hostCond, exists := e.conds[common.BKInnerObjIDHost]; exists && len(hostCond) > 0 {
+		conds = append(conds, hostCond)
+	}
+
+	fields := e.input.Fields
+	if len(fields) != 0 {
+		fields = append(fields, common.BKHostIDField, common.BKCloudIDField)
+	}
+
+	query := &metadata.QueryInput{
+		Condition:      map[string]interface{}{common.BKDBAND: conds},
+		Fields:         strings.Join(fields, ","),
+		Start:          e.input.Page.Start,
+		Limit:          e.input.Page.Limit,
+		Sort:           e.input.Page.Sort,
+		DisableCounter: e.input.DisableCounter,
+	}
+
+	result, err := e.lgc.CoreAPI.CoreService().Host().GetHosts(e.kit.Ctx, e.kit.Header, query)
+	if err != nil {
+		blog.Errorf("get hosts failed, err: %v, input: %+v, rid: %s", err, query, e.kit.Rid)
+		return nil, err
+	}
+
+	return &metadata.InstDataInfo{Count: result.Count, Info: result.Info}, nil
+}
+
+// searchSets searches sets in business that matches the set and its custom mainline topology conditions.
+func (e *filterDynamicGroupExecutor) searchSets() (*metadata.InstDataInfo, error) {
+	setIDs, isFiltered, err := e.searchMainlineSetIDs()
+	if err != nil {
+		return nil, err
+	}
+
+	if isFiltered && len(setIDs) == 0 {
+		return &metadata.InstDataInfo{Info: make([]mapstr.MapStr, 0)}, nil
+	}
+
+	return e.readInstances(common.BKInnerObjIDSet, e.bizCond(e.conds[common.BKInnerObjIDSet], common.BKSetIDField,
+		setIDs))
+}
+
+// searchInstances searches custom mainline object instances in business or common object instances. the common
+// object instances are not in business, so the user must have the permission to find the searched instances.
+func (e *filterDynamicGroupExecutor) searchInstances(objID string) (*metadata.InstDataInfo, error) {
+	cond := e.conds[objID]
+	if util.InStrArr(e.mainlineObjs, objID) {
+		return e.readInstances(objID, e.bizCond(cond, "", nil))
+	}
+
+	if cond == nil {
+		cond = make(mapstr.MapStr)
+	}
+
+	idField := common.GetInstIDField(objID)
+	if len(e.input.Fields) != 0 && !util.InStrArr(e.input.Fields, idField) {
+		e.input.Fields = append(e.input.Fields, idField)
+	}
+
+	result, err := e.readInstances(objID, cond)
+	if err != nil {
+		return nil, err
+	}
+
+	instIDs := make([]int64, len(result.Info))
+	for idx, inst := range result.Info {
+		id, err := inst.Int64(idField)
+		if err != nil {
+			blog.Errorf("parse %s instance id failed, err: %v, inst: %+v, rid: %s", objID, err, inst, e.kit.Rid)
+			return nil, e.kit.CCError.CCErrorf(common.CCErrCommInstFieldConvertFail, objID, idField, "int",
+				err.Error())
+		}
+		instIDs[idx] = id
+	}
+
+	err = e.lgc.AuthManager.AuthorizeByInstanceID(e.kit.Ctx, e.kit.Header, meta.Find, objID, instIDs...)
+	if err != nil {
+		blog.Errorf("authorize %s instances %v failed, err: %v, rid: %s", objID, instIDs, err, e.kit.Rid)
+		if err == ac.NoAuthorizeError {
+			return nil, e.kit.CCError.CCError(common.CCErrCommAuthNotHavePermission)
+		}
+		return nil, e.kit.CCError.CCError(common.CCErrCommAuthorizeFailed)
+	}
+
+	return result, nil
+}
+
+// searchSetIDs searches set ids by set and custom mainline object conditions, returns if the sets are filtered.
+func (e *filterDynamicGroupExecutor) searchSetIDs() ([]int64, bool, error) {
+	setIDs, isFiltered, err := e.searchMainlineSetIDs()
+	if err != nil {
+		return nil, false, err
+	}
+
+	setCond, exists := e.conds[common.BKInnerObjIDSet]
+	if !exists || (isFiltered && len(setIDs) == 0) {
+		return setIDs, isFiltered, nil
+	}
+
+	setIDs, err = e.searchInstIDs(common.BKInnerObjIDSet, e.bizCond(setCond, common.BKSetIDField, setIDs))
+	if err != nil {
+		return nil, false, err
+	}
+	return setIDs, true, nil
+}
+
+// searchMainlineSetIDs searches set ids by custom mainline object conditions, returns if the sets are filtered.
+func (e *filterDynamicGroupExecutor) searchMainlineSetIDs() ([]int64, bool, error) {
+	var setIDs []int64
+	isFiltered := false
+
+	for _, objID := range e.mainlineObjs {
+		cond, exists := e.conds[objID]
+		if !exists {
+			continue
+		}
+
+		instIDs, err := e.searchInstIDs(objID, e.bizCond(cond, "", nil))
+		if err != nil {
+			return nil, false, err
+		}
+
+		if len(instIDs) == 0 {
+			return make([]int64, 0), true, nil
+		}
+
+		ids, err := e.lgc.GetSetIDsByTopo(e.kit, objID, instIDs)
+		if err != nil {
+			return nil, false, err
+		}
+
+		// sets must match all the custom mainline object conditions
+		if isFiltered {
+			ids = util.IntArrIntersection(setIDs, ids)
+		}
+
+		if len(ids) == 0 {
+			return make([]int64, 0), true, nil
+		}
+
+		setIDs = ids
+		isFiltered = true
+	}
+
+	return setIDs, isFiltered, nil
+}
+
+// bizCond merge condition with business id condition and the instance ids condition if instIDs is not empty.
+func (e *filterDynamicGroupExecutor) bizCond(cond mapstr.MapStr, idField string, instIDs []int64) mapstr.MapStr {
+	conds := []map[string]interface{}{{common.BKAppIDField: e.bizID}}
+	if len(instIDs) > 0 {
+		conds = append(conds, map[string]interface{}{idField: mapstr.MapStr{common.BKDBIN: instIDs}})
+	}
+
+	if len(cond) > 0 {
+		conds = append(conds, cond)
+	}
+
+	return mapstr.MapStr{common.BKDBAND: conds}
+}
+
+func (e *filterDynamicGroupExecutor) readInstances(objID string, cond mapstr.MapStr) (*metadata.InstDataInfo,
+	error) {
+
+	query := &metadata.QueryCondition{
+		Condition:      cond,
+		Fields:         e.input.Fields,
+		Page:           e.input.Page,
+		DisableCounter: e.input.DisableCounter,
+	}
+
+	result, err := e.lgc.CoreAPI.CoreService().Instance().ReadInstance(e.kit.Ctx, e.kit.Header, objID, query)
+	if err != nil {
+		blog.Errorf("search %s instances failed, err: %v, input: %+v, rid: %s", objID, err, query, e.kit.Rid)
+		return nil, err
+	}
+
+	return result, nil
+}
+
+func (e *filterDynamicGroupExecutor) searchInstIDs(objID string, cond mapstr.MapStr) ([]int64, error) {
+	idField := common.GetInstIDField(objID)
+	query := &metadata.QueryCondition{
+		Condition:      cond,
+		Fields:         []string{idField},
+		Page:           metadata.BasePage{Limit: common.BKNoLimit},
+		DisableCounter: true,
+	}
+
+	result, err := e.lgc.CoreAPI.CoreService().Instance().ReadInstance(e.kit.Ctx, e.kit.Header, objID, query)
+	if err != nil {
+		blog.Errorf("search %s instance ids failed, err: %v, input: %+v, rid: %s", objID, err, query, e.kit.Rid)
+		return nil, err
+	}
+
+	instIDs := make([]int64, len(result.Info))
+	for idx, inst := range result.Info {
+		id, err := inst.Int64(idField)
+		if err != nil {
+			blog.Errorf("parse %s instance id failed, err: %v, inst: %+v, rid: %s", objID, err, inst, e.kit.Rid)
+			return nil, e.kit.CCError.CCErrorf(common.CCErrCommInstFieldConvertFail, objID, idField, "int",
+				err.Error())
+		}
+		instIDs[idx] = id
+	}
+
+	return instIDs, nil
+}
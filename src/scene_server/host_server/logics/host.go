@@ -343,6 +343,11 @@ func (lgc *Logics) GetHostModuleRelation(kit *rest.Kit, cond *metadata.HostModul
 // delete old business  host and module relation
 func (lgc *Logics) TransferHostAcrossBusiness(kit *rest.Kit, srcBizID, dstAppID int64, hostID []int64,
 	moduleID int64) errors.CCError {
+
+	if err := lgc.ValidateHostLock(kit, hostID, metadata.HostLockScopeTransfer); err != nil {
+		return err
+	}
+
 	// get both biz's resource set's modules
 	query := &metadata.QueryCondition{
 		Fields: []string{common.BKModuleIDField, common.BKAppIDField},
@@ -610,6 +615,11 @@ func (lgc *Logics) TransferResourceHostsAcrossBusiness(kit *rest.Kit, transResou
 			transResources, dstAppID, moduleID, err, kit.Rid)
 		return err
 	}
+
+	if err := lgc.ValidateHostLock(kit, hostIDs, metadata.HostLockScopeTransfer); err != nil {
+		return err
+	}
+
 	// step 2: 校验业务信息是否正确
 	if err := lgc.transResourcesValidateBizParams(kit, transResources, dstAppID); err != nil {
 		return err
@@ -674,6 +684,10 @@ func (lgc *Logics) DeleteHostFromBusiness(kit *rest.Kit, bizID int64, hostIDArr
 		return nil, nil
 	}
 
+	if err := lgc.ValidateHostLock(kit, hostIDArr, metadata.HostLockScopeDelete); err != nil {
+		return nil, err
+	}
+
 	// ready audit log of delete host.
 	audit := auditlog.NewHostAudit(lgc.CoreAPI.CoreService())
 	generateAuditParameter := auditlog.NewGenerateAuditCommonParameter(kit, metadata.AuditDelete)
@@ -709,6 +723,10 @@ func (lgc *Logics) DeleteHostFromBusiness(kit *rest.Kit, bizID int64, hostIDArr
 // CloneHostProperty clone host info and host and module relation in same application
 func (lgc *Logics) CloneHostProperty(kit *rest.Kit, appID int64, srcHostID int64, dstHostID int64) errors.CCErrorCoder {
 
+	if err := lgc.ValidateHostLock(kit, []int64{dstHostID}, metadata.HostLockScopeUpdate); err != nil {
+		return err
+	}
+
 	// check if source host and destination host both belong to biz
 	relReq := &metadata.DistinctHostIDByTopoRelationRequest{
 		ApplicationIDArr: []int64{appID},
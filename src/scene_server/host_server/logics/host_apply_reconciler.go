@@ -0,0 +1,539 @@
+/*
+ * Tencent is pleased to support the open source community by making 蓝鲸 available.
+ * Copyright (C) 2017-2018 THL A29 Limited, a Tencent company. All rights reserved.
+ * Licensed under the MIT License (the "License"); you may not use this file except
+ * in compliance with the License. You may obtain a copy of the License at
+ * http://opensource.org/licenses/MIT
+ * Unless required by applicable law or agreed to in writing, software distributed under
+ * the License is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND,
+ * either express or implied. See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package logics
+
+import (
+	"context"
+	"time"
+
+	"configcenter/src/common"
+	"configcenter/src/common/auditlog"
+	"configcenter/src/common/blog"
+	"configcenter/src/common/errors"
+	"configcenter/src/common/http/rest"
+	"configcenter/src/common/json"
+	"configcenter/src/common/metadata"
+	"configcenter/src/common/util"
+	"configcenter/src/common/watch"
+	"configcenter/src/storage/dal/redis"
+
+	"github.com/tidwall/gjson"
+)
+
+const (
+	// hostApplyReconcileCursorKey is the redis key to store the host event cursor of the host apply reconciler
+	hostApplyReconcileCursorKey = common.BKCacheKeyV3Prefix + "host_apply_reconcile:cursor"
+	// hostApplyReconcileFailedKey is the redis hash key to record the hosts that failed to be reconciled, the field
+	// is the supplier account, the value is the json of the failed hostEvents of the supplier account.
+	hostApplyReconcileFailedKey = common.BKCacheKeyV3Prefix + "host_apply_reconcile:failed"
+	// hostApplyReconcileRetryTimes is the retry times of reconciling the host events of a supplier account
+	hostApplyReconcileRetryTimes = 3
+	// hostApplyReconcileFailedInterval is the interval to reconcile the recorded failed hosts again
+	hostApplyReconcileFailedInterval = 5 * time.Minute
+)
+
+// RunHostApplyReconciler watch the host update and delete events on master, the updated hosts are reconciled with
+// the host apply rules of their modules by the reconcile mode of their business, the drifts of deleted hosts are
+// removed. the watch cursor is saved in redis so that the new master can resume from it.
+func (lgc *Logics) RunHostApplyReconciler(ctx context.Context) {
+	cursor := ""
+	preMaster := false
+	lastFailedTime := time.Now()
+	errFreq := util.NewErrFrequency(nil)
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		default:
+		}
+
+		if !lgc.Discovery().IsMaster() {
+			preMaster = false
+			time.Sleep(10 * time.Second)
+			continue
+		}
+
+		// the host events of all supplier accounts are watched, they are reconciled by their own supplier accounts
+		header := util.BuildHeader(common.CCSystemOperatorUserName, common.BKDefaultOwnerID)
+		rid := util.GetHTTPCCRequestID(header)
+
+		// the cursor may be changed by the previous master, get the latest one from redis
+		if !preMaster {
+			cursor = lgc.getHostApplyReconcileCursor(ctx, rid)
+			preMaster = true
+		}
+
+		opts := &watch.WatchEventOptions{
+			EventTypes: []watch.EventType{watch.Update, watch.Delete},
+			Fields:     []string{common.BKHostIDField, common.BkSupplierAccount},
+			Resource:   watch.Host,
+			Cursor:     cursor,
+		}
+
+		resp, err := lgc.CoreAPI.CacheService().Cache().Event().WatchEvent(ctx, header, opts)
+		if err != nil {
+			// the cursor is expired or the same error keeps occurring, reset to watch from now.
+			if err.GetCode() == common.CCErrEventChainNodeNotExist || errFreq.IsErrAlwaysAppear(err) {
+				blog.Errorf("watch host event failed, reset to watch from now, cursor: %s, err: %v, rid: %s", cursor,
+					err, rid)
+				cursor = ""
+				errFreq.Release()
+			} else {
+				blog.Errorf("watch host event failed, cursor: %s, err: %v, rid: %s", cursor, err, rid)
+			}
+			time.Sleep(time.Second)
+			continue
+		}
+		errFreq.Release()
+
+		watchResp := new(watch.WatchResp)
+		if err := json.Unmarshal([]byte(*resp), watchResp); err != nil {
+			blog.Errorf("unmarshal host watch response failed, err: %v, rid: %s", err, rid)
+			time.Sleep(time.Second)
+			continue
+		}
+
+		if len(watchResp.Events) == 0 {
+			continue
+		}
+
+		lastCursor := watchResp.Events[len(watchResp.Events)-1].Cursor
+
+		// no event is matched, the returned cursor is only used to locate the start point.
+		if !watchResp.Watched {
+			cursor = lastCursor
+			lgc.saveHostApplyReconcileCursor(ctx, cursor, rid)
+			continue
+		}
+
+		// the hosts that still failed to be reconciled after retries are recorded and reconciled again later, the
+		// cursor is always moved forward so that the following events are not blocked by them.
+		for supplierAccount, hostEvent := range groupHostEvents(watchResp.Events) {
+			if err := lgc.reconcileHostEventsWithRetry(supplierAccount, hostEvent); err != nil {
+				blog.Errorf("reconcile host events failed, supplier account: %s, updated hosts: %v, deleted hosts: %v, "+
+					"err: %v, rid: %s", supplierAccount, hostEvent.UpdatedHostIDs, hostEvent.DeletedHostIDs, err, rid)
+				lgc.recordHostApplyReconcileFailure(ctx, supplierAccount, hostEvent, rid)
+			}
+		}
+
+		cursor = lastCursor
+		lgc.saveHostApplyReconcileCursor(ctx, cursor, rid)
+
+		if time.Since(lastFailedTime) > hostApplyReconcileFailedInterval {
+			lgc.reconcileFailedHosts(ctx, rid)
+			lastFailedTime = time.Now()
+		}
+	}
+}
+
+// hostEvents is the host ids of the updated and deleted host events of a supplier account
+type hostEvents struct {
+	UpdatedHostIDs []int64 `json:"updated"`
+	DeletedHostIDs []int64 `json:"deleted"`
+}
+
+// merge the host ids of another hostEvents into this one, a deleted host is removed from the updated hosts
+func (h *hostEvents) merge(other *hostEvents) {
+	deleted := make(map[int64]struct{})
+	for _, hostID := range h.DeletedHostIDs {
+		deleted[hostID] = struct{}{}
+	}
+	for _, hostID := range other.DeletedHostIDs {
+		if _, exists := deleted[hostID]; !exists {
+			deleted[hostID] = struct{}{}
+			h.DeletedHostIDs = append(h.DeletedHostIDs, hostID)
+		}
+	}
+
+	updated := make(map[int64]struct{})
+	updatedHostIDs := make([]int64, 0)
+	for _, hostID := range append(append([]int64{}, h.UpdatedHostIDs...), other.UpdatedHostIDs...) {
+		if _, exists := deleted[hostID]; exists {
+			continue
+		}
+		if _, exists := updated[hostID]; !exists {
+			updated[hostID] = struct{}{}
+			updatedHostIDs = append(updatedHostIDs, hostID)
+		}
+	}
+	h.UpdatedHostIDs = updatedHostIDs
+}
+
+// groupHostEvents group the host ids in the host events by their supplier accounts, the hosts without supplier
+// account belong to the default supplier account. a host deleted in the events is not reconciled as updated host.
+func groupHostEvents(events []*watch.WatchEventDetail) map[string]*hostEvents {
+	supplierEvents := make(map[string]*hostEvents)
+	for _, event := range events {
+		detail, ok := event.Detail.(watch.JsonString)
+		if !ok {
+			continue
+		}
+
+		hostID := gjson.Get(string(detail), common.BKHostIDField).Int()
+		if hostID == 0 {
+			continue
+		}
+
+		supplierAccount := gjson.Get(string(detail), common.BkSupplierAccount).String()
+		if len(supplierAccount) == 0 {
+			supplierAccount = common.BKDefaultOwnerID
+		}
+
+		if _, exists := supplierEvents[supplierAccount]; !exists {
+			supplierEvents[supplierAccount] = new(hostEvents)
+		}
+		hostEvent := supplierEvents[supplierAccount]
+
+		switch event.EventType {
+		case watch.Update:
+			hostEvent.UpdatedHostIDs = append(hostEvent.UpdatedHostIDs, hostID)
+		case watch.Delete:
+			hostEvent.DeletedHostIDs = append(hostEvent.DeletedHostIDs, hostID)
+		}
+	}
+
+	// merge into an empty one to remove the duplicate and deleted hosts from the updated hosts
+	for supplierAccount, hostEvent := range supplierEvents {
+		merged := new(hostEvents)
+		merged.merge(hostEvent)
+		supplierEvents[supplierAccount] = merged
+	}
+
+	return supplierEvents
+}
+
+// reconcileHostEventsWithRetry reconcile the host events of a supplier account, retry if it is failed
+func (lgc *Logics) reconcileHostEventsWithRetry(supplierAccount string, hostEvent *hostEvents) error {
+	header := util.BuildHeader(common.CCSystemOperatorUserName, supplierAccount)
+	kit := rest.NewKitFromHeader(header, lgc.CCErr)
+
+	var err error
+	for retry := 0; retry < hostApplyReconcileRetryTimes; retry++ {
+		if err = lgc.reconcileSupplierHosts(kit, hostEvent.UpdatedHostIDs, hostEvent.DeletedHostIDs); err == nil {
+			return nil
+		}
+		time.Sleep(time.Duration(retry+1) * time.Second)
+	}
+	return err
+}
+
+// recordHostApplyReconcileFailure merge the failed host events of the supplier account into the failure record
+func (lgc *Logics) recordHostApplyReconcileFailure(ctx context.Context, supplierAccount string,
+	hostEvent *hostEvents, rid string) {
+
+	failed := new(hostEvents)
+	value, err := lgc.cache.HGet(ctx, hostApplyReconcileFailedKey, supplierAccount).Result()
+	if err != nil && !redis.IsNilErr(err) {
+		blog.Errorf("get host apply reconcile failure record failed, supplier account: %s, err: %v, rid: %s",
+			supplierAccount, err, rid)
+	}
+	if err == nil {
+		if err := json.UnmarshalFromString(value, failed); err != nil {
+			blog.Errorf("unmarshal host apply reconcile failure record %s failed, err: %v, rid: %s", value, err, rid)
+		}
+	}
+	failed.merge(hostEvent)
+
+	value, err = json.MarshalToString(failed)
+	if err != nil {
+		blog.Errorf("marshal host apply reconcile failure record failed, record: %+v, err: %v, rid: %s", failed,
+			err, rid)
+		return
+	}
+
+	if err := lgc.cache.HSet(ctx, hostApplyReconcileFailedKey, supplierAccount, value).Err(); err != nil {
+		blog.Errorf("save host apply reconcile failure record failed, supplier account: %s, record: %s, err: %v, "+
+			"rid: %s", supplierAccount, value, err, rid)
+	}
+}
+
+// reconcileFailedHosts reconcile the recorded failed hosts again, the record of a supplier account is removed once
+// its hosts are reconciled successfully.
+func (lgc *Logics) reconcileFailedHosts(ctx context.Context, rid string) {
+	records, err := lgc.cache.HGetAll(ctx, hostApplyReconcileFailedKey).Result()
+	if err != nil {
+		if !redis.IsNilErr(err) {
+			blog.Errorf("get host apply reconcile failure records failed, err: %v, rid: %s", err, rid)
+		}
+		return
+	}
+
+	for supplierAccount, value := range records {
+		failed := new(hostEvents)
+		if err := json.UnmarshalFromString(value, failed); err != nil {
+			blog.Errorf("unmarshal host apply reconcile failure record %s failed, err: %v, rid: %s", value, err, rid)
+			continue
+		}
+
+		if err := lgc.reconcileHostEventsWithRetry(supplierAccount, failed); err != nil {
+			blog.Errorf("reconcile failed hosts again failed, supplier account: %s, record: %s, err: %v, rid: %s",
+				supplierAccount, value, err, rid)
+			continue
+		}
+
+		if err := lgc.cache.HDel(ctx, hostApplyReconcileFailedKey, supplierAccount).Err(); err != nil {
+			blog.Errorf("delete host apply reconcile failure record failed, supplier account: %s, err: %v, rid: %s",
+				supplierAccount, err, rid)
+		}
+	}
+}
+
+// reconcileSupplierHosts reconcile the updated hosts of a supplier account, the drifts of the deleted hosts are
+// removed.
+func (lgc *Logics) reconcileSupplierHosts(kit *rest.Kit, hostIDs []int64, deletedHostIDs []int64) error {
+
+	hostApplyCli := lgc.CoreAPI.CoreService().HostApplyRule()
+	for _, hostIDs := range splitIDs(deletedHostIDs, common.BKMaxLimitSize) {
+		opt := &metadata.DeleteHostApplyDriftOption{HostIDs: hostIDs}
+		if err := hostApplyCli.DeleteHostApplyDrift(kit.Ctx, kit.Header, opt); err != nil {
+			blog.Errorf("delete host apply drift failed, host ids: %v, err: %v, rid: %s", hostIDs, err, kit.Rid)
+			return err
+		}
+	}
+
+	if len(hostIDs) == 0 {
+		return nil
+	}
+
+	bizHostIDs, err := lgc.getHostBizIDs(kit, hostIDs)
+	if err != nil {
+		return err
+	}
+
+	if len(bizHostIDs) == 0 {
+		return nil
+	}
+
+	bizIDs := make([]int64, 0, len(bizHostIDs))
+	for bizID := range bizHostIDs {
+		bizIDs = append(bizIDs, bizID)
+	}
+
+	configs := make([]metadata.HostApplyReconcileConfig, 0)
+	for _, ids := range splitIDs(bizIDs, common.BKMaxLimitSize) {
+		opt := &metadata.ListHostApplyReconcileConfigOption{BizIDs: ids}
+		result, err := hostApplyCli.ListHostApplyReconcileConfig(kit.Ctx, kit.Header, opt)
+		if err != nil {
+			blog.Errorf("list host apply reconcile config failed, biz ids: %v, err: %v, rid: %s", ids, err, kit.Rid)
+			return err
+		}
+		configs = append(configs, result...)
+	}
+
+	for _, config := range configs {
+		if config.Mode == metadata.HostApplyReconcileDisabled {
+			continue
+		}
+
+		for _, ids := range splitIDs(bizHostIDs[config.BizID], common.BKMaxLimitSize) {
+			opt := &metadata.ReconcileHostApplyOption{HostIDs: ids}
+			result, err := hostApplyCli.ReconcileHostApply(kit.Ctx, kit.Header, config.BizID, opt)
+			if err != nil {
+				blog.Errorf("reconcile host apply failed, biz: %d, option: %+v, err: %v, rid: %s", config.BizID, opt,
+					err, kit.Rid)
+				return err
+			}
+
+			if len(result.Drifts) == 0 {
+				continue
+			}
+
+			reappliedHostIDs := make([]int64, 0)
+			if config.Mode == metadata.HostApplyReconcileReapply {
+				reappliedHostIDs, err = lgc.reapplyHostApplyDrifts(kit, config.BizID, result.Drifts)
+				if err != nil {
+					return err
+				}
+			}
+
+			blog.Infof("reconcile host apply in biz %d, mode: %s, drift count: %d, reapplied hosts: %v, rid: %s",
+				config.BizID, config.Mode, len(result.Drifts), reappliedHostIDs, kit.Rid)
+		}
+	}
+
+	return nil
+}
+
+// reapplyHostApplyDrifts re-apply the expected values of the drifted fields to the hosts with host audit logs, the
+// hosts that are locked for update are skipped. the drifts of the re-applied hosts are removed, the other drifts are
+// kept in the drift report. returns the ids of the re-applied hosts.
+func (lgc *Logics) reapplyHostApplyDrifts(kit *rest.Kit, bizID int64, drifts []metadata.HostApplyDrift) ([]int64,
+	errors.CCErrorCoder) {
+
+	hostIDs, hostData := getDriftHostData(drifts)
+
+	hostIDs, ccErr := lgc.FilterLockedHosts(kit, hostIDs, metadata.HostLockScopeUpdate)
+	if ccErr != nil {
+		return nil, ccErr
+	}
+
+	updateHostIDs, updateData, err := groupHostsByData(hostIDs, hostData)
+	if err != nil {
+		blog.Errorf("group hosts by update data failed, data: %+v, err: %v, rid: %s", hostData, err, kit.Rid)
+		return nil, kit.CCError.CCError(common.CCErrCommJSONMarshalFailed)
+	}
+
+	reappliedHostIDs := make([]int64, 0)
+	for dataStr, ids := range updateHostIDs {
+		data := updateData[dataStr]
+		if err := lgc.updateHostsWithAudit(kit, bizID, ids, data); err != nil {
+			// the hosts that failed to be re-applied are kept in the drift report, the other hosts are still re-applied
+			blog.Errorf("re-apply host apply drifts failed, hosts: %v, data: %+v, err: %v, rid: %s", ids, data, err,
+				kit.Rid)
+			continue
+		}
+		reappliedHostIDs = append(reappliedHostIDs, ids...)
+	}
+
+	if len(reappliedHostIDs) == 0 {
+		return reappliedHostIDs, nil
+	}
+
+	opt := &metadata.DeleteHostApplyDriftOption{HostIDs: reappliedHostIDs}
+	if err := lgc.CoreAPI.CoreService().HostApplyRule().DeleteHostApplyDrift(kit.Ctx, kit.Header, opt); err != nil {
+		blog.Errorf("delete re-applied host apply drifts failed, host ids: %v, err: %v, rid: %s", reappliedHostIDs,
+			err, kit.Rid)
+		return nil, err
+	}
+
+	return reappliedHostIDs, nil
+}
+
+// getDriftHostData get the expected values of the drifted fields of each host, returns the drifted host ids in the
+// order of the drifts and the map of host id to its update data
+func getDriftHostData(drifts []metadata.HostApplyDrift) ([]int64, map[int64]map[string]interface{}) {
+	hostData := make(map[int64]map[string]interface{})
+	hostIDs := make([]int64, 0)
+	for _, drift := range drifts {
+		if _, exists := hostData[drift.HostID]; !exists {
+			hostData[drift.HostID] = make(map[string]interface{})
+			hostIDs = append(hostIDs, drift.HostID)
+		}
+		hostData[drift.HostID][drift.PropertyID] = drift.ExpectValue
+	}
+	return hostIDs, hostData
+}
+
+// groupHostsByData group the hosts with the same update data together to update them in batch, returns the maps of
+// the marshaled data to the host ids and to the update data
+func groupHostsByData(hostIDs []int64, hostData map[int64]map[string]interface{}) (map[string][]int64,
+	map[string]map[string]interface{}, error) {
+
+	updateHostIDs := make(map[string][]int64)
+	updateData := make(map[string]map[string]interface{})
+	for _, hostID := range hostIDs {
+		data, exists := hostData[hostID]
+		if !exists {
+			continue
+		}
+
+		dataStr, err := json.MarshalToString(data)
+		if err != nil {
+			return nil, nil, err
+		}
+		updateHostIDs[dataStr] = append(updateHostIDs[dataStr], hostID)
+		updateData[dataStr] = data
+	}
+	return updateHostIDs, updateData, nil
+}
+
+// updateHostsWithAudit update the hosts with the data and save the host update audit logs
+func (lgc *Logics) updateHostsWithAudit(kit *rest.Kit, bizID int64, hostIDs []int64,
+	data map[string]interface{}) error {
+
+	audit := auditlog.NewHostAudit(lgc.CoreAPI.CoreService())
+	auditParam := auditlog.NewGenerateAuditCommonParameter(kit, metadata.AuditUpdate).WithUpdateFields(data).
+		WithOperateFrom(metadata.FromCCSystem)
+	auditCond := map[string]interface{}{common.BKHostIDField: map[string]interface{}{common.BKDBIN: hostIDs}}
+	auditLogs, err := audit.GenerateAuditLogByCond(auditParam, bizID, auditCond)
+	if err != nil {
+		blog.Errorf("generate host audit log failed, host ids: %v, err: %v, rid: %s", hostIDs, err, kit.Rid)
+		return err
+	}
+
+	opt := &metadata.UpdateOption{
+		Condition: map[string]interface{}{common.BKHostIDField: map[string]interface{}{common.BKDBIN: hostIDs}},
+		Data:      data,
+	}
+	if _, err := lgc.CoreAPI.CoreService().Instance().UpdateInstance(kit.Ctx, kit.Header, common.BKInnerObjIDHost,
+		opt); err != nil {
+		blog.Errorf("update hosts failed, option: %+v, err: %v, rid: %s", opt, err, kit.Rid)
+		return err
+	}
+
+	if err := audit.SaveAuditLog(kit, auditLogs...); err != nil {
+		blog.Errorf("save host audit log failed, host ids: %v, err: %v, rid: %s", hostIDs, err, kit.Rid)
+		return err
+	}
+	return nil
+}
+
+// getHostBizIDs get the hosts' business ids, returns the map of business id to its host ids
+func (lgc *Logics) getHostBizIDs(kit *rest.Kit, hostIDs []int64) (map[int64][]int64, error) {
+	bizHostIDs := make(map[int64][]int64)
+	hostExists := make(map[int64]struct{})
+
+	for _, ids := range splitIDs(hostIDs, common.BKMaxLimitSize) {
+		opt := &metadata.HostModuleRelationRequest{
+			HostIDArr: ids,
+			Page:      metadata.BasePage{Limit: common.BKNoLimit},
+			Fields:    []string{common.BKAppIDField, common.BKHostIDField},
+		}
+		relations, err := lgc.CoreAPI.CoreService().Host().GetHostModuleRelation(kit.Ctx, kit.Header, opt)
+		if err != nil {
+			blog.Errorf("get host module relations failed, host ids: %v, err: %v, rid: %s", ids, err, kit.Rid)
+			return nil, err
+		}
+
+		for _, relation := range relations.Info {
+			if _, exists := hostExists[relation.HostID]; exists {
+				continue
+			}
+			hostExists[relation.HostID] = struct{}{}
+			bizHostIDs[relation.AppID] = append(bizHostIDs[relation.AppID], relation.HostID)
+		}
+	}
+
+	return bizHostIDs, nil
+}
+
+func (lgc *Logics) getHostApplyReconcileCursor(ctx context.Context, rid string) string {
+	cursor, err := lgc.cache.Get(ctx, hostApplyReconcileCursorKey).Result()
+	if err != nil {
+		if !redis.IsNilErr(err) {
+			blog.Errorf("get host apply reconcile cursor failed, watch from now, err: %v, rid: %s", err, rid)
+		}
+		return ""
+	}
+	return cursor
+}
+
+func (lgc *Logics) saveHostApplyReconcileCursor(ctx context.Context, cursor string, rid string) {
+	if err := lgc.cache.Set(ctx, hostApplyReconcileCursorKey, cursor, 0).Err(); err != nil {
+		blog.Errorf("save host apply reconcile cursor %s failed, err: %v, rid: %s", cursor, err, rid)
+	}
+}
+
+// splitIDs split the ids into batches whose length is no more than the limit
+func splitIDs(ids []int64, limit int) [][]int64 {
+	batches := make([][]int64, 0)
+	for start := 0; start < len(ids); start += limit {
+		end := start + limit
+		if end > len(ids) {
+			end = len(ids)
+		}
+		batches = append(batches, ids[start:end])
+	}
+	return batches
+}
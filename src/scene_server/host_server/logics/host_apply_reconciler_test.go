@@ -0,0 +1,167 @@
+/*
+ * Tencent is pleased to support the open source community by making 蓝鲸 available.
+ * Copyright (C) 2017-2018 THL A29 Limited, a Tencent company. All rights reserved.
+ * Licensed under the MIT License (the "License"); you may not use this file except
+ * in compliance with the License. You may obtain a copy of the License at
+ * http://opensource.org/licenses/MIT
+ * Unless required by applicable law or agreed to in writing, software distributed under
+ * the License is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND,
+ * either express or implied. See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package logics
+
+import (
+	"reflect"
+	"sort"
+	"testing"
+
+	"configcenter/src/common"
+	"configcenter/src/common/metadata"
+	"configcenter/src/common/watch"
+)
+
+func TestSplitIDs(t *testing.T) {
+	testCases := []struct {
+		name   string
+		ids    []int64
+		limit  int
+		expect [][]int64
+	}{
+		{name: "empty", ids: nil, limit: 2, expect: [][]int64{}},
+		{name: "less than limit", ids: []int64{1}, limit: 2, expect: [][]int64{{1}}},
+		{name: "equal to limit", ids: []int64{1, 2}, limit: 2, expect: [][]int64{{1, 2}}},
+		{name: "more than limit", ids: []int64{1, 2, 3, 4, 5}, limit: 2, expect: [][]int64{{1, 2}, {3, 4}, {5}}},
+	}
+
+	for _, tc := range testCases {
+		if batches := splitIDs(tc.ids, tc.limit); !reflect.DeepEqual(batches, tc.expect) {
+			t.Errorf("%s: split ids %v by %d, expect %v, got %v", tc.name, tc.ids, tc.limit, tc.expect, batches)
+		}
+	}
+}
+
+func newHostEvent(eventType watch.EventType, detail string) *watch.WatchEventDetail {
+	return &watch.WatchEventDetail{EventType: eventType, Detail: watch.JsonString(detail)}
+}
+
+func TestGroupHostEvents(t *testing.T) {
+	events := []*watch.WatchEventDetail{
+		newHostEvent(watch.Update, `{"bk_host_id":1,"bk_supplier_account":"0"}`),
+		newHostEvent(watch.Update, `{"bk_host_id":2,"bk_supplier_account":"1"}`),
+		// host without supplier account belongs to the default supplier account
+		newHostEvent(watch.Update, `{"bk_host_id":3}`),
+		// duplicate update event
+		newHostEvent(watch.Update, `{"bk_host_id":1,"bk_supplier_account":"0"}`),
+		// updated then deleted host is only deleted
+		newHostEvent(watch.Update, `{"bk_host_id":4,"bk_supplier_account":"1"}`),
+		newHostEvent(watch.Delete, `{"bk_host_id":4,"bk_supplier_account":"1"}`),
+		// invalid events are ignored
+		newHostEvent(watch.Update, `{"bk_supplier_account":"1"}`),
+	}
+
+	expect := map[string]*hostEvents{
+		common.BKDefaultOwnerID: {UpdatedHostIDs: []int64{1, 3}, DeletedHostIDs: []int64{}},
+		"1":                     {UpdatedHostIDs: []int64{2}, DeletedHostIDs: []int64{4}},
+	}
+
+	result := groupHostEvents(events)
+	if len(result) != len(expect) {
+		t.Fatalf("group host events, expect %d supplier accounts, got %d", len(expect), len(result))
+	}
+
+	for supplierAccount, expectEvent := range expect {
+		hostEvent, exists := result[supplierAccount]
+		if !exists {
+			t.Errorf("group host events, supplier account %s not exists", supplierAccount)
+			continue
+		}
+
+		if !reflect.DeepEqual(hostEvent.UpdatedHostIDs, expectEvent.UpdatedHostIDs) {
+			t.Errorf("supplier account %s, expect updated hosts %v, got %v", supplierAccount,
+				expectEvent.UpdatedHostIDs, hostEvent.UpdatedHostIDs)
+		}
+
+		if len(hostEvent.DeletedHostIDs) != len(expectEvent.DeletedHostIDs) ||
+			(len(expectEvent.DeletedHostIDs) > 0 &&
+				!reflect.DeepEqual(hostEvent.DeletedHostIDs, expectEvent.DeletedHostIDs)) {
+			t.Errorf("supplier account %s, expect deleted hosts %v, got %v", supplierAccount,
+				expectEvent.DeletedHostIDs, hostEvent.DeletedHostIDs)
+		}
+	}
+}
+
+func TestHostEventsMerge(t *testing.T) {
+	failed := &hostEvents{UpdatedHostIDs: []int64{1, 2}, DeletedHostIDs: []int64{3}}
+	failed.merge(&hostEvents{UpdatedHostIDs: []int64{2, 4}, DeletedHostIDs: []int64{1, 3}})
+
+	if expect := []int64{2, 4}; !reflect.DeepEqual(failed.UpdatedHostIDs, expect) {
+		t.Errorf("merge host events, expect updated hosts %v, got %v", expect, failed.UpdatedHostIDs)
+	}
+
+	if expect := []int64{3, 1}; !reflect.DeepEqual(failed.DeletedHostIDs, expect) {
+		t.Errorf("merge host events, expect deleted hosts %v, got %v", expect, failed.DeletedHostIDs)
+	}
+}
+
+func TestReapplyHostSelection(t *testing.T) {
+	drifts := []metadata.HostApplyDrift{
+		{HostID: 1, PropertyID: "bk_os_type", ExpectValue: "1"},
+		{HostID: 2, PropertyID: "bk_os_type", ExpectValue: "1"},
+		{HostID: 1, PropertyID: "operator", ExpectValue: "admin"},
+		{HostID: 2, PropertyID: "operator", ExpectValue: "admin"},
+		{HostID: 3, PropertyID: "bk_os_type", ExpectValue: "1"},
+		{HostID: 4, PropertyID: "bk_os_type", ExpectValue: "2"},
+	}
+
+	hostIDs, hostData := getDriftHostData(drifts)
+	if expect := []int64{1, 2, 3, 4}; !reflect.DeepEqual(hostIDs, expect) {
+		t.Fatalf("get drift host data, expect hosts %v, got %v", expect, hostIDs)
+	}
+
+	expectData := map[string]interface{}{"bk_os_type": "1", "operator": "admin"}
+	if !reflect.DeepEqual(hostData[1], expectData) {
+		t.Errorf("get drift host data, expect host 1 data %v, got %v", expectData, hostData[1])
+	}
+
+	// host 2 is locked and filtered, only the unlocked hosts are selected to be re-applied
+	updateHostIDs, updateData, err := groupHostsByData([]int64{1, 3, 4}, hostData)
+	if err != nil {
+		t.Fatalf("group hosts by data failed, err: %v", err)
+	}
+
+	if len(updateHostIDs) != 3 || len(updateData) != 3 {
+		t.Fatalf("group hosts by data, expect 3 groups, got %d hosts groups and %d data groups",
+			len(updateHostIDs), len(updateData))
+	}
+
+	groups := make([][]int64, 0)
+	for dataStr, ids := range updateHostIDs {
+		if !reflect.DeepEqual(updateData[dataStr], hostData[ids[0]]) {
+			t.Errorf("group hosts by data, data %s not matches host %d data %v", dataStr, ids[0], hostData[ids[0]])
+		}
+		groups = append(groups, ids)
+	}
+
+	sort.Slice(groups, func(i, j int) bool { return groups[i][0] < groups[j][0] })
+	if expect := [][]int64{{1}, {3}, {4}}; !reflect.DeepEqual(groups, expect) {
+		t.Errorf("group hosts by data, expect groups %v, got %v", expect, groups)
+	}
+
+	// the hosts with the same data are grouped together
+	updateHostIDs, _, err = groupHostsByData([]int64{1, 2, 3}, hostData)
+	if err != nil {
+		t.Fatalf("group hosts by data failed, err: %v", err)
+	}
+
+	if len(updateHostIDs) != 2 {
+		t.Fatalf("group hosts by data, expect 2 groups, got %v", updateHostIDs)
+	}
+
+	for _, ids := range updateHostIDs {
+		if len(ids) == 2 && !reflect.DeepEqual(ids, []int64{1, 2}) {
+			t.Errorf("group hosts by data, expect hosts 1 and 2 grouped together, got %v", ids)
+		}
+	}
+}
@@ -14,10 +14,12 @@ package logics
 
 import (
 	"configcenter/src/common"
+	"configcenter/src/common/auditlog"
 	"configcenter/src/common/blog"
 	"configcenter/src/common/errors"
 	"configcenter/src/common/http/rest"
 	"configcenter/src/common/metadata"
+	"configcenter/src/common/util"
 )
 
 // LockHost TODO
@@ -32,12 +34,26 @@ func (lgc *Logics) LockHost(kit *rest.Kit, input *metadata.HostLockRequest) erro
 		blog.Errorf("lock host, add host lock  error, error code:%d error message:%s,input:%+v,logID:%s", hostLockResult.Code, hostLockResult.ErrMsg, input, kit.Rid)
 		return kit.CCError.New(hostLockResult.Code, hostLockResult.ErrMsg)
 	}
+
+	locks, err := lgc.ListHostLock(kit, input.IDS)
+	if err != nil {
+		return err
+	}
+
+	if err := lgc.saveHostLockAuditLog(kit, metadata.AuditLock, locks); err != nil {
+		return err
+	}
 	return nil
 }
 
 // UnlockHost TODO
 func (lgc *Logics) UnlockHost(kit *rest.Kit, input *metadata.HostLockRequest) errors.CCError {
 
+	locks, lockErr := lgc.ListHostLock(kit, input.IDS)
+	if lockErr != nil {
+		return lockErr
+	}
+
 	hostUnlockResult, err := lgc.CoreAPI.CoreService().Host().UnlockHost(kit.Ctx, kit.Header, input)
 	if nil != err {
 		blog.Errorf("unlock host, http request error, error:%s,input:%+v,logID:%s", err.Error(), input, kit.Rid)
@@ -47,6 +63,15 @@ func (lgc *Logics) UnlockHost(kit *rest.Kit, input *metadata.HostLockRequest) er
 		blog.Errorf("unlock host, release host lock  error, error code:%d error message:%s,input:%+v,logID:%s", hostUnlockResult.Code, hostUnlockResult.ErrMsg, input, kit.Rid)
 		return kit.CCError.New(hostUnlockResult.Code, hostUnlockResult.ErrMsg)
 	}
+
+	action := metadata.AuditUnlock
+	if input.Force {
+		action = metadata.AuditForceUnlock
+	}
+
+	if err := lgc.saveHostLockAuditLog(kit, action, locks); err != nil {
+		return err
+	}
 	return nil
 }
 
@@ -72,3 +97,108 @@ func (lgc *Logics) QueryHostLock(kit *rest.Kit, input *metadata.QueryHostLockReq
 
 	return hostLockMap, nil
 }
+
+// ListHostLock list the unexpired locks of the hosts
+func (lgc *Logics) ListHostLock(kit *rest.Kit, hostIDs []int64) ([]metadata.HostLockData, errors.CCErrorCoder) {
+	input := &metadata.QueryHostLockRequest{IDS: hostIDs}
+	hostLockResult, err := lgc.CoreAPI.CoreService().Host().QueryHostLock(kit.Ctx, kit.Header, input)
+	if err != nil {
+		blog.Errorf("list host lock, http request error, err: %v, input: %+v, rid: %s", err, input, kit.Rid)
+		return nil, kit.CCError.CCError(common.CCErrCommHTTPDoRequestFailed)
+	}
+	if err := hostLockResult.CCError(); err != nil {
+		blog.Errorf("list host lock failed, err: %v, input: %+v, rid: %s", err, input, kit.Rid)
+		return nil, err
+	}
+	return hostLockResult.Data.Info, nil
+}
+
+// ValidateHostLock check if the hosts are locked in the scope, all the host transfer, update and delete operations
+// must call it before operating the hosts, returns error if any of the hosts is locked.
+func (lgc *Logics) ValidateHostLock(kit *rest.Kit, hostIDs []int64,
+	scope metadata.HostLockScope) errors.CCErrorCoder {
+
+	if len(hostIDs) == 0 {
+		return nil
+	}
+
+	locks, err := lgc.ListHostLock(kit, util.IntArrayUnique(hostIDs))
+	if err != nil {
+		return err
+	}
+
+	lockedHostIDs := make([]int64, 0)
+	for _, lock := range locks {
+		if lock.InScope(scope) {
+			lockedHostIDs = append(lockedHostIDs, lock.ID)
+		}
+	}
+
+	if len(lockedHostIDs) > 0 {
+		blog.Errorf("hosts %v are locked, %s operation is not allowed, rid: %s", lockedHostIDs, scope, kit.Rid)
+		return kit.CCError.CCErrorf(common.CCErrHostLocked, lockedHostIDs, scope)
+	}
+	return nil
+}
+
+// FilterLockedHosts filter out the hosts that are locked in the scope, returns the unlocked host ids. it is used by
+// the operations that allow partial success like host apply, so that the locked hosts are skipped instead of failing
+// the whole operation.
+func (lgc *Logics) FilterLockedHosts(kit *rest.Kit, hostIDs []int64, scope metadata.HostLockScope) ([]int64,
+	errors.CCErrorCoder) {
+
+	if len(hostIDs) == 0 {
+		return hostIDs, nil
+	}
+
+	locks, err := lgc.ListHostLock(kit, util.IntArrayUnique(hostIDs))
+	if err != nil {
+		return nil, err
+	}
+
+	lockedHosts := make(map[int64]struct{})
+	for _, lock := range locks {
+		if lock.InScope(scope) {
+			lockedHosts[lock.ID] = struct{}{}
+		}
+	}
+
+	if len(lockedHosts) == 0 {
+		return hostIDs, nil
+	}
+
+	unlockedHostIDs := make([]int64, 0)
+	lockedHostIDs := make([]int64, 0)
+	for _, hostID := range hostIDs {
+		if _, exists := lockedHosts[hostID]; exists {
+			lockedHostIDs = append(lockedHostIDs, hostID)
+			continue
+		}
+		unlockedHostIDs = append(unlockedHostIDs, hostID)
+	}
+
+	blog.Warnf("hosts %v are locked, skip the %s operation on them, rid: %s", lockedHostIDs, scope, kit.Rid)
+	return unlockedHostIDs, nil
+}
+
+func (lgc *Logics) saveHostLockAuditLog(kit *rest.Kit, action metadata.ActionType,
+	locks []metadata.HostLockData) errors.CCError {
+
+	if len(locks) == 0 {
+		return nil
+	}
+
+	audit := auditlog.NewHostAudit(lgc.CoreAPI.CoreService())
+	generateAuditParameter := auditlog.NewGenerateAuditCommonParameter(kit, action)
+	auditLogs, err := audit.GenerateLockAuditLog(generateAuditParameter, locks)
+	if err != nil {
+		blog.Errorf("generate host lock audit log failed, locks: %+v, err: %v, rid: %s", locks, err, kit.Rid)
+		return kit.CCError.Error(common.CCErrAuditSaveLogFailed)
+	}
+
+	if err := audit.SaveAuditLog(kit, auditLogs...); err != nil {
+		blog.Errorf("save host lock audit log failed, locks: %+v, err: %v, rid: %s", locks, err, kit.Rid)
+		return kit.CCError.Error(common.CCErrAuditSaveLogFailed)
+	}
+	return nil
+}
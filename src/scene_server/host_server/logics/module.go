@@ -192,6 +192,10 @@ func (lgc *Logics) GetModuleIDAndIsInternal(kit *rest.Kit, bizID, moduleID int64
 // MoveHostToResourcePool transfer hosts to a resource pool
 func (lgc *Logics) MoveHostToResourcePool(kit *rest.Kit, conf *metadata.DefaultModuleHostConfigParams) ([]metadata.ExceptionResult, error) {
 
+	if err := lgc.ValidateHostLock(kit, conf.HostIDs, metadata.HostLockScopeTransfer); err != nil {
+		return nil, err
+	}
+
 	ownerAppID, err := lgc.GetDefaultAppID(kit)
 	if err != nil {
 		blog.Errorf("move host to resource pool, but get default appid failed, err: %v, input:%+v,rid:%s", err, conf, kit.Rid)
@@ -315,6 +319,10 @@ func (lgc *Logics) notExistAppModuleHost(kit *rest.Kit, appIDs []int64, moduleID
 // AssignHostToApp transfer resource host to  idle module
 func (lgc *Logics) AssignHostToApp(kit *rest.Kit, conf *metadata.DefaultModuleHostConfigParams) ([]metadata.ExceptionResult, error) {
 
+	if err := lgc.ValidateHostLock(kit, conf.HostIDs, metadata.HostLockScopeTransfer); err != nil {
+		return nil, err
+	}
+
 	cond := hutil.NewOperation().WithAppID(conf.ApplicationID).Data()
 	fields := fmt.Sprintf("%s,%s", common.BKOwnerIDField, common.BKAppNameField)
 	appInfo, err := lgc.GetAppDetails(kit, fields, cond)
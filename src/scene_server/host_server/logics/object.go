@@ -323,3 +323,20 @@ func (lgc *Logics) GetHostIDByInstID(kit *rest.Kit, asstObjId string, instIDArr
 
 	return hostIDs, nil
 }
+
+// GetCustomMainlineObjects returns the custom mainline object ids between business and set.
+func (lgc *Logics) GetCustomMainlineObjects(kit *rest.Kit) ([]string, error) {
+	objChildMap, err := lgc.searchMainlineRelationMap(kit)
+	if err != nil {
+		return nil, err
+	}
+
+	// traverse down topo from business till set
+	mainlineObjs := make([]string, 0)
+	for childObj := objChildMap[common.BKInnerObjIDApp]; childObj != "" && childObj != common.BKInnerObjIDSet; {
+		mainlineObjs = append(mainlineObjs, childObj)
+		childObj = objChildMap[childObj]
+	}
+
+	return mainlineObjs, nil
+}
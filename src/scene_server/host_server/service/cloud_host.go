@@ -502,6 +502,11 @@ func (s *Service) DeleteCloudHostFromBiz(ctx *rest.Contexts) {
 		return
 	}
 
+	if err := s.Logic.ValidateHostLock(ctx.Kit, input.HostIDs, metadata.HostLockScopeDelete); err != nil {
+		ctx.RespAutoError(err)
+		return
+	}
+
 	txnErr := s.CoreAPI.CoreService().Txn().AutoRunTxn(ctx.Kit.Ctx, ctx.Kit.Header, func() error {
 		// delete all instance associations of the hosts
 		asstCond := &metadata.InstAsstDeleteOption{
@@ -479,6 +479,11 @@ func (s *Service) UpdateHostCloudAreaField(ctx *rest.Contexts) {
 		return
 	}
 
+	if err := s.Logic.ValidateHostLock(ctx.Kit, input.HostIDs, metadata.HostLockScopeUpdate); err != nil {
+		ctx.RespAutoError(err)
+		return
+	}
+
 	txnErr := s.Engine.CoreAPI.CoreService().Txn().AutoRunTxn(ctx.Kit.Ctx, ctx.Kit.Header, func() error {
 		ccErr := s.CoreAPI.CoreService().Host().UpdateHostCloudAreaField(ctx.Kit.Ctx, ctx.Kit.Header, input)
 		if ccErr != nil {
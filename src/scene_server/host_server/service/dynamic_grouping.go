@@ -122,13 +122,19 @@ func (s *Service) registerActionToIAM(kit *rest.Kit, dynamicGroup meta.DynamicGr
 
 // createGroupParamCheck 新建动态分组接口请求参数检查
 func (s *Service) createGroupParamCheck(kit *rest.Kit, dynamicGroup meta.DynamicGroup) error {
+	lgc := logics.NewLogics(s.Engine, s.CacheDB, s.AuthManager)
+
 	//  validate dynamic group func.
 	validateFunc := func(objectID string) ([]meta.Attribute, error) {
-		return logics.NewLogics(s.Engine, s.CacheDB, s.AuthManager).
-			SearchObjectAttributes(kit, dynamicGroup.AppID, objectID)
+		return lgc.SearchObjectAttributes(kit, dynamicGroup.AppID, objectID)
 	}
 
-	if err := dynamicGroup.Validate(validateFunc); err != nil {
+	mainlineObjs, err := s.getDynamicGroupMainlineObjs(kit, lgc, &dynamicGroup.Info)
+	if err != nil {
+		return err
+	}
+
+	if err := dynamicGroup.Validate(validateFunc, mainlineObjs); err != nil {
 		blog.Errorf("create dynamic group failed, invalid param, err: %v, input: %+v, rid: %s",
 			err, dynamicGroup, kit.Rid)
 		return err
@@ -137,6 +143,22 @@ func (s *Service) createGroupParamCheck(kit *rest.Kit, dynamicGroup meta.Dynamic
 	return nil
 }
 
+// getDynamicGroupMainlineObjs returns the custom mainline objects for validating dynamic group filter conditions.
+func (s *Service) getDynamicGroupMainlineObjs(kit *rest.Kit, lgc *logics.Logics, info *meta.DynamicGroupInfo) (
+	[]string, error) {
+
+	if len(info.FilterCondition) == 0 {
+		return make([]string, 0), nil
+	}
+
+	mainlineObjs, err := lgc.GetCustomMainlineObjects(kit)
+	if err != nil {
+		blog.Errorf("get custom mainline objects failed, err: %v, rid: %s", err, kit.Rid)
+		return nil, err
+	}
+	return mainlineObjs, nil
+}
+
 // UpdateDynamicGroup updates target dynamic group.
 func (s *Service) UpdateDynamicGroup(ctx *rest.Contexts) {
 	req := ctx.Request
@@ -164,7 +186,7 @@ func (s *Service) UpdateDynamicGroup(ctx *rest.Contexts) {
 	}
 	// final updates.
 	updates := make(map[string]interface{})
-	if err := s.updateGroupParamCheck(ctx.Kit, bizIDInt64, params, updates); err != nil {
+	if err := s.updateGroupParamCheck(ctx.Kit, bizIDInt64, targetID, params, updates); err != nil {
 		blog.Errorf("update request param check failed, err: %v, rid: %s", err, ctx.Kit.Rid)
 		ctx.RespAutoError(err)
 		return
@@ -211,7 +233,8 @@ func (s *Service) UpdateDynamicGroup(ctx *rest.Contexts) {
 }
 
 // updateGroupParamCheck 更新动态分组接口请求参数检查
-func (s *Service) updateGroupParamCheck(kit *rest.Kit, bizID int64, params, updates map[string]interface{}) error {
+func (s *Service) updateGroupParamCheck(kit *rest.Kit, bizID int64, targetID string,
+	params, updates map[string]interface{}) error {
 
 	if info, isExist := params["info"]; isExist {
 		// update dynamic group info.
@@ -240,12 +263,19 @@ func (s *Service) updateGroupParamCheck(kit *rest.Kit, bizID int64, params, upda
 			return err
 		}
 
+		lgc := logics.NewLogics(s.Engine, s.CacheDB, s.AuthManager)
+
 		//  validate dynamic group func.
 		validatefunc := func(objectID string) ([]meta.Attribute, error) {
-			return logics.NewLogics(s.Engine, s.CacheDB, s.AuthManager).
-				SearchObjectAttributes(kit, bizID, objectID)
+			return lgc.SearchObjectAttributes(kit, bizID, objectID)
 		}
-		if err := dynamicGroupInfo.Validate(objectID, validatefunc); err != nil {
+
+		mainlineObjs, err := s.getDynamicGroupMainlineObjs(kit, lgc, dynamicGroupInfo)
+		if err != nil {
+			return err
+		}
+
+		if err := dynamicGroupInfo.Validate(objectID, validatefunc, mainlineObjs); err != nil {
 			blog.Errorf("update dynamic group failed, err: %v, rid: %s", err, kit.Rid)
 			return err
 		}
@@ -261,10 +291,11 @@ func (s *Service) updateGroupParamCheck(kit *rest.Kit, bizID int64, params, upda
 			return kit.CCError.Errorf(common.CCErrCommParamsIsInvalid)
 		}
 
-		_, isExist = params[common.BKFieldName]
-		if !isExist {
-			blog.Errorf("update dynamic group failed, err: empty update content, bk_biz_id/info/name, input: %+v, "+
-				"rid: %s", params, kit.Rid)
+		_, isNameExist := params[common.BKFieldName]
+		_, isTrackMemberExist := params["track_member"]
+		if !isNameExist && !isTrackMemberExist {
+			blog.Errorf("update dynamic group failed, err: empty update content, bk_biz_id/info/name/track_member, "+
+				"input: %+v, rid: %s", params, kit.Rid)
 			return kit.CCError.Errorf(common.CCErrCommParamsIsInvalid)
 		}
 	}
@@ -272,6 +303,62 @@ func (s *Service) updateGroupParamCheck(kit *rest.Kit, bizID int64, params, upda
 	if name, isExist := params[common.BKFieldName]; isExist {
 		updates[common.BKFieldName] = name
 	}
+	return s.trackMemberParamCheck(kit, bizID, targetID, params, updates)
+}
+
+// trackMemberParamCheck checks if the updated dynamic group's members can be tracked, the origin dynamic group is used
+// to check when its conditions or track member flag is not updated.
+func (s *Service) trackMemberParamCheck(kit *rest.Kit, bizID int64, targetID string,
+	params, updates map[string]interface{}) error {
+
+	value, isTrackMemberExist := params["track_member"]
+	info, isInfoUpdated := updates["info"].(*meta.DynamicGroupInfo)
+	if !isTrackMemberExist && !isInfoUpdated {
+		return nil
+	}
+
+	if isTrackMemberExist {
+		trackMember, ok := value.(bool)
+		if !ok {
+			blog.Errorf("update dynamic group failed, invalid track_member type, value: %+v, rid: %s", value, kit.Rid)
+			return kit.CCError.CCErrorf(common.CCErrCommParamsInvalid, "track_member")
+		}
+		updates["track_member"] = trackMember
+
+		if !trackMember {
+			return nil
+		}
+	}
+
+	objectID, _ := updates[common.BKObjIDField].(string)
+	if !isTrackMemberExist || !isInfoUpdated {
+		result, err := s.CoreAPI.CoreService().Host().GetDynamicGroup(kit.Ctx, strconv.FormatInt(bizID, 10),
+			targetID, kit.Header)
+		if err != nil {
+			blog.Errorf("get dynamic group failed, err: %v, biz: %d, id: %s, rid: %s", err, bizID, targetID, kit.Rid)
+			return kit.CCError.CCError(common.CCErrCommHTTPDoRequestFailed)
+		}
+		if !result.Result {
+			blog.Errorf("get dynamic group failed, errcode: %d, errmsg: %s, biz: %d, id: %s, rid: %s", result.Code,
+				result.ErrMsg, bizID, targetID, kit.Rid)
+			return result.CCError()
+		}
+
+		// the members of the dynamic group is not tracked, no need to check the updated conditions
+		if !isTrackMemberExist && !result.Data.TrackMember {
+			return nil
+		}
+
+		if !isInfoUpdated {
+			objectID = result.Data.ObjID
+			info = &result.Data.Info
+		}
+	}
+
+	if err := meta.ValidateDynamicGroupTrackMember(objectID, info); err != nil {
+		blog.Errorf("update dynamic group failed, err: %v, rid: %s", err, kit.Rid)
+		return kit.CCError.CCErrorf(common.CCErrCommParamsInvalid, "track_member")
+	}
 	return nil
 }
 
@@ -470,6 +557,22 @@ func (s *Service) ExecuteDynamicGroup(ctx *rest.Contexts) {
 
 	// target dynamic group.
 	targetDynamicGroup := result.Data
+
+	// execute dynamic group with filter conditions, it supports all the target object types.
+	if len(targetDynamicGroup.Info.FilterCondition) != 0 {
+		data, err := logics.NewLogics(s.Engine, s.CacheDB, s.AuthManager).
+			ExecuteFilterDynamicGroup(ctx.Kit, &targetDynamicGroup, input)
+		if err != nil {
+			blog.Errorf("execute dynamic group failed, search %s, err: %v, bizID: %s, ID: %s, rid: %s",
+				targetDynamicGroup.ObjID, err, bizID, targetID, ctx.Kit.Rid)
+			ctx.RespAutoError(ctx.Kit.CCError.Errorf(common.CCErrGetUserCustomQueryDetailFailed, err.Error()))
+			return
+		}
+
+		ctx.RespEntity(data)
+		return
+	}
+
 	// execute dynamic group with target object type.
 	switch targetDynamicGroup.ObjID {
 	case common.BKInnerObjIDHost:
@@ -92,6 +92,11 @@ func (s *Service) DeleteHostBatchFromResourcePool(ctx *rest.Contexts) {
 		return
 	}
 
+	if err := s.Logic.ValidateHostLock(ctx.Kit, iHostIDArr, meta.HostLockScopeDelete); err != nil {
+		ctx.RespAutoError(err)
+		return
+	}
+
 	for _, iHostID := range iHostIDArr {
 		asstCond := map[string]interface{}{
 			common.BKDBOR: []map[string]interface{}{
@@ -620,6 +625,11 @@ func (s *Service) UpdateHostBatch(ctx *rest.Contexts) {
 		return
 	}
 
+	if err := s.Logic.ValidateHostLock(ctx.Kit, hostIDArr, meta.HostLockScopeUpdate); err != nil {
+		ctx.RespAutoError(err)
+		return
+	}
+
 	// for audit log.
 	audit := auditlog.NewHostAudit(s.CoreAPI.CoreService())
 
@@ -841,6 +851,11 @@ func (s *Service) UpdateHostPropertyBatch(ctx *rest.Contexts) {
 		return
 	}
 
+	if err := s.Logic.ValidateHostLock(ctx.Kit, hostIDs, meta.HostLockScopeUpdate); err != nil {
+		ctx.RespAutoError(err)
+		return
+	}
+
 	if err := s.updateHostPropertyBatch(ctx.Kit, hostIDs, parameter); err != nil {
 		ctx.RespAutoError(err)
 		return
@@ -1008,6 +1023,12 @@ func (s *Service) MoveSetHost2IdleModule(ctx *rest.Contexts) {
 		ctx.RespEntity(nil)
 		return
 	}
+
+	if err := s.Logic.ValidateHostLock(ctx.Kit, hostIDArr, meta.HostLockScopeTransfer); err != nil {
+		ctx.RespAutoError(err)
+		return
+	}
+
 	moduleCond := []meta.ConditionItem{
 		{
 			Field:    common.BKAppIDField,
@@ -1397,6 +1418,11 @@ func (s *Service) UpdateImportHosts(ctx *rest.Contexts) {
 		return
 	}
 
+	if err := s.Logic.ValidateHostLock(ctx.Kit, hostIDArr, meta.HostLockScopeUpdate); err != nil {
+		ctx.RespAutoError(err)
+		return
+	}
+
 	successData, errData, err := s.Logic.UpdateHostByExcel(ctx.Kit, hosts, hostIDArr, indexHostIDMap)
 	if err != nil {
 		blog.Errorf("update host by excel failed, err: %v, rid: %s", err, ctx.Kit.Rid)
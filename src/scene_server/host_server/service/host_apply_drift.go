@@ -0,0 +1,113 @@
+/*
+ * Tencent is pleased to support the open source community by making 蓝鲸 available.
+ * Copyright (C) 2017-2018 THL A29 Limited, a Tencent company. All rights reserved.
+ * Licensed under the MIT License (the "License"); you may not use this file except
+ * in compliance with the License. You may obtain a copy of the License at
+ * http://opensource.org/licenses/MIT
+ * Unless required by applicable law or agreed to in writing, software distributed under
+ * the License is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND,
+ * either express or implied. See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package service
+
+import (
+	"strconv"
+
+	"configcenter/src/common"
+	"configcenter/src/common/blog"
+	"configcenter/src/common/http/rest"
+	"configcenter/src/common/metadata"
+)
+
+// GetHostApplyReconcileConfig get the host apply reconcile config of the business
+func (s *Service) GetHostApplyReconcileConfig(ctx *rest.Contexts) {
+	bizID, err := strconv.ParseInt(ctx.Request.PathParameter(common.BKAppIDField), 10, 64)
+	if err != nil || bizID <= 0 {
+		ctx.RespAutoError(ctx.Kit.CCError.Errorf(common.CCErrCommParamsInvalid, common.BKAppIDField))
+		return
+	}
+
+	opt := &metadata.ListHostApplyReconcileConfigOption{BizIDs: []int64{bizID}}
+	configs, ccErr := s.CoreAPI.CoreService().HostApplyRule().ListHostApplyReconcileConfig(ctx.Kit.Ctx,
+		ctx.Kit.Header, opt)
+	if ccErr != nil {
+		blog.Errorf("get host apply reconcile config failed, bizID: %d, err: %v, rid: %s", bizID, ccErr, ctx.Kit.Rid)
+		ctx.RespAutoError(ccErr)
+		return
+	}
+
+	if len(configs) == 0 {
+		ctx.RespEntity(metadata.HostApplyReconcileConfig{BizID: bizID, Mode: metadata.HostApplyReconcileDisabled})
+		return
+	}
+	ctx.RespEntity(configs[0])
+}
+
+// UpdateHostApplyReconcileConfig update the host apply reconcile mode of the business, the reconciler reacts to the
+// host update events after the mode is changed, the drifts of the business are cleared if the mode is disabled.
+func (s *Service) UpdateHostApplyReconcileConfig(ctx *rest.Contexts) {
+	bizID, err := strconv.ParseInt(ctx.Request.PathParameter(common.BKAppIDField), 10, 64)
+	if err != nil || bizID <= 0 {
+		ctx.RespAutoError(ctx.Kit.CCError.Errorf(common.CCErrCommParamsInvalid, common.BKAppIDField))
+		return
+	}
+
+	opt := new(metadata.UpdateHostApplyReconcileConfigOption)
+	if err := ctx.DecodeInto(opt); err != nil {
+		ctx.RespAutoError(err)
+		return
+	}
+
+	if rawErr := opt.Validate(); rawErr.ErrCode != 0 {
+		ctx.RespAutoError(rawErr.ToCCError(ctx.Kit.CCError))
+		return
+	}
+
+	ccErr := s.CoreAPI.CoreService().HostApplyRule().UpdateHostApplyReconcileConfig(ctx.Kit.Ctx, ctx.Kit.Header,
+		bizID, opt)
+	if ccErr != nil {
+		blog.Errorf("update host apply reconcile config failed, bizID: %d, opt: %+v, err: %v, rid: %s", bizID, opt,
+			ccErr, ctx.Kit.Rid)
+		ctx.RespAutoError(ccErr)
+		return
+	}
+	ctx.RespEntity(nil)
+}
+
+// ListHostApplyDrift list the hosts' fields that drift from the host apply rules in the business, with the expected
+// values of the rules and the actual values of the hosts.
+func (s *Service) ListHostApplyDrift(ctx *rest.Contexts) {
+	bizID, err := strconv.ParseInt(ctx.Request.PathParameter(common.BKAppIDField), 10, 64)
+	if err != nil || bizID <= 0 {
+		ctx.RespAutoError(ctx.Kit.CCError.Errorf(common.CCErrCommParamsInvalid, common.BKAppIDField))
+		return
+	}
+
+	opt := new(metadata.ListHostApplyDriftOption)
+	if err := ctx.DecodeInto(opt); err != nil {
+		ctx.RespAutoError(err)
+		return
+	}
+
+	if rawErr := opt.Validate(); rawErr.ErrCode != 0 {
+		ctx.RespAutoError(rawErr.ToCCError(ctx.Kit.CCError))
+		return
+	}
+
+	if err := checkIDs(opt.ModuleIDs); err != nil {
+		ctx.RespAutoError(ctx.Kit.CCError.Errorf(common.CCErrCommParamsInvalid, "bk_module_ids"))
+		return
+	}
+
+	result, ccErr := s.CoreAPI.CoreService().HostApplyRule().ListHostApplyDrift(ctx.Kit.Ctx, ctx.Kit.Header, bizID,
+		opt)
+	if ccErr != nil {
+		blog.Errorf("list host apply drift failed, bizID: %d, opt: %+v, err: %v, rid: %s", bizID, opt, ccErr,
+			ctx.Kit.Rid)
+		ctx.RespAutoError(ccErr)
+		return
+	}
+	ctx.RespEntity(result)
+}
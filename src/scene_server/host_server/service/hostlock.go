@@ -31,9 +31,9 @@ func (s *Service) LockHost(ctx *rest.Contexts) {
 		return
 	}
 
-	if 0 == len(input.IDS) {
-		blog.Errorf("lock host, id_list is empty,input:%+v, rid:%s", input, ctx.Kit.Rid)
-		ctx.RespAutoError(ctx.Kit.CCError.Errorf(common.CCErrCommParamsNeedSet, "id_list"))
+	if rawErr := input.ValidateLockOption(); rawErr.ErrCode != 0 {
+		blog.Errorf("lock host, input is invalid, input: %+v, rid: %s", input, ctx.Kit.Rid)
+		ctx.RespAutoError(rawErr.ToCCError(ctx.Kit.CCError))
 		return
 	}
 
@@ -100,6 +100,17 @@ func (s *Service) UnlockHost(ctx *rest.Contexts) {
 		return
 	}
 
+	// releasing the locks of the other owners is an admin operation, it needs the global settings permission
+	if input.Force {
+		resource := meta.ResourceAttribute{Basic: meta.Basic{Type: meta.ConfigAdmin, Action: meta.Update}}
+		if resp, authorized := s.AuthManager.Authorize(ctx.Kit, resource); !authorized {
+			blog.Errorf("force unlock hosts %v, but user %s is not authorized, rid: %s", input.IDS, ctx.Kit.User,
+				ctx.Kit.Rid)
+			ctx.RespNoAuth(resp)
+			return
+		}
+	}
+
 	txnErr := s.Engine.CoreAPI.CoreService().Txn().AutoRunTxn(ctx.Kit.Ctx, ctx.Kit.Header, func() error {
 		err := s.Logic.UnlockHost(ctx.Kit, input)
 		if nil != err {
@@ -156,3 +167,47 @@ func (s *Service) QueryHostLock(ctx *rest.Contexts) {
 	}
 	ctx.RespEntity(hostLockInfos)
 }
+
+// QueryHostLockDetail query the unexpired locks of the hosts with the owner, reason, scope and expire time
+func (s *Service) QueryHostLockDetail(ctx *rest.Contexts) {
+	input := new(metadata.QueryHostLockRequest)
+	if err := ctx.DecodeInto(input); err != nil {
+		ctx.RespAutoError(err)
+		return
+	}
+
+	if len(input.IDS) == 0 {
+		ctx.RespAutoError(ctx.Kit.CCError.Errorf(common.CCErrCommParamsNeedSet, "id_list"))
+		return
+	}
+
+	if len(input.IDS) > common.BKMaxLimitSize {
+		ctx.RespAutoError(ctx.Kit.CCError.Errorf(common.CCErrCommXXExceedLimit, "id_list", common.BKMaxLimitSize))
+		return
+	}
+
+	// auth: check authorization
+	if err := s.AuthManager.AuthorizeByHostsIDs(ctx.Kit.Ctx, ctx.Kit.Header, meta.Update, input.IDS...); err != nil {
+		if err != ac.NoAuthorizeError {
+			blog.Errorf("check host authorization failed, hosts: %+v, err: %v, rid: %s", input.IDS, err, ctx.Kit.Rid)
+			ctx.RespAutoError(ctx.Kit.CCError.Error(common.CCErrCommAuthorizeFailed))
+			return
+		}
+		perm, err := s.AuthManager.GenEditBizHostNoPermissionResp(ctx.Kit.Ctx, ctx.Kit.Header, input.IDS)
+		if err != nil {
+			blog.Errorf("gen no permission response failed, err: %v, rid: %s", err, ctx.Kit.Rid)
+			ctx.RespAutoError(ctx.Kit.CCError.Error(common.CCErrCommAuthorizeFailed))
+			return
+		}
+		ctx.RespEntityWithError(perm, ac.NoAuthorizeError)
+		return
+	}
+
+	locks, err := s.Logic.ListHostLock(ctx.Kit, input.IDS)
+	if err != nil {
+		blog.Errorf("query host lock detail failed, err: %v, input: %+v, rid: %s", err, input, ctx.Kit.Rid)
+		ctx.RespAutoError(err)
+		return
+	}
+	ctx.RespEntity(metadata.HostLockDetailResult{Info: locks, Count: int64(len(locks))})
+}
@@ -32,6 +32,11 @@ func (s *Service) TransferHostModule(ctx *rest.Contexts) {
 		return
 	}
 
+	if err := s.Logic.ValidateHostLock(ctx.Kit, config.HostID, metadata.HostLockScopeTransfer); err != nil {
+		ctx.RespAutoError(err)
+		return
+	}
+
 	for _, moduleID := range config.ModuleID {
 		module, err := s.Logic.GetNormalModuleByModuleID(ctx.Kit, config.ApplicationID, moduleID)
 		if err != nil {
@@ -364,6 +369,11 @@ func (s *Service) moveHostToDefaultModule(ctx *rest.Contexts, defaultModuleFlag
 		return
 	}
 
+	if err := s.Logic.ValidateHostLock(ctx.Kit, conf.HostIDs, metadata.HostLockScopeTransfer); err != nil {
+		ctx.RespAutoError(err)
+		return
+	}
+
 	audit := auditlog.NewHostModuleLog(s.CoreAPI.CoreService(), conf.HostIDs)
 	if err := audit.WithPrevious(ctx.Kit); err != nil {
 		blog.Errorf("move host to default module s failed, get prev module host config failed, hostIDs: %v, err: %s, rid: %s", conf.HostIDs, err.Error(), ctx.Kit.Rid)
@@ -427,6 +437,11 @@ func (s *Service) TransferHostResourceDirectory(ctx *rest.Contexts) {
 		return
 	}
 
+	if err := s.Logic.ValidateHostLock(ctx.Kit, input.HostID, metadata.HostLockScopeTransfer); err != nil {
+		ctx.RespAutoError(err)
+		return
+	}
+
 	audit := auditlog.NewHostModuleLog(s.CoreAPI.CoreService(), input.HostID)
 	if err := audit.WithPrevious(ctx.Kit); err != nil {
 		blog.Errorf("TransferHostResourceDirectory, but get prev module host config failed, err: %v, hostIDs:%#v,rid:%s", err, input.HostID, ctx.Kit.Rid)
@@ -208,6 +208,16 @@ func (s *Service) initHostapplyrule(web *restful.WebService) {
 		Path:    "/host/findmany/module/host_apply_plan/status",
 		Handler: s.GetHostApplyTaskStatus})
 
+	// host apply drift reconciliation
+	utility.AddHandler(rest.Action{Verb: http.MethodGet,
+		Path:    "/find/host_apply_reconcile_config/bk_biz_id/{bk_biz_id}",
+		Handler: s.GetHostApplyReconcileConfig})
+	utility.AddHandler(rest.Action{Verb: http.MethodPut,
+		Path:    "/update/host_apply_reconcile_config/bk_biz_id/{bk_biz_id}",
+		Handler: s.UpdateHostApplyReconcileConfig})
+	utility.AddHandler(rest.Action{Verb: http.MethodPost, Path: "/findmany/host_apply_drift/bk_biz_id/{bk_biz_id}",
+		Handler: s.ListHostApplyDrift})
+
 	utility.AddToRestfulWebService(web)
 }
 
@@ -221,6 +231,8 @@ func (s *Service) initHostlock(web *restful.WebService) {
 	utility.AddHandler(rest.Action{Verb: http.MethodPost, Path: "/host/lock", Handler: s.LockHost})
 	utility.AddHandler(rest.Action{Verb: http.MethodDelete, Path: "/host/lock", Handler: s.UnlockHost})
 	utility.AddHandler(rest.Action{Verb: http.MethodPost, Path: "/host/lock/search", Handler: s.QueryHostLock})
+	utility.AddHandler(rest.Action{Verb: http.MethodPost, Path: "/host/lock/detail/search",
+		Handler: s.QueryHostLockDetail})
 
 	utility.AddToRestfulWebService(web)
 
@@ -71,6 +71,11 @@ func (s *Service) TransferHostWithAutoClearServiceInstance(ctx *rest.Contexts) {
 		return
 	}
 
+	if ccErr := s.Logic.ValidateHostLock(ctx.Kit, option.HostIDs, metadata.HostLockScopeTransfer); ccErr != nil {
+		ctx.RespAutoError(ccErr)
+		return
+	}
+
 	transferPlans, hostIDs, err := s.preTransferPlans(ctx.Kit, option, bizID)
 	if err != nil {
 		blog.ErrorJSON("generate transfer plans failed, bizID: %s, option: %s, err: %s, rid: %s", bizID, option, err,
@@ -336,6 +341,16 @@ func (s *Service) upsertServiceInstance(kit *rest.Kit, bizID int64,
 func (s *Service) updateHostApplyByRule(kit *rest.Kit, rules []metadata.HostAttribute,
 	hostIDs []int64) errors.CCErrorCoder {
 
+	// the locked hosts are skipped, the other hosts are still applied with the rules
+	hostIDs, ccErr := s.Logic.FilterLockedHosts(kit, hostIDs, metadata.HostLockScopeUpdate)
+	if ccErr != nil {
+		return ccErr
+	}
+
+	if len(hostIDs) == 0 {
+		return nil
+	}
+
 	attributeIDs := make([]int64, 0)
 	for _, rule := range rules {
 		attributeIDs = append(attributeIDs, rule.AttributeID)
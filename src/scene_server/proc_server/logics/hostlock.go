@@ -0,0 +1,67 @@
+/*
+ * Tencent is pleased to support the open source community by making 蓝鲸 available.
+ * Copyright (C) 2017-2018 THL A29 Limited, a Tencent company. All rights reserved.
+ * Licensed under the MIT License (the "License"); you may not use this file except
+ * in compliance with the License. You may obtain a copy of the License at
+ * http://opensource.org/licenses/MIT
+ * Unless required by applicable law or agreed to in writing, software distributed under
+ * the License is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND,
+ * either express or implied. See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package logics
+
+import (
+	"configcenter/src/common"
+	"configcenter/src/common/blog"
+	"configcenter/src/common/errors"
+	"configcenter/src/common/http/rest"
+	"configcenter/src/common/metadata"
+	"configcenter/src/common/util"
+)
+
+// FilterLockedHosts filter out the hosts that are locked in the scope, returns the unlocked host ids, so that the
+// locked hosts are skipped by the operations that allow partial success like host apply.
+func (lgc *Logic) FilterLockedHosts(kit *rest.Kit, hostIDs []int64, scope metadata.HostLockScope) ([]int64,
+	errors.CCErrorCoder) {
+
+	if len(hostIDs) == 0 {
+		return hostIDs, nil
+	}
+
+	input := &metadata.QueryHostLockRequest{IDS: util.IntArrayUnique(hostIDs)}
+	result, err := lgc.CoreAPI.CoreService().Host().QueryHostLock(kit.Ctx, kit.Header, input)
+	if err != nil {
+		blog.Errorf("list host lock failed, input: %+v, err: %v, rid: %s", input, err, kit.Rid)
+		return nil, kit.CCError.CCError(common.CCErrCommHTTPDoRequestFailed)
+	}
+	if ccErr := result.CCError(); ccErr != nil {
+		blog.Errorf("list host lock failed, input: %+v, err: %v, rid: %s", input, ccErr, kit.Rid)
+		return nil, ccErr
+	}
+
+	lockedHosts := make(map[int64]struct{})
+	for _, lock := range result.Data.Info {
+		if lock.InScope(scope) {
+			lockedHosts[lock.ID] = struct{}{}
+		}
+	}
+
+	if len(lockedHosts) == 0 {
+		return hostIDs, nil
+	}
+
+	unlockedHostIDs := make([]int64, 0)
+	lockedHostIDs := make([]int64, 0)
+	for _, hostID := range hostIDs {
+		if _, exists := lockedHosts[hostID]; exists {
+			lockedHostIDs = append(lockedHostIDs, hostID)
+			continue
+		}
+		unlockedHostIDs = append(unlockedHostIDs, hostID)
+	}
+
+	blog.Warnf("hosts %v are locked, skip the %s operation on them, rid: %s", lockedHostIDs, scope, kit.Rid)
+	return unlockedHostIDs, nil
+}
@@ -489,6 +489,16 @@ func generateCondition(dataStr string, hostIDs []int64) (map[string]interface{},
 func (s *ProcServer) updateHostAttributes(kit *rest.Kit, planResult *metadata.HostApplyServiceTemplateOption,
 	hostIDs []int64) errors.CCErrorCoder {
 
+	// the locked hosts are skipped, the other hosts are still applied with the rules
+	hostIDs, err := s.Logic.FilterLockedHosts(kit, hostIDs, metadata.HostLockScopeUpdate)
+	if err != nil {
+		return err
+	}
+
+	if len(hostIDs) == 0 {
+		return nil
+	}
+
 	dataStr, err := s.getUpdateDataStrByApplyRule(kit, planResult.AdditionalRules)
 	if err != nil {
 		return err
@@ -48,8 +48,7 @@ func (lgc *Logics) Create(kit *rest.Kit, input *metadata.CreateTaskRequest) (met
 		common.BKTaskTypeField: input.TaskType,
 		common.BKInstIDField:   input.InstID,
 		common.BKStatusField: map[string]interface{}{
-			common.BKDBIN: []metadata.APITaskStatus{metadata.APITaskStatusNew, metadata.APITaskStatusWaitExecute,
-				metadata.APITaskStatusExecute},
+			common.BKDBIN: metadata.APITaskUnfinishedStatus,
 		},
 	}
 
@@ -77,6 +76,7 @@ func (lgc *Logics) Create(kit *rest.Kit, input *metadata.CreateTaskRequest) (met
 	dbTask.Header = GetDBHTTPHeader(kit.Header)
 	dbTask.Status = metadata.APITaskStatusNew
 	dbTask.Extra = input.Extra
+	dbTask.Priority = input.Priority
 	dbTask.CreateTime = time.Now()
 	dbTask.LastTime = time.Now()
 	dbTask.SupplierAccount = kit.SupplierAccount
@@ -87,6 +87,7 @@ func (lgc *Logics) Create(kit *rest.Kit, input *metadata.CreateTaskRequest) (met
 			Status:    metadata.APITaskStatusNew,
 		})
 	}
+	dbTask.Progress = metadata.NewAPITaskProgress(dbTask.Detail)
 	err = lgc.db.Table(common.BKTableNameAPITask).Insert(kit.Ctx, dbTask)
 	if err != nil {
 		blog.Errorf("create task failed, data: %#v, err: %v, rid: %s", dbTask, err, kit.Rid)
@@ -98,6 +99,7 @@ func (lgc *Logics) Create(kit *rest.Kit, input *metadata.CreateTaskRequest) (met
 		TaskType:        input.TaskType,
 		InstID:          input.InstID,
 		Status:          metadata.APITaskStatusNew,
+		Progress:        dbTask.Progress,
 		Creator:         kit.User,
 		CreateTime:      dbTask.CreateTime,
 		LastTime:        dbTask.LastTime,
@@ -157,6 +159,7 @@ func (lgc *Logics) CreateBatch(kit *rest.Kit, tasks []metadata.CreateTaskRequest
 		dbTask.TaskID = getStrTaskID("id")
 		dbTask.TaskType = task.TaskType
 		dbTask.InstID = task.InstID
+		dbTask.Priority = task.Priority
 		dbTask.Detail = make([]metadata.APISubTaskDetail, 0)
 		for _, taskItem := range task.Data {
 			dbTask.Detail = append(dbTask.Detail, metadata.APISubTaskDetail{
@@ -165,11 +168,13 @@ func (lgc *Logics) CreateBatch(kit *rest.Kit, tasks []metadata.CreateTaskRequest
 				Status:    metadata.APITaskStatusNew,
 			})
 		}
+		dbTask.Progress = metadata.NewAPITaskProgress(dbTask.Detail)
 		dbTasks[index] = dbTask
 
 		taskHistory.TaskID = dbTask.TaskID
 		taskHistory.TaskType = task.TaskType
 		taskHistory.InstID = task.InstID
+		taskHistory.Progress = dbTask.Progress
 		taskHistories[index] = taskHistory
 	}
 
@@ -229,6 +234,7 @@ func (lgc *Logics) CreateFieldTemplateBatch(kit *rest.Kit, tasks []metadata.Crea
 		dbTask.TaskID = getStrTaskID("id")
 		dbTask.TaskType = task.TaskType
 		dbTask.InstID = task.InstID
+		dbTask.Priority = task.Priority
 		dbTask.Extra = task.Extra
 		dbTask.Detail = make([]metadata.APISubTaskDetail, 0)
 		for _, taskItem := range task.Data {
@@ -238,11 +244,13 @@ func (lgc *Logics) CreateFieldTemplateBatch(kit *rest.Kit, tasks []metadata.Crea
 				Status:    metadata.APITaskStatusNew,
 			})
 		}
+		dbTask.Progress = metadata.NewAPITaskProgress(dbTask.Detail)
 		dbTasks[index] = dbTask
 
 		taskHistory.TaskID = dbTask.TaskID
 		taskHistory.TaskType = task.TaskType
 		taskHistory.InstID = task.InstID
+		taskHistory.Progress = dbTask.Progress
 		taskHistory.Extra = task.Extra
 		taskHistories[index] = taskHistory
 
@@ -271,8 +279,7 @@ func (lgc *Logics) isDuplicateFieldTmplTaskInProgress(kit *rest.Kit, taskType st
 		common.BKInstIDField:       instID,
 		metadata.APITaskExtraField: extra,
 		common.BKStatusField: map[string]interface{}{
-			common.BKDBIN: []metadata.APITaskStatus{metadata.APITaskStatusNew, metadata.APITaskStatusWaitExecute,
-				metadata.APITaskStatusExecute},
+			common.BKDBIN: metadata.APITaskUnfinishedStatus,
 		},
 	}
 
@@ -296,8 +303,7 @@ func (lgc *Logics) isDuplicateTaskInProgress(kit *rest.Kit, taskTypes []string,
 		common.BKTaskTypeField: map[string]interface{}{common.BKDBIN: taskTypes},
 		common.BKInstIDField:   map[string]interface{}{common.BKDBIN: instIDs},
 		common.BKStatusField: map[string]interface{}{
-			common.BKDBIN: []metadata.APITaskStatus{metadata.APITaskStatusNew, metadata.APITaskStatusWaitExecute,
-				metadata.APITaskStatusExecute},
+			common.BKDBIN: metadata.APITaskUnfinishedStatus,
 		},
 	}
 
@@ -756,3 +762,208 @@ func (lgc *Logics) UpdateTaskStatus(ctx context.Context, cond mapstr.MapStr, sta
 
 	return nil
 }
+
+// getOperateTasks get the tasks to be operated, returns error if some tasks are not found or their status can not be
+// changed to the target status
+func (lgc *Logics) getOperateTasks(kit *rest.Kit, taskIDs []string, targetStatus metadata.APITaskStatus) (
+	[]metadata.APITaskDetail, error) {
+
+	taskIDs = util.StrArrayUnique(taskIDs)
+	cond := mapstr.MapStr{
+		common.BKTaskIDField: mapstr.MapStr{common.BKDBIN: taskIDs},
+	}
+	cond = util.SetQueryOwner(cond, kit.SupplierAccount)
+
+	tasks := make([]metadata.APITaskDetail, 0)
+	err := lgc.db.Table(common.BKTableNameAPITask).Find(cond).Fields(common.BKTaskIDField, common.BKStatusField,
+		"detail.sub_task_id", "detail.status").All(kit.Ctx, &tasks)
+	if err != nil {
+		blog.Errorf("get tasks to operate failed, err: %v, cond: %#v, rid: %s", err, cond, kit.Rid)
+		return nil, kit.CCError.Error(common.CCErrCommDBSelectFailed)
+	}
+
+	if len(tasks) != len(taskIDs) {
+		blog.Errorf("some tasks are not found, task ids: %+v, tasks: %+v, rid: %s", taskIDs, tasks, kit.Rid)
+		return nil, kit.CCError.CCError(common.CCErrTaskNotFound)
+	}
+
+	for _, task := range tasks {
+		if !task.Status.CanChangeTo(targetStatus) {
+			blog.Errorf("task %s status %s can not be changed to %s, rid: %s", task.TaskID, task.Status,
+				targetStatus, kit.Rid)
+			return nil, kit.CCError.CCErrorf(common.CCErrTaskStatusNotAllowChangeTo, targetStatus)
+		}
+	}
+
+	return tasks, nil
+}
+
+// changeTaskStatus change the status of the tasks whose status can be changed to the target status
+func (lgc *Logics) changeTaskStatus(kit *rest.Kit, taskIDs []string, targetStatus metadata.APITaskStatus) (
+	[]metadata.APITaskDetail, error) {
+
+	tasks, err := lgc.getOperateTasks(kit, taskIDs, targetStatus)
+	if err != nil {
+		return nil, err
+	}
+
+	// tasks whose status are changed during the operation will not be updated
+	cond := mapstr.MapStr{
+		common.BKTaskIDField: mapstr.MapStr{common.BKDBIN: taskIDs},
+		common.BKStatusField: mapstr.MapStr{common.BKDBIN: metadata.APITaskStatusTransitions[targetStatus]},
+	}
+	cond = util.SetQueryOwner(cond, kit.SupplierAccount)
+
+	if err = lgc.UpdateTaskStatus(kit.Ctx, cond, targetStatus, kit.Rid); err != nil {
+		return nil, kit.CCError.CCError(common.CCErrCommDBUpdateFailed)
+	}
+
+	return tasks, nil
+}
+
+// CancelTask cancel the unfinished tasks and their subtasks that are not executed, the executing batch of subtasks will
+// not be interrupted, but the subsequent subtasks will not be executed.
+func (lgc *Logics) CancelTask(kit *rest.Kit, taskIDs []string) error {
+	tasks, err := lgc.changeTaskStatus(kit, taskIDs, metadata.APITaskStatusCanceled)
+	if err != nil {
+		return err
+	}
+
+	for _, task := range tasks {
+		for _, subTask := range task.Detail {
+			if subTask.Status != metadata.APITaskStatusNew && subTask.Status != metadata.APITaskStatusWaitExecute {
+				continue
+			}
+
+			// only cancel the subtask that is not executed, the executing subtask will update its own status
+			cond := mapstr.MapStr{
+				common.BKTaskIDField: task.TaskID,
+				"detail": mapstr.MapStr{common.BKDBElemMatch: mapstr.MapStr{
+					"sub_task_id": subTask.SubTaskID,
+					common.BKStatusField: mapstr.MapStr{common.BKDBIN: []metadata.APITaskStatus{
+						metadata.APITaskStatusNew, metadata.APITaskStatusWaitExecute}},
+				}},
+			}
+			data := mapstr.MapStr{"detail.$.status": metadata.APITaskStatusCanceled}
+
+			if err = lgc.db.Table(common.BKTableNameAPITask).Update(kit.Ctx, cond, data); err != nil {
+				blog.Errorf("cancel subtask failed, err: %v, cond: %#v, rid: %s", err, cond, kit.Rid)
+				return kit.CCError.CCError(common.CCErrCommDBUpdateFailed)
+			}
+		}
+	}
+
+	return nil
+}
+
+// PauseTask pause the tasks that are not finished, the executing batch of subtasks will not be interrupted, but the
+// subsequent subtasks will not be executed until the task is resumed.
+func (lgc *Logics) PauseTask(kit *rest.Kit, taskIDs []string) error {
+	_, err := lgc.changeTaskStatus(kit, taskIDs, metadata.APITaskStatusPaused)
+	return err
+}
+
+// ResumeTask resume the paused tasks, put them back to the wait execute queue
+func (lgc *Logics) ResumeTask(kit *rest.Kit, taskIDs []string) error {
+	_, err := lgc.changeTaskStatus(kit, taskIDs, metadata.APITaskStatusWaitExecute)
+	return err
+}
+
+// UpdateTaskPriority update the priority of the tasks that are not finished
+func (lgc *Logics) UpdateTaskPriority(kit *rest.Kit, taskIDs []string, priority int64) error {
+	taskIDs = util.StrArrayUnique(taskIDs)
+	cond := mapstr.MapStr{
+		common.BKTaskIDField: mapstr.MapStr{common.BKDBIN: taskIDs},
+		common.BKStatusField: mapstr.MapStr{common.BKDBIN: metadata.APITaskUnfinishedStatus},
+	}
+	cond = util.SetQueryOwner(cond, kit.SupplierAccount)
+
+	data := mapstr.MapStr{
+		metadata.APITaskPriorityField: priority,
+		common.LastTimeField:          time.Now(),
+	}
+
+	if err := lgc.db.Table(common.BKTableNameAPITask).Update(kit.Ctx, cond, data); err != nil {
+		blog.Errorf("update task priority failed, err: %v, cond: %#v, rid: %s", err, cond, kit.Rid)
+		return kit.CCError.CCError(common.CCErrCommDBUpdateFailed)
+	}
+
+	return nil
+}
+
+// watchTaskProgressInterval is the interval to check if the watched task is updated, the executing task's progress is
+// updated every few seconds, so checking it more frequently only adds db load.
+const watchTaskProgressInterval = 3 * time.Second
+
+// WatchTaskProgress long poll the task progress, returns when the task is updated after the last time in the option,
+// or the task is finished, or the watch times out.
+func (lgc *Logics) WatchTaskProgress(kit *rest.Kit, opt *metadata.WatchAPITaskProgressOption) (
+	*metadata.APITaskProgressResult, error) {
+
+	deadline := time.Now().Add(time.Duration(opt.Timeout) * time.Second)
+	for {
+		result, err := lgc.getTaskProgress(kit, opt.TaskID)
+		if err != nil {
+			return nil, err
+		}
+
+		if opt.LastTime == nil || result.LastTime.After(*opt.LastTime) {
+			result.Changed = true
+			return result, nil
+		}
+
+		if result.Status.IsFinished() || !time.Now().Before(deadline) {
+			return result, nil
+		}
+
+		select {
+		case <-kit.Ctx.Done():
+			return result, nil
+		case <-time.After(watchTaskProgressInterval):
+		}
+	}
+}
+
+func (lgc *Logics) getTaskProgress(kit *rest.Kit, taskID string) (*metadata.APITaskProgressResult, error) {
+	cond := mapstr.MapStr{common.BKTaskIDField: taskID}
+	cond = util.SetQueryOwner(cond, kit.SupplierAccount)
+
+	// do not get the subtask data, which is useless for progress and may be large
+	fields := []string{common.BKTaskIDField, common.BKStatusField, metadata.APITaskProgressField,
+		common.LastTimeField, "detail.sub_task_id", "detail.status", "detail.response", "detail.retry_times",
+		"detail.start_time", "detail.end_time"}
+
+	tasks := make([]metadata.APITaskDetail, 0)
+	err := lgc.db.Table(common.BKTableNameAPITask).Find(cond).Fields(fields...).Limit(1).All(kit.Ctx, &tasks)
+	if err != nil {
+		blog.Errorf("get task progress failed, err: %v, cond: %#v, rid: %s", err, cond, kit.Rid)
+		return nil, kit.CCError.CCError(common.CCErrCommDBSelectFailed)
+	}
+
+	if len(tasks) == 0 {
+		blog.Errorf("task %s is not found, rid: %s", taskID, kit.Rid)
+		return nil, kit.CCError.CCError(common.CCErrTaskNotFound)
+	}
+	task := tasks[0]
+
+	// compatible for the tasks that are created before progress is supported
+	if task.Progress.Total == 0 {
+		task.Progress = metadata.NewAPITaskProgress(task.Detail)
+	}
+
+	result := &metadata.APITaskProgressResult{
+		TaskID:         task.TaskID,
+		Status:         task.Status,
+		Progress:       task.Progress,
+		FailedSubTasks: make([]metadata.APISubTaskDetail, 0),
+		LastTime:       task.LastTime,
+	}
+
+	for _, subTask := range task.Detail {
+		if subTask.Status == metadata.APITAskStatusFail {
+			result.FailedSubTasks = append(result.FailedSubTasks, subTask)
+		}
+	}
+
+	return result, nil
+}
@@ -33,6 +33,12 @@ import (
 
 var (
 	dbMaxRetry = 3
+	// interruptCheckBatchSize the number of subtasks executed between two checks of whether the task is interrupted
+	interruptCheckBatchSize = 10
+	// progressUpdateBatchSize the max number of subtasks executed between two updates of the task progress
+	progressUpdateBatchSize = 10
+	// progressUpdateInterval the max interval between two updates of the task progress
+	progressUpdateInterval = 3 * time.Second
 )
 
 // TaskInfo TODO
@@ -212,23 +218,55 @@ func (tq *TaskQueue) executePush(ctx context.Context, taskInfo TaskInfo, taskQue
 	blog.InfoJSON("start execute task, id: %s, rid: %s", taskQueue.TaskID, kit.Rid)
 
 	allSucc := true
+	progress := metadata.NewAPITaskProgress(taskQueue.Detail)
+	startTime, startDone := time.Now(), progress.Done
 
+	executed := 0
+	throttle := new(progressThrottle)
 	for _, subTask := range taskQueue.Detail {
+		if subTask.Status == metadata.APITaskStatusSuccess {
+			continue
+		}
+
+		// stop executing the subsequent subtasks if the task is paused or canceled, the status is checked once per
+		// batch of subtasks to avoid querying db for every subtask
+		if executed%interruptCheckBatchSize == 0 && tq.isTaskInterrupted(kit, taskQueue) {
+			tq.updateTaskProgress(kit, taskQueue.TaskID, &metadata.APITaskProgress{Total: progress.Total,
+				Done: progress.Done})
+			return
+		}
+
+		progress.CurrentSubTaskID = subTask.SubTaskID
+		if throttle.needUpdate(time.Now()) {
+			tq.updateTaskProgress(kit, taskQueue.TaskID, &progress)
+		}
+
+		executed++
 		success, needReturn := tq.executeSubTask(kit, taskInfo, taskQueue.TaskID, &subTask)
 		if needReturn {
 			return
 		}
 
+		progress.Done++
+		progress.EstimatedEndTime = estimateEndTime(startTime, progress.Done-startDone, progress.Total-progress.Done)
+
 		if !success {
 			allSucc = false
 			break
 		}
 	}
 
+	tq.updateTaskProgress(kit, taskQueue.TaskID, &metadata.APITaskProgress{Total: progress.Total,
+		Done: progress.Done})
+
 	// 所有任务执行完成，修改整个任务状态
 	blog.Infof("execute task %s done, all subtask success: %v, rid: %s", taskQueue.TaskID, allSucc, kit.Rid)
 
-	updateCond := mapstr.MapStr{common.BKTaskIDField: taskQueue.TaskID}
+	// only update the executing task, the task may be paused or canceled during the execution of the last subtask
+	updateCond := mapstr.MapStr{
+		common.BKTaskIDField: taskQueue.TaskID,
+		common.BKStatusField: metadata.APITaskStatusExecute,
+	}
 	var updateStatus metadata.APITaskStatus
 	if allSucc {
 		updateStatus = metadata.APITaskStatusSuccess
@@ -266,9 +304,12 @@ func (tq *TaskQueue) executeSubTask(kit *rest.Kit, taskInfo TaskInfo, taskID str
 		return false, false
 	}
 
+	startTime := time.Now()
 	var resp *metadata.Response
 	var err error
+	var retryTimes int64 = -1
 	needReturn := retryWrapper(kit, int(taskInfo.Retry), func() error {
+		retryTimes++
 		if resp, err = tq.service.CoreAPI.TaskServer().Queue(taskInfo.Name).Post(kit.Ctx, kit.Header, taskInfo.Path,
 			subTask.Data); err != nil {
 			time.Sleep(time.Millisecond * 100)
@@ -286,7 +327,8 @@ func (tq *TaskQueue) executeSubTask(kit *rest.Kit, taskInfo TaskInfo, taskID str
 	if err != nil {
 		resp.Result = false
 		resp.Code = common.CCErrCommHTTPDoRequestFailed
-		resp.ErrMsg = kit.CCError.CCErrorf(common.CCErrCommHTTPDoRequestFailed).Error()
+		resp.ErrMsg = fmt.Sprintf("%s, err: %v", kit.CCError.CCErrorf(common.CCErrCommHTTPDoRequestFailed).Error(),
+			err)
 	}
 
 	updateCond := mapstr.MapStr{"task_id": taskID, "detail.sub_task_id": subTask.SubTaskID}
@@ -294,11 +336,13 @@ func (tq *TaskQueue) executeSubTask(kit *rest.Kit, taskInfo TaskInfo, taskID str
 
 	if err != nil || !resp.Result {
 		updateData.Set("detail.$.status", metadata.APITAskStatusFail)
-		updateData.Set("status", metadata.APITAskStatusFail)
 	} else {
 		updateData.Set("detail.$.status", metadata.APITaskStatusSuccess)
 	}
 	updateData.Set("detail.$.response", resp)
+	updateData.Set("detail.$.retry_times", retryTimes)
+	updateData.Set("detail.$.start_time", startTime)
+	updateData.Set("detail.$.end_time", time.Now())
 	updateData.Set(common.LastTimeField, time.Now())
 
 	needReturn = retryWrapper(kit, dbMaxRetry, func() error {
@@ -325,6 +369,58 @@ func (tq *TaskQueue) executeSubTask(kit *rest.Kit, taskInfo TaskInfo, taskID str
 	return true, false
 }
 
+// updateTaskProgress update task execution progress, progress is only used for display, so the task keeps executing
+// if it is failed to be updated.
+func (tq *TaskQueue) updateTaskProgress(kit *rest.Kit, taskID string, progress *metadata.APITaskProgress) {
+	cond := mapstr.MapStr{common.BKTaskIDField: taskID}
+	data := mapstr.MapStr{
+		metadata.APITaskProgressField: progress,
+		common.LastTimeField:          time.Now(),
+	}
+
+	if err := tq.service.DB.Table(common.BKTableNameAPITask).Update(kit.Ctx, cond, data); err != nil {
+		blog.Errorf("update task progress failed, err: %v, cond: %#v, data: %#v, rid: %s", err, cond, data, kit.Rid)
+		return
+	}
+
+	historyCond := mapstr.MapStr{common.BKTaskIDField: taskID}
+	historyData := mapstr.MapStr{metadata.APITaskProgressField: progress}
+	err := tq.service.DB.Table(common.BKTableNameAPITaskSyncHistory).Update(kit.Ctx, historyCond, historyData)
+	if err != nil {
+		blog.Errorf("update task history progress failed, err: %v, cond: %#v, data: %#v, rid: %s", err, historyCond,
+			historyData, kit.Rid)
+	}
+}
+
+// progressThrottle decides whether the task progress needs to be updated before executing a subtask, the progress
+// is updated every progressUpdateBatchSize subtasks or every progressUpdateInterval to reduce db writes.
+type progressThrottle struct {
+	count      int
+	lastUpdate time.Time
+}
+
+func (p *progressThrottle) needUpdate(now time.Time) bool {
+	p.count++
+	if p.count < progressUpdateBatchSize && now.Sub(p.lastUpdate) < progressUpdateInterval {
+		return false
+	}
+
+	p.count = 0
+	p.lastUpdate = now
+	return true
+}
+
+// estimateEndTime estimate the end time of the task by the average cost of the subtasks executed in this execution
+func estimateEndTime(startTime time.Time, executed, remaining int64) *time.Time {
+	if executed <= 0 {
+		return nil
+	}
+
+	now := time.Now()
+	endTime := now.Add(now.Sub(startTime) / time.Duration(executed) * time.Duration(remaining))
+	return &endTime
+}
+
 // retryWrapper retry task execute step wrapper, returns if task is terminated.
 func retryWrapper(kit *rest.Kit, maxRetry int, handler func() error) bool {
 	for retry := 0; retry < maxRetry; retry++ {
@@ -380,6 +476,28 @@ func (tq *TaskQueue) isTaskLocked(ctx context.Context, taskID string) (bool, err
 	return result == 1, nil
 }
 
+// isTaskInterrupted returns if the task is paused or canceled and needs to stop executing
+func (tq *TaskQueue) isTaskInterrupted(kit *rest.Kit, taskQueue *metadata.APITaskDetail) bool {
+	cond := mapstr.MapStr{
+		common.BKTaskIDField:     taskQueue.TaskID,
+		common.BkSupplierAccount: taskQueue.SupplierAccount,
+	}
+
+	task := new(metadata.APITaskDetail)
+	err := tq.service.DB.Table(common.BKTableNameAPITask).Find(cond).Fields(common.BKStatusField).One(kit.Ctx, task)
+	if err != nil {
+		// do not interrupt the task if its status can not be confirmed
+		blog.Errorf("get task %s status failed, err: %v, rid: %s", taskQueue.TaskID, err, kit.Rid)
+		return false
+	}
+
+	if task.Status.IsInterrupted() {
+		blog.Infof("task %s is %s, stop executing it, rid: %s", taskQueue.TaskID, task.Status, kit.Rid)
+		return true
+	}
+	return false
+}
+
 func (tq *TaskQueue) getWaitExecute(ctx context.Context, name string) ([]metadata.APITaskDetail, error) {
 	cond := mapstr.MapStr{
 		common.BKTaskTypeField: name,
@@ -389,7 +507,8 @@ func (tq *TaskQueue) getWaitExecute(ctx context.Context, name string) ([]metadat
 	}
 
 	rows := make([]metadata.APITaskDetail, 0)
-	err := tq.service.DB.Table(common.BKTableNameAPITask).Find(cond).Sort("create_time").Limit(20).All(ctx, &rows)
+	err := tq.service.DB.Table(common.BKTableNameAPITask).Find(cond).
+		Sort("-"+metadata.APITaskPriorityField+","+common.CreateTimeField).Limit(20).All(ctx, &rows)
 	if err != nil {
 		blog.ErrorJSON("query wait execute failed, err: %v, task type: %s, cond: %#v", err, name, cond)
 		return nil, tq.service.CCErr.Error("zh-cn", common.CCErrCommDBSelectFailed)
@@ -0,0 +1,220 @@
+/*
+ * Tencent is pleased to support the open source community by making 蓝鲸 available.
+ * Copyright (C) 2017-2018 THL A29 Limited, a Tencent company. All rights reserved.
+ * Licensed under the MIT License (the "License"); you may not use this file except
+ * in compliance with the License. You may obtain a copy of the License at
+ * http://opensource.org/licenses/MIT
+ * Unless required by applicable law or agreed to in writing, software distributed under
+ * the License is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND,
+ * either express or implied. See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package service
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"sort"
+	"strings"
+	"testing"
+	"time"
+
+	"configcenter/src/common"
+	"configcenter/src/common/http/rest"
+	"configcenter/src/common/mapstr"
+	"configcenter/src/common/metadata"
+	"configcenter/src/storage/dal"
+	"configcenter/src/storage/dal/types"
+)
+
+// fakeDB is a db that returns the preset tasks and records the query of api task table
+type fakeDB struct {
+	dal.RDB
+	find *fakeFind
+}
+
+// Table returns the fake table
+func (f *fakeDB) Table(collection string) types.Table {
+	return &fakeTable{find: f.find}
+}
+
+type fakeTable struct {
+	types.Table
+	find *fakeFind
+}
+
+// Find records the query filter
+func (f *fakeTable) Find(filter types.Filter, opts ...*types.FindOpts) types.Find {
+	f.find.filter = filter
+	return f.find
+}
+
+type fakeFind struct {
+	types.Find
+	filter types.Filter
+	sort   string
+	limit  uint64
+	tasks  []metadata.APITaskDetail
+	err    error
+}
+
+// Fields returns the fake find itself, all fields are returned
+func (f *fakeFind) Fields(fields ...string) types.Find {
+	return f
+}
+
+// Sort records the sort, only sorting by priority and create time is supported
+func (f *fakeFind) Sort(sort string) types.Find {
+	f.sort = sort
+	return f
+}
+
+// Limit records the limit
+func (f *fakeFind) Limit(limit uint64) types.Find {
+	f.limit = limit
+	return f
+}
+
+// One returns the first preset task
+func (f *fakeFind) One(ctx context.Context, result interface{}) error {
+	if f.err != nil {
+		return f.err
+	}
+	*result.(*metadata.APITaskDetail) = f.tasks[0]
+	return nil
+}
+
+// All returns the preset tasks sorted by the recorded sort
+func (f *fakeFind) All(ctx context.Context, result interface{}) error {
+	if f.err != nil {
+		return f.err
+	}
+
+	tasks := append([]metadata.APITaskDetail{}, f.tasks...)
+	sortFields := strings.Split(f.sort, ",")
+	sort.SliceStable(tasks, func(i, j int) bool {
+		for _, field := range sortFields {
+			desc := strings.HasPrefix(field, "-")
+			switch strings.TrimLeft(field, "+-") {
+			case metadata.APITaskPriorityField:
+				if tasks[i].Priority != tasks[j].Priority {
+					return (tasks[i].Priority > tasks[j].Priority) == desc
+				}
+			case common.CreateTimeField:
+				if !tasks[i].CreateTime.Equal(tasks[j].CreateTime) {
+					return tasks[i].CreateTime.After(tasks[j].CreateTime) == desc
+				}
+			}
+		}
+		return false
+	})
+
+	*result.(*[]metadata.APITaskDetail) = tasks
+	return nil
+}
+
+func newFakeTaskQueue(find *fakeFind) *TaskQueue {
+	return &TaskQueue{service: &Service{DB: &fakeDB{find: find}}}
+}
+
+func TestIsTaskInterrupted(t *testing.T) {
+	kit := &rest.Kit{Ctx: context.Background(), Rid: "test_rid"}
+	taskQueue := &metadata.APITaskDetail{TaskID: "task1", SupplierAccount: "1"}
+	expectCond := mapstr.MapStr{common.BKTaskIDField: "task1", common.BkSupplierAccount: "1"}
+
+	tests := []struct {
+		name   string
+		status metadata.APITaskStatus
+		err    error
+		want   bool
+	}{
+		{"executing task", metadata.APITaskStatusExecute, nil, false},
+		{"paused task", metadata.APITaskStatusPaused, nil, true},
+		{"canceled task", metadata.APITaskStatusCanceled, nil, true},
+		{"resumed task", metadata.APITaskStatusWaitExecute, nil, false},
+		{"get status failed", metadata.APITaskStatusPaused, errors.New("db error"), false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			find := &fakeFind{tasks: []metadata.APITaskDetail{{TaskID: "task1", Status: tt.status}}, err: tt.err}
+			if got := newFakeTaskQueue(find).isTaskInterrupted(kit, taskQueue); got != tt.want {
+				t.Errorf("isTaskInterrupted() = %v, want %v", got, tt.want)
+			}
+
+			if !reflect.DeepEqual(find.filter, expectCond) {
+				t.Errorf("isTaskInterrupted() filter = %#v, want %#v", find.filter, expectCond)
+			}
+		})
+	}
+}
+
+func TestGetWaitExecuteOrder(t *testing.T) {
+	now := time.Now()
+	find := &fakeFind{tasks: []metadata.APITaskDetail{
+		{TaskID: "low_old", Priority: 0, CreateTime: now.Add(-2 * time.Hour)},
+		{TaskID: "high_new", Priority: 10, CreateTime: now},
+		{TaskID: "low_new", Priority: 0, CreateTime: now},
+		{TaskID: "high_old", Priority: 10, CreateTime: now.Add(-time.Hour)},
+		{TaskID: "middle", Priority: 5, CreateTime: now.Add(time.Hour)},
+	}}
+
+	tasks, err := newFakeTaskQueue(find).getWaitExecute(context.Background(), "sync_task")
+	if err != nil {
+		t.Fatalf("getWaitExecute() failed, err: %v", err)
+	}
+
+	taskIDs := make([]string, 0)
+	for _, task := range tasks {
+		taskIDs = append(taskIDs, task.TaskID)
+	}
+
+	// tasks with higher priority are executed first, tasks with the same priority are executed by their create time
+	expectIDs := []string{"high_old", "high_new", "middle", "low_old", "low_new"}
+	if !reflect.DeepEqual(taskIDs, expectIDs) {
+		t.Errorf("getWaitExecute() order = %v, want %v", taskIDs, expectIDs)
+	}
+
+	if expectSort := "-priority,create_time"; find.sort != expectSort {
+		t.Errorf("getWaitExecute() sort = %s, want %s", find.sort, expectSort)
+	}
+
+	expectCond := mapstr.MapStr{
+		common.BKTaskTypeField: "sync_task",
+		common.BKStatusField: mapstr.MapStr{
+			common.BKDBIN: []metadata.APITaskStatus{metadata.APITaskStatusNew, metadata.APITaskStatusWaitExecute},
+		},
+	}
+	if !reflect.DeepEqual(find.filter, expectCond) {
+		t.Errorf("getWaitExecute() filter = %#v, want %#v", find.filter, expectCond)
+	}
+}
+
+func TestProgressThrottle(t *testing.T) {
+	start := time.Now()
+	throttle := new(progressThrottle)
+
+	// the progress of the first subtask is always updated
+	if !throttle.needUpdate(start) {
+		t.Fatalf("progress of the first subtask is not updated")
+	}
+
+	// the progress is updated after progressUpdateBatchSize subtasks are executed in the interval
+	for i := 1; i < progressUpdateBatchSize; i++ {
+		if throttle.needUpdate(start.Add(time.Duration(i) * time.Millisecond)) {
+			t.Fatalf("progress of subtask %d is updated in the batch", i)
+		}
+	}
+	if !throttle.needUpdate(start.Add(time.Second)) {
+		t.Errorf("progress is not updated after %d subtasks", progressUpdateBatchSize)
+	}
+
+	// the progress is updated after the interval even if the batch is not full
+	if throttle.needUpdate(start.Add(time.Second + time.Millisecond)) {
+		t.Errorf("progress is updated right after the last update")
+	}
+	if !throttle.needUpdate(start.Add(time.Second + progressUpdateInterval)) {
+		t.Errorf("progress is not updated after the update interval")
+	}
+}
@@ -88,6 +88,13 @@ func (s *Service) addAPIService(web *restful.WebService) {
 	utility.AddHandler(rest.Action{Verb: http.MethodPost, Path: "/task/findone/detail/{task_id}",
 		Handler: s.DetailTask})
 	utility.AddHandler(rest.Action{Verb: http.MethodPost, Path: "/task/deletemany", Handler: s.DeleteTask})
+	utility.AddHandler(rest.Action{Verb: http.MethodPost, Path: "/task/watch/progress",
+		Handler: s.WatchTaskProgress})
+	utility.AddHandler(rest.Action{Verb: http.MethodPost, Path: "/task/cancel", Handler: s.CancelTask})
+	utility.AddHandler(rest.Action{Verb: http.MethodPost, Path: "/task/pause", Handler: s.PauseTask})
+	utility.AddHandler(rest.Action{Verb: http.MethodPost, Path: "/task/resume", Handler: s.ResumeTask})
+	utility.AddHandler(rest.Action{Verb: http.MethodPut, Path: "/task/update/priority",
+		Handler: s.UpdateTaskPriority})
 	utility.AddHandler(rest.Action{Verb: http.MethodPost, Path: "/findmany/latest/sync_status",
 		Handler: s.ListLatestSyncStatus})
 	utility.AddHandler(rest.Action{Verb: http.MethodPost, Path: "/findmany/sync_status_history",
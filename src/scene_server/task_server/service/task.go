@@ -138,6 +138,112 @@ func (s *Service) DeleteTask(ctx *rest.Contexts) {
 	ctx.RespEntity(common.CCSuccessStr)
 }
 
+// CancelTask cancel the unfinished tasks and their subtasks
+func (s *Service) CancelTask(ctx *rest.Contexts) {
+	input := new(metadata.OperateAPITaskOption)
+	if err := ctx.DecodeInto(input); err != nil {
+		ctx.RespAutoError(err)
+		return
+	}
+
+	if rawErr := input.Validate(); rawErr.ErrCode != 0 {
+		ctx.RespAutoError(rawErr.ToCCError(ctx.Kit.CCError))
+		return
+	}
+
+	if err := s.Logics.CancelTask(ctx.Kit, input.TaskIDs); err != nil {
+		ctx.RespAutoError(err)
+		return
+	}
+
+	ctx.RespEntity(nil)
+}
+
+// PauseTask pause the unfinished tasks
+func (s *Service) PauseTask(ctx *rest.Contexts) {
+	input := new(metadata.OperateAPITaskOption)
+	if err := ctx.DecodeInto(input); err != nil {
+		ctx.RespAutoError(err)
+		return
+	}
+
+	if rawErr := input.Validate(); rawErr.ErrCode != 0 {
+		ctx.RespAutoError(rawErr.ToCCError(ctx.Kit.CCError))
+		return
+	}
+
+	if err := s.Logics.PauseTask(ctx.Kit, input.TaskIDs); err != nil {
+		ctx.RespAutoError(err)
+		return
+	}
+
+	ctx.RespEntity(nil)
+}
+
+// ResumeTask resume the paused tasks
+func (s *Service) ResumeTask(ctx *rest.Contexts) {
+	input := new(metadata.OperateAPITaskOption)
+	if err := ctx.DecodeInto(input); err != nil {
+		ctx.RespAutoError(err)
+		return
+	}
+
+	if rawErr := input.Validate(); rawErr.ErrCode != 0 {
+		ctx.RespAutoError(rawErr.ToCCError(ctx.Kit.CCError))
+		return
+	}
+
+	if err := s.Logics.ResumeTask(ctx.Kit, input.TaskIDs); err != nil {
+		ctx.RespAutoError(err)
+		return
+	}
+
+	ctx.RespEntity(nil)
+}
+
+// UpdateTaskPriority update the priority of the unfinished tasks
+func (s *Service) UpdateTaskPriority(ctx *rest.Contexts) {
+	input := new(metadata.UpdateAPITaskPriorityOption)
+	if err := ctx.DecodeInto(input); err != nil {
+		ctx.RespAutoError(err)
+		return
+	}
+
+	if rawErr := input.Validate(); rawErr.ErrCode != 0 {
+		ctx.RespAutoError(rawErr.ToCCError(ctx.Kit.CCError))
+		return
+	}
+
+	if err := s.Logics.UpdateTaskPriority(ctx.Kit, input.TaskIDs, input.Priority); err != nil {
+		ctx.RespAutoError(err)
+		return
+	}
+
+	ctx.RespEntity(nil)
+}
+
+// WatchTaskProgress long poll the task progress and its failed subtask details
+func (s *Service) WatchTaskProgress(ctx *rest.Contexts) {
+	input := new(metadata.WatchAPITaskProgressOption)
+	if err := ctx.DecodeInto(input); err != nil {
+		ctx.RespAutoError(err)
+		return
+	}
+
+	if rawErr := input.Validate(); rawErr.ErrCode != 0 {
+		ctx.RespAutoError(rawErr.ToCCError(ctx.Kit.CCError))
+		return
+	}
+
+	result, err := s.Logics.WatchTaskProgress(ctx.Kit, input)
+	if err != nil {
+		ctx.RespAutoError(err)
+		return
+	}
+
+	ctx.RespEntity(result)
+}
+
 // ListLatestSyncStatus list latest api task sync status
 func (s *Service) ListLatestSyncStatus(ctx *rest.Contexts) {
 	input := new(metadata.ListLatestSyncStatusRequest)
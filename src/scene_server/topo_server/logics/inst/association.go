@@ -36,6 +36,11 @@ type AssociationOperationInterface interface {
 	DeleteInstAssociation(kit *rest.Kit, objID string, asstIDList []int64) (uint64, error)
 	// CheckAssociations returns error if the instances has associations with exist instances, clear dirty associations
 	CheckAssociations(*rest.Kit, string, []int64) error
+	// FindInstGraph traverses the instance associations and the mainline topology from the start instances
+	FindInstGraph(kit *rest.Kit, option *metadata.InstGraphQueryOption) (*metadata.InstGraphQueryResult, error)
+	// AnalyzeInstImpact returns the blast radius report of what depends on the instances
+	AnalyzeInstImpact(kit *rest.Kit, objID string, option *metadata.InstImpactOption) (*metadata.InstImpactResult,
+		error)
 
 	// SearchMainlineAssociationInstTopo search mainline association topo by objID and instID
 	SearchMainlineAssociationInstTopo(kit *rest.Kit, objID string, instID int64,
@@ -0,0 +1,413 @@
+/*
+ * Tencent is pleased to support the open source community by making 蓝鲸 available.
+ * Copyright (C) 2017-2018 THL A29 Limited, a Tencent company. All rights reserved.
+ * Licensed under the MIT License (the "License"); you may not use this file except
+ * in compliance with the License. You may obtain a copy of the License at
+ * http://opensource.org/licenses/MIT
+ * Unless required by applicable law or agreed to in writing, software distributed under
+ * the License is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND,
+ * either express or implied. See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package inst
+
+import (
+	"configcenter/src/ac/meta"
+	"configcenter/src/common"
+	"configcenter/src/common/auditlog"
+	"configcenter/src/common/blog"
+	"configcenter/src/common/http/rest"
+	"configcenter/src/common/mapstr"
+	"configcenter/src/common/metadata"
+	"configcenter/src/common/util"
+)
+
+// cascadeDeletion is the instances that will be deleted in cascade because of the association on delete actions.
+// if an instance is deleted, the destination instances of the associations whose on delete action is delete_dest
+// and the source instances of the associations whose on delete action is delete_src are deleted too, and so on.
+type cascadeDeletion struct {
+	// tree are the root nodes of the cascade deletion tree, which are the directly deleted instances
+	tree []*metadata.InstCascadeNode
+	// nodes are all the nodes in the tree, object id => instance id => node
+	nodes map[string]map[int64]*metadata.InstCascadeNode
+	// count is the number of all the nodes in the tree
+	count int
+	// insts are the instances deleted in cascade grouped by object id, the directly deleted ones are not included
+	insts map[string][]mapstr.MapStr
+	// assts are all the instance associations of the instances in the tree, association id => association
+	assts map[int64]metadata.InstAsst
+}
+
+func (d *cascadeDeletion) getNode(objID string, instID int64) *metadata.InstCascadeNode {
+	return d.nodes[objID][instID]
+}
+
+// addNode adds the node to the tree, returns false if the instance already exists in the tree to avoid cycles
+func (d *cascadeDeletion) addNode(node *metadata.InstCascadeNode) bool {
+	if d.getNode(node.ObjectID, node.InstID) != nil {
+		return false
+	}
+
+	if _, exists := d.nodes[node.ObjectID]; !exists {
+		d.nodes[node.ObjectID] = make(map[int64]*metadata.InstCascadeNode)
+	}
+	d.nodes[node.ObjectID][node.InstID] = node
+	d.count++
+	return true
+}
+
+// cascadeCandidate is an instance that may be deleted in cascade, it is added to the tree if it exists
+type cascadeCandidate struct {
+	parent   *metadata.InstCascadeNode
+	asst     metadata.InstAsst
+	onDelete metadata.AssociationOnDeleteAction
+	instID   int64
+}
+
+// PreviewCascadeDelete returns the cascade deletion tree of the instances, which shows all the instances that will
+// be deleted in cascade if these instances are deleted.
+func (c *commonInst) PreviewCascadeDelete(kit *rest.Kit, objID string, instIDs []int64) (
+	*metadata.InstCascadeDeletePreviewResult, error) {
+
+	cond := mapstr.MapStr{common.GetInstIDField(objID): mapstr.MapStr{common.BKDBIN: instIDs}}
+	if metadata.IsCommon(objID) {
+		cond[common.BKObjIDField] = objID
+	}
+
+	instRsp, err := c.FindInst(kit, objID, &metadata.QueryCondition{Condition: cond,
+		Page: metadata.BasePage{Limit: common.BKNoLimit}})
+	if err != nil {
+		return nil, err
+	}
+
+	cascade, err := c.buildCascadeDeletion(kit, objID, instRsp.Info)
+	if err != nil {
+		return nil, err
+	}
+
+	return &metadata.InstCascadeDeletePreviewResult{Count: cascade.count, Tree: cascade.tree}, nil
+}
+
+// buildCascadeDeletion builds the cascade deletion tree of the instances level by level, an instance is only added
+// once to avoid cycles, and error is returned if the tree is deeper than the max depth.
+func (c *commonInst) buildCascadeDeletion(kit *rest.Kit, objID string, insts []mapstr.MapStr) (*cascadeDeletion,
+	error) {
+
+	cascade := &cascadeDeletion{
+		tree:  make([]*metadata.InstCascadeNode, 0),
+		nodes: make(map[string]map[int64]*metadata.InstCascadeNode),
+		insts: make(map[string][]mapstr.MapStr),
+		assts: make(map[int64]metadata.InstAsst),
+	}
+
+	level := make(map[string][]int64)
+	for _, inst := range insts {
+		node, err := newCascadeNode(kit, objID, inst)
+		if err != nil {
+			return nil, err
+		}
+
+		if cascade.addNode(node) {
+			cascade.tree = append(cascade.tree, node)
+			level[objID] = append(level[objID], node.InstID)
+		}
+	}
+
+	if len(level) == 0 {
+		return cascade, nil
+	}
+
+	excludedObjs, err := c.getCascadeExcludedObjects(kit)
+	if err != nil {
+		return nil, err
+	}
+
+	onDeleteMap := make(map[string]metadata.AssociationOnDeleteAction)
+	for depth := 0; len(level) > 0; depth++ {
+		candidates, err := c.getCascadeCandidates(kit, cascade, level, onDeleteMap, excludedObjs)
+		if err != nil {
+			return nil, err
+		}
+
+		if len(candidates) == 0 {
+			break
+		}
+
+		if depth >= metadata.InstCascadeDeleteMaxDepth {
+			blog.Errorf("cascade delete %s instances exceeds max depth %d, rid: %s", objID,
+				metadata.InstCascadeDeleteMaxDepth, kit.Rid)
+			return nil, kit.CCError.CCErrorf(common.CCErrCommXXExceedLimit, "cascade delete depth",
+				metadata.InstCascadeDeleteMaxDepth)
+		}
+
+		level, err = c.addCascadeNodes(kit, cascade, candidates)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return cascade, nil
+}
+
+func newCascadeNode(kit *rest.Kit, objID string, inst mapstr.MapStr) (*metadata.InstCascadeNode, error) {
+	instID, err := inst.Int64(common.GetInstIDField(objID))
+	if err != nil {
+		blog.Errorf("can not convert ID to int64, err: %v, inst: %#v, rid: %s", err, inst, kit.Rid)
+		return nil, kit.CCError.CCErrorf(common.CCErrCommParamsNeedInt, common.GetInstIDField(objID))
+	}
+
+	return &metadata.InstCascadeNode{
+		ObjectID: objID,
+		InstID:   instID,
+		InstName: util.GetStrByInterface(inst[metadata.GetInstNameFieldName(objID)]),
+		Children: make([]*metadata.InstCascadeNode, 0),
+	}, nil
+}
+
+// getCascadeExcludedObjects returns the mainline objects, their instances are deleted with the topology rules,
+// so they are never deleted in cascade, and neither are the inner objects' instances.
+func (c *commonInst) getCascadeExcludedObjects(kit *rest.Kit) (map[string]struct{}, error) {
+	cond := &metadata.QueryCondition{
+		Condition:      mapstr.MapStr{common.AssociationKindIDField: common.AssociationKindMainline},
+		DisableCounter: true,
+	}
+	rsp, err := c.clientSet.CoreService().Association().ReadModelAssociation(kit.Ctx, kit.Header, cond)
+	if err != nil {
+		blog.Errorf("search mainline object associations failed, err: %v, rid: %s", err, kit.Rid)
+		return nil, err
+	}
+
+	excludedObjs := make(map[string]struct{})
+	for _, asst := range rsp.Info {
+		excludedObjs[asst.ObjectID] = struct{}{}
+		excludedObjs[asst.AsstObjID] = struct{}{}
+	}
+	return excludedObjs, nil
+}
+
+// getCascadeCandidates returns the instances that are associated with the instances in this level by the
+// associations whose on delete action points to them, the candidates are grouped by object id.
+func (c *commonInst) getCascadeCandidates(kit *rest.Kit, cascade *cascadeDeletion, level map[string][]int64,
+	onDeleteMap map[string]metadata.AssociationOnDeleteAction, excludedObjs map[string]struct{}) (
+	map[string][]cascadeCandidate, error) {
+
+	candidates := make(map[string][]cascadeCandidate)
+	candidateExists := make(map[string]map[int64]struct{})
+
+	for objID, instIDs := range level {
+		cond := &metadata.InstAsstQueryCondition{
+			Cond: metadata.QueryCondition{
+				Condition: mapstr.MapStr{common.BKDBOR: []mapstr.MapStr{
+					{common.BKObjIDField: objID, common.BKInstIDField: mapstr.MapStr{common.BKDBIN: instIDs}},
+					{common.BKAsstObjIDField: objID, common.BKAsstInstIDField: mapstr.MapStr{common.BKDBIN: instIDs}},
+				}},
+				DisableCounter: true,
+			},
+			ObjID: objID,
+		}
+		assts, err := c.clientSet.CoreService().Association().ReadInstAssociation(kit.Ctx, kit.Header, cond)
+		if err != nil {
+			blog.Errorf("search instance associations failed, cond: %#v, err: %v, rid: %s", cond, err, kit.Rid)
+			return nil, err
+		}
+
+		if err := c.getAssociationOnDeleteActions(kit, assts.Info, onDeleteMap); err != nil {
+			return nil, err
+		}
+
+		for _, asst := range assts.Info {
+			cascade.assts[asst.ID] = asst
+
+			candidate := cascadeCandidate{asst: asst, onDelete: onDeleteMap[asst.ObjectAsstID]}
+			var targetObjID string
+			switch candidate.onDelete {
+			case metadata.DeleteDestinatioin:
+				candidate.parent = cascade.getNode(asst.ObjectID, asst.InstID)
+				targetObjID, candidate.instID = asst.AsstObjectID, asst.AsstInstID
+			case metadata.DeleteSource:
+				candidate.parent = cascade.getNode(asst.AsstObjectID, asst.AsstInstID)
+				targetObjID, candidate.instID = asst.ObjectID, asst.InstID
+			default:
+				continue
+			}
+
+			if candidate.parent == nil || cascade.getNode(targetObjID, candidate.instID) != nil {
+				continue
+			}
+
+			if _, excluded := excludedObjs[targetObjID]; excluded || common.IsInnerModel(targetObjID) {
+				continue
+			}
+
+			if _, exists := candidateExists[targetObjID][candidate.instID]; exists {
+				continue
+			}
+
+			if _, exists := candidateExists[targetObjID]; !exists {
+				candidateExists[targetObjID] = make(map[int64]struct{})
+			}
+			candidateExists[targetObjID][candidate.instID] = struct{}{}
+			candidates[targetObjID] = append(candidates[targetObjID], candidate)
+		}
+	}
+
+	return candidates, nil
+}
+
+// getAssociationOnDeleteActions gets the on delete actions of the object associations that are not in the map yet
+func (c *commonInst) getAssociationOnDeleteActions(kit *rest.Kit, assts []metadata.InstAsst,
+	onDeleteMap map[string]metadata.AssociationOnDeleteAction) error {
+
+	objAsstIDs := make([]string, 0)
+	for _, asst := range assts {
+		if _, exists := onDeleteMap[asst.ObjectAsstID]; !exists {
+			objAsstIDs = append(objAsstIDs, asst.ObjectAsstID)
+			onDeleteMap[asst.ObjectAsstID] = metadata.NoAction
+		}
+	}
+
+	if len(objAsstIDs) == 0 {
+		return nil
+	}
+
+	cond := &metadata.QueryCondition{
+		Condition:      mapstr.MapStr{common.AssociationObjAsstIDField: mapstr.MapStr{common.BKDBIN: objAsstIDs}},
+		DisableCounter: true,
+	}
+	rsp, err := c.clientSet.CoreService().Association().ReadModelAssociation(kit.Ctx, kit.Header, cond)
+	if err != nil {
+		blog.Errorf("search object associations failed, ids: %v, err: %v, rid: %s", objAsstIDs, err, kit.Rid)
+		return err
+	}
+
+	for _, asst := range rsp.Info {
+		onDeleteMap[asst.AssociationName] = asst.OnDelete
+	}
+	return nil
+}
+
+// addCascadeNodes adds the existing candidate instances to the tree, returns them as the next level
+func (c *commonInst) addCascadeNodes(kit *rest.Kit, cascade *cascadeDeletion,
+	candidates map[string][]cascadeCandidate) (map[string][]int64, error) {
+
+	nextLevel := make(map[string][]int64)
+	for objID, objCandidates := range candidates {
+		instIDs := make([]int64, len(objCandidates))
+		for index, candidate := range objCandidates {
+			instIDs[index] = candidate.instID
+		}
+
+		cond := mapstr.MapStr{common.GetInstIDField(objID): mapstr.MapStr{common.BKDBIN: instIDs}}
+		if metadata.IsCommon(objID) {
+			cond[common.BKObjIDField] = objID
+		}
+		instRsp, err := c.FindInst(kit, objID, &metadata.QueryCondition{Condition: cond,
+			Page: metadata.BasePage{Limit: common.BKNoLimit}})
+		if err != nil {
+			return nil, err
+		}
+
+		instMap := make(map[int64]mapstr.MapStr)
+		for _, inst := range instRsp.Info {
+			instID, err := inst.Int64(common.GetInstIDField(objID))
+			if err != nil {
+				blog.Errorf("can not convert ID to int64, err: %v, inst: %#v, rid: %s", err, inst, kit.Rid)
+				return nil, kit.CCError.CCErrorf(common.CCErrCommParamsNeedInt, common.GetInstIDField(objID))
+			}
+			instMap[instID] = inst
+		}
+
+		// the instances that do not exist are dirty associations, they are cleared by the association check
+		for _, candidate := range objCandidates {
+			inst, exists := instMap[candidate.instID]
+			if !exists {
+				continue
+			}
+
+			node, err := newCascadeNode(kit, objID, inst)
+			if err != nil {
+				return nil, err
+			}
+			node.AsstID = candidate.asst.ID
+			node.ObjectAsstID = candidate.asst.ObjectAsstID
+			node.OnDelete = candidate.onDelete
+
+			if !cascade.addNode(node) {
+				continue
+			}
+			candidate.parent.Children = append(candidate.parent.Children, node)
+			cascade.insts[objID] = append(cascade.insts[objID], inst)
+			nextLevel[objID] = append(nextLevel[objID], node.InstID)
+		}
+	}
+
+	return nextLevel, nil
+}
+
+// prepareCascadeDeletion authorizes the deletion of the instances deleted in cascade, then deletes the associations
+// between the instances in the tree, so that they can pass the association check when they are deleted.
+func (c *commonInst) prepareCascadeDeletion(kit *rest.Kit, cascade *cascadeDeletion) error {
+	if len(cascade.insts) == 0 {
+		return nil
+	}
+
+	if c.authManager != nil {
+		for objID := range cascade.insts {
+			instIDs := make([]int64, 0)
+			for instID := range cascade.nodes[objID] {
+				if cascade.getNode(objID, instID).AsstID != 0 {
+					instIDs = append(instIDs, instID)
+				}
+			}
+
+			if err := c.authManager.AuthorizeByInstanceID(kit.Ctx, kit.Header, meta.Delete, objID,
+				instIDs...); err != nil {
+				blog.Errorf("authorize cascade delete %s instances %v failed, err: %v, rid: %s", objID, instIDs,
+					err, kit.Rid)
+				return kit.CCError.CCError(common.CCErrCommAuthNotHavePermission)
+			}
+		}
+	}
+
+	innerAsstIDs := make(map[string][]int64)
+	for _, asst := range cascade.assts {
+		if cascade.getNode(asst.ObjectID, asst.InstID) == nil ||
+			cascade.getNode(asst.AsstObjectID, asst.AsstInstID) == nil {
+			continue
+		}
+		innerAsstIDs[asst.ObjectID] = append(innerAsstIDs[asst.ObjectID], asst.ID)
+	}
+
+	for objID, asstIDs := range innerAsstIDs {
+		if _, err := c.asst.DeleteInstAssociation(kit, objID, asstIDs); err != nil {
+			blog.Errorf("delete %s cascade associations %v failed, err: %v, rid: %s", objID, asstIDs, err, kit.Rid)
+			return err
+		}
+	}
+
+	return nil
+}
+
+// deleteCascadeInsts deletes the instances in cascade, one audit log is saved for each of them
+func (c *commonInst) deleteCascadeInsts(kit *rest.Kit, cascade *cascadeDeletion) ([]metadata.AuditLog, error) {
+	audit := auditlog.NewInstanceAudit(c.clientSet.CoreService())
+	auditLogs := make([]metadata.AuditLog, 0)
+
+	for objID, insts := range cascade.insts {
+		auditParam := auditlog.NewGenerateAuditCommonParameter(kit, metadata.AuditDelete).
+			WithOperateFrom(metadata.FromCascadeDelete)
+		objAuditLogs, err := audit.GenerateAuditLog(auditParam, objID, insts)
+		if err != nil {
+			blog.Errorf("generate cascade delete audit log failed, err: %v, rid: %s", err, kit.Rid)
+			return nil, err
+		}
+		auditLogs = append(auditLogs, objAuditLogs...)
+
+		if err := c.deleteInsts(kit, insts, objID); err != nil {
+			return nil, err
+		}
+	}
+
+	return auditLogs, nil
+}
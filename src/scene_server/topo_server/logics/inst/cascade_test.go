@@ -0,0 +1,375 @@
+/*
+ * Tencent is pleased to support the open source community by making 蓝鲸 available.
+ * Copyright (C) 2017-2018 THL A29 Limited, a Tencent company. All rights reserved.
+ * Licensed under the MIT License (the "License"); you may not use this file except
+ * in compliance with the License. You may obtain a copy of the License at
+ * http://opensource.org/licenses/MIT
+ * Unless required by applicable law or agreed to in writing, software distributed under
+ * the License is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND,
+ * either express or implied. See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package inst
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"sort"
+	"strings"
+	"testing"
+
+	"configcenter/src/apimachinery"
+	"configcenter/src/apimachinery/coreservice"
+	asstapi "configcenter/src/apimachinery/coreservice/association"
+	instapi "configcenter/src/apimachinery/coreservice/instance"
+	"configcenter/src/common"
+	"configcenter/src/common/errors"
+	"configcenter/src/common/http/rest"
+	"configcenter/src/common/mapstr"
+	"configcenter/src/common/metadata"
+)
+
+// fakeCascadeClientSet is a client set whose core service returns the preset instances and associations
+type fakeCascadeClientSet struct {
+	apimachinery.ClientSetInterface
+	coreService *fakeCascadeCoreService
+}
+
+// CoreService returns the fake core service
+func (f *fakeCascadeClientSet) CoreService() coreservice.CoreServiceClientInterface {
+	return f.coreService
+}
+
+type fakeCascadeCoreService struct {
+	coreservice.CoreServiceClientInterface
+	// insts are the existing instance ids, object id => instance id => exists
+	insts map[string]map[int64]bool
+	// objAssts are the object associations, including the mainline ones
+	objAssts []metadata.Association
+	// instAssts are the instance associations
+	instAssts []metadata.InstAsst
+}
+
+// Instance returns the fake instance client
+func (f *fakeCascadeCoreService) Instance() instapi.InstanceClientInterface {
+	return &fakeCascadeInstance{coreService: f}
+}
+
+// Association returns the fake association client
+func (f *fakeCascadeCoreService) Association() asstapi.AssociationClientInterface {
+	return &fakeCascadeAssociation{coreService: f}
+}
+
+type fakeCascadeInstance struct {
+	instapi.InstanceClientInterface
+	coreService *fakeCascadeCoreService
+}
+
+// ReadInstance returns the existing instances whose ids are in the condition
+func (f *fakeCascadeInstance) ReadInstance(ctx context.Context, h http.Header, objID string,
+	input *metadata.QueryCondition) (*metadata.InstDataInfo, error) {
+
+	result := &metadata.InstDataInfo{Info: make([]mapstr.MapStr, 0)}
+	instIDs := input.Condition[common.BKInstIDField].(mapstr.MapStr)[common.BKDBIN].([]int64)
+	for _, instID := range instIDs {
+		if f.coreService.insts[objID][instID] {
+			result.Info = append(result.Info, mapstr.MapStr{
+				common.BKObjIDField:    objID,
+				common.BKInstIDField:   instID,
+				common.BKInstNameField: fmt.Sprintf("%s%d", objID, instID),
+			})
+		}
+	}
+	result.Count = len(result.Info)
+	return result, nil
+}
+
+type fakeCascadeAssociation struct {
+	asstapi.AssociationClientInterface
+	coreService *fakeCascadeCoreService
+}
+
+// ReadModelAssociation returns the mainline associations or the associations whose ids are in the condition
+func (f *fakeCascadeAssociation) ReadModelAssociation(ctx context.Context, h http.Header,
+	input *metadata.QueryCondition) (*metadata.QueryModelAssociationResult, error) {
+
+	result := &metadata.QueryModelAssociationResult{Info: make([]metadata.Association, 0)}
+	for _, asst := range f.coreService.objAssts {
+		if input.Condition[common.AssociationKindIDField] == common.AssociationKindMainline {
+			if asst.AsstKindID == common.AssociationKindMainline {
+				result.Info = append(result.Info, asst)
+			}
+			continue
+		}
+
+		objAsstIDs := input.Condition[common.AssociationObjAsstIDField].(mapstr.MapStr)[common.BKDBIN].([]string)
+		for _, objAsstID := range objAsstIDs {
+			if asst.AssociationName == objAsstID {
+				result.Info = append(result.Info, asst)
+			}
+		}
+	}
+	return result, nil
+}
+
+// ReadInstAssociation returns the instance associations of the instances in the condition
+func (f *fakeCascadeAssociation) ReadInstAssociation(ctx context.Context, h http.Header,
+	input *metadata.InstAsstQueryCondition) (*metadata.QueryInstAssociationResult, error) {
+
+	orCond := input.Cond.Condition[common.BKDBOR].([]mapstr.MapStr)
+	instIDs := orCond[0][common.BKInstIDField].(mapstr.MapStr)[common.BKDBIN].([]int64)
+	instIDMap := make(map[int64]struct{})
+	for _, instID := range instIDs {
+		instIDMap[instID] = struct{}{}
+	}
+
+	result := &metadata.QueryInstAssociationResult{Info: make([]metadata.InstAsst, 0)}
+	for _, asst := range f.coreService.instAssts {
+		_, srcMatched := instIDMap[asst.InstID]
+		_, destMatched := instIDMap[asst.AsstInstID]
+		if (asst.ObjectID == input.ObjID && srcMatched) || (asst.AsstObjectID == input.ObjID && destMatched) {
+			result.Info = append(result.Info, asst)
+		}
+	}
+	return result, nil
+}
+
+func newFakeCascadeInst(coreService *fakeCascadeCoreService) *commonInst {
+	return &commonInst{clientSet: &fakeCascadeClientSet{coreService: coreService}}
+}
+
+// newCascadeInstAsst returns an instance association, the object association id is "$src_$dest"
+func newCascadeInstAsst(id int64, objID string, instID int64, asstObjID string, asstInstID int64) metadata.InstAsst {
+	return metadata.InstAsst{ID: id, ObjectID: objID, InstID: instID, AsstObjectID: asstObjID,
+		AsstInstID: asstInstID, ObjectAsstID: objID + "_" + asstObjID}
+}
+
+func newCascadeObjAsst(objID, asstObjID string, onDelete metadata.AssociationOnDeleteAction) metadata.Association {
+	return metadata.Association{AssociationName: objID + "_" + asstObjID, ObjectID: objID, AsstObjID: asstObjID,
+		AsstKindID: "connect", OnDelete: onDelete}
+}
+
+// formatCascadeTree formats the cascade deletion tree like "a1(b1,b2(c1))", the children are sorted by name
+func formatCascadeTree(nodes []*metadata.InstCascadeNode) string {
+	names := make([]string, len(nodes))
+	for index, node := range nodes {
+		names[index] = node.InstName
+		if len(node.Children) > 0 {
+			names[index] += "(" + formatCascadeTree(node.Children) + ")"
+		}
+	}
+	sort.Strings(names)
+	return strings.Join(names, ",")
+}
+
+func TestBuildCascadeDeletion(t *testing.T) {
+	kit := &rest.Kit{Ctx: context.Background(), Header: make(http.Header), Rid: "test_rid",
+		CCError: errors.NewFromCtx(errors.EmptyErrorsSetting).CreateDefaultCCErrorIf("zh-cn")}
+
+	// a chain of instances in which each one deletes the next one, the root is deleted directly
+	newChain := func(length int) (map[string][]int64, []metadata.InstAsst) {
+		insts := map[string][]int64{"a": {1}}
+		instAssts := make([]metadata.InstAsst, 0)
+		for i := 2; i <= length; i++ {
+			insts["a"] = append(insts["a"], int64(i))
+			instAssts = append(instAssts, newCascadeInstAsst(int64(i), "a", int64(i-1), "a", int64(i)))
+		}
+		return insts, instAssts
+	}
+
+	maxDepthInsts, maxDepthAssts := newChain(metadata.InstCascadeDeleteMaxDepth + 1)
+	exceedInsts, exceedAssts := newChain(metadata.InstCascadeDeleteMaxDepth + 2)
+	chainObjAssts := []metadata.Association{newCascadeObjAsst("a", "a", metadata.DeleteDestinatioin)}
+	tests := []struct {
+		name      string
+		insts     map[string][]int64
+		objAssts  []metadata.Association
+		instAssts []metadata.InstAsst
+		deleteIDs []int64
+		wantTree  string
+		wantCount int
+		wantErr   bool
+	}{
+		{
+			name:      "delete dest",
+			insts:     map[string][]int64{"a": {1}, "b": {1, 2}, "c": {1}},
+			objAssts:  []metadata.Association{newCascadeObjAsst("a", "b", metadata.DeleteDestinatioin)},
+			instAssts: []metadata.InstAsst{newCascadeInstAsst(1, "a", 1, "b", 1), newCascadeInstAsst(2, "a", 1, "b", 2)},
+			deleteIDs: []int64{1},
+			wantTree:  "a1(b1,b2)",
+			wantCount: 3,
+		},
+		{
+			name:  "delete dest does not delete src",
+			insts: map[string][]int64{"a": {1}, "b": {1}},
+			objAssts: []metadata.Association{newCascadeObjAsst("a", "b", metadata.DeleteDestinatioin),
+				newCascadeObjAsst("b", "a", metadata.DeleteDestinatioin)},
+			instAssts: []metadata.InstAsst{newCascadeInstAsst(1, "b", 1, "a", 1)},
+			deleteIDs: []int64{1},
+			wantTree:  "a1",
+			wantCount: 1,
+		},
+		{
+			name:      "delete src",
+			insts:     map[string][]int64{"a": {1}, "b": {1}},
+			objAssts:  []metadata.Association{newCascadeObjAsst("b", "a", metadata.DeleteSource)},
+			instAssts: []metadata.InstAsst{newCascadeInstAsst(1, "b", 1, "a", 1)},
+			deleteIDs: []int64{1},
+			wantTree:  "a1(b1)",
+			wantCount: 2,
+		},
+		{
+			name:      "delete src does not delete dest",
+			insts:     map[string][]int64{"a": {1}, "b": {1}},
+			objAssts:  []metadata.Association{newCascadeObjAsst("a", "b", metadata.DeleteSource)},
+			instAssts: []metadata.InstAsst{newCascadeInstAsst(1, "a", 1, "b", 1)},
+			deleteIDs: []int64{1},
+			wantTree:  "a1",
+			wantCount: 1,
+		},
+		{
+			name:      "no action",
+			insts:     map[string][]int64{"a": {1}, "b": {1}},
+			objAssts:  []metadata.Association{newCascadeObjAsst("a", "b", metadata.NoAction)},
+			instAssts: []metadata.InstAsst{newCascadeInstAsst(1, "a", 1, "b", 1)},
+			deleteIDs: []int64{1},
+			wantTree:  "a1",
+			wantCount: 1,
+		},
+		{
+			name:  "multiple levels with delete src and delete dest",
+			insts: map[string][]int64{"a": {1}, "b": {1}, "c": {1}},
+			objAssts: []metadata.Association{newCascadeObjAsst("a", "b", metadata.DeleteDestinatioin),
+				newCascadeObjAsst("c", "b", metadata.DeleteSource)},
+			instAssts: []metadata.InstAsst{newCascadeInstAsst(1, "a", 1, "b", 1),
+				newCascadeInstAsst(2, "c", 1, "b", 1)},
+			deleteIDs: []int64{1},
+			wantTree:  "a1(b1(c1))",
+			wantCount: 3,
+		},
+		{
+			name:  "cycle",
+			insts: map[string][]int64{"a": {1}, "b": {1}, "c": {1}},
+			objAssts: []metadata.Association{newCascadeObjAsst("a", "b", metadata.DeleteDestinatioin),
+				newCascadeObjAsst("b", "c", metadata.DeleteDestinatioin),
+				newCascadeObjAsst("c", "a", metadata.DeleteDestinatioin)},
+			instAssts: []metadata.InstAsst{newCascadeInstAsst(1, "a", 1, "b", 1),
+				newCascadeInstAsst(2, "b", 1, "c", 1), newCascadeInstAsst(3, "c", 1, "a", 1)},
+			deleteIDs: []int64{1},
+			wantTree:  "a1(b1(c1))",
+			wantCount: 3,
+		},
+		{
+			name:      "self association cycle",
+			insts:     map[string][]int64{"a": {1, 2}},
+			objAssts:  []metadata.Association{newCascadeObjAsst("a", "a", metadata.DeleteDestinatioin)},
+			instAssts: []metadata.InstAsst{newCascadeInstAsst(1, "a", 1, "a", 2), newCascadeInstAsst(2, "a", 2, "a", 1)},
+			deleteIDs: []int64{1},
+			wantTree:  "a1(a2)",
+			wantCount: 2,
+		},
+		{
+			name:      "instance reached by multiple paths is added once",
+			insts:     map[string][]int64{"a": {1, 2}, "b": {1}},
+			objAssts:  []metadata.Association{newCascadeObjAsst("a", "b", metadata.DeleteDestinatioin)},
+			instAssts: []metadata.InstAsst{newCascadeInstAsst(1, "a", 1, "b", 1), newCascadeInstAsst(2, "a", 2, "b", 1)},
+			deleteIDs: []int64{1, 2},
+			wantTree:  "a1(b1),a2",
+			wantCount: 3,
+		},
+		{
+			name:  "mainline and inner objects are excluded",
+			insts: map[string][]int64{"a": {1}, "set": {1}, common.BKInnerObjIDHost: {1}},
+			objAssts: []metadata.Association{newCascadeObjAsst("a", "set", metadata.DeleteDestinatioin),
+				newCascadeObjAsst("a", common.BKInnerObjIDHost, metadata.DeleteDestinatioin),
+				{ObjectID: "set", AsstObjID: common.BKInnerObjIDModule, AsstKindID: common.AssociationKindMainline}},
+			instAssts: []metadata.InstAsst{newCascadeInstAsst(1, "a", 1, "set", 1),
+				newCascadeInstAsst(2, "a", 1, common.BKInnerObjIDHost, 1)},
+			deleteIDs: []int64{1},
+			wantTree:  "a1",
+			wantCount: 1,
+		},
+		{
+			name:      "dirty association is skipped",
+			insts:     map[string][]int64{"a": {1}, "b": {1}},
+			objAssts:  []metadata.Association{newCascadeObjAsst("a", "b", metadata.DeleteDestinatioin)},
+			instAssts: []metadata.InstAsst{newCascadeInstAsst(1, "a", 1, "b", 1), newCascadeInstAsst(2, "a", 1, "b", 2)},
+			deleteIDs: []int64{1},
+			wantTree:  "a1(b1)",
+			wantCount: 2,
+		},
+		{
+			name:      "no deleted instances",
+			insts:     map[string][]int64{"a": {1}},
+			deleteIDs: []int64{2},
+			wantCount: 0,
+		},
+		{
+			name:      "max depth",
+			insts:     maxDepthInsts,
+			objAssts:  chainObjAssts,
+			instAssts: maxDepthAssts,
+			deleteIDs: []int64{1},
+			wantTree:  "a1(a2(a3(a4(a5(a6(a7(a8(a9(a10(a11))))))))))",
+			wantCount: metadata.InstCascadeDeleteMaxDepth + 1,
+		},
+		{
+			name:      "exceed max depth",
+			insts:     exceedInsts,
+			objAssts:  chainObjAssts,
+			instAssts: exceedAssts,
+			deleteIDs: []int64{1},
+			wantErr:   true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			coreService := &fakeCascadeCoreService{
+				insts:     make(map[string]map[int64]bool),
+				objAssts:  tt.objAssts,
+				instAssts: tt.instAssts,
+			}
+			for objID, instIDs := range tt.insts {
+				coreService.insts[objID] = make(map[int64]bool)
+				for _, instID := range instIDs {
+					coreService.insts[objID][instID] = true
+				}
+			}
+
+			insts := make([]mapstr.MapStr, 0)
+			for _, instID := range tt.deleteIDs {
+				if coreService.insts["a"][instID] {
+					insts = append(insts, mapstr.MapStr{common.BKInstIDField: instID,
+						common.BKInstNameField: fmt.Sprintf("a%d", instID)})
+				}
+			}
+
+			cascade, err := newFakeCascadeInst(coreService).buildCascadeDeletion(kit, "a", insts)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("buildCascadeDeletion() err = %v, want err %v", err, tt.wantErr)
+			}
+			if tt.wantErr {
+				return
+			}
+
+			if got := formatCascadeTree(cascade.tree); got != tt.wantTree {
+				t.Errorf("buildCascadeDeletion() tree = %s, want %s", got, tt.wantTree)
+			}
+			if cascade.count != tt.wantCount {
+				t.Errorf("buildCascadeDeletion() count = %d, want %d", cascade.count, tt.wantCount)
+			}
+
+			cascadeCount := 0
+			for _, objInsts := range cascade.insts {
+				cascadeCount += len(objInsts)
+			}
+			if cascadeCount != tt.wantCount-len(cascade.tree) {
+				t.Errorf("buildCascadeDeletion() cascade instances = %d, want %d", cascadeCount,
+					tt.wantCount-len(cascade.tree))
+			}
+		})
+	}
+}
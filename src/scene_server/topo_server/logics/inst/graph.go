@@ -0,0 +1,631 @@
+/*
+ * Tencent is pleased to support the open source community by making 蓝鲸 available.
+ * Copyright (C) 2017-2018 THL A29 Limited, a Tencent company. All rights reserved.
+ * Licensed under the MIT License (the "License"); you may not use this file except
+ * in compliance with the License. You may obtain a copy of the License at
+ * http://opensource.org/licenses/MIT
+ * Unless required by applicable law or agreed to in writing, software distributed under
+ * the License is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND,
+ * either express or implied. See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package inst
+
+import (
+	"configcenter/src/common"
+	"configcenter/src/common/blog"
+	"configcenter/src/common/http/rest"
+	"configcenter/src/common/mapstr"
+	"configcenter/src/common/metadata"
+	"configcenter/src/common/util"
+)
+
+// instGraphKey is the key of an instance in the instance graph
+type instGraphKey struct {
+	objID  string
+	instID int64
+}
+
+// instGraphStep is an edge in the instance graph from an instance to another one
+type instGraphStep struct {
+	from instGraphKey
+	to   instGraphKey
+	edge metadata.InstGraphEdge
+}
+
+// instGraph is the traversed part of the graph made up of the instance associations and the mainline topology
+type instGraph struct {
+	nodes map[instGraphKey]*metadata.InstGraphNode
+	// nodeKeys are the keys of the nodes in the order they are reached
+	nodeKeys []instGraphKey
+	// prev is the step that a node is first reached by, they make up the shortest paths from the start instances
+	prev      map[instGraphKey]instGraphStep
+	edges     map[metadata.InstGraphEdge]struct{}
+	edgeList  []metadata.InstGraphEdge
+	truncated bool
+}
+
+func newInstGraph() *instGraph {
+	return &instGraph{
+		nodes:    make(map[instGraphKey]*metadata.InstGraphNode),
+		nodeKeys: make([]instGraphKey, 0),
+		prev:     make(map[instGraphKey]instGraphStep),
+		edges:    make(map[metadata.InstGraphEdge]struct{}),
+		edgeList: make([]metadata.InstGraphEdge, 0),
+	}
+}
+
+// addNode adds the node to the graph, returns false if the node already exists or the nodes exceed the limit
+func (g *instGraph) addNode(node *metadata.InstGraphNode) bool {
+	key := instGraphKey{objID: node.ObjectID, instID: node.InstID}
+	if _, exists := g.nodes[key]; exists {
+		return false
+	}
+
+	if len(g.nodes) >= metadata.InstGraphMaxNodes {
+		g.truncated = true
+		return false
+	}
+
+	g.nodes[key] = node
+	g.nodeKeys = append(g.nodeKeys, key)
+	return true
+}
+
+// addEdge adds the edge to the graph if it does not exist, marks the graph as truncated if edges exceed the limit
+func (g *instGraph) addEdge(edge metadata.InstGraphEdge) {
+	if _, exists := g.edges[edge]; exists {
+		return
+	}
+
+	if len(g.edgeList) >= metadata.InstGraphMaxEdges {
+		g.truncated = true
+		return
+	}
+
+	g.edges[edge] = struct{}{}
+	g.edgeList = append(g.edgeList, edge)
+}
+
+// getPath returns the shortest path from a start instance to the node
+func (g *instGraph) getPath(key instGraphKey) metadata.InstGraphPath {
+	nodes := []metadata.InstGraphNode{*g.nodes[key]}
+	edges := make([]metadata.InstGraphEdge, 0)
+	for step, exists := g.prev[key]; exists; step, exists = g.prev[key] {
+		key = step.from
+		nodes = append(nodes, *g.nodes[key])
+		edges = append(edges, step.edge)
+	}
+
+	// the path is built from the end node, reverse it to make it start from the start instance
+	for i, j := 0, len(nodes)-1; i < j; i, j = i+1, j-1 {
+		nodes[i], nodes[j] = nodes[j], nodes[i]
+	}
+	for i, j := 0, len(edges)-1; i < j; i, j = i+1, j-1 {
+		edges[i], edges[j] = edges[j], edges[i]
+	}
+	return metadata.InstGraphPath{Nodes: nodes, Edges: edges}
+}
+
+// getResult converts the traversed graph to the query result by the return mode
+func (g *instGraph) getResult(option *metadata.InstGraphQueryOption) *metadata.InstGraphQueryResult {
+	targets := make(map[string]struct{})
+	for _, objID := range option.TargetObjects {
+		targets[objID] = struct{}{}
+	}
+
+	// the start instances are not regarded as reached targets
+	targetKeys := make([]instGraphKey, 0)
+	for _, key := range g.nodeKeys {
+		if g.nodes[key].Depth == 0 {
+			continue
+		}
+		if _, exists := targets[key.objID]; len(targets) == 0 || exists {
+			targetKeys = append(targetKeys, key)
+		}
+	}
+
+	result := &metadata.InstGraphQueryResult{Truncated: g.truncated}
+	switch option.ReturnMode {
+	case metadata.InstGraphReturnNodes:
+		result.Nodes = make([]metadata.InstGraphNode, len(targetKeys))
+		for index, key := range targetKeys {
+			result.Nodes[index] = *g.nodes[key]
+		}
+
+	case metadata.InstGraphReturnPaths:
+		result.Paths = make([]metadata.InstGraphPath, len(targetKeys))
+		for index, key := range targetKeys {
+			result.Paths[index] = g.getPath(key)
+		}
+
+	case metadata.InstGraphReturnSubgraph:
+		if len(targets) == 0 {
+			result.Nodes = make([]metadata.InstGraphNode, len(g.nodeKeys))
+			for index, key := range g.nodeKeys {
+				result.Nodes[index] = *g.nodes[key]
+			}
+			result.Edges = g.edgeList
+			return result
+		}
+
+		nodeExists := make(map[instGraphKey]struct{})
+		edgeExists := make(map[metadata.InstGraphEdge]struct{})
+		result.Nodes = make([]metadata.InstGraphNode, 0)
+		result.Edges = make([]metadata.InstGraphEdge, 0)
+		for _, key := range targetKeys {
+			path := g.getPath(key)
+			for _, node := range path.Nodes {
+				nodeKey := instGraphKey{objID: node.ObjectID, instID: node.InstID}
+				if _, exists := nodeExists[nodeKey]; !exists {
+					nodeExists[nodeKey] = struct{}{}
+					result.Nodes = append(result.Nodes, node)
+				}
+			}
+			for _, edge := range path.Edges {
+				if _, exists := edgeExists[edge]; !exists {
+					edgeExists[edge] = struct{}{}
+					result.Edges = append(result.Edges, edge)
+				}
+			}
+		}
+	}
+
+	return result
+}
+
+// instGraphMainline is the mainline topology of the objects
+type instGraphMainline struct {
+	// parents is child object id => the mainline association to its parent object
+	parents map[string]metadata.Association
+	// children is parent object id => the mainline association to its child object
+	children map[string]metadata.Association
+}
+
+// FindInstGraph traverses the graph made up of the instance associations and the mainline topology level by level
+// from the start instances, the instances in a level are looked up in batches, and the traversal stops when the
+// nodes or edges exceed the limit.
+func (assoc *association) FindInstGraph(kit *rest.Kit, option *metadata.InstGraphQueryOption) (
+	*metadata.InstGraphQueryResult, error) {
+
+	cond := mapstr.New()
+	if option.Start.Conditions != nil && option.Start.Conditions.Rule != nil {
+		filter, key, err := option.Start.Conditions.ToMgo()
+		if err != nil {
+			blog.Errorf("parse start conditions failed, key: %s, err: %v, rid: %s", key, err, kit.Rid)
+			return nil, kit.CCError.CCErrorf(common.CCErrCommParamsInvalid, "start.conditions."+key)
+		}
+		cond = filter
+	}
+
+	graph, err := assoc.traverseInstGraph(kit, option.Start.ObjectID, cond, option.GetHop, option.MaxDepth)
+	if err != nil {
+		return nil, err
+	}
+
+	return graph.getResult(option), nil
+}
+
+// traverseInstGraph traverses the instance graph from the start instances of the object that match the condition,
+// getHop returns the associations allowed in the hop at the depth.
+func (assoc *association) traverseInstGraph(kit *rest.Kit, objID string, cond mapstr.MapStr,
+	getHop func(depth int) metadata.InstGraphHop, maxDepth int) (*instGraph, error) {
+
+	graph := newInstGraph()
+	level, err := assoc.getInstGraphStart(kit, graph, objID, cond)
+	if err != nil {
+		return nil, err
+	}
+
+	mainline, err := assoc.getInstGraphMainline(kit)
+	if err != nil {
+		return nil, err
+	}
+
+	for depth := 0; depth < maxDepth && len(level) > 0 && !graph.truncated; depth++ {
+		steps, err := assoc.getInstGraphSteps(kit, graph, level, getHop(depth), mainline)
+		if err != nil {
+			return nil, err
+		}
+
+		level, err = assoc.addInstGraphSteps(kit, graph, steps, depth+1)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return graph, nil
+}
+
+// getInstGraphStart adds the start instances to the graph, returns their ids grouped by object id as the first level
+func (assoc *association) getInstGraphStart(kit *rest.Kit, graph *instGraph, objID string, cond mapstr.MapStr) (
+	map[string][]int64, error) {
+
+	if metadata.IsCommon(objID) {
+		cond[common.BKObjIDField] = objID
+	}
+
+	instRsp, err := assoc.inst.FindInst(kit, objID, &metadata.QueryCondition{
+		Condition: cond,
+		Fields:    []string{common.GetInstIDField(objID), metadata.GetInstNameFieldName(objID)},
+		Page:      metadata.BasePage{Limit: metadata.InstGraphStartLimit},
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	if instRsp.Count > metadata.InstGraphStartLimit {
+		blog.Errorf("start instances count %d exceeds limit %d, rid: %s", instRsp.Count,
+			metadata.InstGraphStartLimit, kit.Rid)
+		return nil, kit.CCError.CCErrorf(common.CCErrCommXXExceedLimit, "start instances",
+			metadata.InstGraphStartLimit)
+	}
+
+	level := make(map[string][]int64)
+	for _, inst := range instRsp.Info {
+		node, err := newInstGraphNode(kit, objID, inst, 0)
+		if err != nil {
+			return nil, err
+		}
+
+		if graph.addNode(node) {
+			level[objID] = append(level[objID], node.InstID)
+		}
+	}
+
+	return level, nil
+}
+
+func newInstGraphNode(kit *rest.Kit, objID string, inst mapstr.MapStr, depth int) (*metadata.InstGraphNode,
+	error) {
+
+	instID, err := inst.Int64(common.GetInstIDField(objID))
+	if err != nil {
+		blog.Errorf("can not convert ID to int64, err: %v, inst: %#v, rid: %s", err, inst, kit.Rid)
+		return nil, kit.CCError.CCErrorf(common.CCErrCommParamsNeedInt, common.GetInstIDField(objID))
+	}
+
+	return &metadata.InstGraphNode{
+		ObjectID: objID,
+		InstID:   instID,
+		InstName: util.GetStrByInterface(inst[metadata.GetInstNameFieldName(objID)]),
+		Depth:    depth,
+	}, nil
+}
+
+// getInstGraphMainline returns the mainline topology of the objects, including the relation of host and module
+func (assoc *association) getInstGraphMainline(kit *rest.Kit) (*instGraphMainline, error) {
+	cond := &metadata.QueryCondition{
+		Condition:      mapstr.MapStr{common.AssociationKindIDField: common.AssociationKindMainline},
+		DisableCounter: true,
+	}
+	rsp, err := assoc.clientSet.CoreService().Association().ReadModelAssociation(kit.Ctx, kit.Header, cond)
+	if err != nil {
+		blog.Errorf("search mainline object associations failed, err: %v, rid: %s", err, kit.Rid)
+		return nil, err
+	}
+
+	mainline := &instGraphMainline{
+		parents:  make(map[string]metadata.Association),
+		children: make(map[string]metadata.Association),
+	}
+	for _, asst := range rsp.Info {
+		mainline.parents[asst.ObjectID] = asst
+		mainline.children[asst.AsstObjID] = asst
+	}
+	return mainline, nil
+}
+
+// getInstGraphSteps returns the steps from the instances in the level by the associations allowed in the hop, the
+// instances are looked up in batches to limit the size of each query.
+func (assoc *association) getInstGraphSteps(kit *rest.Kit, graph *instGraph, level map[string][]int64,
+	hop metadata.InstGraphHop, mainline *instGraphMainline) ([]instGraphStep, error) {
+
+	withMainline := len(hop.AsstKindIDs) == 0
+	asstKinds := make([]string, 0)
+	for _, kind := range hop.AsstKindIDs {
+		if kind == common.AssociationKindMainline {
+			withMainline = true
+			continue
+		}
+		asstKinds = append(asstKinds, kind)
+	}
+	withAsst := len(hop.AsstKindIDs) == 0 || len(asstKinds) > 0
+
+	steps := make([]instGraphStep, 0)
+	for objID, instIDs := range level {
+		for start := 0; start < len(instIDs); start += common.BKMaxInstanceLimit {
+			end := start + common.BKMaxInstanceLimit
+			if end > len(instIDs) {
+				end = len(instIDs)
+			}
+
+			if withAsst {
+				asstSteps, err := assoc.getInstGraphAsstSteps(kit, graph, objID, instIDs[start:end], hop.Direction,
+					asstKinds)
+				if err != nil {
+					return nil, err
+				}
+				steps = append(steps, asstSteps...)
+			}
+
+			if withMainline {
+				mainlineSteps, err := assoc.getInstGraphMainlineSteps(kit, graph, objID, instIDs[start:end],
+					hop.Direction, mainline)
+				if err != nil {
+					return nil, err
+				}
+				steps = append(steps, mainlineSteps...)
+			}
+		}
+	}
+
+	return steps, nil
+}
+
+// getInstGraphAsstSteps returns the steps from the instances by the instance associations of the kinds
+func (assoc *association) getInstGraphAsstSteps(kit *rest.Kit, graph *instGraph, objID string, instIDs []int64,
+	direction metadata.InstGraphDirection, asstKinds []string) ([]instGraphStep, error) {
+
+	orCond := make([]mapstr.MapStr, 0)
+	if direction != metadata.InstGraphIncoming {
+		orCond = append(orCond, mapstr.MapStr{common.BKObjIDField: objID,
+			common.BKInstIDField: mapstr.MapStr{common.BKDBIN: instIDs}})
+	}
+	if direction != metadata.InstGraphOutgoing {
+		orCond = append(orCond, mapstr.MapStr{common.BKAsstObjIDField: objID,
+			common.BKAsstInstIDField: mapstr.MapStr{common.BKDBIN: instIDs}})
+	}
+
+	cond := mapstr.MapStr{common.BKDBOR: orCond}
+	if len(asstKinds) > 0 {
+		cond[common.AssociationKindIDField] = mapstr.MapStr{common.BKDBIN: asstKinds}
+	}
+
+	query := &metadata.InstAsstQueryCondition{
+		Cond: metadata.QueryCondition{
+			Condition:      cond,
+			Page:           metadata.BasePage{Limit: metadata.InstGraphMaxEdges},
+			DisableCounter: true,
+		},
+		ObjID: objID,
+	}
+	assts, err := assoc.clientSet.CoreService().Association().ReadInstAssociation(kit.Ctx, kit.Header, query)
+	if err != nil {
+		blog.Errorf("search instance associations failed, cond: %#v, err: %v, rid: %s", query, err, kit.Rid)
+		return nil, err
+	}
+
+	if len(assts.Info) >= metadata.InstGraphMaxEdges {
+		graph.truncated = true
+	}
+
+	instIDMap := make(map[int64]struct{})
+	for _, instID := range instIDs {
+		instIDMap[instID] = struct{}{}
+	}
+
+	steps := make([]instGraphStep, 0)
+	for _, asst := range assts.Info {
+		edge := metadata.InstGraphEdge{
+			ID:           asst.ID,
+			ObjectAsstID: asst.ObjectAsstID,
+			AsstKindID:   asst.AssociationKindID,
+			ObjectID:     asst.ObjectID,
+			InstID:       asst.InstID,
+			AsstObjectID: asst.AsstObjectID,
+			AsstInstID:   asst.AsstInstID,
+		}
+		src := instGraphKey{objID: asst.ObjectID, instID: asst.InstID}
+		dest := instGraphKey{objID: asst.AsstObjectID, instID: asst.AsstInstID}
+
+		if _, exists := instIDMap[asst.InstID]; exists && direction != metadata.InstGraphIncoming &&
+			asst.ObjectID == objID {
+			steps = append(steps, instGraphStep{from: src, to: dest, edge: edge})
+		}
+
+		if _, exists := instIDMap[asst.AsstInstID]; exists && direction != metadata.InstGraphOutgoing &&
+			asst.AsstObjectID == objID {
+			steps = append(steps, instGraphStep{from: dest, to: src, edge: edge})
+		}
+	}
+
+	return steps, nil
+}
+
+// getInstGraphMainlineSteps returns the steps from the instances to their mainline parents and children
+func (assoc *association) getInstGraphMainlineSteps(kit *rest.Kit, graph *instGraph, objID string, instIDs []int64,
+	direction metadata.InstGraphDirection, mainline *instGraphMainline) ([]instGraphStep, error) {
+
+	steps := make([]instGraphStep, 0)
+	if asst, exists := mainline.parents[objID]; exists && direction != metadata.InstGraphIncoming {
+		edges, err := assoc.getInstGraphMainlineEdges(kit, graph, asst, instIDs, false)
+		if err != nil {
+			return nil, err
+		}
+
+		for _, edge := range edges {
+			steps = append(steps, instGraphStep{
+				from: instGraphKey{objID: edge.ObjectID, instID: edge.InstID},
+				to:   instGraphKey{objID: edge.AsstObjectID, instID: edge.AsstInstID},
+				edge: edge,
+			})
+		}
+	}
+
+	if asst, exists := mainline.children[objID]; exists && direction != metadata.InstGraphOutgoing {
+		edges, err := assoc.getInstGraphMainlineEdges(kit, graph, asst, instIDs, true)
+		if err != nil {
+			return nil, err
+		}
+
+		for _, edge := range edges {
+			steps = append(steps, instGraphStep{
+				from: instGraphKey{objID: edge.AsstObjectID, instID: edge.AsstInstID},
+				to:   instGraphKey{objID: edge.ObjectID, instID: edge.InstID},
+				edge: edge,
+			})
+		}
+	}
+
+	return steps, nil
+}
+
+// getInstGraphMainlineEdges returns the mainline relations of the child object instances, or of the parent object
+// instances if byParent is set. the relations of host and module are stored in the host module relation table, the
+// others are stored in the parent id field of the child instances.
+func (assoc *association) getInstGraphMainlineEdges(kit *rest.Kit, graph *instGraph, asst metadata.Association,
+	instIDs []int64, byParent bool) ([]metadata.InstGraphEdge, error) {
+
+	edges := make([]metadata.InstGraphEdge, 0)
+	newEdge := func(instID, parentID int64) metadata.InstGraphEdge {
+		return metadata.InstGraphEdge{
+			ObjectAsstID: asst.AssociationName,
+			AsstKindID:   common.AssociationKindMainline,
+			ObjectID:     asst.ObjectID,
+			InstID:       instID,
+			AsstObjectID: asst.AsstObjID,
+			AsstInstID:   parentID,
+		}
+	}
+
+	if asst.ObjectID == common.BKInnerObjIDHost {
+		option := &metadata.HostModuleRelationRequest{
+			Page:   metadata.BasePage{Limit: metadata.InstGraphMaxEdges},
+			Fields: []string{common.BKHostIDField, common.BKModuleIDField},
+		}
+		if byParent {
+			option.ModuleIDArr = instIDs
+		} else {
+			option.HostIDArr = instIDs
+		}
+
+		relations, err := assoc.clientSet.CoreService().Host().GetHostModuleRelation(kit.Ctx, kit.Header, option)
+		if err != nil {
+			blog.Errorf("get host module relations failed, option: %#v, err: %v, rid: %s", option, err, kit.Rid)
+			return nil, err
+		}
+
+		if len(relations.Info) >= metadata.InstGraphMaxEdges {
+			graph.truncated = true
+		}
+
+		for _, relation := range relations.Info {
+			edges = append(edges, newEdge(relation.HostID, relation.ModuleID))
+		}
+		return edges, nil
+	}
+
+	cond := mapstr.MapStr{common.GetInstIDField(asst.ObjectID): mapstr.MapStr{common.BKDBIN: instIDs}}
+	if byParent {
+		cond = mapstr.MapStr{common.BKParentIDField: mapstr.MapStr{common.BKDBIN: instIDs}}
+	}
+	if metadata.IsCommon(asst.ObjectID) {
+		cond[common.BKObjIDField] = asst.ObjectID
+	}
+
+	instRsp, err := assoc.inst.FindInst(kit, asst.ObjectID, &metadata.QueryCondition{
+		Condition:      cond,
+		Fields:         []string{common.GetInstIDField(asst.ObjectID), common.BKParentIDField},
+		Page:           metadata.BasePage{Limit: metadata.InstGraphMaxEdges},
+		DisableCounter: true,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	if len(instRsp.Info) >= metadata.InstGraphMaxEdges {
+		graph.truncated = true
+	}
+
+	for _, inst := range instRsp.Info {
+		instID, err := inst.Int64(common.GetInstIDField(asst.ObjectID))
+		if err != nil {
+			blog.Errorf("can not convert ID to int64, err: %v, inst: %#v, rid: %s", err, inst, kit.Rid)
+			return nil, kit.CCError.CCErrorf(common.CCErrCommParamsNeedInt, common.GetInstIDField(asst.ObjectID))
+		}
+
+		parentID, err := inst.Int64(common.BKParentIDField)
+		if err != nil {
+			blog.Errorf("can not convert parent ID to int64, err: %v, inst: %#v, rid: %s", err, inst, kit.Rid)
+			return nil, kit.CCError.CCErrorf(common.CCErrCommParamsNeedInt, common.BKParentIDField)
+		}
+		edges = append(edges, newEdge(instID, parentID))
+	}
+
+	return edges, nil
+}
+
+// addInstGraphSteps adds the existing instances reached by the steps to the graph, returns the newly reached ones
+// as the next level. the edges between the reached instances are all added, but only the first step that reaches
+// an instance is used in its shortest path.
+func (assoc *association) addInstGraphSteps(kit *rest.Kit, graph *instGraph, steps []instGraphStep, depth int) (
+	map[string][]int64, error) {
+
+	candidates := make(map[string][]int64)
+	candidateExists := make(map[instGraphKey]struct{})
+	for _, step := range steps {
+		if _, exists := graph.nodes[step.to]; exists {
+			continue
+		}
+
+		if _, exists := candidateExists[step.to]; exists {
+			continue
+		}
+		candidateExists[step.to] = struct{}{}
+		candidates[step.to.objID] = append(candidates[step.to.objID], step.to.instID)
+	}
+
+	newNodes := make(map[instGraphKey]*metadata.InstGraphNode)
+	for objID, instIDs := range candidates {
+		for start := 0; start < len(instIDs); start += common.BKMaxInstanceLimit {
+			end := start + common.BKMaxInstanceLimit
+			if end > len(instIDs) {
+				end = len(instIDs)
+			}
+
+			cond := mapstr.MapStr{common.GetInstIDField(objID): mapstr.MapStr{common.BKDBIN: instIDs[start:end]}}
+			if metadata.IsCommon(objID) {
+				cond[common.BKObjIDField] = objID
+			}
+
+			instRsp, err := assoc.inst.FindInst(kit, objID, &metadata.QueryCondition{
+				Condition:      cond,
+				Fields:         []string{common.GetInstIDField(objID), metadata.GetInstNameFieldName(objID)},
+				Page:           metadata.BasePage{Limit: common.BKMaxInstanceLimit},
+				DisableCounter: true,
+			})
+			if err != nil {
+				return nil, err
+			}
+
+			for _, inst := range instRsp.Info {
+				node, err := newInstGraphNode(kit, objID, inst, depth)
+				if err != nil {
+					return nil, err
+				}
+				newNodes[instGraphKey{objID: objID, instID: node.InstID}] = node
+			}
+		}
+	}
+
+	// the instances that do not exist are dirty associations, they are skipped. when the nodes exceed the limit, the
+	// remaining new instances are skipped, but the edges between the instances in the graph are still added.
+	nextLevel := make(map[string][]int64)
+	for _, step := range steps {
+		if node, exists := newNodes[step.to]; exists {
+			delete(newNodes, step.to)
+			if graph.addNode(node) {
+				graph.prev[step.to] = step
+				nextLevel[step.to.objID] = append(nextLevel[step.to.objID], step.to.instID)
+			}
+		}
+
+		if _, exists := graph.nodes[step.to]; exists {
+			graph.addEdge(step.edge)
+		}
+	}
+
+	return nextLevel, nil
+}
@@ -0,0 +1,223 @@
+/*
+ * Tencent is pleased to support the open source community by making 蓝鲸 available.
+ * Copyright (C) 2017-2018 THL A29 Limited, a Tencent company. All rights reserved.
+ * Licensed under the MIT License (the "License"); you may not use this file except
+ * in compliance with the License. You may obtain a copy of the License at
+ * http://opensource.org/licenses/MIT
+ * Unless required by applicable law or agreed to in writing, software distributed under
+ * the License is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND,
+ * either express or implied. See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package inst
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"reflect"
+	"testing"
+
+	"configcenter/src/common"
+	"configcenter/src/common/errors"
+	"configcenter/src/common/http/rest"
+	"configcenter/src/common/mapstr"
+	"configcenter/src/common/metadata"
+)
+
+// fakeGraphInst returns the preset existing instances
+type fakeGraphInst struct {
+	InstOperationInterface
+	// insts are the existing instance ids, object id => instance id => exists
+	insts map[string]map[int64]bool
+}
+
+// FindInst returns the existing instances whose ids are in the condition
+func (f *fakeGraphInst) FindInst(kit *rest.Kit, objID string, cond *metadata.QueryCondition) (
+	*metadata.InstResult, error) {
+
+	result := &metadata.InstResult{Info: make([]mapstr.MapStr, 0)}
+	instIDs := cond.Condition[common.BKInstIDField].(mapstr.MapStr)[common.BKDBIN].([]int64)
+	for _, instID := range instIDs {
+		if f.insts[objID][instID] {
+			result.Info = append(result.Info, mapstr.MapStr{
+				common.BKInstIDField:   instID,
+				common.BKInstNameField: fmt.Sprintf("%s%d", objID, instID),
+			})
+		}
+	}
+	result.Count = len(result.Info)
+	return result, nil
+}
+
+func newGraphStep(objID string, instID int64, asstObjID string, asstInstID int64) instGraphStep {
+	return instGraphStep{
+		from: instGraphKey{objID: objID, instID: instID},
+		to:   instGraphKey{objID: asstObjID, instID: asstInstID},
+		edge: metadata.InstGraphEdge{ObjectID: objID, InstID: instID, AsstObjectID: asstObjID, AsstInstID: asstInstID},
+	}
+}
+
+// newFullInstGraph returns a graph that has nodeCount nodes of object "a" with id from 1 to nodeCount, and edgeCount
+// edges of object "z" that are not related to any node
+func newFullInstGraph(nodeCount, edgeCount int) *instGraph {
+	graph := newInstGraph()
+	for i := 1; i <= nodeCount; i++ {
+		graph.addNode(&metadata.InstGraphNode{ObjectID: "a", InstID: int64(i), InstName: fmt.Sprintf("a%d", i)})
+	}
+	for i := 1; i <= edgeCount; i++ {
+		graph.addEdge(metadata.InstGraphEdge{ObjectID: "z", InstID: int64(i), AsstObjectID: "z"})
+	}
+	return graph
+}
+
+func TestInstGraphAddNode(t *testing.T) {
+	tests := []struct {
+		name          string
+		nodeCount     int
+		node          *metadata.InstGraphNode
+		want          bool
+		wantTruncated bool
+	}{
+		{"new node", 1, &metadata.InstGraphNode{ObjectID: "a", InstID: 2}, true, false},
+		{"existing node", 1, &metadata.InstGraphNode{ObjectID: "a", InstID: 1}, false, false},
+		{"same id of another object", 1, &metadata.InstGraphNode{ObjectID: "b", InstID: 1}, true, false},
+		{"last node below limit", metadata.InstGraphMaxNodes - 1, &metadata.InstGraphNode{ObjectID: "b", InstID: 1},
+			true, false},
+		{"new node exceeds limit", metadata.InstGraphMaxNodes, &metadata.InstGraphNode{ObjectID: "b", InstID: 1},
+			false, true},
+		{"existing node at limit", metadata.InstGraphMaxNodes, &metadata.InstGraphNode{ObjectID: "a", InstID: 1},
+			false, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			graph := newFullInstGraph(tt.nodeCount, 0)
+			if got := graph.addNode(tt.node); got != tt.want {
+				t.Errorf("addNode() = %v, want %v", got, tt.want)
+			}
+			if graph.truncated != tt.wantTruncated {
+				t.Errorf("addNode() truncated = %v, want %v", graph.truncated, tt.wantTruncated)
+			}
+			if len(graph.nodes) != len(graph.nodeKeys) || len(graph.nodes) > metadata.InstGraphMaxNodes {
+				t.Errorf("addNode() nodes = %d, node keys = %d", len(graph.nodes), len(graph.nodeKeys))
+			}
+		})
+	}
+}
+
+func TestInstGraphAddEdge(t *testing.T) {
+	newEdge := metadata.InstGraphEdge{ObjectID: "a", InstID: 1, AsstObjectID: "b", AsstInstID: 1}
+	existingEdge := metadata.InstGraphEdge{ObjectID: "z", InstID: 1, AsstObjectID: "z"}
+
+	tests := []struct {
+		name          string
+		edgeCount     int
+		edge          metadata.InstGraphEdge
+		wantCount     int
+		wantTruncated bool
+	}{
+		{"new edge", 1, newEdge, 2, false},
+		{"existing edge", 1, existingEdge, 1, false},
+		{"last edge below limit", metadata.InstGraphMaxEdges - 1, newEdge, metadata.InstGraphMaxEdges, false},
+		{"new edge exceeds limit", metadata.InstGraphMaxEdges, newEdge, metadata.InstGraphMaxEdges, true},
+		{"existing edge at limit", metadata.InstGraphMaxEdges, existingEdge, metadata.InstGraphMaxEdges, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			graph := newFullInstGraph(0, tt.edgeCount)
+			graph.addEdge(tt.edge)
+			if len(graph.edgeList) != tt.wantCount || len(graph.edges) != tt.wantCount {
+				t.Errorf("addEdge() edges = %d, edge list = %d, want %d", len(graph.edges), len(graph.edgeList),
+					tt.wantCount)
+			}
+			if graph.truncated != tt.wantTruncated {
+				t.Errorf("addEdge() truncated = %v, want %v", graph.truncated, tt.wantTruncated)
+			}
+		})
+	}
+}
+
+func TestAddInstGraphSteps(t *testing.T) {
+	kit := &rest.Kit{Ctx: context.Background(), Header: make(http.Header), Rid: "test_rid",
+		CCError: errors.NewFromCtx(errors.EmptyErrorsSetting).CreateDefaultCCErrorIf("zh-cn")}
+	assoc := &association{inst: &fakeGraphInst{insts: map[string]map[int64]bool{"b": {1: true, 2: true}}}}
+
+	// a1 reaches b1 and b2, b9 does not exist, a1 and a2 are both in the graph already
+	steps := []instGraphStep{
+		newGraphStep("a", 1, "b", 9),
+		newGraphStep("a", 1, "b", 1),
+		newGraphStep("a", 1, "b", 2),
+		newGraphStep("a", 2, "b", 1),
+		newGraphStep("a", 1, "a", 2),
+	}
+
+	tests := []struct {
+		name          string
+		nodeCount     int
+		edgeCount     int
+		wantNextLevel map[string][]int64
+		wantEdges     []instGraphStep
+		wantTruncated bool
+	}{
+		{
+			name:          "below limits",
+			nodeCount:     2,
+			wantNextLevel: map[string][]int64{"b": {1, 2}},
+			wantEdges:     []instGraphStep{steps[1], steps[2], steps[3], steps[4]},
+		},
+		{
+			name:          "edges between reached instances are added after node limit is hit",
+			nodeCount:     metadata.InstGraphMaxNodes - 1,
+			wantNextLevel: map[string][]int64{"b": {1}},
+			wantEdges:     []instGraphStep{steps[1], steps[3], steps[4]},
+			wantTruncated: true,
+		},
+		{
+			name:          "no new instance is added at node limit",
+			nodeCount:     metadata.InstGraphMaxNodes,
+			wantNextLevel: map[string][]int64{},
+			wantEdges:     []instGraphStep{steps[4]},
+			wantTruncated: true,
+		},
+		{
+			name:          "edges are not added after edge limit is hit",
+			nodeCount:     2,
+			edgeCount:     metadata.InstGraphMaxEdges - 2,
+			wantNextLevel: map[string][]int64{"b": {1, 2}},
+			wantEdges:     []instGraphStep{steps[1], steps[2]},
+			wantTruncated: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			graph := newFullInstGraph(tt.nodeCount, tt.edgeCount)
+			nextLevel, err := assoc.addInstGraphSteps(kit, graph, steps, 1)
+			if err != nil {
+				t.Fatalf("addInstGraphSteps() failed, err: %v", err)
+			}
+
+			if !reflect.DeepEqual(nextLevel, tt.wantNextLevel) {
+				t.Errorf("addInstGraphSteps() next level = %v, want %v", nextLevel, tt.wantNextLevel)
+			}
+			if graph.truncated != tt.wantTruncated {
+				t.Errorf("addInstGraphSteps() truncated = %v, want %v", graph.truncated, tt.wantTruncated)
+			}
+
+			wantEdges := make([]metadata.InstGraphEdge, len(tt.wantEdges))
+			for index, step := range tt.wantEdges {
+				wantEdges[index] = step.edge
+			}
+			if edges := graph.edgeList[tt.edgeCount:]; !reflect.DeepEqual(edges, wantEdges) {
+				t.Errorf("addInstGraphSteps() edges = %v, want %v", edges, wantEdges)
+			}
+
+			// the new nodes are reached by the first step to them, the existing nodes are not changed
+			for key, step := range graph.prev {
+				if step.to != key || step.from.objID != "a" || step.from.instID != 1 {
+					t.Errorf("addInstGraphSteps() node %v is reached by %v", key, step)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,333 @@
+/*
+ * Tencent is pleased to support the open source community by making 蓝鲸 available.
+ * Copyright (C) 2017-2018 THL A29 Limited, a Tencent company. All rights reserved.
+ * Licensed under the MIT License (the "License"); you may not use this file except
+ * in compliance with the License. You may obtain a copy of the License at
+ * http://opensource.org/licenses/MIT
+ * Unless required by applicable law or agreed to in writing, software distributed under
+ * the License is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND,
+ * either express or implied. See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package inst
+
+import (
+	"sort"
+	"strings"
+
+	"configcenter/src/common"
+	"configcenter/src/common/blog"
+	"configcenter/src/common/http/rest"
+	"configcenter/src/common/mapstr"
+	"configcenter/src/common/metadata"
+	"configcenter/src/common/util"
+)
+
+// AnalyzeInstImpact returns what depends on the instances, which are the instances linked to them by the dependency
+// associations, and the businesses, sets, modules, service instances and processes of the hosts among them.
+func (assoc *association) AnalyzeInstImpact(kit *rest.Kit, objID string, option *metadata.InstImpactOption) (
+	*metadata.InstImpactResult, error) {
+
+	hop := metadata.InstGraphHop{AsstKindIDs: option.AsstKindIDs, Direction: metadata.InstGraphBoth}
+	if len(hop.AsstKindIDs) == 0 {
+		hop.AsstKindIDs = metadata.InstImpactDefaultAsstKinds
+	}
+
+	maxDepth := option.MaxDepth
+	if maxDepth == 0 {
+		maxDepth = 1
+	}
+
+	cond := mapstr.MapStr{common.GetInstIDField(objID): mapstr.MapStr{common.BKDBIN: option.InstIDs}}
+	graph, err := assoc.traverseInstGraph(kit, objID, cond, func(int) metadata.InstGraphHop { return hop }, maxDepth)
+	if err != nil {
+		return nil, err
+	}
+
+	result := &metadata.InstImpactResult{
+		Instances:           make([]metadata.InstGraphNode, 0),
+		AssociatedInstances: make([]metadata.InstImpactObjectInsts, 0),
+		Businesses:          make([]metadata.InstImpactBusiness, 0),
+		Sets:                make([]metadata.InstImpactSet, 0),
+		Modules:             make([]metadata.InstImpactModule, 0),
+		ServiceInstances:    make([]metadata.InstImpactServiceInstance, 0),
+		Processes:           make([]metadata.InstImpactProcess, 0),
+		Contacts:            make([]string, 0),
+		Truncated:           graph.truncated,
+	}
+
+	hostIDs := make([]int64, 0)
+	asstObjIndex := make(map[string]int)
+	for _, key := range graph.nodeKeys {
+		node := *graph.nodes[key]
+		if key.objID == common.BKInnerObjIDHost {
+			hostIDs = append(hostIDs, key.instID)
+		}
+
+		if node.Depth == 0 {
+			result.Instances = append(result.Instances, node)
+			continue
+		}
+
+		index, exists := asstObjIndex[key.objID]
+		if !exists {
+			index = len(result.AssociatedInstances)
+			asstObjIndex[key.objID] = index
+			result.AssociatedInstances = append(result.AssociatedInstances,
+				metadata.InstImpactObjectInsts{ObjectID: key.objID, Instances: make([]metadata.InstGraphNode, 0)})
+		}
+		result.AssociatedInstances[index].Instances = append(result.AssociatedInstances[index].Instances, node)
+	}
+
+	if len(hostIDs) == 0 {
+		return result, nil
+	}
+
+	if err := assoc.analyzeHostImpact(kit, hostIDs, result); err != nil {
+		return nil, err
+	}
+
+	return result, nil
+}
+
+// analyzeHostImpact fills the topology, service instances, processes and owners related to the hosts into the result
+func (assoc *association) analyzeHostImpact(kit *rest.Kit, hostIDs []int64, result *metadata.InstImpactResult) error {
+	relOpt := &metadata.HostModuleRelationRequest{
+		HostIDArr: hostIDs,
+		Page:      metadata.BasePage{Limit: common.BKNoLimit},
+		Fields:    []string{common.BKAppIDField, common.BKSetIDField, common.BKModuleIDField, common.BKHostIDField},
+	}
+	relations, err := assoc.clientSet.CoreService().Host().GetHostModuleRelation(kit.Ctx, kit.Header, relOpt)
+	if err != nil {
+		blog.Errorf("get host module relations failed, host ids: %v, err: %v, rid: %s", hostIDs, err, kit.Rid)
+		return err
+	}
+
+	bizHostIDs := make(map[int64][]int64)
+	setIDs, moduleIDs := make([]int64, 0), make([]int64, 0)
+	for _, relation := range relations.Info {
+		bizHostIDs[relation.AppID] = append(bizHostIDs[relation.AppID], relation.HostID)
+		setIDs = append(setIDs, relation.SetID)
+		moduleIDs = append(moduleIDs, relation.ModuleID)
+	}
+
+	bizIDs := make([]int64, 0, len(bizHostIDs))
+	for bizID, ids := range bizHostIDs {
+		bizIDs = append(bizIDs, bizID)
+		bizHostIDs[bizID] = util.IntArrayUnique(ids)
+	}
+	sort.Slice(bizIDs, func(i, j int) bool { return bizIDs[i] < bizIDs[j] })
+
+	contacts := make([]string, 0)
+	bizs, err := assoc.findImpactInsts(kit, common.BKInnerObjIDApp, bizIDs, []string{common.BKAppIDField,
+		common.BKAppNameField, common.BKMaintainersField, common.BKProductPMField, common.BKDeveloperField,
+		common.BKTesterField, common.BKOperatorField})
+	if err != nil {
+		return err
+	}
+	for _, biz := range bizs {
+		business := metadata.InstImpactBusiness{
+			BizName:     util.GetStrByInterface(biz[common.BKAppNameField]),
+			Maintainers: util.GetStrByInterface(biz[common.BKMaintainersField]),
+			Productor:   util.GetStrByInterface(biz[common.BKProductPMField]),
+			Developer:   util.GetStrByInterface(biz[common.BKDeveloperField]),
+			Tester:      util.GetStrByInterface(biz[common.BKTesterField]),
+			Operator:    util.GetStrByInterface(biz[common.BKOperatorField]),
+		}
+		if business.BizID, err = getImpactInstID(kit, biz, common.BKAppIDField); err != nil {
+			return err
+		}
+		result.Businesses = append(result.Businesses, business)
+		contacts = append(contacts, business.Maintainers, business.Productor, business.Developer, business.Tester,
+			business.Operator)
+	}
+
+	sets, err := assoc.findImpactInsts(kit, common.BKInnerObjIDSet, util.IntArrayUnique(setIDs),
+		[]string{common.BKAppIDField, common.BKSetIDField, common.BKSetNameField})
+	if err != nil {
+		return err
+	}
+	for _, set := range sets {
+		impactSet := metadata.InstImpactSet{SetName: util.GetStrByInterface(set[common.BKSetNameField])}
+		if impactSet.BizID, err = getImpactInstID(kit, set, common.BKAppIDField); err != nil {
+			return err
+		}
+		if impactSet.SetID, err = getImpactInstID(kit, set, common.BKSetIDField); err != nil {
+			return err
+		}
+		result.Sets = append(result.Sets, impactSet)
+	}
+
+	modules, err := assoc.findImpactInsts(kit, common.BKInnerObjIDModule, util.IntArrayUnique(moduleIDs),
+		[]string{common.BKAppIDField, common.BKSetIDField, common.BKModuleIDField, common.BKModuleNameField,
+			common.BKOperatorField, common.BKBakOperatorField})
+	if err != nil {
+		return err
+	}
+	for _, module := range modules {
+		impactModule := metadata.InstImpactModule{
+			ModuleName:  util.GetStrByInterface(module[common.BKModuleNameField]),
+			Operator:    util.GetStrByInterface(module[common.BKOperatorField]),
+			BakOperator: util.GetStrByInterface(module[common.BKBakOperatorField]),
+		}
+		if impactModule.BizID, err = getImpactInstID(kit, module, common.BKAppIDField); err != nil {
+			return err
+		}
+		if impactModule.SetID, err = getImpactInstID(kit, module, common.BKSetIDField); err != nil {
+			return err
+		}
+		if impactModule.ModuleID, err = getImpactInstID(kit, module, common.BKModuleIDField); err != nil {
+			return err
+		}
+		result.Modules = append(result.Modules, impactModule)
+		contacts = append(contacts, impactModule.Operator, impactModule.BakOperator)
+	}
+
+	result.Contacts = getImpactContacts(contacts)
+
+	// service instances and processes are stored by business, so they are searched in each business
+	for _, bizID := range bizIDs {
+		if err := assoc.analyzeHostProcessImpact(kit, bizID, bizHostIDs[bizID], result); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// analyzeHostProcessImpact fills the service instances and processes of the hosts in the business into the result
+func (assoc *association) analyzeHostProcessImpact(kit *rest.Kit, bizID int64, hostIDs []int64,
+	result *metadata.InstImpactResult) error {
+
+	srvInstOpt := &metadata.ListServiceInstanceOption{
+		BusinessID: bizID,
+		HostIDs:    hostIDs,
+		Page:       metadata.BasePage{Limit: common.BKNoLimit},
+	}
+	srvInsts, ccErr := assoc.clientSet.CoreService().Process().ListServiceInstance(kit.Ctx, kit.Header, srvInstOpt)
+	if ccErr != nil {
+		blog.Errorf("list service instances failed, option: %#v, err: %v, rid: %s", srvInstOpt, ccErr, kit.Rid)
+		return ccErr
+	}
+
+	if len(srvInsts.Info) == 0 {
+		return nil
+	}
+
+	srvInstIDs := make([]int64, len(srvInsts.Info))
+	for index, srvInst := range srvInsts.Info {
+		srvInstIDs[index] = srvInst.ID
+		result.ServiceInstances = append(result.ServiceInstances, metadata.InstImpactServiceInstance{
+			BizID:    srvInst.BizID,
+			ID:       srvInst.ID,
+			Name:     srvInst.Name,
+			ModuleID: srvInst.ModuleID,
+			HostID:   srvInst.HostID,
+		})
+	}
+
+	relOpt := &metadata.ListProcessInstanceRelationOption{
+		BusinessID:         bizID,
+		ServiceInstanceIDs: srvInstIDs,
+		Page:               metadata.BasePage{Limit: common.BKNoLimit},
+	}
+	relations, ccErr := assoc.clientSet.CoreService().Process().ListProcessInstanceRelation(kit.Ctx, kit.Header,
+		relOpt)
+	if ccErr != nil {
+		blog.Errorf("list process relations failed, option: %#v, err: %v, rid: %s", relOpt, ccErr, kit.Rid)
+		return ccErr
+	}
+
+	if len(relations.Info) == 0 {
+		return nil
+	}
+
+	procIDs := make([]int64, len(relations.Info))
+	for index, relation := range relations.Info {
+		procIDs[index] = relation.ProcessID
+	}
+
+	procs, err := assoc.findImpactInsts(kit, common.BKInnerObjIDProc, procIDs,
+		[]string{common.BKProcIDField, common.BKProcNameField})
+	if err != nil {
+		return err
+	}
+
+	procNameMap := make(map[int64]string)
+	for _, proc := range procs {
+		procID, err := getImpactInstID(kit, proc, common.BKProcIDField)
+		if err != nil {
+			return err
+		}
+		procNameMap[procID] = util.GetStrByInterface(proc[common.BKProcNameField])
+	}
+
+	for _, relation := range relations.Info {
+		result.Processes = append(result.Processes, metadata.InstImpactProcess{
+			BizID:             relation.BizID,
+			ProcessID:         relation.ProcessID,
+			ProcessName:       procNameMap[relation.ProcessID],
+			ServiceInstanceID: relation.ServiceInstanceID,
+			HostID:            relation.HostID,
+		})
+	}
+
+	return nil
+}
+
+// findImpactInsts finds the instances of the inner object by ids in batches
+func (assoc *association) findImpactInsts(kit *rest.Kit, objID string, instIDs []int64, fields []string) (
+	[]mapstr.MapStr, error) {
+
+	insts := make([]mapstr.MapStr, 0)
+	for start := 0; start < len(instIDs); start += common.BKMaxInstanceLimit {
+		end := start + common.BKMaxInstanceLimit
+		if end > len(instIDs) {
+			end = len(instIDs)
+		}
+
+		cond := mapstr.MapStr{common.GetInstIDField(objID): mapstr.MapStr{common.BKDBIN: instIDs[start:end]}}
+		instRsp, err := assoc.inst.FindInst(kit, objID, &metadata.QueryCondition{
+			Condition:      cond,
+			Fields:         fields,
+			Page:           metadata.BasePage{Limit: common.BKMaxInstanceLimit},
+			DisableCounter: true,
+		})
+		if err != nil {
+			return nil, err
+		}
+		insts = append(insts, instRsp.Info...)
+	}
+
+	return insts, nil
+}
+
+func getImpactInstID(kit *rest.Kit, inst mapstr.MapStr, field string) (int64, error) {
+	instID, err := inst.Int64(field)
+	if err != nil {
+		blog.Errorf("can not convert %s to int64, err: %v, inst: %#v, rid: %s", field, err, inst, kit.Rid)
+		return 0, kit.CCError.CCErrorf(common.CCErrCommParamsNeedInt, field)
+	}
+	return instID, nil
+}
+
+// getImpactContacts splits the comma separated owners, returns the sorted distinct ones
+func getImpactContacts(owners []string) []string {
+	contactMap := make(map[string]struct{})
+	for _, owner := range owners {
+		for _, contact := range strings.Split(owner, ",") {
+			contact = strings.TrimSpace(contact)
+			if len(contact) > 0 {
+				contactMap[contact] = struct{}{}
+			}
+		}
+	}
+
+	contacts := make([]string, 0, len(contactMap))
+	for contact := range contactMap {
+		contacts = append(contacts, contact)
+	}
+	sort.Strings(contacts)
+	return contacts
+}
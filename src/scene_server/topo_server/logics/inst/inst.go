@@ -47,6 +47,12 @@ type InstOperationInterface interface {
 	DeleteInst(kit *rest.Kit, objectID string, cond mapstr.MapStr, needCheckHost bool) error
 	// DeleteInstByInstID batch delete instance by inst id
 	DeleteInstByInstID(kit *rest.Kit, objectID string, instID []int64, needCheckHost bool) error
+	// PreviewCascadeDelete returns the tree of the instances that will be deleted in cascade with the instances
+	PreviewCascadeDelete(kit *rest.Kit, objID string, instIDs []int64) (*metadata.InstCascadeDeletePreviewResult,
+		error)
+	// RestoreInst restore deleted instances from the delete archive with their original ids
+	RestoreInst(kit *rest.Kit, objID string, option *metadata.RestoreInstOption) (*metadata.RestoreInstResult,
+		error)
 	// FindInst search instance by condition
 	FindInst(kit *rest.Kit, objID string, cond *metadata.QueryCondition) (*metadata.InstResult, error)
 	// FindInstByAssociationInst deprecated function.
@@ -600,6 +606,16 @@ func (c *commonInst) DeleteInst(kit *rest.Kit, objectID string, cond mapstr.MapS
 		return kit.CCError.Error(common.CCErrTopoHasHostCheckFailed)
 	}
 
+	// the instances associated by the association on delete actions are deleted in cascade
+	cascade, err := c.buildCascadeDeletion(kit, objectID, instRsp.Info)
+	if err != nil {
+		return err
+	}
+
+	if err := c.prepareCascadeDeletion(kit, cascade); err != nil {
+		return err
+	}
+
 	audit := auditlog.NewInstanceAudit(c.clientSet.CoreService())
 	auditLogs := make([]metadata.AuditLog, 0)
 
@@ -618,6 +634,12 @@ func (c *commonInst) DeleteInst(kit *rest.Kit, objectID string, cond mapstr.MapS
 		}
 	}
 
+	cascadeAuditLogs, err := c.deleteCascadeInsts(kit, cascade)
+	if err != nil {
+		return err
+	}
+	auditLogs = append(auditLogs, cascadeAuditLogs...)
+
 	err = audit.SaveAuditLog(kit, auditLogs...)
 	if err != nil {
 		blog.Errorf("delete inst, save audit log failed, err: %v, rid: %s", err, kit.Rid)
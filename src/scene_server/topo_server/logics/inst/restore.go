@@ -0,0 +1,68 @@
+/*
+ * Tencent is pleased to support the open source community by making 蓝鲸 available.
+ * Copyright (C) 2017-2018 THL A29 Limited, a Tencent company. All rights reserved.
+ * Licensed under the MIT License (the "License"); you may not use this file except
+ * in compliance with the License. You may obtain a copy of the License at
+ * http://opensource.org/licenses/MIT
+ * Unless required by applicable law or agreed to in writing, software distributed under
+ * the License is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND,
+ * either express or implied. See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package inst
+
+import (
+	"configcenter/src/common"
+	"configcenter/src/common/auditlog"
+	"configcenter/src/common/blog"
+	"configcenter/src/common/http/rest"
+	"configcenter/src/common/metadata"
+)
+
+// RestoreInst restore deleted instances from the delete archive with their original ids, the still valid
+// associations and host relations are restored too, then the restore audit logs are saved.
+func (c *commonInst) RestoreInst(kit *rest.Kit, objID string, option *metadata.RestoreInstOption) (
+	*metadata.RestoreInstResult, error) {
+
+	result, ccErr := c.clientSet.CoreService().Instance().RestoreInstance(kit.Ctx, kit.Header, objID, option)
+	if ccErr != nil {
+		blog.Errorf("restore %s instances failed, err: %v, option: %#v, rid: %s", objID, ccErr, option, kit.Rid)
+		return nil, ccErr
+	}
+
+	audit := auditlog.NewInstanceAudit(c.clientSet.CoreService())
+	auditParam := auditlog.NewGenerateAuditCommonParameter(kit, metadata.AuditRestore)
+	var auditLogs []metadata.AuditLog
+	var err error
+	if objID == common.BKInnerObjIDHost {
+		// the business of the restored hosts are got from their restored relations
+		auditLogs, err = auditlog.NewHostAudit(c.clientSet.CoreService()).GenerateAuditLog(auditParam, 0,
+			result.Instances)
+	} else {
+		auditLogs, err = audit.GenerateAuditLog(auditParam, objID, result.Instances)
+	}
+	if err != nil {
+		blog.Errorf("generate %s instance restore audit log failed, err: %v, rid: %s", objID, err, kit.Rid)
+		return nil, err
+	}
+
+	asstAudit := auditlog.NewInstanceAssociationAudit(c.clientSet.CoreService())
+	asstAuditParam := auditlog.NewGenerateAuditCommonParameter(kit, metadata.AuditCreate)
+	for idx := range result.Associations {
+		asst := result.Associations[idx]
+		auditLog, err := asstAudit.GenerateAuditLog(asstAuditParam, asst.ID, asst.ObjectID, &asst)
+		if err != nil {
+			blog.Errorf("generate restored association audit log failed, err: %v, rid: %s", err, kit.Rid)
+			return nil, err
+		}
+		auditLogs = append(auditLogs, *auditLog)
+	}
+
+	if err := audit.SaveAuditLog(kit, auditLogs...); err != nil {
+		blog.Errorf("save %s instance restore audit log failed, err: %v, rid: %s", objID, err, kit.Rid)
+		return nil, kit.CCError.Error(common.CCErrAuditSaveLogFailed)
+	}
+
+	return result, nil
+}
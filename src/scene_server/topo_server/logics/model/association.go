@@ -243,6 +243,11 @@ func (assoc *association) CreateCommonAssociation(kit *rest.Kit, data *metadata.
 		data.OnDelete = metadata.NoAction
 	}
 
+	if !data.OnDelete.IsValid() {
+		blog.Errorf("association on delete action %s is invalid, rid: %s", data.OnDelete, kit.Rid)
+		return nil, kit.CCError.CCErrorf(common.CCErrCommParamsIsInvalid, "on_delete")
+	}
+
 	// check if this association has already exist,
 	// if yes, it's not allowed to create this association
 
@@ -352,6 +357,12 @@ func (assoc *association) UpdateObjectAssociation(kit *rest.Kit, data mapstr.Map
 		return kit.CCError.CCError(common.CCErrorTopoObjectAssociationUpdateForbiddenFields)
 	}
 
+	if onDelete, exists := data.Get("on_delete"); exists &&
+		!metadata.AssociationOnDeleteAction(util.GetStrByInterface(onDelete)).IsValid() {
+		blog.Errorf("association on delete action %v is invalid, rid: %s", onDelete, kit.Rid)
+		return kit.CCError.CCErrorf(common.CCErrCommParamsIsInvalid, "on_delete")
+	}
+
 	rsp, err := assoc.clientSet.CoreService().Association().ReadModelAssociation(kit.Ctx, kit.Header,
 		&metadata.QueryCondition{Condition: mapstr.MapStr{metadata.AssociationFieldAssociationId: assoID}})
 	if err != nil {
@@ -834,6 +834,31 @@ func (s *Service) SearchAssociationRelatedInst(ctx *rest.Contexts) {
 	ctx.RespEntity(res.Info)
 }
 
+// SearchInstGraph traverses the graph made up of the instance associations and the mainline topology from the start
+// instances hop by hop, returns the reached instances in the form of paths, distinct nodes or a subgraph
+func (s *Service) SearchInstGraph(ctx *rest.Contexts) {
+	option := new(metadata.InstGraphQueryOption)
+	if err := ctx.DecodeInto(option); err != nil {
+		ctx.RespAutoError(err)
+		return
+	}
+
+	if rawErr := option.Validate(); rawErr.ErrCode != 0 {
+		blog.Errorf("validate instance graph query option failed, err: %v, rid: %s", rawErr, ctx.Kit.Rid)
+		ctx.RespAutoError(rawErr.ToCCError(ctx.Kit.CCError))
+		return
+	}
+
+	result, err := s.Logics.InstAssociationOperation().FindInstGraph(ctx.Kit, option)
+	if err != nil {
+		blog.Errorf("search instance graph failed, err: %v, option: %#v, rid: %s", err, option, ctx.Kit.Rid)
+		ctx.RespAutoError(err)
+		return
+	}
+
+	ctx.RespEntity(result)
+}
+
 // SearchInstAssociationAndInstDetail search association, source object inst and destination object inst
 // related issue: https://github.com/TencentBlueKing/bk-cmdb/issues/5807
 func (s *Service) SearchInstAssociationAndInstDetail(ctx *rest.Contexts) {
@@ -0,0 +1,474 @@
+/*
+ * Tencent is pleased to support the open source community by making 蓝鲸 available.
+ * Copyright (C) 2017-2018 THL A29 Limited, a Tencent company. All rights reserved.
+ * Licensed under the MIT License (the "License"); you may not use this file except
+ * in compliance with the License. You may obtain a copy of the License at
+ * http://opensource.org/licenses/MIT
+ * Unless required by applicable law or agreed to in writing, software distributed under
+ * the License is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND,
+ * either express or implied. See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package service
+
+import (
+	"sort"
+	"time"
+
+	"configcenter/src/common"
+	"configcenter/src/common/auditlog"
+	"configcenter/src/common/blog"
+	"configcenter/src/common/http/rest"
+	"configcenter/src/common/mapstr"
+	"configcenter/src/common/metadata"
+	"configcenter/src/common/util"
+
+	"github.com/coccyx/timeparser"
+)
+
+// SearchAuditSnapshot reconstruct the instance's state at a past time by replaying its audit logs
+func (s *Service) SearchAuditSnapshot(ctx *rest.Contexts) {
+	opt := new(metadata.AuditSnapshotOption)
+	if err := ctx.DecodeInto(opt); err != nil {
+		ctx.RespAutoError(err)
+		return
+	}
+
+	if rawErr := opt.Validate(); rawErr.ErrCode != 0 {
+		ctx.RespAutoError(rawErr.ToCCError(ctx.Kit.CCError))
+		return
+	}
+
+	ctx.SetReadPreference(common.SecondaryPreferredMode)
+
+	cond, err := s.genInstAuditCond(ctx.Kit, opt.ObjID, opt.InstID)
+	if err != nil {
+		ctx.RespAutoError(err)
+		return
+	}
+
+	snapshot, err := s.getInstAuditSnapshot(ctx.Kit, opt.ObjID, opt.InstID, opt.AsOf, cond)
+	if err != nil {
+		ctx.RespAutoError(err)
+		return
+	}
+
+	if opt.ObjID == common.BKInnerObjIDHost {
+		snapshot.HostTopo, err = s.getHostTopoAuditSnapshot(ctx.Kit, opt.InstID, opt.AsOf)
+		if err != nil {
+			ctx.RespAutoError(err)
+			return
+		}
+	}
+
+	ctx.RespEntity(snapshot)
+}
+
+// genInstAuditCond generate the condition to search the audit logs of the instance
+func (s *Service) genInstAuditCond(kit *rest.Kit, objID string, instID int64) (mapstr.MapStr, error) {
+	isMainline, err := s.Logics.AssociationOperation().IsMainlineObject(kit, objID)
+	if err != nil {
+		blog.Errorf("check if object %s is mainline object failed, err: %v, rid: %s", objID, err, kit.Rid)
+		return nil, err
+	}
+
+	resType := metadata.GetResourceTypeByObjID(objID, isMainline)
+	cond := mapstr.MapStr{
+		common.BKResourceTypeField: resType,
+		common.BKResourceIDField:   instID,
+	}
+
+	if resType == metadata.ModelInstanceRes || resType == metadata.MainlineInstanceRes {
+		cond[common.BKOperationDetailField+"."+common.BKObjIDField] = objID
+	}
+
+	return cond, nil
+}
+
+// getInstAuditSnapshot reconstruct the instance at the time. the data before the first data audit log after the time
+// is the data at the time, if there is no such audit log, the instance is not changed after the time, so the current
+// data is used, if the instance has been deleted, the data after the last data audit log before the time is used.
+func (s *Service) getInstAuditSnapshot(kit *rest.Kit, objID string, instID int64, asOf string,
+	auditCond mapstr.MapStr) (*metadata.AuditSnapshot, error) {
+
+	snapshot := &metadata.AuditSnapshot{ObjID: objID, InstID: instID, AsOf: asOf}
+
+	cond := mapstr.MapStr{
+		common.BKActionField:        mapstr.MapStr{common.BKDBIN: metadata.AuditDataActions},
+		common.BKOperationTimeField: mapstr.MapStr{common.BKDBGT: asOf},
+	}
+	cond.Merge(auditCond)
+	next, err := s.searchOneAuditLog(kit, cond, common.BKFieldID)
+	if err != nil {
+		return nil, err
+	}
+
+	if next != nil {
+		snapshot.Data, snapshot.Exists = auditlog.DataBeforeAuditLog(next)
+		snapshot.AuditID = next.ID
+		return snapshot, nil
+	}
+
+	// the instance is not changed after the time, use the current data if it exists
+	query := &metadata.QueryCondition{
+		Condition:      mapstr.MapStr{metadata.GetInstIDFieldByObjID(objID): instID},
+		DisableCounter: true,
+	}
+	instRes, err := s.Engine.CoreAPI.CoreService().Instance().ReadInstance(kit.Ctx, kit.Header, objID, query)
+	if err != nil {
+		blog.Errorf("get %s instance %d failed, err: %v, rid: %s", objID, instID, err, kit.Rid)
+		return nil, err
+	}
+
+	if len(instRes.Info) > 0 {
+		snapshot.Data = instRes.Info[0]
+		snapshot.Exists = isCreatedBefore(instRes.Info[0], asOf)
+		return snapshot, nil
+	}
+
+	// the instance has been deleted, use the last known data before the time
+	cond[common.BKOperationTimeField] = mapstr.MapStr{common.BKDBLTE: asOf}
+	last, err := s.searchOneAuditLog(kit, cond, "-"+common.BKFieldID)
+	if err != nil {
+		return nil, err
+	}
+
+	if last != nil {
+		snapshot.Data, snapshot.Exists = auditlog.ApplyAuditLog(nil, last)
+		snapshot.AuditID = last.ID
+	}
+
+	return snapshot, nil
+}
+
+// isCreatedBefore checks if the instance is created before the time, returns true if the create time is unknown
+func isCreatedBefore(inst mapstr.MapStr, asOf string) bool {
+	createTimeStr, ok := inst[common.CreateTimeField].(string)
+	if !ok {
+		return true
+	}
+
+	createTime, err := metadata.ParseTime(createTimeStr)
+	if err != nil {
+		return true
+	}
+
+	asOfTime, err := timeparser.TimeParserInLocation(asOf, time.Local)
+	if err != nil {
+		return true
+	}
+
+	return !createTime.After(asOfTime)
+}
+
+// getHostTopoAuditSnapshot get the host's business topology at the time from the host transfer audit logs, returns
+// nil if the topology is not changed after the time
+func (s *Service) getHostTopoAuditSnapshot(kit *rest.Kit, hostID int64, asOf string) (*metadata.HostBizTopo,
+	error) {
+
+	cond := mapstr.MapStr{
+		common.BKResourceTypeField:  metadata.HostRes,
+		common.BKResourceIDField:    hostID,
+		common.BKActionField:        mapstr.MapStr{common.BKDBIN: metadata.AuditHostTopoActions},
+		common.BKOperationTimeField: mapstr.MapStr{common.BKDBGT: asOf},
+	}
+	next, err := s.searchOneAuditLog(kit, cond, common.BKFieldID)
+	if err != nil {
+		return nil, err
+	}
+
+	if next == nil {
+		return nil, nil
+	}
+
+	detail, ok := next.OperationDetail.(*metadata.HostTransferOpDetail)
+	if !ok {
+		return nil, nil
+	}
+	return &detail.PreData, nil
+}
+
+// searchOneAuditLog search the first audit log sorted by the sort field, returns nil if not found
+func (s *Service) searchOneAuditLog(kit *rest.Kit, cond mapstr.MapStr, sort string) (*metadata.AuditLog, error) {
+	query := metadata.QueryCondition{
+		Condition: cond,
+		Page:      metadata.BasePage{Limit: 1, Sort: sort},
+	}
+
+	rsp, err := s.Engine.CoreAPI.CoreService().Audit().SearchAuditLog(kit.Ctx, kit.Header, query)
+	if err != nil {
+		blog.Errorf("search audit log failed, cond: %#v, err: %v, rid: %s", cond, err, kit.Rid)
+		return nil, err
+	}
+
+	if len(rsp.Info) == 0 {
+		return nil, nil
+	}
+	return &rsp.Info[0], nil
+}
+
+// searchAllAuditLogs search all audit logs sorted by id, the count of them can not exceed the replay limit
+func (s *Service) searchAllAuditLogs(kit *rest.Kit, cond mapstr.MapStr) ([]metadata.AuditLog, error) {
+	auditLogs := make([]metadata.AuditLog, 0)
+	for start := 0; ; start += common.BKAuditLogPageLimit {
+		query := metadata.QueryCondition{
+			Condition: cond,
+			Page:      metadata.BasePage{Start: start, Limit: common.BKAuditLogPageLimit, Sort: common.BKFieldID},
+		}
+
+		rsp, err := s.Engine.CoreAPI.CoreService().Audit().SearchAuditLog(kit.Ctx, kit.Header, query)
+		if err != nil {
+			blog.Errorf("search audit log failed, cond: %#v, err: %v, rid: %s", cond, err, kit.Rid)
+			return nil, err
+		}
+
+		if rsp.Count > metadata.AuditReplayLimit {
+			blog.Errorf("audit log count %d exceeds replay limit, cond: %#v, rid: %s", rsp.Count, cond, kit.Rid)
+			return nil, kit.CCError.CCErrorf(common.CCErrExceedMaxOperationRecordsAtOnce, metadata.AuditReplayLimit)
+		}
+
+		auditLogs = append(auditLogs, rsp.Info...)
+		if len(rsp.Info) < common.BKAuditLogPageLimit {
+			return auditLogs, nil
+		}
+	}
+}
+
+// SearchBizTopoAuditSnapshot reconstruct the business topology at a past time by replaying the audit logs of the
+// mainline instances in the business
+func (s *Service) SearchBizTopoAuditSnapshot(ctx *rest.Contexts) {
+	opt := new(metadata.BizTopoAuditSnapshotOption)
+	if err := ctx.DecodeInto(opt); err != nil {
+		ctx.RespAutoError(err)
+		return
+	}
+
+	if rawErr := opt.Validate(); rawErr.ErrCode != 0 {
+		ctx.RespAutoError(rawErr.ToCCError(ctx.Kit.CCError))
+		return
+	}
+
+	ctx.SetReadPreference(common.SecondaryPreferredMode)
+	kit := ctx.Kit
+
+	bizCond, err := s.genInstAuditCond(kit, common.BKInnerObjIDApp, opt.BizID)
+	if err != nil {
+		ctx.RespAutoError(err)
+		return
+	}
+
+	biz, err := s.getInstAuditSnapshot(kit, common.BKInnerObjIDApp, opt.BizID, opt.AsOf, bizCond)
+	if err != nil {
+		ctx.RespAutoError(err)
+		return
+	}
+
+	if !biz.Exists {
+		blog.Errorf("business %d does not exist at %s, rid: %s", opt.BizID, opt.AsOf, kit.Rid)
+		ctx.RespAutoError(kit.CCError.CCErrorf(common.CCErrCommNotFound, common.BKAppIDField))
+		return
+	}
+
+	mainlineTopo, err := s.Logics.AssociationOperation().SearchMainlineAssociationTopo(kit, common.BKInnerObjIDApp)
+	if err != nil {
+		ctx.RespAutoError(err)
+		return
+	}
+
+	root := &metadata.TopoInstRst{TopoInst: metadata.TopoInst{InstID: opt.BizID, ObjID: common.BKInnerObjIDApp,
+		InstName: util.GetStrByInterface(biz.Data[common.BKAppNameField])}}
+	parents := map[int64]*metadata.TopoInstRst{opt.BizID: root}
+
+	for _, obj := range mainlineTopo {
+		if obj.ObjID == common.BKInnerObjIDApp || obj.ObjID == common.BKInnerObjIDHost {
+			continue
+		}
+
+		insts, err := s.getBizMainlineInstAuditSnapshot(kit, obj.ObjID, opt.BizID, opt.AsOf)
+		if err != nil {
+			ctx.RespAutoError(err)
+			return
+		}
+
+		parentField := common.BKParentIDField
+		if obj.ObjID == common.BKInnerObjIDModule {
+			parentField = common.BKSetIDField
+		}
+
+		children := make(map[int64]*metadata.TopoInstRst)
+		for instID, inst := range insts {
+			parentID, err := util.GetInt64ByInterface(inst[parentField])
+			if err != nil {
+				blog.Errorf("parse %s %d parent id failed, inst: %v, err: %v, rid: %s", obj.ObjID, instID, inst, err,
+					kit.Rid)
+				continue
+			}
+
+			parent, exists := parents[parentID]
+			if !exists {
+				blog.Warnf("%s %d parent %d does not exist at %s, rid: %s", obj.ObjID, instID, parentID, opt.AsOf,
+					kit.Rid)
+				continue
+			}
+
+			defaultVal, _ := util.GetIntByInterface(inst[common.BKDefaultField])
+			child := &metadata.TopoInstRst{
+				TopoInst: metadata.TopoInst{
+					InstID:   instID,
+					InstName: util.GetStrByInterface(inst[metadata.GetInstNameFieldName(obj.ObjID)]),
+					ObjID:    obj.ObjID,
+					ObjName:  obj.ObjName,
+					Default:  defaultVal,
+				},
+				Child: make([]*metadata.TopoInstRst, 0),
+			}
+			parent.Child = append(parent.Child, child)
+			children[instID] = child
+		}
+		parents = children
+	}
+
+	root.DeepFirstTraverse(func(tir *metadata.TopoInstRst) {
+		sort.Slice(tir.Child, func(i, j int) bool {
+			return tir.Child[i].InstID < tir.Child[j].InstID
+		})
+	})
+
+	ctx.RespEntity(root)
+}
+
+// getBizMainlineInstAuditSnapshot get the mainline instances of the object in the business at the time, returns the
+// map of instance id to its data. the data before the first data audit log after the time is the data at the time,
+// the current instances that are not changed after the time and are created before the time are also returned.
+func (s *Service) getBizMainlineInstAuditSnapshot(kit *rest.Kit, objID string, bizID int64, asOf string) (
+	map[int64]mapstr.MapStr, error) {
+
+	resType := metadata.GetResourceTypeByObjID(objID, true)
+	cond := mapstr.MapStr{
+		common.BKResourceTypeField:  resType,
+		common.BKAppIDField:         bizID,
+		common.BKActionField:        mapstr.MapStr{common.BKDBIN: metadata.AuditDataActions},
+		common.BKOperationTimeField: mapstr.MapStr{common.BKDBGT: asOf},
+	}
+	if resType == metadata.MainlineInstanceRes {
+		cond[common.BKOperationDetailField+"."+common.BKObjIDField] = objID
+	}
+
+	auditLogs, err := s.searchAllAuditLogs(kit, cond)
+	if err != nil {
+		return nil, err
+	}
+
+	insts := make(map[int64]mapstr.MapStr)
+	changed := make(map[int64]struct{})
+	for i := range auditLogs {
+		instID, err := util.GetInt64ByInterface(auditLogs[i].ResourceID)
+		if err != nil {
+			blog.Errorf("parse audit log %d resource id failed, err: %v, rid: %s", auditLogs[i].ID, err, kit.Rid)
+			continue
+		}
+
+		if _, exists := changed[instID]; exists {
+			continue
+		}
+		changed[instID] = struct{}{}
+
+		if data, exists := auditlog.DataBeforeAuditLog(&auditLogs[i]); exists {
+			insts[instID] = data
+		}
+	}
+
+	query := &metadata.QueryCondition{
+		Condition:      mapstr.MapStr{common.BKAppIDField: bizID},
+		Page:           metadata.BasePage{Limit: common.BKNoLimit},
+		DisableCounter: true,
+	}
+	instRes, err := s.Engine.CoreAPI.CoreService().Instance().ReadInstance(kit.Ctx, kit.Header, objID, query)
+	if err != nil {
+		blog.Errorf("get biz %d %s instances failed, err: %v, rid: %s", bizID, objID, err, kit.Rid)
+		return nil, err
+	}
+
+	idField := metadata.GetInstIDFieldByObjID(objID)
+	for _, inst := range instRes.Info {
+		instID, err := util.GetInt64ByInterface(inst[idField])
+		if err != nil {
+			blog.Errorf("parse %s instance id failed, inst: %v, err: %v, rid: %s", objID, inst, err, kit.Rid)
+			continue
+		}
+
+		if _, exists := changed[instID]; exists || !isCreatedBefore(inst, asOf) {
+			continue
+		}
+		insts[instID] = inst
+	}
+
+	return insts, nil
+}
+
+// SearchAuditTimeline get the field level change timeline of an instance from its audit logs
+func (s *Service) SearchAuditTimeline(ctx *rest.Contexts) {
+	opt := new(metadata.AuditTimelineOption)
+	if err := ctx.DecodeInto(opt); err != nil {
+		ctx.RespAutoError(err)
+		return
+	}
+
+	if rawErr := opt.Validate(); rawErr.ErrCode != 0 {
+		ctx.RespAutoError(rawErr.ToCCError(ctx.Kit.CCError))
+		return
+	}
+
+	ctx.SetReadPreference(common.SecondaryPreferredMode)
+
+	instCond, err := s.genInstAuditCond(ctx.Kit, opt.ObjID, opt.InstID)
+	if err != nil {
+		ctx.RespAutoError(err)
+		return
+	}
+
+	actions := metadata.AuditDataActions
+	if opt.ObjID == common.BKInnerObjIDHost {
+		actions = append(append(make([]metadata.ActionType, 0), actions...), metadata.AuditHostTopoActions...)
+	}
+
+	cond := mapstr.MapStr{common.BKActionField: mapstr.MapStr{common.BKDBIN: actions}}
+	cond.Merge(instCond)
+	timeCond, err := parseOperationTimeCondition(ctx.Kit, opt.OperationTime)
+	if err != nil {
+		ctx.RespAutoError(err)
+		return
+	}
+	if len(timeCond) != 0 {
+		cond[common.BKOperationTimeField] = timeCond
+	}
+
+	auditLogs, err := s.searchAllAuditLogs(ctx.Kit, cond)
+	if err != nil {
+		ctx.RespAutoError(err)
+		return
+	}
+
+	if len(auditLogs) == 0 {
+		ctx.RespEntity(make([]metadata.AuditTimelineItem, 0))
+		return
+	}
+
+	// get the first data audit log after the end time to get the accurate data after the last audit log
+	var next *metadata.AuditLog
+	if len(opt.OperationTime.End) != 0 {
+		nextCond := mapstr.MapStr{
+			common.BKActionField:        mapstr.MapStr{common.BKDBIN: metadata.AuditDataActions},
+			common.BKOperationTimeField: mapstr.MapStr{common.BKDBGT: opt.OperationTime.End},
+		}
+		nextCond.Merge(instCond)
+		next, err = s.searchOneAuditLog(ctx.Kit, nextCond, common.BKFieldID)
+		if err != nil {
+			ctx.RespAutoError(err)
+			return
+		}
+	}
+
+	ctx.RespEntity(auditlog.BuildAuditTimeline(auditLogs, next, opt.Fields))
+}
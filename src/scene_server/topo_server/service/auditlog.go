@@ -223,6 +223,29 @@ func (s *Service) SearchAuditDetail(ctx *rest.Contexts) {
 	ctx.RespEntity(rsp.Info)
 }
 
+// VerifyAuditChain verify a range of the audit log hash chain, to find out the removed or edited audit logs
+func (s *Service) VerifyAuditChain(ctx *rest.Contexts) {
+	opt := new(metadata.VerifyAuditChainOption)
+	if err := ctx.DecodeInto(opt); err != nil {
+		ctx.RespAutoError(err)
+		return
+	}
+
+	if rawErr := opt.Validate(); rawErr.ErrCode != 0 {
+		ctx.RespAutoError(rawErr.ToCCError(ctx.Kit.CCError))
+		return
+	}
+
+	result, err := s.Engine.CoreAPI.CoreService().Audit().VerifyAuditChain(ctx.Kit.Ctx, ctx.Kit.Header, opt)
+	if err != nil {
+		blog.Errorf("verify audit chain failed, err: %v, opt: %+v, rid: %s", err, opt, ctx.Kit.Rid)
+		ctx.RespAutoError(err)
+		return
+	}
+
+	ctx.RespEntity(result)
+}
+
 func parseOperationTimeCondition(kit *rest.Kit, operationTime metadata.OperationTimeCondition) (map[string]interface{},
 	error) {
 	timeCond := make(map[string]interface{})
@@ -269,6 +269,129 @@ func (s *Service) DeleteInst(ctx *rest.Contexts) {
 	ctx.RespEntity(nil)
 }
 
+// PreviewCascadeDeleteInsts returns the instances that will be deleted in cascade with the instances by the
+// association on delete actions, in the form of trees whose roots are the instances to be deleted
+func (s *Service) PreviewCascadeDeleteInsts(ctx *rest.Contexts) {
+	objID := ctx.Request.PathParameter(common.BKObjIDField)
+
+	option := new(metadata.InstCascadeDeletePreviewOption)
+	if err := ctx.DecodeInto(option); err != nil {
+		ctx.RespAutoError(err)
+		return
+	}
+
+	if rawErr := option.Validate(); rawErr.ErrCode != 0 {
+		ctx.RespAutoError(rawErr.ToCCError(ctx.Kit.CCError))
+		return
+	}
+
+	result, err := s.Logics.InstOperation().PreviewCascadeDelete(ctx.Kit, objID, option.InstIDs)
+	if err != nil {
+		blog.Errorf("preview cascade delete failed, err: %v, objID: %s, instIDs: %v, rid: %s", err, objID,
+			option.InstIDs, ctx.Kit.Rid)
+		ctx.RespAutoError(err)
+		return
+	}
+
+	ctx.RespEntity(result)
+}
+
+// AnalyzeInstImpact returns what depends on the instances before maintenance, including the instances linked by the
+// dependency associations, and the topology, service instances, processes and owners of the related hosts
+func (s *Service) AnalyzeInstImpact(ctx *rest.Contexts) {
+	objID := ctx.Request.PathParameter(common.BKObjIDField)
+
+	option := new(metadata.InstImpactOption)
+	if err := ctx.DecodeInto(option); err != nil {
+		ctx.RespAutoError(err)
+		return
+	}
+
+	if rawErr := option.Validate(); rawErr.ErrCode != 0 {
+		ctx.RespAutoError(rawErr.ToCCError(ctx.Kit.CCError))
+		return
+	}
+
+	result, err := s.Logics.InstAssociationOperation().AnalyzeInstImpact(ctx.Kit, objID, option)
+	if err != nil {
+		blog.Errorf("analyze instance impact failed, err: %v, objID: %s, instIDs: %v, rid: %s", err, objID,
+			option.InstIDs, ctx.Kit.Rid)
+		ctx.RespAutoError(err)
+		return
+	}
+
+	ctx.RespEntity(result)
+}
+
+// RestoreInsts restore deleted set, module, host or custom object instances from the delete archive
+func (s *Service) RestoreInsts(ctx *rest.Contexts) {
+	objID := ctx.Request.PathParameter(common.BKObjIDField)
+
+	option := new(metadata.RestoreInstOption)
+	if err := ctx.DecodeInto(option); err != nil {
+		ctx.RespAutoError(err)
+		return
+	}
+
+	if rawErr := option.Validate(); rawErr.ErrCode != 0 {
+		ctx.RespAutoError(rawErr.ToCCError(ctx.Kit.CCError))
+		return
+	}
+
+	switch objID {
+	case common.BKInnerObjIDHost, common.BKInnerObjIDSet, common.BKInnerObjIDModule:
+	default:
+		if common.IsInnerModel(objID) {
+			blog.Errorf("restore %s instance is not supported, rid: %s", objID, ctx.Kit.Rid)
+			ctx.RespAutoError(ctx.Kit.CCError.CCErrorf(common.CCErrCommParamsInvalid, common.BKObjIDField))
+			return
+		}
+
+		exist, err := s.Logics.ObjectOperation().IsObjectExist(ctx.Kit, objID)
+		if err != nil {
+			blog.Errorf("failed to search the object(%s), err: %v, rid: %s", objID, err, ctx.Kit.Rid)
+			ctx.RespAutoError(err)
+			return
+		}
+
+		if !exist {
+			blog.Errorf("object(%s) is non-exist, rid: %s", objID, ctx.Kit.Rid)
+			ctx.RespAutoError(ctx.Kit.CCError.CCErrorf(common.CCErrCommParamsInvalid, common.BKObjIDField))
+			return
+		}
+	}
+
+	// the business of mainline instances is used for authorization, so it must be specified
+	isMainline, err := s.Logics.AssociationOperation().IsMainlineObject(ctx.Kit, objID)
+	if err != nil {
+		blog.Errorf("check whether model %s to be mainline failed, err: %v, rid: %s", objID, err, ctx.Kit.Rid)
+		ctx.RespAutoError(err)
+		return
+	}
+
+	if isMainline && option.BizID <= 0 {
+		ctx.RespAutoError(ctx.Kit.CCError.CCErrorf(common.CCErrCommParamsNeedSet, common.BKAppIDField))
+		return
+	}
+
+	var result *metadata.RestoreInstResult
+	txnErr := s.Engine.CoreAPI.CoreService().Txn().AutoRunTxn(ctx.Kit.Ctx, ctx.Kit.Header, func() error {
+		result, err = s.Logics.InstOperation().RestoreInst(ctx.Kit, objID, option)
+		if err != nil {
+			blog.Errorf("restore instance failed, err: %v, objID: %s, option: %#v, rid: %s", err, objID, option,
+				ctx.Kit.Rid)
+			return err
+		}
+		return nil
+	})
+
+	if txnErr != nil {
+		ctx.RespAutoError(txnErr)
+		return
+	}
+	ctx.RespEntity(result)
+}
+
 // UpdateInsts batch update insts
 func (s *Service) UpdateInsts(ctx *rest.Contexts) {
 	objID := ctx.Request.PathParameter("bk_obj_id")
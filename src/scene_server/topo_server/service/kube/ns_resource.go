@@ -0,0 +1,339 @@
+/*
+ * Tencent is pleased to support the open source community by making
+ * 蓝鲸智云 - 配置平台 (BlueKing - Configuration System) available.
+ * Copyright (C) 2017 THL A29 Limited,
+ * a Tencent company. All rights reserved.
+ * Licensed under the MIT License (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at http://opensource.org/licenses/MIT
+ * Unless required by applicable law or agreed to in writing,
+ * software distributed under the License is distributed on
+ * an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND,
+ * either express or implied. See the License for the
+ * specific language governing permissions and limitations under the License.
+ * We undertake not to change the open source license (MIT license) applicable
+ * to the current version of the project delivered to anyone in the future.
+ */
+
+package kube
+
+import (
+	acmeta "configcenter/src/ac/meta"
+	"configcenter/src/common"
+	"configcenter/src/common/auditlog"
+	"configcenter/src/common/blog"
+	"configcenter/src/common/http/rest"
+	"configcenter/src/common/mapstr"
+	"configcenter/src/common/metadata"
+	"configcenter/src/kube/types"
+)
+
+// services, ingresses, endpoints and config maps are namespace scoped resources that describe how the workloads in
+// the namespace are accessed and configured, so they are authorized by the namespace permissions.
+
+// CreateNsResource create namespace scoped resources
+func (s *service) CreateNsResource(ctx *rest.Contexts) {
+	kind := types.NsResourceType(ctx.Request.PathParameter(types.KindField))
+	if err := kind.Validate(); err != nil {
+		blog.Errorf("namespace resource kind is invalid, kind: %v, err: %v, rid: %s", kind, err, ctx.Kit.Rid)
+		ctx.RespAutoError(ctx.Kit.CCError.CCErrorf(common.CCErrCommParamsInvalid, types.KindField))
+		return
+	}
+
+	req := types.NsResCreateOption{Kind: kind}
+	if err := ctx.DecodeInto(&req); err != nil {
+		ctx.RespAutoError(err)
+		return
+	}
+
+	if rawErr := req.Validate(); rawErr.ErrCode != 0 {
+		ctx.RespAutoError(rawErr.ToCCError(ctx.Kit.CCError))
+		return
+	}
+
+	// authorize
+	authRes := acmeta.ResourceAttribute{Basic: acmeta.Basic{Type: acmeta.KubeNamespace, Action: acmeta.Update},
+		BusinessID: req.BizID}
+	if resp, authorized := s.AuthManager.Authorize(ctx.Kit, authRes); !authorized {
+		ctx.RespNoAuth(resp)
+		return
+	}
+
+	var data *metadata.RspIDs
+	txnErr := s.ClientSet.CoreService().Txn().AutoRunTxn(ctx.Kit.Ctx, ctx.Kit.Header, func() error {
+		res, err := s.ClientSet.CoreService().Kube().CreateNsResource(ctx.Kit.Ctx, ctx.Kit.Header, kind, req.Data)
+		if err != nil {
+			blog.Errorf("create %s failed, data: %v, err: %v, rid: %s", kind, req, err, ctx.Kit.Rid)
+			return err
+		}
+		data = res
+
+		for idx := range req.Data {
+			base := req.Data[idx].GetNsResourceBase()
+			base.ID = res.IDs[idx]
+			base.SupplierAccount = ctx.Kit.SupplierAccount
+			req.Data[idx].SetNsResourceBase(base)
+		}
+
+		return s.saveNsResourceAuditLog(ctx.Kit, metadata.AuditCreate, kind, req.Data, nil)
+	})
+
+	if txnErr != nil {
+		ctx.RespAutoError(txnErr)
+		return
+	}
+
+	ctx.RespEntity(data)
+}
+
+// UpdateNsResource update namespace scoped resources
+func (s *service) UpdateNsResource(ctx *rest.Contexts) {
+	kind := types.NsResourceType(ctx.Request.PathParameter(types.KindField))
+	if err := kind.Validate(); err != nil {
+		ctx.RespAutoError(ctx.Kit.CCError.CCErrorf(common.CCErrCommParamsInvalid, types.KindField))
+		return
+	}
+
+	req := new(types.NsResUpdateOption)
+	req.Kind = kind
+	if err := ctx.DecodeInto(req); err != nil {
+		ctx.RespAutoError(err)
+		return
+	}
+
+	if rawErr := req.Validate(); rawErr.ErrCode != 0 {
+		ctx.RespAutoError(rawErr.ToCCError(ctx.Kit.CCError))
+		return
+	}
+
+	// authorize
+	authRes := acmeta.ResourceAttribute{Basic: acmeta.Basic{Type: acmeta.KubeNamespace, Action: acmeta.Update},
+		BusinessID: req.BizID}
+	if resp, authorized := s.AuthManager.Authorize(ctx.Kit, authRes); !authorized {
+		ctx.RespNoAuth(resp)
+		return
+	}
+
+	resources, err := s.checkNsResourceData(ctx.Kit, req.BizID, req.IDs, kind)
+	if err != nil {
+		ctx.RespAutoError(err)
+		return
+	}
+
+	if len(resources) == 0 {
+		blog.Errorf("no %s founded, bizID: %d, ids: %v, rid: %s", kind, req.BizID, req.IDs, ctx.Kit.Rid)
+		ctx.RespAutoError(ctx.Kit.CCError.CCError(common.CCErrCommNotFound))
+		return
+	}
+
+	txnErr := s.ClientSet.CoreService().Txn().AutoRunTxn(ctx.Kit.Ctx, ctx.Kit.Header, func() error {
+		err := s.ClientSet.CoreService().Kube().UpdateNsResource(ctx.Kit.Ctx, ctx.Kit.Header, kind,
+			&req.NsResUpdateByIDsOption)
+		if err != nil {
+			blog.Errorf("update %s failed, data: %v, err: %v, rid: %s", kind, req, err, ctx.Kit.Rid)
+			return err
+		}
+
+		updateFields, goErr := mapstr.Struct2Map(req.Data)
+		if goErr != nil {
+			blog.Errorf("update fields convert failed, err: %v, rid: %s", goErr, ctx.Kit.Rid)
+			return goErr
+		}
+
+		return s.saveNsResourceAuditLog(ctx.Kit, metadata.AuditUpdate, kind, resources, updateFields)
+	})
+
+	if txnErr != nil {
+		ctx.RespAutoError(txnErr)
+		return
+	}
+
+	ctx.RespEntity(nil)
+}
+
+// DeleteNsResource delete namespace scoped resources
+func (s *service) DeleteNsResource(ctx *rest.Contexts) {
+	kind := types.NsResourceType(ctx.Request.PathParameter(types.KindField))
+	if err := kind.Validate(); err != nil {
+		ctx.RespAutoError(ctx.Kit.CCError.CCErrorf(common.CCErrCommParamsInvalid, types.KindField))
+		return
+	}
+
+	req := new(types.NsResDeleteOption)
+	if err := ctx.DecodeInto(req); err != nil {
+		ctx.RespAutoError(err)
+		return
+	}
+
+	if rawErr := req.Validate(); rawErr.ErrCode != 0 {
+		ctx.RespAutoError(rawErr.ToCCError(ctx.Kit.CCError))
+		return
+	}
+
+	// authorize
+	authRes := acmeta.ResourceAttribute{Basic: acmeta.Basic{Type: acmeta.KubeNamespace, Action: acmeta.Update},
+		BusinessID: req.BizID}
+	if resp, authorized := s.AuthManager.Authorize(ctx.Kit, authRes); !authorized {
+		ctx.RespNoAuth(resp)
+		return
+	}
+
+	resources, err := s.checkNsResourceData(ctx.Kit, req.BizID, req.IDs, kind)
+	if err != nil {
+		ctx.RespAutoError(err)
+		return
+	}
+
+	// if all resources are already deleted, return
+	if len(resources) == 0 {
+		ctx.RespEntity(nil)
+		return
+	}
+
+	txnErr := s.ClientSet.CoreService().Txn().AutoRunTxn(ctx.Kit.Ctx, ctx.Kit.Header, func() error {
+		err := s.ClientSet.CoreService().Kube().DeleteNsResource(ctx.Kit.Ctx, ctx.Kit.Header, kind,
+			&req.NsResDeleteByIDsOption)
+		if err != nil {
+			blog.Errorf("delete %s failed, data: %v, err: %v, rid: %s", kind, req, err, ctx.Kit.Rid)
+			return err
+		}
+
+		return s.saveNsResourceAuditLog(ctx.Kit, metadata.AuditDelete, kind, resources, nil)
+	})
+
+	if txnErr != nil {
+		ctx.RespAutoError(txnErr)
+		return
+	}
+
+	ctx.RespEntity(nil)
+}
+
+func (s *service) saveNsResourceAuditLog(kit *rest.Kit, action metadata.ActionType, kind types.NsResourceType,
+	resources []types.NsResourceInterface, updateFields map[string]interface{}) error {
+
+	audit := auditlog.NewKubeAudit(s.ClientSet.CoreService())
+	auditParam := auditlog.NewGenerateAuditCommonParameter(kit, action)
+	if action == metadata.AuditUpdate {
+		auditParam.WithUpdateFields(updateFields)
+	}
+
+	auditLogs, err := audit.GenerateNsResourceAuditLog(auditParam, resources, kind)
+	if err != nil {
+		blog.Errorf("generate %s audit log failed, data: %v, err: %v, rid: %s", kind, resources, err, kit.Rid)
+		return err
+	}
+
+	if err := audit.SaveAuditLog(kit, auditLogs...); err != nil {
+		blog.Errorf("save %s audit log failed, data: %v, err: %v, rid: %s", kind, resources, err, kit.Rid)
+		return err
+	}
+
+	return nil
+}
+
+// checkNsResourceData get the namespace resources by ids, and checks if their namespaces are shared namespaces when
+// their biz ids are not the same with the input biz id
+func (s *service) checkNsResourceData(kit *rest.Kit, bizID int64, ids []int64, kind types.NsResourceType) (
+	[]types.NsResourceInterface, error) {
+
+	query := &metadata.QueryCondition{
+		Condition: mapstr.MapStr{common.BKFieldID: mapstr.MapStr{common.BKDBIN: ids}},
+		Page:      metadata.BasePage{Limit: common.BKNoLimit},
+	}
+	resp, err := s.ClientSet.CoreService().Kube().ListNsResource(kit.Ctx, kit.Header, query, kind)
+	if err != nil {
+		blog.Errorf("list %s failed, bizID: %d, ids: %+v, err: %v, rid: %s", kind, bizID, ids, err, kit.Rid)
+		return nil, err
+	}
+
+	mismatchNsIDs := make([]int64, 0)
+	for _, res := range resp.Info {
+		base := res.GetNsResourceBase()
+		if base.BizID != bizID {
+			mismatchNsIDs = append(mismatchNsIDs, base.NamespaceID)
+		}
+	}
+
+	if len(mismatchNsIDs) > 0 {
+		mismatchNsMap := map[int64][]int64{bizID: mismatchNsIDs}
+		if err := s.Logics.KubeOperation().CheckPlatBizSharedNs(kit, mismatchNsMap); err != nil {
+			return nil, err
+		}
+	}
+
+	return resp.Info, nil
+}
+
+// ListNsResource list namespace scoped resources
+func (s *service) ListNsResource(ctx *rest.Contexts) {
+	kind := types.NsResourceType(ctx.Request.PathParameter(types.KindField))
+	table, err := kind.Table()
+	if err != nil {
+		ctx.RespAutoError(ctx.Kit.CCError.CCErrorf(common.CCErrCommParamsInvalid, types.KindField))
+		return
+	}
+
+	req := new(types.NsResQueryOption)
+	if err := ctx.DecodeInto(req); err != nil {
+		ctx.RespAutoError(err)
+		return
+	}
+
+	if rawErr := req.Validate(kind); rawErr.ErrCode != 0 {
+		ctx.RespAutoError(rawErr.ToCCError(ctx.Kit.CCError))
+		return
+	}
+
+	// authorize
+	authRes := acmeta.ResourceAttribute{Basic: acmeta.Basic{Type: acmeta.KubeNamespace, Action: acmeta.Find},
+		BusinessID: req.BizID}
+	if resp, authorized := s.AuthManager.Authorize(ctx.Kit, authRes); !authorized {
+		ctx.RespNoAuth(resp)
+		return
+	}
+
+	// compatible for shared cluster scenario
+	cond, err := s.Logics.KubeOperation().GenSharedNsListCond(ctx.Kit, types.KubeNsResource, req.BizID, req.Filter)
+	if err != nil {
+		ctx.RespAutoError(err)
+		return
+	}
+
+	if req.Page.EnableCount {
+		counts, err := s.ClientSet.CoreService().Count().GetCountByFilter(ctx.Kit.Ctx, ctx.Kit.Header, table,
+			[]map[string]interface{}{cond})
+		if err != nil {
+			blog.Errorf("count %s failed, cond: %v, err: %v, rid: %s", kind, cond, err, ctx.Kit.Rid)
+			ctx.RespAutoError(err)
+			return
+		}
+		ctx.RespEntityWithCount(counts[0], make([]mapstr.MapStr, 0))
+		return
+	}
+
+	if req.Page.Sort == "" {
+		req.Page.Sort = common.BKFieldID
+	}
+
+	query := &metadata.QueryCondition{
+		Condition: cond,
+		Page:      req.Page,
+		Fields:    req.Fields,
+	}
+
+	resp, err := s.ClientSet.CoreService().Kube().ListNsResource(ctx.Kit.Ctx, ctx.Kit.Header, query, kind)
+	if err != nil {
+		blog.Errorf("list %s failed, bizID: %d, cond: %v, err: %v, rid: %s", kind, req.BizID, query, err,
+			ctx.Kit.Rid)
+		ctx.RespAutoError(err)
+		return
+	}
+
+	if len(resp.Info) == 0 {
+		ctx.RespEntityWithCount(0, []mapstr.MapStr{})
+		return
+	}
+
+	ctx.RespEntityWithCount(0, resp.Info)
+}
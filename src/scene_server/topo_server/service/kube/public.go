@@ -654,12 +654,14 @@ func (s *service) hasNextLevelResource(kit *rest.Kit, kind string, ids []int64)
 		tables = []string{types.BKTableNameBaseNamespace, types.BKTableNameBaseNode, types.BKTableNameBasePod}
 		workLoads := types.GetWorkLoadTables()
 		tables = append(tables, workLoads...)
+		tables = append(tables, types.GetNsResourceTables()...)
 		filter[types.BKClusterIDFiled] = map[string]interface{}{common.BKDBIN: ids}
 
 	case types.KubeNamespace:
 		tables = []string{types.BKTableNameBasePod}
 		workLoads := types.GetWorkLoadTables()
 		tables = append(tables, workLoads...)
+		tables = append(tables, types.GetNsResourceTables()...)
 		filter[types.BKNamespaceIDField] = map[string]interface{}{common.BKDBIN: ids}
 
 	default:
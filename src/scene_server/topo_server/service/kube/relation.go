@@ -0,0 +1,515 @@
+/*
+ * Tencent is pleased to support the open source community by making
+ * 蓝鲸智云 - 配置平台 (BlueKing - Configuration System) available.
+ * Copyright (C) 2017 THL A29 Limited,
+ * a Tencent company. All rights reserved.
+ * Licensed under the MIT License (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at http://opensource.org/licenses/MIT
+ * Unless required by applicable law or agreed to in writing,
+ * software distributed under the License is distributed on
+ * an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND,
+ * either express or implied. See the License for the
+ * specific language governing permissions and limitations under the License.
+ * We undertake not to change the open source license (MIT license) applicable
+ * to the current version of the project delivered to anyone in the future.
+ */
+
+package kube
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"configcenter/pkg/filter"
+	filtertools "configcenter/pkg/tools/filter"
+	acmeta "configcenter/src/ac/meta"
+	"configcenter/src/common"
+	"configcenter/src/common/blog"
+	"configcenter/src/common/http/rest"
+	"configcenter/src/common/mapstr"
+	"configcenter/src/common/metadata"
+	"configcenter/src/common/util"
+	"configcenter/src/kube/types"
+)
+
+// FindKubeRelation find the related services, ingresses, endpoints, config maps, workloads and pods of the
+// namespace scoped resources, workloads or pods, and the ingress hosts that route traffic to them.
+func (s *service) FindKubeRelation(ctx *rest.Contexts) {
+	kind := ctx.Request.PathParameter(types.KindField)
+	if !types.IsKubeRelationKind(kind) {
+		ctx.RespAutoError(ctx.Kit.CCError.CCErrorf(common.CCErrCommParamsInvalid, types.KindField))
+		return
+	}
+
+	req := new(types.KubeRelationOption)
+	if err := ctx.DecodeInto(req); err != nil {
+		ctx.RespAutoError(err)
+		return
+	}
+
+	if rawErr := req.Validate(); rawErr.ErrCode != 0 {
+		ctx.RespAutoError(rawErr.ToCCError(ctx.Kit.CCError))
+		return
+	}
+
+	// authorize
+	authRes := acmeta.ResourceAttribute{Basic: acmeta.Basic{Type: acmeta.Business, Action: acmeta.ViewBusinessResource,
+		InstanceID: req.BizID}}
+	if resp, authorized := s.AuthManager.Authorize(ctx.Kit, authRes); !authorized {
+		ctx.RespNoAuth(resp)
+		return
+	}
+
+	targets, err := s.getRelationTargets(ctx.Kit, kind, req)
+	if err != nil {
+		ctx.RespAutoError(err)
+		return
+	}
+
+	if len(targets) == 0 {
+		ctx.RespEntity(make([]types.KubeRelation, 0))
+		return
+	}
+
+	nsIDs := make([]int64, 0)
+	for _, target := range targets {
+		nsIDs = append(nsIDs, target.namespaceID)
+	}
+
+	nsResources, err := s.getNsRelationResources(ctx.Kit, util.IntArrayUnique(nsIDs))
+	if err != nil {
+		ctx.RespAutoError(err)
+		return
+	}
+
+	relations := make([]types.KubeRelation, len(targets))
+	for idx, target := range targets {
+		res, exists := nsResources[target.namespaceID]
+		if !exists {
+			res = new(nsRelationResources)
+		}
+		relations[idx] = res.findRelation(target)
+	}
+
+	ctx.RespEntity(relations)
+}
+
+// relationTarget the resource to find relations for
+type relationTarget struct {
+	kind        string
+	id          int64
+	name        string
+	namespaceID int64
+	// selector the label selector of the workload
+	selector *types.LabelSelector
+	// pod the target pod
+	pod *types.Pod
+	// nsResource the target namespace scoped resource
+	nsResource types.NsResourceInterface
+}
+
+// getRelationTargets get the resources to find relations for
+func (s *service) getRelationTargets(kit *rest.Kit, kind string, req *types.KubeRelationOption) ([]relationTarget,
+	error) {
+
+	idCond := filtertools.GenAtomFilter(common.BKFieldID, filter.In, req.IDs)
+	targets := make([]relationTarget, 0)
+
+	switch {
+	case kind == types.KubePod:
+		cond, err := s.Logics.KubeOperation().GenSharedNsListCond(kit, types.KubePod, req.BizID, idCond)
+		if err != nil {
+			return nil, err
+		}
+
+		pods, err := s.listRelationPods(kit, cond)
+		if err != nil {
+			return nil, err
+		}
+
+		for idx := range pods {
+			targets = append(targets, relationTarget{kind: kind, id: pods[idx].ID, name: podName(pods[idx].Name),
+				namespaceID: pods[idx].NamespaceID, pod: &pods[idx]})
+		}
+
+	case types.NsResourceType(kind).Validate() == nil:
+		cond, err := s.Logics.KubeOperation().GenSharedNsListCond(kit, types.KubeNsResource, req.BizID, idCond)
+		if err != nil {
+			return nil, err
+		}
+
+		query := &metadata.QueryCondition{Condition: cond, Page: metadata.BasePage{Limit: common.BKNoLimit}}
+		resp, err := s.ClientSet.CoreService().Kube().ListNsResource(kit.Ctx, kit.Header, query,
+			types.NsResourceType(kind))
+		if err != nil {
+			blog.Errorf("list %s failed, cond: %v, err: %v, rid: %s", kind, cond, err, kit.Rid)
+			return nil, err
+		}
+
+		for _, res := range resp.Info {
+			base := res.GetNsResourceBase()
+			targets = append(targets, relationTarget{kind: kind, id: base.ID, name: base.Name,
+				namespaceID: base.NamespaceID, nsResource: res})
+		}
+
+	default:
+		cond, err := s.Logics.KubeOperation().GenSharedNsListCond(kit, types.KubeWorkload, req.BizID, idCond)
+		if err != nil {
+			return nil, err
+		}
+
+		query := &metadata.QueryCondition{Condition: cond, Page: metadata.BasePage{Limit: common.BKNoLimit}}
+		resp, err := s.ClientSet.CoreService().Kube().ListWorkload(kit.Ctx, kit.Header, query,
+			types.WorkloadType(kind))
+		if err != nil {
+			blog.Errorf("list %s failed, cond: %v, err: %v, rid: %s", kind, cond, err, kit.Rid)
+			return nil, err
+		}
+
+		for _, wl := range resp.Info {
+			selector, err := getWorkloadSelector(wl)
+			if err != nil {
+				blog.Errorf("get %s selector failed, workload: %+v, err: %v, rid: %s", kind, wl, err, kit.Rid)
+				return nil, err
+			}
+
+			base := wl.GetWorkloadBase()
+			targets = append(targets, relationTarget{kind: kind, id: base.ID, name: base.Name,
+				namespaceID: base.NamespaceID, selector: selector})
+		}
+	}
+
+	return targets, nil
+}
+
+// getWorkloadSelector get the label selector of the workload, all workload types store it in the selector field
+func getWorkloadSelector(wl types.WorkloadInterface) (*types.LabelSelector, error) {
+	js, err := json.Marshal(wl)
+	if err != nil {
+		return nil, err
+	}
+
+	data := struct {
+		Selector *types.LabelSelector `json:"selector"`
+	}{}
+	if err = json.Unmarshal(js, &data); err != nil {
+		return nil, err
+	}
+
+	return data.Selector, nil
+}
+
+// listRelationPods list the pods with the fields that are used to find relations
+func (s *service) listRelationPods(kit *rest.Kit, cond mapstr.MapStr) ([]types.Pod, error) {
+	query := &metadata.QueryCondition{
+		Condition: cond,
+		Fields: []string{common.BKFieldID, common.BKFieldName, common.BKAppIDField, types.BKNamespaceIDField,
+			types.LabelsField, types.VolumesField, types.RefField},
+		Page: metadata.BasePage{Limit: common.BKNoLimit},
+	}
+
+	resp, err := s.ClientSet.CoreService().Kube().ListPod(kit.Ctx, kit.Header, query)
+	if err != nil {
+		blog.Errorf("list pod failed, cond: %v, err: %v, rid: %s", cond, err, kit.Rid)
+		return nil, err
+	}
+
+	return resp.Info, nil
+}
+
+// nsRelationResources the resources in a namespace that are used to find relations
+type nsRelationResources struct {
+	services   []*types.Service
+	ingresses  []*types.Ingress
+	endpoints  []*types.Endpoints
+	configMaps []*types.ConfigMap
+	pods       []types.Pod
+}
+
+// getNsRelationResources get the resources in the namespaces that are used to find relations
+func (s *service) getNsRelationResources(kit *rest.Kit, nsIDs []int64) (map[int64]*nsRelationResources, error) {
+	cond := mapstr.MapStr{types.BKNamespaceIDField: mapstr.MapStr{common.BKDBIN: nsIDs}}
+	nsResources := make(map[int64]*nsRelationResources)
+	getNsResources := func(nsID int64) *nsRelationResources {
+		if _, exists := nsResources[nsID]; !exists {
+			nsResources[nsID] = new(nsRelationResources)
+		}
+		return nsResources[nsID]
+	}
+
+	for _, kind := range []types.NsResourceType{types.KubeService, types.KubeIngress, types.KubeEndpoints,
+		types.KubeConfigMap} {
+
+		query := &metadata.QueryCondition{Condition: cond, Page: metadata.BasePage{Limit: common.BKNoLimit}}
+		resp, err := s.ClientSet.CoreService().Kube().ListNsResource(kit.Ctx, kit.Header, query, kind)
+		if err != nil {
+			blog.Errorf("list %s failed, cond: %v, err: %v, rid: %s", kind, cond, err, kit.Rid)
+			return nil, err
+		}
+
+		for _, res := range resp.Info {
+			nsRes := getNsResources(res.GetNsResourceBase().NamespaceID)
+			switch data := res.(type) {
+			case *types.Service:
+				nsRes.services = append(nsRes.services, data)
+			case *types.Ingress:
+				nsRes.ingresses = append(nsRes.ingresses, data)
+			case *types.Endpoints:
+				nsRes.endpoints = append(nsRes.endpoints, data)
+			case *types.ConfigMap:
+				nsRes.configMaps = append(nsRes.configMaps, data)
+			}
+		}
+	}
+
+	pods, err := s.listRelationPods(kit, cond)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, pod := range pods {
+		nsRes := getNsResources(pod.NamespaceID)
+		nsRes.pods = append(nsRes.pods, pod)
+	}
+
+	return nsResources, nil
+}
+
+// findRelation find the relation of the target resource in the namespace
+func (n *nsRelationResources) findRelation(target relationTarget) types.KubeRelation {
+	b := newRelationBuilder(target)
+
+	switch target.kind {
+	case types.KubePod:
+		b.addPod(target.pod)
+		n.addPodsRelated([]*types.Pod{target.pod}, b)
+
+	case string(types.KubeService):
+		svc := target.nsResource.(*types.Service)
+		for idx := range n.pods {
+			if svc.MatchPod(podLabels(&n.pods[idx])) {
+				b.addPod(&n.pods[idx])
+			}
+		}
+		n.addServiceRelated(svc, b)
+
+	case string(types.KubeIngress):
+		ingress := target.nsResource.(*types.Ingress)
+		for _, name := range ingress.GetServiceNames() {
+			for _, svc := range n.services {
+				if svc.Name != name {
+					continue
+				}
+				b.addObject(string(types.KubeService), svc.ID, svc.Name)
+				for idx := range n.pods {
+					if svc.MatchPod(podLabels(&n.pods[idx])) {
+						b.addPod(&n.pods[idx])
+					}
+				}
+				n.addServiceRelated(svc, b)
+			}
+		}
+
+	case string(types.KubeEndpoints):
+		endpoints := target.nsResource.(*types.Endpoints)
+		for _, svc := range n.services {
+			if svc.Name == endpoints.Name {
+				b.addObject(string(types.KubeService), svc.ID, svc.Name)
+				n.addServiceRelated(svc, b)
+			}
+		}
+
+		podNames := make(map[string]struct{})
+		for _, name := range endpoints.GetPodNames() {
+			podNames[name] = struct{}{}
+		}
+		for idx := range n.pods {
+			if _, exists := podNames[podName(n.pods[idx].Name)]; exists {
+				b.addPod(&n.pods[idx])
+			}
+		}
+
+	case string(types.KubeConfigMap):
+		configMap := target.nsResource.(*types.ConfigMap)
+		for idx := range n.pods {
+			if n.pods[idx].Volumes != nil && configMap.IsMountedBy(*n.pods[idx].Volumes) {
+				b.addPod(&n.pods[idx])
+			}
+		}
+
+	default:
+		n.addWorkloadRelated(target, b)
+	}
+
+	return b.relation
+}
+
+// addWorkloadRelated add the pods of the workload and their related resources, if the workload has no pod, the
+// services are associated with the workload by its selector, which is also the labels of the pods to be created.
+func (n *nsRelationResources) addWorkloadRelated(target relationTarget, b *relationBuilder) {
+	pods := make([]*types.Pod, 0)
+	for idx := range n.pods {
+		ref := n.pods[idx].Ref
+		if ref != nil && string(ref.Kind) == target.kind && ref.ID == target.id {
+			b.addPod(&n.pods[idx])
+			pods = append(pods, &n.pods[idx])
+		}
+	}
+
+	if len(pods) > 0 {
+		n.addPodsRelated(pods, b)
+		return
+	}
+
+	if target.selector == nil || len(target.selector.MatchLabels) == 0 {
+		return
+	}
+
+	for _, svc := range n.services {
+		if svc.MatchPod(target.selector.MatchLabels) {
+			b.addObject(string(types.KubeService), svc.ID, svc.Name)
+			n.addServiceRelated(svc, b)
+		}
+	}
+}
+
+// addPodsRelated add the services that select the pods and the config maps that are mounted by the pods
+func (n *nsRelationResources) addPodsRelated(pods []*types.Pod, b *relationBuilder) {
+	for _, svc := range n.services {
+		for _, pod := range pods {
+			if svc.MatchPod(podLabels(pod)) {
+				b.addObject(string(types.KubeService), svc.ID, svc.Name)
+				n.addServiceRelated(svc, b)
+				break
+			}
+		}
+	}
+
+	for _, configMap := range n.configMaps {
+		for _, pod := range pods {
+			if pod.Volumes != nil && configMap.IsMountedBy(*pod.Volumes) {
+				b.addObject(string(types.KubeConfigMap), configMap.ID, configMap.Name)
+				break
+			}
+		}
+	}
+}
+
+// addServiceRelated add the endpoints of the service, and the ingresses that route traffic to the service with
+// their hosts
+func (n *nsRelationResources) addServiceRelated(svc *types.Service, b *relationBuilder) {
+	for _, endpoints := range n.endpoints {
+		if endpoints.Name == svc.Name {
+			b.addObject(string(types.KubeEndpoints), endpoints.ID, endpoints.Name)
+		}
+	}
+
+	for _, ingress := range n.ingresses {
+		if !util.InStrArr(ingress.GetServiceNames(), svc.Name) {
+			continue
+		}
+
+		b.addObject(string(types.KubeIngress), ingress.ID, ingress.Name)
+		for _, host := range ingress.GetServiceHosts(svc.Name) {
+			b.addHost(host)
+		}
+	}
+}
+
+func podName(name *string) string {
+	if name == nil {
+		return ""
+	}
+	return *name
+}
+
+func podLabels(pod *types.Pod) map[string]string {
+	if pod.Labels == nil {
+		return make(map[string]string)
+	}
+	return *pod.Labels
+}
+
+// relationBuilder build the relation of the target resource without duplicate resources
+type relationBuilder struct {
+	relation types.KubeRelation
+	exists   map[string]struct{}
+}
+
+func newRelationBuilder(target relationTarget) *relationBuilder {
+	b := &relationBuilder{
+		relation: types.KubeRelation{
+			Kind:       target.kind,
+			ID:         target.id,
+			Name:       target.name,
+			Services:   make([]types.KubeObjectInfo, 0),
+			Ingresses:  make([]types.KubeObjectInfo, 0),
+			Endpoints:  make([]types.KubeObjectInfo, 0),
+			ConfigMaps: make([]types.KubeObjectInfo, 0),
+			Workloads:  make([]types.KubeObjectInfo, 0),
+			Pods:       make([]types.KubeObjectInfo, 0),
+			Hosts:      make([]string, 0),
+		},
+		exists: make(map[string]struct{}),
+	}
+
+	// the target itself is not its related resource
+	b.exists[relationKey(target.kind, target.id)] = struct{}{}
+	if target.kind == string(types.KubeIngress) {
+		ingress := target.nsResource.(*types.Ingress)
+		if ingress.Rules != nil {
+			for _, rule := range *ingress.Rules {
+				if rule.Host != "" {
+					b.addHost(rule.Host)
+				}
+			}
+		}
+	}
+	return b
+}
+
+func relationKey(kind string, id int64) string {
+	return fmt.Sprintf("%s:%d", kind, id)
+}
+
+// addPod add the pod and its workload
+func (b *relationBuilder) addPod(pod *types.Pod) {
+	b.addObject(types.KubePod, pod.ID, podName(pod.Name))
+	if pod.Ref != nil && pod.Ref.ID != 0 {
+		b.addObject(string(pod.Ref.Kind), pod.Ref.ID, pod.Ref.Name)
+	}
+}
+
+func (b *relationBuilder) addObject(kind string, id int64, name string) {
+	key := relationKey(kind, id)
+	if _, exists := b.exists[key]; exists {
+		return
+	}
+	b.exists[key] = struct{}{}
+
+	obj := types.KubeObjectInfo{ID: id, Name: name, Kind: kind}
+	switch kind {
+	case string(types.KubeService):
+		b.relation.Services = append(b.relation.Services, obj)
+	case string(types.KubeIngress):
+		b.relation.Ingresses = append(b.relation.Ingresses, obj)
+	case string(types.KubeEndpoints):
+		b.relation.Endpoints = append(b.relation.Endpoints, obj)
+	case string(types.KubeConfigMap):
+		b.relation.ConfigMaps = append(b.relation.ConfigMaps, obj)
+	case types.KubePod:
+		b.relation.Pods = append(b.relation.Pods, obj)
+	default:
+		b.relation.Workloads = append(b.relation.Workloads, obj)
+	}
+}
+
+func (b *relationBuilder) addHost(host string) {
+	key := "host:" + host
+	if _, exists := b.exists[key]; exists {
+		return
+	}
+	b.exists[key] = struct{}{}
+	b.relation.Hosts = append(b.relation.Hosts, host)
+}
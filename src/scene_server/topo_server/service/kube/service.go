@@ -68,6 +68,18 @@ func InitKube(utility *rest.RestUtility, c *capability.Capability) {
 	utility.AddHandler(rest.Action{Verb: http.MethodPost, Path: "/findmany/kube/workload/{kind}",
 		Handler: s.ListWorkload})
 
+	// namespace scoped resource
+	utility.AddHandler(rest.Action{Verb: http.MethodPost, Path: "/createmany/kube/ns_resource/{kind}",
+		Handler: s.CreateNsResource})
+	utility.AddHandler(rest.Action{Verb: http.MethodPut, Path: "/updatemany/kube/ns_resource/{kind}",
+		Handler: s.UpdateNsResource})
+	utility.AddHandler(rest.Action{Verb: http.MethodDelete, Path: "/deletemany/kube/ns_resource/{kind}",
+		Handler: s.DeleteNsResource})
+	utility.AddHandler(rest.Action{Verb: http.MethodPost, Path: "/findmany/kube/ns_resource/{kind}",
+		Handler: s.ListNsResource})
+	utility.AddHandler(rest.Action{Verb: http.MethodPost, Path: "/find/kube/relation/{kind}",
+		Handler: s.FindKubeRelation})
+
 	// topo
 	utility.AddHandler(rest.Action{Verb: http.MethodPost, Path: "/find/kube/host_node_path",
 		Handler: s.FindNodePathForHost})
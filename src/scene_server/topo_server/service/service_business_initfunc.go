@@ -190,6 +190,8 @@ func (s *Service) initBusinessAssociation(web *restful.WebService) {
 		Handler: s.SearchAssociationInst})
 	utility.AddHandler(rest.Action{Verb: http.MethodPost, Path: "/find/instassociation/related",
 		Handler: s.SearchAssociationRelatedInst})
+	utility.AddHandler(rest.Action{Verb: http.MethodPost, Path: "/find/instassociation/graph",
+		Handler: s.SearchInstGraph})
 	utility.AddHandler(rest.Action{Verb: http.MethodPost, Path: "/create/instassociation",
 		Handler: s.CreateAssociationInst})
 	utility.AddHandler(rest.Action{Verb: http.MethodPost, Path: "/createmany/instassociation",
@@ -243,6 +245,12 @@ func (s *Service) initBusinessInst(web *restful.WebService) {
 		Handler: s.DeleteInst})
 	utility.AddHandler(rest.Action{Verb: http.MethodDelete, Path: "/deletemany/instance/object/{bk_obj_id}",
 		Handler: s.DeleteInsts})
+	utility.AddHandler(rest.Action{Verb: http.MethodPost, Path: "/restore/instance/object/{bk_obj_id}",
+		Handler: s.RestoreInsts})
+	utility.AddHandler(rest.Action{Verb: http.MethodPost,
+		Path: "/find/instance/object/{bk_obj_id}/cascade_delete/preview", Handler: s.PreviewCascadeDeleteInsts})
+	utility.AddHandler(rest.Action{Verb: http.MethodPost, Path: "/find/instance/object/{bk_obj_id}/impact",
+		Handler: s.AnalyzeInstImpact})
 	utility.AddHandler(rest.Action{Verb: http.MethodPut, Path: "/update/instance/object/{bk_obj_id}/inst/{inst_id}",
 		Handler: s.UpdateInst})
 	utility.AddHandler(rest.Action{Verb: http.MethodPut, Path: "/updatemany/instance/object/{bk_obj_id}",
@@ -53,6 +53,14 @@ func (s *Service) initAuditLog(web *restful.WebService) {
 	utility.AddHandler(rest.Action{Verb: http.MethodPost, Path: "/findmany/audit_list", Handler: s.SearchAuditList})
 	utility.AddHandler(rest.Action{Verb: http.MethodPost, Path: "/find/audit", Handler: s.SearchAuditDetail})
 	utility.AddHandler(rest.Action{Verb: http.MethodPost, Path: "/find/inst_audit", Handler: s.SearchInstAudit})
+	utility.AddHandler(rest.Action{Verb: http.MethodPost, Path: "/find/audit_snapshot",
+		Handler: s.SearchAuditSnapshot})
+	utility.AddHandler(rest.Action{Verb: http.MethodPost, Path: "/find/audit_biz_topo_snapshot",
+		Handler: s.SearchBizTopoAuditSnapshot})
+	utility.AddHandler(rest.Action{Verb: http.MethodPost, Path: "/find/audit_timeline",
+		Handler: s.SearchAuditTimeline})
+	utility.AddHandler(rest.Action{Verb: http.MethodPost, Path: "/find/audit_chain/verify",
+		Handler: s.VerifyAuditChain})
 
 	utility.AddToRestfulWebService(web)
 }
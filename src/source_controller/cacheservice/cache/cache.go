@@ -18,6 +18,7 @@ import (
 
 	"configcenter/src/apimachinery/discovery"
 	"configcenter/src/source_controller/cacheservice/cache/host"
+	"configcenter/src/source_controller/cacheservice/cache/instance"
 	"configcenter/src/source_controller/cacheservice/cache/mainline"
 	"configcenter/src/source_controller/cacheservice/cache/topology"
 	"configcenter/src/source_controller/cacheservice/cache/topotree"
@@ -41,6 +42,10 @@ func NewCache(reflector reflector.Interface, loopW stream.LoopInterface, isMaste
 		return nil, fmt.Errorf("new host cache failed, err: %v", err)
 	}
 
+	if err := instance.NewInstanceCache(loopW); err != nil {
+		return nil, fmt.Errorf("new instance cache failed, err: %v", err)
+	}
+
 	topo, err := topology.NewTopology(isMaster, loopW)
 	if err != nil {
 		return nil, err
@@ -54,6 +59,7 @@ func NewCache(reflector reflector.Interface, loopW stream.LoopInterface, isMaste
 		Host:     hostClient,
 		Business: mainlineClient,
 		Topology: topo,
+		Instance: instance.NewClient(),
 		Event:    watch.NewClient(watchDB, mongodb.Client(), redis.Client()),
 	}
 	return cache, nil
@@ -65,5 +71,6 @@ type ClientSet struct {
 	Topology *topology.Topology
 	Host     *host.Client
 	Business *mainline.Client
+	Instance *instance.Client
 	Event    *watch.Client
 }
@@ -0,0 +1,224 @@
+/*
+ * Tencent is pleased to support the open source community by making 蓝鲸 available.
+ * Copyright (C) 2017-2018 THL A29 Limited, a Tencent company. All rights reserved.
+ * Licensed under the MIT License (the "License"); you may not use this file except
+ * in compliance with the License. You may obtain a copy of the License at
+ * http://opensource.org/licenses/MIT
+ * Unless required by applicable law or agreed to in writing, software distributed under
+ * the License is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND,
+ * either express or implied. See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package instance
+
+import (
+	"context"
+	"fmt"
+	"sync"
+
+	"configcenter/src/common"
+	"configcenter/src/common/blog"
+	"configcenter/src/common/json"
+	"configcenter/src/common/metadata"
+	"configcenter/src/common/util"
+	"configcenter/src/storage/dal"
+	"configcenter/src/storage/dal/redis"
+	"configcenter/src/storage/driver/mongodb"
+	drvredis "configcenter/src/storage/driver/redis"
+)
+
+var client *Client
+var clientOnce sync.Once
+
+// NewClient new an instance cache client, which is used to get the instances from cache, the instances of the
+// resources that are not opted in are got from db directly.
+// this client can only be initialized for once.
+func NewClient() *Client {
+	if client != nil {
+		return client
+	}
+
+	clientOnce.Do(func() {
+		client = &Client{
+			rds: drvredis.Client(),
+			db:  mongodb.Client(),
+		}
+	})
+
+	return client
+}
+
+// Client is the instance cache client
+type Client struct {
+	rds redis.Client
+	db  dal.DB
+}
+
+// ListInstances list the object's instances with id list, the instances that do not exist are skipped.
+func (c *Client) ListInstances(ctx context.Context, objID, supplierAccount string, opt *metadata.ListWithIDOption) (
+	[]string, error) {
+
+	if cache != nil && supplierAccount == common.BKDefaultOwnerID {
+		if res, exists := cache.objects[objID]; exists {
+			return c.listWithCache(ctx, res, opt)
+		}
+	}
+
+	res := &resource{objID: objID, idField: common.GetInstIDField(objID)}
+	return c.listFromDB(ctx, res, supplierAccount, opt.IDs, opt.Fields)
+}
+
+// ListServiceInstances list the service instances with id list, the instances that do not exist are skipped.
+func (c *Client) ListServiceInstances(ctx context.Context, supplierAccount string, opt *metadata.ListWithIDOption) (
+	[]string, error) {
+
+	if cache != nil && cache.serviceInstance != nil && supplierAccount == common.BKDefaultOwnerID {
+		return c.listWithCache(ctx, cache.serviceInstance, opt)
+	}
+
+	res := &resource{idField: common.BKFieldID}
+	return c.listFromDB(ctx, res, supplierAccount, opt.IDs, opt.Fields)
+}
+
+// ListProcesses list the processes with id list, the processes that do not exist are skipped.
+func (c *Client) ListProcesses(ctx context.Context, supplierAccount string, opt *metadata.ListWithIDOption) (
+	[]string, error) {
+
+	if cache != nil && cache.process != nil && supplierAccount == common.BKDefaultOwnerID {
+		return c.listWithCache(ctx, cache.process, opt)
+	}
+
+	res := &resource{objID: common.BKInnerObjIDProc, idField: common.BKProcIDField}
+	return c.listFromDB(ctx, res, supplierAccount, opt.IDs, opt.Fields)
+}
+
+// listWithCache list the resource's instances of the default supplier account from cache, the ones that are not in
+// cache are got from db and refreshed to cache.
+func (c *Client) listWithCache(ctx context.Context, res *resource, opt *metadata.ListWithIDOption) ([]string,
+	error) {
+
+	if len(opt.IDs) == 0 {
+		return make([]string, 0), nil
+	}
+
+	rid := ctx.Value(common.ContextRequestIDField)
+
+	keys := make([]string, len(opt.IDs))
+	for idx, instID := range opt.IDs {
+		keys[idx] = res.key.detailKey(instID)
+	}
+
+	list, err := c.rds.MGet(context.Background(), keys...).Result()
+	if err != nil {
+		blog.Errorf("list %s instances %v from cache failed, get from db directly, err: %v, rid: %v", res.key.name,
+			opt.IDs, err, rid)
+		return c.listWithRefreshCache(ctx, res, opt.IDs, opt.Fields)
+	}
+
+	all := make([]string, 0)
+	toAdd := make([]int64, 0)
+	for idx, inst := range list {
+		if inst == nil {
+			toAdd = append(toAdd, opt.IDs[idx])
+			continue
+		}
+
+		detail, ok := inst.(string)
+		if !ok {
+			blog.Errorf("got invalid %s instance cache %v, rid: %v", res.key.name, inst, rid)
+			return nil, fmt.Errorf("got invalid %s instance cache %v", res.key.name, inst)
+		}
+
+		if len(opt.Fields) != 0 {
+			all = append(all, *json.CutJsonDataWithFields(&detail, opt.Fields))
+		} else {
+			all = append(all, detail)
+		}
+	}
+
+	if len(toAdd) != 0 {
+		details, err := c.listWithRefreshCache(ctx, res, toAdd, opt.Fields)
+		if err != nil {
+			return nil, err
+		}
+		all = append(all, details...)
+	}
+
+	return all, nil
+}
+
+// listWithRefreshCache list the resource's instances of the default supplier account from db and refresh them to
+// cache at the same time.
+func (c *Client) listWithRefreshCache(ctx context.Context, res *resource, instIDs []int64, fields []string) (
+	[]string, error) {
+
+	rid := ctx.Value(common.ContextRequestIDField)
+
+	instances := make([]map[string]interface{}, 0)
+	err := c.db.Table(res.tableName(common.BKDefaultOwnerID)).Find(res.filter(instIDs, common.BKDefaultOwnerID)).
+		All(ctx, &instances)
+	if err != nil {
+		blog.Errorf("get %s instances %v from db failed, err: %v, rid: %v", res.key.name, instIDs, err, rid)
+		return nil, err
+	}
+
+	pipe := c.rds.Pipeline()
+	all := make([]string, len(instances))
+	for idx := range instances {
+		js, err := json.Marshal(instances[idx])
+		if err != nil {
+			return nil, err
+		}
+
+		instID, err := util.GetInt64ByInterface(instances[idx][res.idField])
+		if err != nil {
+			return nil, err
+		}
+		pipe.Set(res.key.detailKey(instID), js, res.key.detailExpireDuration)
+
+		detail := string(js)
+		if len(fields) != 0 {
+			detail = *json.CutJsonDataWithFields(&detail, fields)
+		}
+		all[idx] = detail
+	}
+
+	if _, err := pipe.Exec(); err != nil {
+		blog.Errorf("refresh %s instance cache failed, err: %v, rid: %v", res.key.name, err, rid)
+		// do not return, cache will be refreshed for the next round
+	}
+
+	return all, nil
+}
+
+// listFromDB list the instances of the resource that is not opted in from db directly, without caching them.
+func (c *Client) listFromDB(ctx context.Context, res *resource, supplierAccount string, instIDs []int64,
+	fields []string) ([]string, error) {
+
+	if len(instIDs) == 0 {
+		return make([]string, 0), nil
+	}
+
+	rid := ctx.Value(common.ContextRequestIDField)
+
+	instances := make([]map[string]interface{}, 0)
+	err := c.db.Table(res.tableName(supplierAccount)).Find(res.filter(instIDs, supplierAccount)).Fields(fields...).
+		All(ctx, &instances)
+	if err != nil {
+		blog.Errorf("get %s instances %v from db failed, err: %v, rid: %v", res.tableName(supplierAccount), instIDs,
+			err, rid)
+		return nil, err
+	}
+
+	all := make([]string, len(instances))
+	for idx := range instances {
+		js, err := json.Marshal(instances[idx])
+		if err != nil {
+			return nil, err
+		}
+		all[idx] = string(js)
+	}
+
+	return all, nil
+}
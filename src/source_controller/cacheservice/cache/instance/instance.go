@@ -0,0 +1,279 @@
+/*
+ * Tencent is pleased to support the open source community by making 蓝鲸 available.
+ * Copyright (C) 2017-2018 THL A29 Limited, a Tencent company. All rights reserved.
+ * Licensed under the MIT License (the "License"); you may not use this file except
+ * in compliance with the License. You may obtain a copy of the License at
+ * http://opensource.org/licenses/MIT
+ * Unless required by applicable law or agreed to in writing, software distributed under
+ * the License is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND,
+ * either express or implied. See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+// Package instance is the event driven and ttl bounded cache of the custom object instances, service instances and
+// processes, which are opted in by the config.
+package instance
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"configcenter/src/common"
+	cc "configcenter/src/common/backbone/configcenter"
+	"configcenter/src/common/blog"
+	"configcenter/src/common/mapstr"
+	"configcenter/src/common/util"
+	"configcenter/src/storage/dal"
+	"configcenter/src/storage/dal/redis"
+	"configcenter/src/storage/driver/mongodb"
+	drvredis "configcenter/src/storage/driver/redis"
+	"configcenter/src/storage/stream"
+	"configcenter/src/storage/stream/types"
+
+	"github.com/tidwall/gjson"
+)
+
+const (
+	configPrefix  = "cacheService.instanceCache"
+	batchSize     = 200
+	retryDuration = 500 * time.Millisecond
+)
+
+// resource is a kind of instances that can be cached, which is an object's instances, service instances or processes
+type resource struct {
+	key keyGenerator
+	// objID is the object of the instances, it's empty for service instances
+	objID string
+	// idField is the field of the instance id
+	idField string
+}
+
+// tableName returns the table of the resource's instances
+func (r *resource) tableName(supplierAccount string) string {
+	if r.objID == "" {
+		return common.BKTableNameServiceInstance
+	}
+	return common.GetInstTableName(r.objID, supplierAccount)
+}
+
+// filter returns the condition to find the resource's instances of the supplier account with ids
+func (r *resource) filter(instIDs []int64, supplierAccount string) mapstr.MapStr {
+	filter := mapstr.MapStr{r.idField: mapstr.MapStr{common.BKDBIN: instIDs}}
+	if r.objID != "" && !common.IsInnerModel(r.objID) {
+		filter[common.BKObjIDField] = r.objID
+	}
+	return util.SetQueryOwner(filter, supplierAccount)
+}
+
+// cacheConfig is the config of the instance cache
+type cacheConfig struct {
+	objects             []string
+	withServiceInstance bool
+	ttl                 time.Duration
+}
+
+// parseCacheConfig parse the instance cache config, the objects whose instances are cached should be configured
+// explicitly, and the service instances and processes are cached together if withServiceInstance is set.
+func parseCacheConfig() *cacheConfig {
+	conf := &cacheConfig{ttl: defaultDetailTTLDuration}
+
+	objects, err := cc.StringSlice(configPrefix + ".objects")
+	if err == nil {
+		for _, objID := range objects {
+			if objID == "" || common.IsInnerMainlineModel(objID) || objID == common.BKInnerObjIDHost {
+				// the mainline instances and hosts are already cached by the other caches
+				blog.Warnf("instance cache does not support object %s, skip it", objID)
+				continue
+			}
+			conf.objects = append(conf.objects, objID)
+		}
+	}
+
+	conf.withServiceInstance, _ = cc.Bool(configPrefix + ".withServiceInstance")
+
+	minutes, err := cc.Int(configPrefix + ".detailTTLMinutes")
+	if err == nil && minutes > 0 {
+		conf.ttl = time.Duration(minutes) * time.Minute
+	}
+
+	return conf
+}
+
+// instanceCache is the instance cache of all the opted in resources
+type instanceCache struct {
+	event stream.LoopInterface
+	rds   redis.Client
+	db    dal.DB
+	// objects is object id => the resource of the object's instances
+	objects         map[string]*resource
+	serviceInstance *resource
+	process         *resource
+}
+
+var cache *instanceCache
+
+// NewInstanceCache initialize the instance cache, it watches the events of the opted in resources' tables and
+// refreshes the changed instances to the cache in time, the cache expires with ttl if no event is triggered.
+// Note: it can only be called for once, the config change takes effect after restart.
+func NewInstanceCache(event stream.LoopInterface) error {
+	if cache != nil {
+		return nil
+	}
+
+	conf := parseCacheConfig()
+	c := &instanceCache{
+		event:   event,
+		rds:     drvredis.Client(),
+		db:      mongodb.Client(),
+		objects: make(map[string]*resource),
+	}
+
+	for _, objID := range conf.objects {
+		res := &resource{
+			key:     newObjectKey(objID, conf.ttl),
+			objID:   objID,
+			idField: common.GetInstIDField(objID),
+		}
+		if err := c.watch(res); err != nil {
+			return fmt.Errorf("run object %s instance cache failed, err: %v", objID, err)
+		}
+		c.objects[objID] = res
+	}
+
+	if conf.withServiceInstance {
+		c.serviceInstance = &resource{
+			key:     keyGenerator{name: serviceInstanceKeyName, detailExpireDuration: conf.ttl},
+			idField: common.BKFieldID,
+		}
+		if err := c.watch(c.serviceInstance); err != nil {
+			return fmt.Errorf("run service instance cache failed, err: %v", err)
+		}
+
+		c.process = &resource{
+			key:     keyGenerator{name: processKeyName, detailExpireDuration: conf.ttl},
+			objID:   common.BKInnerObjIDProc,
+			idField: common.BKProcIDField,
+		}
+		if err := c.watch(c.process); err != nil {
+			return fmt.Errorf("run process cache failed, err: %v", err)
+		}
+	}
+
+	cache = c
+	return nil
+}
+
+// watch the events of the resource's table to refresh the cache, only the instances of the default supplier account
+// are cached, the other supplier accounts' instances are got from db directly.
+func (c *instanceCache) watch(res *resource) error {
+	handler := newTokenHandler(res.key)
+	startTime, err := handler.getStartTimestamp(context.Background())
+	if err != nil {
+		blog.Errorf("get %s instance cache event start at time failed, err: %v", res.key.name, err)
+		return err
+	}
+
+	loopOpts := &types.LoopBatchOptions{
+		LoopOptions: types.LoopOptions{
+			Name: fmt.Sprintf("%s_instance_cache", res.key.name),
+			WatchOpt: &types.WatchOptions{
+				Options: types.Options{
+					EventStruct: new(map[string]interface{}),
+					Collection:  res.tableName(common.BKDefaultOwnerID),
+					// start token will be automatically set when it's running,
+					// so we do not set here.
+					StartAfterToken:         nil,
+					StartAtTime:             startTime,
+					WatchFatalErrorCallback: handler.resetWatchTokenWithTimestamp,
+				},
+			},
+			TokenHandler: handler,
+			RetryOptions: &types.RetryOptions{
+				MaxRetryCount: 4,
+				RetryDuration: retryDuration,
+			},
+		},
+		EventHandler: &types.BatchHandler{
+			DoBatch: func(es []*types.Event) bool {
+				return c.onChange(res, es)
+			},
+		},
+		BatchSize: batchSize,
+	}
+
+	blog.Infof("start run %s instance cache with watch", res.key.name)
+	return c.event.WithBatch(loopOpts)
+}
+
+// onChange refresh the cache of the changed instances, the deleted instances are found in the delete archive
+func (c *instanceCache) onChange(res *resource, es []*types.Event) (retry bool) {
+	if len(es) == 0 {
+		return false
+	}
+
+	rid := es[0].ID()
+	pipe := c.rds.Pipeline()
+	deletedOids := make([]string, 0)
+	for _, e := range es {
+		if blog.V(4) {
+			blog.Infof("received %s instance cache event, op: %s, doc: %s, rid: %s", res.key.name, e.OperationType,
+				e.DocBytes, e.ID())
+		}
+
+		switch e.OperationType {
+		case types.Insert, types.Update, types.Replace:
+			instID := gjson.GetBytes(e.DocBytes, res.idField).Int()
+			if instID <= 0 {
+				blog.Errorf("received invalid %s instance event, skip, op: %s, doc: %s, rid: %s", res.key.name,
+					e.OperationType, e.DocBytes, e.ID())
+				continue
+			}
+			// the shared tables like service instance table contains the instances of the other supplier accounts
+			if gjson.GetBytes(e.DocBytes, common.BkSupplierAccount).String() != common.BKDefaultOwnerID {
+				continue
+			}
+			pipe.Set(res.key.detailKey(instID), e.DocBytes, res.key.detailExpireDuration)
+
+		case types.Delete:
+			deletedOids = append(deletedOids, e.Oid)
+		}
+	}
+
+	if len(deletedOids) > 0 {
+		filter := mapstr.MapStr{
+			"coll": es[0].Collection,
+			"oid":  mapstr.MapStr{common.BKDBIN: deletedOids},
+		}
+		archives := make([]instArchive, 0)
+		err := c.db.Table(common.BKTableNameDelArchive).Find(filter).Fields("detail").All(context.Background(),
+			&archives)
+		if err != nil {
+			blog.Errorf("get deleted %s instance archive failed, oids: %v, err: %v, rid: %s", res.key.name,
+				deletedOids, err, rid)
+			return true
+		}
+
+		for _, archive := range archives {
+			instID, err := util.GetInt64ByInterface(archive.Detail[res.idField])
+			if err != nil {
+				blog.Errorf("get deleted %s instance id failed, detail: %v, err: %v, rid: %s", res.key.name,
+					archive.Detail, err, rid)
+				continue
+			}
+			pipe.Del(res.key.detailKey(instID))
+		}
+	}
+
+	if _, err := pipe.Exec(); err != nil {
+		blog.Errorf("refresh %s instance cache failed, err: %v, rid: %s", res.key.name, err, rid)
+		return true
+	}
+
+	return false
+}
+
+// instArchive is the delete archive of an instance
+type instArchive struct {
+	Detail map[string]interface{} `bson:"detail"`
+}
@@ -0,0 +1,148 @@
+/*
+ * Tencent is pleased to support the open source community by making 蓝鲸 available.
+ * Copyright (C) 2017-2018 THL A29 Limited, a Tencent company. All rights reserved.
+ * Licensed under the MIT License (the "License"); you may not use this file except
+ * in compliance with the License. You may obtain a copy of the License at
+ * http://opensource.org/licenses/MIT
+ * Unless required by applicable law or agreed to in writing, software distributed under
+ * the License is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND,
+ * either express or implied. See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package instance
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"configcenter/src/common"
+	"configcenter/src/storage/dal/redis"
+	drv "configcenter/src/storage/driver/redis"
+	"configcenter/src/storage/stream/types"
+)
+
+const (
+	instNamespace = common.BKCacheKeyV3Prefix + "inst"
+	// defaultDetailTTLDuration is the default expire duration of the instance detail cache, the cache is refreshed
+	// by the events in time, the ttl only bounds the memory used and the staleness if an event is lost.
+	defaultDetailTTLDuration = 60 * time.Minute
+)
+
+const (
+	serviceInstanceKeyName = "service_instance"
+	processKeyName         = common.BKInnerObjIDProc
+	objectKeyNamePrefix    = "object_"
+)
+
+// keyGenerator is an instance cache key generator of a kind of resource.
+type keyGenerator struct {
+	name                 string
+	detailExpireDuration time.Duration
+}
+
+// newObjectKey initialize an object's instance key generator with object, the object id is prefixed so that it
+// will not conflict with the other resources.
+func newObjectKey(objID string, ttl time.Duration) keyGenerator {
+	return keyGenerator{
+		name:                 objectKeyNamePrefix + objID,
+		detailExpireDuration: ttl,
+	}
+}
+
+// resumeTokenKey is used to store the event resume token data
+func (k keyGenerator) resumeTokenKey() string {
+	return fmt.Sprintf("%s:%s:resume_token", instNamespace, k.name)
+}
+
+// resumeAtTimeKey is used to store the time where to resume the event stream.
+func (k keyGenerator) resumeAtTimeKey() string {
+	return fmt.Sprintf("%s:%s:resume_at_time", instNamespace, k.name)
+}
+
+// detailKey is to generate the key to store the instance's detail information.
+func (k keyGenerator) detailKey(instID int64) string {
+	return fmt.Sprintf("%s:%s_detail:%d", instNamespace, k.name, instID)
+}
+
+// newTokenHandler initialize a token handler.
+func newTokenHandler(key keyGenerator) *tokenHandler {
+	return &tokenHandler{
+		key: key,
+		rds: drv.Client(),
+	}
+}
+
+// tokenHandler is used to handle the watch token of a resource's instance cache, so that the cache can re-watch
+// the events from where it stopped when the task is restarted.
+type tokenHandler struct {
+	key keyGenerator
+	rds redis.Client
+}
+
+// SetLastWatchToken set watch token and resume time at the same time.
+func (t *tokenHandler) SetLastWatchToken(_ context.Context, token string) error {
+	stamp := &types.TimeStamp{
+		Sec: uint32(time.Now().Unix()),
+	}
+	atTime, err := json.Marshal(stamp)
+	if err != nil {
+		return err
+	}
+
+	pipe := t.rds.Pipeline()
+	pipe.Set(t.key.resumeTokenKey(), token, 0)
+	pipe.Set(t.key.resumeAtTimeKey(), string(atTime), 0)
+	_, err = pipe.Exec()
+	return err
+}
+
+// GetStartWatchToken get the last watched token, it can be empty.
+func (t *tokenHandler) GetStartWatchToken(ctx context.Context) (string, error) {
+	token, err := t.rds.Get(ctx, t.key.resumeTokenKey()).Result()
+	if err != nil {
+		if redis.IsNilErr(err) {
+			return "", nil
+		}
+		return "", err
+	}
+	return token, nil
+}
+
+// getStartTimestamp get the last event's timestamp, starts from now if it's never set.
+func (t *tokenHandler) getStartTimestamp(ctx context.Context) (*types.TimeStamp, error) {
+	js, err := t.rds.Get(ctx, t.key.resumeAtTimeKey()).Result()
+	if err != nil {
+		if redis.IsNilErr(err) {
+			return &types.TimeStamp{Sec: uint32(time.Now().Unix())}, nil
+		}
+		return nil, err
+	}
+
+	stamp := new(types.TimeStamp)
+	if len(js) == 0 {
+		return stamp, nil
+	}
+
+	if err := json.Unmarshal([]byte(js), stamp); err != nil {
+		return nil, err
+	}
+	return stamp, nil
+}
+
+// resetWatchTokenWithTimestamp reset the watch token, and update the start at time, so that we can re-watch from
+// the timestamp we set now.
+func (t *tokenHandler) resetWatchTokenWithTimestamp(startAtTime types.TimeStamp) error {
+	atTime, err := json.Marshal(startAtTime)
+	if err != nil {
+		return err
+	}
+
+	pipe := t.rds.Pipeline()
+	pipe.Set(t.key.resumeTokenKey(), "", 0)
+	pipe.Set(t.key.resumeAtTimeKey(), string(atTime), 0)
+	_, err = pipe.Exec()
+	return err
+}
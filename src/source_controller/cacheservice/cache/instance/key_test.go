@@ -0,0 +1,54 @@
+/*
+ * Tencent is pleased to support the open source community by making 蓝鲸 available.
+ * Copyright (C) 2017-2018 THL A29 Limited, a Tencent company. All rights reserved.
+ * Licensed under the MIT License (the "License"); you may not use this file except
+ * in compliance with the License. You may obtain a copy of the License at
+ * http://opensource.org/licenses/MIT
+ * Unless required by applicable law or agreed to in writing, software distributed under
+ * the License is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND,
+ * either express or implied. See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package instance
+
+import (
+	"testing"
+
+	"configcenter/src/common"
+)
+
+func TestObjectKey(t *testing.T) {
+	switchKey := newObjectKey("switch", defaultDetailTTLDuration)
+	// test resume token key
+	if switchKey.resumeTokenKey() != "cc:v3:inst:object_switch:resume_token" {
+		t.Fatalf("invalid switch object instance resume token key")
+	}
+
+	// test resume at time key
+	if switchKey.resumeAtTimeKey() != "cc:v3:inst:object_switch:resume_at_time" {
+		t.Fatalf("invalid switch object instance resume at time key")
+	}
+
+	// test detail key
+	if switchKey.detailKey(1) != "cc:v3:inst:object_switch_detail:1" {
+		t.Fatalf("invalid switch object instance detail key")
+	}
+}
+
+func TestServiceInstanceAndProcessKey(t *testing.T) {
+	svcInstKey := keyGenerator{name: serviceInstanceKeyName, detailExpireDuration: defaultDetailTTLDuration}
+	if svcInstKey.detailKey(1) != "cc:v3:inst:service_instance_detail:1" {
+		t.Fatalf("invalid service instance detail key")
+	}
+
+	procKey := keyGenerator{name: processKeyName, detailExpireDuration: defaultDetailTTLDuration}
+	if procKey.detailKey(1) != "cc:v3:inst:process_detail:1" {
+		t.Fatalf("invalid process detail key")
+	}
+
+	// the process key should not conflict with the object named process
+	if newObjectKey(common.BKInnerObjIDProc, defaultDetailTTLDuration).detailKey(1) == procKey.detailKey(1) {
+		t.Fatalf("object instance key conflicts with process key")
+	}
+}
@@ -21,33 +21,19 @@ import (
 	"configcenter/src/common/util"
 )
 
-// GetBizTopology TODO
+// GetBizTopology get a business's brief topology with the host and service instance counts of each node
 func (t *Topology) GetBizTopology(kit *rest.Kit, biz int64) (*string, error) {
 	// read from secondary in mongodb cluster.
 	kit.Ctx = util.SetDBReadPreference(kit.Ctx, common.SecondaryPreferredMode)
 
-	topology, err := t.briefBizKey.getTopology(kit.Ctx, biz)
-	if err == nil {
-		if len(*topology) != 0 {
-			// get data from cache success
-			return topology, nil
-		}
-		// get from db directly.
-	}
-
-	blog.Errorf("get biz: %d topology from cache failed, get from db now, err: %v, rid: %s", biz, err, kit.Rid)
-
-	// do not get biz topology from cache, get it from db directly.
-	topo, err := t.genBusinessTopology(kit.Ctx, biz)
+	topo, err := t.getBizBriefTopology(kit.Ctx, biz)
 	if err != nil {
-		blog.Errorf("generate biz: %d topology from db failed, err: %v, rid: %s", biz, err, kit.Rid)
 		return nil, err
 	}
 
-	// update it to cache directly.
-	if err := t.briefBizKey.updateTopology(kit.Ctx, topo); err != nil {
-		blog.Errorf("refresh biz: %d topology cache failed, err: %v, rid: %s", biz, err, kit.Rid)
-		// do not return error
+	if err := t.fillTopologyCounts(kit.Ctx, topo); err != nil {
+		blog.Errorf("get biz: %d topology node counts failed, err: %v, rid: %s", biz, err, kit.Rid)
+		return nil, err
 	}
 
 	dat, err := json.Marshal(topo)
@@ -0,0 +1,447 @@
+/*
+ * Tencent is pleased to support the open source community by making 蓝鲸 available.
+ * Copyright (C) 2017-2018 THL A29 Limited, a Tencent company. All rights reserved.
+ * Licensed under the MIT License (the "License"); you may not use this file except
+ * in compliance with the License. You may obtain a copy of the License at
+ * http://opensource.org/licenses/MIT
+ * Unless required by applicable law or agreed to in writing, software distributed under
+ * the License is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND,
+ * either express or implied. See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package topology
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+
+	"configcenter/src/common"
+	"configcenter/src/common/blog"
+	"configcenter/src/common/json"
+	"configcenter/src/common/mapstr"
+)
+
+// NOTE: this script depends on the way that the count keys and fields are generated,
+// so, when you change them, you need to change this script too.
+// KEYS[1] is the biz count key, KEYS[2] is the biz host reference key.
+// ARGV is grouped with (count field, host reference field, delta). the count is changed with delta directly
+// if the host reference field is empty, otherwise the count is changed only when the host's reference in the
+// node becomes positive or non-positive, so that a host is counted only once in a node.
+// nothing is changed if the biz counts do not exist, they will be counted from db when they are used.
+const updateTopoCountScript = `
+if (redis.call('exists', KEYS[1]) == 0) then
+	return 0
+end;
+
+for i = 1, #ARGV, 3 do
+	local delta = tonumber(ARGV[i+2])
+	if (ARGV[i+1] == '') then
+		redis.call('hincrby', KEYS[1], ARGV[i], delta)
+	else
+		local ref = redis.call('hincrby', KEYS[2], ARGV[i+1], delta)
+		local former = ref - delta
+		if (former <= 0 and ref > 0) then
+			redis.call('hincrby', KEYS[1], ARGV[i], 1)
+		elseif (former > 0 and ref <= 0) then
+			redis.call('hincrby', KEYS[1], ARGV[i], -1)
+		end;
+
+		if (ref <= 0) then
+			redis.call('hdel', KEYS[2], ARGV[i+1])
+		end;
+	end;
+end;
+
+return 1
+`
+
+// topoCountChange is a change of a module's host or service instance count
+type topoCountChange struct {
+	moduleID int64
+	// hostID is the host whose relation with the module is changed,
+	// it's 0 if it's the service instance count that is changed.
+	hostID int64
+	delta  int64
+}
+
+// nodeKey is the key of a topology node in the count fields, like set:1
+func nodeKey(object string, id int64) string {
+	return fmt.Sprintf("%s:%d", object, id)
+}
+
+func hostCountField(node string) string {
+	return node + ":host"
+}
+
+func svcInstCountField(node string) string {
+	return node + ":svc_inst"
+}
+
+func hostRefField(node string, hostID int64) string {
+	return fmt.Sprintf("%s:%d", node, hostID)
+}
+
+// modulePaths returns module id => the node keys from the business all the way to the module
+func modulePaths(topo *BizBriefTopology) map[int64][]string {
+	paths := make(map[int64][]string)
+
+	var walk func(nodes []*Node, parents []string)
+	walk = func(nodes []*Node, parents []string) {
+		for _, node := range nodes {
+			path := make([]string, len(parents), len(parents)+1)
+			copy(path, parents)
+			path = append(path, nodeKey(node.Object, node.ID))
+
+			if node.Object == common.BKInnerObjIDModule {
+				paths[node.ID] = path
+				continue
+			}
+			walk(node.SubNodes, path)
+		}
+	}
+
+	bizPath := []string{nodeKey(common.BKInnerObjIDApp, topo.Biz.ID)}
+	walk(topo.Idle, bizPath)
+	walk(topo.Nodes, bizPath)
+	return paths
+}
+
+// getBizBriefTopology get a business's brief topology from cache, if not exist in cache, then generate it from
+// db and refresh it to cache.
+func (t *Topology) getBizBriefTopology(ctx context.Context, biz int64) (*BizBriefTopology, error) {
+	rid := ctx.Value(common.ContextRequestIDField)
+
+	topology, err := t.briefBizKey.getTopology(ctx, biz)
+	if err == nil && len(*topology) != 0 {
+		topo := new(BizBriefTopology)
+		if err = json.Unmarshal([]byte(*topology), topo); err == nil {
+			// get data from cache success
+			return topo, nil
+		}
+	}
+
+	blog.Errorf("get biz: %d topology from cache failed, get from db now, err: %v, rid: %v", biz, err, rid)
+
+	// do not get biz topology from cache, get it from db directly.
+	topo, err := t.genBusinessTopology(ctx, biz)
+	if err != nil {
+		blog.Errorf("generate biz: %d topology from db failed, err: %v, rid: %v", biz, err, rid)
+		return nil, err
+	}
+
+	// update it to cache directly.
+	if err := t.briefBizKey.updateTopology(ctx, topo); err != nil {
+		blog.Errorf("refresh biz: %d topology cache failed, err: %v, rid: %v", biz, err, rid)
+		// do not return error
+	}
+
+	return topo, nil
+}
+
+// fillTopologyCounts fill the host and service instance counts of the business's topology nodes with the counts
+// in cache, the counts are counted from db and refreshed to cache if they do not exist.
+func (t *Topology) fillTopologyCounts(ctx context.Context, topo *BizBriefTopology) error {
+	rid := ctx.Value(common.ContextRequestIDField)
+
+	cached, err := t.rds.HGetAll(ctx, t.briefBizKey.bizCountKey(topo.Biz.ID)).Result()
+	if err != nil {
+		blog.Errorf("get biz: %d topology counts from cache failed, get from db now, err: %v, rid: %v",
+			topo.Biz.ID, err, rid)
+	}
+
+	if err == nil && len(cached) != 0 {
+		setTopologyCounts(topo, parseTopologyCounts(cached))
+		return nil
+	}
+
+	counts, refs, err := t.countBizTopology(ctx, topo)
+	if err != nil {
+		return err
+	}
+
+	if err := t.saveTopologyCounts(ctx, topo.Biz.ID, counts, refs); err != nil {
+		blog.Errorf("refresh biz: %d topology counts cache failed, err: %v, rid: %v", topo.Biz.ID, err, rid)
+		// do not return error
+	}
+
+	setTopologyCounts(topo, counts)
+	return nil
+}
+
+// recountBizTopology count the host and service instance counts of the business's topology nodes from db, and
+// verify the counts in cache with them, then refresh them to cache to correct the incremental counts.
+func (t *Topology) recountBizTopology(ctx context.Context, topo *BizBriefTopology) error {
+	rid := ctx.Value(common.ContextRequestIDField)
+
+	counts, refs, err := t.countBizTopology(ctx, topo)
+	if err != nil {
+		return err
+	}
+
+	cached, err := t.rds.HGetAll(ctx, t.briefBizKey.bizCountKey(topo.Biz.ID)).Result()
+	if err != nil {
+		blog.Errorf("get biz: %d topology counts from cache failed, err: %v, rid: %v", topo.Biz.ID, err, rid)
+	} else if len(cached) != 0 {
+		if mismatched := diffTopologyCounts(parseTopologyCounts(cached), counts); len(mismatched) != 0 {
+			if len(mismatched) > 20 {
+				mismatched = mismatched[:20]
+			}
+			blog.Warnf("biz: %d topology counts in cache mismatch with db, correct them now, fields: %v, rid: %v",
+				topo.Biz.ID, mismatched, rid)
+		}
+	}
+
+	return t.saveTopologyCounts(ctx, topo.Biz.ID, counts, refs)
+}
+
+// countBizTopology count the distinct hosts and service instances of each node in the business's topology from db.
+// returns the counts and the host references which is how many relations a host has under a node.
+func (t *Topology) countBizTopology(ctx context.Context, topo *BizBriefTopology) (map[string]int64,
+	map[string]int64, error) {
+
+	rid := ctx.Value(common.ContextRequestIDField)
+	paths := modulePaths(topo)
+
+	bizNode := nodeKey(common.BKInnerObjIDApp, topo.Biz.ID)
+	counts := map[string]int64{
+		hostCountField(bizNode):    0,
+		svcInstCountField(bizNode): 0,
+	}
+	refs := make(map[string]int64)
+
+	filter := mapstr.MapStr{common.BKAppIDField: topo.Biz.ID}
+	start := uint64(0)
+	for {
+		relations := make([]*hostRelationBase, 0)
+		err := t.db.Table(common.BKTableNameModuleHostConfig).Find(filter).Fields(hostRelationBaseFields...).
+			Sort(common.BKHostIDField+","+common.BKModuleIDField).Start(start).Limit(countStep).All(ctx, &relations)
+		if err != nil {
+			blog.Errorf("get biz: %d host relations failed, err: %v, rid: %v", topo.Biz.ID, err, rid)
+			return nil, nil, err
+		}
+
+		for _, relation := range relations {
+			for _, node := range paths[relation.ModuleID] {
+				field := hostRefField(node, relation.HostID)
+				refs[field]++
+				if refs[field] == 1 {
+					counts[hostCountField(node)]++
+				}
+			}
+		}
+
+		if len(relations) < countStep {
+			// we got all the data
+			break
+		}
+
+		// update start position
+		start += countStep
+	}
+
+	start = 0
+	for {
+		instances := make([]*serviceInstanceBase, 0)
+		err := t.db.Table(common.BKTableNameServiceInstance).Find(filter).Fields(serviceInstanceBaseFields...).
+			Sort(common.BKFieldID).Start(start).Limit(countStep).All(ctx, &instances)
+		if err != nil {
+			blog.Errorf("get biz: %d service instances failed, err: %v, rid: %v", topo.Biz.ID, err, rid)
+			return nil, nil, err
+		}
+
+		for _, instance := range instances {
+			for _, node := range paths[instance.ModuleID] {
+				counts[svcInstCountField(node)]++
+			}
+		}
+
+		if len(instances) < countStep {
+			// we got all the data
+			break
+		}
+
+		// update start position
+		start += countStep
+	}
+
+	return counts, refs, nil
+}
+
+// saveTopologyCounts replace the business's topology counts and host references in cache at one transaction.
+func (t *Topology) saveTopologyCounts(ctx context.Context, biz int64, counts, refs map[string]int64) error {
+	countKey := t.briefBizKey.bizCountKey(biz)
+	refKey := t.briefBizKey.bizHostRefKey(biz)
+
+	pipe := t.rds.TxPipeline(ctx)
+	pipe.Del(countKey, refKey)
+	for _, values := range splitHashValues(counts) {
+		pipe.HSet(countKey, values...)
+	}
+	for _, values := range splitHashValues(refs) {
+		pipe.HSet(refKey, values...)
+	}
+	pipe.Expire(countKey, t.briefBizKey.ttl)
+	pipe.Expire(refKey, t.briefBizKey.ttl)
+
+	_, err := pipe.Exec()
+	return err
+}
+
+// applyTopoCountChanges apply the count changes of each business to the counts in cache incrementally.
+// the changes that failed to be applied are not retried, because a change can not be applied twice, they
+// will be corrected by the full refresh of the business topology.
+func (t *Topology) applyTopoCountChanges(changes map[int64][]topoCountChange, rid string) {
+	ctx := context.WithValue(context.Background(), common.ContextRequestIDField, rid)
+
+	for biz, bizChanges := range changes {
+		paths, err := t.getModulePaths(ctx, biz, bizChanges)
+		if err != nil {
+			blog.Errorf("get biz: %d topology module paths failed, skip count changes, err: %v, rid: %s", biz, err,
+				rid)
+			continue
+		}
+
+		args := make([]interface{}, 0)
+		for _, change := range bizChanges {
+			path, exists := paths[change.moduleID]
+			if !exists {
+				blog.Warnf("module %d is not in biz: %d topology, skip its count change, rid: %s", change.moduleID,
+					biz, rid)
+				continue
+			}
+
+			for _, node := range path {
+				if change.hostID == 0 {
+					args = append(args, svcInstCountField(node), "", change.delta)
+					continue
+				}
+				args = append(args, hostCountField(node), hostRefField(node, change.hostID), change.delta)
+			}
+		}
+
+		if len(args) == 0 {
+			continue
+		}
+
+		keys := []string{t.briefBizKey.bizCountKey(biz), t.briefBizKey.bizHostRefKey(biz)}
+		if err := t.rds.Eval(ctx, updateTopoCountScript, keys, args...).Err(); err != nil {
+			blog.Errorf("update biz: %d topology counts in cache failed, err: %v, rid: %s", biz, err, rid)
+			continue
+		}
+	}
+}
+
+// getModulePaths get the module paths of the business's topology, the topology is regenerated from db if the
+// changed modules are not in the cached one, which happens when the module is created just now.
+func (t *Topology) getModulePaths(ctx context.Context, biz int64, changes []topoCountChange) (
+	map[int64][]string, error) {
+
+	topo, err := t.getBizBriefTopology(ctx, biz)
+	if err != nil {
+		return nil, err
+	}
+
+	paths := modulePaths(topo)
+	for _, change := range changes {
+		if _, exists := paths[change.moduleID]; exists {
+			continue
+		}
+
+		topo, err = t.genBusinessTopology(ctx, biz)
+		if err != nil {
+			return nil, err
+		}
+
+		if err := t.briefBizKey.updateTopology(ctx, topo); err != nil {
+			blog.Errorf("refresh biz: %d topology cache failed, err: %v, rid: %v",
+				biz, err, ctx.Value(common.ContextRequestIDField))
+			// do not return error
+		}
+
+		return modulePaths(topo), nil
+	}
+
+	return paths, nil
+}
+
+// setTopologyCounts set the host and service instance counts of the business's topology nodes
+func setTopologyCounts(topo *BizBriefTopology, counts map[string]int64) {
+	bizNode := nodeKey(common.BKInnerObjIDApp, topo.Biz.ID)
+	topo.HostCount = getTopologyCount(counts, hostCountField(bizNode))
+	topo.ServiceInstanceCount = getTopologyCount(counts, svcInstCountField(bizNode))
+
+	var set func(nodes []*Node)
+	set = func(nodes []*Node) {
+		for _, node := range nodes {
+			key := nodeKey(node.Object, node.ID)
+			node.HostCount = getTopologyCount(counts, hostCountField(key))
+			node.ServiceInstanceCount = getTopologyCount(counts, svcInstCountField(key))
+			set(node.SubNodes)
+		}
+	}
+
+	set(topo.Idle)
+	set(topo.Nodes)
+}
+
+// getTopologyCount get the count of the field, the incremental count can be negative for a while if the delete
+// event is handled before the counts are refreshed, it is treated as 0.
+func getTopologyCount(counts map[string]int64, field string) int64 {
+	if count := counts[field]; count > 0 {
+		return count
+	}
+	return 0
+}
+
+// parseTopologyCounts parse the counts in cache, the invalid ones are skipped
+func parseTopologyCounts(cached map[string]string) map[string]int64 {
+	counts := make(map[string]int64, len(cached))
+	for field, value := range cached {
+		count, err := strconv.ParseInt(value, 10, 64)
+		if err != nil {
+			blog.Errorf("got invalid topology count %s: %s, skip", field, value)
+			continue
+		}
+		counts[field] = count
+	}
+	return counts
+}
+
+// diffTopologyCounts returns the fields whose counts are different in the two counts
+func diffTopologyCounts(cached, counts map[string]int64) []string {
+	mismatched := make([]string, 0)
+	for field, count := range counts {
+		if getTopologyCount(cached, field) != count {
+			mismatched = append(mismatched, field)
+		}
+	}
+
+	for field := range cached {
+		if _, exists := counts[field]; !exists && getTopologyCount(cached, field) != 0 {
+			mismatched = append(mismatched, field)
+		}
+	}
+
+	return mismatched
+}
+
+// splitHashValues split the hash to field value pairs with at most countStep fields in one group
+func splitHashValues(hash map[string]int64) [][]interface{} {
+	all := make([][]interface{}, 0)
+	values := make([]interface{}, 0)
+	for field, value := range hash {
+		values = append(values, field, value)
+		if len(values) >= 2*countStep {
+			all = append(all, values)
+			values = make([]interface{}, 0)
+		}
+	}
+
+	if len(values) != 0 {
+		all = append(all, values)
+	}
+
+	return all
+}
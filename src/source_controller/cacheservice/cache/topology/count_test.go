@@ -0,0 +1,78 @@
+/*
+ * Tencent is pleased to support the open source community by making 蓝鲸 available.
+ * Copyright (C) 2017-2018 THL A29 Limited, a Tencent company. All rights reserved.
+ * Licensed under the MIT License (the "License"); you may not use this file except
+ * in compliance with the License. You may obtain a copy of the License at
+ * http://opensource.org/licenses/MIT
+ * Unless required by applicable law or agreed to in writing, software distributed under
+ * the License is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND,
+ * either express or implied. See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package topology
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestModulePathsAndCounts(t *testing.T) {
+	topo := &BizBriefTopology{
+		Biz:  &BizBase{ID: 1},
+		Idle: []*Node{{Object: "set", ID: 2, SubNodes: []*Node{{Object: "module", ID: 3}}}},
+		Nodes: []*Node{{Object: "country", ID: 4, SubNodes: []*Node{
+			{Object: "set", ID: 5, SubNodes: []*Node{{Object: "module", ID: 6}, {Object: "module", ID: 7}}},
+		}}},
+	}
+
+	paths := modulePaths(topo)
+	expected := map[int64][]string{
+		3: {"biz:1", "set:2", "module:3"},
+		6: {"biz:1", "country:4", "set:5", "module:6"},
+		7: {"biz:1", "country:4", "set:5", "module:7"},
+	}
+	if !reflect.DeepEqual(paths, expected) {
+		t.Fatalf("invalid module paths: %v", paths)
+	}
+
+	counts := map[string]int64{
+		"biz:1:host":        2,
+		"set:5:host":        1,
+		"module:7:host":     -1,
+		"set:5:svc_inst":    3,
+		"country:4:host":    1,
+		"module:6:host":     1,
+		"biz:1:svc_inst":    3,
+		"module:6:svc_inst": 3,
+	}
+	setTopologyCounts(topo, counts)
+
+	if topo.HostCount != 2 || topo.ServiceInstanceCount != 3 {
+		t.Fatalf("invalid biz counts: %d, %d", topo.HostCount, topo.ServiceInstanceCount)
+	}
+
+	set := topo.Nodes[0].SubNodes[0]
+	if set.HostCount != 1 || set.ServiceInstanceCount != 3 {
+		t.Fatalf("invalid set counts: %d, %d", set.HostCount, set.ServiceInstanceCount)
+	}
+
+	// negative incremental count is treated as 0
+	if set.SubNodes[1].HostCount != 0 {
+		t.Fatalf("invalid module counts: %d", set.SubNodes[1].HostCount)
+	}
+
+	if topo.Idle[0].HostCount != 0 || topo.Idle[0].SubNodes[0].ServiceInstanceCount != 0 {
+		t.Fatalf("invalid idle set counts")
+	}
+}
+
+func TestDiffTopologyCounts(t *testing.T) {
+	cached := map[string]int64{"biz:1:host": 2, "set:2:host": -1, "set:3:host": 1}
+	counts := map[string]int64{"biz:1:host": 2, "module:4:host": 1}
+
+	mismatched := diffTopologyCounts(cached, counts)
+	if len(mismatched) != 2 {
+		t.Fatalf("invalid mismatched counts: %v", mismatched)
+	}
+}
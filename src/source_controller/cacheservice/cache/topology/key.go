@@ -130,6 +130,17 @@ func (c *cacheKey) bizTopologyKey(biz int64) string {
 	return fmt.Sprintf("%s:%d", c.namespace, biz)
 }
 
+// bizCountKey is the key of the hash which stores the host and service instance counts of the biz topology nodes
+func (c *cacheKey) bizCountKey(biz int64) string {
+	return fmt.Sprintf("%s:count:%d", c.namespace, biz)
+}
+
+// bizHostRefKey is the key of the hash which stores how many relations a host has under each biz topology node,
+// it's used to count the distinct hosts of a node incrementally.
+func (c *cacheKey) bizHostRefKey(biz int64) string {
+	return fmt.Sprintf("%s:host_ref:%d", c.namespace, biz)
+}
+
 // updateTopology update biz Topology cache
 func (c *cacheKey) updateTopology(ctx context.Context, topo *BizBriefTopology) error {
 
@@ -56,6 +56,16 @@ func NewTopology(isMaster discovery.ServiceManageInterface, loopW stream.LoopInt
 		return nil, err
 	}
 
+	if err := t.watchHostRelation(); err != nil {
+		blog.Errorf("topology watch host relation failed, err: %v", err)
+		return nil, err
+	}
+
+	if err := t.watchServiceInstance(); err != nil {
+		blog.Errorf("topology watch service instance failed, err: %v", err)
+		return nil, err
+	}
+
 	go t.loopBizBriefCache()
 
 	return t, nil
@@ -139,6 +149,13 @@ func (t *Topology) refreshBizTopology(biz *BizBase, rid string) error {
 		return err
 	}
 
+	// the counts are maintained incrementally with events, recount them to correct the missed changes.
+	err = t.recountBizTopology(ctx, topo)
+	if err != nil {
+		blog.Errorf("recount biz %d/%s topology counts failed, err: %v, rid: %s", biz.ID, biz.Name, err, rid)
+		return err
+	}
+
 	return nil
 }
 
@@ -20,6 +20,10 @@ type BizBriefTopology struct {
 	Idle []*Node `json:"idle"`
 	// the other common nodes
 	Nodes []*Node `json:"nds"`
+	// the number of the distinct hosts in this business
+	HostCount int64 `json:"hc"`
+	// the number of the service instances in this business
+	ServiceInstanceCount int64 `json:"sic"`
 }
 
 // Node TODO
@@ -37,6 +41,10 @@ type Node struct {
 	Default *int `json:"dft,omitempty"`
 	// the sub-nodes of current node
 	SubNodes []*Node `json:"nds"`
+	// the number of the distinct hosts in this node
+	HostCount int64 `json:"hc"`
+	// the number of the service instances in this node
+	ServiceInstanceCount int64 `json:"sic"`
 }
 
 var bizBaseFields = []string{"bk_biz_id", "bk_biz_name", "default", "bk_supplier_account"}
@@ -100,6 +108,32 @@ type moduleArchive struct {
 	Detail *moduleBase `bson:"detail"`
 }
 
+var hostRelationBaseFields = []string{"bk_biz_id", "bk_host_id", "bk_module_id"}
+
+type hostRelationBase struct {
+	Business int64 `bson:"bk_biz_id"`
+	HostID   int64 `bson:"bk_host_id"`
+	ModuleID int64 `bson:"bk_module_id"`
+}
+
+type hostRelationArchive struct {
+	Oid    string            `bson:"oid"`
+	Detail *hostRelationBase `bson:"detail"`
+}
+
+var serviceInstanceBaseFields = []string{"bk_biz_id", "id", "bk_module_id"}
+
+type serviceInstanceBase struct {
+	Business int64 `bson:"bk_biz_id"`
+	ID       int64 `bson:"id"`
+	ModuleID int64 `bson:"bk_module_id"`
+}
+
+type serviceInstanceArchive struct {
+	Oid    string               `bson:"oid"`
+	Detail *serviceInstanceBase `bson:"detail"`
+}
+
 var mainlineAsstFields = []string{"bk_asst_obj_id", "bk_obj_id"}
 
 type mainlineAssociation struct {
@@ -110,5 +144,6 @@ type mainlineAssociation struct {
 // page step
 const (
 	step                          = 100
+	countStep                     = 1000
 	defaultRefreshIntervalMinutes = 15
 )
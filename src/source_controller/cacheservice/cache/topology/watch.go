@@ -334,3 +334,204 @@ func (t *Topology) onCustomChange(es []*types.Event) (retry bool) {
 
 	return false
 }
+
+// watchHostRelation watch host relation change event to maintain the host counts of the topology nodes
+func (t *Topology) watchHostRelation() error {
+	watchOpts := &types.WatchOptions{
+		Options: types.Options{
+			EventStruct: new(hostRelationBase),
+			Collection:  common.BKTableNameModuleHostConfig,
+			Filter:      mapstr.MapStr{},
+		},
+	}
+
+	tokenHandler := newTokenHandler("host_relation")
+	startAtTime, err := tokenHandler.getStartWatchTime(context.Background())
+	if err != nil {
+		blog.Errorf("get start watch time for %s failed, err: %v", watchOpts.Collection, err)
+		return err
+	}
+	watchOpts.StartAtTime = startAtTime
+	watchOpts.WatchFatalErrorCallback = tokenHandler.resetWatchToken
+
+	loopOptions := &types.LoopBatchOptions{
+		LoopOptions: types.LoopOptions{
+			Name:         "topology cache with host relation",
+			WatchOpt:     watchOpts,
+			TokenHandler: tokenHandler,
+			RetryOptions: &types.RetryOptions{
+				MaxRetryCount: 10,
+				RetryDuration: 1 * time.Second,
+			},
+		},
+		EventHandler: &types.BatchHandler{
+			DoBatch: t.onHostRelationChange,
+		},
+		BatchSize: 200,
+	}
+
+	return t.loopW.WithBatch(loopOptions)
+}
+
+func (t *Topology) onHostRelationChange(es []*types.Event) (retry bool) {
+	if len(es) == 0 {
+		return false
+	}
+
+	rid := es[0].ID()
+	changes := make(map[int64][]topoCountChange)
+	for idx := range es {
+		one := es[idx]
+
+		var relation *hostRelationBase
+		var delta int64
+		switch one.OperationType {
+		case types.Insert:
+			relation = one.Document.(*hostRelationBase)
+			delta = 1
+
+		case types.Delete:
+			filter := mapstr.MapStr{
+				"oid":  one.Oid,
+				"coll": common.BKTableNameModuleHostConfig,
+			}
+			archive := new(hostRelationArchive)
+			err := t.db.Table(common.BKTableNameDelArchive).Find(filter).One(context.TODO(), archive)
+			if err != nil {
+				blog.Errorf("topology cache, get deleted host relation %s failed, err: %v, rid: %s", one.Oid, err,
+					rid)
+				if t.db.IsNotFoundError(err) {
+					blog.Errorf("can not find deleted host relation %s detail, skip, rid: %s", one.Oid, rid)
+					continue
+				} else {
+					return true
+				}
+			}
+
+			relation = archive.Detail
+			delta = -1
+
+		default:
+			// host relation is not updated, it's deleted and created when host is transferred.
+			continue
+		}
+
+		if blog.V(4) {
+			blog.Infof("topology cache, received biz: %d, host: %d, module: %d relation, op: %s, op-time: %s, "+
+				"changed event, rid: %s", relation.Business, relation.HostID, relation.ModuleID, one.OperationType,
+				one.ClusterTime.String(), rid)
+		}
+
+		changes[relation.Business] = append(changes[relation.Business], topoCountChange{
+			moduleID: relation.ModuleID,
+			hostID:   relation.HostID,
+			delta:    delta,
+		})
+	}
+
+	// the count changes can not be retried, the failed ones will be corrected by the full refresh.
+	t.applyTopoCountChanges(changes, rid)
+	return false
+}
+
+// watchServiceInstance watch service instance change event to maintain the service instance counts of the
+// topology nodes
+func (t *Topology) watchServiceInstance() error {
+	watchOpts := &types.WatchOptions{
+		Options: types.Options{
+			EventStruct: new(serviceInstanceBase),
+			Collection:  common.BKTableNameServiceInstance,
+			Filter:      mapstr.MapStr{},
+		},
+	}
+
+	tokenHandler := newTokenHandler("service_instance")
+	startAtTime, err := tokenHandler.getStartWatchTime(context.Background())
+	if err != nil {
+		blog.Errorf("get start watch time for %s failed, err: %v", watchOpts.Collection, err)
+		return err
+	}
+	watchOpts.StartAtTime = startAtTime
+	watchOpts.WatchFatalErrorCallback = tokenHandler.resetWatchToken
+
+	loopOptions := &types.LoopBatchOptions{
+		LoopOptions: types.LoopOptions{
+			Name:         "topology cache with service instance",
+			WatchOpt:     watchOpts,
+			TokenHandler: tokenHandler,
+			RetryOptions: &types.RetryOptions{
+				MaxRetryCount: 10,
+				RetryDuration: 1 * time.Second,
+			},
+		},
+		EventHandler: &types.BatchHandler{
+			DoBatch: t.onServiceInstanceChange,
+		},
+		BatchSize: 200,
+	}
+
+	return t.loopW.WithBatch(loopOptions)
+}
+
+// onServiceInstanceChange count the service instances of the modules, the deleted service instances are found in the
+// delete archive, which requires the service instance table to be archived on deletion by the mongo dal. the archives
+// are kept for a week by the event flow's gc, which is long enough for the delete events to be handled.
+func (t *Topology) onServiceInstanceChange(es []*types.Event) (retry bool) {
+	if len(es) == 0 {
+		return false
+	}
+
+	rid := es[0].ID()
+	changes := make(map[int64][]topoCountChange)
+	for idx := range es {
+		one := es[idx]
+
+		var instance *serviceInstanceBase
+		var delta int64
+		switch one.OperationType {
+		case types.Insert:
+			instance = one.Document.(*serviceInstanceBase)
+			delta = 1
+
+		case types.Delete:
+			filter := mapstr.MapStr{
+				"oid":  one.Oid,
+				"coll": common.BKTableNameServiceInstance,
+			}
+			archive := new(serviceInstanceArchive)
+			err := t.db.Table(common.BKTableNameDelArchive).Find(filter).One(context.TODO(), archive)
+			if err != nil {
+				blog.Errorf("topology cache, get deleted service instance %s failed, err: %v, rid: %s", one.Oid, err,
+					rid)
+				if t.db.IsNotFoundError(err) {
+					blog.Errorf("can not find deleted service instance %s detail, skip, rid: %s", one.Oid, rid)
+					continue
+				} else {
+					return true
+				}
+			}
+
+			instance = archive.Detail
+			delta = -1
+
+		default:
+			// the module of a service instance can not be changed, only handle insert and delete event.
+			continue
+		}
+
+		if blog.V(4) {
+			blog.Infof("topology cache, received biz: %d, module: %d, service instance: %d, op: %s, op-time: %s, "+
+				"changed event, rid: %s", instance.Business, instance.ModuleID, instance.ID, one.OperationType,
+				one.ClusterTime.String(), rid)
+		}
+
+		changes[instance.Business] = append(changes[instance.Business], topoCountChange{
+			moduleID: instance.ModuleID,
+			delta:    delta,
+		})
+	}
+
+	// the count changes can not be retried, the failed ones will be corrected by the full refresh.
+	t.applyTopoCountChanges(changes, rid)
+	return false
+}
@@ -0,0 +1,261 @@
+/*
+ * Tencent is pleased to support the open source community by making 蓝鲸 available.
+ * Copyright (C) 2017-2018 THL A29 Limited, a Tencent company. All rights reserved.
+ * Licensed under the MIT License (the "License"); you may not use this file except
+ * in compliance with the License. You may obtain a copy of the License at
+ * http://opensource.org/licenses/MIT
+ * Unless required by applicable law or agreed to in writing, software distributed under
+ * the License is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND,
+ * either express or implied. See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+// Package auditexport exports the audit logs to the syslog, kafka or rotating file sinks in near real time
+package auditexport
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"time"
+
+	"configcenter/src/common"
+	cc "configcenter/src/common/backbone/configcenter"
+	"configcenter/src/common/blog"
+	"configcenter/src/common/mapstr"
+	"configcenter/src/common/metadata"
+	"configcenter/src/storage/dal"
+	"configcenter/src/storage/dal/kafka"
+	"configcenter/src/storage/stream"
+	"configcenter/src/storage/stream/types"
+)
+
+const (
+	configPrefix = "cacheService.auditExport"
+
+	defaultSyslogAppName    = "bk-cmdb"
+	defaultFileMaxSizeMB    = 100
+	defaultFileMaxBackups   = 10
+	exportBatchSize         = 200
+	exportRetryDuration     = 3 * time.Second
+	sinkTypeSyslog          = "syslog"
+	sinkTypeKafka           = "kafka"
+	sinkTypeFile            = "file"
+	syslogNetworkTCP        = "tcp"
+	syslogNetworkUDP        = "udp"
+	auditExportWatchJobName = "audit log export"
+)
+
+// exportConfig is the config of the audit log export
+type exportConfig struct {
+	format Format
+	sink   string
+	syslog syslogConfig
+	kafka  kafka.Config
+	file   fileConfig
+}
+
+type syslogConfig struct {
+	network string
+	address string
+	appName string
+}
+
+type fileConfig struct {
+	path       string
+	maxSizeMB  int
+	maxBackups int
+}
+
+// parseExportConfig parse the audit log export config, returns false if the export is not enabled
+func parseExportConfig() (*exportConfig, bool, error) {
+	enabled, err := cc.Bool(configPrefix + ".enabled")
+	if err != nil || !enabled {
+		return nil, false, nil
+	}
+
+	conf := &exportConfig{format: FormatJSON}
+	if format, err := cc.String(configPrefix + ".format"); err == nil && format != "" {
+		conf.format = Format(format)
+	}
+
+	if conf.format != FormatJSON && conf.format != FormatCEF {
+		return nil, false, fmt.Errorf("audit export format %s is invalid, only supports json and cef", conf.format)
+	}
+
+	conf.sink, _ = cc.String(configPrefix + ".sink")
+	switch conf.sink {
+	case sinkTypeSyslog:
+		conf.syslog.network, _ = cc.String(configPrefix + ".syslog.network")
+		if conf.syslog.network == "" {
+			conf.syslog.network = syslogNetworkUDP
+		}
+		if conf.syslog.network != syslogNetworkTCP && conf.syslog.network != syslogNetworkUDP {
+			return nil, false, fmt.Errorf("audit export syslog network %s is invalid", conf.syslog.network)
+		}
+
+		conf.syslog.address, _ = cc.String(configPrefix + ".syslog.address")
+		if conf.syslog.address == "" {
+			return nil, false, errors.New("audit export syslog address is not set")
+		}
+
+		conf.syslog.appName, _ = cc.String(configPrefix + ".syslog.appName")
+		if conf.syslog.appName == "" {
+			conf.syslog.appName = defaultSyslogAppName
+		}
+
+	case sinkTypeKafka:
+		conf.kafka, err = cc.Kafka(configPrefix + ".kafka")
+		if err != nil {
+			return nil, false, err
+		}
+
+		if len(conf.kafka.Brokers) == 0 || conf.kafka.Topic == "" {
+			return nil, false, errors.New("audit export kafka brokers or topic is not set")
+		}
+
+	case sinkTypeFile:
+		conf.file.path, _ = cc.String(configPrefix + ".file.path")
+		if conf.file.path == "" {
+			return nil, false, errors.New("audit export file path is not set")
+		}
+
+		conf.file.maxSizeMB, err = cc.Int(configPrefix + ".file.maxSizeMB")
+		if err != nil || conf.file.maxSizeMB <= 0 {
+			conf.file.maxSizeMB = defaultFileMaxSizeMB
+		}
+
+		conf.file.maxBackups, err = cc.Int(configPrefix + ".file.maxBackups")
+		if err != nil || conf.file.maxBackups <= 0 {
+			conf.file.maxBackups = defaultFileMaxBackups
+		}
+
+	default:
+		return nil, false, fmt.Errorf("audit export sink %s is invalid, only supports syslog, kafka and file",
+			conf.sink)
+	}
+
+	return conf, true, nil
+}
+
+// NewAuditExporter init and run the audit log export watch if it is enabled
+func NewAuditExporter(watch stream.LoopInterface, ccDB dal.DB) error {
+	conf, enabled, err := parseExportConfig()
+	if err != nil {
+		blog.Errorf("parse audit log export config failed, err: %v", err)
+		return err
+	}
+
+	if !enabled {
+		blog.Info("audit log export is not enabled, skip")
+		return nil
+	}
+
+	sink, err := newSink(conf)
+	if err != nil {
+		blog.Errorf("new audit log export %s sink failed, err: %v", conf.sink, err)
+		return err
+	}
+
+	exporter := &auditExporter{
+		watch:     watch,
+		formatter: newFormatter(conf.format),
+		sink:      sink,
+		token:     newTokenHandler(ccDB),
+	}
+
+	if err := exporter.run(); err != nil {
+		sink.Close()
+		return err
+	}
+
+	blog.Infof("audit log export to %s sink in %s format started", conf.sink, conf.format)
+	return nil
+}
+
+type auditExporter struct {
+	watch     stream.LoopInterface
+	formatter formatter
+	sink      Sink
+	token     *tokenHandler
+}
+
+func (a *auditExporter) run() error {
+	insertType := types.Insert
+	watchOpts := &types.WatchOptions{
+		Options: types.Options{
+			EventStruct:   new(metadata.AuditLog),
+			Collection:    common.BKTableNameAuditLog,
+			OperationType: &insertType,
+			Filter:        mapstr.MapStr{},
+		},
+	}
+
+	startAtTime, err := a.token.getStartWatchTime()
+	if err != nil {
+		return err
+	}
+	watchOpts.StartAtTime = startAtTime
+	watchOpts.WatchFatalErrorCallback = a.token.resetWatchToken
+
+	loopOptions := &types.LoopBatchOptions{
+		LoopOptions: types.LoopOptions{
+			Name:         auditExportWatchJobName,
+			WatchOpt:     watchOpts,
+			TokenHandler: a.token,
+			// the batch is retried from the last token until it is sent, so that no audit log is skipped
+			RetryOptions: &types.RetryOptions{
+				MaxRetryCount: math.MaxInt32,
+				RetryDuration: exportRetryDuration,
+			},
+		},
+		EventHandler: &types.BatchHandler{
+			DoBatch: a.export,
+		},
+		BatchSize: exportBatchSize,
+	}
+
+	return a.watch.WithBatch(loopOptions)
+}
+
+// export formats the audit log events and sends them to the sink, returns retry if the sending failed, then the
+// events are re-watched from the last token, and the token is not saved until they are sent.
+func (a *auditExporter) export(es []*types.Event) (retry bool) {
+	if len(es) == 0 {
+		return false
+	}
+
+	rid := es[0].ID()
+	messages := make([][]byte, 0, len(es))
+	for _, e := range es {
+		if e.OperationType != types.Insert {
+			continue
+		}
+
+		auditLog, ok := e.Document.(*metadata.AuditLog)
+		if !ok {
+			blog.Errorf("audit log export received invalid event document, doc: %s, rid: %s", e.DocBytes, rid)
+			continue
+		}
+
+		msg, err := a.formatter.Format(auditLog)
+		if err != nil {
+			// the audit log that can not be formatted is never sent, skip it instead of blocking the others
+			blog.Errorf("format audit log %d failed, skip it, err: %v, rid: %s", auditLog.ID, err, rid)
+			continue
+		}
+		messages = append(messages, msg)
+	}
+
+	if len(messages) == 0 {
+		return false
+	}
+
+	if err := a.sink.Send(messages); err != nil {
+		blog.Errorf("send %d audit logs to sink failed, retry later, err: %v, rid: %s", len(messages), err, rid)
+		return true
+	}
+
+	blog.V(4).Infof("exported %d audit logs, rid: %s", len(messages), rid)
+	return false
+}
@@ -0,0 +1,79 @@
+/*
+ * Tencent is pleased to support the open source community by making 蓝鲸 available.
+ * Copyright (C) 2017-2018 THL A29 Limited, a Tencent company. All rights reserved.
+ * Licensed under the MIT License (the "License"); you may not use this file except
+ * in compliance with the License. You may obtain a copy of the License at
+ * http://opensource.org/licenses/MIT
+ * Unless required by applicable law or agreed to in writing, software distributed under
+ * the License is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND,
+ * either express or implied. See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package auditexport
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"configcenter/src/common/metadata"
+)
+
+func TestCEFFormatter(t *testing.T) {
+	auditLog := &metadata.AuditLog{
+		ID:           1,
+		AuditType:    metadata.HostType,
+		ResourceType: metadata.HostRes,
+		Action:       metadata.AuditDelete,
+		ResourceName: "a=b|c\nd",
+	}
+
+	msg, err := newFormatter(FormatCEF).Format(auditLog)
+	if err != nil {
+		t.Fatalf("format audit log failed, err: %v", err)
+	}
+
+	if !strings.HasPrefix(string(msg), "CEF:0|Tencent|BlueKing CMDB|") {
+		t.Fatalf("invalid cef header: %s", msg)
+	}
+
+	if !strings.Contains(string(msg), "|host:delete|delete host|7|") {
+		t.Fatalf("invalid cef signature or severity: %s", msg)
+	}
+
+	if !strings.Contains(string(msg), `cs3=a\=b|c\nd `) {
+		t.Fatalf("invalid cef extension escape: %s", msg)
+	}
+}
+
+func TestFileSinkRotate(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "audit.log")
+	sink, err := newFileSink(fileConfig{path: path, maxSizeMB: 1, maxBackups: 2})
+	if err != nil {
+		t.Fatalf("new file sink failed, err: %v", err)
+	}
+	defer sink.Close()
+
+	msg := []byte(strings.Repeat("a", 600*1024))
+	for i := 0; i < 4; i++ {
+		if err := sink.Send([][]byte{msg}); err != nil {
+			t.Fatalf("send message failed, err: %v", err)
+		}
+	}
+
+	for _, name := range []string{path, path + ".1", path + ".2"} {
+		info, err := os.Stat(name)
+		if err != nil {
+			t.Fatalf("stat %s failed, err: %v", name, err)
+		}
+		if info.Size() != int64(len(msg)+1) {
+			t.Fatalf("file %s size %d is invalid", name, info.Size())
+		}
+	}
+
+	if _, err := os.Stat(path + ".3"); !os.IsNotExist(err) {
+		t.Fatalf("backups more than max backups are kept")
+	}
+}
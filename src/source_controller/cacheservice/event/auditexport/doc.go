@@ -0,0 +1,24 @@
+/*
+ * Tencent is pleased to support the open source community by making 蓝鲸 available.
+ * Copyright (C) 2017-2018 THL A29 Limited, a Tencent company. All rights reserved.
+ * Licensed under the MIT License (the "License"); you may not use this file except
+ * in compliance with the License. You may obtain a copy of the License at
+ * http://opensource.org/licenses/MIT
+ * Unless required by applicable law or agreed to in writing, software distributed under
+ * the License is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND,
+ * either express or implied. See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package auditexport
+
+/*
+  Audit log export ships the newly created audit logs to the SIEM in near real time. It has these features as follows:
+  1. It watches the insert events of cc_AuditLog with the loop watch, only the master cache service does the export.
+  2. The audit logs are formatted to JSON(the same as the audit log api) or CEF(Common Event Format) messages, and
+    sent to one of the sinks: syslog over TCP/UDP, kafka or the local files that are rotated by size.
+  3. The watch token is saved after the batch of audit logs are all sent successfully, if the sending failed, the
+    batch is re-watched from the last token and sent again, so the delivery is at least once and the receivers may
+    get duplicate messages identified by the audit log id.
+  4. The export is disabled by default, it is enabled by the cacheService.auditExport config in common.yaml.
+*/
@@ -0,0 +1,127 @@
+/*
+ * Tencent is pleased to support the open source community by making 蓝鲸 available.
+ * Copyright (C) 2017-2018 THL A29 Limited, a Tencent company. All rights reserved.
+ * Licensed under the MIT License (the "License"); you may not use this file except
+ * in compliance with the License. You may obtain a copy of the License at
+ * http://opensource.org/licenses/MIT
+ * Unless required by applicable law or agreed to in writing, software distributed under
+ * the License is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND,
+ * either express or implied. See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package auditexport
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+)
+
+// fileSink writes the messages to the local file line by line, the file is rotated when it exceeds the max size,
+// and at most maxBackups rotated files(path.1 ~ path.N, path.1 is the newest) are kept.
+type fileSink struct {
+	conf    fileConfig
+	maxSize int64
+	file    *os.File
+	size    int64
+	lock    sync.Mutex
+}
+
+func newFileSink(conf fileConfig) (*fileSink, error) {
+	if err := os.MkdirAll(filepath.Dir(conf.path), 0755); err != nil {
+		return nil, fmt.Errorf("create audit export file directory failed, err: %v", err)
+	}
+
+	f := &fileSink{conf: conf, maxSize: int64(conf.maxSizeMB) * 1024 * 1024}
+	if err := f.open(); err != nil {
+		return nil, err
+	}
+	return f, nil
+}
+
+func (f *fileSink) open() error {
+	file, err := os.OpenFile(f.conf.path, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0644)
+	if err != nil {
+		return fmt.Errorf("open audit export file %s failed, err: %v", f.conf.path, err)
+	}
+
+	info, err := file.Stat()
+	if err != nil {
+		_ = file.Close()
+		return fmt.Errorf("stat audit export file %s failed, err: %v", f.conf.path, err)
+	}
+
+	f.file = file
+	f.size = info.Size()
+	return nil
+}
+
+// rotate renames path.N-1 to path.N ... path to path.1, the oldest backup is overwritten, then reopens the file
+func (f *fileSink) rotate() error {
+	if f.file != nil {
+		_ = f.file.Close()
+		f.file = nil
+	}
+
+	for i := f.conf.maxBackups - 1; i > 0; i-- {
+		src := fmt.Sprintf("%s.%d", f.conf.path, i)
+		if _, err := os.Stat(src); err != nil {
+			continue
+		}
+		if err := os.Rename(src, fmt.Sprintf("%s.%d", f.conf.path, i+1)); err != nil {
+			return fmt.Errorf("rotate audit export file %s failed, err: %v", src, err)
+		}
+	}
+
+	if err := os.Rename(f.conf.path, f.conf.path+".1"); err != nil && !os.IsNotExist(err) {
+		return fmt.Errorf("rotate audit export file %s failed, err: %v", f.conf.path, err)
+	}
+
+	return f.open()
+}
+
+// Send writes the messages to the file and syncs them to disk, so that the watch token is saved only after the
+// messages are persisted
+func (f *fileSink) Send(messages [][]byte) error {
+	f.lock.Lock()
+	defer f.lock.Unlock()
+
+	if f.file == nil {
+		if err := f.open(); err != nil {
+			return err
+		}
+	}
+
+	for _, msg := range messages {
+		length := int64(len(msg) + 1)
+		if f.size > 0 && f.size+length > f.maxSize {
+			if err := f.rotate(); err != nil {
+				return err
+			}
+		}
+
+		n, err := f.file.Write(append(msg, '\n'))
+		f.size += int64(n)
+		if err != nil {
+			return fmt.Errorf("write audit export file %s failed, err: %v", f.conf.path, err)
+		}
+	}
+
+	if err := f.file.Sync(); err != nil {
+		return fmt.Errorf("sync audit export file %s failed, err: %v", f.conf.path, err)
+	}
+	return nil
+}
+
+// Close the file
+func (f *fileSink) Close() {
+	f.lock.Lock()
+	defer f.lock.Unlock()
+
+	if f.file != nil {
+		_ = f.file.Close()
+		f.file = nil
+	}
+}
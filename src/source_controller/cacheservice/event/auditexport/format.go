@@ -0,0 +1,126 @@
+/*
+ * Tencent is pleased to support the open source community by making 蓝鲸 available.
+ * Copyright (C) 2017-2018 THL A29 Limited, a Tencent company. All rights reserved.
+ * Licensed under the MIT License (the "License"); you may not use this file except
+ * in compliance with the License. You may obtain a copy of the License at
+ * http://opensource.org/licenses/MIT
+ * Unless required by applicable law or agreed to in writing, software distributed under
+ * the License is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND,
+ * either express or implied. See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package auditexport
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"configcenter/src/common/json"
+	"configcenter/src/common/metadata"
+	"configcenter/src/common/version"
+)
+
+// Format is the message format of the exported audit logs
+type Format string
+
+const (
+	// FormatJSON formats the audit log as json, which is the same as the audit log api returns
+	FormatJSON Format = "json"
+	// FormatCEF formats the audit log in the ArcSight Common Event Format
+	FormatCEF Format = "cef"
+)
+
+const (
+	cefVendor  = "Tencent"
+	cefProduct = "BlueKing CMDB"
+)
+
+// formatter formats an audit log to the message sent to the sink
+type formatter interface {
+	Format(auditLog *metadata.AuditLog) ([]byte, error)
+}
+
+func newFormatter(format Format) formatter {
+	if format == FormatCEF {
+		return new(cefFormatter)
+	}
+	return new(jsonFormatter)
+}
+
+type jsonFormatter struct{}
+
+// Format the audit log as json
+func (f *jsonFormatter) Format(auditLog *metadata.AuditLog) ([]byte, error) {
+	return json.Marshal(auditLog)
+}
+
+type cefFormatter struct{}
+
+// Format the audit log as CEF, the header is "CEF:Version|Device Vendor|Device Product|Device Version|Signature ID|
+// Name|Severity|Extension", the signature id is the resource type and action, the details are put in the extension.
+func (f *cefFormatter) Format(auditLog *metadata.AuditLog) ([]byte, error) {
+	detail, err := json.Marshal(auditLog.OperationDetail)
+	if err != nil {
+		return nil, err
+	}
+
+	header := []string{
+		"CEF:0",
+		escapeCEFHeader(cefVendor),
+		escapeCEFHeader(cefProduct),
+		escapeCEFHeader(version.CCVersion),
+		escapeCEFHeader(fmt.Sprintf("%s:%s", auditLog.ResourceType, auditLog.Action)),
+		escapeCEFHeader(fmt.Sprintf("%s %s", auditLog.Action, auditLog.ResourceType)),
+		strconv.Itoa(cefSeverity(auditLog.Action)),
+	}
+
+	extension := []string{
+		"rt=" + strconv.FormatInt(auditLog.OperationTime.UnixNano()/1e6, 10),
+		"externalId=" + strconv.FormatInt(auditLog.ID, 10),
+		"suser=" + escapeCEFExtension(auditLog.User),
+		"act=" + escapeCEFExtension(string(auditLog.Action)),
+		"cat=" + escapeCEFExtension(string(auditLog.AuditType)),
+		"cs1Label=resourceType cs1=" + escapeCEFExtension(string(auditLog.ResourceType)),
+		"cs2Label=resourceId cs2=" + escapeCEFExtension(fmt.Sprintf("%v", auditLog.ResourceID)),
+		"cs3Label=resourceName cs3=" + escapeCEFExtension(auditLog.ResourceName),
+		"cs4Label=supplierAccount cs4=" + escapeCEFExtension(auditLog.SupplierAccount),
+		"cs5Label=operateFrom cs5=" + escapeCEFExtension(string(auditLog.OperateFrom)),
+		"cs6Label=requestId cs6=" + escapeCEFExtension(auditLog.RequestID),
+		"cn1Label=bizId cn1=" + strconv.FormatInt(auditLog.BusinessID, 10),
+	}
+
+	if auditLog.AppCode != "" {
+		extension = append(extension, "requestClientApplication="+escapeCEFExtension(auditLog.AppCode))
+	}
+
+	extension = append(extension, "msg="+escapeCEFExtension(string(detail)))
+
+	return []byte(strings.Join(header, "|") + "|" + strings.Join(extension, " ")), nil
+}
+
+// cefSeverity the removal of resources is more important than the others
+func cefSeverity(action metadata.ActionType) int {
+	switch action {
+	case metadata.AuditDelete, metadata.AuditArchive:
+		return 7
+	case metadata.AuditCreate, metadata.AuditUpdate, metadata.AuditRestore:
+		return 5
+	default:
+		return 3
+	}
+}
+
+var (
+	cefHeaderEscaper    = strings.NewReplacer(`\`, `\\`, `|`, `\|`, "\r", " ", "\n", " ")
+	cefExtensionEscaper = strings.NewReplacer(`\`, `\\`, `=`, `\=`, "\r", `\r`, "\n", `\n`)
+)
+
+func escapeCEFHeader(value string) string {
+	return cefHeaderEscaper.Replace(value)
+}
+
+func escapeCEFExtension(value string) string {
+	return cefExtensionEscaper.Replace(value)
+}
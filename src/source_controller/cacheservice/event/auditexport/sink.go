@@ -0,0 +1,175 @@
+/*
+ * Tencent is pleased to support the open source community by making 蓝鲸 available.
+ * Copyright (C) 2017-2018 THL A29 Limited, a Tencent company. All rights reserved.
+ * Licensed under the MIT License (the "License"); you may not use this file except
+ * in compliance with the License. You may obtain a copy of the License at
+ * http://opensource.org/licenses/MIT
+ * Unless required by applicable law or agreed to in writing, software distributed under
+ * the License is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND,
+ * either express or implied. See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package auditexport
+
+import (
+	"fmt"
+	"net"
+	"os"
+	"strconv"
+	"sync"
+	"time"
+
+	"configcenter/src/storage/dal/kafka"
+
+	"github.com/Shopify/sarama"
+)
+
+// Sink is where the audit log messages are sent to
+type Sink interface {
+	// Send sends the messages in order, if it returns an error, all the messages will be sent again later
+	Send(messages [][]byte) error
+	// Close releases the resources of the sink
+	Close()
+}
+
+func newSink(conf *exportConfig) (Sink, error) {
+	switch conf.sink {
+	case sinkTypeSyslog:
+		return newSyslogSink(conf.syslog), nil
+	case sinkTypeKafka:
+		return newKafkaSink(conf.kafka)
+	case sinkTypeFile:
+		return newFileSink(conf.file)
+	default:
+		return nil, fmt.Errorf("audit export sink %s is invalid", conf.sink)
+	}
+}
+
+const (
+	syslogDialTimeout  = 5 * time.Second
+	syslogWriteTimeout = 10 * time.Second
+	// syslogPriority is facility "log audit"(13) with severity "notice"(5)
+	syslogPriority = 13*8 + 5
+)
+
+// syslogSink sends the messages to the syslog server in RFC 5424 format. the tcp messages are framed by octet
+// counting(RFC 6587), and the udp messages are sent one per datagram, which are not guaranteed to be received.
+type syslogSink struct {
+	conf     syslogConfig
+	hostname string
+	conn     net.Conn
+	lock     sync.Mutex
+}
+
+func newSyslogSink(conf syslogConfig) *syslogSink {
+	hostname, err := os.Hostname()
+	if err != nil || hostname == "" {
+		hostname = "-"
+	}
+
+	return &syslogSink{conf: conf, hostname: hostname}
+}
+
+// Send the messages to syslog server, the connection is re-established after it failed
+func (s *syslogSink) Send(messages [][]byte) error {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	if s.conn == nil {
+		conn, err := net.DialTimeout(s.conf.network, s.conf.address, syslogDialTimeout)
+		if err != nil {
+			return fmt.Errorf("connect to syslog %s://%s failed, err: %v", s.conf.network, s.conf.address, err)
+		}
+		s.conn = conn
+	}
+
+	for _, msg := range messages {
+		frame := s.frame(msg)
+		if err := s.conn.SetWriteDeadline(time.Now().Add(syslogWriteTimeout)); err != nil {
+			s.closeConn()
+			return err
+		}
+
+		if _, err := s.conn.Write(frame); err != nil {
+			s.closeConn()
+			return fmt.Errorf("write to syslog %s://%s failed, err: %v", s.conf.network, s.conf.address, err)
+		}
+	}
+
+	return nil
+}
+
+// frame the message as "<PRI>1 TIMESTAMP HOSTNAME APP-NAME PROCID MSGID STRUCTURED-DATA MSG"
+func (s *syslogSink) frame(msg []byte) []byte {
+	header := fmt.Sprintf("<%d>1 %s %s %s %d audit - ", syslogPriority, time.Now().Format(time.RFC3339),
+		s.hostname, s.conf.appName, os.Getpid())
+
+	line := make([]byte, 0, len(header)+len(msg)+16)
+	if s.conf.network == syslogNetworkTCP {
+		line = append(line, strconv.Itoa(len(header)+len(msg))...)
+		line = append(line, ' ')
+	}
+	line = append(line, header...)
+	return append(line, msg...)
+}
+
+func (s *syslogSink) closeConn() {
+	if s.conn != nil {
+		_ = s.conn.Close()
+		s.conn = nil
+	}
+}
+
+// Close the syslog connection
+func (s *syslogSink) Close() {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	s.closeConn()
+}
+
+// kafkaSink sends the messages to the kafka topic, the messages are acknowledged by all the in-sync replicas
+type kafkaSink struct {
+	topic    string
+	producer sarama.SyncProducer
+}
+
+func newKafkaSink(conf kafka.Config) (*kafkaSink, error) {
+	config := sarama.NewConfig()
+	config.Version = sarama.V0_10_2_0
+	config.Producer.RequiredAcks = sarama.WaitForAll
+	config.Producer.Return.Successes = true
+	config.Producer.Retry.Max = 3
+	if conf.User != "" && conf.Password != "" {
+		config.Net.SASL.Enable = true
+		config.Net.SASL.User = conf.User
+		config.Net.SASL.Password = conf.Password
+		config.Net.SASL.Handshake = true
+		config.Net.SASL.SCRAMClientGeneratorFunc = func() sarama.SCRAMClient {
+			return &kafka.XDGSCRAMClient{HashGeneratorFcn: kafka.SHA512}
+		}
+		config.Net.SASL.Mechanism = sarama.SASLTypeSCRAMSHA512
+	}
+
+	producer, err := sarama.NewSyncProducer(conf.Brokers, config)
+	if err != nil {
+		return nil, fmt.Errorf("create kafka producer failed, err: %v", err)
+	}
+
+	return &kafkaSink{topic: conf.Topic, producer: producer}, nil
+}
+
+// Send the messages to kafka
+func (k *kafkaSink) Send(messages [][]byte) error {
+	msgs := make([]*sarama.ProducerMessage, len(messages))
+	for index, msg := range messages {
+		msgs[index] = &sarama.ProducerMessage{Topic: k.topic, Value: sarama.ByteEncoder(msg)}
+	}
+
+	return k.producer.SendMessages(msgs)
+}
+
+// Close the kafka producer
+func (k *kafkaSink) Close() {
+	_ = k.producer.Close()
+}
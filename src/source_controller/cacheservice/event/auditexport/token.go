@@ -0,0 +1,112 @@
+/*
+ * Tencent is pleased to support the open source community by making 蓝鲸 available.
+ * Copyright (C) 2017-2018 THL A29 Limited, a Tencent company. All rights reserved.
+ * Licensed under the MIT License (the "License"); you may not use this file except
+ * in compliance with the License. You may obtain a copy of the License at
+ * http://opensource.org/licenses/MIT
+ * Unless required by applicable law or agreed to in writing, software distributed under
+ * the License is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND,
+ * either express or implied. See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package auditexport
+
+import (
+	"context"
+	"time"
+
+	"configcenter/src/common"
+	"configcenter/src/common/blog"
+	"configcenter/src/common/mapstr"
+	"configcenter/src/storage/dal"
+	"configcenter/src/storage/stream/types"
+)
+
+const (
+	// exportWatchTokenDoc is the id of the document in system table that stores the audit log export watch token
+	exportWatchTokenDoc = "audit_log_export_watch_token"
+	tokenField          = "token"
+	startTimeField      = "start_time"
+)
+
+func newTokenHandler(db dal.DB) *tokenHandler {
+	return &tokenHandler{db: db}
+}
+
+// tokenHandler saves the resume token of the audit log export watch, the token is the last sent audit log's event
+type tokenHandler struct {
+	db dal.DB
+}
+
+// SetLastWatchToken set the last watch token after the audit logs are sent
+func (t *tokenHandler) SetLastWatchToken(ctx context.Context, token string) error {
+	var err error
+	filter := map[string]interface{}{"_id": exportWatchTokenDoc}
+	tokenData := mapstr.MapStr{tokenField: token}
+
+	// do with retry
+	for try := 0; try < 5; try++ {
+		err = t.db.Table(common.BKTableNameSystem).Upsert(ctx, filter, tokenData)
+		if err != nil {
+			blog.Errorf("set audit log export watch token %s failed, err: %v", token, err)
+			time.Sleep(time.Duration(try/2+1) * time.Second)
+			continue
+		}
+		return nil
+	}
+
+	return err
+}
+
+// GetStartWatchToken get the last watch token to resume the export from, returns "" if not exists
+func (t *tokenHandler) GetStartWatchToken(ctx context.Context) (string, error) {
+	var err error
+	filter := map[string]interface{}{"_id": exportWatchTokenDoc}
+
+	// do with retry
+	for try := 0; try < 5; try++ {
+		tokenData := make(map[string]string)
+		err = t.db.Table(common.BKTableNameSystem).Find(filter).Fields(tokenField).One(ctx, &tokenData)
+		if err != nil {
+			if t.db.IsNotFoundError(err) {
+				return "", nil
+			}
+			blog.Errorf("get audit log export watch token failed, err: %v", err)
+			time.Sleep(time.Duration(try/2+1) * time.Second)
+			continue
+		}
+		return tokenData[tokenField], nil
+	}
+
+	return "", err
+}
+
+// resetWatchToken set watch token to empty and set the start watch time to the given one for next watch
+func (t *tokenHandler) resetWatchToken(startAtTime types.TimeStamp) error {
+	filter := map[string]interface{}{"_id": exportWatchTokenDoc}
+	tokenData := mapstr.MapStr{
+		tokenField:     "",
+		startTimeField: startAtTime,
+	}
+
+	return t.db.Table(common.BKTableNameSystem).Upsert(context.Background(), filter, tokenData)
+}
+
+// getStartWatchTime get the time to start the watch when the token is reset, starts from now if not set
+func (t *tokenHandler) getStartWatchTime() (*types.TimeStamp, error) {
+	filter := map[string]interface{}{"_id": exportWatchTokenDoc}
+
+	data := make(map[string]types.TimeStamp)
+	err := t.db.Table(common.BKTableNameSystem).Find(filter).Fields(startTimeField).One(context.Background(), &data)
+	if err != nil {
+		if !t.db.IsNotFoundError(err) {
+			blog.Errorf("get audit log export start watch time failed, err: %v", err)
+			return nil, err
+		}
+		return new(types.TimeStamp), nil
+	}
+
+	startTime := data[startTimeField]
+	return &startTime, nil
+}
@@ -0,0 +1,310 @@
+/*
+ * Tencent is pleased to support the open source community by making 蓝鲸 available.
+ * Copyright (C) 2017-2018 THL A29 Limited, a Tencent company. All rights reserved.
+ * Licensed under the MIT License (the "License"); you may not use this file except
+ * in compliance with the License. You may obtain a copy of the License at
+ * http://opensource.org/licenses/MIT
+ * Unless required by applicable law or agreed to in writing, software distributed under
+ * the License is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND,
+ * either express or implied. See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package dgmember
+
+import (
+	"context"
+	"strings"
+
+	"configcenter/pkg/filter"
+	"configcenter/src/common"
+	"configcenter/src/common/blog"
+	"configcenter/src/common/metadata"
+	"configcenter/src/common/util"
+	"configcenter/src/storage/stream/types"
+
+	"github.com/tidwall/gjson"
+	"go.mongodb.org/mongo-driver/x/bsonx"
+)
+
+// trackedGroup is the dynamic group whose members are tracked, with its conditions classified by object id
+type trackedGroup struct {
+	bizID int64
+	id    string
+	conds map[string]*filter.Expression
+}
+
+// getTrackedGroups get all the host dynamic groups whose members are tracked
+func (d *dynamicGroupMember) getTrackedGroups(rid string) ([]*trackedGroup, error) {
+	cond := map[string]interface{}{
+		"track_member":      true,
+		common.BKObjIDField: common.BKInnerObjIDHost,
+	}
+
+	groups := make([]metadata.DynamicGroup, 0)
+	err := d.ccDB.Table(common.BKTableNameDynamicGroup).Find(cond).Fields(common.BKAppIDField, common.BKFieldID,
+		"info").All(context.Background(), &groups)
+	if err != nil {
+		d.metrics.CollectMongoError()
+		blog.Errorf("get tracked dynamic groups failed, cond: %+v, err: %v, rid: %s", cond, err, rid)
+		return nil, err
+	}
+
+	trackedGroups := make([]*trackedGroup, 0)
+	for _, group := range groups {
+		if len(group.Info.FilterCondition) == 0 {
+			blog.Errorf("tracked dynamic group %s has no filter condition, skip, rid: %s", group.ID, rid)
+			continue
+		}
+
+		conds := make(map[string]*filter.Expression)
+		for _, condition := range group.Info.FilterCondition {
+			if condition.Filter != nil {
+				conds[condition.ObjID] = condition.Filter
+			}
+		}
+
+		trackedGroups = append(trackedGroups, &trackedGroup{bizID: group.AppID, id: group.ID, conds: conds})
+	}
+
+	return trackedGroups, nil
+}
+
+// getCareFields get the fields of the object that are used in the tracked dynamic groups' conditions
+func getCareFields(groups []*trackedGroup, objID string) map[string]struct{} {
+	fields := make(map[string]struct{})
+	for _, group := range groups {
+		cond, exists := group.conds[objID]
+		if !exists {
+			continue
+		}
+
+		// embedded fields of object and array fields are checked by its root field.
+		for _, field := range cond.RuleFields() {
+			fields[strings.Split(field, ".")[0]] = struct{}{}
+		}
+	}
+	return fields
+}
+
+// isEventCared check if the update event changes the cared fields, the event without change description is cared.
+func isEventCared(e *types.Event, careFields map[string]struct{}) bool {
+	if e.ChangeDesc == nil || (len(e.ChangeDesc.UpdatedFields) == 0 && len(e.ChangeDesc.RemovedFields) == 0) {
+		return true
+	}
+
+	for field := range e.ChangeDesc.UpdatedFields {
+		if _, exists := careFields[strings.Split(field, ".")[0]]; exists {
+			return true
+		}
+	}
+
+	for _, field := range e.ChangeDesc.RemovedFields {
+		if _, exists := careFields[strings.Split(field, ".")[0]]; exists {
+			return true
+		}
+	}
+
+	return false
+}
+
+// getHostEventHostIDs get the hosts of the host events that may change the dynamic group members
+// host events arrange policy:
+// 1. do not care insert and delete events, host relation events are generated when host joins or leaves business.
+// 2. only care about update events that changes the fields used in the tracked dynamic groups' host conditions.
+func (d *dynamicGroupMember) getHostEventHostIDs(es []*types.Event, groups []*trackedGroup, rid string) []int64 {
+	careFields := getCareFields(groups, common.BKInnerObjIDHost)
+	if len(careFields) == 0 {
+		return make([]int64, 0)
+	}
+
+	hostIDs := make([]int64, 0)
+	for _, e := range es {
+		if e.OperationType != types.Update && e.OperationType != types.Replace {
+			continue
+		}
+
+		if !isEventCared(e, careFields) {
+			blog.V(4).Infof("dynamic group member event, host changed detail do not care, skip, oid: %s, rid: %s",
+				e.ID(), rid)
+			continue
+		}
+
+		hostID := gjson.GetBytes(e.DocBytes, common.BKHostIDField).Int()
+		if hostID <= 0 {
+			blog.Errorf("dynamic group member event, get host id from host: %s failed, skip, rid: %s", e.DocBytes, rid)
+			continue
+		}
+		hostIDs = append(hostIDs, hostID)
+	}
+
+	return util.IntArrayUnique(hostIDs)
+}
+
+// getHostRelationEventHostIDs get the hosts of all the host relation events, deleted relations are got from archive.
+func (d *dynamicGroupMember) getHostRelationEventHostIDs(es []*types.Event, rid string) ([]int64, error) {
+	hostIDs := make([]int64, 0)
+	deleteOids := make([]string, 0)
+	for _, e := range es {
+		if e.OperationType == types.Delete {
+			deleteOids = append(deleteOids, e.Oid)
+			continue
+		}
+
+		hostID := gjson.GetBytes(e.DocBytes, common.BKHostIDField).Int()
+		if hostID <= 0 {
+			blog.Errorf("dynamic group member event, get host id from relation: %s failed, skip, rid: %s",
+				e.DocBytes, rid)
+			continue
+		}
+		hostIDs = append(hostIDs, hostID)
+	}
+
+	if len(deleteOids) == 0 {
+		return util.IntArrayUnique(hostIDs), nil
+	}
+
+	filter := map[string]interface{}{
+		"oid":  map[string]interface{}{common.BKDBIN: deleteOids},
+		"coll": common.BKTableNameModuleHostConfig,
+	}
+
+	docs := make([]bsonx.Doc, 0)
+	err := d.ccDB.Table(common.BKTableNameDelArchive).Find(filter).All(context.Background(), &docs)
+	if err != nil {
+		d.metrics.CollectMongoError()
+		blog.Errorf("get archived host relations failed, oids: %+v, err: %v, rid: %s", deleteOids, err, rid)
+		return nil, err
+	}
+
+	for _, doc := range docs {
+		hostID, ok := doc.Lookup("detail", common.BKHostIDField).Int64OK()
+		if !ok || hostID <= 0 {
+			blog.Errorf("archived host relation's host id is invalid, skip, relation: %s, rid: %s",
+				doc.Lookup("detail").String(), rid)
+			continue
+		}
+		hostIDs = append(hostIDs, hostID)
+	}
+
+	return util.IntArrayUnique(hostIDs), nil
+}
+
+// getTopoEventHostIDs get the hosts in the sets or modules of the set or module events
+// topology events arrange policy:
+// 1. do not care insert and delete events, there is no host in the set or module when it's created or deleted.
+// 2. only care about update events that changes the fields used in the tracked dynamic groups' conditions.
+func (d *dynamicGroupMember) getTopoEventHostIDs(es []*types.Event, groups []*trackedGroup, objID string,
+	rid string) ([]int64, error) {
+
+	careFields := getCareFields(groups, objID)
+	if len(careFields) == 0 {
+		return make([]int64, 0), nil
+	}
+
+	idField := common.GetInstIDField(objID)
+	instIDs := make([]int64, 0)
+	for _, e := range es {
+		if e.OperationType != types.Update && e.OperationType != types.Replace {
+			continue
+		}
+
+		if !isEventCared(e, careFields) {
+			blog.V(4).Infof("dynamic group member event, %s changed detail do not care, skip, oid: %s, rid: %s",
+				objID, e.ID(), rid)
+			continue
+		}
+
+		instID := gjson.GetBytes(e.DocBytes, idField).Int()
+		if instID <= 0 {
+			blog.Errorf("dynamic group member event, get %s id from %s failed, skip, rid: %s", objID, e.DocBytes, rid)
+			continue
+		}
+		instIDs = append(instIDs, instID)
+	}
+
+	if len(instIDs) == 0 {
+		return make([]int64, 0), nil
+	}
+
+	cond := map[string]interface{}{
+		idField: map[string]interface{}{common.BKDBIN: util.IntArrayUnique(instIDs)},
+	}
+	ids, err := d.ccDB.Table(common.BKTableNameModuleHostConfig).Distinct(context.Background(),
+		common.BKHostIDField, cond)
+	if err != nil {
+		d.metrics.CollectMongoError()
+		blog.Errorf("get host ids by %s ids failed, cond: %+v, err: %v, rid: %s", objID, cond, err, rid)
+		return nil, err
+	}
+
+	hostIDs, err := util.SliceInterfaceToInt64(ids)
+	if err != nil {
+		blog.Errorf("parse host ids(%+v) failed, err: %v, rid: %s", ids, err, rid)
+		return nil, err
+	}
+
+	return hostIDs, nil
+}
+
+// groupCareFields are the dynamic group fields that change its members
+var groupCareFields = map[string]struct{}{"track_member": {}, "info": {}}
+
+// getChangedGroups get the tracked dynamic groups of the dynamic group events whose members need to be recomputed
+// dynamic group events arrange policy:
+// 1. do not care delete events, the members are cleared when the dynamic group is deleted.
+// 2. care about insert events and update events that start to track the members or change the conditions.
+func getChangedGroups(es []*types.Event, groups []*trackedGroup, rid string) []*trackedGroup {
+	changedIDs := make(map[string]struct{})
+	for _, e := range es {
+		if e.OperationType == types.Delete {
+			continue
+		}
+
+		if !isEventCared(e, groupCareFields) {
+			blog.V(4).Infof("dynamic group member event, dynamic group changed detail do not care, skip, oid: %s, "+
+				"rid: %s", e.ID(), rid)
+			continue
+		}
+
+		changedIDs[gjson.GetBytes(e.DocBytes, common.BKFieldID).String()] = struct{}{}
+	}
+
+	changedGroups := make([]*trackedGroup, 0)
+	for _, group := range groups {
+		if _, exists := changedIDs[group.id]; exists {
+			changedGroups = append(changedGroups, group)
+		}
+	}
+	return changedGroups
+}
+
+// getGroupHostIDs get the hosts that may be the dynamic group's members, which are the hosts in its business and its
+// current members that may have left the business.
+func (d *dynamicGroupMember) getGroupHostIDs(group *trackedGroup, rid string) ([]int64, error) {
+	relationCond := map[string]interface{}{common.BKAppIDField: group.bizID}
+	ids, err := d.ccDB.Table(common.BKTableNameModuleHostConfig).Distinct(context.Background(),
+		common.BKHostIDField, relationCond)
+	if err != nil {
+		d.metrics.CollectMongoError()
+		blog.Errorf("get host ids by biz failed, cond: %+v, err: %v, rid: %s", relationCond, err, rid)
+		return nil, err
+	}
+
+	memberCond := map[string]interface{}{common.BKFieldID: group.id}
+	memberIDs, err := d.ccDB.Table(common.BKTableNameDynamicGroupMember).Distinct(context.Background(),
+		common.BKHostIDField, memberCond)
+	if err != nil {
+		d.metrics.CollectMongoError()
+		blog.Errorf("get dynamic group member ids failed, cond: %+v, err: %v, rid: %s", memberCond, err, rid)
+		return nil, err
+	}
+
+	hostIDs, err := util.SliceInterfaceToInt64(append(ids, memberIDs...))
+	if err != nil {
+		blog.Errorf("parse host ids(%+v) failed, err: %v, rid: %s", ids, err, rid)
+		return nil, err
+	}
+
+	return util.IntArrayUnique(hostIDs), nil
+}
@@ -0,0 +1,100 @@
+/*
+ * Tencent is pleased to support the open source community by making 蓝鲸 available.
+ * Copyright (C) 2017-2018 THL A29 Limited, a Tencent company. All rights reserved.
+ * Licensed under the MIT License (the "License"); you may not use this file except
+ * in compliance with the License. You may obtain a copy of the License at
+ * http://opensource.org/licenses/MIT
+ * Unless required by applicable law or agreed to in writing, software distributed under
+ * the License is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND,
+ * either express or implied. See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+// Package dgmember defines the tracked dynamic group member event watch logics
+package dgmember
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"configcenter/src/common"
+	"configcenter/src/common/blog"
+	"configcenter/src/source_controller/cacheservice/event"
+	mixevent "configcenter/src/source_controller/cacheservice/event/mix-event"
+	"configcenter/src/storage/dal"
+	"configcenter/src/storage/dal/mongo/local"
+	"configcenter/src/storage/stream"
+)
+
+const (
+	dynamicGroupMemberLockKey = common.BKCacheKeyV3Prefix + "dynamic_group_member:event_lock"
+	dynamicGroupMemberLockTTL = 1 * time.Minute
+)
+
+// NewDynamicGroupMember init and run dynamic group member event watch
+func NewDynamicGroupMember(watch stream.LoopInterface, watchDB *local.Mongo, ccDB dal.DB) error {
+	base := mixevent.MixEventFlowOptions{
+		MixKey:       event.DynamicGroupMemberKey,
+		Watch:        watch,
+		WatchDB:      watchDB,
+		CcDB:         ccDB,
+		EventLockKey: dynamicGroupMemberLockKey,
+		EventLockTTL: dynamicGroupMemberLockTTL,
+	}
+
+	// members are refreshed by all the sub event flows, use one lock to refresh them one by one
+	memberLock := new(sync.Mutex)
+
+	// watch host event
+	host := base
+	host.Key = event.HostKey
+	host.WatchFields = []string{common.BKHostIDField}
+	if err := newDynamicGroupMember(context.Background(), host, memberLock); err != nil {
+		blog.Errorf("watch host event for dynamic group member failed, err: %v", err)
+		return err
+	}
+	blog.Info("watch dynamic group member events, watch host success")
+
+	// watch host relation event
+	relation := base
+	relation.Key = event.ModuleHostRelationKey
+	relation.WatchFields = []string{common.BKHostIDField}
+	if err := newDynamicGroupMember(context.Background(), relation, memberLock); err != nil {
+		blog.Errorf("watch host relation event for dynamic group member failed, err: %v", err)
+		return err
+	}
+	blog.Info("watch dynamic group member events, watch host relation success")
+
+	// watch set event
+	set := base
+	set.Key = event.SetKey
+	set.WatchFields = []string{common.BKSetIDField}
+	if err := newDynamicGroupMember(context.Background(), set, memberLock); err != nil {
+		blog.Errorf("watch set event for dynamic group member failed, err: %v", err)
+		return err
+	}
+	blog.Info("watch dynamic group member events, watch set success")
+
+	// watch module event
+	module := base
+	module.Key = event.ModuleKey
+	module.WatchFields = []string{common.BKModuleIDField}
+	if err := newDynamicGroupMember(context.Background(), module, memberLock); err != nil {
+		blog.Errorf("watch module event for dynamic group member failed, err: %v", err)
+		return err
+	}
+	blog.Info("watch dynamic group member events, watch module success")
+
+	// watch dynamic group event
+	group := base
+	group.Key = event.DynamicGroupKey
+	group.WatchFields = []string{common.BKAppIDField, common.BKFieldID, common.BKObjIDField, "track_member", "info"}
+	if err := newDynamicGroupMember(context.Background(), group, memberLock); err != nil {
+		blog.Errorf("watch dynamic group event for dynamic group member failed, err: %v", err)
+		return err
+	}
+	blog.Info("watch dynamic group member events, watch dynamic group success")
+
+	return nil
+}
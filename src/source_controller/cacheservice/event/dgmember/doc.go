@@ -0,0 +1,42 @@
+/*
+ * Tencent is pleased to support the open source community by making 蓝鲸 available.
+ * Copyright (C) 2017-2018 THL A29 Limited, a Tencent company. All rights reserved.
+ * Licensed under the MIT License (the "License"); you may not use this file except
+ * in compliance with the License. You may obtain a copy of the License at
+ * http://opensource.org/licenses/MIT
+ * Unless required by applicable law or agreed to in writing, software distributed under
+ * the License is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND,
+ * either express or implied. See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package dgmember
+
+/*
+  Dynamic group member event is a mix event of cc_HostBase, cc_ModuleHostConfig, cc_SetBase, cc_ModuleBase and
+  cc_DynamicGroup events.
+  It tracks the materialized host members of the dynamic groups whose "track_member" flag is set, and has these
+  features as follows:
+  1. Only host dynamic groups with filter expression conditions(info.filter_condition) can be tracked. The members
+     are stored in cc_DynamicGroupMember table, one document for each host of each dynamic group.
+  2. Host update, host relation, set update and module update events are converted to the hosts they affect. Host and
+     topology update events that do not change the fields used in the tracked dynamic groups' conditions are skipped.
+     Set and module events are converted to the hosts in them by the host relations.
+  3. The affected hosts are matched with the tracked dynamic groups of their business and the dynamic groups that they
+     are already in. The conditions are matched in memory with the host, its modules, sets and custom mainline
+     instances. A host joins the dynamic group if it matches the conditions but is not a member, and it leaves the
+     dynamic group if it is a member but does not match the conditions anymore.
+  4. A host joining a dynamic group generates a create event, and a host leaving a dynamic group generates a delete
+     event. The event detail is in the form of {"bk_biz_id": 1, "id": "xxx", "bk_host_id": 1}. The chain node's
+     instance id is the host id, and its sub resource is the dynamic group id, which can be used to watch the member
+     events of one dynamic group.
+  5. All the member events generated in one batch use the last original event's cluster time and oid, the cursor is
+     unique with the dynamic group id and host id as its unique key. The members are saved before the events are
+     stored, so if storing the events failed, the retried batch will not generate these member events again.
+  6. A dynamic group creation with "track_member" set, or an update that sets "track_member" or changes its conditions
+     fully recomputes its members, all the hosts in its business and its current members are matched with the latest
+     conditions. Custom mainline instance changes do not refresh the members directly, the members are refreshed when
+     the related hosts or topology changes next time. The members are cleared when the dynamic group is deleted or its
+     members are no longer tracked.
+  7. Dynamic group member's auth resource is redirect to host resource, and it's event is authorized by host event.
+*/
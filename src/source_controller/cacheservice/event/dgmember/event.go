@@ -0,0 +1,168 @@
+/*
+ * Tencent is pleased to support the open source community by making 蓝鲸 available.
+ * Copyright (C) 2017-2018 THL A29 Limited, a Tencent company. All rights reserved.
+ * Licensed under the MIT License (the "License"); you may not use this file except
+ * in compliance with the License. You may obtain a copy of the License at
+ * http://opensource.org/licenses/MIT
+ * Unless required by applicable law or agreed to in writing, software distributed under
+ * the License is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND,
+ * either express or implied. See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package dgmember
+
+import (
+	"context"
+	"fmt"
+	"sync"
+
+	"configcenter/src/common"
+	"configcenter/src/common/blog"
+	"configcenter/src/common/json"
+	"configcenter/src/common/watch"
+	"configcenter/src/source_controller/cacheservice/event"
+	mixevent "configcenter/src/source_controller/cacheservice/event/mix-event"
+	"configcenter/src/storage/dal"
+	"configcenter/src/storage/stream/types"
+
+	"github.com/tidwall/gjson"
+)
+
+// newDynamicGroupMember init and run dynamic group member event watch with sub event key
+func newDynamicGroupMember(ctx context.Context, opts mixevent.MixEventFlowOptions, memberLock *sync.Mutex) error {
+	member := dynamicGroupMember{
+		mixKey:     opts.MixKey,
+		key:        opts.Key,
+		ccDB:       opts.CcDB,
+		memberLock: memberLock,
+		metrics:    event.InitialMetrics(opts.Key.Collection(), "dynamic_group_member"),
+	}
+
+	flow, err := mixevent.NewMixEventFlow(opts, member.rearrangeEvents, member.parseEvent)
+	if err != nil {
+		return err
+	}
+
+	return flow.RunFlow(ctx)
+}
+
+// dynamicGroupMember dynamic group member event watch logic struct
+type dynamicGroupMember struct {
+	mixKey     event.Key
+	key        event.Key
+	ccDB       dal.DB
+	memberLock *sync.Mutex
+
+	metrics *event.EventMetrics
+}
+
+// rearrangeEvents rearrange host, host relation, set, module and dynamic group events into dynamic group member
+// change events
+func (d *dynamicGroupMember) rearrangeEvents(rid string, es []*types.Event) ([]*types.Event, error) {
+	groups, err := d.getTrackedGroups(rid)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(groups) == 0 {
+		return es[:0], nil
+	}
+
+	var hostIDs []int64
+	switch d.key.Collection() {
+	case event.HostKey.Collection():
+		hostIDs = d.getHostEventHostIDs(es, groups, rid)
+	case event.ModuleHostRelationKey.Collection():
+		hostIDs, err = d.getHostRelationEventHostIDs(es, rid)
+	case event.SetKey.Collection():
+		hostIDs, err = d.getTopoEventHostIDs(es, groups, common.BKInnerObjIDSet, rid)
+	case event.ModuleKey.Collection():
+		hostIDs, err = d.getTopoEventHostIDs(es, groups, common.BKInnerObjIDModule, rid)
+	case event.DynamicGroupKey.Collection():
+		return d.reconcileGroups(groups, es, rid)
+	default:
+		blog.Errorf("received unsupported dynamic group member event, skip, es: %+v, rid: %s", es, rid)
+		return es[:0], nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	if len(hostIDs) == 0 {
+		return es[:0], nil
+	}
+
+	// member events are generated by the last event, since the members are refreshed with the latest data
+	return d.refreshMembers(groups, hostIDs, es[len(es)-1], rid)
+}
+
+// parseEvent parse dynamic group member event into chain node and detail, the detail is generated when rearranging
+func (d *dynamicGroupMember) parseEvent(e *types.Event, id uint64, rid string) (*watch.ChainNode, []byte, bool,
+	error) {
+
+	switch e.OperationType {
+	case types.Insert, types.Delete:
+	default:
+		blog.Errorf("dynamic group member event, received unsupported event operation type: %s, doc: %s, rid: %s",
+			e.OperationType, e.DocBytes, rid)
+		return nil, nil, false, nil
+	}
+
+	if err := d.mixKey.Validate(e.DocBytes); err != nil {
+		blog.Errorf("dynamic group member event, received invalid event doc: %s, err: %v, rid: %s", e.DocBytes, err,
+			rid)
+		return nil, nil, false, nil
+	}
+
+	groupID := gjson.GetBytes(e.DocBytes, common.BKFieldID).String()
+	hostID := d.mixKey.InstanceID(e.DocBytes)
+
+	cursor, err := genDynamicGroupMemberCursor(e, groupID, hostID, rid)
+	if err != nil {
+		return nil, nil, false, err
+	}
+
+	chainNode := &watch.ChainNode{
+		ID:          id,
+		ClusterTime: e.ClusterTime,
+		Oid:         e.Oid,
+		EventType:   watch.ConvertOperateType(e.OperationType),
+		Token:       e.Token.Data,
+		Cursor:      cursor,
+		InstanceID:  hostID,
+		SubResource: []string{groupID},
+	}
+
+	detail := types.EventDetail{
+		Detail: types.JsonString(e.DocBytes),
+	}
+	detailBytes, err := json.Marshal(detail)
+	if err != nil {
+		blog.Errorf("run %s flow, %s, marshal detail failed, detail: %+v, err: %v, oid: %s, rid: %s",
+			d.mixKey.Collection(), d.key.Collection(), detail, err, e.ID(), rid)
+		return nil, nil, false, err
+	}
+
+	return chainNode, detailBytes, false, nil
+}
+
+// genDynamicGroupMemberCursor generate dynamic group member event cursor, all the member events in a batch has the
+// same cluster time and oid, so the dynamic group id and host id is used as the unique key.
+func genDynamicGroupMemberCursor(e *types.Event, groupID string, hostID int64, rid string) (string, error) {
+	cursor := &watch.Cursor{
+		Type:        watch.DynamicGroupMember,
+		ClusterTime: e.ClusterTime,
+		Oid:         e.Oid,
+		Oper:        e.OperationType,
+		UniqKey:     fmt.Sprintf("%s:%d", groupID, hostID),
+	}
+
+	cursorEncode, err := cursor.Encode()
+	if err != nil {
+		blog.Errorf("encode dynamic group member cursor failed, cursor: %+v, err: %v, rid: %s", cursor, err, rid)
+		return "", err
+	}
+
+	return cursorEncode, nil
+}
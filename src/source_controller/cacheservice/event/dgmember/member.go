@@ -0,0 +1,474 @@
+/*
+ * Tencent is pleased to support the open source community by making 蓝鲸 available.
+ * Copyright (C) 2017-2018 THL A29 Limited, a Tencent company. All rights reserved.
+ * Licensed under the MIT License (the "License"); you may not use this file except
+ * in compliance with the License. You may obtain a copy of the License at
+ * http://opensource.org/licenses/MIT
+ * Unless required by applicable law or agreed to in writing, software distributed under
+ * the License is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND,
+ * either express or implied. See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package dgmember
+
+import (
+	"context"
+	"time"
+
+	"configcenter/src/common"
+	"configcenter/src/common/blog"
+	"configcenter/src/common/mapstr"
+	"configcenter/src/common/metadata"
+	"configcenter/src/common/util"
+	"configcenter/src/source_controller/cacheservice/event"
+	"configcenter/src/storage/stream/types"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// refreshMembers matches the hosts with the tracked dynamic groups, saves the changed members and generates the
+// member change events of them by the last original event.
+func (d *dynamicGroupMember) refreshMembers(groups []*trackedGroup, hostIDs []int64, last *types.Event,
+	rid string) ([]*types.Event, error) {
+
+	d.memberLock.Lock()
+	defer d.memberLock.Unlock()
+
+	memberMap, err := d.getMemberMap(hostIDs, rid)
+	if err != nil {
+		return nil, err
+	}
+
+	matcher, err := d.newMemberMatcher(groups, hostIDs, rid)
+	if err != nil {
+		return nil, err
+	}
+
+	memberEvents := make([]*types.Event, 0)
+	for _, hostID := range hostIDs {
+		for _, group := range groups {
+			_, isMember := memberMap[group.id][hostID]
+			if !isMember && !matcher.isInBiz(hostID, group.bizID) {
+				continue
+			}
+
+			matched, err := matcher.match(group, hostID)
+			if err != nil {
+				blog.Errorf("match host %d with dynamic group %s failed, skip, err: %v, rid: %s", hostID, group.id,
+					err, rid)
+				continue
+			}
+
+			var changed bool
+			var operation types.OperType
+			switch {
+			case matched && !isMember:
+				operation = types.Insert
+				changed, err = d.joinGroup(group, hostID, rid)
+			case !matched && isMember:
+				operation = types.Delete
+				changed, err = d.leaveGroup(group, hostID, rid)
+			}
+			if err != nil {
+				return nil, err
+			}
+
+			if !changed {
+				continue
+			}
+
+			memberEvents = append(memberEvents, &types.Event{
+				Oid:           last.Oid,
+				DocBytes:      []byte(event.GenDynamicGroupMemberDetail(group.bizID, group.id, hostID)),
+				OperationType: operation,
+				Collection:    d.mixKey.Collection(),
+				ClusterTime:   last.ClusterTime,
+				Token:         last.Token,
+			})
+		}
+	}
+
+	return memberEvents, nil
+}
+
+// reconcileGroupsBatchSize is the number of hosts that are refreshed in one batch when reconciling a dynamic group
+const reconcileGroupsBatchSize = 500
+
+// reconcileGroups fully recomputes the members of the tracked dynamic groups that are changed by the dynamic group
+// events, all the hosts in their businesses and their current members are matched with their latest conditions.
+func (d *dynamicGroupMember) reconcileGroups(groups []*trackedGroup, es []*types.Event, rid string) (
+	[]*types.Event, error) {
+
+	changedGroups := getChangedGroups(es, groups, rid)
+	if len(changedGroups) == 0 {
+		return es[:0], nil
+	}
+
+	memberEvents := make([]*types.Event, 0)
+	for _, group := range changedGroups {
+		hostIDs, err := d.getGroupHostIDs(group, rid)
+		if err != nil {
+			return nil, err
+		}
+
+		for start := 0; start < len(hostIDs); start += reconcileGroupsBatchSize {
+			end := start + reconcileGroupsBatchSize
+			if end > len(hostIDs) {
+				end = len(hostIDs)
+			}
+
+			events, err := d.refreshMembers([]*trackedGroup{group}, hostIDs[start:end], es[len(es)-1], rid)
+			if err != nil {
+				return nil, err
+			}
+			memberEvents = append(memberEvents, events...)
+		}
+
+		blog.Infof("reconcile dynamic group %s members of %d hosts, rid: %s", group.id, len(hostIDs), rid)
+	}
+
+	return memberEvents, nil
+}
+
+// getMemberMap get the dynamic group id to host ids map of the hosts' current members
+func (d *dynamicGroupMember) getMemberMap(hostIDs []int64, rid string) (map[string]map[int64]struct{}, error) {
+	cond := map[string]interface{}{
+		common.BKHostIDField: map[string]interface{}{common.BKDBIN: hostIDs},
+	}
+
+	members := make([]metadata.DynamicGroupMember, 0)
+	err := d.ccDB.Table(common.BKTableNameDynamicGroupMember).Find(cond).All(context.Background(), &members)
+	if err != nil {
+		d.metrics.CollectMongoError()
+		blog.Errorf("get dynamic group members failed, cond: %+v, err: %v, rid: %s", cond, err, rid)
+		return nil, err
+	}
+
+	memberMap := make(map[string]map[int64]struct{})
+	for _, member := range members {
+		if _, exists := memberMap[member.GroupID]; !exists {
+			memberMap[member.GroupID] = make(map[int64]struct{})
+		}
+		memberMap[member.GroupID][member.HostID] = struct{}{}
+	}
+	return memberMap, nil
+}
+
+// joinGroup add the host to the dynamic group's members, returns false if the host is already a member.
+func (d *dynamicGroupMember) joinGroup(group *trackedGroup, hostID int64, rid string) (bool, error) {
+	member := &metadata.DynamicGroupMember{
+		AppID:      group.bizID,
+		GroupID:    group.id,
+		HostID:     hostID,
+		CreateTime: time.Now().UTC(),
+	}
+
+	err := d.ccDB.Table(common.BKTableNameDynamicGroupMember).Insert(context.Background(), member)
+	if err != nil {
+		if d.ccDB.IsDuplicatedError(err) {
+			return false, nil
+		}
+		d.metrics.CollectMongoError()
+		blog.Errorf("add dynamic group member failed, member: %+v, err: %v, rid: %s", member, err, rid)
+		return false, err
+	}
+	return true, nil
+}
+
+// leaveGroup remove the host from the dynamic group's members, returns false if the host is not a member.
+func (d *dynamicGroupMember) leaveGroup(group *trackedGroup, hostID int64, rid string) (bool, error) {
+	cond := map[string]interface{}{
+		common.BKFieldID:     group.id,
+		common.BKHostIDField: hostID,
+	}
+
+	count, err := d.ccDB.Table(common.BKTableNameDynamicGroupMember).DeleteMany(context.Background(), cond)
+	if err != nil {
+		d.metrics.CollectMongoError()
+		blog.Errorf("delete dynamic group member failed, cond: %+v, err: %v, rid: %s", cond, err, rid)
+		return false, err
+	}
+	return count > 0, nil
+}
+
+// memberMatcher matches the hosts with the dynamic group conditions using the hosts and their topology data
+type memberMatcher struct {
+	hosts     map[int64]mapstr.MapStr
+	relations map[int64][]metadata.ModuleHost
+	sets      map[int64]mapstr.MapStr
+	modules   map[int64]mapstr.MapStr
+	// parentObjs is the custom mainline object id to its parent object id map, including set
+	parentObjs map[string]string
+	// mainlineInsts is the custom mainline object id to its instances map
+	mainlineInsts map[string]map[int64]mapstr.MapStr
+}
+
+// newMemberMatcher get the hosts and their topology data that are used in the tracked dynamic groups' conditions
+func (d *dynamicGroupMember) newMemberMatcher(groups []*trackedGroup, hostIDs []int64, rid string) (*memberMatcher,
+	error) {
+
+	m := &memberMatcher{
+		relations:     make(map[int64][]metadata.ModuleHost),
+		sets:          make(map[int64]mapstr.MapStr),
+		modules:       make(map[int64]mapstr.MapStr),
+		parentObjs:    make(map[string]string),
+		mainlineInsts: make(map[string]map[int64]mapstr.MapStr),
+	}
+
+	var err error
+	m.hosts, err = d.getInstances(common.BKInnerObjIDHost, "", hostIDs, rid)
+	if err != nil {
+		return nil, err
+	}
+
+	relationCond := map[string]interface{}{
+		common.BKHostIDField: map[string]interface{}{common.BKDBIN: hostIDs},
+	}
+	relations := make([]metadata.ModuleHost, 0)
+	err = d.ccDB.Table(common.BKTableNameModuleHostConfig).Find(relationCond).All(context.Background(), &relations)
+	if err != nil {
+		d.metrics.CollectMongoError()
+		blog.Errorf("get host relations failed, cond: %+v, err: %v, rid: %s", relationCond, err, rid)
+		return nil, err
+	}
+
+	setIDs, moduleIDs := make([]int64, 0), make([]int64, 0)
+	for _, relation := range relations {
+		m.relations[relation.HostID] = append(m.relations[relation.HostID], relation)
+		setIDs = append(setIDs, relation.SetID)
+		moduleIDs = append(moduleIDs, relation.ModuleID)
+	}
+
+	needSet, needModule, needMainline := false, false, false
+	for _, group := range groups {
+		for objID := range group.conds {
+			switch objID {
+			case common.BKInnerObjIDHost:
+			case common.BKInnerObjIDSet:
+				needSet = true
+			case common.BKInnerObjIDModule:
+				needModule = true
+			default:
+				needMainline = true
+			}
+		}
+	}
+
+	if needModule && len(moduleIDs) > 0 {
+		m.modules, err = d.getInstances(common.BKInnerObjIDModule, "", util.IntArrayUnique(moduleIDs), rid)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	if (needSet || needMainline) && len(setIDs) > 0 {
+		m.sets, err = d.getInstances(common.BKInnerObjIDSet, "", util.IntArrayUnique(setIDs), rid)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	if needMainline && len(m.sets) > 0 {
+		if err = d.getMainlineInstances(m, rid); err != nil {
+			return nil, err
+		}
+	}
+
+	return m, nil
+}
+
+// getMainlineInstances get the custom mainline instances of the sets layer by layer until reaching the business
+func (d *dynamicGroupMember) getMainlineInstances(m *memberMatcher, rid string) error {
+	cond := map[string]interface{}{
+		common.AssociationKindIDField: common.AssociationKindMainline,
+	}
+	relations := make([]metadata.Association, 0)
+	err := d.ccDB.Table(common.BKTableNameObjAsst).Find(cond).Fields(common.BKObjIDField,
+		common.BKAsstObjIDField).All(context.Background(), &relations)
+	if err != nil {
+		d.metrics.CollectMongoError()
+		blog.Errorf("get mainline associations failed, err: %v, rid: %s", err, rid)
+		return err
+	}
+
+	for _, relation := range relations {
+		m.parentObjs[relation.ObjectID] = relation.AsstObjID
+	}
+
+	supplierAccount := common.BKDefaultOwnerID
+	parentIDs := make([]int64, 0)
+	for _, set := range m.sets {
+		if account := util.GetStrByInterface(set[common.BkSupplierAccount]); account != "" {
+			supplierAccount = account
+		}
+
+		parentID, err := set.Int64(common.BKParentIDField)
+		if err != nil {
+			blog.Errorf("parse set parent id failed, err: %v, set: %+v, rid: %s", err, set, rid)
+			return err
+		}
+		parentIDs = append(parentIDs, parentID)
+	}
+
+	objID := m.parentObjs[common.BKInnerObjIDSet]
+	for objID != "" && objID != common.BKInnerObjIDApp && len(parentIDs) > 0 {
+		insts, err := d.getInstances(objID, supplierAccount, util.IntArrayUnique(parentIDs), rid)
+		if err != nil {
+			return err
+		}
+		m.mainlineInsts[objID] = insts
+
+		parentIDs = make([]int64, 0)
+		for _, inst := range insts {
+			parentID, err := inst.Int64(common.BKParentIDField)
+			if err != nil {
+				blog.Errorf("parse %s parent id failed, err: %v, inst: %+v, rid: %s", objID, err, inst, rid)
+				return err
+			}
+			parentIDs = append(parentIDs, parentID)
+		}
+
+		objID = m.parentObjs[objID]
+	}
+
+	return nil
+}
+
+// getInstances get the instances by ids, returns the instance id to instance map
+func (d *dynamicGroupMember) getInstances(objID, supplierAccount string, ids []int64, rid string) (
+	map[int64]mapstr.MapStr, error) {
+
+	idField := common.GetInstIDField(objID)
+	cond := map[string]interface{}{
+		idField: map[string]interface{}{common.BKDBIN: ids},
+	}
+
+	insts := make([]mapstr.MapStr, 0)
+	err := d.ccDB.Table(common.GetInstTableName(objID, supplierAccount)).Find(cond).All(context.Background(), &insts)
+	if err != nil {
+		d.metrics.CollectMongoError()
+		blog.Errorf("get %s instances failed, cond: %+v, err: %v, rid: %s", objID, cond, err, rid)
+		return nil, err
+	}
+
+	instMap := make(map[int64]mapstr.MapStr, len(insts))
+	for _, inst := range insts {
+		id, err := inst.Int64(idField)
+		if err != nil {
+			blog.Errorf("parse %s id failed, err: %v, inst: %+v, rid: %s", objID, err, inst, rid)
+			return nil, err
+		}
+		instMap[id] = convertTimeFields(inst)
+	}
+	return instMap, nil
+}
+
+// convertTimeFields converts the db time fields into time type so that they can be matched by the datetime operators
+func convertTimeFields(inst mapstr.MapStr) mapstr.MapStr {
+	for key, value := range inst {
+		if dateTime, ok := value.(primitive.DateTime); ok {
+			inst[key] = dateTime.Time()
+		}
+	}
+	return inst
+}
+
+// isInBiz check if the host is in the business
+func (m *memberMatcher) isInBiz(hostID, bizID int64) bool {
+	for _, relation := range m.relations[hostID] {
+		if relation.AppID == bizID {
+			return true
+		}
+	}
+	return false
+}
+
+// match check if the host matches the dynamic group conditions, the host matches if it matches the host condition
+// and one of its modules matches all the topology conditions in the dynamic group's business.
+func (m *memberMatcher) match(group *trackedGroup, hostID int64) (bool, error) {
+	host, exists := m.hosts[hostID]
+	if !exists {
+		return false, nil
+	}
+
+	if cond, exists := group.conds[common.BKInnerObjIDHost]; exists {
+		matched, err := cond.Match(host)
+		if err != nil || !matched {
+			return false, err
+		}
+	}
+
+	for _, relation := range m.relations[hostID] {
+		if relation.AppID != group.bizID {
+			continue
+		}
+
+		matched, err := m.matchTopo(group, relation)
+		if err != nil {
+			return false, err
+		}
+
+		if matched {
+			return true, nil
+		}
+	}
+
+	return false, nil
+}
+
+// matchTopo check if the host relation's topology matches all the topology conditions of the dynamic group
+func (m *memberMatcher) matchTopo(group *trackedGroup, relation metadata.ModuleHost) (bool, error) {
+	for objID, cond := range group.conds {
+		var inst mapstr.MapStr
+		switch objID {
+		case common.BKInnerObjIDHost:
+			continue
+		case common.BKInnerObjIDSet:
+			inst = m.sets[relation.SetID]
+		case common.BKInnerObjIDModule:
+			inst = m.modules[relation.ModuleID]
+		default:
+			inst = m.getSetMainlineInstance(objID, relation.SetID)
+		}
+
+		if inst == nil {
+			return false, nil
+		}
+
+		matched, err := cond.Match(inst)
+		if err != nil || !matched {
+			return false, err
+		}
+	}
+
+	return true, nil
+}
+
+// getSetMainlineInstance get the set's ancestor instance of the custom mainline object
+func (m *memberMatcher) getSetMainlineInstance(objID string, setID int64) mapstr.MapStr {
+	inst, exists := m.sets[setID]
+	if !exists {
+		return nil
+	}
+
+	parentObj := m.parentObjs[common.BKInnerObjIDSet]
+	for parentObj != "" && parentObj != common.BKInnerObjIDApp {
+		parentID, err := inst.Int64(common.BKParentIDField)
+		if err != nil {
+			return nil
+		}
+
+		inst, exists = m.mainlineInsts[parentObj][parentID]
+		if !exists {
+			return nil
+		}
+
+		if parentObj == objID {
+			return inst
+		}
+		parentObj = m.parentObjs[parentObj]
+	}
+
+	return nil
+}
@@ -119,10 +119,65 @@ func NewEvent(watch stream.LoopInterface, isMaster discovery.ServiceManageInterf
 		return err
 	}
 
+	if err := e.runKubeService(context.Background()); err != nil {
+		blog.Errorf("run kube service event flow failed, err: %v", err)
+		return err
+	}
+
+	if err := e.runKubeIngress(context.Background()); err != nil {
+		blog.Errorf("run kube ingress event flow failed, err: %v", err)
+		return err
+	}
+
+	if err := e.runKubeEndpoints(context.Background()); err != nil {
+		blog.Errorf("run kube endpoints event flow failed, err: %v", err)
+		return err
+	}
+
+	if err := e.runKubeConfigMap(context.Background()); err != nil {
+		blog.Errorf("run kube config map event flow failed, err: %v", err)
+		return err
+	}
+
 	if err := e.runProject(context.Background()); err != nil {
 		blog.Errorf("run project event flow failed, err: %v", err)
 	}
 
+	if err := e.runServiceTemplate(context.Background()); err != nil {
+		blog.Errorf("run service template event flow failed, err: %v", err)
+		return err
+	}
+
+	if err := e.runProcessTemplate(context.Background()); err != nil {
+		blog.Errorf("run process template event flow failed, err: %v", err)
+		return err
+	}
+
+	if err := e.runSetTemplate(context.Background()); err != nil {
+		blog.Errorf("run set template event flow failed, err: %v", err)
+		return err
+	}
+
+	if err := e.runFieldTemplate(context.Background()); err != nil {
+		blog.Errorf("run field template event flow failed, err: %v", err)
+		return err
+	}
+
+	if err := e.runModel(context.Background()); err != nil {
+		blog.Errorf("run model event flow failed, err: %v", err)
+		return err
+	}
+
+	if err := e.runModelAttribute(context.Background()); err != nil {
+		blog.Errorf("run model attribute event flow failed, err: %v", err)
+		return err
+	}
+
+	if err := e.runHostApplyRule(context.Background()); err != nil {
+		blog.Errorf("run host apply rule event flow failed, err: %v", err)
+		return err
+	}
+
 	gc := &gc{
 		ccDB:     ccDB,
 		isMaster: isMaster,
@@ -348,6 +403,58 @@ func (e *Event) runKubePod(ctx context.Context) error {
 	return newFlow(ctx, opts, getDeleteEventDetails, parsePodEvent)
 }
 
+func (e *Event) runKubeService(ctx context.Context) error {
+	opts := flowOptions{
+		key:         event.KubeServiceKey,
+		watch:       e.watch,
+		watchDB:     e.watchDB,
+		ccDB:        e.ccDB,
+		isMaster:    e.isMaster,
+		EventStruct: new(map[string]interface{}),
+	}
+
+	return newFlow(ctx, opts, getDeleteEventDetails, parseEvent)
+}
+
+func (e *Event) runKubeIngress(ctx context.Context) error {
+	opts := flowOptions{
+		key:         event.KubeIngressKey,
+		watch:       e.watch,
+		watchDB:     e.watchDB,
+		ccDB:        e.ccDB,
+		isMaster:    e.isMaster,
+		EventStruct: new(map[string]interface{}),
+	}
+
+	return newFlow(ctx, opts, getDeleteEventDetails, parseEvent)
+}
+
+func (e *Event) runKubeEndpoints(ctx context.Context) error {
+	opts := flowOptions{
+		key:         event.KubeEndpointsKey,
+		watch:       e.watch,
+		watchDB:     e.watchDB,
+		ccDB:        e.ccDB,
+		isMaster:    e.isMaster,
+		EventStruct: new(map[string]interface{}),
+	}
+
+	return newFlow(ctx, opts, getDeleteEventDetails, parseEvent)
+}
+
+func (e *Event) runKubeConfigMap(ctx context.Context) error {
+	opts := flowOptions{
+		key:         event.KubeConfigMapKey,
+		watch:       e.watch,
+		watchDB:     e.watchDB,
+		ccDB:        e.ccDB,
+		isMaster:    e.isMaster,
+		EventStruct: new(map[string]interface{}),
+	}
+
+	return newFlow(ctx, opts, getDeleteEventDetails, parseEvent)
+}
+
 func (e *Event) runProject(ctx context.Context) error {
 	opts := flowOptions{
 		key:         event.ProjectKey,
@@ -360,3 +467,94 @@ func (e *Event) runProject(ctx context.Context) error {
 
 	return newFlow(ctx, opts, getDeleteEventDetails, parseEvent)
 }
+
+func (e *Event) runServiceTemplate(ctx context.Context) error {
+	opts := flowOptions{
+		key:         event.ServiceTemplateKey,
+		watch:       e.watch,
+		watchDB:     e.watchDB,
+		ccDB:        e.ccDB,
+		isMaster:    e.isMaster,
+		EventStruct: new(map[string]interface{}),
+	}
+
+	return newFlow(ctx, opts, getDeleteEventDetails, parseEvent)
+}
+
+func (e *Event) runProcessTemplate(ctx context.Context) error {
+	opts := flowOptions{
+		key:         event.ProcessTemplateKey,
+		watch:       e.watch,
+		watchDB:     e.watchDB,
+		ccDB:        e.ccDB,
+		isMaster:    e.isMaster,
+		EventStruct: new(map[string]interface{}),
+	}
+
+	return newFlow(ctx, opts, getDeleteEventDetails, parseProcessTemplateEvent)
+}
+
+func (e *Event) runSetTemplate(ctx context.Context) error {
+	opts := flowOptions{
+		key:         event.SetTemplateKey,
+		watch:       e.watch,
+		watchDB:     e.watchDB,
+		ccDB:        e.ccDB,
+		isMaster:    e.isMaster,
+		EventStruct: new(map[string]interface{}),
+	}
+
+	return newFlow(ctx, opts, getDeleteEventDetails, parseEvent)
+}
+
+func (e *Event) runFieldTemplate(ctx context.Context) error {
+	opts := flowOptions{
+		key:         event.FieldTemplateKey,
+		watch:       e.watch,
+		watchDB:     e.watchDB,
+		ccDB:        e.ccDB,
+		isMaster:    e.isMaster,
+		EventStruct: new(map[string]interface{}),
+	}
+
+	return newFlow(ctx, opts, getDeleteEventDetails, parseEvent)
+}
+
+func (e *Event) runModel(ctx context.Context) error {
+	opts := flowOptions{
+		key:         event.ModelKey,
+		watch:       e.watch,
+		watchDB:     e.watchDB,
+		ccDB:        e.ccDB,
+		isMaster:    e.isMaster,
+		EventStruct: new(map[string]interface{}),
+	}
+
+	return newFlow(ctx, opts, getDeleteEventDetails, parseEvent)
+}
+
+func (e *Event) runModelAttribute(ctx context.Context) error {
+	opts := flowOptions{
+		key:         event.ModelAttributeKey,
+		watch:       e.watch,
+		watchDB:     e.watchDB,
+		ccDB:        e.ccDB,
+		isMaster:    e.isMaster,
+		EventStruct: new(map[string]interface{}),
+	}
+
+	return newFlow(ctx, opts, getDeleteEventDetails, parseModelAttributeEvent)
+}
+
+func (e *Event) runHostApplyRule(ctx context.Context) error {
+	opts := flowOptions{
+		key:         event.HostApplyRuleKey,
+		watch:       e.watch,
+		watchDB:     e.watchDB,
+		ccDB:        e.ccDB,
+		isMaster:    e.isMaster,
+		EventStruct: new(map[string]interface{}),
+	}
+
+	return newFlow(ctx, opts, getDeleteEventDetails, parseEvent)
+}
@@ -330,6 +330,50 @@ func parseKubeWorkloadEvent(db dal.DB, key event.Key, e *types.Event, oidDetailM
 	return chainNode, details, false, nil
 }
 
+// parseProcessTemplateEvent parse process template event, its sub resource is its service template id
+func parseProcessTemplateEvent(db dal.DB, key event.Key, e *types.Event, oidDetailMap map[oidCollKey][]byte, id uint64,
+	rid string) (*watch.ChainNode, []byte, bool, error) {
+
+	chainNode, details, retry, err := parseEvent(db, key, e, oidDetailMap, id, rid)
+	if err != nil {
+		return nil, nil, retry, err
+	}
+
+	// ignore invalid event
+	if chainNode == nil {
+		return nil, nil, false, nil
+	}
+
+	svcTempID := gjson.GetBytes(e.DocBytes, common.BKServiceTemplateIDField).Int()
+	chainNode.SubResource = []string{strconv.FormatInt(svcTempID, 10)}
+
+	return chainNode, details, false, nil
+}
+
+// parseModelAttributeEvent parse model attribute event, its sub resource is its object id
+func parseModelAttributeEvent(db dal.DB, key event.Key, e *types.Event, oidDetailMap map[oidCollKey][]byte, id uint64,
+	rid string) (*watch.ChainNode, []byte, bool, error) {
+
+	chainNode, details, retry, err := parseEvent(db, key, e, oidDetailMap, id, rid)
+	if err != nil {
+		return nil, nil, retry, err
+	}
+
+	// ignore invalid event
+	if chainNode == nil {
+		return nil, nil, false, nil
+	}
+
+	objID := gjson.GetBytes(e.DocBytes, common.BKObjIDField).String()
+	if len(objID) == 0 {
+		blog.Errorf("model attribute event has no object id, oid: %s, doc: %s, rid: %s", e.Oid, e.DocBytes, rid)
+		return nil, nil, false, nil
+	}
+	chainNode.SubResource = []string{objID}
+
+	return chainNode, details, false, nil
+}
+
 // parseEventToNodeAndDetail parse validated event into db chain nodes to store in db and details to store in redis
 func parseEventToNodeAndDetail(key event.Key, e *types.Event, id uint64, rid string) (*watch.ChainNode, []byte, bool,
 	error) {
@@ -325,6 +325,58 @@ func GenBizSetRelationDetail(bizSetID int64, bizIDsStr string) string {
 	return fmt.Sprintf(`{"bk_biz_set_id":%d,"bk_biz_ids":[%s]}`, bizSetID, bizIDsStr)
 }
 
+// dynamicGroupMemberWatchCollName a virtual collection name for host, host relation, set, module & dynamic group events
+// in the form of the tracked dynamic groups' member change events
+const dynamicGroupMemberWatchCollName = "cc_dynamicGroupMemberMixed"
+
+// DynamicGroupMemberKey dynamic group member event watch key
+var DynamicGroupMemberKey = Key{
+	namespace:  watchCacheNamespace + "dynamic_group_member",
+	collection: dynamicGroupMemberWatchCollName,
+	ttlSeconds: 6 * 60 * 60,
+	validator: func(doc []byte) error {
+		fields := gjson.GetManyBytes(doc, dynamicGroupMemberFields...)
+		for idx := range dynamicGroupMemberFields {
+			if !fields[idx].Exists() {
+				return fmt.Errorf("field %s not exist", dynamicGroupMemberFields[idx])
+			}
+		}
+		return nil
+	},
+	instID: func(doc []byte) int64 {
+		return gjson.GetBytes(doc, common.BKHostIDField).Int()
+	},
+}
+
+var dynamicGroupMemberFields = []string{common.BKAppIDField, common.BKFieldID, common.BKHostIDField}
+
+// DynamicGroupKey dynamic group event key, it's only used to refresh the tracked dynamic group members when the
+// dynamic group changes, the dynamic group events are not stored and can not be watched.
+var DynamicGroupKey = Key{
+	namespace:  watchCacheNamespace + "dynamic_group",
+	collection: common.BKTableNameDynamicGroup,
+	ttlSeconds: 6 * 60 * 60,
+	validator: func(doc []byte) error {
+		fields := gjson.GetManyBytes(doc, dynamicGroupFields...)
+		for idx := range dynamicGroupFields {
+			if !fields[idx].Exists() {
+				return fmt.Errorf("field %s not exist", dynamicGroupFields[idx])
+			}
+		}
+		return nil
+	},
+	instName: func(doc []byte) string {
+		return gjson.GetBytes(doc, common.BKFieldName).String()
+	},
+}
+
+var dynamicGroupFields = []string{common.BKAppIDField, common.BKFieldID}
+
+// GenDynamicGroupMemberDetail generate dynamic group member event detail json form by biz id, group id and host id
+func GenDynamicGroupMemberDetail(bizID int64, groupID string, hostID int64) string {
+	return fmt.Sprintf(`{"bk_biz_id":%d,"id":%q,"bk_host_id":%d}`, bizID, groupID, hostID)
+}
+
 var platFields = []string{common.BKCloudIDField, common.BKCloudNameField}
 
 // PlatKey cloud area event watch key
@@ -466,6 +518,94 @@ var KubePodKey = Key{
 	},
 }
 
+// KubeServiceKey kube service event watch key
+var KubeServiceKey = Key{
+	namespace:  watchCacheNamespace + string(kubetypes.KubeService),
+	collection: kubetypes.BKTableNameBaseService,
+	ttlSeconds: 6 * 60 * 60,
+	validator: func(doc []byte) error {
+		fields := gjson.GetManyBytes(doc, kubeFields...)
+		for idx := range kubeFields {
+			if !fields[idx].Exists() {
+				return fmt.Errorf("field %s not exist", kubeFields[idx])
+			}
+		}
+		return nil
+	},
+	instName: func(doc []byte) string {
+		return gjson.GetBytes(doc, common.BKFieldName).String()
+	},
+	instID: func(doc []byte) int64 {
+		return gjson.GetBytes(doc, common.BKFieldID).Int()
+	},
+}
+
+// KubeIngressKey kube ingress event watch key
+var KubeIngressKey = Key{
+	namespace:  watchCacheNamespace + string(kubetypes.KubeIngress),
+	collection: kubetypes.BKTableNameBaseIngress,
+	ttlSeconds: 6 * 60 * 60,
+	validator: func(doc []byte) error {
+		fields := gjson.GetManyBytes(doc, kubeFields...)
+		for idx := range kubeFields {
+			if !fields[idx].Exists() {
+				return fmt.Errorf("field %s not exist", kubeFields[idx])
+			}
+		}
+		return nil
+	},
+	instName: func(doc []byte) string {
+		return gjson.GetBytes(doc, common.BKFieldName).String()
+	},
+	instID: func(doc []byte) int64 {
+		return gjson.GetBytes(doc, common.BKFieldID).Int()
+	},
+}
+
+// KubeEndpointsKey kube endpoints event watch key
+var KubeEndpointsKey = Key{
+	namespace:  watchCacheNamespace + string(kubetypes.KubeEndpoints),
+	collection: kubetypes.BKTableNameBaseEndpoints,
+	ttlSeconds: 6 * 60 * 60,
+	validator: func(doc []byte) error {
+		fields := gjson.GetManyBytes(doc, kubeFields...)
+		for idx := range kubeFields {
+			if !fields[idx].Exists() {
+				return fmt.Errorf("field %s not exist", kubeFields[idx])
+			}
+		}
+		return nil
+	},
+	instName: func(doc []byte) string {
+		return gjson.GetBytes(doc, common.BKFieldName).String()
+	},
+	instID: func(doc []byte) int64 {
+		return gjson.GetBytes(doc, common.BKFieldID).Int()
+	},
+}
+
+// KubeConfigMapKey kube config map event watch key
+var KubeConfigMapKey = Key{
+	namespace:  watchCacheNamespace + string(kubetypes.KubeConfigMap),
+	collection: kubetypes.BKTableNameBaseConfigMap,
+	ttlSeconds: 6 * 60 * 60,
+	validator: func(doc []byte) error {
+		fields := gjson.GetManyBytes(doc, kubeFields...)
+		for idx := range kubeFields {
+			if !fields[idx].Exists() {
+				return fmt.Errorf("field %s not exist", kubeFields[idx])
+			}
+		}
+		return nil
+	},
+	instName: func(doc []byte) string {
+		return gjson.GetBytes(doc, common.BKFieldName).String()
+	},
+	instID: func(doc []byte) int64 {
+		return gjson.GetBytes(doc, common.BKFieldID).Int()
+	},
+}
+
 var projectFields = []string{common.BKFieldID, common.BKProjectNameField}
 
 // ProjectKey project event watch key
@@ -490,6 +630,169 @@ var ProjectKey = Key{
 	},
 }
 
+// templateFields template related resource id and name fields, used for validation
+var templateFields = []string{common.BKFieldID, common.BKFieldName}
+
+// ServiceTemplateKey service template event watch key
+var ServiceTemplateKey = Key{
+	namespace:  watchCacheNamespace + "service_template",
+	collection: common.BKTableNameServiceTemplate,
+	ttlSeconds: 6 * 60 * 60,
+	validator: func(doc []byte) error {
+		fields := gjson.GetManyBytes(doc, templateFields...)
+		for idx := range templateFields {
+			if !fields[idx].Exists() {
+				return fmt.Errorf("field %s not exist", templateFields[idx])
+			}
+		}
+		return nil
+	},
+	instName: func(doc []byte) string {
+		return gjson.GetBytes(doc, common.BKFieldName).String()
+	},
+	instID: func(doc []byte) int64 {
+		return gjson.GetBytes(doc, common.BKFieldID).Int()
+	},
+}
+
+var processTemplateFields = []string{common.BKFieldID, common.BKProcessNameField, common.BKServiceTemplateIDField}
+
+// ProcessTemplateKey process template event watch key
+var ProcessTemplateKey = Key{
+	namespace:  watchCacheNamespace + "process_template",
+	collection: common.BKTableNameProcessTemplate,
+	ttlSeconds: 6 * 60 * 60,
+	validator: func(doc []byte) error {
+		fields := gjson.GetManyBytes(doc, processTemplateFields...)
+		for idx := range processTemplateFields {
+			if !fields[idx].Exists() {
+				return fmt.Errorf("field %s not exist", processTemplateFields[idx])
+			}
+		}
+		return nil
+	},
+	instName: func(doc []byte) string {
+		return gjson.GetBytes(doc, common.BKProcessNameField).String()
+	},
+	instID: func(doc []byte) int64 {
+		return gjson.GetBytes(doc, common.BKFieldID).Int()
+	},
+}
+
+// SetTemplateKey set template event watch key
+var SetTemplateKey = Key{
+	namespace:  watchCacheNamespace + "set_template",
+	collection: common.BKTableNameSetTemplate,
+	ttlSeconds: 6 * 60 * 60,
+	validator: func(doc []byte) error {
+		fields := gjson.GetManyBytes(doc, templateFields...)
+		for idx := range templateFields {
+			if !fields[idx].Exists() {
+				return fmt.Errorf("field %s not exist", templateFields[idx])
+			}
+		}
+		return nil
+	},
+	instName: func(doc []byte) string {
+		return gjson.GetBytes(doc, common.BKFieldName).String()
+	},
+	instID: func(doc []byte) int64 {
+		return gjson.GetBytes(doc, common.BKFieldID).Int()
+	},
+}
+
+// FieldTemplateKey field template event watch key
+var FieldTemplateKey = Key{
+	namespace:  watchCacheNamespace + "field_template",
+	collection: common.BKTableNameFieldTemplate,
+	ttlSeconds: 6 * 60 * 60,
+	validator: func(doc []byte) error {
+		fields := gjson.GetManyBytes(doc, templateFields...)
+		for idx := range templateFields {
+			if !fields[idx].Exists() {
+				return fmt.Errorf("field %s not exist", templateFields[idx])
+			}
+		}
+		return nil
+	},
+	instName: func(doc []byte) string {
+		return gjson.GetBytes(doc, common.BKFieldName).String()
+	},
+	instID: func(doc []byte) int64 {
+		return gjson.GetBytes(doc, common.BKFieldID).Int()
+	},
+}
+
+var modelFields = []string{common.BKFieldID, common.BKObjIDField, common.BKObjNameField}
+
+// ModelKey model definition event watch key
+var ModelKey = Key{
+	namespace:  watchCacheNamespace + "model",
+	collection: common.BKTableNameObjDes,
+	ttlSeconds: 6 * 60 * 60,
+	validator: func(doc []byte) error {
+		fields := gjson.GetManyBytes(doc, modelFields...)
+		for idx := range modelFields {
+			if !fields[idx].Exists() {
+				return fmt.Errorf("field %s not exist", modelFields[idx])
+			}
+		}
+		return nil
+	},
+	instName: func(doc []byte) string {
+		return gjson.GetBytes(doc, common.BKObjNameField).String()
+	},
+	instID: func(doc []byte) int64 {
+		return gjson.GetBytes(doc, common.BKFieldID).Int()
+	},
+}
+
+var modelAttributeFields = []string{common.BKFieldID, common.BKObjIDField, common.BKPropertyIDField,
+	common.BKPropertyNameField}
+
+// ModelAttributeKey model attribute event watch key
+var ModelAttributeKey = Key{
+	namespace:  watchCacheNamespace + "model_attribute",
+	collection: common.BKTableNameObjAttDes,
+	ttlSeconds: 6 * 60 * 60,
+	validator: func(doc []byte) error {
+		fields := gjson.GetManyBytes(doc, modelAttributeFields...)
+		for idx := range modelAttributeFields {
+			if !fields[idx].Exists() {
+				return fmt.Errorf("field %s not exist", modelAttributeFields[idx])
+			}
+		}
+		return nil
+	},
+	instName: func(doc []byte) string {
+		return gjson.GetBytes(doc, common.BKPropertyNameField).String()
+	},
+	instID: func(doc []byte) int64 {
+		return gjson.GetBytes(doc, common.BKFieldID).Int()
+	},
+}
+
+var hostApplyRuleFields = []string{common.BKFieldID, common.BKModuleIDField, common.BKAttributeIDField}
+
+// HostApplyRuleKey host apply rule event watch key
+var HostApplyRuleKey = Key{
+	namespace:  watchCacheNamespace + "host_apply_rule",
+	collection: common.BKTableNameHostApplyRule,
+	ttlSeconds: 6 * 60 * 60,
+	validator: func(doc []byte) error {
+		fields := gjson.GetManyBytes(doc, hostApplyRuleFields...)
+		for idx := range hostApplyRuleFields {
+			if !fields[idx].Exists() {
+				return fmt.Errorf("field %s not exist", hostApplyRuleFields[idx])
+			}
+		}
+		return nil
+	},
+	instID: func(doc []byte) int64 {
+		return gjson.GetBytes(doc, common.BKFieldID).Int()
+	},
+}
+
 // Key TODO
 type Key struct {
 	namespace string
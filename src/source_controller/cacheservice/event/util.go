@@ -42,6 +42,18 @@ var resourceKeyMap = map[watch.CursorType]Key{
 	watch.KubeWorkload:            KubeWorkloadKey,
 	watch.KubePod:                 KubePodKey,
 	watch.Project:                 ProjectKey,
+	watch.DynamicGroupMember:      DynamicGroupMemberKey,
+	watch.ServiceTemplate:         ServiceTemplateKey,
+	watch.ProcessTemplate:         ProcessTemplateKey,
+	watch.SetTemplate:             SetTemplateKey,
+	watch.FieldTemplate:           FieldTemplateKey,
+	watch.Model:                   ModelKey,
+	watch.ModelAttribute:          ModelAttributeKey,
+	watch.HostApplyRule:           HostApplyRuleKey,
+	watch.KubeService:             KubeServiceKey,
+	watch.KubeIngress:             KubeIngressKey,
+	watch.KubeEndpoints:           KubeEndpointsKey,
+	watch.KubeConfigMap:           KubeConfigMapKey,
 }
 
 // GetResourceKeyWithCursorType get resource key
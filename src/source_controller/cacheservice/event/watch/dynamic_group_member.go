@@ -0,0 +1,107 @@
+/*
+ * Tencent is pleased to support the open source community by making 蓝鲸 available.
+ * Copyright (C) 2017-2018 THL A29 Limited, a Tencent company. All rights reserved.
+ * Licensed under the MIT License (the "License"); you may not use this file except
+ * in compliance with the License. You may obtain a copy of the License at
+ * http://opensource.org/licenses/MIT
+ * Unless required by applicable law or agreed to in writing, software distributed under
+ * the License is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND,
+ * either express or implied. See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package watch
+
+import (
+	"configcenter/src/common"
+	"configcenter/src/common/blog"
+	"configcenter/src/common/http/rest"
+	"configcenter/src/common/metadata"
+	"configcenter/src/common/watch"
+	"configcenter/src/source_controller/cacheservice/event"
+	"configcenter/src/storage/driver/mongodb"
+	"configcenter/src/storage/stream/types"
+)
+
+// getDynamicGroupMemberEventDetailWithNodes get dynamic group member event detail by chain nodes, the detail is
+// generated by the chain node's sub resource(dynamic group id) and instance id(host id) if it's not in redis.
+func (c *Client) getDynamicGroupMemberEventDetailWithNodes(kit *rest.Kit, hitNodes []*watch.ChainNode) (
+	[]*watch.WatchEventDetail, error) {
+
+	if len(hitNodes) == 0 {
+		return make([]*watch.WatchEventDetail, 0), nil
+	}
+
+	cursors := make([]string, len(hitNodes))
+	for index, node := range hitNodes {
+		cursors[index] = node.Cursor
+	}
+
+	// get dynamic group member event detail from redis, ignore the fields in watch option, return the whole detail
+	details, errCursors, errCursorIndexMap, err := c.searchEventDetailsFromRedis(kit, cursors,
+		event.DynamicGroupMemberKey)
+	if err != nil {
+		return nil, err
+	}
+
+	// get the biz ids of the dynamic groups in the chain nodes whose details failed to read from redis
+	errIndexGroupMap := make(map[int]string)
+	groupIDs := make([]string, 0)
+	for _, errCursor := range errCursors {
+		index := errCursorIndexMap[errCursor]
+		if len(hitNodes[index].SubResource) == 0 {
+			continue
+		}
+		errIndexGroupMap[index] = hitNodes[index].SubResource[0]
+		groupIDs = append(groupIDs, hitNodes[index].SubResource[0])
+	}
+
+	groupBizMap, err := c.getDynamicGroupBizMap(kit, groupIDs)
+	if err != nil {
+		return nil, err
+	}
+
+	// generate event details, if dynamic group not exists(dynamic group is deleted), return detail with biz id 0
+	resp := make([]*watch.WatchEventDetail, len(details))
+	for idx, detail := range details {
+		node := hitNodes[idx]
+		if groupID, exists := errIndexGroupMap[idx]; exists {
+			detail = event.GenDynamicGroupMemberDetail(groupBizMap[groupID], groupID, node.InstanceID)
+		} else {
+			detail = *types.GetEventDetail(&detail)
+		}
+
+		resp[idx] = &watch.WatchEventDetail{
+			Cursor:    node.Cursor,
+			Resource:  watch.DynamicGroupMember,
+			EventType: node.EventType,
+			Detail:    watch.JsonString(detail),
+		}
+	}
+	return resp, nil
+}
+
+// getDynamicGroupBizMap get dynamic group id to its biz id map by dynamic group ids from mongo
+func (c *Client) getDynamicGroupBizMap(kit *rest.Kit, groupIDs []string) (map[string]int64, error) {
+	if len(groupIDs) == 0 {
+		return make(map[string]int64), nil
+	}
+
+	cond := map[string]interface{}{
+		common.BKFieldID: map[string]interface{}{common.BKDBIN: groupIDs},
+	}
+
+	groups := make([]metadata.DynamicGroup, 0)
+	err := mongodb.Client().Table(common.BKTableNameDynamicGroup).Find(cond).
+		Fields(common.BKFieldID, common.BKAppIDField).All(kit.Ctx, &groups)
+	if err != nil {
+		blog.Errorf("get dynamic groups by cond(%+v) failed, err: %v, rid: %s", cond, err, kit.Rid)
+		return nil, err
+	}
+
+	groupBizMap := make(map[string]int64, len(groups))
+	for _, group := range groups {
+		groupBizMap[group.ID] = group.AppID
+	}
+	return groupBizMap, nil
+}
@@ -204,6 +204,11 @@ func (c *Client) getEventDetailsWithNodes(kit *rest.Kit, opts *watch.WatchEventO
 		return c.getBizSetRelationEventDetailWithNodes(kit, hitNodes)
 	}
 
+	if opts.Resource == watch.DynamicGroupMember {
+		// get the whole detail, the detail do not have other fields.
+		return c.getDynamicGroupMemberEventDetailWithNodes(kit, hitNodes)
+	}
+
 	cursors := make([]string, len(hitNodes))
 	for index, node := range hitNodes {
 		cursors[index] = node.Cursor
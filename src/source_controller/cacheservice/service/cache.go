@@ -18,6 +18,7 @@ import (
 
 	"configcenter/src/common"
 	"configcenter/src/common/blog"
+	"configcenter/src/common/errors"
 	"configcenter/src/common/http/rest"
 	"configcenter/src/common/metadata"
 	"configcenter/src/common/util"
@@ -160,6 +161,93 @@ func (s *cacheService) ListSetsInCache(ctx *rest.Contexts) {
 	ctx.RespStringArray(details)
 }
 
+// ListInstancesInCache list the object's instances with id from cache, the instances of the objects that are not
+// configured to be cached are got from mongodb directly.
+func (s *cacheService) ListInstancesInCache(ctx *rest.Contexts) {
+	objID := ctx.Request.PathParameter(common.BKObjIDField)
+	if objID == "" {
+		ctx.RespErrorCodeOnly(common.CCErrCommParamsNeedSet, "bk_obj_id is not set")
+		return
+	}
+
+	opt := new(metadata.ListWithIDOption)
+	if err := ctx.DecodeInto(&opt); nil != err {
+		ctx.RespAutoError(err)
+		return
+	}
+
+	if rawErr := validateListWithIDOption(opt); rawErr.ErrCode != 0 {
+		ctx.RespAutoError(rawErr.ToCCError(ctx.Kit.CCError))
+		return
+	}
+
+	details, err := s.cacheSet.Instance.ListInstances(ctx.Kit.Ctx, objID, ctx.Kit.SupplierAccount, opt)
+	if err != nil {
+		ctx.RespErrorCodeOnly(common.CCErrCommDBSelectFailed, "list %s instances with id in cache failed, err: %v",
+			objID, err)
+		return
+	}
+	ctx.RespStringArray(details)
+}
+
+// ListServiceInstancesInCache list service instances with id from cache, if not exist in cache, then get from
+// mongodb directly.
+func (s *cacheService) ListServiceInstancesInCache(ctx *rest.Contexts) {
+	opt := new(metadata.ListWithIDOption)
+	if err := ctx.DecodeInto(&opt); nil != err {
+		ctx.RespAutoError(err)
+		return
+	}
+
+	if rawErr := validateListWithIDOption(opt); rawErr.ErrCode != 0 {
+		ctx.RespAutoError(rawErr.ToCCError(ctx.Kit.CCError))
+		return
+	}
+
+	details, err := s.cacheSet.Instance.ListServiceInstances(ctx.Kit.Ctx, ctx.Kit.SupplierAccount, opt)
+	if err != nil {
+		ctx.RespErrorCodeOnly(common.CCErrCommDBSelectFailed, "list service instances with id in cache failed, "+
+			"err: %v", err)
+		return
+	}
+	ctx.RespStringArray(details)
+}
+
+// ListProcessesInCache list processes with id from cache, if not exist in cache, then get from mongodb directly.
+func (s *cacheService) ListProcessesInCache(ctx *rest.Contexts) {
+	opt := new(metadata.ListWithIDOption)
+	if err := ctx.DecodeInto(&opt); nil != err {
+		ctx.RespAutoError(err)
+		return
+	}
+
+	if rawErr := validateListWithIDOption(opt); rawErr.ErrCode != 0 {
+		ctx.RespAutoError(rawErr.ToCCError(ctx.Kit.CCError))
+		return
+	}
+
+	details, err := s.cacheSet.Instance.ListProcesses(ctx.Kit.Ctx, ctx.Kit.SupplierAccount, opt)
+	if err != nil {
+		ctx.RespErrorCodeOnly(common.CCErrCommDBSelectFailed, "list processes with id in cache failed, err: %v", err)
+		return
+	}
+	ctx.RespStringArray(details)
+}
+
+// validateListWithIDOption validate the id list length is in range [1,500]
+func validateListWithIDOption(opt *metadata.ListWithIDOption) errors.RawErrorInfo {
+	if len(opt.IDs) == 0 {
+		return errors.RawErrorInfo{ErrCode: common.CCErrCommParamsNeedSet, Args: []interface{}{"ids"}}
+	}
+
+	if len(opt.IDs) > common.BKMaxInstanceLimit {
+		return errors.RawErrorInfo{ErrCode: common.CCErrCommXXExceedLimit,
+			Args: []interface{}{"ids", common.BKMaxInstanceLimit}}
+	}
+
+	return errors.RawErrorInfo{}
+}
+
 // SearchBusinessInCache TODO
 func (s *cacheService) SearchBusinessInCache(ctx *rest.Contexts) {
 	bizID, err := strconv.ParseInt(ctx.Request.PathParameter(common.BKAppIDField), 10, 64)
@@ -28,7 +28,9 @@ import (
 	"configcenter/src/source_controller/cacheservice/app/options"
 	"configcenter/src/source_controller/cacheservice/cache"
 	cacheop "configcenter/src/source_controller/cacheservice/cache"
+	"configcenter/src/source_controller/cacheservice/event/auditexport"
 	"configcenter/src/source_controller/cacheservice/event/bsrelation"
+	"configcenter/src/source_controller/cacheservice/event/dgmember"
 	"configcenter/src/source_controller/cacheservice/event/flow"
 	"configcenter/src/source_controller/cacheservice/event/identifier"
 	"configcenter/src/source_controller/coreservice/core"
@@ -131,6 +133,16 @@ func (s *cacheService) SetConfig(cfg options.Config, engine *backbone.Engine, er
 		return err
 	}
 
+	if err := dgmember.NewDynamicGroupMember(watcher, watchDB, ccDB); err != nil {
+		blog.Errorf("new dynamic group member event failed, err: %v", err)
+		return err
+	}
+
+	if err := auditexport.NewAuditExporter(watcher, ccDB); err != nil {
+		blog.Errorf("new audit log exporter failed, err: %v", err)
+		return err
+	}
+
 	return nil
 }
 
@@ -86,6 +86,21 @@ func (s *cacheService) initCache(web *restful.WebService) {
 		Path:    "/find/cache/{bk_obj_id}/{bk_inst_id}",
 		Handler: s.SearchCustomLayerInCache,
 	})
+	utility.AddHandler(rest.Action{
+		Verb:    http.MethodPost,
+		Path:    "/findmany/cache/instance/object/{bk_obj_id}",
+		Handler: s.ListInstancesInCache,
+	})
+	utility.AddHandler(rest.Action{
+		Verb:    http.MethodPost,
+		Path:    "/findmany/cache/service_instance",
+		Handler: s.ListServiceInstancesInCache,
+	})
+	utility.AddHandler(rest.Action{
+		Verb:    http.MethodPost,
+		Path:    "/findmany/cache/process",
+		Handler: s.ListProcessesInCache,
+	})
 	utility.AddHandler(rest.Action{
 		Verb:    http.MethodPost,
 		Path:    "find/cache/topo/node_path/biz/{bk_biz_id}",
@@ -76,6 +76,10 @@ func (m *auditManager) CreateAuditLog(kit *rest.Kit, logs ...metadata.AuditLog)
 	if len(logRows) == 0 {
 		return nil
 	}
+
+	if isHashChainEnabled() {
+		return m.insertChainedAuditLog(kit, logRows)
+	}
 	return mongodb.Client().Table(common.BKTableNameAuditLog).Insert(kit.Ctx, logRows)
 }
 
@@ -0,0 +1,422 @@
+/*
+ * Tencent is pleased to support the open source community by making 蓝鲸 available.,
+ * Copyright (C) 2017-2018 THL A29 Limited, a Tencent company. All rights reserved.
+ * Licensed under the MIT License (the ",License",); you may not use this file except
+ * in compliance with the License. You may obtain a copy of the License at
+ * http://opensource.org/licenses/MIT
+ * Unless required by applicable law or agreed to in writing, software distributed under
+ * the License is distributed on an ",AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND,
+ * either express or implied. See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package auditlog
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+
+	"configcenter/src/common"
+	cc "configcenter/src/common/backbone/configcenter"
+	"configcenter/src/common/blog"
+	"configcenter/src/common/http/rest"
+	"configcenter/src/common/mapstr"
+	"configcenter/src/common/metadata"
+	"configcenter/src/storage/driver/mongodb"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/x/bsonx/bsoncore"
+)
+
+const (
+	// hashChainConfigKey is the config key to enable the audit log hash chain
+	hashChainConfigKey = "coreService.auditLog.enableHashChain"
+	// maxChainReserveRetry is the max times to reserve the chain slots when others are reserving concurrently
+	maxChainReserveRetry = 10
+)
+
+// isHashChainEnabled returns if the audit logs need to be linked to the hash chain, it is disabled if not configured
+func isHashChainEnabled() bool {
+	enabled, err := cc.Bool(hashChainConfigKey)
+	if err != nil {
+		return false
+	}
+	return enabled
+}
+
+// chainFields is the hash chain related fields of an audit log
+type chainFields struct {
+	ID       int64  `bson:"id"`
+	SeqNo    int64  `bson:"seq_no"`
+	PrevHash string `bson:"prev_hash"`
+	Hash     string `bson:"hash"`
+}
+
+// calcAuditLogHash calculates the hash of an audit log from the raw data stored in db, it covers all the fields
+// except the db generated _id and the hash itself, the covered prev_hash links it to the previous one. the raw data
+// is used so that the hash is not affected by the order of map keys when the audit log is marshaled again.
+func calcAuditLogHash(doc bson.Raw) (string, error) {
+	elements, err := doc.Elements()
+	if err != nil {
+		return "", err
+	}
+
+	hash := sha256.New()
+	for _, element := range elements {
+		key := element.Key()
+		if key == "_id" || key == common.BKAuditHashField {
+			continue
+		}
+		hash.Write(element)
+	}
+
+	return hex.EncodeToString(hash.Sum(nil)), nil
+}
+
+// buildChainedAuditLogs links the audit logs to the chain after the last one, returns the raw data to be inserted
+func buildChainedAuditLogs(logs []metadata.AuditLog, last *chainFields) ([]bson.Raw, *chainFields, error) {
+	docs := make([]bson.Raw, len(logs))
+	tail := &chainFields{SeqNo: last.SeqNo, Hash: last.Hash}
+	for index, log := range logs {
+		log.SeqNo = tail.SeqNo + 1
+		log.PrevHash = tail.Hash
+		log.Hash = ""
+
+		doc, err := bson.Marshal(log)
+		if err != nil {
+			return nil, nil, err
+		}
+
+		hash, err := calcAuditLogHash(doc)
+		if err != nil {
+			return nil, nil, err
+		}
+
+		elements, err := bson.Raw(doc).Elements()
+		if err != nil {
+			return nil, nil, err
+		}
+
+		elemBytes := make([][]byte, 0, len(elements)+1)
+		for _, element := range elements {
+			elemBytes = append(elemBytes, element)
+		}
+		elemBytes = append(elemBytes, bsoncore.AppendStringElement(nil, common.BKAuditHashField, hash))
+		docs[index] = bsoncore.BuildDocumentFromElements(nil, elemBytes...)
+
+		tail = &chainFields{ID: log.ID, SeqNo: log.SeqNo, PrevHash: log.PrevHash, Hash: hash}
+	}
+
+	return docs, tail, nil
+}
+
+// getChainHead get the supplier account's hash chain head, returns an empty one if the chain is not started
+func getChainHead(kit *rest.Kit) (*metadata.AuditChainHead, error) {
+	head := new(metadata.AuditChainHead)
+	cond := mapstr.MapStr{common.BKOwnerIDField: kit.SupplierAccount}
+	err := mongodb.Client().Table(common.BKTableNameAuditLogChain).Find(cond).One(kit.Ctx, head)
+	if err != nil {
+		if mongodb.Client().IsNotFoundError(err) {
+			return &metadata.AuditChainHead{SupplierAccount: kit.SupplierAccount}, nil
+		}
+		blog.Errorf("get audit log chain head failed, err: %v, rid: %s", err, kit.Rid)
+		return nil, err
+	}
+
+	return head, nil
+}
+
+// getChainedAuditLog get the hash chain fields of the audit log with the sequence number, returns nil if not found.
+// if the sequence number is 0, the latest one in the chain is returned.
+func getChainedAuditLog(kit *rest.Kit, seqNo int64) (*chainFields, error) {
+	cond := mapstr.MapStr{
+		common.BKOwnerIDField:    kit.SupplierAccount,
+		common.BKAuditSeqNoField: mapstr.MapStr{common.BKDBGT: 0},
+	}
+	if seqNo > 0 {
+		cond[common.BKAuditSeqNoField] = seqNo
+	}
+
+	logs := make([]chainFields, 0)
+	err := mongodb.Client().Table(common.BKTableNameAuditLog).Find(cond).Sort(common.BKAuditSeqNoField+":-1").
+		Fields(common.BKFieldID, common.BKAuditSeqNoField, common.BKAuditPrevHashField, common.BKAuditHashField).
+		Limit(1).All(kit.Ctx, &logs)
+	if err != nil {
+		blog.Errorf("get chained audit log by seq no %d failed, err: %v, rid: %s", seqNo, err, kit.Rid)
+		return nil, err
+	}
+
+	if len(logs) == 0 {
+		return nil, nil
+	}
+	return &logs[0], nil
+}
+
+// getChainTail get the last reserved slot of the chain that the new audit logs are linked after, which is recorded
+// in the chain head. the pruned one is used if the chain head is behind it. if the chain head is not created yet,
+// the latest audit log in the chain is used, and the returned bool is false.
+func getChainTail(kit *rest.Kit) (*chainFields, bool, error) {
+	head, err := getChainHead(kit)
+	if err != nil {
+		return nil, false, err
+	}
+
+	if head.SeqNo == 0 && head.PrunedSeqNo == 0 {
+		last, err := getChainedAuditLog(kit, 0)
+		if err != nil {
+			return nil, false, err
+		}
+
+		if last == nil {
+			return new(chainFields), false, nil
+		}
+		return last, false, nil
+	}
+
+	tail := &chainFields{SeqNo: head.SeqNo, Hash: head.Hash}
+	if head.PrunedSeqNo > tail.SeqNo {
+		tail = &chainFields{SeqNo: head.PrunedSeqNo, Hash: head.PrunedHash}
+	}
+	return tail, true, nil
+}
+
+// insertChainedAuditLog links the audit logs to the supplier account's hash chain and saves them. the chain slots
+// are reserved by moving the chain head forward atomically out of the caller's transaction, so that the concurrent
+// writers are linked one after another instead of conflicting with each other in their transactions. if the
+// caller's transaction is aborted, the reserved slots are left as a gap in the chain, which is reported by the
+// verification, and the audit logs after it are still linked to the reserved hash.
+func (m *auditManager) insertChainedAuditLog(kit *rest.Kit, logs []metadata.AuditLog) error {
+	docs, err := reserveChainSlots(kit, logs)
+	if err != nil {
+		return err
+	}
+
+	err = mongodb.Client().Table(common.BKTableNameAuditLog).Insert(kit.Ctx, docs)
+	if err != nil {
+		blog.Errorf("insert chained audit logs failed, err: %v, rid: %s", err, kit.Rid)
+		return err
+	}
+
+	return nil
+}
+
+// reserveChainSlots links the audit logs after the chain tail and moves the chain head to the last one, the head is
+// only moved if it's not moved by others since it is read, otherwise the audit logs are linked again after the new
+// tail. returns the raw data of the linked audit logs to be inserted.
+func reserveChainSlots(kit *rest.Kit, logs []metadata.AuditLog) ([]bson.Raw, error) {
+	// run without the caller's transaction, the reserved slots are visible to the others immediately.
+	chainKit := *kit
+	chainKit.Ctx = context.WithValue(context.Background(), common.ContextRequestIDField, kit.Rid)
+
+	for retry := 0; retry < maxChainReserveRetry; retry++ {
+		last, headExists, err := getChainTail(&chainKit)
+		if err != nil {
+			return nil, err
+		}
+
+		docs, tail, err := buildChainedAuditLogs(logs, last)
+		if err != nil {
+			blog.Errorf("build chained audit logs failed, err: %v, rid: %s", err, kit.Rid)
+			return nil, err
+		}
+
+		reserved, err := moveChainHead(&chainKit, last, tail, headExists)
+		if err != nil {
+			return nil, err
+		}
+
+		if reserved {
+			return docs, nil
+		}
+
+		blog.V(4).Infof("audit log chain head is moved concurrently, retry: %d, rid: %s", retry, kit.Rid)
+	}
+
+	blog.Errorf("reserve audit log chain slots failed after retry %d times, rid: %s", maxChainReserveRetry, kit.Rid)
+	return nil, kit.CCError.CCError(common.CCERrrCoreServiceConcurrent)
+}
+
+// moveChainHead move the chain head from the last slot to the tail of the new linked audit logs, returns false if
+// the chain head is not at the last slot any more, which means it's moved by others.
+func moveChainHead(kit *rest.Kit, last, tail *chainFields, headExists bool) (bool, error) {
+	if !headExists {
+		head := &metadata.AuditChainHead{
+			SupplierAccount: kit.SupplierAccount,
+			SeqNo:           tail.SeqNo,
+			Hash:            tail.Hash,
+			LastTime:        metadata.Now(),
+		}
+		err := mongodb.Client().Table(common.BKTableNameAuditLogChain).Insert(kit.Ctx, head)
+		if err != nil {
+			// the unique index of the supplier account rejects the head if others have created it
+			if mongodb.Client().IsDuplicatedError(err) {
+				return false, nil
+			}
+			blog.Errorf("create audit log chain head failed, err: %v, rid: %s", err, kit.Rid)
+			return false, err
+		}
+		return true, nil
+	}
+
+	cond := mapstr.MapStr{
+		common.BKOwnerIDField: kit.SupplierAccount,
+		common.BKDBOR: []mapstr.MapStr{
+			{common.BKAuditSeqNoField: last.SeqNo, common.BKAuditHashField: last.Hash},
+			// the head is behind the pruned audit logs, the new ones are linked after the pruned one
+			{common.BKAuditSeqNoField: mapstr.MapStr{common.BKDBLT: last.SeqNo}, "pruned_seq_no": last.SeqNo,
+				"pruned_hash": last.Hash},
+		},
+	}
+	data := mapstr.MapStr{
+		common.BKAuditSeqNoField: tail.SeqNo,
+		common.BKAuditHashField:  tail.Hash,
+		common.LastTimeField:     metadata.Now(),
+	}
+
+	cnt, err := mongodb.Client().Table(common.BKTableNameAuditLogChain).UpdateMany(kit.Ctx, cond, data)
+	if err != nil {
+		blog.Errorf("move audit log chain head to %d failed, err: %v, rid: %s", tail.SeqNo, err, kit.Rid)
+		return false, err
+	}
+
+	return cnt > 0, nil
+}
+
+// VerifyAuditChain walks through a range of the supplier account's audit log hash chain, checks that the audit logs
+// are continuous, not edited and linked to the previous ones, and the chain head matches the latest one.
+// Note: the slots reserved by the rolled back or not yet committed transactions are reported as gaps too.
+func (m *auditManager) VerifyAuditChain(kit *rest.Kit, opt *metadata.VerifyAuditChainOption) (
+	*metadata.VerifyAuditChainResult, error) {
+
+	head, err := getChainHead(kit)
+	if err != nil {
+		return nil, err
+	}
+
+	result := &metadata.VerifyAuditChainResult{
+		HeadSeqNo:   head.SeqNo,
+		PrunedSeqNo: head.PrunedSeqNo,
+		Issues:      make([]metadata.AuditChainIssue, 0),
+	}
+
+	// the audit logs after the chain head are also verified, they are linked by the former versions that move the
+	// chain head after the audit logs are saved
+	last, err := getChainedAuditLog(kit, 0)
+	if err != nil {
+		return nil, err
+	}
+
+	endSeqNo := head.SeqNo
+	if last != nil && last.SeqNo > endSeqNo {
+		endSeqNo = last.SeqNo
+	}
+
+	startSeqNo := opt.StartSeqNo
+	if startSeqNo <= head.PrunedSeqNo {
+		startSeqNo = head.PrunedSeqNo + 1
+	}
+
+	if startSeqNo+opt.Limit-1 < endSeqNo {
+		endSeqNo = startSeqNo + opt.Limit - 1
+	} else {
+		result.Finished = true
+	}
+	result.NextSeqNo = endSeqNo + 1
+
+	if startSeqNo > endSeqNo {
+		result.NextSeqNo = startSeqNo
+		return result, nil
+	}
+
+	prevHash, prevKnown, err := getChainPrevHash(kit, startSeqNo, head)
+	if err != nil {
+		return nil, err
+	}
+
+	cond := mapstr.MapStr{
+		common.BKOwnerIDField: kit.SupplierAccount,
+		common.BKAuditSeqNoField: mapstr.MapStr{
+			common.BKDBGTE: startSeqNo,
+			common.BKDBLTE: endSeqNo,
+		},
+	}
+	docs := make([]bson.Raw, 0)
+	err = mongodb.Client().Table(common.BKTableNameAuditLog).Find(cond).Sort(common.BKAuditSeqNoField).
+		All(kit.Ctx, &docs)
+	if err != nil {
+		blog.Errorf("get chained audit logs failed, cond: %+v, err: %v, rid: %s", cond, err, kit.Rid)
+		return nil, err
+	}
+
+	expectSeqNo := startSeqNo
+	for _, doc := range docs {
+		fields := new(chainFields)
+		if err := bson.Unmarshal(doc, fields); err != nil {
+			blog.Errorf("unmarshal chained audit log failed, err: %v, rid: %s", err, kit.Rid)
+			return nil, err
+		}
+
+		if fields.SeqNo > expectSeqNo {
+			result.Issues = append(result.Issues, metadata.AuditChainIssue{Type: metadata.AuditChainGap,
+				StartSeqNo: expectSeqNo, EndSeqNo: fields.SeqNo - 1})
+			prevKnown = false
+		}
+
+		if prevKnown && fields.PrevHash != prevHash {
+			result.Issues = append(result.Issues, metadata.AuditChainIssue{Type: metadata.AuditChainLinkMismatch,
+				StartSeqNo: fields.SeqNo, EndSeqNo: fields.SeqNo, AuditID: fields.ID, Expected: prevHash,
+				Actual: fields.PrevHash})
+		}
+
+		hash, err := calcAuditLogHash(doc)
+		if err != nil {
+			blog.Errorf("calculate audit log %d hash failed, err: %v, rid: %s", fields.ID, err, kit.Rid)
+			return nil, err
+		}
+
+		if hash != fields.Hash {
+			result.Issues = append(result.Issues, metadata.AuditChainIssue{Type: metadata.AuditChainHashMismatch,
+				StartSeqNo: fields.SeqNo, EndSeqNo: fields.SeqNo, AuditID: fields.ID, Expected: hash,
+				Actual: fields.Hash})
+		}
+
+		if fields.SeqNo == head.SeqNo && fields.Hash != head.Hash {
+			result.Issues = append(result.Issues, metadata.AuditChainIssue{Type: metadata.AuditChainHeadMismatch,
+				StartSeqNo: fields.SeqNo, EndSeqNo: fields.SeqNo, AuditID: fields.ID, Expected: head.Hash,
+				Actual: fields.Hash})
+		}
+
+		prevHash, prevKnown, expectSeqNo = fields.Hash, true, fields.SeqNo+1
+		result.Checked++
+	}
+
+	if expectSeqNo <= endSeqNo {
+		result.Issues = append(result.Issues, metadata.AuditChainIssue{Type: metadata.AuditChainGap,
+			StartSeqNo: expectSeqNo, EndSeqNo: endSeqNo})
+	}
+
+	return result, nil
+}
+
+// getChainPrevHash get the hash that the audit log with the sequence number should be linked to, returns false if
+// the previous audit log is missing, which is reported as a gap when verifying the range that contains it.
+func getChainPrevHash(kit *rest.Kit, seqNo int64, head *metadata.AuditChainHead) (string, bool, error) {
+	if seqNo == 1 {
+		return "", true, nil
+	}
+
+	if seqNo == head.PrunedSeqNo+1 {
+		return head.PrunedHash, true, nil
+	}
+
+	prev, err := getChainedAuditLog(kit, seqNo-1)
+	if err != nil {
+		return "", false, err
+	}
+
+	if prev == nil {
+		return "", false, nil
+	}
+	return prev.Hash, true, nil
+}
@@ -0,0 +1,82 @@
+/*
+ * Tencent is pleased to support the open source community by making 蓝鲸 available.,
+ * Copyright (C) 2017-2018 THL A29 Limited, a Tencent company. All rights reserved.
+ * Licensed under the MIT License (the ",License",); you may not use this file except
+ * in compliance with the License. You may obtain a copy of the License at
+ * http://opensource.org/licenses/MIT
+ * Unless required by applicable law or agreed to in writing, software distributed under
+ * the License is distributed on an ",AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND,
+ * either express or implied. See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package auditlog
+
+import (
+	"testing"
+
+	"configcenter/src/common/metadata"
+
+	"github.com/stretchr/testify/require"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/x/bsonx/bsoncore"
+)
+
+// storeChainedAuditLog simulates the db that adds the _id field in front of the inserted audit log
+func storeChainedAuditLog(t *testing.T, doc bson.Raw) bson.Raw {
+	elements, err := doc.Elements()
+	require.NoError(t, err)
+
+	elemBytes := [][]byte{bsoncore.AppendObjectIDElement(nil, "_id", primitive.NewObjectID())}
+	for _, element := range elements {
+		elemBytes = append(elemBytes, element)
+	}
+	return bsoncore.BuildDocumentFromElements(nil, elemBytes...)
+}
+
+func TestBuildChainedAuditLogs(t *testing.T) {
+	logs := make([]metadata.AuditLog, 0)
+	for id := int64(1); id <= 3; id++ {
+		logs = append(logs, metadata.AuditLog{
+			ID:           id,
+			Action:       metadata.AuditCreate,
+			ResourceType: metadata.HostRes,
+			OperationDetail: &metadata.InstanceOpDetail{BasicOpDetail: metadata.BasicOpDetail{
+				Details: &metadata.BasicContent{CurData: map[string]interface{}{"bk_host_id": id, "a": 1, "b": 2}},
+			}},
+		})
+	}
+
+	docs, tail, err := buildChainedAuditLogs(logs, &chainFields{SeqNo: 10, Hash: "prev"})
+	require.NoError(t, err)
+	require.Len(t, docs, 3)
+	require.Equal(t, int64(13), tail.SeqNo)
+
+	prevHash := "prev"
+	for index, doc := range docs {
+		stored := storeChainedAuditLog(t, doc)
+
+		fields := new(chainFields)
+		require.NoError(t, bson.Unmarshal(stored, fields))
+		require.Equal(t, int64(11+index), fields.SeqNo)
+		require.Equal(t, prevHash, fields.PrevHash)
+
+		hash, err := calcAuditLogHash(stored)
+		require.NoError(t, err)
+		require.Equal(t, fields.Hash, hash)
+		prevHash = fields.Hash
+	}
+	require.Equal(t, prevHash, tail.Hash)
+
+	// edit the stored audit log, its hash does not match any more
+	log := new(metadata.AuditLog)
+	require.NoError(t, bson.Unmarshal(docs[1], log))
+	log.User = "someone"
+	edited, err := bson.Marshal(log)
+	require.NoError(t, err)
+
+	hash, err := calcAuditLogHash(edited)
+	require.NoError(t, err)
+	require.NotEqual(t, log.Hash, hash)
+}
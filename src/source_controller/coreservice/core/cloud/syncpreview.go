@@ -0,0 +1,134 @@
+/*
+ * Tencent is pleased to support the open source community by making 蓝鲸 available.
+ * Copyright (C) 2017-2018 THL A29 Limited, a Tencent company. All rights reserved.
+ * Licensed under the MIT License (the "License"); you may not use this file except
+ * in compliance with the License. You may obtain a copy of the License at
+ * http://opensource.org/licenses/MIT
+ * Unless required by applicable law or agreed to in writing, software distributed under
+ * the License is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND,
+ * either express or implied. See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package cloud
+
+import (
+	"time"
+
+	"configcenter/src/common"
+	"configcenter/src/common/blog"
+	"configcenter/src/common/errors"
+	"configcenter/src/common/http/rest"
+	"configcenter/src/common/metadata"
+	"configcenter/src/common/util"
+)
+
+// CreateSyncPreview 创建云同步预览，同步任务之前待审批的同步预览会被替换
+func (c *cloudOperation) CreateSyncPreview(kit *rest.Kit, preview *metadata.CloudSyncPreview) (
+	*metadata.CloudSyncPreview, errors.CCErrorCoder) {
+
+	if preview.TaskID <= 0 {
+		return nil, kit.CCError.CCErrorf(common.CCErrCommParamsNeedSet, common.BKCloudSyncTaskID)
+	}
+
+	if err := c.DeletePendingSyncPreview(kit, preview.TaskID); err != nil {
+		return nil, err
+	}
+
+	id, err := c.dbProxy.NextSequence(kit.Ctx, common.BKTableNameCloudSyncPreview)
+	if err != nil {
+		blog.Errorf("generate cloud sync preview id failed, err: %v, rid: %s", err, kit.Rid)
+		return nil, kit.CCError.CCErrorf(common.CCErrCommGenerateRecordIDFailed)
+	}
+
+	preview.PreviewID = int64(id)
+	preview.Status = metadata.CloudSyncPreviewPending
+	preview.Approver = ""
+	preview.ApplyTime = nil
+	preview.OwnerID = kit.SupplierAccount
+	preview.CreateTime = time.Now()
+
+	if err = c.dbProxy.Table(common.BKTableNameCloudSyncPreview).Insert(kit.Ctx, preview); err != nil {
+		blog.Errorf("insert cloud sync preview failed, task id: %d, err: %v, rid: %s", preview.TaskID, err, kit.Rid)
+		return nil, kit.CCError.CCError(common.CCErrCommDBInsertFailed)
+	}
+
+	return preview, nil
+}
+
+// SearchSyncPreview 查询云同步预览
+func (c *cloudOperation) SearchSyncPreview(kit *rest.Kit, option *metadata.SearchSyncPreviewOption) (
+	*metadata.MultipleSyncPreview, errors.CCErrorCoder) {
+
+	cond := map[string]interface{}{common.BKCloudSyncTaskID: option.TaskID}
+	if option.PreviewID > 0 {
+		cond[common.BKCloudSyncPreviewID] = option.PreviewID
+	}
+	if len(option.Status) > 0 {
+		cond[common.BKStatusField] = option.Status
+	}
+	cond = util.SetQueryOwner(cond, kit.SupplierAccount)
+
+	count, err := c.dbProxy.Table(common.BKTableNameCloudSyncPreview).Find(cond).Count(kit.Ctx)
+	if err != nil {
+		blog.Errorf("count cloud sync previews failed, cond: %+v, err: %v, rid: %s", cond, err, kit.Rid)
+		return nil, kit.CCError.CCError(common.CCErrCommDBSelectFailed)
+	}
+
+	sort := option.Page.Sort
+	if len(sort) == 0 {
+		sort = "-" + common.BKCloudSyncPreviewID
+	}
+
+	previews := make([]metadata.CloudSyncPreview, 0)
+	err = c.dbProxy.Table(common.BKTableNameCloudSyncPreview).Find(cond).Sort(sort).
+		Start(uint64(option.Page.Start)).Limit(uint64(option.Page.Limit)).All(kit.Ctx, &previews)
+	if err != nil {
+		blog.Errorf("search cloud sync previews failed, cond: %+v, err: %v, rid: %s", cond, err, kit.Rid)
+		return nil, kit.CCError.CCError(common.CCErrCommDBSelectFailed)
+	}
+
+	return &metadata.MultipleSyncPreview{Count: int64(count), Info: previews}, nil
+}
+
+// ApplySyncPreview 将待审批的云同步预览置为已应用状态，同步预览不存在或不是待审批状态时返回错误
+func (c *cloudOperation) ApplySyncPreview(kit *rest.Kit, previewID int64) errors.CCErrorCoder {
+	cond := map[string]interface{}{
+		common.BKCloudSyncPreviewID: previewID,
+		common.BKStatusField:        metadata.CloudSyncPreviewPending,
+	}
+	cond = util.SetModOwner(cond, kit.SupplierAccount)
+
+	doc := map[string]interface{}{
+		common.BKStatusField: metadata.CloudSyncPreviewApplied,
+		"approver":           kit.User,
+		"apply_time":         time.Now(),
+	}
+
+	count, err := c.dbProxy.Table(common.BKTableNameCloudSyncPreview).UpdateMany(kit.Ctx, cond, doc)
+	if err != nil {
+		blog.Errorf("update cloud sync preview failed, cond: %+v, err: %v, rid: %s", cond, err, kit.Rid)
+		return kit.CCError.CCError(common.CCErrCommDBUpdateFailed)
+	}
+
+	if count == 0 {
+		blog.Errorf("cloud sync preview %d is not pending, rid: %s", previewID, kit.Rid)
+		return kit.CCError.CCErrorf(common.CCErrCloudSyncPreviewNotPending, previewID)
+	}
+	return nil
+}
+
+// DeletePendingSyncPreview 删除同步任务待审批的同步预览，在同步任务没有差异时清理过期的同步预览
+func (c *cloudOperation) DeletePendingSyncPreview(kit *rest.Kit, taskID int64) errors.CCErrorCoder {
+	cond := map[string]interface{}{
+		common.BKCloudSyncTaskID: taskID,
+		common.BKStatusField:     metadata.CloudSyncPreviewPending,
+	}
+	cond = util.SetModOwner(cond, kit.SupplierAccount)
+
+	if err := c.dbProxy.Table(common.BKTableNameCloudSyncPreview).Delete(kit.Ctx, cond); err != nil {
+		blog.Errorf("delete pending cloud sync previews failed, cond: %+v, err: %v, rid: %s", cond, err, kit.Rid)
+		return kit.CCError.CCError(common.CCErrCommDBDeleteFailed)
+	}
+	return nil
+}
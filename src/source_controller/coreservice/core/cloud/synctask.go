@@ -129,6 +129,12 @@ func (c *cloudOperation) DeleteSyncTask(kit *rest.Kit, taskID int64) errors.CCEr
 		return kit.CCError.CCError(common.CCErrCommDBDeleteFailed)
 	}
 
+	// 删除同步任务的同步预览
+	if err := c.dbProxy.Table(common.BKTableNameCloudSyncPreview).Delete(kit.Ctx, cond); err != nil {
+		blog.Errorf("delete cloud sync previews failed, filter: %+v, err: %v, rid: %s", cond, err, kit.Rid)
+		return kit.CCError.CCError(common.CCErrCommDBDeleteFailed)
+	}
+
 	return nil
 }
 
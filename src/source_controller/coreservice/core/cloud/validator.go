@@ -172,6 +172,16 @@ func (c *cloudOperation) validCreateSyncTask(kit *rest.Kit, task *metadata.Cloud
 		return err
 	}
 
+	if err := task.SyncMode.Validate(); err != nil {
+		blog.Errorf("sync mode %s is invalid, rid: %s", task.SyncMode, kit.Rid)
+		return kit.CCError.CCErrorf(common.CCErrCloudValidSyncTaskParamFail, common.BKCloudSyncMode)
+	}
+
+	if err := metadata.ValidateCloudSyncRemoveThreshold(task.RemoveThreshold); err != nil {
+		blog.Errorf("remove threshold %d is invalid, rid: %s", task.RemoveThreshold, kit.Rid)
+		return kit.CCError.CCErrorf(common.CCErrCloudValidSyncTaskParamFail, common.BKCloudSyncRemoveThreshold)
+	}
+
 	// account task count check, one account can only have one task
 	option := &metadata.SearchCloudOption{Condition: mapstr.MapStr{common.BKCloudAccountID: task.AccountID}}
 	multiTask, err := c.SearchSyncTask(kit, option)
@@ -242,6 +252,22 @@ func (c *cloudOperation) validUpdateSyncTask(kit *rest.Kit, taskID int64, option
 
 	}
 
+	if option.Exists(common.BKCloudSyncMode) {
+		mode, err := option.String(common.BKCloudSyncMode)
+		if err != nil || metadata.CloudSyncMode(mode).Validate() != nil {
+			blog.Errorf("sync mode %v is invalid, rid: %s", option[common.BKCloudSyncMode], kit.Rid)
+			return kit.CCError.CCErrorf(common.CCErrCloudValidSyncTaskParamFail, common.BKCloudSyncMode)
+		}
+	}
+
+	if option.Exists(common.BKCloudSyncRemoveThreshold) {
+		threshold, err := option.Int64(common.BKCloudSyncRemoveThreshold)
+		if err != nil || metadata.ValidateCloudSyncRemoveThreshold(threshold) != nil {
+			blog.Errorf("remove threshold %v is invalid, rid: %s", option[common.BKCloudSyncRemoveThreshold], kit.Rid)
+			return kit.CCError.CCErrorf(common.CCErrCloudValidSyncTaskParamFail, common.BKCloudSyncRemoveThreshold)
+		}
+	}
+
 	return nil
 }
 
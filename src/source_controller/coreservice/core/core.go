@@ -126,6 +126,8 @@ type InstanceOperation interface {
 	DeleteModelInstance(kit *rest.Kit, objID string, inputParam metadata.DeleteOption) (*metadata.DeletedCount, error)
 	CascadeDeleteModelInstance(kit *rest.Kit, objID string, inputParam metadata.DeleteOption) (*metadata.DeletedCount,
 		error)
+	RestoreModelInstance(kit *rest.Kit, objID string, option *metadata.RestoreInstOption) (
+		*metadata.RestoreInstResult, error)
 }
 
 // KubeOperation crud operations on kube data.
@@ -234,6 +236,7 @@ type AssociationOperation interface {
 type AuditOperation interface {
 	CreateAuditLog(kit *rest.Kit, logs ...metadata.AuditLog) error
 	SearchAuditLog(kit *rest.Kit, param metadata.QueryCondition) ([]metadata.AuditLog, uint64, error)
+	VerifyAuditChain(kit *rest.Kit, opt *metadata.VerifyAuditChainOption) (*metadata.VerifyAuditChainResult, error)
 }
 
 // StatisticOperation TODO
@@ -402,6 +405,15 @@ type HostApplyRuleOperation interface {
 		metadata.MultipleHostApplyResult, errors.CCErrorCoder)
 	SearchRuleRelatedServiceTemplates(kit *rest.Kit, option metadata.RuleRelatedServiceTemplateOption) (
 		[]metadata.SrvTemplate, errors.CCErrorCoder)
+	ReconcileHostApply(kit *rest.Kit, bizID int64, option metadata.ReconcileHostApplyOption) (
+		*metadata.ReconcileHostApplyResult, errors.CCErrorCoder)
+	DeleteHostApplyDrift(kit *rest.Kit, option metadata.DeleteHostApplyDriftOption) errors.CCErrorCoder
+	ListHostApplyDrift(kit *rest.Kit, bizID int64, option metadata.ListHostApplyDriftOption) (
+		*metadata.HostApplyDriftResult, errors.CCErrorCoder)
+	ListHostApplyReconcileConfig(kit *rest.Kit, option metadata.ListHostApplyReconcileConfigOption) (
+		[]metadata.HostApplyReconcileConfig, errors.CCErrorCoder)
+	UpdateHostApplyReconcileConfig(kit *rest.Kit, bizID int64,
+		option metadata.UpdateHostApplyReconcileConfigOption) errors.CCErrorCoder
 }
 
 // CloudOperation TODO
@@ -423,6 +435,12 @@ type CloudOperation interface {
 	SearchSyncHistory(kit *rest.Kit, option *metadata.SearchSyncHistoryOption) (*metadata.MultipleSyncHistory,
 		errors.CCErrorCoder)
 	DeleteDestroyedHostRelated(kit *rest.Kit, option *metadata.DeleteDestroyedHostRelatedOption) errors.CCErrorCoder
+	CreateSyncPreview(kit *rest.Kit, preview *metadata.CloudSyncPreview) (*metadata.CloudSyncPreview,
+		errors.CCErrorCoder)
+	SearchSyncPreview(kit *rest.Kit, option *metadata.SearchSyncPreviewOption) (*metadata.MultipleSyncPreview,
+		errors.CCErrorCoder)
+	ApplySyncPreview(kit *rest.Kit, previewID int64) errors.CCErrorCoder
+	DeletePendingSyncPreview(kit *rest.Kit, taskID int64) errors.CCErrorCoder
 }
 
 // SystemOperation TODO
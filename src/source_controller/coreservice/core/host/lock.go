@@ -47,41 +47,70 @@ func (hm *hostManager) LockHost(kit *rest.Kit, input *metadata.HostLockRequest)
 		return kit.CCError.Errorf(common.CCErrCommParamsIsInvalid, fmt.Sprintf(" id_list %v", diffID))
 	}
 
-	user := util.GetUser(kit.Header)
-	var insertDataArr []interface{}
+	owner := kit.User
+	if len(input.Owner) != 0 && input.Owner != owner {
+		if kit.User != common.CCSystemOperatorUserName {
+			blog.Errorf("lock host, user %s can not lock for owner %s, rid: %s", kit.User, input.Owner, kit.Rid)
+			return kit.CCError.CCErrorf(common.CCErrCommParamsIsInvalid, "owner")
+		}
+		owner = input.Owner
+	}
+
 	ts := time.Now().UTC()
+	var expireTime *time.Time
+	if input.TTL > 0 {
+		expire := ts.Add(time.Duration(input.TTL) * time.Second)
+		expireTime = &expire
+	}
+
+	// the unexpired locks of the other owners can not be taken over, only the owner can renew its own locks
+	lockedIDs, ccErr := getOtherOwnerHostLocks(kit, input.IDS, owner)
+	if ccErr != nil {
+		return ccErr
+	}
+
+	if len(lockedIDs) != 0 {
+		blog.Errorf("lock host, hosts %v are already locked by others, rid: %s", lockedIDs, kit.Rid)
+		return kit.CCError.CCErrorf(common.CCErrHostLocked, lockedIDs, "lock")
+	}
+
+	// the existing locks of the owner are replaced so that the reason, scope and expire time can be renewed
 	for _, id := range input.IDS {
 		conds := mapstr.MapStr{
 			common.BKHostIDField: id,
 		}
 		conds = util.SetQueryOwner(conds, kit.SupplierAccount)
-		cnt, err := mongodb.Client().Table(common.BKTableNameHostLock).Find(conds).Count(kit.Ctx)
-		if nil != err {
-			blog.Errorf("lock host, query host lock from db failed, err:%+v, rid:%s", err, kit.Rid)
-			return kit.CCError.Errorf(common.CCErrCommDBSelectFailed)
+		lock := metadata.HostLockData{
+			User:       owner,
+			ID:         id,
+			Reason:     input.Reason,
+			Scope:      input.Scope,
+			ExpireTime: expireTime,
+			CreateTime: ts,
+			OwnerID:    util.GetOwnerID(kit.Header),
 		}
-		if 0 == cnt {
-			insertDataArr = append(insertDataArr, metadata.HostLockData{
-				User:       user,
-				ID:         id,
-				CreateTime: ts,
-				OwnerID:    util.GetOwnerID(kit.Header),
-			})
-		}
-	}
-
-	if 0 < len(insertDataArr) {
-		err := mongodb.Client().Table(common.BKTableNameHostLock).Insert(kit.Ctx, insertDataArr)
-		if nil != err {
-			blog.Errorf("lock host, save host lock to db failed, err: %+v, rid:%s", err, kit.Rid)
+		if err := mongodb.Client().Table(common.BKTableNameHostLock).Upsert(kit.Ctx, conds, lock); err != nil {
+			blog.Errorf("lock host, save host lock %+v to db failed, err: %v, rid: %s", lock, err, kit.Rid)
 			return kit.CCError.Errorf(common.CCErrCommDBInsertFailed)
 		}
 	}
 	return nil
 }
 
-// UnlockHost TODO
+// UnlockHost release the host locks, the locks of the other owners can only be released by force
 func (hm *hostManager) UnlockHost(kit *rest.Kit, input *metadata.HostLockRequest) errors.CCError {
+	if !input.Force {
+		lockedIDs, ccErr := getOtherOwnerHostLocks(kit, input.IDS, kit.User)
+		if ccErr != nil {
+			return ccErr
+		}
+
+		if len(lockedIDs) != 0 {
+			blog.Errorf("unlock host, hosts %v are locked by others, rid: %s", lockedIDs, kit.Rid)
+			return kit.CCError.CCErrorf(common.CCErrHostLocked, lockedIDs, "unlock")
+		}
+	}
+
 	conds := mapstr.MapStr{
 		common.BKHostIDField: mapstr.MapStr{common.BKDBIN: input.IDS},
 	}
@@ -102,6 +131,11 @@ func (hm *hostManager) QueryHostLock(kit *rest.Kit, input *metadata.QueryHostLoc
 		common.BKHostIDField: mapstr.MapStr{common.BKDBIN: input.IDS},
 	}
 	conds = util.SetModOwner(conds, kit.SupplierAccount)
+	// expired locks may not be removed by mongodb yet, skip them
+	conds[common.BKDBOR] = []mapstr.MapStr{
+		{metadata.HostLockExpireTimeField: nil},
+		{metadata.HostLockExpireTimeField: mapstr.MapStr{common.BKDBGT: time.Now().UTC()}},
+	}
 	limit := uint64(len(input.IDS))
 	err := mongodb.Client().Table(common.BKTableNameHostLock).Find(conds).Limit(limit).All(kit.Ctx, &hostLockInfoArr)
 	if nil != err {
@@ -111,6 +145,33 @@ func (hm *hostManager) QueryHostLock(kit *rest.Kit, input *metadata.QueryHostLoc
 	return hostLockInfoArr, nil
 }
 
+// getOtherOwnerHostLocks returns the ids of the hosts that are locked by the other owners and not expired
+func getOtherOwnerHostLocks(kit *rest.Kit, ids []int64, owner string) ([]int64, errors.CCError) {
+	conds := mapstr.MapStr{
+		common.BKHostIDField:       mapstr.MapStr{common.BKDBIN: ids},
+		metadata.HostLockUserField: mapstr.MapStr{common.BKDBNE: owner},
+		common.BKDBOR: []mapstr.MapStr{
+			{metadata.HostLockExpireTimeField: nil},
+			{metadata.HostLockExpireTimeField: mapstr.MapStr{common.BKDBGT: time.Now().UTC()}},
+		},
+	}
+	conds = util.SetQueryOwner(conds, kit.SupplierAccount)
+
+	locks := make([]metadata.HostLockData, 0)
+	err := mongodb.Client().Table(common.BKTableNameHostLock).Find(conds).Fields(common.BKHostIDField).
+		All(kit.Ctx, &locks)
+	if err != nil {
+		blog.Errorf("lock host, query host lock from db failed, err: %v, rid: %s", err, kit.Rid)
+		return nil, kit.CCError.CCErrorf(common.CCErrCommDBSelectFailed)
+	}
+
+	lockedIDs := make([]int64, len(locks))
+	for idx, lock := range locks {
+		lockedIDs[idx] = lock.ID
+	}
+	return lockedIDs, nil
+}
+
 func diffHostLockID(ids []int64, hostInfos []metadata.HostMapStr, rid string) []int64 {
 	mapInnerID := make(map[int64]bool)
 	for _, hostInfo := range hostInfos {
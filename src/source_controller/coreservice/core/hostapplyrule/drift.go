@@ -0,0 +1,239 @@
+/*
+ * Tencent is pleased to support the open source community by making 蓝鲸 available.
+ * Copyright (C) 2017-2018 THL A29 Limited, a Tencent company. All rights reserved.
+ * Licensed under the MIT License (the "License"); you may not use this file except
+ * in compliance with the License. You may obtain a copy of the License at
+ * http://opensource.org/licenses/MIT
+ * Unless required by applicable law or agreed to in writing, software distributed under
+ * the License is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND,
+ * either express or implied. See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package hostapplyrule
+
+import (
+	"time"
+
+	"configcenter/src/common"
+	"configcenter/src/common/blog"
+	"configcenter/src/common/errors"
+	"configcenter/src/common/http/rest"
+	"configcenter/src/common/metadata"
+	"configcenter/src/storage/driver/mongodb"
+)
+
+// ReconcileHostApply check if the hosts drift from the host apply rules of their modules, the drifted fields are
+// recorded as drifts and the previous drifts of the hosts are replaced. the drifted fields are not re-applied here,
+// the host server re-applies them with the host lock check and the host audit logs.
+func (p *hostApplyRule) ReconcileHostApply(kit *rest.Kit, bizID int64, option metadata.ReconcileHostApplyOption) (
+	*metadata.ReconcileHostApplyResult, errors.CCErrorCoder) {
+
+	result := &metadata.ReconcileHostApplyResult{Drifts: make([]metadata.HostApplyDrift, 0)}
+
+	relationFilter := map[string]interface{}{
+		common.BKAppIDField:  bizID,
+		common.BKHostIDField: map[string]interface{}{common.BKDBIN: option.HostIDs},
+	}
+	relations := make([]metadata.ModuleHost, 0)
+	err := mongodb.Client().Table(common.BKTableNameModuleHostConfig).Find(relationFilter).All(kit.Ctx, &relations)
+	if err != nil {
+		blog.Errorf("find host relations failed, filter: %+v, err: %v, rid: %s", relationFilter, err, kit.Rid)
+		return nil, kit.CCError.CCError(common.CCErrCommDBSelectFailed)
+	}
+
+	if len(relations) > 0 {
+		planResult, ccErr := p.generateHostsApplyPlan(kit, bizID, relations)
+		if ccErr != nil {
+			return nil, ccErr
+		}
+
+		now := time.Now()
+		for _, plan := range planResult.Plans {
+			result.Drifts = append(result.Drifts, convertPlanToDrifts(kit, bizID, plan, now)...)
+		}
+	}
+
+	if err := p.replaceHostApplyDrifts(kit, option.HostIDs, result.Drifts); err != nil {
+		return nil, err
+	}
+
+	return result, nil
+}
+
+// convertPlanToDrifts convert the fields to be updated in the host apply plan to drifts, the actual value of the
+// field is the original value in the conflict field, since the expect host in the plan is already changed.
+func convertPlanToDrifts(kit *rest.Kit, bizID int64, plan metadata.OneHostApplyPlan,
+	detectTime time.Time) []metadata.HostApplyDrift {
+
+	actualValues := make(map[int64]interface{})
+	for _, field := range plan.ConflictFields {
+		actualValues[field.AttributeID] = field.PropertyValue
+	}
+
+	drifts := make([]metadata.HostApplyDrift, 0)
+	for _, field := range plan.UpdateFields {
+		drifts = append(drifts, metadata.HostApplyDrift{
+			BizID:           bizID,
+			HostID:          plan.HostID,
+			ModuleIDs:       plan.ModuleIDs,
+			AttributeID:     field.AttributeID,
+			PropertyID:      field.PropertyID,
+			ExpectValue:     field.PropertyValue,
+			ActualValue:     actualValues[field.AttributeID],
+			DetectTime:      detectTime,
+			SupplierAccount: kit.SupplierAccount,
+		})
+	}
+	return drifts
+}
+
+// replaceHostApplyDrifts replace the previous drifts of the hosts with the new drifts
+func (p *hostApplyRule) replaceHostApplyDrifts(kit *rest.Kit, hostIDs []int64,
+	drifts []metadata.HostApplyDrift) errors.CCErrorCoder {
+
+	if err := p.DeleteHostApplyDrift(kit, metadata.DeleteHostApplyDriftOption{HostIDs: hostIDs}); err != nil {
+		return err
+	}
+
+	if len(drifts) == 0 {
+		return nil
+	}
+
+	if err := mongodb.Client().Table(common.BKTableNameHostApplyDrift).Insert(kit.Ctx, drifts); err != nil {
+		blog.Errorf("insert host apply drifts failed, drifts: %+v, err: %v, rid: %s", drifts, err, kit.Rid)
+		return kit.CCError.CCError(common.CCErrCommDBInsertFailed)
+	}
+	return nil
+}
+
+// DeleteHostApplyDrift delete the host apply drifts of the hosts
+func (p *hostApplyRule) DeleteHostApplyDrift(kit *rest.Kit,
+	option metadata.DeleteHostApplyDriftOption) errors.CCErrorCoder {
+	filter := map[string]interface{}{
+		common.BKHostIDField:     map[string]interface{}{common.BKDBIN: option.HostIDs},
+		common.BkSupplierAccount: kit.SupplierAccount,
+	}
+
+	if err := mongodb.Client().Table(common.BKTableNameHostApplyDrift).Delete(kit.Ctx, filter); err != nil {
+		blog.Errorf("delete host apply drifts failed, filter: %+v, err: %v, rid: %s", filter, err, kit.Rid)
+		return kit.CCError.CCError(common.CCErrCommDBDeleteFailed)
+	}
+	return nil
+}
+
+// ListHostApplyDrift list the host apply drifts in the business
+func (p *hostApplyRule) ListHostApplyDrift(kit *rest.Kit, bizID int64, option metadata.ListHostApplyDriftOption) (
+	*metadata.HostApplyDriftResult, errors.CCErrorCoder) {
+
+	filter := map[string]interface{}{
+		common.BKAppIDField:      bizID,
+		common.BkSupplierAccount: kit.SupplierAccount,
+	}
+	if len(option.HostIDs) != 0 {
+		filter[common.BKHostIDField] = map[string]interface{}{common.BKDBIN: option.HostIDs}
+	}
+	if len(option.ModuleIDs) != 0 {
+		filter[metadata.HostApplyDriftModuleIDsField] = map[string]interface{}{common.BKDBIN: option.ModuleIDs}
+	}
+	if len(option.PropertyIDs) != 0 {
+		filter[common.BKPropertyIDField] = map[string]interface{}{common.BKDBIN: option.PropertyIDs}
+	}
+
+	if option.Page.EnableCount {
+		count, err := mongodb.Client().Table(common.BKTableNameHostApplyDrift).Find(filter).Count(kit.Ctx)
+		if err != nil {
+			blog.Errorf("count host apply drifts failed, filter: %+v, err: %v, rid: %s", filter, err, kit.Rid)
+			return nil, kit.CCError.CCError(common.CCErrCommDBSelectFailed)
+		}
+		return &metadata.HostApplyDriftResult{Count: int64(count)}, nil
+	}
+
+	sort := option.Page.Sort
+	if len(sort) == 0 {
+		sort = common.BKHostIDField
+	}
+
+	drifts := make([]metadata.HostApplyDrift, 0)
+	err := mongodb.Client().Table(common.BKTableNameHostApplyDrift).Find(filter).Sort(sort).
+		Start(uint64(option.Page.Start)).Limit(uint64(option.Page.Limit)).All(kit.Ctx, &drifts)
+	if err != nil {
+		blog.Errorf("list host apply drifts failed, filter: %+v, err: %v, rid: %s", filter, err, kit.Rid)
+		return nil, kit.CCError.CCError(common.CCErrCommDBSelectFailed)
+	}
+
+	return &metadata.HostApplyDriftResult{Info: drifts}, nil
+}
+
+// ListHostApplyReconcileConfig list the host apply reconcile configs of the businesses, the businesses without config
+// are returned with the disabled mode.
+func (p *hostApplyRule) ListHostApplyReconcileConfig(kit *rest.Kit,
+	option metadata.ListHostApplyReconcileConfigOption) ([]metadata.HostApplyReconcileConfig, errors.CCErrorCoder) {
+
+	filter := map[string]interface{}{
+		common.BKAppIDField:      map[string]interface{}{common.BKDBIN: option.BizIDs},
+		common.BkSupplierAccount: kit.SupplierAccount,
+	}
+
+	configs := make([]metadata.HostApplyReconcileConfig, 0)
+	err := mongodb.Client().Table(common.BKTableNameHostApplyReconcileConfig).Find(filter).All(kit.Ctx, &configs)
+	if err != nil {
+		blog.Errorf("list host apply reconcile configs failed, filter: %+v, err: %v, rid: %s", filter, err, kit.Rid)
+		return nil, kit.CCError.CCError(common.CCErrCommDBSelectFailed)
+	}
+
+	configMap := make(map[int64]metadata.HostApplyReconcileConfig)
+	for _, config := range configs {
+		configMap[config.BizID] = config
+	}
+
+	result := make([]metadata.HostApplyReconcileConfig, 0)
+	for _, bizID := range option.BizIDs {
+		config, exists := configMap[bizID]
+		if !exists {
+			config = metadata.HostApplyReconcileConfig{
+				BizID:           bizID,
+				Mode:            metadata.HostApplyReconcileDisabled,
+				SupplierAccount: kit.SupplierAccount,
+			}
+		}
+		result = append(result, config)
+	}
+
+	return result, nil
+}
+
+// UpdateHostApplyReconcileConfig create or update the host apply reconcile config of the business, the drifts of the
+// business are cleared when the reconciliation is disabled.
+func (p *hostApplyRule) UpdateHostApplyReconcileConfig(kit *rest.Kit, bizID int64,
+	option metadata.UpdateHostApplyReconcileConfigOption) errors.CCErrorCoder {
+
+	filter := map[string]interface{}{
+		common.BKAppIDField:      bizID,
+		common.BkSupplierAccount: kit.SupplierAccount,
+	}
+
+	config := metadata.HostApplyReconcileConfig{
+		BizID:           bizID,
+		Mode:            option.Mode,
+		Modifier:        kit.User,
+		LastTime:        time.Now(),
+		SupplierAccount: kit.SupplierAccount,
+	}
+
+	err := mongodb.Client().Table(common.BKTableNameHostApplyReconcileConfig).Upsert(kit.Ctx, filter, config)
+	if err != nil {
+		blog.Errorf("upsert host apply reconcile config failed, config: %+v, err: %v, rid: %s", config, err, kit.Rid)
+		return kit.CCError.CCError(common.CCErrCommDBUpdateFailed)
+	}
+
+	if option.Mode != metadata.HostApplyReconcileDisabled {
+		return nil
+	}
+
+	if err := mongodb.Client().Table(common.BKTableNameHostApplyDrift).Delete(kit.Ctx, filter); err != nil {
+		blog.Errorf("delete host apply drifts failed, filter: %+v, err: %v, rid: %s", filter, err, kit.Rid)
+		return kit.CCError.CCError(common.CCErrCommDBDeleteFailed)
+	}
+	return nil
+}
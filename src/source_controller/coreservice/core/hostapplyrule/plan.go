@@ -16,6 +16,7 @@ import (
 	"fmt"
 	"sort"
 	"strings"
+	"time"
 
 	"configcenter/src/common"
 	"configcenter/src/common/blog"
@@ -529,6 +530,31 @@ func (p *hostApplyRule) RunHostApplyOnHosts(kit *rest.Kit, bizID int64, relation
 
 	result := metadata.MultipleHostApplyResult{HostResults: make([]metadata.HostApplyResult, 0)}
 
+	planResult, err := p.generateHostsApplyPlan(kit, bizID, relations)
+	if err != nil {
+		return result, err
+	}
+
+	if len(planResult.Plans) == 0 {
+		return result, nil
+	}
+
+	result.HostResults = p.carryOutPlan(kit, planResult.Plans)
+	for _, hostResult := range result.HostResults {
+		// the locked hosts are skipped, they do not fail the other hosts
+		if ccErr := hostResult.GetError(); ccErr != nil && ccErr.GetCode() != common.CCErrHostLocked {
+			result.SetError(ccErr)
+			break
+		}
+	}
+	return result, result.GetError()
+}
+
+// generateHostsApplyPlan generate the host apply plan of the hosts by the enabled rules of their modules, only the
+// hosts that need to be changed are returned in the plan
+func (p *hostApplyRule) generateHostsApplyPlan(kit *rest.Kit, bizID int64, relations []metadata.ModuleHost) (
+	metadata.HostApplyPlanResult, errors.CCErrorCoder) {
+
 	moduleIDs := make([]int64, 0)
 	for _, item := range relations {
 		moduleIDs = append(moduleIDs, item.ModuleID)
@@ -542,12 +568,12 @@ func (p *hostApplyRule) RunHostApplyOnHosts(kit *rest.Kit, bizID int64, relation
 		All(kit.Ctx, &modules)
 	if err != nil {
 		blog.Errorf("search modules info failed, filter: %s, err: %v, rid: %s", moduleFilter, err, kit.Rid)
-		return result, kit.CCError.CCError(common.CCErrCommDBSelectFailed)
+		return metadata.HostApplyPlanResult{}, kit.CCError.CCError(common.CCErrCommDBSelectFailed)
 	}
 
 	enableModuleMap, haveHostApplyIDs, srvTemplateIDMap, cErr := getModuleIDsAndSrvTempIDs(kit, modules)
-	if err != nil {
-		return result, cErr
+	if cErr != nil {
+		return metadata.HostApplyPlanResult{}, cErr
 	}
 
 	host2Modules := make(map[int64][]int64)
@@ -575,11 +601,11 @@ func (p *hostApplyRule) RunHostApplyOnHosts(kit *rest.Kit, bizID int64, relation
 
 	finalRules, cErr := p.getFinalRules(kit, bizID, haveHostApplyIDs, serviceTemplateIDs, srvTemplateIDMap)
 	if cErr != nil {
-		return result, cErr
+		return metadata.HostApplyPlanResult{}, cErr
 	}
 
-	if len(finalRules) == 0 {
-		return result, nil
+	if len(finalRules) == 0 || len(hostModules) == 0 {
+		return metadata.HostApplyPlanResult{Plans: make([]metadata.OneHostApplyPlan, 0)}, nil
 	}
 
 	planOption := metadata.HostApplyPlanOption{
@@ -590,17 +616,10 @@ func (p *hostApplyRule) RunHostApplyOnHosts(kit *rest.Kit, bizID int64, relation
 	planResult, ccErr := p.GenerateApplyPlan(kit, bizID, planOption)
 	if ccErr != nil {
 		blog.Errorf("generate apply plan failed, option: %v, err: %v, rid: %s", planOption, ccErr, kit.Rid)
-		return result, kit.CCError.CCError(common.CCErrCommDBSelectFailed)
+		return metadata.HostApplyPlanResult{}, kit.CCError.CCError(common.CCErrCommDBSelectFailed)
 	}
 
-	result.HostResults = p.carryOutPlan(kit, planResult.Plans)
-	for _, hostResult := range result.HostResults {
-		if ccErr := hostResult.GetError(); ccErr != nil {
-			result.SetError(ccErr)
-			break
-		}
-	}
-	return result, result.GetError()
+	return planResult, nil
 }
 
 type updateHostOption struct {
@@ -611,6 +630,24 @@ type updateHostOption struct {
 func (p *hostApplyRule) carryOutPlan(kit *rest.Kit, plans []metadata.OneHostApplyPlan) []metadata.HostApplyResult {
 	hostResults := make([]metadata.HostApplyResult, 0)
 
+	hostIDs := make([]int64, 0)
+	for _, plan := range plans {
+		if len(plan.UpdateFields) != 0 {
+			hostIDs = append(hostIDs, plan.HostID)
+		}
+	}
+
+	// the hosts that are locked for update are not applied, they are returned with the host locked error
+	lockedHosts, err := getUpdateLockedHosts(kit, hostIDs)
+	if err != nil {
+		for _, plan := range plans {
+			applyResult := metadata.HostApplyResult{HostID: plan.HostID}
+			applyResult.SetError(err)
+			hostResults = append(hostResults, applyResult)
+		}
+		return hostResults
+	}
+
 	// group hosts with the same host apply update data together, updateDataMap key is the json format of update data
 	updateDataMap := make(map[string]*updateHostOption)
 	for _, plan := range plans {
@@ -619,6 +656,14 @@ func (p *hostApplyRule) carryOutPlan(kit *rest.Kit, plans []metadata.OneHostAppl
 			continue
 		}
 
+		if _, exists := lockedHosts[plan.HostID]; exists {
+			applyResult := metadata.HostApplyResult{HostID: plan.HostID}
+			applyResult.SetError(kit.CCError.CCErrorf(common.CCErrHostLocked, []int64{plan.HostID},
+				metadata.HostLockScopeUpdate))
+			hostResults = append(hostResults, applyResult)
+			continue
+		}
+
 		dataStr := plan.GetUpdateDataStr()
 		if _, exists := updateDataMap[dataStr]; !exists {
 			updateDataMap[dataStr] = &updateHostOption{
@@ -659,3 +704,33 @@ func (p *hostApplyRule) carryOutPlan(kit *rest.Kit, plans []metadata.OneHostAppl
 
 	return hostResults
 }
+
+// getUpdateLockedHosts get the hosts that are locked for update, the expired locks are skipped
+func getUpdateLockedHosts(kit *rest.Kit, hostIDs []int64) (map[int64]struct{}, errors.CCErrorCoder) {
+	lockedHosts := make(map[int64]struct{})
+	if len(hostIDs) == 0 {
+		return lockedHosts, nil
+	}
+
+	filter := map[string]interface{}{
+		common.BKHostIDField: map[string]interface{}{common.BKDBIN: hostIDs},
+		common.BKDBOR: []map[string]interface{}{
+			{metadata.HostLockExpireTimeField: nil},
+			{metadata.HostLockExpireTimeField: map[string]interface{}{common.BKDBGT: time.Now().UTC()}},
+		},
+	}
+	filter = util.SetQueryOwner(filter, kit.SupplierAccount)
+
+	locks := make([]metadata.HostLockData, 0)
+	if err := mongodb.Client().Table(common.BKTableNameHostLock).Find(filter).All(kit.Ctx, &locks); err != nil {
+		blog.Errorf("find host locks failed, filter: %+v, err: %v, rid: %s", filter, err, kit.Rid)
+		return nil, kit.CCError.CCError(common.CCErrCommDBSelectFailed)
+	}
+
+	for _, lock := range locks {
+		if lock.InScope(metadata.HostLockScopeUpdate) {
+			lockedHosts[lock.ID] = struct{}{}
+		}
+	}
+	return lockedHosts, nil
+}
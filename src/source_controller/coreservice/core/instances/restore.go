@@ -0,0 +1,505 @@
+/*
+ * Tencent is pleased to support the open source community by making 蓝鲸 available.,
+ * Copyright (C) 2017-2018 THL A29 Limited, a Tencent company. All rights reserved.
+ * Licensed under the MIT License (the ",License",); you may not use this file except
+ * in compliance with the License. You may obtain a copy of the License at
+ * http://opensource.org/licenses/MIT
+ * Unless required by applicable law or agreed to in writing, software distributed under
+ * the License is distributed on an ",AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND,
+ * either express or implied. See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package instances
+
+import (
+	"time"
+
+	"configcenter/src/common"
+	"configcenter/src/common/blog"
+	"configcenter/src/common/http/rest"
+	"configcenter/src/common/mapstr"
+	"configcenter/src/common/metadata"
+	"configcenter/src/common/util"
+	"configcenter/src/storage/driver/mongodb"
+	"configcenter/src/storage/driver/mongodb/instancemapping"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// restoreArchiveWindow is the time window before an instance is archived, the associations that are archived in it
+// are regarded as deleted together with the instance, older ones are deleted on purpose.
+const restoreArchiveWindow = time.Minute
+
+// instArchive is the archived instance in the delete archive table
+type instArchive struct {
+	ID     primitive.ObjectID `bson:"_id"`
+	Detail mapstr.MapStr      `bson:"detail"`
+}
+
+// hostArchive is the archived host in the delete archive table, its ip and operator fields need to be converted
+type hostArchive struct {
+	ID     primitive.ObjectID  `bson:"_id"`
+	Detail metadata.HostMapStr `bson:"detail"`
+}
+
+// instAsstArchive is the archived instance association in the delete archive table
+type instAsstArchive struct {
+	ID     primitive.ObjectID `bson:"_id"`
+	Detail metadata.InstAsst  `bson:"detail"`
+}
+
+// RestoreModelInstance restore deleted instances with their original ids from the delete archive, the associations
+// that are deleted together with them are restored too if they are still valid, and the hosts are restored into
+// the resource pool idle module.
+func (m *instanceManager) RestoreModelInstance(kit *rest.Kit, objID string, option *metadata.RestoreInstOption) (
+	*metadata.RestoreInstResult, error) {
+
+	if rawErr := option.Validate(); rawErr.ErrCode != 0 {
+		return nil, rawErr.ToCCError(kit.CCError)
+	}
+
+	isMainline, err := m.isMainlineObject(kit, objID)
+	if err != nil {
+		return nil, err
+	}
+
+	result := &metadata.RestoreInstResult{
+		Instances:     make([]mapstr.MapStr, 0),
+		Associations:  make([]metadata.InstAsst, 0),
+		HostRelations: make([]metadata.ModuleHost, 0),
+	}
+
+	for _, instID := range option.InstIDs {
+		inst, archivedAt, err := m.restoreOneInstance(kit, objID, instID, option.BizID, isMainline)
+		if err != nil {
+			return nil, err
+		}
+		result.Instances = append(result.Instances, inst)
+
+		assts, err := m.restoreInstAssociations(kit, objID, instID, archivedAt)
+		if err != nil {
+			return nil, err
+		}
+		result.Associations = append(result.Associations, assts...)
+
+		if objID != common.BKInnerObjIDHost {
+			continue
+		}
+
+		relations, err := m.restoreHostRelations(kit, instID)
+		if err != nil {
+			return nil, err
+		}
+		result.HostRelations = append(result.HostRelations, relations...)
+	}
+
+	return result, nil
+}
+
+func (m *instanceManager) restoreOneInstance(kit *rest.Kit, objID string, instID, bizID int64, isMainline bool) (
+	mapstr.MapStr, time.Time, error) {
+
+	instIDField := common.GetInstIDField(objID)
+	_, exists, err := m.instCnt(kit, objID, mapstr.MapStr{instIDField: instID})
+	if err != nil {
+		blog.Errorf("count %s instance %d failed, err: %v, rid: %s", objID, instID, err, kit.Rid)
+		return nil, time.Time{}, kit.CCError.CCError(common.CCErrCommDBSelectFailed)
+	}
+
+	if exists {
+		blog.Errorf("%s instance %d already exists, can not be restored, rid: %s", objID, instID, kit.Rid)
+		return nil, time.Time{}, kit.CCError.CCErrorf(common.CCErrCommDuplicateItem, instIDField)
+	}
+
+	inst, archivedAt, err := m.getInstArchive(kit, objID, instID)
+	if err != nil {
+		return nil, time.Time{}, err
+	}
+
+	instBizID, err := m.getBizIDFromInstance(kit, objID, inst, common.ValidCreate, 0)
+	if err != nil {
+		return nil, time.Time{}, err
+	}
+
+	if bizID != 0 && instBizID != bizID {
+		blog.Errorf("%s instance %d belongs to biz %d, not %d, rid: %s", objID, instID, instBizID, bizID, kit.Rid)
+		return nil, time.Time{}, kit.CCError.CCErrorf(common.CCErrCommParamsInvalid, common.BKAppIDField)
+	}
+
+	if err := m.validRestoreTopology(kit, objID, inst, instBizID, isMainline); err != nil {
+		return nil, time.Time{}, err
+	}
+
+	// validate a copy of the archived data with the current attributes, the archived data is saved as it is
+	validator, err := m.newValidator(kit, objID, instBizID)
+	if err != nil {
+		return nil, time.Time{}, err
+	}
+
+	validData := make(mapstr.MapStr)
+	for key, val := range inst {
+		if dateTime, ok := val.(primitive.DateTime); ok {
+			val = dateTime.Time()
+		}
+		validData[key] = val
+	}
+
+	if err := m.validCreateInstanceData(kit, objID, validData, validator); err != nil {
+		blog.Errorf("validate restored %s instance %d failed, err: %v, rid: %s", objID, instID, err, kit.Rid)
+		return nil, time.Time{}, err
+	}
+
+	if err := m.saveRestoredInstance(kit, objID, instID, inst); err != nil {
+		return nil, time.Time{}, err
+	}
+
+	return inst, archivedAt, nil
+}
+
+// getInstArchive get the latest archived data of the deleted instance and the time it is archived
+func (m *instanceManager) getInstArchive(kit *rest.Kit, objID string, instID int64) (mapstr.MapStr, time.Time,
+	error) {
+
+	filter := mapstr.MapStr{
+		"coll":                                   common.GetInstTableName(objID, kit.SupplierAccount),
+		"detail." + common.GetInstIDField(objID): instID,
+		"detail." + common.BkSupplierAccount:     kit.SupplierAccount,
+	}
+	query := mongodb.Client().Table(common.BKTableNameDelArchive).Find(filter).Sort("_id:-1")
+
+	var inst mapstr.MapStr
+	var archiveID primitive.ObjectID
+	var err error
+	if objID == common.BKInnerObjIDHost {
+		archive := new(hostArchive)
+		err = query.One(kit.Ctx, archive)
+		inst, archiveID = mapstr.MapStr(archive.Detail), archive.ID
+	} else {
+		archive := new(instArchive)
+		err = query.One(kit.Ctx, archive)
+		inst, archiveID = archive.Detail, archive.ID
+	}
+
+	if err != nil {
+		if mongodb.Client().IsNotFoundError(err) {
+			blog.Errorf("archive of %s instance %d is not found, rid: %s", objID, instID, kit.Rid)
+			return nil, time.Time{}, kit.CCError.CCErrorf(common.CCErrCommNotFound)
+		}
+		blog.Errorf("get archive of %s instance %d failed, err: %v, rid: %s", objID, instID, err, kit.Rid)
+		return nil, time.Time{}, kit.CCError.CCError(common.CCErrCommDBSelectFailed)
+	}
+
+	return inst, archiveID.Timestamp(), nil
+}
+
+// validRestoreTopology check if the topology that the instance belongs to still exists, the mainline parent of set
+// and module etc. must exist in the same business, and so do the set template of the set and the service template
+// of the module.
+func (m *instanceManager) validRestoreTopology(kit *rest.Kit, objID string, inst mapstr.MapStr, bizID int64,
+	isMainline bool) error {
+
+	if isMainline && objID != common.BKInnerObjIDApp {
+		parentID, err := inst.Int64(common.BKParentIDField)
+		if err != nil {
+			return kit.CCError.CCErrorf(common.CCErrCommParamsInvalid, common.BKParentIDField)
+		}
+
+		asst := new(metadata.Association)
+		asstCond := mapstr.MapStr{
+			common.AssociationKindIDField: common.AssociationKindMainline,
+			common.BKObjIDField:           objID,
+		}
+		if err := mongodb.Client().Table(common.BKTableNameObjAsst).Find(asstCond).One(kit.Ctx, asst); err != nil {
+			blog.Errorf("get parent of mainline object %s failed, err: %v, rid: %s", objID, err, kit.Rid)
+			return kit.CCError.CCError(common.CCErrorTopoMainlineObjectAssociationNotExist)
+		}
+
+		if err := m.validRestoreInstExists(kit, asst.AsstObjID, parentID, bizID); err != nil {
+			return kit.CCError.CCErrorf(common.CCErrCommParamsInvalid, common.BKParentIDField)
+		}
+	}
+
+	switch objID {
+	case common.BKInnerObjIDSet:
+		return m.validRestoreTemplate(kit, inst, bizID, common.BKSetTemplateIDField, common.BKTableNameSetTemplate)
+	case common.BKInnerObjIDModule:
+		return m.validRestoreTemplate(kit, inst, bizID, common.BKServiceTemplateIDField,
+			common.BKTableNameServiceTemplate)
+	}
+
+	return nil
+}
+
+// validRestoreTemplate check if the template of the set or module still exists in the business, the instance that is
+// not created by template has a zero template id.
+func (m *instanceManager) validRestoreTemplate(kit *rest.Kit, inst mapstr.MapStr, bizID int64, field,
+	table string) error {
+
+	templateID, err := inst.Int64(field)
+	if err != nil || templateID == 0 {
+		return nil
+	}
+
+	cond := mapstr.MapStr{common.BKFieldID: templateID, common.BKAppIDField: bizID}
+	cnt, err := mongodb.Client().Table(table).Find(cond).Count(kit.Ctx)
+	if err != nil {
+		blog.Errorf("count template %d in %s failed, err: %v, rid: %s", templateID, table, err, kit.Rid)
+		return kit.CCError.CCError(common.CCErrCommDBSelectFailed)
+	}
+
+	if cnt == 0 {
+		blog.Errorf("template %d in %s of biz %d is not exist, rid: %s", templateID, table, bizID, kit.Rid)
+		return kit.CCError.CCErrorf(common.CCErrCommParamsInvalid, field)
+	}
+	return nil
+}
+
+// validRestoreInstExists check if the instance exists, if bizID is set, it must be in the business too
+func (m *instanceManager) validRestoreInstExists(kit *rest.Kit, objID string, instID, bizID int64) error {
+	cond := mapstr.MapStr{common.GetInstIDField(objID): instID}
+	if bizID != 0 && objID != common.BKInnerObjIDApp {
+		cond[common.BKAppIDField] = bizID
+	}
+
+	cnt, err := m.countInstance(kit, objID, cond)
+	if err != nil {
+		blog.Errorf("count %s instance %d failed, err: %v, rid: %s", objID, instID, err, kit.Rid)
+		return err
+	}
+
+	if cnt == 0 {
+		blog.Errorf("%s instance %d in biz %d is not exist, rid: %s", objID, instID, bizID, kit.Rid)
+		return kit.CCError.CCErrorf(common.CCErrCommNotFound)
+	}
+	return nil
+}
+
+// saveRestoredInstance save the archived instance with its original id, the unique constraints are checked by db
+func (m *instanceManager) saveRestoredInstance(kit *rest.Kit, objID string, instID int64, inst mapstr.MapStr) error {
+	if objID == common.BKInnerObjIDHost {
+		var err error
+		inst, err = metadata.ConvertHostSpecialStringToArray(inst)
+		if err != nil {
+			return err
+		}
+	}
+
+	ts := time.Now()
+	inst.Set(common.LastTimeField, ts)
+	inst.Set(common.BKUpdatedBy, kit.User)
+	inst.Set(common.BKUpdatedAt, ts)
+
+	if metadata.IsCommon(objID) {
+		mapping := mapstr.MapStr{
+			common.GetInstIDField(objID): instID,
+			common.BKObjIDField:          objID,
+			common.BkSupplierAccount:     kit.SupplierAccount,
+		}
+
+		if err := instancemapping.Create(kit.Ctx, mapping); err != nil {
+			blog.Errorf("create %s instance %d mapping failed, err: %v, rid: %s", objID, instID, err, kit.Rid)
+			return err
+		}
+	}
+
+	tableName := common.GetInstTableName(objID, kit.SupplierAccount)
+	if err := mongodb.Client().Table(tableName).Insert(kit.Ctx, inst); err != nil {
+		blog.Errorf("save restored %s instance %d failed, err: %v, rid: %s", objID, instID, err, kit.Rid)
+		if mongodb.Client().IsDuplicatedError(err) {
+			return kit.CCError.CCErrorf(common.CCErrCommDuplicateItem, mongodb.GetDuplicateKey(err))
+		}
+		return kit.CCError.CCError(common.CCErrCommDBInsertFailed)
+	}
+
+	return nil
+}
+
+// restoreInstAssociations restore the associations that are deleted together with the instance, the association is
+// skipped if its model association or the other instance is deleted, or it conflicts with the association mapping.
+func (m *instanceManager) restoreInstAssociations(kit *rest.Kit, objID string, instID int64, archivedAt time.Time) (
+	[]metadata.InstAsst, error) {
+
+	filter := mapstr.MapStr{
+		"coll": common.GetObjectInstAsstTableName(objID, kit.SupplierAccount),
+		common.BKDBOR: []mapstr.MapStr{
+			{"detail." + common.BKObjIDField: objID, "detail." + common.BKInstIDField: instID},
+			{"detail." + common.BKAsstObjIDField: objID, "detail." + common.BKAsstInstIDField: instID},
+		},
+		"_id": mapstr.MapStr{
+			common.BKDBGTE: primitive.NewObjectIDFromTimestamp(archivedAt.Add(-restoreArchiveWindow)),
+			common.BKDBLTE: primitive.NewObjectIDFromTimestamp(archivedAt.Add(time.Second)),
+		},
+	}
+
+	archives := make([]instAsstArchive, 0)
+	if err := mongodb.Client().Table(common.BKTableNameDelArchive).Find(filter).All(kit.Ctx, &archives); err != nil {
+		blog.Errorf("get archived associations of %s instance %d failed, err: %v, rid: %s", objID, instID, err,
+			kit.Rid)
+		return nil, kit.CCError.CCError(common.CCErrCommDBSelectFailed)
+	}
+
+	restored := make([]metadata.InstAsst, 0)
+	restoredIDs := make(map[int64]struct{})
+	for _, archive := range archives {
+		asst := archive.Detail
+		if _, exists := restoredIDs[asst.ID]; exists {
+			continue
+		}
+		restoredIDs[asst.ID] = struct{}{}
+
+		valid, err := m.isRestoredAsstValid(kit, asst)
+		if err != nil {
+			return nil, err
+		}
+
+		if !valid {
+			blog.Infof("skip restoring invalid association %d of %s instance %d, rid: %s", asst.ID, objID, instID,
+				kit.Rid)
+			continue
+		}
+
+		asstTableName := common.GetObjectInstAsstTableName(asst.ObjectID, kit.SupplierAccount)
+		if err := mongodb.Client().Table(asstTableName).Insert(kit.Ctx, asst); err != nil {
+			blog.Errorf("restore association %+v failed, err: %v, rid: %s", asst, err, kit.Rid)
+			return nil, kit.CCError.CCError(common.CCErrCommDBInsertFailed)
+		}
+
+		// do not insert twice for self related association
+		if asst.ObjectID != asst.AsstObjectID {
+			asstTableName = common.GetObjectInstAsstTableName(asst.AsstObjectID, kit.SupplierAccount)
+			if err := mongodb.Client().Table(asstTableName).Insert(kit.Ctx, asst); err != nil {
+				blog.Errorf("restore association %+v failed, err: %v, rid: %s", asst, err, kit.Rid)
+				return nil, kit.CCError.CCError(common.CCErrCommDBInsertFailed)
+			}
+		}
+
+		restored = append(restored, asst)
+	}
+
+	return restored, nil
+}
+
+// isRestoredAsstValid check if the archived association can still be restored
+func (m *instanceManager) isRestoredAsstValid(kit *rest.Kit, asst metadata.InstAsst) (bool, error) {
+	asstTableName := common.GetObjectInstAsstTableName(asst.ObjectID, kit.SupplierAccount)
+	cnt, err := mongodb.Client().Table(asstTableName).Find(mapstr.MapStr{common.BKFieldID: asst.ID}).Count(kit.Ctx)
+	if err != nil {
+		blog.Errorf("count association %d failed, err: %v, rid: %s", asst.ID, err, kit.Rid)
+		return false, kit.CCError.CCError(common.CCErrCommDBSelectFailed)
+	}
+
+	if cnt > 0 {
+		return false, nil
+	}
+
+	modelAsst := new(metadata.Association)
+	modelAsstCond := mapstr.MapStr{common.AssociationObjAsstIDField: asst.ObjectAsstID}
+	modelAsstCond = util.SetQueryOwner(modelAsstCond, kit.SupplierAccount)
+	err = mongodb.Client().Table(common.BKTableNameObjAsst).Find(modelAsstCond).One(kit.Ctx, modelAsst)
+	if err != nil {
+		if mongodb.Client().IsNotFoundError(err) {
+			return false, nil
+		}
+		blog.Errorf("get model association %s failed, err: %v, rid: %s", asst.ObjectAsstID, err, kit.Rid)
+		return false, kit.CCError.CCError(common.CCErrCommDBSelectFailed)
+	}
+
+	// both of the instances must exist, one of them is the restored instance
+	srcCnt, err := m.countInstance(kit, asst.ObjectID, mapstr.MapStr{common.GetInstIDField(asst.ObjectID): asst.InstID})
+	if err != nil {
+		return false, err
+	}
+
+	dstCnt, err := m.countInstance(kit, asst.AsstObjectID,
+		mapstr.MapStr{common.GetInstIDField(asst.AsstObjectID): asst.AsstInstID})
+	if err != nil {
+		return false, err
+	}
+
+	if srcCnt == 0 || dstCnt == 0 {
+		return false, nil
+	}
+
+	// check if the association conflicts with the instance associations that are created after the deletion
+	for _, cond := range getRestoredAsstConflictConds(asst, modelAsst.Mapping) {
+		cond = util.SetQueryOwner(cond, kit.SupplierAccount)
+		cnt, err := mongodb.Client().Table(asstTableName).Find(cond).Count(kit.Ctx)
+		if err != nil {
+			blog.Errorf("count association failed, err: %v, cond: %#v, rid: %s", err, cond, kit.Rid)
+			return false, kit.CCError.CCError(common.CCErrCommDBSelectFailed)
+		}
+
+		if cnt > 0 {
+			return false, nil
+		}
+	}
+
+	return true, nil
+}
+
+// getRestoredAsstConflictConds returns the conditions of the instance associations that conflict with the restored
+// association by the mapping, the source instance of a 1:1 association can only be associated with one destination
+// instance and vice versa, the destination instance of a 1:n association can only be associated with one source
+// instance, and n:n associations never conflict.
+func getRestoredAsstConflictConds(asst metadata.InstAsst, mapping metadata.AssociationMapping) []mapstr.MapStr {
+	conflictConds := make([]mapstr.MapStr, 0)
+	switch mapping {
+	case metadata.OneToOneMapping:
+		conflictConds = append(conflictConds, mapstr.MapStr{common.BKInstIDField: asst.InstID},
+			mapstr.MapStr{common.BKAsstInstIDField: asst.AsstInstID})
+	case metadata.OneToManyMapping:
+		conflictConds = append(conflictConds, mapstr.MapStr{common.BKAsstInstIDField: asst.AsstInstID})
+	}
+
+	for _, cond := range conflictConds {
+		cond[common.AssociationObjAsstIDField] = asst.ObjectAsstID
+	}
+	return conflictConds
+}
+
+// restoreHostRelations restore the host into the resource pool idle module, the host is not restored into the
+// business modules it belongs to before, because restoring a host is authorized as creating a resource pool host,
+// it can be transferred to the business by the host transfer apis that are authorized by the business.
+func (m *instanceManager) restoreHostRelations(kit *rest.Kit, hostID int64) ([]metadata.ModuleHost, error) {
+	relation, err := m.getResPoolIdleModuleRelation(kit, hostID)
+	if err != nil {
+		return nil, err
+	}
+	relation.OwnerID = kit.SupplierAccount
+
+	if err := mongodb.Client().Table(common.BKTableNameModuleHostConfig).Insert(kit.Ctx, relation); err != nil {
+		blog.Errorf("restore host %d relation %+v failed, err: %v, rid: %s", hostID, relation, err, kit.Rid)
+		return nil, kit.CCError.CCError(common.CCErrCommDBInsertFailed)
+	}
+
+	return []metadata.ModuleHost{*relation}, nil
+}
+
+// getResPoolIdleModuleRelation get the relation between the host and the resource pool idle module
+func (m *instanceManager) getResPoolIdleModuleRelation(kit *rest.Kit, hostID int64) (*metadata.ModuleHost, error) {
+	bizCond := mapstr.MapStr{common.BKDefaultField: common.DefaultAppFlag}
+	bizCond = util.SetQueryOwner(bizCond, kit.SupplierAccount)
+	biz := new(metadata.BizInst)
+	err := mongodb.Client().Table(common.BKTableNameBaseApp).Find(bizCond).Fields(common.BKAppIDField).
+		One(kit.Ctx, biz)
+	if err != nil {
+		blog.Errorf("get resource pool biz failed, err: %v, rid: %s", err, kit.Rid)
+		return nil, kit.CCError.CCError(common.CCErrCommDBSelectFailed)
+	}
+
+	moduleCond := mapstr.MapStr{
+		common.BKAppIDField:   biz.BizID,
+		common.BKDefaultField: common.DefaultResModuleFlag,
+	}
+	relation := new(metadata.ModuleHost)
+	err = mongodb.Client().Table(common.BKTableNameBaseModule).Find(moduleCond).
+		Fields(common.BKAppIDField, common.BKSetIDField, common.BKModuleIDField).One(kit.Ctx, relation)
+	if err != nil {
+		blog.Errorf("get resource pool idle module failed, err: %v, rid: %s", err, kit.Rid)
+		return nil, kit.CCError.CCErrorf(common.CCErrHostGetModuleFail, err.Error())
+	}
+
+	relation.HostID = hostID
+	return relation, nil
+}
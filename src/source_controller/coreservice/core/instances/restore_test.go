@@ -0,0 +1,50 @@
+/*
+ * Tencent is pleased to support the open source community by making 蓝鲸 available.
+ * Copyright (C) 2017-2018 THL A29 Limited, a Tencent company. All rights reserved.
+ * Licensed under the MIT License (the "License"); you may not use this file except
+ * in compliance with the License. You may obtain a copy of the License at
+ * http://opensource.org/licenses/MIT
+ * Unless required by applicable law or agreed to in writing, software distributed under
+ * the License is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND,
+ * either express or implied. See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package instances
+
+import (
+	"reflect"
+	"testing"
+
+	"configcenter/src/common"
+	"configcenter/src/common/mapstr"
+	"configcenter/src/common/metadata"
+)
+
+func TestGetRestoredAsstConflictConds(t *testing.T) {
+	asst := metadata.InstAsst{ID: 1, ObjectID: "switch", InstID: 2, AsstObjectID: "router", AsstInstID: 3,
+		ObjectAsstID: "switch_connect_router"}
+
+	srcCond := mapstr.MapStr{common.BKInstIDField: int64(2), common.AssociationObjAsstIDField: "switch_connect_router"}
+	destCond := mapstr.MapStr{common.BKAsstInstIDField: int64(3),
+		common.AssociationObjAsstIDField: "switch_connect_router"}
+
+	tests := []struct {
+		name    string
+		mapping metadata.AssociationMapping
+		want    []mapstr.MapStr
+	}{
+		{"one to one conflicts with source and destination", metadata.OneToOneMapping,
+			[]mapstr.MapStr{srcCond, destCond}},
+		{"one to many conflicts with destination", metadata.OneToManyMapping, []mapstr.MapStr{destCond}},
+		{"many to many never conflicts", metadata.ManyToManyMapping, []mapstr.MapStr{}},
+		{"unknown mapping never conflicts", "", []mapstr.MapStr{}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := getRestoredAsstConflictConds(asst, tt.mapping); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("getRestoredAsstConflictConds() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
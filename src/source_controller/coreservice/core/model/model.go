@@ -678,9 +678,7 @@ func dealProcessRunningTasks(kit *rest.Kit, ids []int64, objectID int64, isStop
 		},
 		metadata.APITaskExtraField: objectID,
 		common.BKStatusField: mapstr.MapStr{
-			common.BKDBIN: []metadata.APITaskStatus{
-				metadata.APITaskStatusExecute, metadata.APITaskStatusWaitExecute,
-				metadata.APITaskStatusNew},
+			common.BKDBIN: metadata.APITaskUnfinishedStatus,
 		},
 	}
 	cond = util.SetQueryOwner(cond, kit.SupplierAccount)
@@ -735,7 +733,8 @@ func dealProcessRunningTasks(kit *rest.Kit, ids []int64, objectID int64, isStop
 	delCond := mapstr.MapStr{
 		common.BKTaskIDField: taskID,
 		common.BKStatusField: mapstr.MapStr{
-			common.BKDBIN: []metadata.APITaskStatus{metadata.APITaskStatusWaitExecute, metadata.APITaskStatusNew},
+			common.BKDBIN: []metadata.APITaskStatus{metadata.APITaskStatusWaitExecute, metadata.APITaskStatusNew,
+				metadata.APITaskStatusPaused},
 		},
 	}
 	err := mongodb.Client().Table(common.BKTableNameAPITask).Delete(kit.Ctx, delCond)
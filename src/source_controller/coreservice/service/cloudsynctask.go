@@ -142,3 +142,70 @@ func (s *coreService) DeleteDestroyedHostRelated(ctx *rest.Contexts) {
 	}
 	ctx.RespEntity(nil)
 }
+
+// CreateSyncPreview create cloud sync preview, the pending preview of the task will be replaced
+func (s *coreService) CreateSyncPreview(ctx *rest.Contexts) {
+	preview := metadata.CloudSyncPreview{}
+	if err := ctx.DecodeInto(&preview); err != nil {
+		ctx.RespAutoError(err)
+		return
+	}
+
+	result, err := s.core.CloudOperation().CreateSyncPreview(ctx.Kit, &preview)
+	if err != nil {
+		ctx.RespAutoError(err)
+		return
+	}
+	ctx.RespEntity(result)
+}
+
+// SearchSyncPreview search cloud sync previews of a task
+func (s *coreService) SearchSyncPreview(ctx *rest.Contexts) {
+	option := metadata.SearchSyncPreviewOption{}
+	if err := ctx.DecodeInto(&option); err != nil {
+		ctx.RespAutoError(err)
+		return
+	}
+
+	if rawErr := option.Validate(); rawErr.ErrCode != 0 {
+		ctx.RespAutoError(rawErr.ToCCError(ctx.Kit.CCError))
+		return
+	}
+
+	result, err := s.core.CloudOperation().SearchSyncPreview(ctx.Kit, &option)
+	if err != nil {
+		ctx.RespAutoError(err)
+		return
+	}
+	ctx.RespEntity(result)
+}
+
+// ApplySyncPreview set the pending cloud sync preview to applied status
+func (s *coreService) ApplySyncPreview(ctx *rest.Contexts) {
+	previewID, err := strconv.ParseInt(ctx.Request.PathParameter(common.BKCloudSyncPreviewID), 10, 64)
+	if err != nil || previewID <= 0 {
+		ctx.RespAutoError(ctx.Kit.CCError.CCErrorf(common.CCErrCommParamsInvalid, common.BKCloudSyncPreviewID))
+		return
+	}
+
+	if err := s.core.CloudOperation().ApplySyncPreview(ctx.Kit, previewID); err != nil {
+		ctx.RespAutoError(err)
+		return
+	}
+	ctx.RespEntity(nil)
+}
+
+// DeletePendingSyncPreview delete the pending cloud sync preview of a task
+func (s *coreService) DeletePendingSyncPreview(ctx *rest.Contexts) {
+	taskID, err := strconv.ParseInt(ctx.Request.PathParameter(common.BKCloudSyncTaskID), 10, 64)
+	if err != nil || taskID <= 0 {
+		ctx.RespAutoError(ctx.Kit.CCError.CCErrorf(common.CCErrCommParamsInvalid, common.BKCloudSyncTaskID))
+		return
+	}
+
+	if err := s.core.CloudOperation().DeletePendingSyncPreview(ctx.Kit, taskID); err != nil {
+		ctx.RespAutoError(err)
+		return
+	}
+	ctx.RespEntity(nil)
+}
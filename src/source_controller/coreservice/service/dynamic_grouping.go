@@ -101,9 +101,27 @@ func (s *coreService) UpdateDynamicGroup(ctx *rest.Contexts) {
 		ctx.RespAutoError(ctx.Kit.CCError.CCError(common.CCErrCommDBUpdateFailed))
 		return
 	}
+
+	// clear the materialized members of the dynamic group when its members are no longer tracked.
+	if trackMember, exists := data["track_member"]; exists && trackMember == false {
+		if err := s.deleteDynamicGroupMembers(ctx.Kit, bizIDUint64, targetID); err != nil {
+			ctx.RespAutoError(err)
+			return
+		}
+	}
 	ctx.RespEntity(nil)
 }
 
+// deleteDynamicGroupMembers deletes the materialized members of the target dynamic group.
+func (s *coreService) deleteDynamicGroupMembers(kit *rest.Kit, bizID int64, targetID string) error {
+	filter := common.KvMap{common.BKFieldID: targetID, common.BKAppIDField: bizID}
+	if err := mongodb.Client().Table(common.BKTableNameDynamicGroupMember).Delete(kit.Ctx, filter); err != nil {
+		blog.Errorf("delete dynamic group members failed, err: %v, filter: %v, rid: %s", err, filter, kit.Rid)
+		return kit.CCError.CCError(common.CCErrCommDBDeleteFailed)
+	}
+	return nil
+}
+
 // DeleteDynamicGroup deletes target dynamic group.
 func (s *coreService) DeleteDynamicGroup(ctx *rest.Contexts) {
 	req := ctx.Request
@@ -140,6 +158,11 @@ func (s *coreService) DeleteDynamicGroup(ctx *rest.Contexts) {
 		ctx.RespAutoError(ctx.Kit.CCError.CCError(common.CCErrCommDBDeleteFailed))
 		return
 	}
+
+	if err := s.deleteDynamicGroupMembers(ctx.Kit, bizIDUint64, targetID); err != nil {
+		ctx.RespAutoError(err)
+		return
+	}
 	ctx.RespEntity(nil)
 }
 
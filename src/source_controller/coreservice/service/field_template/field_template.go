@@ -218,9 +218,7 @@ func (s *service) dealProcessRunningTasks(kit *rest.Kit, option *metadata.FieldT
 		common.BKInstIDField:       option.ID,
 		metadata.APITaskExtraField: option.ObjectID,
 		common.BKStatusField: mapstr.MapStr{
-			common.BKDBIN: []metadata.APITaskStatus{
-				metadata.APITaskStatusExecute, metadata.APITaskStatusWaitExecute,
-				metadata.APITaskStatusNew},
+			common.BKDBIN: metadata.APITaskUnfinishedStatus,
 		},
 	}
 	cond = util.SetQueryOwner(cond, kit.SupplierAccount)
@@ -260,7 +258,8 @@ func (s *service) dealProcessRunningTasks(kit *rest.Kit, option *metadata.FieldT
 	delCond := mapstr.MapStr{
 		common.BKTaskIDField: taskID,
 		common.BKStatusField: mapstr.MapStr{
-			common.BKDBIN: []metadata.APITaskStatus{metadata.APITaskStatusWaitExecute, metadata.APITaskStatusNew},
+			common.BKDBIN: []metadata.APITaskStatus{metadata.APITaskStatusWaitExecute, metadata.APITaskStatusNew,
+				metadata.APITaskStatusPaused},
 		},
 	}
 	err := mongodb.Client().Table(common.BKTableNameAPITask).Delete(kit.Ctx, delCond)
@@ -329,9 +328,7 @@ func dealProcessRunningTasks(kit *rest.Kit, ids []int64, objectID int64) error {
 		},
 		metadata.APITaskExtraField: objectID,
 		common.BKStatusField: mapstr.MapStr{
-			common.BKDBIN: []metadata.APITaskStatus{
-				metadata.APITaskStatusExecute, metadata.APITaskStatusWaitExecute,
-				metadata.APITaskStatusNew},
+			common.BKDBIN: metadata.APITaskUnfinishedStatus,
 		},
 	}
 	cond = util.SetQueryOwner(cond, kit.SupplierAccount)
@@ -372,7 +369,8 @@ func dealProcessRunningTasks(kit *rest.Kit, ids []int64, objectID int64) error {
 	delCond := mapstr.MapStr{
 		common.BKTaskIDField: taskID,
 		common.BKStatusField: mapstr.MapStr{
-			common.BKDBIN: []metadata.APITaskStatus{metadata.APITaskStatusWaitExecute, metadata.APITaskStatusNew},
+			common.BKDBIN: []metadata.APITaskStatus{metadata.APITaskStatusWaitExecute, metadata.APITaskStatusNew,
+				metadata.APITaskStatusPaused},
 		},
 	}
 	err := mongodb.Client().Table(common.BKTableNameAPITask).Delete(kit.Ctx, delCond)
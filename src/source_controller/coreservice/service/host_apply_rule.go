@@ -379,3 +379,134 @@ func (s *coreService) SearchRuleRelatedServiceTemplates(ctx *rest.Contexts) {
 	}
 	ctx.RespEntity(serviceTemplates)
 }
+
+// ReconcileHostApply reconcile the hosts with the host apply rules of their modules
+func (s *coreService) ReconcileHostApply(ctx *rest.Contexts) {
+	bizID, err := strconv.ParseInt(ctx.Request.PathParameter(common.BKAppIDField), 10, 64)
+	if err != nil {
+		ctx.RespAutoError(ctx.Kit.CCError.CCErrorf(common.CCErrCommParamsInvalid, common.BKAppIDField))
+		return
+	}
+
+	option := metadata.ReconcileHostApplyOption{}
+	if err := ctx.DecodeInto(&option); err != nil {
+		ctx.RespAutoError(err)
+		return
+	}
+
+	if rawErr := option.Validate(); rawErr.ErrCode != 0 {
+		ctx.RespAutoError(rawErr.ToCCError(ctx.Kit.CCError))
+		return
+	}
+
+	result, ccErr := s.core.HostApplyRuleOperation().ReconcileHostApply(ctx.Kit, bizID, option)
+	if ccErr != nil {
+		blog.Errorf("reconcile host apply failed, bizID: %d, option: %+v, err: %v, rid: %s", bizID, option, ccErr,
+			ctx.Kit.Rid)
+		ctx.RespAutoError(ccErr)
+		return
+	}
+	ctx.RespEntity(result)
+}
+
+// DeleteHostApplyDrift delete the host apply drifts of the hosts
+func (s *coreService) DeleteHostApplyDrift(ctx *rest.Contexts) {
+	option := metadata.DeleteHostApplyDriftOption{}
+	if err := ctx.DecodeInto(&option); err != nil {
+		ctx.RespAutoError(err)
+		return
+	}
+
+	if rawErr := option.Validate(); rawErr.ErrCode != 0 {
+		ctx.RespAutoError(rawErr.ToCCError(ctx.Kit.CCError))
+		return
+	}
+
+	if err := s.core.HostApplyRuleOperation().DeleteHostApplyDrift(ctx.Kit, option); err != nil {
+		blog.Errorf("delete host apply drift failed, option: %+v, err: %v, rid: %s", option, err, ctx.Kit.Rid)
+		ctx.RespAutoError(err)
+		return
+	}
+	ctx.RespEntity(nil)
+}
+
+// ListHostApplyDrift list the host apply drifts in the business
+func (s *coreService) ListHostApplyDrift(ctx *rest.Contexts) {
+	bizID, err := strconv.ParseInt(ctx.Request.PathParameter(common.BKAppIDField), 10, 64)
+	if err != nil {
+		ctx.RespAutoError(ctx.Kit.CCError.CCErrorf(common.CCErrCommParamsInvalid, common.BKAppIDField))
+		return
+	}
+
+	option := metadata.ListHostApplyDriftOption{}
+	if err := ctx.DecodeInto(&option); err != nil {
+		ctx.RespAutoError(err)
+		return
+	}
+
+	if rawErr := option.Validate(); rawErr.ErrCode != 0 {
+		ctx.RespAutoError(rawErr.ToCCError(ctx.Kit.CCError))
+		return
+	}
+
+	result, ccErr := s.core.HostApplyRuleOperation().ListHostApplyDrift(ctx.Kit, bizID, option)
+	if ccErr != nil {
+		blog.Errorf("list host apply drift failed, bizID: %d, option: %+v, err: %v, rid: %s", bizID, option, ccErr,
+			ctx.Kit.Rid)
+		ctx.RespAutoError(ccErr)
+		return
+	}
+	ctx.RespEntity(result)
+}
+
+// ListHostApplyReconcileConfig list the host apply reconcile configs of the businesses
+func (s *coreService) ListHostApplyReconcileConfig(ctx *rest.Contexts) {
+	option := metadata.ListHostApplyReconcileConfigOption{}
+	if err := ctx.DecodeInto(&option); err != nil {
+		ctx.RespAutoError(err)
+		return
+	}
+
+	if rawErr := option.Validate(); rawErr.ErrCode != 0 {
+		ctx.RespAutoError(rawErr.ToCCError(ctx.Kit.CCError))
+		return
+	}
+
+	result, err := s.core.HostApplyRuleOperation().ListHostApplyReconcileConfig(ctx.Kit, option)
+	if err != nil {
+		blog.Errorf("list host apply reconcile config failed, option: %+v, err: %v, rid: %s", option, err,
+			ctx.Kit.Rid)
+		ctx.RespAutoError(err)
+		return
+	}
+	ctx.RespEntity(result)
+}
+
+// UpdateHostApplyReconcileConfig update the host apply reconcile config of the business
+func (s *coreService) UpdateHostApplyReconcileConfig(ctx *rest.Contexts) {
+	bizID, err := strconv.ParseInt(ctx.Request.PathParameter(common.BKAppIDField), 10, 64)
+	if err != nil {
+		ctx.RespAutoError(ctx.Kit.CCError.CCErrorf(common.CCErrCommParamsInvalid, common.BKAppIDField))
+		return
+	}
+
+	option := metadata.UpdateHostApplyReconcileConfigOption{}
+	if err := ctx.DecodeInto(&option); err != nil {
+		ctx.RespAutoError(err)
+		return
+	}
+
+	if rawErr := option.Validate(); rawErr.ErrCode != 0 {
+		ctx.RespAutoError(rawErr.ToCCError(ctx.Kit.CCError))
+		return
+	}
+
+	ccErr := s.core.HostApplyRuleOperation().UpdateHostApplyReconcileConfig(ctx.Kit, bizID, option)
+	if ccErr != nil {
+		blog.Errorf("update host apply reconcile config failed, bizID: %d, option: %+v, err: %v, rid: %s", bizID,
+			option, ccErr, ctx.Kit.Rid)
+		ctx.RespAutoError(ccErr)
+		return
+	}
+	ctx.RespEntity(nil)
+}
@@ -136,6 +136,24 @@ func (s *coreService) CascadeDeleteModelInstances(ctx *rest.Contexts) {
 	ctx.RespEntityWithError(s.core.InstanceOperation().CascadeDeleteModelInstance(ctx.Kit, ctx.Request.PathParameter("bk_obj_id"), inputData))
 }
 
+// RestoreModelInstances restore deleted model instances from the delete archive
+func (s *coreService) RestoreModelInstances(ctx *rest.Contexts) {
+	input := new(metadata.RestoreInstOption)
+	if err := ctx.DecodeInto(input); err != nil {
+		ctx.RespAutoError(err)
+		return
+	}
+
+	result, err := s.core.InstanceOperation().RestoreModelInstance(ctx.Kit,
+		ctx.Request.PathParameter(common.BKObjIDField), input)
+	if err != nil {
+		ctx.RespAutoError(err)
+		return
+	}
+
+	ctx.RespEntity(result)
+}
+
 // GetInstanceObjectMapping TODO
 func (s *coreService) GetInstanceObjectMapping(ctx *rest.Contexts) {
 	inputData := metadata.GetInstanceObjectMappingsOption{}
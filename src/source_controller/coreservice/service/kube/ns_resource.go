@@ -0,0 +1,225 @@
+/*
+ * Tencent is pleased to support the open source community by making
+ * 蓝鲸智云 - 配置平台 (BlueKing - Configuration System) available.
+ * Copyright (C) 2017 THL A29 Limited,
+ * a Tencent company. All rights reserved.
+ * Licensed under the MIT License (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at http://opensource.org/licenses/MIT
+ * Unless required by applicable law or agreed to in writing,
+ * software distributed under the License is distributed on
+ * an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND,
+ * either express or implied. See the License for the
+ * specific language governing permissions and limitations under the License.
+ * We undertake not to change the open source license (MIT license) applicable
+ * to the current version of the project delivered to anyone in the future.
+ */
+
+package kube
+
+import (
+	"encoding/json"
+	"time"
+
+	"configcenter/src/common"
+	"configcenter/src/common/blog"
+	"configcenter/src/common/http/rest"
+	"configcenter/src/common/mapstr"
+	"configcenter/src/common/metadata"
+	"configcenter/src/common/util"
+	"configcenter/src/kube/types"
+	"configcenter/src/storage/dal/table"
+	"configcenter/src/storage/driver/mongodb"
+)
+
+// CreateNsResource create namespace scoped resources, including service, ingress, endpoints and configMap
+func (s *service) CreateNsResource(ctx *rest.Contexts) {
+	kind := types.NsResourceType(ctx.Request.PathParameter(types.KindField))
+	tableName, err := kind.Table()
+	if err != nil {
+		ctx.RespAutoError(ctx.Kit.CCError.CCErrorf(common.CCErrCommParamsInvalid, types.KindField))
+		return
+	}
+
+	rawReq := json.RawMessage{}
+	if err = ctx.DecodeInto(&rawReq); err != nil {
+		ctx.RespAutoError(err)
+		return
+	}
+
+	resources, err := types.NsResArrayUnmarshalJSON(kind, rawReq)
+	if err != nil {
+		ctx.RespAutoError(err)
+		return
+	}
+
+	for _, res := range resources {
+		if rawErr := res.ValidateCreate(); rawErr.ErrCode != 0 {
+			blog.Errorf("%s %+v is invalid, err: %v, rid: %s", kind, res, rawErr, ctx.Kit.Rid)
+			ctx.RespAutoError(rawErr.ToCCError(ctx.Kit.CCError))
+			return
+		}
+	}
+
+	ids, err := mongodb.Client().NextSequences(ctx.Kit.Ctx, tableName, len(resources))
+	if err != nil {
+		blog.Errorf("get %s ids failed, table: %s, err: %v, rid: %s", kind, tableName, err, ctx.Kit.Rid)
+		ctx.RespAutoError(ctx.Kit.CCError.CCError(common.CCErrCommDBSelectFailed))
+		return
+	}
+
+	nsIDs := make([]int64, 0)
+	for _, data := range resources {
+		nsIDs = append(nsIDs, data.GetNsResourceBase().NamespaceID)
+	}
+	nsSpecs, err := s.GetNamespaceSpec(ctx.Kit, nsIDs)
+	if err != nil {
+		blog.Errorf("get namespace spec message failed, namespaceIDs: %v, err: %v, rid: %s", nsIDs, err, ctx.Kit.Rid)
+		ctx.RespAutoError(err)
+		return
+	}
+
+	respData := metadata.RspIDs{IDs: make([]int64, len(ids))}
+	mismatchNsMap := make(map[int64][]int64)
+	now := time.Now().Unix()
+
+	for idx, data := range resources {
+		base := data.GetNsResourceBase()
+
+		if base.BizID != nsSpecs[base.NamespaceID].BizID {
+			mismatchNsMap[base.BizID] = append(mismatchNsMap[base.BizID], base.NamespaceID)
+		}
+
+		base.NamespaceSpec = nsSpecs[base.NamespaceID]
+		base.ID = int64(ids[idx])
+		respData.IDs[idx] = base.ID
+		base.Revision = table.Revision{
+			Creator:    ctx.Kit.User,
+			Modifier:   ctx.Kit.User,
+			CreateTime: now,
+			LastTime:   now,
+		}
+		base.SupplierAccount = ctx.Kit.SupplierAccount
+		data.SetNsResourceBase(base)
+	}
+
+	// checks if resource's namespace is a shared namespace and if its biz id is not the same with the input biz id
+	if err = s.core.KubeOperation().CheckPlatBizSharedNs(ctx.Kit, mismatchNsMap); err != nil {
+		ctx.RespAutoError(err)
+		return
+	}
+
+	if err = mongodb.Client().Table(tableName).Insert(ctx.Kit.Ctx, resources); err != nil {
+		blog.Errorf("add %s failed, data: %v, err: %v, rid: %s", kind, resources, err, ctx.Kit.Rid)
+		ctx.RespAutoError(ctx.Kit.CCError.CCError(common.CCErrCommDBInsertFailed))
+		return
+	}
+
+	ctx.RespEntity(respData)
+}
+
+// UpdateNsResource update namespace scoped resources
+func (s *service) UpdateNsResource(ctx *rest.Contexts) {
+	kind := types.NsResourceType(ctx.Request.PathParameter(types.KindField))
+	tableName, err := kind.Table()
+	if err != nil {
+		ctx.RespAutoError(ctx.Kit.CCError.CCErrorf(common.CCErrCommParamsInvalid, types.KindField))
+		return
+	}
+
+	req := types.NsResUpdateByIDsOption{Kind: kind}
+	if err := ctx.DecodeInto(&req); err != nil {
+		ctx.RespAutoError(err)
+		return
+	}
+
+	if rawErr := req.Validate(); rawErr.ErrCode != 0 {
+		ctx.RespAutoError(rawErr.ToCCError(ctx.Kit.CCError))
+		return
+	}
+
+	cond := map[string]interface{}{
+		common.BKFieldID: mapstr.MapStr{common.BKDBIN: req.IDs},
+	}
+	util.SetModOwner(cond, ctx.Kit.SupplierAccount)
+	updateData, err := req.Data.BuildUpdateData(ctx.Kit.User)
+	if err != nil {
+		blog.Errorf("get update data failed, kind: %s, info: %v, err: %v, rid: %s", kind, req.Data, err, ctx.Kit.Rid)
+		ctx.RespAutoError(ctx.Kit.CCError.CCError(common.CCErrCommDBUpdateFailed))
+		return
+	}
+
+	err = mongodb.Client().Table(tableName).Update(ctx.Kit.Ctx, cond, updateData)
+	if err != nil {
+		blog.Errorf("update %s failed, filter: %v, updateData: %v, err: %v, rid: %s", kind, cond, updateData, err,
+			ctx.Kit.Rid)
+		ctx.RespAutoError(ctx.Kit.CCError.CCError(common.CCErrCommDBUpdateFailed))
+		return
+	}
+
+	ctx.RespEntity(nil)
+}
+
+// DeleteNsResource delete namespace scoped resources
+func (s *service) DeleteNsResource(ctx *rest.Contexts) {
+	kind := types.NsResourceType(ctx.Request.PathParameter(types.KindField))
+	tableName, err := kind.Table()
+	if err != nil {
+		ctx.RespAutoError(ctx.Kit.CCError.CCErrorf(common.CCErrCommParamsInvalid, types.KindField))
+		return
+	}
+
+	req := new(types.NsResDeleteByIDsOption)
+	if err := ctx.DecodeInto(req); err != nil {
+		ctx.RespAutoError(err)
+		return
+	}
+
+	if rawErr := req.Validate(); rawErr.ErrCode != 0 {
+		ctx.RespAutoError(rawErr.ToCCError(ctx.Kit.CCError))
+		return
+	}
+
+	filter := mapstr.MapStr{
+		common.BKFieldID: mapstr.MapStr{common.BKDBIN: req.IDs},
+	}
+	util.SetModOwner(filter, ctx.Kit.SupplierAccount)
+	if err := mongodb.Client().Table(tableName).Delete(ctx.Kit.Ctx, filter); err != nil {
+		blog.Errorf("delete %s failed, filter: %v, err: %v, rid: %s", kind, filter, err, ctx.Kit.Rid)
+		ctx.RespAutoError(ctx.Kit.CCError.CCError(common.CCErrCommDBDeleteFailed))
+		return
+	}
+
+	ctx.RespEntity(nil)
+}
+
+// ListNsResource list namespace scoped resources
+func (s *service) ListNsResource(ctx *rest.Contexts) {
+	input := new(metadata.QueryCondition)
+	if err := ctx.DecodeInto(input); err != nil {
+		ctx.RespAutoError(err)
+		return
+	}
+
+	kind := types.NsResourceType(ctx.Request.PathParameter(types.KindField))
+	tableName, err := kind.Table()
+	if err != nil {
+		blog.Errorf("namespace resource kind is invalid, kind: %v, rid: %s", kind, ctx.Kit.Rid)
+		ctx.RespAutoError(ctx.Kit.CCError.CCErrorf(common.CCErrCommParamsInvalid, types.KindField))
+		return
+	}
+
+	util.SetQueryOwner(input.Condition, ctx.Kit.SupplierAccount)
+	resources := make([]mapstr.MapStr, 0)
+	err = mongodb.Client().Table(tableName).Find(input.Condition).Start(uint64(input.Page.Start)).
+		Limit(uint64(input.Page.Limit)).
+		Sort(input.Page.Sort).
+		Fields(input.Fields...).All(ctx.Kit.Ctx, &resources)
+	if err != nil {
+		blog.Errorf("search %s failed, cond: %v, err: %v, rid: %s", kind, input, err, ctx.Kit.Rid)
+		ctx.RespAutoError(ctx.Kit.CCError.CCError(common.CCErrCommDBSelectFailed))
+		return
+	}
+
+	ctx.RespEntity(&metadata.QueryResult{Info: resources})
+}
@@ -46,5 +46,18 @@ func (s *coreService) initHostApplyRule(web *restful.WebService) {
 	utility.AddHandler(rest.Action{Verb: http.MethodPost, Path: "/findmany/service_templates/host_apply_rule_related",
 		Handler: s.SearchRuleRelatedServiceTemplates})
 
+	utility.AddHandler(rest.Action{Verb: http.MethodPut,
+		Path:    "/updatemany/host/bk_biz_id/{bk_biz_id}/reconcile_host_apply",
+		Handler: s.ReconcileHostApply})
+	utility.AddHandler(rest.Action{Verb: http.MethodDelete, Path: "/deletemany/host_apply_drift",
+		Handler: s.DeleteHostApplyDrift})
+	utility.AddHandler(rest.Action{Verb: http.MethodPost, Path: "/findmany/host_apply_drift/bk_biz_id/{bk_biz_id}",
+		Handler: s.ListHostApplyDrift})
+	utility.AddHandler(rest.Action{Verb: http.MethodPost, Path: "/findmany/host_apply_reconcile_config",
+		Handler: s.ListHostApplyReconcileConfig})
+	utility.AddHandler(rest.Action{Verb: http.MethodPut,
+		Path:    "/update/host_apply_reconcile_config/bk_biz_id/{bk_biz_id}",
+		Handler: s.UpdateHostApplyReconcileConfig})
+
 	utility.AddToRestfulWebService(web)
 }
@@ -161,6 +161,8 @@ func (s *coreService) initModelInstances(web *restful.WebService) {
 		Handler: s.DeleteModelInstances})
 	utility.AddHandler(rest.Action{Verb: http.MethodDelete, Path: "/delete/model/{bk_obj_id}/instance/cascade",
 		Handler: s.CascadeDeleteModelInstances})
+	utility.AddHandler(rest.Action{Verb: http.MethodPost, Path: "/restore/model/{bk_obj_id}/instance",
+		Handler: s.RestoreModelInstances})
 	utility.AddHandler(rest.Action{Verb: http.MethodPost, Path: "/get/instance/object/mapping",
 		Handler: s.GetInstanceObjectMapping})
 
@@ -342,6 +344,8 @@ func (s *coreService) audit(web *restful.WebService) {
 		Handler: s.CreateAuditLog})
 	utility.AddHandler(rest.Action{Verb: http.MethodPost, Path: "/read/auditlog",
 		Handler: s.SearchAuditLog})
+	utility.AddHandler(rest.Action{Verb: http.MethodPost, Path: "/verify/auditlog/chain",
+		Handler: s.VerifyAuditChain})
 
 	utility.AddToRestfulWebService(web)
 }
@@ -476,6 +480,14 @@ func (s *coreService) initCloudSync(web *restful.WebService) {
 		Handler: s.SearchSyncHistory})
 	utility.AddHandler(rest.Action{Verb: http.MethodDelete, Path: "/delete/cloud/sync/destroyed_host_related",
 		Handler: s.DeleteDestroyedHostRelated})
+	utility.AddHandler(rest.Action{Verb: http.MethodPost, Path: "/create/cloud/sync/preview",
+		Handler: s.CreateSyncPreview})
+	utility.AddHandler(rest.Action{Verb: http.MethodPost, Path: "/findmany/cloud/sync/preview",
+		Handler: s.SearchSyncPreview})
+	utility.AddHandler(rest.Action{Verb: http.MethodPut, Path: "/update/cloud/sync/preview/{bk_preview_id}/apply",
+		Handler: s.ApplySyncPreview})
+	utility.AddHandler(rest.Action{Verb: http.MethodDelete, Path: "/delete/cloud/sync/preview/pending/{bk_task_id}",
+		Handler: s.DeletePendingSyncPreview})
 
 	utility.AddToRestfulWebService(web)
 }
@@ -642,9 +642,19 @@ func (c *Collection) tryArchiveDeletedDoc(ctx context.Context, filter types.Filt
 	case common.BKTableNameSetTemplate:
 	case common.BKTableNameBaseProcess:
 	case common.BKTableNameProcessInstanceRelation:
+	// the delete events of service instances carry no document, the instance cache and the topology count cache
+	// find the deleted service instances in the delete archive to clean the cache and decrease the counts. like
+	// the other archives, they are removed by the event flow's daily gc a week after they are deleted.
+	case common.BKTableNameServiceInstance:
 	case common.BKTableNameBaseBizSet:
 	case common.BKTableNameBasePlat:
 	case common.BKTableNameBaseProject:
+	case common.BKTableNameServiceTemplate:
+	case common.BKTableNameProcessTemplate:
+	case common.BKTableNameFieldTemplate:
+	case common.BKTableNameObjDes:
+	case common.BKTableNameObjAttDes:
+	case common.BKTableNameHostApplyRule:
 
 	case common.BKTableNameBaseInst:
 	case common.BKTableNameInstAsst:
@@ -664,6 +674,10 @@ func (c *Collection) tryArchiveDeletedDoc(ctx context.Context, filter types.Filt
 	case kubetypes.BKTableNameBaseCustom:
 	case kubetypes.BKTableNameBasePod:
 	case kubetypes.BKTableNameBaseContainer:
+	case kubetypes.BKTableNameBaseService:
+	case kubetypes.BKTableNameBaseIngress:
+	case kubetypes.BKTableNameBaseEndpoints:
+	case kubetypes.BKTableNameBaseConfigMap:
 		// NOTE: should not use the table name for archive, the object instance and association
 		// was saved in sharding tables, we still case the BKTableNameBaseInst here for the archive
 		// error message in order to find the wrong table name used in logics level.
@@ -207,7 +207,8 @@ func DeleteAllBizs() {
 		kubetypes.BKTableNameBaseStatefulSet, kubetypes.BKTableNameBaseDaemonSet, kubetypes.BKTableNameGameDeployment,
 		kubetypes.BKTableNameGameStatefulSet, kubetypes.BKTableNameBaseCronJob, kubetypes.BKTableNameBaseJob,
 		kubetypes.BKTableNameBasePodWorkload, kubetypes.BKTableNameBaseCustom, kubetypes.BKTableNameBasePod,
-		kubetypes.BKTableNameBaseContainer, common.BKTableNameBaseApp}
+		kubetypes.BKTableNameBaseContainer, kubetypes.BKTableNameBaseService, kubetypes.BKTableNameBaseIngress,
+		kubetypes.BKTableNameBaseEndpoints, kubetypes.BKTableNameBaseConfigMap, common.BKTableNameBaseApp}
 
 	delCond := mapstr.MapStr{common.BKAppIDField: mapstr.MapStr{common.BKDBIN: bizIDs}}
 	for _, table := range tableNames {
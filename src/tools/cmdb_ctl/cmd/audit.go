@@ -0,0 +1,129 @@
+/*
+ * Tencent is pleased to support the open source community by making 蓝鲸 available.
+ * Copyright (C) 2017-2018 THL A29 Limited, a Tencent company. All rights reserved.
+ * Licensed under the MIT License (the "License"); you may not use this file except
+ * in compliance with the License. You may obtain a copy of the License at
+ * http://opensource.org/licenses/MIT
+ * Unless required by applicable law or agreed to in writing, software distributed under
+ * the License is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND,
+ * either express or implied. See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package cmd
+
+import (
+	"fmt"
+	"net/http"
+
+	"configcenter/src/common"
+	"configcenter/src/common/metadata"
+	"configcenter/src/common/types"
+
+	"github.com/spf13/cobra"
+)
+
+func init() {
+	rootCmd.AddCommand(NewAuditCommand())
+}
+
+type auditVerifyConf struct {
+	startSeqNo      int64
+	pageSize        int64
+	supplierAccount string
+}
+
+// NewAuditCommand new audit log operation command
+func NewAuditCommand() *cobra.Command {
+	conf := new(auditVerifyConf)
+
+	cmd := &cobra.Command{
+		Use:   "audit",
+		Short: "audit log operations",
+		Run: func(cmd *cobra.Command, args []string) {
+			_ = cmd.Help()
+		},
+	}
+
+	verifyCmd := &cobra.Command{
+		Use:   "verify",
+		Short: "walk the audit log hash chain and report the gaps and mismatches",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return runAuditVerify(conf)
+		},
+	}
+	conf.addFlags(verifyCmd)
+	cmd.AddCommand(verifyCmd)
+
+	return cmd
+}
+
+func (c *auditVerifyConf) addFlags(cmd *cobra.Command) {
+	cmd.Flags().Int64Var(&c.startSeqNo, "start-seq-no", 0, "the sequence number to verify from, default is the "+
+		"first audit log that is not cleaned")
+	cmd.Flags().Int64Var(&c.pageSize, "page-size", metadata.AuditChainVerifyLimit, "the number of audit logs to "+
+		"verify in one request")
+	cmd.Flags().StringVar(&c.supplierAccount, "supplier-account", common.BKDefaultOwnerID,
+		"the supplier account of the audit log hash chain")
+}
+
+func runAuditVerify(c *auditVerifyConf) error {
+	server, err := getServerAddress(types.CC_MODULE_TOPO)
+	if err != nil {
+		return err
+	}
+
+	url := fmt.Sprintf("http://%s/topo/v3/find/audit_chain/verify", server)
+	option := &metadata.VerifyAuditChainOption{StartSeqNo: c.startSeqNo, Limit: c.pageSize}
+	if rawErr := option.Validate(); rawErr.ErrCode != 0 {
+		return fmt.Errorf("verify option is invalid, error code: %d, args: %v", rawErr.ErrCode, rawErr.Args)
+	}
+
+	var checked, issueCnt int64
+	for {
+		result := new(metadata.VerifyAuditChainResult)
+		if err := doServerRequest(http.MethodPost, url, c.supplierAccount, option, result); err != nil {
+			fmt.Printf(WithRedColor(fmt.Sprintf("verify audit chain from %d failed, err: %v", option.StartSeqNo,
+				err)))
+			return err
+		}
+
+		for _, issue := range result.Issues {
+			issueCnt++
+			fmt.Printf(WithRedColor(formatAuditChainIssue(issue)))
+		}
+		checked += result.Checked
+
+		if result.Finished {
+			if issueCnt > 0 {
+				return fmt.Errorf("verified %d audit logs, %d issues are found, the chain head is %d",
+					checked, issueCnt, result.HeadSeqNo)
+			}
+
+			fmt.Printf(WithGreenColor(fmt.Sprintf("verified %d audit logs after the cleaned %d ones, the chain "+
+				"head is %d, no issue is found", checked, result.PrunedSeqNo, result.HeadSeqNo)))
+			return nil
+		}
+		option.StartSeqNo = result.NextSeqNo
+	}
+}
+
+func formatAuditChainIssue(issue metadata.AuditChainIssue) string {
+	switch issue.Type {
+	case metadata.AuditChainGap:
+		return fmt.Sprintf("gap: audit logs with sequence number %d to %d are missing", issue.StartSeqNo,
+			issue.EndSeqNo)
+	case metadata.AuditChainHashMismatch:
+		return fmt.Sprintf("hash mismatch: audit log %d with sequence number %d is edited, expected hash: %s, "+
+			"actual hash: %s", issue.AuditID, issue.StartSeqNo, issue.Expected, issue.Actual)
+	case metadata.AuditChainLinkMismatch:
+		return fmt.Sprintf("link mismatch: audit log %d with sequence number %d is not linked to the previous one, "+
+			"expected previous hash: %s, actual previous hash: %s", issue.AuditID, issue.StartSeqNo,
+			issue.Expected, issue.Actual)
+	case metadata.AuditChainHeadMismatch:
+		return fmt.Sprintf("head mismatch: audit log %d with sequence number %d does not match the chain head, "+
+			"expected hash: %s, actual hash: %s", issue.AuditID, issue.StartSeqNo, issue.Expected, issue.Actual)
+	default:
+		return fmt.Sprintf("%s: sequence number %d to %d", issue.Type, issue.StartSeqNo, issue.EndSeqNo)
+	}
+}
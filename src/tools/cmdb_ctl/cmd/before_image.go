@@ -0,0 +1,186 @@
+/*
+ * Tencent is pleased to support the open source community by making 蓝鲸 available.
+ * Copyright (C) 2017-2018 THL A29 Limited, a Tencent company. All rights reserved.
+ * Licensed under the MIT License (the "License"); you may not use this file except
+ * in compliance with the License. You may obtain a copy of the License at
+ * http://opensource.org/licenses/MIT
+ * Unless required by applicable law or agreed to in writing, software distributed under
+ * the License is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND,
+ * either express or implied. See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package cmd
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	"configcenter/src/common/mapstr"
+	"configcenter/src/storage/dal/mongo/local"
+	"configcenter/src/storage/dal/redis"
+
+	"go.mongodb.org/mongo-driver/bson"
+)
+
+// beforeImageType is the type of the data source that a before-image is exported from
+type beforeImageType string
+
+const (
+	mongoBeforeImage beforeImageType = "mongo"
+	redisBeforeImage beforeImageType = "redis"
+)
+
+const (
+	defaultSampleNum = 5
+	// redisDumpScript dumps the serialized value and the remaining ttl in milliseconds of a key,
+	// returns an empty array if the key not exists.
+	redisDumpScript = `local v = redis.call('DUMP', KEYS[1])
+if not v then return {} end
+return {v, redis.call('PTTL', KEYS[1])}`
+	// redisRestoreScript restores a key from its serialized value with the ttl in milliseconds, 0 means no ttl.
+	redisRestoreScript = `return redis.call('RESTORE', KEYS[1], ARGV[1], ARGV[2])`
+)
+
+// beforeImage is the data exported before a deletion, which can be used to restore the deleted data.
+type beforeImage struct {
+	Type       beforeImageType `json:"type"`
+	Collection string          `json:"collection,omitempty"`
+	Condition  string          `json:"condition,omitempty"`
+	Match      string          `json:"match,omitempty"`
+	CreateTime time.Time       `json:"create_time"`
+	// Documents are the mongodb documents in canonical extended json format, so that the bson types are kept.
+	Documents []json.RawMessage `json:"documents,omitempty"`
+	Keys      []redisKeyImage   `json:"keys,omitempty"`
+}
+
+// redisKeyImage is the before-image of a redis key
+type redisKeyImage struct {
+	Key string `json:"key"`
+	// TTL is the remaining ttl of the key in milliseconds, 0 means the key has no ttl.
+	TTL int64 `json:"ttl"`
+	// Value is the base64 encoded value of the key that is serialized by redis DUMP command.
+	Value string `json:"value"`
+}
+
+// genBeforeImageFileName generate the default before-image file name for a data source
+func genBeforeImageFileName(source string) string {
+	name := strings.NewReplacer("*", "", "?", "", "/", "_", ":", "_", " ", "").Replace(source)
+	return fmt.Sprintf("%s_before_image_%s.json", name, time.Now().Format("20060102150405"))
+}
+
+func writeBeforeImage(file string, image *beforeImage) error {
+	content, err := json.MarshalIndent(image, "", "    ")
+	if err != nil {
+		return fmt.Errorf("marshal before-image failed, err: %v", err)
+	}
+
+	if err = os.WriteFile(file, content, 0644); err != nil {
+		return fmt.Errorf("write before-image to file %s failed, err: %v", file, err)
+	}
+	return nil
+}
+
+func readBeforeImage(file string, typ beforeImageType) (*beforeImage, error) {
+	if len(file) == 0 {
+		return nil, errors.New("before-image file must be set")
+	}
+
+	content, err := os.ReadFile(file)
+	if err != nil {
+		return nil, fmt.Errorf("read before-image file %s failed, err: %v", file, err)
+	}
+
+	image := new(beforeImage)
+	if err = json.Unmarshal(content, image); err != nil {
+		return nil, fmt.Errorf("unmarshal before-image file %s failed, err: %v", file, err)
+	}
+
+	if image.Type != typ {
+		return nil, fmt.Errorf("before-image file %s is of type %s, not %s", file, image.Type, typ)
+	}
+	return image, nil
+}
+
+// exportMongoBeforeImage export all the documents that matches the condition in the collection
+func exportMongoBeforeImage(ctx context.Context, db *local.Mongo, collection, condition string,
+	cond mapstr.MapStr) (*beforeImage, error) {
+
+	cursor, err := db.GetDBClient().Database(db.GetDBName()).Collection(collection).Find(ctx, cond)
+	if err != nil {
+		return nil, fmt.Errorf("find documents to export failed, err: %v", err)
+	}
+	defer cursor.Close(ctx)
+
+	image := &beforeImage{
+		Type:       mongoBeforeImage,
+		Collection: collection,
+		Condition:  condition,
+		CreateTime: time.Now(),
+		Documents:  make([]json.RawMessage, 0),
+	}
+
+	for cursor.Next(ctx) {
+		doc, err := bson.MarshalExtJSON(cursor.Current, true, false)
+		if err != nil {
+			return nil, fmt.Errorf("marshal document %s to extended json failed, err: %v", cursor.Current, err)
+		}
+		image.Documents = append(image.Documents, doc)
+	}
+
+	if err = cursor.Err(); err != nil {
+		return nil, fmt.Errorf("iterate documents to export failed, err: %v", err)
+	}
+	return image, nil
+}
+
+// dumpRedisKey dump the before-image of a redis key, returns nil if the key not exists
+func dumpRedisKey(ctx context.Context, cli redis.Client, key string) (*redisKeyImage, error) {
+	res, err := cli.Eval(ctx, redisDumpScript, []string{key}).Result()
+	if err != nil {
+		return nil, fmt.Errorf("dump redis key %s failed, err: %v", key, err)
+	}
+
+	values, ok := res.([]interface{})
+	if !ok {
+		return nil, fmt.Errorf("dump redis key %s result %v is invalid", key, res)
+	}
+
+	if len(values) == 0 {
+		return nil, nil
+	}
+
+	if len(values) != 2 {
+		return nil, fmt.Errorf("dump redis key %s result %v is invalid", key, res)
+	}
+
+	value, ok := values[0].(string)
+	if !ok {
+		return nil, fmt.Errorf("dump redis key %s value %v is invalid", key, values[0])
+	}
+
+	ttl, ok := values[1].(int64)
+	if !ok {
+		return nil, fmt.Errorf("dump redis key %s ttl %v is invalid", key, values[1])
+	}
+
+	// pttl returns -1 if the key has no ttl, -2 if the key is expired during the dump
+	if ttl == -2 {
+		return nil, nil
+	}
+	if ttl < 0 {
+		ttl = 0
+	}
+
+	return &redisKeyImage{
+		Key:   key,
+		TTL:   ttl,
+		Value: base64.StdEncoding.EncodeToString([]byte(value)),
+	}, nil
+}
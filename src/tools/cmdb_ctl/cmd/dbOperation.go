@@ -47,8 +47,15 @@ func init() {
 }
 
 type delDbConf struct {
-	colName   string
-	condition string
+	colName    string
+	condition  string
+	dryRun     bool
+	sampleNum  int
+	exportFile string
+}
+
+type restoreDbConf struct {
+	fromFile string
 }
 
 type findDbConf struct {
@@ -60,9 +67,10 @@ type findDbConf struct {
 }
 
 type dbOperationConf struct {
-	service   *config.Service
-	delParam  delDbConf
-	findParam findDbConf
+	service      *config.Service
+	delParam     delDbConf
+	findParam    findDbConf
+	restoreParam restoreDbConf
 }
 type delData struct {
 	MongoID primitive.ObjectID `bson:"_id"`
@@ -72,7 +80,10 @@ type delData struct {
 //    --find
 //             --colName(collection name) --condition（查询的条件） --resfilter（结果是否需要过滤指定字段） --pretty（是否需要采用json pretty格式返回） --num（返回的数量默认值是5）
 //    --delete
-//             --colName（collection name）--condition（删除的条件）
+//             --colName（collection name）--condition（删除的条件）--dry-run（仅预览，不删除）--sample-num（预览的数据条数）
+//             --export（删除前导出数据的文件）
+//    --restore
+//             --from（由delete导出的数据文件）
 //    --show
 
 // NewDbOperationCommand TODO
@@ -121,11 +132,28 @@ func NewDbOperationCommand() *cobra.Command {
 	delCmd.Flags().StringVar(&conf.delParam.colName, "collection", "", "collection name,the parameter must be assigned")
 	delCmd.Flags().StringVar(&conf.delParam.condition, "condition", "",
 		"conditions for deletion,the parameter must be json format string")
+	delCmd.Flags().BoolVar(&conf.delParam.dryRun, "dry-run", false,
+		"only show the matched data num and samples and export the matched data, do not delete")
+	delCmd.Flags().IntVar(&conf.delParam.sampleNum, "sample-num", defaultSampleNum,
+		"numbers of matched data samples to show in dry run mode")
+	delCmd.Flags().StringVar(&conf.delParam.exportFile, "export", "", "file to export the matched data to before "+
+		"deletion, which can be used by db restore command, default is generated by collection name in dry run mode")
 	delCmds = append(delCmds, delCmd)
 	for _, dCmd := range delCmds {
 		cmd.AddCommand(dCmd)
 	}
 
+	restoreCmd := &cobra.Command{
+		Use:   "restore",
+		Short: "re-insert the data exported by db delete command into the db",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return runRestoreDbDataCmd(conf)
+		},
+	}
+	restoreCmd.Flags().StringVar(&conf.restoreParam.fromFile, "from", "",
+		"the exported data file of db delete command,the parameter must be assigned")
+	cmd.AddCommand(restoreCmd)
+
 	showCmds := make([]*cobra.Command, 0)
 	showCmd := &cobra.Command{
 		Use:   "show",
@@ -165,11 +193,22 @@ func runDelDbDataCmd(conf *dbOperationConf) error {
 		return err
 	}
 
+	if conf.delParam.dryRun {
+		return previewDelDbData(ctx, s, conf.delParam, cond, total)
+	}
+
 	if total > maxDeleteNum {
 		errInfo := fmt.Sprintf("number of data to delete is %d,over the max delete number 1000.", total)
 		return errors.New(errInfo)
 	}
 
+	if len(conf.delParam.exportFile) != 0 {
+		if err = exportDelDbData(ctx, s, conf.delParam, cond); err != nil {
+			fmt.Printf("export data before deletion failed, err: %v\n", err)
+			return err
+		}
+	}
+
 	if total < maxDeleteBatchNum {
 		if err = s.DbProxy.Table(conf.delParam.colName).Delete(ctx, cond); err != nil {
 			fmt.Printf("delete data failed, err: %s \n", err.Error())
@@ -226,6 +265,123 @@ func runDelDbDataCmd(conf *dbOperationConf) error {
 	return nil
 }
 
+// previewDelDbData show the matched data num and samples, and export the matched data without deletion
+func previewDelDbData(ctx context.Context, s *config.Service, param delDbConf, cond mapstr.MapStr,
+	total uint64) error {
+
+	fmt.Printf("matched data num is %d\n", total)
+	if total == 0 {
+		return nil
+	}
+
+	if total > maxDeleteNum {
+		fmt.Printf("number of data to delete is over the max delete number %d, the deletion will be rejected\n",
+			maxDeleteNum)
+		return nil
+	}
+
+	samples := make([]map[string]interface{}, 0)
+	err := s.DbProxy.Table(param.colName).Find(cond).Sort("_id").Limit(uint64(param.sampleNum)).All(ctx, &samples)
+	if err != nil {
+		fmt.Printf("find sample data failed, err: %v\n", err)
+		return err
+	}
+
+	fmt.Printf("show some matched data as an example :\n")
+	for _, sample := range samples {
+		sampleJSON, err := json.Marshal(sample)
+		if err != nil {
+			fmt.Printf("marshal sample data failed, err: %v\n", err)
+			return err
+		}
+		fmt.Printf("%s\n", sampleJSON)
+	}
+	fmt.Printf("example end\n")
+
+	if len(param.exportFile) == 0 {
+		param.exportFile = genBeforeImageFileName(param.colName)
+	}
+
+	if err = exportDelDbData(ctx, s, param, cond); err != nil {
+		fmt.Printf("export matched data failed, err: %v\n", err)
+		return err
+	}
+
+	fmt.Printf("dry run, no data is deleted\n")
+	return nil
+}
+
+// exportDelDbData export the data to be deleted to the export file
+func exportDelDbData(ctx context.Context, s *config.Service, param delDbConf, cond mapstr.MapStr) error {
+	mongo, ok := s.DbProxy.(*local.Mongo)
+	if !ok {
+		return fmt.Errorf("db is not local.Mongo type")
+	}
+
+	image, err := exportMongoBeforeImage(ctx, mongo, param.colName, param.condition, cond)
+	if err != nil {
+		return err
+	}
+
+	if err = writeBeforeImage(param.exportFile, image); err != nil {
+		return err
+	}
+
+	fmt.Printf("export %d data to file %s\n", len(image.Documents), param.exportFile)
+	return nil
+}
+
+func runRestoreDbDataCmd(conf *dbOperationConf) error {
+	image, err := readBeforeImage(conf.restoreParam.fromFile, mongoBeforeImage)
+	if err != nil {
+		fmt.Printf("read exported data failed, err: %v\n", err)
+		return err
+	}
+
+	if len(image.Collection) == 0 {
+		return fmt.Errorf("collection of the exported data is not set")
+	}
+
+	s, err := newMongo(config.Conf.MongoURI, config.Conf.MongoRsName)
+	if err != nil {
+		fmt.Printf("connect mongo db fail ,err: %v\n", err)
+		return err
+	}
+	defer s.DbProxy.Close()
+
+	mongo, ok := s.DbProxy.(*local.Mongo)
+	if !ok {
+		return fmt.Errorf("db is not local.Mongo type")
+	}
+	coll := mongo.GetDBClient().Database(mongo.GetDBName()).Collection(image.Collection)
+
+	ctx := context.Background()
+	var restored, skipped int
+	for _, rawDoc := range image.Documents {
+		doc := make(bson.D, 0)
+		if err = bson.UnmarshalExtJSON(rawDoc, true, &doc); err != nil {
+			fmt.Printf("unmarshal exported data %s failed, err: %v\n", rawDoc, err)
+			return err
+		}
+
+		if _, err = coll.InsertOne(ctx, doc); err != nil {
+			// the data may not be deleted or already restored, skip it
+			if mongo.IsDuplicatedError(err) {
+				fmt.Printf("data %s already exists, skip it\n", rawDoc)
+				skipped++
+				continue
+			}
+			fmt.Printf("restore data %s failed, err: %v\n", rawDoc, err)
+			return err
+		}
+		restored++
+	}
+
+	fmt.Printf("restore data to collection %s, restored num is %d, skipped num is %d\n", image.Collection,
+		restored, skipped)
+	return nil
+}
+
 func runFindDbDataCmd(conf *dbOperationConf) error {
 
 	s, err := newMongo(config.Conf.MongoURI, config.Conf.MongoRsName)
@@ -20,8 +20,11 @@ import (
 	"net/http"
 	"net/url"
 	"os"
+	"strconv"
 	"time"
 
+	"configcenter/src/common/watch"
+
 	"github.com/spf13/cobra"
 )
 
@@ -32,6 +35,7 @@ func init() {
 type echo struct {
 	url        string
 	jsonPretty bool
+	secret     string
 }
 
 // NewEchoCommand TODO
@@ -54,6 +58,8 @@ func NewEchoCommand() *cobra.Command {
 func (c *echo) addFlags(cmd *cobra.Command) {
 	cmd.Flags().StringVar(&c.url, "url", "", "the url of the echo server, eg: http://127.0.0.1:80/echo")
 	cmd.Flags().BoolVar(&c.jsonPretty, "pretty", false, "json indent the received data if it's json format.")
+	cmd.Flags().StringVar(&c.secret, "secret", "", "the event subscription secret, if set, the signature of the "+
+		"received data is verified, and the data with an invalid signature is rejected.")
 }
 
 func runEchoServer(c *echo) error {
@@ -76,6 +82,22 @@ func (c *echo) echoServer(w http.ResponseWriter, r *http.Request) {
 	}
 	fmt.Fprintf(os.Stdout, "%c[1;40;31m>> received new data, time: %s %c[0m\n", 0x1B, time.Now().Format(time.RFC3339),
 		0x1B)
+
+	if subID := r.Header.Get(watch.SubscriptionIDHeader); len(subID) != 0 {
+		fmt.Fprintf(os.Stdout, "subscription: %s, timestamp: %s, signature: %s\n", subID,
+			r.Header.Get(watch.SubscriptionTimestampHeader), r.Header.Get(watch.SubscriptionSignatureHeader))
+	}
+
+	if len(c.secret) != 0 {
+		timestamp, err := strconv.ParseInt(r.Header.Get(watch.SubscriptionTimestampHeader), 10, 64)
+		if err != nil || !watch.VerifySubscriptionSignature(c.secret, timestamp, s,
+			r.Header.Get(watch.SubscriptionSignatureHeader)) {
+			fmt.Fprintf(os.Stderr, "verify signature failed, reject the data\n\n")
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		fmt.Fprintf(os.Stdout, "verify signature success\n")
+	}
 	if c.jsonPretty {
 		var prettyJSON bytes.Buffer
 		if err := json.Indent(&prettyJSON, s, "", "    "); err != nil {
@@ -0,0 +1,97 @@
+/*
+ * Tencent is pleased to support the open source community by making 蓝鲸 available.
+ * Copyright (C) 2017-2018 THL A29 Limited, a Tencent company. All rights reserved.
+ * Licensed under the MIT License (the "License"); you may not use this file except
+ * in compliance with the License. You may obtain a copy of the License at
+ * http://opensource.org/licenses/MIT
+ * Unless required by applicable law or agreed to in writing, software distributed under
+ * the License is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND,
+ * either express or implied. See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package cmd
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+
+	"configcenter/pkg/filter"
+
+	"github.com/spf13/cobra"
+)
+
+func init() {
+	rootCmd.AddCommand(NewFilterCommand())
+}
+
+type filterConf struct {
+	query  string
+	expr   string
+	pretty bool
+}
+
+// NewFilterCommand new filter command to convert filter expression between text form query and json
+func NewFilterCommand() *cobra.Command {
+	conf := new(filterConf)
+
+	cmd := &cobra.Command{
+		Use:   "filter",
+		Short: "convert filter expression between text form query and json",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return runFilter(conf)
+		},
+	}
+
+	conf.addFlags(cmd)
+
+	return cmd
+}
+
+func (c *filterConf) addFlags(cmd *cobra.Command) {
+	cmd.Flags().StringVar(&c.query, "query", "", "the text form query to be converted to json filter expression, "+
+		`eg: bk_os_type = "1" AND NOT bk_cloud_id in (0,2)`)
+	cmd.Flags().StringVar(&c.expr, "expr", "", "the json filter expression to be converted to text form query")
+	cmd.Flags().BoolVar(&c.pretty, "pretty", false, "json indent the converted filter expression")
+}
+
+func runFilter(c *filterConf) error {
+	switch {
+	case len(c.query) != 0 && len(c.expr) != 0:
+		return errors.New("query and expr can not be set at the same time")
+	case len(c.query) != 0:
+		exp, err := filter.ParseQuery(c.query, nil)
+		if err != nil {
+			return err
+		}
+
+		var out []byte
+		if c.pretty {
+			out, err = json.MarshalIndent(exp, "", "    ")
+		} else {
+			out, err = json.Marshal(exp)
+		}
+		if err != nil {
+			return err
+		}
+
+		fmt.Println(string(out))
+		return nil
+	case len(c.expr) != 0:
+		exp := new(filter.Expression)
+		if err := json.Unmarshal([]byte(c.expr), exp); err != nil {
+			return fmt.Errorf("unmarshal filter expression failed, err: %v", err)
+		}
+
+		query, err := filter.FormatQuery(exp)
+		if err != nil {
+			return err
+		}
+
+		fmt.Println(query)
+		return nil
+	default:
+		return errors.New("query or expr must be set")
+	}
+}
@@ -14,7 +14,9 @@ package cmd
 
 import (
 	"context"
+	"encoding/base64"
 	"fmt"
+	"strings"
 	"time"
 
 	"configcenter/src/storage/dal/redis"
@@ -36,8 +38,12 @@ const (
 )
 
 type redisOperation struct {
-	match   string
-	service *config.Service
+	match      string
+	dryRun     bool
+	sampleNum  int
+	exportFile string
+	fromFile   string
+	service    *config.Service
 }
 
 // NewRedisOperationCommand TODO
@@ -76,12 +82,29 @@ func NewRedisOperationCommand() *cobra.Command {
 		},
 	}
 	delCmd.Flags().StringVar(&conf.match, "match", "", "redis scan  match pattern  default is null")
+	delCmd.Flags().BoolVar(&conf.dryRun, "dry-run", false,
+		"only show the matched keys num and samples and export the matched keys, do not delete")
+	delCmd.Flags().IntVar(&conf.sampleNum, "sample-num", defaultSampleNum,
+		"numbers of matched keys samples to show in dry run mode")
+	delCmd.Flags().StringVar(&conf.exportFile, "export", "", "file to export the matched keys to before "+
+		"deletion, which can be used by redis restore command, default is generated by match pattern in dry run mode")
 
 	delCmds = append(delCmds, delCmd)
 	for _, fCmd := range delCmds {
 		cmd.AddCommand(fCmd)
 	}
 
+	restoreCmd := &cobra.Command{
+		Use:   "restore",
+		Short: "restore the keys exported by scan-del command",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return runRedisRestore(conf)
+		},
+	}
+	restoreCmd.Flags().StringVar(&conf.fromFile, "from", "",
+		"the exported keys file of scan-del command,the parameter must be assigned")
+	cmd.AddCommand(restoreCmd)
+
 	return cmd
 }
 
@@ -150,6 +173,26 @@ func runRedisScanDel(conf *redisOperation) error {
 	}
 
 	ctx := context.Background()
+	if conf.dryRun {
+		if len(conf.exportFile) == 0 {
+			conf.exportFile = genBeforeImageFileName(conf.match)
+		}
+		if err = exportRedisScanKeys(ctx, redisCli, conf); err != nil {
+			fmt.Printf("export matched keys failed, err: %v\n", err)
+			return err
+		}
+		fmt.Printf("dry run, no key is deleted\n")
+		return nil
+	}
+
+	// keys that are created between export and deletion are not exported
+	if len(conf.exportFile) != 0 {
+		if err = exportRedisScanKeys(ctx, redisCli, conf); err != nil {
+			fmt.Printf("export keys before deletion failed, err: %v\n", err)
+			return err
+		}
+	}
+
 	fmt.Printf("del keys start :\n")
 	for {
 		res := redisCli.Scan(ctx, cursor, conf.match, redisDefaultCount)
@@ -198,3 +241,102 @@ func runRedisScanDel(conf *redisOperation) error {
 
 	return nil
 }
+
+// exportRedisScanKeys export all the scanned keys to the export file, and show the matched keys num and samples
+func exportRedisScanKeys(ctx context.Context, redisCli redis.Client, conf *redisOperation) error {
+	image := &beforeImage{
+		Type:       redisBeforeImage,
+		Match:      conf.match,
+		CreateTime: time.Now(),
+		Keys:       make([]redisKeyImage, 0),
+	}
+
+	cursor := redisDefaultCursor
+	for {
+		keys, cur, err := redisCli.Scan(ctx, cursor, conf.match, redisDefaultCount).Result()
+		if err != nil {
+			return fmt.Errorf("scan keys failed, err: %v", err)
+		}
+
+		for _, key := range keys {
+			keyImage, err := dumpRedisKey(ctx, redisCli, key)
+			if err != nil {
+				return err
+			}
+
+			// the key is deleted or expired during the scan
+			if keyImage == nil {
+				continue
+			}
+			image.Keys = append(image.Keys, *keyImage)
+		}
+
+		if cur == redisDefaultCursor {
+			break
+		}
+		cursor = cur
+	}
+
+	fmt.Printf("matched keys num is %d\n", len(image.Keys))
+	if len(image.Keys) == 0 {
+		return nil
+	}
+
+	if conf.dryRun {
+		fmt.Printf("show some matched keys as an example :\n")
+		for i := 0; i < conf.sampleNum && i < len(image.Keys); i++ {
+			fmt.Printf("%v \n", image.Keys[i].Key)
+		}
+		fmt.Printf("example end \n")
+	}
+
+	if err := writeBeforeImage(conf.exportFile, image); err != nil {
+		return err
+	}
+
+	fmt.Printf("export %d keys to file %s\n", len(image.Keys), conf.exportFile)
+	return nil
+}
+
+func runRedisRestore(conf *redisOperation) error {
+	image, err := readBeforeImage(conf.fromFile, redisBeforeImage)
+	if err != nil {
+		fmt.Printf("read exported keys failed, err: %v\n", err)
+		return err
+	}
+
+	// don't need to open too many conns
+	config.Conf.RedisConf.MaxOpenConns = redisDefaultConnNum
+
+	redisCli, err := redis.NewFromConfig(config.Conf.RedisConf)
+	if err != nil {
+		fmt.Printf("read redis config fail, err :%v.\n", err)
+		return err
+	}
+
+	ctx := context.Background()
+	var restored, skipped int
+	for _, keyImage := range image.Keys {
+		value, err := base64.StdEncoding.DecodeString(keyImage.Value)
+		if err != nil {
+			fmt.Printf("decode the value of key %s failed, err: %v\n", keyImage.Key, err)
+			return err
+		}
+
+		err = redisCli.Eval(ctx, redisRestoreScript, []string{keyImage.Key}, keyImage.TTL, string(value)).Err()
+		if err != nil {
+			// the key may not be deleted or already restored, skip it
+			if strings.Contains(err.Error(), "BUSYKEY") {
+				fmt.Printf("key %s already exists, skip it\n", keyImage.Key)
+				skipped++
+				continue
+			}
+			fmt.Printf("restore key %s failed, err: %v\n", keyImage.Key, err)
+			return err
+		}
+		restored++
+	}
+
+	fmt.Printf("restore keys success, restored num is %d, skipped num is %d\n", restored, skipped)
+	return nil
+}
@@ -0,0 +1,96 @@
+/*
+ * Tencent is pleased to support the open source community by making 蓝鲸 available.
+ * Copyright (C) 2017-2018 THL A29 Limited, a Tencent company. All rights reserved.
+ * Licensed under the MIT License (the "License"); you may not use this file except
+ * in compliance with the License. You may obtain a copy of the License at
+ * http://opensource.org/licenses/MIT
+ * Unless required by applicable law or agreed to in writing, software distributed under
+ * the License is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND,
+ * either express or implied. See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package cmd
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+
+	"configcenter/src/common"
+	"configcenter/src/common/metadata"
+	"configcenter/src/common/types"
+
+	"github.com/spf13/cobra"
+)
+
+func init() {
+	rootCmd.AddCommand(NewRestoreCommand())
+}
+
+type restoreConf struct {
+	objID           string
+	bizID           int64
+	instIDs         []int64
+	supplierAccount string
+}
+
+// NewRestoreCommand new restore deleted instances command
+func NewRestoreCommand() *cobra.Command {
+	conf := new(restoreConf)
+
+	cmd := &cobra.Command{
+		Use:   "restore",
+		Short: "restore deleted set, module, host or custom object instances from the delete archive",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return runRestore(conf)
+		},
+	}
+
+	conf.addFlags(cmd)
+
+	return cmd
+}
+
+func (c *restoreConf) addFlags(cmd *cobra.Command) {
+	cmd.Flags().StringVar(&c.objID, "obj-id", "", "the object id of the deleted instances, like set, module, host "+
+		"or custom object id")
+	cmd.Flags().Int64Var(&c.bizID, "biz-id", 0, "the business id of the deleted instances, required for set, "+
+		"module and custom mainline instances")
+	cmd.Flags().Int64SliceVar(&c.instIDs, "inst-ids", nil, "the ids of the deleted instances, separated by comma, "+
+		"they are restored in order")
+	cmd.Flags().StringVar(&c.supplierAccount, "supplier-account", common.BKDefaultOwnerID,
+		"the supplier account of the deleted instances")
+}
+
+func runRestore(c *restoreConf) error {
+	if c.objID == "" {
+		return errors.New("obj-id must be set")
+	}
+
+	option := &metadata.RestoreInstOption{
+		BizID:   c.bizID,
+		InstIDs: c.instIDs,
+	}
+	if rawErr := option.Validate(); rawErr.ErrCode != 0 {
+		return fmt.Errorf("restore option is invalid, error code: %d, args: %v", rawErr.ErrCode, rawErr.Args)
+	}
+
+	server, err := getServerAddress(types.CC_MODULE_TOPO)
+	if err != nil {
+		return err
+	}
+
+	url := fmt.Sprintf("http://%s/topo/v3/restore/instance/object/%s", server, c.objID)
+	result := new(metadata.RestoreInstResult)
+	if err := doServerRequest(http.MethodPost, url, c.supplierAccount, option, result); err != nil {
+		fmt.Printf(WithRedColor(fmt.Sprintf("restore %s instances %v failed, err: %v", c.objID, c.instIDs, err)))
+		return err
+	}
+
+	js, _ := json.MarshalIndent(result, "", "    ")
+	fmt.Printf(WithGreenColor(fmt.Sprintf("restore %s instances %v success", c.objID, c.instIDs)))
+	fmt.Println(string(js))
+	return nil
+}
@@ -13,7 +13,15 @@
 package cmd
 
 import (
+	"bytes"
+	"encoding/json"
 	"fmt"
+	"net/http"
+
+	"configcenter/src/common/metadata"
+	"configcenter/src/common/types"
+	"configcenter/src/common/util"
+	"configcenter/src/tools/cmdb_ctl/app/config"
 )
 
 // WithRedColor TODO
@@ -30,3 +38,65 @@ func WithGreenColor(str string) string {
 func WithBlueColor(str string) string {
 	return fmt.Sprintf("%c[1;40;34m>> %s %c[0m\n", 0x1B, str, 0x1B)
 }
+
+// getServerAddress get the address of one of the cmdb servers of the module from zookeeper
+func getServerAddress(module string) (string, error) {
+	zk, err := config.NewZkService(config.Conf.ZkAddr)
+	if err != nil {
+		return "", fmt.Errorf("new zk client failed, err: %v", err)
+	}
+
+	path := types.CC_SERV_BASEPATH + "/" + module
+	children, err := zk.ZkCli.GetChildren(path)
+	if err != nil {
+		return "", fmt.Errorf("get %s server failed, err: %v", module, err)
+	}
+
+	for _, child := range children {
+		node, err := zk.ZkCli.Get(path + "/" + child)
+		if err != nil {
+			return "", err
+		}
+
+		svr := new(types.ServerInfo)
+		if err := json.Unmarshal([]byte(node), svr); err != nil {
+			return "", err
+		}
+		return fmt.Sprintf("%s:%d", svr.RegisterIP, svr.Port), nil
+	}
+
+	return "", fmt.Errorf("no %s server", module)
+}
+
+// doServerRequest do a json request to the cmdb server as the cmdb tool user, the response data is decoded into result
+func doServerRequest(method, url, supplierAccount string, body interface{}, result interface{}) error {
+	bodyByte, err := json.Marshal(body)
+	if err != nil {
+		return err
+	}
+
+	req, err := http.NewRequest(method, url, bytes.NewReader(bodyByte))
+	if err != nil {
+		return err
+	}
+	req.Header.Add("HTTP_BLUEKING_SUPPLIER_ID", supplierAccount)
+	req.Header.Add("BK_User", "cmdb_tool")
+	req.Header.Add("Content-Type", "application/json")
+	req.Header.Add("Cc_Request_Id", util.GenerateRID())
+
+	resp, err := new(http.Client).Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	response := &metadata.Response{Data: result}
+	if err := json.NewDecoder(resp.Body).Decode(response); err != nil {
+		return err
+	}
+
+	if !response.Result {
+		return fmt.Errorf("request failed, code: %d, err: %s", response.Code, response.ErrMsg)
+	}
+	return nil
+}
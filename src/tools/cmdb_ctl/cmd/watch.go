@@ -50,12 +50,14 @@ func (w *watchConf) addFlags(cmd *cobra.Command) {
 		"which is means start from now-(start-from)")
 	cmd.PersistentFlags().StringVar(&w.resource, "rsc", "host", "the resource to watch, can be：host, host_relation, "+
 		"biz, set, module, process, process_instance_relation, object_instance, mainline_instance, inst_asst, "+
-		"host_identifier, biz_set, biz_set_relation")
+		"host_identifier, biz_set, biz_set_relation, dynamic_group_member, service_template, process_template, "+
+		"set_template, field_template, model, model_attribute, host_apply_rule")
 	cmd.PersistentFlags().StringSliceVar(&w.fields, "fields", nil, "the resource fields to return")
 	cmd.PersistentFlags().StringVar(&w.filter, "filter", "", "a k:v pair to filter events, k and v is separate with "+
 		"':' , multiple kv is separated with ';', like k1:v1;k2:v2")
 	cmd.PersistentFlags().StringVar(&w.subresource, "sub-rsc", "", "the sub resource to watch, can be the object ID "+
-		"of object_instance or mainline_instance resource")
+		"of object_instance, mainline_instance or model_attribute resource, the dynamic group ID of "+
+		"dynamic_group_member resource, or the service template ID of process_template resource")
 }
 
 // NewWatchCommand TODO
@@ -156,9 +158,11 @@ func runStartFromWatch(c *watchConf) error {
 
 	if len(c.subresource) > 0 {
 		switch watch.CursorType(c.resource) {
-		case watch.ObjectBase, watch.MainlineInstance:
+		case watch.ObjectBase, watch.MainlineInstance, watch.DynamicGroupMember, watch.ProcessTemplate,
+			watch.ModelAttribute:
 		default:
-			return fmt.Errorf("sub reource can only be set when resource is object_instance or mainline_instance")
+			return fmt.Errorf("sub reource can only be set when resource is object_instance, mainline_instance, " +
+				"dynamic_group_member, process_template or model_attribute")
 		}
 	}
 